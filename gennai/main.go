@@ -2,9 +2,11 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"os"
+	"os/signal"
 	"strings"
 	"time"
 
@@ -13,12 +15,13 @@ import (
 	"github.com/fpt/go-gennai-cli/internal/infra"
 	"github.com/fpt/go-gennai-cli/internal/mcp"
 	"github.com/fpt/go-gennai-cli/pkg/agent/domain"
-	"github.com/fpt/go-gennai-cli/pkg/client/anthropic"
-	"github.com/fpt/go-gennai-cli/pkg/client/gemini"
-	"github.com/fpt/go-gennai-cli/pkg/client/ollama"
-	"github.com/fpt/go-gennai-cli/pkg/client/openai"
+	"github.com/fpt/go-gennai-cli/pkg/agent/events"
+	"github.com/fpt/go-gennai-cli/pkg/client"
+	"github.com/fpt/go-gennai-cli/pkg/client/models"
+	"github.com/fpt/go-gennai-cli/pkg/httpclient"
 	pkgLogger "github.com/fpt/go-gennai-cli/pkg/logger"
 	"github.com/fpt/go-gennai-cli/pkg/message"
+	"golang.org/x/term"
 )
 
 // resolveStringFlag returns the non-empty value, preferring short flag over long flag
@@ -43,11 +46,27 @@ func printUsage() {
 	fmt.Println("  gennai \"Create a HTTP server\"             # One-shot mode (code scenario)")
 	fmt.Println("  gennai -s research \"Go best practices\"    # Research scenario")
 	fmt.Println("  gennai -s code \"Fix compilation errors\"   # Code scenario")
+	fmt.Println("  gennai --auto-scenario \"Compare Go ORMs\"  # Let the model pick the scenario")
 	fmt.Println("  gennai -b anthropic \"Analyze this code\"  # Use Anthropic backend")
+	fmt.Println("  gennai -b azure -m my-deployment \"Q\"      # Use an Azure OpenAI deployment")
 	fmt.Println("  gennai -f prompts.txt                     # Multi-turn from file (no memory)")
+	fmt.Println("  gennai --session refactor                 # Interactive mode with a named session")
+	fmt.Println("  gennai --continue \"Now add tests\"         # One-shot that resumes and extends the project session")
 	// Custom scenario CLI option removed
 	fmt.Println("  gennai -v \"Debug this issue\"             # Enable verbose debug logging")
+	fmt.Println("  gennai --verbose-tools \"Why does X fail\"  # Show full tool results instead of truncated ones")
+	fmt.Println("  gennai --plain \"Explain main.go\" | less   # Print the answer as raw Markdown")
+	fmt.Println("  gennai --time-limit 10m \"Fix the tests\"   # Stop after 10 minutes with the partial result")
 	fmt.Println("  gennai -l                                # Show conversation history")
+	fmt.Println("  gennai --json-events \"Run the tests\"      # One-shot with JSON-lines agent events on stdout")
+	fmt.Println("  gennai --offline \"Summarize this repo\"    # No web tools or remote MCP servers")
+	fmt.Println("  gennai --dry-run \"Rename the config type\" # Print proposed changes as a patch, write nothing")
+	fmt.Println("  gennai --output json \"Summarize main.go\"   # One-shot with a single JSON result object on stdout")
+	fmt.Println("  gennai -s respond --schema answer.json \"Q\" # Answer as JSON conforming to a schema")
+	fmt.Println("  gennai --export run.md \"Fix the build\"    # One-shot, saving a Markdown transcript")
+	fmt.Println("  gennai --watch '*.go' \"Fix failing tests\" # Re-run the prompt whenever a .go file changes")
+	fmt.Println("  gennai --llm-cache replay --llm-cache-dir testdata/llm \"Q\" # Answer from recorded LLM responses")
+	fmt.Println("  gennai serve --addr 127.0.0.1:8420        # Serve the agent over HTTP (SSE events) for a GUI; prints the bearer token")
 	fmt.Println()
 }
 
@@ -55,17 +74,33 @@ func main() {
 	ctx := context.Background()
 
 	// Define command line flags
-	var backend = flag.String("b", "", "LLM backend (ollama, anthropic, openai, or gemini)")
-	var backendLong = flag.String("backend", "", "LLM backend (ollama, anthropic, openai, or gemini)")
+	var backend = flag.String("b", "", "LLM backend (ollama, anthropic, openai, azure, or gemini)")
+	var backendLong = flag.String("backend", "", "LLM backend (ollama, anthropic, openai, azure, or gemini)")
 	var model = flag.String("m", "", "Model name to use")
 	var modelLong = flag.String("model", "", "Model name to use")
 	var workdir = flag.String("workdir", "", "Working directory")
 	var settingsPath = flag.String("settings", "", "Path to settings file")
 	var scenario = flag.String("s", "code", "Scenario to use (default: code)")
 	var scenarioLong = flag.String("scenario", "code", "Scenario to use (default: code)")
+	var autoScenario = flag.Bool("auto-scenario", false, "One-shot mode: let the model pick the scenario for the prompt (-s overrides)")
 	var showLog = flag.Bool("l", false, "Print conversation message history and exit")
 	var showLogLong = flag.Bool("log", false, "Print conversation message history and exit")
+	var sessionName = flag.String("session", "", "Named session to resume or create in interactive mode (default: session)")
+	var continueSession = flag.Bool("continue", false, "One-shot mode: resume the project session (or --session) and save the new turn back to it")
+	var jsonEvents = flag.Bool("json-events", false, "One-shot mode: write agent events as JSON lines to stdout (human output goes to stderr)")
+	var outputFormat = flag.String("output", "text", "One-shot output format: text, or json for a single machine-readable result object on stdout")
+	var schemaPath = flag.String("schema", "", "JSON schema file the respond scenario's answer must conform to")
+	var exportPath = flag.String("export", "", "One-shot and file mode: write a Markdown transcript of the conversation to this path")
+	var offline = flag.Bool("offline", false, "Disable all network tools (WebFetch, WebSearch, HTTP/SSE MCP servers); only the LLM endpoint is contacted")
+	var dryRun = flag.Bool("dry-run", false, "Propose file changes as a patch instead of writing them (bash limited to read-only commands, build and MCP tools refused)")
+	var watchPattern = flag.String("watch", "", "One-shot mode: after the run, re-run the prompt whenever files matching this glob change (e.g. '*.go')")
 	var promptFile = flag.String("f", "", "File containing multi-turn prompts separated by '----' (no memory between turns)")
+	var llmCache = flag.String("llm-cache", "", "Record LLM responses (record) or answer from recorded ones without calling the API (replay)")
+	var llmCacheDir = flag.String("llm-cache-dir", ".gennai/llm-cache", "Directory holding the responses for --llm-cache")
+	var timeLimit = flag.Duration("time-limit", 0, "Stop each run after this wall-clock time (e.g. 10m) and return its partial result (0 = no limit)")
+	var verboseTools = flag.Bool("verbose-tools", false, "Show tool results in full instead of truncated (secrets are still redacted)")
+	var plain = flag.Bool("plain", false, "Print answers as raw Markdown instead of rendering them (also when NO_COLOR is set or stdout is not a terminal)")
+	var noDotEnv = flag.Bool("no-dotenv", false, "Don't load provider API keys from .env in the working directory or ~/.gennai/.env")
 	var verbose = flag.Bool("v", false, "Enable verbose logging (debug level)")
 	var verboseLong = flag.Bool("verbose", false, "Enable verbose logging (debug level)")
 	var help = flag.Bool("h", false, "Show this help message")
@@ -93,16 +128,49 @@ func main() {
 	resolvedBackend := resolveStringFlag(*backend, *backendLong)
 	resolvedModel := resolveStringFlag(*model, *modelLong)
 	resolvedScenario := resolveStringFlag(*scenario, *scenarioLong)
+	scenarioExplicit := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == "s" || f.Name == "scenario" {
+			scenarioExplicit = true
+		}
+	})
 	resolvedShowLog := *showLog || *showLogLong
 	resolvedVerbose := *verbose || *verboseLong
 
 	// Get remaining arguments as the command
 	args := flag.Args()
 
+	// "gennai serve [--addr ADDR] [--allow-remote]" runs the HTTP API instead of a prompt
+	serveMode := len(args) > 0 && args[0] == "serve"
+	serveAddr := defaultServeAddr
+	serveAllowRemote := false
+	if serveMode {
+		serveFlags := flag.NewFlagSet("serve", flag.ExitOnError)
+		addr := serveFlags.String("addr", defaultServeAddr, "Address to listen on (host:port)")
+		allowRemote := serveFlags.Bool("allow-remote", false, "Allow an --addr other machines can reach")
+		_ = serveFlags.Parse(args[1:])
+		serveAddr = *addr
+		serveAllowRemote = *allowRemote
+	}
+
+	// Provider keys may live in .env files; they must be in the environment
+	// before settings validation, secret redaction and client creation
+	var dotEnvLoaded []string
+	if !*noDotEnv {
+		envDir := *workdir
+		if envDir == "" {
+			envDir = "."
+		}
+		var err error
+		if dotEnvLoaded, err = config.LoadDotEnv(envDir); err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️  Warning: failed to load .env: %v\n", err)
+		}
+	}
+
 	// Load settings
 	settings, err := config.LoadSettings(*settingsPath)
 	if err != nil {
-		fmt.Printf("⚠️  Warning: failed to load settings: %v\n", err)
+		fmt.Fprintf(os.Stderr, "⚠️  Warning: failed to load settings: %v\n", err)
 		settings = config.GetDefaultSettings()
 	}
 
@@ -112,8 +180,34 @@ func main() {
 	if resolvedVerbose {
 		logLevel = "debug"
 	}
-	// Use a single writer for console output and ScenarioRunner output
+	// Use a single writer for console output and ScenarioRunner output.
+	// In JSON events and JSON output modes stdout is reserved for machine-readable
+	// output, so human output goes to stderr.
+	jsonOutput := *outputFormat == "json"
 	out := os.Stdout
+	if *jsonEvents || jsonOutput {
+		out = os.Stderr
+	}
+	if settings.Agent.LogFile != "" {
+		fileLevel := settings.Agent.LogFileLevel
+		if fileLevel == "" {
+			fileLevel = "debug"
+		}
+		if err := pkgLogger.ConfigureFile(pkgLogger.FileOptions{
+			Path:       settings.Agent.LogFile,
+			Level:      pkgLogger.LogLevel(fileLevel),
+			MaxSizeMB:  settings.Agent.LogFileMaxSizeMB,
+			MaxBackups: settings.Agent.LogFileMaxBackups,
+		}); err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️  Warning: failed to open log file %s: %v\n", settings.Agent.LogFile, err)
+		}
+	}
+	// Keep credentials out of the console and log file
+	if scrubber, err := settings.Secrets.Scrubber(); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  Warning: secret redaction disabled: %v\n", err)
+	} else if scrubber != nil {
+		pkgLogger.SetRedactor(scrubber.Redact)
+	}
 	pkgLogger.SetGlobalLoggerWithConsoleWriter(pkgLogger.LogLevel(logLevel), out)
 	logger := pkgLogger.NewLoggerWithConsoleWriter(pkgLogger.LogLevel(logLevel), out)
 
@@ -121,6 +215,9 @@ func main() {
 	if resolvedVerbose {
 		logger.DebugWithIntention(pkgLogger.IntentionStatistics, "Verbose logging enabled", "log_level", logLevel)
 	}
+	if len(dotEnvLoaded) > 0 {
+		logger.DebugWithIntention(pkgLogger.IntentionConfig, "Loaded provider variables from .env", "variables", strings.Join(dotEnvLoaded, ","))
+	}
 
 	// Convert scenario to uppercase for case-insensitive matching with YAML files
 	internalScenario := strings.ToUpper(resolvedScenario)
@@ -143,63 +240,115 @@ func main() {
 		settings.LLM.Model = resolvedModel
 	}
 
+	if *offline {
+		settings.Offline = true
+	}
+
 	// Validate settings
 	if err := config.ValidateSettings(settings); err != nil {
 		logger.Error("Settings validation failed", "error", err)
 		os.Exit(1)
 	}
 
-	// Create LLM client based on settings
-	var llmClient domain.LLM
-	switch settings.LLM.Backend {
-	case "anthropic", "claude":
-		llmClient, err = anthropic.NewAnthropicClientWithTokens(settings.LLM.Model, settings.LLM.MaxTokens)
+	// Unknown models still run (clients fall back to a default or, for Ollama,
+	// probe capabilities), but a likely typo is worth pointing out
+	if _, known := models.Lookup(settings.LLM.Backend, settings.LLM.Model); !known {
+		if suggestion := models.Suggest(settings.LLM.Backend, settings.LLM.Model); suggestion != "" {
+			logger.Warn("Model not in known model list, proceeding anyway",
+				"backend", settings.LLM.Backend, "model", settings.LLM.Model)
+			fmt.Fprintf(out, "💡 Did you mean -m %s?\n", suggestion)
+		} else if settings.LLM.Backend != "ollama" && settings.LLM.Backend != "azure" {
+			logger.Warn("Model not in known model list, proceeding anyway; the client may fall back to its default model",
+				"backend", settings.LLM.Backend, "model", settings.LLM.Model)
+		}
+	}
+
+	// API clients and web tools share one transport: HTTP_PROXY/HTTPS_PROXY/NO_PROXY
+	// plus the optional CA bundle. It must be configured before any client exists.
+	if err := httpclient.Configure(httpclient.Options{CABundle: settings.CABundle}); err != nil {
+		logger.Error("Failed to configure HTTP transport", "error", err, "ca_bundle", settings.CABundle)
+		os.Exit(1)
+	}
+
+	if serveMode && (*promptFile != "" || *continueSession || *watchPattern != "" || *jsonEvents || jsonOutput || *llmCache != "") {
+		logger.Error("serve cannot be combined with -f, --continue, --watch, --json-events, --output json or --llm-cache")
+		os.Exit(1)
+	}
+
+	var llmCacheMode client.LLMCacheMode
+	if *llmCache != "" {
+		llmCacheMode, err = client.ParseLLMCacheMode(*llmCache)
 		if err != nil {
-			logger.Error("Failed to create Anthropic client", "error", err)
+			logger.Error("Invalid --llm-cache", "error", err)
 			os.Exit(1)
 		}
-	case "openai":
-		llmClient, err = openai.NewOpenAIClient(settings.LLM.Model, settings.LLM.MaxTokens)
-		if err != nil {
-			logger.Error("Failed to create OpenAI client", "error", err)
+	}
+
+	if *continueSession && (len(args) == 0 || *promptFile != "") {
+		logger.Error("--continue requires a one-shot command argument (interactive mode always resumes the session)")
+		os.Exit(1)
+	}
+
+	if *watchPattern != "" && (len(args) == 0 || *promptFile != "" || *jsonEvents || jsonOutput) {
+		logger.Error("--watch requires a one-shot command argument and cannot be combined with -f, --json-events or --output json")
+		os.Exit(1)
+	}
+
+	if *autoScenario && (len(args) == 0 || serveMode || *promptFile != "") {
+		logger.Error("--auto-scenario requires a one-shot command argument and cannot be combined with -f or serve")
+		os.Exit(1)
+	}
+
+	if *timeLimit < 0 {
+		logger.Error("--time-limit must not be negative", "time_limit", *timeLimit)
+		os.Exit(1)
+	}
+
+	if *jsonEvents && len(args) == 0 {
+		logger.Error("--json-events requires a one-shot command argument")
+		os.Exit(1)
+	}
+
+	if *outputFormat != "text" && !jsonOutput {
+		logger.Error("Unsupported --output format (must be 'text' or 'json')", "output", *outputFormat)
+		os.Exit(1)
+	}
+	if jsonOutput && (len(args) == 0 || *jsonEvents) {
+		logger.Error("--output json requires a one-shot command argument and cannot be combined with --json-events")
+		os.Exit(1)
+	}
+
+	var responseSchema json.RawMessage
+	if *schemaPath != "" {
+		if internalScenario != "RESPOND" {
+			logger.Error("--schema is only supported with the respond scenario (-s respond)")
 			os.Exit(1)
 		}
-	case "gemini":
-		llmClient, err = gemini.NewGeminiClientWithTokens(settings.LLM.Model, settings.LLM.MaxTokens)
+		responseSchema, err = loadResponseSchema(*schemaPath)
 		if err != nil {
-			logger.Error("Failed to create Gemini client", "error", err)
+			logger.Error("Failed to load response schema", "error", err)
 			os.Exit(1)
 		}
-	default:
-		// For Ollama, check if model is in known list, if not, test capability
-		if !ollama.IsModelInKnownList(settings.LLM.Model) {
-			logger.Warn("Model not in known capabilities list, testing tool calling capability",
-				"model", settings.LLM.Model)
-
-			// Test model capability with a 30-second timeout
-			testCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
-			defer cancel()
-
-			hasToolCapability, testErr := ollama.DynamicCapabilityCheck(testCtx, settings.LLM.Model, false)
-			if testErr != nil {
-				logger.Warn("Failed to test model capability, proceeding without tool support",
-					"model", settings.LLM.Model, "error", testErr)
-			} else if !hasToolCapability {
-				logger.Warn("Model does not support tool calling - limited functionality",
-					"model", settings.LLM.Model, "suggestion", "consider using 'gpt-oss:latest'")
-			} else {
-				logger.InfoWithIntention(pkgLogger.IntentionSuccess, "Model supports tool calling, proceeding with full functionality",
-					"model", settings.LLM.Model)
-			}
-		}
+	}
 
-		llmClient, err = ollama.NewOllamaClient(settings.LLM.Model, settings.LLM.MaxTokens, settings.LLM.Thinking)
-		if err != nil {
-			logger.Error("Failed to create Ollama client", "error", err)
+	if *sessionName != "" {
+		if err := config.ValidateSessionName(*sessionName); err != nil {
+			logger.Error("Invalid session name", "error", err)
 			os.Exit(1)
 		}
 	}
 
+	// Create LLM client based on settings
+	llmClient, err := app.NewLLMClient(ctx, settings.LLM, logger)
+	if err != nil {
+		logger.Error("Failed to create LLM client", "error", err)
+		os.Exit(1)
+	}
+	if llmCacheMode != "" {
+		llmClient = client.NewCachingLLM(llmClient, client.NewDiskCacheStore(*llmCacheDir), llmCacheMode)
+		logger.InfoWithIntention(pkgLogger.IntentionConfig, "LLM response cache enabled", "mode", llmCacheMode, "dir", *llmCacheDir)
+	}
+
 	// Determine working directory (don't change process cwd, just pass to tools)
 	workingDirectory := *workdir
 	if workingDirectory != "" {
@@ -209,16 +358,20 @@ func main() {
 				"directory", workingDirectory, "error", err)
 			os.Exit(1)
 		}
-		fmt.Printf("Working directory: %s\n", workingDirectory)
+		fmt.Fprintf(out, "Working directory: %s\n", workingDirectory)
 	} else {
 		workingDirectory = "." // current directory
 	}
 
+	if settings.Offline {
+		logger.InfoWithIntention(pkgLogger.IntentionConfig, "Offline mode: web tools and HTTP/SSE MCP servers are disabled")
+	}
+
 	// Initialize MCP integration if any servers are enabled
 	var mcpIntegration *mcp.Integration
 	if hasEnabledMCPServers(settings.MCP.Servers) {
-		fmt.Println("🔌 Initializing MCP Integration...")
-		mcpIntegration = initializeMCP(ctx, settings.MCP, logger)
+		fmt.Fprintln(out, "🔌 Initializing MCP Integration...")
+		mcpIntegration = initializeMCP(ctx, settings.MCP, settings.Offline, logger)
 		if mcpIntegration != nil {
 			defer mcpIntegration.Close()
 		}
@@ -227,29 +380,60 @@ func main() {
 	// Create shared FilesystemRepository instance at application level
 	fsRepo := infra.NewOSFilesystemRepository()
 
+	// Register each connected MCP server by name for scenario configs
+	mcpToolManagers := make(map[string]domain.ToolManager)
+	if mcpIntegration != nil {
+		toolManager := mcpIntegration.GetToolManager()
+		for _, serverName := range mcpIntegration.ListServers() {
+			mcpToolManagers[serverName] = toolManager
+		}
+	}
+
+	if serveMode {
+		if err := runServer(ctx, serveAddr, serveAllowRemote, settings, mcpToolManagers, workingDirectory, fsRepo, internalScenario, logger, out); err != nil {
+			logger.Error("Server failed", "error", err, "addr", serveAddr)
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Initialize the scenario runner with optional MCP tool manager and additional scenarios
-	var a *app.ScenarioRunner
 	// Skip session restoration for file mode (-f flag) to ensure clean isolated tests
 	skipSessionRestore := (*promptFile != "")
 	// Determine if we're in interactive mode (affects project directory usage)
 	isInteractiveMode := len(args) == 0 && *promptFile == ""
 
-	if mcpIntegration != nil {
-		toolManager := mcpIntegration.GetToolManager()
-		mcpToolManagers := map[string]domain.ToolManager{}
+	a := app.NewScenarioRunnerWithOptions(llmClient, workingDirectory, mcpToolManagers, settings, logger, out, skipSessionRestore, isInteractiveMode, *sessionName, fsRepo)
 
-		// Register each connected MCP server by name for scenario configs
-		serverNames := mcpIntegration.ListServers()
-		for _, serverName := range serverNames {
-			mcpToolManagers[serverName] = toolManager
+	if *continueSession {
+		if err := a.ContinueSession(); err != nil {
+			logger.Error("Failed to continue session", "error", err)
+			os.Exit(1)
+		}
+	}
+	if *dryRun {
+		a.SetDryRun(true)
+	}
+	if *verboseTools {
+		a.SetVerboseTools(true)
+	}
+	if *timeLimit > 0 {
+		a.SetTimeLimit(*timeLimit)
+	}
+	// Render Markdown answers only for a person at a terminal; JSON output and
+	// pipes get the model's text unchanged
+	if !*plain && os.Getenv("NO_COLOR") == "" && !*jsonEvents && !jsonOutput && term.IsTerminal(int(os.Stdout.Fd())) {
+		a.SetMarkdownOutput(true)
+	}
+	if responseSchema != nil {
+		a.SetResponseSchema(responseSchema)
+	}
+	if *exportPath != "" {
+		if isInteractiveMode {
+			fmt.Fprintln(out, "💡 --export applies to one-shot and file mode; use /export PATH in interactive mode")
+		} else {
+			a.SetExportPath(*exportPath)
 		}
-
-		a = app.NewScenarioRunnerWithOptions(llmClient, workingDirectory, mcpToolManagers, settings, logger, out, skipSessionRestore, isInteractiveMode, fsRepo)
-	} else {
-		mcpToolManagers := make(map[string]domain.ToolManager)
-		a = app.NewScenarioRunnerWithOptions(llmClient, workingDirectory, mcpToolManagers, settings, logger, out, skipSessionRestore, isInteractiveMode, fsRepo)
-
-		// Note: SimpleToolManager removed - tools now managed by specialized managers
 	}
 
 	// Using built-in scenarios only
@@ -269,8 +453,20 @@ func main() {
 		return
 	}
 
+	// Let the model pick the scenario unless one was given with -s
+	if *autoScenario && !scenarioExplicit {
+		selection, err := a.SelectScenario(ctx, strings.Join(args, " "))
+		if err != nil {
+			logger.Warn("Scenario selection failed; using the default scenario", "error", err)
+		} else {
+			internalScenario = selection.Action
+			resolvedScenario = strings.ToLower(selection.Action)
+			fmt.Fprintf(out, "🧭 Selected scenario: %s (%s)\n", resolvedScenario, selection.Reasoning)
+		}
+	}
+
 	// Show which scenario is being used
-	fmt.Printf("📋 Using scenario: %s (%s)\n", resolvedScenario, internalScenario)
+	fmt.Fprintf(out, "📋 Using scenario: %s (%s)\n", resolvedScenario, internalScenario)
 
 	// Handle multi-turn prompt file if specified
 	if *promptFile != "" {
@@ -282,6 +478,18 @@ func main() {
 	if len(args) > 0 {
 		// One-shot mode: execute single command and exit
 		userInput := strings.Join(args, " ")
+		if *jsonEvents {
+			executeCommandWithJSONEvents(ctx, a, userInput, internalScenario)
+			return
+		}
+		if jsonOutput {
+			executeCommandWithJSONOutput(ctx, a, userInput, internalScenario)
+			return
+		}
+		if *watchPattern != "" {
+			executeWatch(ctx, a, userInput, internalScenario, *watchPattern)
+			return
+		}
 		executeCommand(ctx, a, userInput, internalScenario)
 	} else {
 		// Interactive mode: start REPL
@@ -289,6 +497,19 @@ func main() {
 	}
 }
 
+// loadResponseSchema reads a JSON schema file for --schema
+func loadResponseSchema(path string) (json.RawMessage, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var schema map[string]any
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, fmt.Errorf("%s is not a JSON schema object: %w", path, err)
+	}
+	return json.RawMessage(data), nil
+}
+
 func executeCommand(ctx context.Context, a *app.ScenarioRunner, userInput string, scenario string) {
 	fmt.Print("\n")
 
@@ -303,11 +524,156 @@ func executeCommand(ctx context.Context, a *app.ScenarioRunner, userInput string
 		os.Exit(1)
 	}
 
-	// Print plain header + content via ScenarioRunner writer
+	// Print plain header + content via ScenarioRunner writer (unless already streamed)
+	a.WriteResponse(a.OutWriter(), response)
+
+	// Timing goes to stderr so piped output stays the answer alone
+	stats := a.LastRunStats()
+	fmt.Fprintf(os.Stderr, "\n⏱️ %s total: %s\n", stats.Elapsed.Round(100*time.Millisecond), stats.Latency.Summary())
+}
+
+// executeWatch runs a one-shot command, then re-runs it each time files matching
+// pattern change, until Ctrl+C. Runs share the session so each one sees the
+// previous answers. A failed run is reported and the watch continues.
+func executeWatch(ctx context.Context, a *app.ScenarioRunner, userInput string, scenario string, pattern string) {
+	watcher, err := app.NewFileWatcher(a.WorkingDir(), pattern)
+	if err != nil {
+		fmt.Printf("❌ Invalid --watch pattern: %v\n", err)
+		os.Exit(1)
+	}
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
 	w := a.OutWriter()
-	model := a.GetLLMClient().ModelID()
-	app.WriteResponseHeader(w, model, false)
-	fmt.Fprintln(w, response.Content())
+	for run := 1; ; run++ {
+		if run > 1 {
+			fmt.Fprintf(w, "\n%s\n🔁 Run %d\n", strings.Repeat("─", 60), run)
+		}
+		response, err := a.Invoke(ctx, userInput, scenario)
+		if ctx.Err() != nil {
+			fmt.Fprintln(w, "\n👋 Watch stopped")
+			return
+		}
+		if err != nil {
+			fmt.Fprintf(w, "❌ Command execution failed: %v\n", err)
+		} else {
+			a.WriteResponse(w, response)
+		}
+
+		fmt.Fprintf(w, "\n👀 Watching %s for changes (Ctrl+C to exit)...\n", watcher.Pattern())
+		changed, err := watcher.WaitForChange(ctx)
+		if err != nil {
+			fmt.Fprintln(w, "\n👋 Watch stopped")
+			return
+		}
+		fmt.Fprintf(w, "📝 Changed: %s\n", strings.Join(changed, ", "))
+	}
+}
+
+// executeCommandWithJSONEvents runs a one-shot command, writing each agent event as
+// one JSON object per line to stdout. The final answer arrives as a "response"
+// event; a failure is reported as an "error" event and a non-zero exit code.
+func executeCommandWithJSONEvents(ctx context.Context, a *app.ScenarioRunner, userInput string, scenario string) {
+	encoder := json.NewEncoder(os.Stdout)
+	eventCh := make(chan events.AgentEvent)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for event := range eventCh {
+			if err := encoder.Encode(event); err != nil {
+				fmt.Fprintf(os.Stderr, "failed to encode event %s: %v\n", event.Type, err)
+			}
+		}
+	}()
+
+	a.StreamEvents(eventCh)
+	_, err := a.Invoke(ctx, userInput, scenario)
+	a.StreamEvents(nil)
+	close(eventCh)
+	<-done
+
+	if err != nil {
+		_ = encoder.Encode(events.AgentEvent{
+			Type:      events.EventTypeError,
+			Timestamp: time.Now(),
+			Data:      events.ErrorData{Error: err, Context: "command execution failed"},
+		})
+		os.Exit(1)
+	}
+}
+
+// commandResult is the object written by --output json
+type commandResult struct {
+	Content   string      `json:"content"`
+	Model     string      `json:"model"`
+	Scenario  string      `json:"scenario"`
+	Usage     resultUsage `json:"usage"`
+	ToolCalls int         `json:"tool_calls"`
+	Latency   resultTime  `json:"latency"`
+	Stopped   string      `json:"stopped,omitempty"` // why the content is a partial result ("max_iterations", "time_limit")
+	Error     string      `json:"error,omitempty"`
+	ErrorKind string      `json:"error_kind,omitempty"` // failure mode of error ("llm_auth", "context_overflow", "tool_failure", "cancelled")
+}
+
+type resultUsage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+	TotalTokens  int `json:"total_tokens"`
+}
+
+// resultTime is the run's wall-clock time and how much of it went to the LLM and tools
+type resultTime struct {
+	ElapsedMs int64            `json:"elapsed_ms"`
+	LLMMs     int64            `json:"llm_ms"`
+	ToolMs    int64            `json:"tool_ms"`
+	ByToolMs  map[string]int64 `json:"by_tool_ms,omitempty"`
+}
+
+// executeCommandWithJSONOutput runs a one-shot command and writes a single JSON
+// object with the final answer and run statistics to stdout. Progress output
+// goes to stderr; a failure sets the error field and a non-zero exit code.
+func executeCommandWithJSONOutput(ctx context.Context, a *app.ScenarioRunner, userInput string, scenario string) {
+	response, err := a.Invoke(ctx, userInput, scenario)
+
+	stats := a.LastRunStats()
+	result := commandResult{
+		Model:    a.GetLLMClient().ModelID(),
+		Scenario: scenario,
+		Usage: resultUsage{
+			InputTokens:  stats.Usage.InputTokens,
+			OutputTokens: stats.Usage.OutputTokens,
+			TotalTokens:  stats.Usage.TotalTokens,
+		},
+		ToolCalls: stats.ToolCalls,
+		Latency: resultTime{
+			ElapsedMs: stats.Elapsed.Milliseconds(),
+			LLMMs:     stats.Latency.LLMTime.Milliseconds(),
+			ToolMs:    stats.Latency.ToolTime.Milliseconds(),
+		},
+	}
+	for name, t := range stats.Latency.ByTool {
+		if result.Latency.ByToolMs == nil {
+			result.Latency.ByToolMs = make(map[string]int64)
+		}
+		result.Latency.ByToolMs[name] = t.Time.Milliseconds()
+	}
+	if err != nil {
+		result.Error = err.Error()
+		result.ErrorKind = domain.ErrorKind(err)
+	} else {
+		result.Content = response.Content()
+		result.Stopped = domain.ErrorKind(stats.Stopped)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if encErr := encoder.Encode(result); encErr != nil {
+		fmt.Fprintf(os.Stderr, "failed to encode result: %v\n", encErr)
+		os.Exit(1)
+	}
+	if err != nil {
+		os.Exit(1)
+	}
 }
 
 func executeMultiTurnFile(ctx context.Context, a *app.ScenarioRunner, filePath string, scenario string) {
@@ -350,9 +716,7 @@ func executeMultiTurnFile(ctx context.Context, a *app.ScenarioRunner, filePath s
 		}
 
 		w := a.OutWriter()
-		model := a.GetLLMClient().ModelID()
-		app.WriteResponseHeader(w, model, false)
-		fmt.Fprintln(w, response.Content())
+		a.WriteResponse(w, response)
 		fmt.Fprintf(w, "%s\n\n", strings.Repeat("─", 60))
 	}
 
@@ -369,9 +733,11 @@ func hasEnabledMCPServers(servers []domain.MCPServerConfig) bool {
 	return false
 }
 
-// initializeMCP initializes MCP integration with enabled servers from settings
-func initializeMCP(ctx context.Context, mcpSettings config.MCPSettings, logger *pkgLogger.Logger) *mcp.Integration {
+// initializeMCP initializes MCP integration with enabled servers from settings.
+// In offline mode servers reached over the network are skipped.
+func initializeMCP(ctx context.Context, mcpSettings config.MCPSettings, offline bool, logger *pkgLogger.Logger) *mcp.Integration {
 	integration := mcp.NewIntegration()
+	integration.SetPoolOptions(mcpSettings.PoolSize, mcpSettings.MaxConcurrent)
 
 	// Add only enabled servers from settings
 	var connectedServers []string
@@ -382,6 +748,11 @@ func initializeMCP(ctx context.Context, mcpSettings config.MCPSettings, logger *
 		if !serverConfig.Enabled {
 			continue
 		}
+		if offline && serverConfig.IsRemote() {
+			logger.InfoWithIntention(pkgLogger.IntentionConfig, "Skipping remote MCP server in offline mode",
+				"server", serverConfig.Name, "url", serverConfig.URL)
+			continue
+		}
 
 		if err := integration.AddServer(ctx, serverConfig); err != nil {
 			logger.Warn("Failed to connect to MCP server",
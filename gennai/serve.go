@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/fpt/go-gennai-cli/internal/app"
+	"github.com/fpt/go-gennai-cli/internal/config"
+	"github.com/fpt/go-gennai-cli/internal/repository"
+	"github.com/fpt/go-gennai-cli/internal/server"
+	"github.com/fpt/go-gennai-cli/pkg/agent/domain"
+	pkgLogger "github.com/fpt/go-gennai-cli/pkg/logger"
+)
+
+// defaultServeAddr keeps the API on the local machine: it runs tools without
+// approval prompts, like one-shot mode
+const defaultServeAddr = "127.0.0.1:8420"
+
+// runServer serves the agent over HTTP until SIGINT or SIGTERM. Every session
+// gets its own runner, conversation and LLM client. An address other machines
+// can reach needs allowRemote.
+func runServer(ctx context.Context, addr string, allowRemote bool, settings *config.Settings, mcpToolManagers map[string]domain.ToolManager, workingDir string, fsRepo repository.FilesystemRepository, scenario string, logger *pkgLogger.Logger, out io.Writer) error {
+	if !server.IsLoopbackAddr(addr) {
+		if !allowRemote {
+			return fmt.Errorf("%s is reachable from other machines and prompts run tools without approval; pass --allow-remote to serve on it anyway", addr)
+		}
+		fmt.Fprintf(out, "⚠️  Warning: serving on %s, which other machines can reach; anyone with the token can run tools here\n", addr)
+		logger.Warn("Serving on a non-loopback address", "addr", addr)
+	}
+
+	newRunner := func(sessionID string) (server.Runner, error) {
+		// Clients hold the tool manager of the run in progress, so sessions
+		// running at the same time can't share one
+		llmClient, err := app.NewLLMClient(ctx, settings.LLM, logger)
+		if err != nil {
+			return nil, err
+		}
+		sessionSettings := *settings
+		return app.NewScenarioRunnerWithOptions(llmClient, workingDir, mcpToolManagers, &sessionSettings, logger, io.Discard, true, false, "", fsRepo), nil
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	srv := server.New(newRunner, scenario)
+	fmt.Fprintf(out, "🌐 Serving on http://%s (Ctrl+C to stop)\n", addr)
+	fmt.Fprintf(out, "🔑 Token: %s (send \"Authorization: Bearer <token>\")\n", srv.Token())
+	logger.InfoWithIntention(pkgLogger.IntentionStatus, "HTTP server starting", "addr", addr, "working_dir", workingDir, "default_scenario", scenario)
+	return srv.ListenAndServe(ctx, addr)
+}
@@ -3,6 +3,7 @@ module github.com/fpt/go-gennai-cli
 go 1.24.4
 
 require (
+	github.com/BurntSushi/toml v1.5.0
 	github.com/PuerkitoBio/goquery v1.10.3
 	github.com/anthropics/anthropic-sdk-go v1.5.0
 	github.com/chzyer/readline v1.5.1
@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/fpt/go-gennai-cli/pkg/agent/domain"
+	"github.com/fpt/go-gennai-cli/pkg/client/models"
 	"github.com/fpt/go-gennai-cli/pkg/message"
 	"golang.org/x/term"
 )
@@ -45,8 +46,7 @@ func (cd *ContextDisplay) CalculateUsageDetails(messageState domain.State, llmCl
 		totalTokens += estimateTokensFor(msg)
 	}
 
-	// Get estimated context window size based on client type
-	maxTokens = cd.estimateContextWindow(llmClient)
+	maxTokens = cd.ContextWindow(llmClient)
 	if maxTokens <= 0 {
 		return 0, 0, 0
 	}
@@ -61,8 +61,21 @@ func (cd *ContextDisplay) CalculateUsageDetails(messageState domain.State, llmCl
 	return totalTokens, maxTokens, percentage
 }
 
-// estimateContextWindow estimates the context window size based on LLM client type
-func (cd *ContextDisplay) estimateContextWindow(llmClient domain.LLM) int {
+// ContextWindow returns the model's context window as reported by the LLM
+// client or the model registry, falling back to an estimate based on the
+// client type
+func (cd *ContextDisplay) ContextWindow(llmClient domain.LLM) int {
+	if provider, ok := llmClient.(domain.ContextWindowProvider); ok {
+		if window := provider.MaxContextTokens(); window > 0 {
+			return window
+		}
+	}
+	if llmClient != nil {
+		if window := models.ContextWindow(llmClient.ModelID()); window > 0 {
+			return window
+		}
+	}
+
 	clientType := fmt.Sprintf("%T", llmClient)
 
 	switch {
@@ -0,0 +1,207 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/fpt/go-gennai-cli/internal/tool"
+	"github.com/fpt/go-gennai-cli/pkg/message"
+)
+
+// maxDiffPreviewLine is the number of diff lines shown before truncating the preview
+const maxDiffPreviewLine = 200
+
+// pendingChangeDiff renders a colorized unified diff of the changes a pending
+// Write/Edit/MultiEdit/replace_lines/apply_patch/replace_across_files/delete_file tool call would make. It returns "" for other
+// tools or when the proposed content cannot be determined (the tool itself will
+// report errors).
+func (s *ScenarioRunner) pendingChangeDiff(ctx context.Context, pending message.Message) string {
+	toolCall, ok := pending.(*message.ToolCallMessage)
+	if !ok {
+		return ""
+	}
+	args := toolCall.ToolArguments()
+
+	var diffs []string
+	switch toolCall.ToolName() {
+	case "Write":
+		path, _ := args["file_path"].(string)
+		content, _ := args["content"].(string)
+		if path == "" {
+			return ""
+		}
+		current, _ := s.readCurrentContent(ctx, path)
+		diffs = append(diffs, tool.UnifiedDiff(path, current, content))
+	case "Edit":
+		path, _ := args["file_path"].(string)
+		current, err := s.readCurrentContent(ctx, path)
+		if path == "" || err != nil {
+			return ""
+		}
+		proposed, ok := applyEditPreview(current, args)
+		if !ok {
+			return ""
+		}
+		diffs = append(diffs, tool.UnifiedDiff(path, current, proposed))
+	case "replace_lines":
+		path, _ := args["file_path"].(string)
+		current, err := s.readCurrentContent(ctx, path)
+		if path == "" || err != nil {
+			return ""
+		}
+		proposed, ok := replaceLinesPreview(current, args)
+		if !ok {
+			return ""
+		}
+		diffs = append(diffs, tool.UnifiedDiff(path, current, proposed))
+	case "MultiEdit":
+		edits, _ := args["edits"].([]interface{})
+		originals := map[string]string{}
+		proposed := map[string]string{}
+		var order []string
+		for _, raw := range edits {
+			edit, ok := raw.(map[string]interface{})
+			if !ok {
+				return ""
+			}
+			path, _ := edit["file_path"].(string)
+			if _, seen := proposed[path]; !seen {
+				current, err := s.readCurrentContent(ctx, path)
+				if path == "" || err != nil {
+					return ""
+				}
+				originals[path] = current
+				proposed[path] = current
+				order = append(order, path)
+			}
+			next, ok := applyEditPreview(proposed[path], edit)
+			if !ok {
+				return ""
+			}
+			proposed[path] = next
+		}
+		for _, path := range order {
+			diffs = append(diffs, tool.UnifiedDiff(path, originals[path], proposed[path]))
+		}
+	case "apply_patch":
+		patchText, _ := args["patch"].(string)
+		patches, err := tool.ParseUnifiedDiff(patchText)
+		if err != nil {
+			return ""
+		}
+		for _, p := range patches {
+			current, err := s.readCurrentContent(ctx, p.Path())
+			if err != nil {
+				return ""
+			}
+			proposed, err := p.Apply(current)
+			if err != nil {
+				return ""
+			}
+			diffs = append(diffs, tool.UnifiedDiff(p.Path(), current, proposed))
+		}
+	case "delete_file":
+		path, _ := args["file_path"].(string)
+		if isDir, _ := args["directory"].(bool); path == "" || isDir {
+			return ""
+		}
+		current, err := s.readCurrentContent(ctx, path)
+		if err != nil {
+			return ""
+		}
+		diffs = append(diffs, tool.UnifiedDiff(path, current, ""))
+	case "replace_across_files":
+		if s.fsToolManager == nil {
+			return ""
+		}
+		diffs = append(diffs, s.fsToolManager.ReplaceAcrossFilesDiff(ctx, args))
+	default:
+		return ""
+	}
+
+	return colorizeDiff(limitDiffPreview(strings.Join(diffs, "")))
+}
+
+// readCurrentContent reads a file relative to the working directory.
+// A missing file yields empty content so new files show as all-added.
+func (s *ScenarioRunner) readCurrentContent(ctx context.Context, path string) (string, error) {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(s.workingDir, path)
+	}
+	if exists, err := s.fsRepo.Exists(ctx, path); err != nil || !exists {
+		return "", err
+	}
+	data, err := s.fsRepo.ReadFile(ctx, path)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// applyEditPreview mirrors the Edit tool's exact string replacement
+func applyEditPreview(content string, args map[string]interface{}) (string, bool) {
+	oldString, _ := args["old_string"].(string)
+	newString, _ := args["new_string"].(string)
+	replaceAll, _ := args["replace_all"].(bool)
+	if oldString == "" || !strings.Contains(content, oldString) {
+		return "", false
+	}
+	if replaceAll {
+		return strings.ReplaceAll(content, oldString, newString), true
+	}
+	return strings.Replace(content, oldString, newString, 1), true
+}
+
+// replaceLinesPreview mirrors the replace_lines tool's line-range replacement
+func replaceLinesPreview(content string, args map[string]interface{}) (string, bool) {
+	start, _ := args["start_line"].(float64)
+	end, _ := args["end_line"].(float64)
+	newContent, _ := args["new_content"].(string)
+	lines := tool.SplitDiffLines(content)
+	if start < 1 || end < start || int(end) > len(lines) {
+		return "", false
+	}
+	updated := append([]string{}, lines[:int(start)-1]...)
+	updated = append(updated, tool.SplitDiffLines(newContent)...)
+	updated = append(updated, lines[int(end):]...)
+	if len(updated) == 0 {
+		return "", true
+	}
+	return strings.Join(updated, "\n") + "\n", true
+}
+
+// limitDiffPreview keeps the first maxDiffPreviewLine lines of a diff
+func limitDiffPreview(diff string) string {
+	lines := strings.SplitAfter(diff, "\n")
+	if len(lines) <= maxDiffPreviewLine {
+		return diff
+	}
+	omitted := len(lines) - maxDiffPreviewLine
+	return strings.Join(lines[:maxDiffPreviewLine], "") + fmt.Sprintf("... %d more diff lines not shown\n", omitted)
+}
+
+// colorizeDiff applies ANSI colors to unified diff lines
+func colorizeDiff(diff string) string {
+	if diff == "" {
+		return ""
+	}
+	lines := strings.SplitAfter(diff, "\n")
+	var b strings.Builder
+	for _, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+			b.WriteString("\033[1m" + strings.TrimSuffix(line, "\n") + "\033[0m\n")
+		case strings.HasPrefix(line, "@@"):
+			b.WriteString("\033[36m" + strings.TrimSuffix(line, "\n") + "\033[0m\n")
+		case strings.HasPrefix(line, "+"):
+			b.WriteString("\033[32m" + strings.TrimSuffix(line, "\n") + "\033[0m\n")
+		case strings.HasPrefix(line, "-"):
+			b.WriteString("\033[31m" + strings.TrimSuffix(line, "\n") + "\033[0m\n")
+		default:
+			b.WriteString(line)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,16 @@
+package app
+
+import "testing"
+
+func TestApplyEditPreview(t *testing.T) {
+	content := "foo bar foo"
+	if got, ok := applyEditPreview(content, map[string]interface{}{"old_string": "foo", "new_string": "baz"}); !ok || got != "baz bar foo" {
+		t.Errorf("expected single replacement, got %q", got)
+	}
+	if got, ok := applyEditPreview(content, map[string]interface{}{"old_string": "foo", "new_string": "baz", "replace_all": true}); !ok || got != "baz bar baz" {
+		t.Errorf("expected replace_all, got %q", got)
+	}
+	if _, ok := applyEditPreview(content, map[string]interface{}{"old_string": "missing", "new_string": "x"}); ok {
+		t.Error("expected no preview when old_string is absent")
+	}
+}
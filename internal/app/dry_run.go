@@ -0,0 +1,330 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/fpt/go-gennai-cli/internal/repository"
+	"github.com/fpt/go-gennai-cli/internal/tool"
+	"github.com/fpt/go-gennai-cli/pkg/agent/domain"
+	"github.com/fpt/go-gennai-cli/pkg/message"
+)
+
+// proposalSet collects file changes proposed during a dry run. Later proposals
+// for the same file build on earlier ones, so the patch reflects the final content.
+type proposalSet struct {
+	fsRepo     repository.FilesystemRepository
+	workingDir string
+
+	mu        sync.Mutex
+	originals map[string]string
+	proposed  map[string]string
+	order     []string
+}
+
+func newProposalSet(fsRepo repository.FilesystemRepository, workingDir string) *proposalSet {
+	return &proposalSet{
+		fsRepo:     fsRepo,
+		workingDir: workingDir,
+		originals:  make(map[string]string),
+		proposed:   make(map[string]string),
+	}
+}
+
+// relPath keys proposals by working-directory-relative path so the patch applies
+// from the project root; paths outside it stay absolute
+func (p *proposalSet) relPath(path string) string {
+	if path == "" {
+		return ""
+	}
+	if !filepath.IsAbs(path) {
+		return filepath.Clean(path)
+	}
+	root, err := filepath.Abs(p.workingDir)
+	if err != nil {
+		return path
+	}
+	if rel, err := filepath.Rel(root, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return path
+}
+
+// current returns the proposed content of path, loading it from disk on first use
+func (p *proposalSet) current(ctx context.Context, path string) (string, error) {
+	if content, ok := p.proposed[path]; ok {
+		return content, nil
+	}
+	full := path
+	if !filepath.IsAbs(full) {
+		full = filepath.Join(p.workingDir, full)
+	}
+	content := ""
+	if exists, err := p.fsRepo.Exists(ctx, full); err != nil {
+		return "", err
+	} else if exists {
+		data, err := p.fsRepo.ReadFile(ctx, full)
+		if err != nil {
+			return "", err
+		}
+		content = string(data)
+	}
+	p.originals[path] = content
+	p.proposed[path] = content
+	p.order = append(p.order, path)
+	return content, nil
+}
+
+// propose records the change a Write/Edit/MultiEdit/replace_lines/apply_patch call would make
+// and returns the diff of that change
+func (p *proposalSet) propose(ctx context.Context, toolName message.ToolName, args message.ToolArgumentValues) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	type change struct {
+		path  string
+		args  map[string]interface{}
+		patch tool.FilePatch
+	}
+	var changes []change
+	switch toolName {
+	case "apply_patch":
+		patchText, _ := args["patch"].(string)
+		patches, err := tool.ParseUnifiedDiff(patchText)
+		if err != nil {
+			return "", fmt.Errorf("invalid patch: %v", err)
+		}
+		for _, fp := range patches {
+			changes = append(changes, change{path: p.relPath(fp.Path()), patch: fp})
+		}
+	case "MultiEdit":
+		edits, _ := args["edits"].([]interface{})
+		for _, raw := range edits {
+			edit, ok := raw.(map[string]interface{})
+			if !ok {
+				return "", fmt.Errorf("each edit must be an object")
+			}
+			path, _ := edit["file_path"].(string)
+			changes = append(changes, change{path: p.relPath(path), args: edit})
+		}
+	default:
+		path, _ := args["file_path"].(string)
+		changes = append(changes, change{path: p.relPath(path), args: args})
+	}
+
+	// Apply all changes to a scratch copy so a failed edit leaves no partial proposal
+	before := make(map[string]string)
+	after := make(map[string]string)
+	var touched []string
+	for _, c := range changes {
+		if c.path == "" {
+			return "", fmt.Errorf("file_path is required")
+		}
+		if _, ok := after[c.path]; !ok {
+			content, err := p.current(ctx, c.path)
+			if err != nil {
+				return "", fmt.Errorf("failed to read %s: %v", c.path, err)
+			}
+			before[c.path] = content
+			after[c.path] = content
+			touched = append(touched, c.path)
+		}
+
+		var next string
+		var ok bool
+		switch toolName {
+		case "Write":
+			next, ok = c.args["content"].(string)
+		case "Edit", "MultiEdit":
+			next, ok = applyEditPreview(after[c.path], c.args)
+		case "replace_lines":
+			next, ok = replaceLinesPreview(after[c.path], c.args)
+		case "apply_patch":
+			var err error
+			if next, err = c.patch.Apply(after[c.path]); err != nil {
+				return "", err
+			}
+			ok = true
+		}
+		if !ok {
+			return "", fmt.Errorf("could not apply %s to %s: check the arguments against the current (proposed) content", toolName, c.path)
+		}
+		after[c.path] = next
+	}
+
+	var diff strings.Builder
+	for _, path := range touched {
+		p.proposed[path] = after[path]
+		diff.WriteString(tool.UnifiedDiff(path, before[path], after[path]))
+	}
+	return diff.String(), nil
+}
+
+// patch returns a unified diff of every proposed change against the files on disk
+func (p *proposalSet) patch() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var b strings.Builder
+	for _, path := range p.order {
+		b.WriteString(tool.UnifiedDiff(path, p.originals[path], p.proposed[path]))
+	}
+	return b.String()
+}
+
+// reset discards all proposals
+func (p *proposalSet) reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.originals = make(map[string]string)
+	p.proposed = make(map[string]string)
+	p.order = nil
+}
+
+// dryRunToolManager wraps a tool manager so file-changing tools record proposals
+// instead of writing, bash only runs read-only commands, and every other tool
+// that is not read-only is refused
+type dryRunToolManager struct {
+	inner     domain.ToolManager
+	proposals *proposalSet
+	tools     map[message.ToolName]message.Tool
+}
+
+// proposalTools are the write tools whose changes the proposal set can
+// simulate; other tools that declare message.AccessWrite are refused
+var proposalTools = map[message.ToolName]bool{
+	"Write":         true,
+	"Edit":          true,
+	"MultiEdit":     true,
+	"replace_lines": true,
+	"apply_patch":   true,
+}
+
+func newDryRunToolManager(inner domain.ToolManager, proposals *proposalSet) *dryRunToolManager {
+	m := &dryRunToolManager{
+		inner:     inner,
+		proposals: proposals,
+		tools:     make(map[message.ToolName]message.Tool),
+	}
+	for name, t := range inner.GetTools() {
+		switch {
+		case proposalTools[name]:
+			m.tools[name] = &dryRunTool{Tool: t, handler: m.proposalHandler(name),
+				description: "[dry run: records a proposed change, nothing is written] " + t.Description()}
+		case name == "bash":
+			m.tools[name] = &dryRunTool{Tool: t, handler: m.readOnlyBashHandler(name),
+				description: "[dry run: read-only commands only] " + t.Description()}
+		case name == "replace_across_files":
+			m.tools[name] = &dryRunTool{Tool: t, handler: previewOnlyHandler(t.Handler()),
+				description: "[dry run: always previews, nothing is written] " + t.Description()}
+		case name == "undo_last_edit":
+			m.tools[name] = &dryRunTool{Tool: t, handler: undoInDryRun,
+				description: "[dry run: unavailable, nothing is written] " + t.Description()}
+		case message.EffectsOf(t).Access == message.AccessWrite:
+			m.tools[name] = &dryRunTool{Tool: t, handler: writeInDryRun,
+				description: "[dry run: unavailable, nothing is written] " + t.Description()}
+		case message.EffectsOf(t).Access == message.AccessUnknown:
+			m.tools[name] = &dryRunTool{Tool: t, handler: unknownInDryRun,
+				description: "[dry run: unavailable, it may change files] " + t.Description()}
+		default:
+			m.tools[name] = t
+		}
+	}
+	return m
+}
+
+func (m *dryRunToolManager) GetTool(name message.ToolName) (message.Tool, bool) {
+	t, ok := m.tools[name]
+	return t, ok
+}
+
+func (m *dryRunToolManager) GetTools() map[message.ToolName]message.Tool {
+	return m.tools
+}
+
+func (m *dryRunToolManager) CallTool(ctx context.Context, name message.ToolName, args message.ToolArgumentValues) (message.ToolResult, error) {
+	t, ok := m.tools[name]
+	if !ok {
+		return message.NewToolResultError(fmt.Sprintf("tool %s not found", name)), nil
+	}
+	if _, wrapped := t.(*dryRunTool); wrapped {
+		return t.Handler()(ctx, args)
+	}
+	// Delegate read-only tools so the wrapped manager's policies (e.g. timeouts) still apply
+	return m.inner.CallTool(ctx, name, args)
+}
+
+func (m *dryRunToolManager) RegisterTool(name message.ToolName, description message.ToolDescription, args []message.ToolArgument, handler func(ctx context.Context, args message.ToolArgumentValues) (message.ToolResult, error)) {
+	panic("RegisterTool not supported on dryRunToolManager - register on underlying managers instead")
+}
+
+// undoInDryRun refuses undo_last_edit: proposals never touch disk, and undoing
+// edits made before the dry run would
+func undoInDryRun(ctx context.Context, args message.ToolArgumentValues) (message.ToolResult, error) {
+	return message.NewToolResultError("Dry run: undo is unavailable because nothing is written to disk."), nil
+}
+
+// writeInDryRun refuses write tools such as copy_file, move_file and
+// delete_file, whose changes the proposal diff cannot express
+func writeInDryRun(ctx context.Context, args message.ToolArgumentValues) (message.ToolResult, error) {
+	return message.NewToolResultError("Dry run: copying, moving and deleting files is unavailable because nothing is written to disk; describe the change in your answer instead."), nil
+}
+
+// unknownInDryRun refuses tools that may change anything, such as go_run,
+// go_build, run_tests and MCP tools, which declare no narrower effects
+func unknownInDryRun(ctx context.Context, args message.ToolArgumentValues) (message.ToolResult, error) {
+	return message.NewToolResultError("Dry run: this tool is unavailable because it may change files; use read-only tools or bash with read-only commands instead."), nil
+}
+
+func (m *dryRunToolManager) proposalHandler(name message.ToolName) func(ctx context.Context, args message.ToolArgumentValues) (message.ToolResult, error) {
+	return func(ctx context.Context, args message.ToolArgumentValues) (message.ToolResult, error) {
+		diff, err := m.proposals.propose(ctx, name, args)
+		if err != nil {
+			return message.NewToolResultError(err.Error()), nil
+		}
+		if diff == "" {
+			return message.NewToolResultText("Dry run: no changes proposed (content unchanged)."), nil
+		}
+		return message.NewToolResultText("Dry run: change recorded, nothing was written to disk.\n\n" + diff), nil
+	}
+}
+
+// readOnlyBashHandler runs commands that pass the read-only check through the
+// wrapped manager, so its timeouts still apply
+func (m *dryRunToolManager) readOnlyBashHandler(name message.ToolName) func(ctx context.Context, args message.ToolArgumentValues) (message.ToolResult, error) {
+	return func(ctx context.Context, args message.ToolArgumentValues) (message.ToolResult, error) {
+		command, _ := args["command"].(string)
+		if err := tool.CheckReadOnlyCommand(command); err != nil {
+			return message.NewToolResultError(fmt.Sprintf("dry run: %v", err)), nil
+		}
+		return m.inner.CallTool(ctx, name, args)
+	}
+}
+
+// previewOnlyHandler forces dry_run on a tool that can preview its own changes
+func previewOnlyHandler(inner func(ctx context.Context, args message.ToolArgumentValues) (message.ToolResult, error)) func(ctx context.Context, args message.ToolArgumentValues) (message.ToolResult, error) {
+	return func(ctx context.Context, args message.ToolArgumentValues) (message.ToolResult, error) {
+		preview := make(message.ToolArgumentValues, len(args)+1)
+		for k, v := range args {
+			preview[k] = v
+		}
+		preview["dry_run"] = true
+		return inner(ctx, preview)
+	}
+}
+
+// dryRunTool overrides the handler and description of a wrapped tool
+type dryRunTool struct {
+	message.Tool
+	description message.ToolDescription
+	handler     func(ctx context.Context, args message.ToolArgumentValues) (message.ToolResult, error)
+}
+
+func (t *dryRunTool) Description() message.ToolDescription { return t.description }
+
+func (t *dryRunTool) Handler() func(ctx context.Context, args message.ToolArgumentValues) (message.ToolResult, error) {
+	return t.handler
+}
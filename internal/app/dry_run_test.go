@@ -0,0 +1,189 @@
+package app
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fpt/go-gennai-cli/internal/infra"
+	"github.com/fpt/go-gennai-cli/internal/tool"
+	"github.com/fpt/go-gennai-cli/pkg/agent/domain"
+	"github.com/fpt/go-gennai-cli/pkg/message"
+)
+
+func newTestDryRunManager(t *testing.T, workingDir string) (*dryRunToolManager, *proposalSet) {
+	t.Helper()
+	fsRepo := infra.NewOSFilesystemRepository()
+	fsManager := tool.NewFileSystemToolManager(fsRepo, infra.DefaultFileSystemConfig(workingDir), workingDir)
+	bashManager := tool.NewBashToolManager(tool.BashConfig{WorkingDir: workingDir, MaxDuration: 10 * time.Second})
+	proposals := newProposalSet(fsRepo, workingDir)
+	todoManager := tool.NewInMemoryTodoToolManager()
+	// Registered without declared effects, like an MCP tool
+	todoManager.RegisterTool("mcp_deploy", "Deploy the project", nil, func(ctx context.Context, args message.ToolArgumentValues) (message.ToolResult, error) {
+		t.Error("mcp_deploy ran in dry run")
+		return message.NewToolResultText("deployed"), nil
+	})
+	return newDryRunToolManager(tool.NewCompositeToolManager(fsManager, bashManager, todoManager), proposals), proposals
+}
+
+func TestDryRunToolManager_CollectsPatch(t *testing.T) {
+	ctx := context.Background()
+	workingDir := t.TempDir()
+	existing := filepath.Join(workingDir, "main.go")
+	if err := os.WriteFile(existing, []byte("package main\n\nfunc main() {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m, proposals := newTestDryRunManager(t, workingDir)
+
+	res, err := m.CallTool(ctx, "Edit", message.ToolArgumentValues{
+		"file_path":  existing,
+		"old_string": "func main() {}",
+		"new_string": "func main() {\n\trun()\n}",
+	})
+	if err != nil || res.Error != "" {
+		t.Fatalf("Edit failed: %v %s", err, res.Error)
+	}
+	// A second edit builds on the first proposal, not on the file on disk
+	res, _ = m.CallTool(ctx, "Edit", message.ToolArgumentValues{
+		"file_path":  existing,
+		"old_string": "\trun()",
+		"new_string": "\trun(ctx)",
+	})
+	if res.Error != "" {
+		t.Fatalf("chained Edit failed: %s", res.Error)
+	}
+	res, _ = m.CallTool(ctx, "Write", message.ToolArgumentValues{
+		"file_path": filepath.Join(workingDir, "pkg", "new.go"),
+		"content":   "package pkg\n",
+	})
+	if res.Error != "" {
+		t.Fatalf("Write failed: %s", res.Error)
+	}
+
+	data, _ := os.ReadFile(existing)
+	if string(data) != "package main\n\nfunc main() {}\n" {
+		t.Errorf("dry run modified the file on disk: %q", data)
+	}
+	if _, err := os.Stat(filepath.Join(workingDir, "pkg", "new.go")); !os.IsNotExist(err) {
+		t.Errorf("dry run created a file on disk")
+	}
+
+	patch := proposals.patch()
+	for _, want := range []string{"--- a/main.go", "+++ b/main.go", "+\trun(ctx)", "--- /dev/null", "+++ b/pkg/new.go", "+package pkg"} {
+		if !strings.Contains(patch, want) {
+			t.Errorf("patch missing %q:\n%s", want, patch)
+		}
+	}
+	if strings.Contains(patch, "+\trun()") {
+		t.Errorf("patch should contain only the final content:\n%s", patch)
+	}
+
+	proposals.reset()
+	if got := proposals.patch(); got != "" {
+		t.Errorf("expected empty patch after reset, got:\n%s", got)
+	}
+}
+
+func TestDryRunToolManager_FailedEditLeavesNoProposal(t *testing.T) {
+	workingDir := t.TempDir()
+	path := filepath.Join(workingDir, "a.txt")
+	if err := os.WriteFile(path, []byte("hello\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m, proposals := newTestDryRunManager(t, workingDir)
+
+	res, _ := m.CallTool(context.Background(), "Edit", message.ToolArgumentValues{
+		"file_path":  path,
+		"old_string": "missing",
+		"new_string": "x",
+	})
+	if res.Error == "" {
+		t.Fatal("expected an error for an edit that does not apply")
+	}
+	if got := proposals.patch(); got != "" {
+		t.Errorf("expected no proposal, got:\n%s", got)
+	}
+}
+
+func TestDryRunToolManager_ReadOnlyBashAndDelegation(t *testing.T) {
+	ctx := context.Background()
+	workingDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(workingDir, "keep.txt"), []byte("data\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m, _ := newTestDryRunManager(t, workingDir)
+
+	res, _ := m.CallTool(ctx, "bash", message.ToolArgumentValues{"command": "rm keep.txt"})
+	if res.Error == "" {
+		t.Error("expected rm to be refused in dry run")
+	}
+	if _, err := os.Stat(filepath.Join(workingDir, "keep.txt")); err != nil {
+		t.Errorf("file was removed: %v", err)
+	}
+
+	res, _ = m.CallTool(ctx, "bash", message.ToolArgumentValues{"command": "ls"})
+	if res.Error != "" || !strings.Contains(res.Text, "keep.txt") {
+		t.Errorf("expected ls to run, got text=%q error=%q", res.Text, res.Error)
+	}
+
+	res, _ = m.CallTool(ctx, "delete_file", message.ToolArgumentValues{"file_path": filepath.Join(workingDir, "keep.txt")})
+	if res.Error == "" {
+		t.Error("expected delete_file to be refused in dry run")
+	}
+	if _, err := os.Stat(filepath.Join(workingDir, "keep.txt")); err != nil {
+		t.Errorf("file was deleted: %v", err)
+	}
+
+	for _, name := range []message.ToolName{"go_run", "go_build", "run_tests", "Task", "mcp_deploy"} {
+		res, _ = m.CallTool(ctx, name, message.ToolArgumentValues{"path": ".", "description": "x", "prompt": "x"})
+		if !strings.Contains(res.Error, "Dry run") {
+			t.Errorf("expected %s to be refused in dry run, got text=%q error=%q", name, res.Text, res.Error)
+		}
+	}
+
+	res, _ = m.CallTool(ctx, "todo_write", message.ToolArgumentValues{"todos": []interface{}{}})
+	if strings.Contains(res.Error, "Dry run") {
+		t.Errorf("expected todo_write to delegate, got error=%q", res.Error)
+	}
+
+	res, _ = m.CallTool(ctx, "Read", message.ToolArgumentValues{"file_path": filepath.Join(workingDir, "keep.txt")})
+	if res.Error != "" || !strings.Contains(res.Text, "data") {
+		t.Errorf("expected Read to delegate, got text=%q error=%q", res.Text, res.Error)
+	}
+
+	writeTool, ok := m.GetTool("Write")
+	if !ok || !strings.HasPrefix(string(writeTool.Description()), "[dry run") {
+		t.Errorf("expected Write description to mention dry run")
+	}
+}
+
+// countingToolManager records the calls that reach the wrapped manager
+type countingToolManager struct {
+	domain.ToolManager
+	calls []message.ToolName
+}
+
+func (c *countingToolManager) CallTool(ctx context.Context, name message.ToolName, args message.ToolArgumentValues) (message.ToolResult, error) {
+	c.calls = append(c.calls, name)
+	return c.ToolManager.CallTool(ctx, name, args)
+}
+
+func TestDryRunToolManager_ReadOnlyBashRunsThroughInnerManager(t *testing.T) {
+	workingDir := t.TempDir()
+	bashManager := tool.NewBashToolManager(tool.BashConfig{WorkingDir: workingDir, MaxDuration: 10 * time.Second})
+	inner := &countingToolManager{ToolManager: tool.NewCompositeToolManager(bashManager)}
+	m := newDryRunToolManager(inner, newProposalSet(infra.NewOSFilesystemRepository(), workingDir))
+
+	bash, _ := m.GetTool("bash")
+	res, _ := bash.Handler()(context.Background(), message.ToolArgumentValues{"command": "pwd"})
+	if res.Error != "" {
+		t.Fatalf("expected pwd to run, got error=%q", res.Error)
+	}
+	m.CallTool(context.Background(), "bash", message.ToolArgumentValues{"command": "touch x"})
+	if len(inner.calls) != 1 || inner.calls[0] != "bash" {
+		t.Errorf("expected only the read-only command to reach the inner manager, got %v", inner.calls)
+	}
+}
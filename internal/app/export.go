@@ -0,0 +1,197 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fpt/go-gennai-cli/pkg/message"
+)
+
+// Tool arguments that name files; a blacklisted file in any of them redacts the call
+var transcriptPathArgs = []string{"file_path", "path", "paths"}
+
+// SetExportPath makes every invocation rewrite a Markdown transcript of the
+// conversation at path. Pass "" to turn it off.
+func (s *ScenarioRunner) SetExportPath(path string) {
+	s.exportPath = path
+}
+
+// autoExportTranscript writes the transcript configured with SetExportPath
+func (s *ScenarioRunner) autoExportTranscript() {
+	if s.exportPath == "" {
+		return
+	}
+	if err := s.ExportTranscript(s.exportPath); err != nil {
+		s.logger.Warn("Failed to export transcript", "path", s.exportPath, "error", err)
+	}
+}
+
+// ExportTranscript writes the conversation as a Markdown transcript meant for
+// sharing: user and assistant turns, thinking in collapsible sections, and tool
+// calls and results in fenced code blocks. Tool calls that touch blacklisted
+// files have their arguments and results redacted.
+func (s *ScenarioRunner) ExportTranscript(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create transcript: %w", err)
+	}
+	defer f.Close()
+
+	backend, model := s.CurrentModel()
+	header := transcriptHeader{
+		Model:      model,
+		Backend:    backend,
+		WorkingDir: s.workingDir,
+		Session:    s.SessionName(),
+		Exported:   time.Now(),
+	}
+	if err := writeTranscript(f, header, s.sharedState.GetMessages(), s.isBlacklistedPath); err != nil {
+		return fmt.Errorf("failed to write transcript: %w", err)
+	}
+	return f.Close()
+}
+
+// isBlacklistedPath reports whether the filesystem tools refuse to read path
+func (s *ScenarioRunner) isBlacklistedPath(path string) bool {
+	return s.fsToolManager != nil && s.fsToolManager.IsBlacklisted(path)
+}
+
+// transcriptHeader is the metadata listed at the top of a transcript
+type transcriptHeader struct {
+	Model, Backend, WorkingDir, Session string
+	Exported                            time.Time
+}
+
+// writeTranscript renders messages as Markdown. System prompts are omitted;
+// summaries of compacted history are kept so the transcript reads continuously.
+func writeTranscript(w io.Writer, header transcriptHeader, messages []message.Message, blacklisted func(path string) bool) error {
+	var b strings.Builder
+	b.WriteString("# gennai transcript\n\n")
+	fmt.Fprintf(&b, "- Model: %s (%s)\n", header.Model, header.Backend)
+	if header.Session != "" {
+		fmt.Fprintf(&b, "- Session: %s\n", header.Session)
+	}
+	fmt.Fprintf(&b, "- Working directory: %s\n", header.WorkingDir)
+	fmt.Fprintf(&b, "- Exported: %s\n", header.Exported.Format(time.RFC3339))
+
+	// Results are matched to their calls by ID, so redaction carries over
+	redactedCalls := make(map[string]string)
+	writeCall := func(call *message.ToolCallMessage) {
+		fmt.Fprintf(&b, "\n**🔧 Tool call: `%s`**\n\n", call.ToolName())
+		if path := blacklistedArg(call.ToolArguments(), blacklisted); path != "" {
+			redactedCalls[call.ID()] = path
+			fmt.Fprintf(&b, "_Arguments redacted: %s is a blacklisted file._\n", path)
+			return
+		}
+		args, err := json.MarshalIndent(call.ToolArguments(), "", "  ")
+		if err != nil {
+			args = []byte(fmt.Sprintf("%v", call.ToolArguments()))
+		}
+		writeFenced(&b, "json", string(args))
+	}
+
+	for _, msg := range messages {
+		switch m := msg.(type) {
+		case *message.ToolCallMessage:
+			writeThinking(&b, m.Thinking())
+			writeCall(m)
+		case *message.ToolCallBatchMessage:
+			writeThinking(&b, m.Thinking())
+			for _, call := range m.Calls() {
+				writeCall(call)
+			}
+		case *message.ToolResultMessage:
+			if path, ok := redactedCalls[m.ID()]; ok {
+				fmt.Fprintf(&b, "\n_Result redacted: %s is a blacklisted file._\n", path)
+				continue
+			}
+			if m.Error != "" {
+				b.WriteString("\nResult (error):\n\n")
+				writeFenced(&b, "", m.Error)
+			} else {
+				b.WriteString("\nResult:\n\n")
+				writeFenced(&b, "", m.Result)
+			}
+		default:
+			switch msg.Type() {
+			case message.MessageTypeUser:
+				b.WriteString("\n## 👤 User\n\n")
+				b.WriteString(strings.TrimSpace(msg.Content()) + "\n")
+				if n := len(msg.Images()); n > 0 {
+					fmt.Fprintf(&b, "\n_%d image(s) attached_\n", n)
+				}
+			case message.MessageTypeAssistant:
+				b.WriteString("\n## 🤖 Assistant\n")
+				writeThinking(&b, msg.Thinking())
+				if content := strings.TrimSpace(msg.Content()); content != "" {
+					b.WriteString("\n" + content + "\n")
+				}
+			case message.MessageTypeReasoning:
+				writeThinking(&b, msg.Content())
+			case message.MessageTypeSystem:
+				if msg.Source() == message.MessageSourceSummary {
+					b.WriteString("\n## 📝 Summary of earlier conversation\n\n")
+					b.WriteString(strings.TrimSpace(msg.Content()) + "\n")
+				}
+			}
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// blacklistedArg returns the first blacklisted file named by a tool call's
+// arguments, including files mentioned in a bash command
+func blacklistedArg(args message.ToolArgumentValues, blacklisted func(path string) bool) string {
+	var candidates []string
+	for _, key := range transcriptPathArgs {
+		switch v := args[key].(type) {
+		case string:
+			candidates = append(candidates, v)
+		case []any:
+			for _, item := range v {
+				if s, ok := item.(string); ok {
+					candidates = append(candidates, s)
+				}
+			}
+		}
+	}
+	if command, ok := args["command"].(string); ok {
+		for _, field := range strings.Fields(command) {
+			candidates = append(candidates, strings.Trim(field, `"'`))
+		}
+	}
+	for _, candidate := range candidates {
+		if candidate != "" && blacklisted(candidate) {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// writeThinking writes thinking as a collapsed <details> section
+func writeThinking(b *strings.Builder, thinking string) {
+	thinking = strings.TrimSpace(thinking)
+	if thinking == "" {
+		return
+	}
+	b.WriteString("\n<details>\n<summary>💭 Thinking</summary>\n\n")
+	b.WriteString(thinking)
+	b.WriteString("\n\n</details>\n")
+}
+
+// writeFenced writes content in a code fence longer than any backtick run inside it
+func writeFenced(b *strings.Builder, lang, content string) {
+	fence := "```"
+	for strings.Contains(content, fence) {
+		fence += "`"
+	}
+	b.WriteString(fence + lang + "\n")
+	b.WriteString(strings.TrimRight(content, "\n"))
+	b.WriteString("\n" + fence + "\n")
+}
@@ -0,0 +1,60 @@
+package app
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fpt/go-gennai-cli/internal/infra"
+	"github.com/fpt/go-gennai-cli/internal/tool"
+	"github.com/fpt/go-gennai-cli/pkg/message"
+)
+
+func TestWriteTranscript(t *testing.T) {
+	workingDir := t.TempDir()
+	fsManager := tool.NewFileSystemToolManager(infra.NewOSFilesystemRepository(), infra.DefaultFileSystemConfig(workingDir), workingDir)
+
+	readMain := message.NewToolCallMessage("Read", message.ToolArgumentValues{"file_path": "main.go"})
+	readEnv := message.NewToolCallMessage("Read", message.ToolArgumentValues{"file_path": ".env"})
+	catEnv := message.NewToolCallMessage("Bash", message.ToolArgumentValues{"command": "cat .env"})
+	messages := []message.Message{
+		message.NewSystemMessage("scenario prompt that should not be exported"),
+		message.NewChatMessage(message.MessageTypeUser, "What does main.go do?"),
+		readMain,
+		message.NewToolResultMessage(readMain.ID(), "package main\n```\n", ""),
+		readEnv,
+		message.NewToolResultMessage(readEnv.ID(), "API_KEY=supersecret", ""),
+		catEnv,
+		message.NewToolResultMessage(catEnv.ID(), "API_KEY=supersecret", ""),
+		message.NewChatMessageWithThinking(message.MessageTypeAssistant, "It is an empty program.", "Looked at the file."),
+	}
+
+	var b strings.Builder
+	header := transcriptHeader{Model: "test-model", Backend: "test", WorkingDir: workingDir, Exported: time.Now()}
+	if err := writeTranscript(&b, header, messages, fsManager.IsBlacklisted); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+
+	for _, want := range []string{
+		"- Model: test-model (test)",
+		"## 👤 User\n\nWhat does main.go do?",
+		"**🔧 Tool call: `Read`**",
+		"\"file_path\": \"main.go\"",
+		"````\npackage main\n```\n````", // fence longer than the backticks inside
+		"<summary>💭 Thinking</summary>\n\nLooked at the file.",
+		"## 🤖 Assistant",
+		"It is an empty program.",
+		"_Result redacted: .env is a blacklisted file._",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("transcript missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "supersecret") {
+		t.Errorf("blacklisted file contents leaked into the transcript:\n%s", out)
+	}
+	if strings.Contains(out, "scenario prompt") {
+		t.Errorf("system prompts should not be exported:\n%s", out)
+	}
+}
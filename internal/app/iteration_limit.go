@@ -0,0 +1,52 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/manifoldco/promptui"
+
+	"github.com/fpt/go-gennai-cli/pkg/agent/react"
+)
+
+// DefaultIterationExtension is how many iterations are added when the user
+// continues a run past its limit
+const DefaultIterationExtension = 10
+
+// iterationExtension returns the configured number of iterations to add
+func (s *ScenarioRunner) iterationExtension() int {
+	if s.settings == nil || s.settings.Agent.IterationExtension <= 0 {
+		return DefaultIterationExtension
+	}
+	return s.settings.Agent.IterationExtension
+}
+
+// iterationLimitHandler asks the user whether to continue a run that reached
+// its iteration limit. Outside an interactive terminal session it returns nil,
+// so the run stops with its partial result.
+func (s *ScenarioRunner) iterationLimitHandler() react.IterationLimitHandler {
+	if !s.interactive {
+		return nil
+	}
+	if stat, err := os.Stdin.Stat(); err != nil || (stat.Mode()&os.ModeCharDevice) == 0 {
+		return nil
+	}
+	return func(ctx context.Context, limit int) int {
+		writer := s.OutWriter()
+		s.endResponseStream(writer)
+
+		extra := s.iterationExtension()
+		prompt := promptui.Prompt{
+			Label:     fmt.Sprintf("Max iterations (%d) reached — continue for %d more", limit, extra),
+			IsConfirm: true,
+		}
+		if _, err := prompt.Run(); err != nil {
+			// "n", Enter and Ctrl+C all decline
+			fmt.Fprintf(writer, "⏹️  Stopping with the partial result.\n\n")
+			return 0
+		}
+		fmt.Fprintf(writer, "▶️  Continuing for %d more iterations...\n\n", extra)
+		return extra
+	}
+}
@@ -0,0 +1,214 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fpt/go-gennai-cli/internal/config"
+	"github.com/fpt/go-gennai-cli/pkg/agent/domain"
+	"github.com/fpt/go-gennai-cli/pkg/client/anthropic"
+	"github.com/fpt/go-gennai-cli/pkg/client/gemini"
+	"github.com/fpt/go-gennai-cli/pkg/client/ollama"
+	"github.com/fpt/go-gennai-cli/pkg/client/openai"
+	"github.com/fpt/go-gennai-cli/pkg/httpclient"
+	pkgLogger "github.com/fpt/go-gennai-cli/pkg/logger"
+)
+
+// NewLLMClient creates the LLM client for the configured backend and model
+func NewLLMClient(ctx context.Context, llm config.LLMSettings, logger *pkgLogger.Logger) (domain.LLM, error) {
+	if proxy := httpclient.ProxyFor(llmEndpoint(llm)); proxy != "" {
+		logger.DebugWithIntention(pkgLogger.IntentionConfig, "LLM requests use proxy", "backend", llm.Backend, "proxy", proxy)
+	}
+
+	switch llm.Backend {
+	case "anthropic", "claude":
+		retry := anthropic.RetryConfig{
+			MaxAttempts: llm.RetryMaxAttempts,
+			BaseDelay:   time.Duration(llm.RetryBaseDelayMs) * time.Millisecond,
+		}
+		client, err := anthropic.NewAnthropicClientWithRetry(llm.Model, llm.MaxTokens, retry)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Anthropic client: %w", err)
+		}
+		configurePromptCaching(client, llm, logger)
+		configureThinkingBudget(client, llm, logger)
+		return client, nil
+	case "openai":
+		// OPENAI_BASE_URL takes precedence over the base_url setting; empty means the default endpoint
+		baseURL := os.Getenv("OPENAI_BASE_URL")
+		if baseURL == "" {
+			baseURL = llm.BaseURL
+		}
+		client, err := openai.NewOpenAIClientWithBaseURL(llm.Model, llm.MaxTokens, baseURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create OpenAI client: %w", err)
+		}
+		configurePromptCaching(client, llm, logger)
+		return client, nil
+	case "azure":
+		client, err := openai.NewAzureOpenAIClient(llm.Model, llm.MaxTokens, azureConfig(llm))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Azure OpenAI client: %w", err)
+		}
+		configurePromptCaching(client, llm, logger)
+		return client, nil
+	case "gemini":
+		client, err := gemini.NewGeminiClientWithTokens(llm.Model, llm.MaxTokens)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Gemini client: %w", err)
+		}
+		configureThinkingBudget(client, llm, logger)
+		return client, nil
+	default:
+		// For Ollama, check if model is in known list, if not, test capability
+		if !ollama.IsModelInKnownList(llm.Model) {
+			logger.Warn("Model not in known capabilities list, testing tool calling capability",
+				"model", llm.Model)
+
+			// Test model capability with a 30-second timeout
+			testCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+			defer cancel()
+
+			hasToolCapability, testErr := ollama.DynamicCapabilityCheck(testCtx, llm.Model, false)
+			if testErr != nil {
+				logger.Warn("Failed to test model capability, proceeding without tool support",
+					"model", llm.Model, "error", testErr)
+			} else if !hasToolCapability {
+				logger.Warn("Model does not support tool calling - limited functionality",
+					"model", llm.Model, "suggestion", "consider using 'gpt-oss:latest'")
+			} else {
+				logger.InfoWithIntention(pkgLogger.IntentionSuccess, "Model supports tool calling, proceeding with full functionality",
+					"model", llm.Model)
+			}
+		}
+
+		client, err := ollama.NewOllamaClient(llm.Model, llm.MaxTokens, llm.Thinking)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Ollama client: %w", err)
+		}
+		if ollamaClient, ok := client.(*ollama.OllamaClient); ok {
+			configureKeepAlive(ollamaClient, llm, logger)
+			configureNumCtx(ollamaClient, llm, logger)
+			go preloadOllamaModel(ctx, ollamaClient, logger)
+		}
+		return client, nil
+	}
+}
+
+// ollamaPreloadTimeout bounds the background model load at startup
+const ollamaPreloadTimeout = 5 * time.Minute
+
+// configureKeepAlive applies the keep_alive setting to an Ollama client
+func configureKeepAlive(client *ollama.OllamaClient, llm config.LLMSettings, logger *pkgLogger.Logger) {
+	if llm.KeepAlive == "" {
+		return
+	}
+	keepAlive, err := llm.KeepAliveDuration()
+	if err != nil {
+		logger.Warn("Ignoring keep_alive", "error", err)
+		return
+	}
+	client.SetKeepAlive(keepAlive)
+	logger.DebugWithIntention(pkgLogger.IntentionConfig, "Ollama keep_alive set", "model", llm.Model, "keep_alive", llm.KeepAlive)
+}
+
+// configureNumCtx applies the num_ctx setting to an Ollama client, which
+// otherwise requests the model's known context window
+func configureNumCtx(client *ollama.OllamaClient, llm config.LLMSettings, logger *pkgLogger.Logger) {
+	if llm.NumCtx == 0 {
+		if client.NumCtx() == 0 {
+			logger.DebugWithIntention(pkgLogger.IntentionConfig, "Ollama context window unknown for model; using the server default num_ctx", "model", llm.Model)
+		}
+		return
+	}
+	if window := ollama.GetModelContextWindow(llm.Model); window > 0 && llm.NumCtx > window {
+		logger.Warn("num_ctx exceeds the model's context window; the model may degrade past it",
+			"model", llm.Model, "num_ctx", llm.NumCtx, "context_window", window)
+	}
+	client.SetNumCtx(llm.NumCtx)
+	logger.DebugWithIntention(pkgLogger.IntentionConfig, "Ollama num_ctx set", "model", llm.Model, "num_ctx", llm.NumCtx)
+}
+
+// preloadOllamaModel loads the model while the user types the first prompt,
+// so the first turn does not wait for it
+func preloadOllamaModel(ctx context.Context, client *ollama.OllamaClient, logger *pkgLogger.Logger) {
+	ctx, cancel := context.WithTimeout(ctx, ollamaPreloadTimeout)
+	defer cancel()
+	if err := client.Preload(ctx); err != nil && ctx.Err() == nil {
+		logger.DebugWithIntention(pkgLogger.IntentionDebug, "Ollama model preload failed", "model", client.Model(), "error", err)
+	}
+}
+
+// configurePromptCaching enables provider prompt caching when the prompt_caching
+// setting is on and the client supports it
+func configurePromptCaching(client domain.LLM, llm config.LLMSettings, logger *pkgLogger.Logger) {
+	if !llm.PromptCaching {
+		return
+	}
+	configurator, ok := client.(domain.ModelSideCacheConfigurator)
+	if !ok {
+		return
+	}
+	configurator.ConfigureModelSideCache(domain.ModelSideCacheOptions{
+		PromptCachingEnabled: true,
+		PolicyHint:           "prefix",
+	})
+	logger.DebugWithIntention(pkgLogger.IntentionConfig, "Prompt caching enabled", "backend", llm.Backend, "model", llm.Model)
+}
+
+// configureThinkingBudget passes the thinking_budget setting to clients that accept one
+func configureThinkingBudget(client domain.LLM, llm config.LLMSettings, logger *pkgLogger.Logger) {
+	if llm.ThinkingBudget <= 0 {
+		return
+	}
+	configurator, ok := client.(domain.ThinkingBudgetConfigurator)
+	if !ok {
+		return
+	}
+	configurator.SetThinkingBudget(llm.ThinkingBudget)
+	logger.DebugWithIntention(pkgLogger.IntentionConfig, "Thinking budget set", "backend", llm.Backend, "tokens", llm.ThinkingBudget)
+}
+
+// azureConfig reads the Azure OpenAI environment, falling back to the base_url
+// and api_version settings for what it leaves unset
+func azureConfig(llm config.LLMSettings) openai.AzureConfig {
+	cfg := openai.AzureConfigFromEnv()
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = llm.BaseURL
+	}
+	if cfg.APIVersion == "" {
+		cfg.APIVersion = llm.APIVersion
+	}
+	return cfg
+}
+
+// llmEndpoint returns the API URL the backend's client will contact
+func llmEndpoint(llm config.LLMSettings) string {
+	switch llm.Backend {
+	case "anthropic", "claude":
+		return "https://api.anthropic.com"
+	case "openai":
+		if baseURL := os.Getenv("OPENAI_BASE_URL"); baseURL != "" {
+			return baseURL
+		}
+		if llm.BaseURL != "" {
+			return llm.BaseURL
+		}
+		return "https://api.openai.com/v1"
+	case "azure":
+		return azureConfig(llm).Endpoint
+	case "gemini":
+		return "https://generativelanguage.googleapis.com"
+	default:
+		if host := os.Getenv("OLLAMA_HOST"); host != "" {
+			if !strings.Contains(host, "://") {
+				host = "http://" + host
+			}
+			return host
+		}
+		return llm.BaseURL
+	}
+}
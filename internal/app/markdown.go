@@ -0,0 +1,195 @@
+package app
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ANSI styles used when rendering Markdown answers in the terminal
+const (
+	ansiReset     = "\x1b[0m"
+	ansiBold      = "\x1b[1m"
+	ansiDim       = "\x1b[90m"
+	ansiItalic    = "\x1b[3m"
+	ansiUnderline = "\x1b[4m"
+	ansiCyan      = "\x1b[36m"
+	ansiYellow    = "\x1b[33m"
+)
+
+var (
+	ansiEscapePattern = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+	codeSpanPattern   = regexp.MustCompile("`[^`]+`")
+	boldPattern       = regexp.MustCompile(`\*\*([^*]+)\*\*|__([^_]+)__`)
+	italicPattern     = regexp.MustCompile(`(^|[^\w*])\*([^*\s][^*]*)\*|(^|[^\w_])_([^_\s][^_]*)_`)
+	linkPattern       = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+	headingPattern    = regexp.MustCompile(`^(#{1,6})\s+(.*)$`)
+	orderedPattern    = regexp.MustCompile(`^(\d+)[.)]\s+(.*)$`)
+	tableRulePattern  = regexp.MustCompile(`^\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?$`)
+)
+
+// renderMarkdown formats a Markdown answer for an ANSI terminal of the given
+// width: styled headings, bullets, quotes, aligned tables and indented code
+// blocks. Mermaid diagrams can't be drawn in a terminal, so their source is
+// shown as a labeled block.
+func renderMarkdown(text string, width int) string {
+	if width <= 0 {
+		width = 80
+	}
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	var b strings.Builder
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		// Fenced code block: copy verbatim until the closing fence
+		if fence, lang, ok := openingFence(trimmed); ok {
+			label := lang
+			if lang == "mermaid" {
+				label = "mermaid diagram (source)"
+			}
+			if label != "" {
+				b.WriteString(ansiDim + "  ┌ " + label + ansiReset + "\n")
+			}
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), fence); i++ {
+				b.WriteString("  " + ansiYellow + lines[i] + ansiReset + "\n")
+			}
+			continue
+		}
+
+		// Table: a header row followed by a |---|---| rule
+		if strings.HasPrefix(trimmed, "|") && i+1 < len(lines) && tableRulePattern.MatchString(strings.TrimSpace(lines[i+1])) {
+			rows := [][]string{tableCells(trimmed)}
+			for i += 2; i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), "|"); i++ {
+				rows = append(rows, tableCells(strings.TrimSpace(lines[i])))
+			}
+			i--
+			b.WriteString(renderTable(rows))
+			continue
+		}
+
+		if m := headingPattern.FindStringSubmatch(trimmed); m != nil {
+			style := ansiBold + ansiCyan
+			if len(m[1]) == 1 {
+				style += ansiUnderline
+			}
+			b.WriteString(style + renderInline(strings.TrimRight(m[2], " #")) + ansiReset + "\n")
+			continue
+		}
+
+		switch {
+		case trimmed == "---" || trimmed == "***" || trimmed == "___":
+			b.WriteString(ansiDim + strings.Repeat("─", min(width, 80)) + ansiReset + "\n")
+		case strings.HasPrefix(trimmed, ">"):
+			b.WriteString(ansiDim + "│ " + ansiReset + renderInline(strings.TrimSpace(strings.TrimPrefix(trimmed, ">"))) + "\n")
+		case strings.HasPrefix(trimmed, "- ") || strings.HasPrefix(trimmed, "* ") || strings.HasPrefix(trimmed, "+ "):
+			indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+			item := trimmed[2:]
+			if rest, ok := strings.CutPrefix(item, "[ ] "); ok {
+				item = "☐ " + rest
+			} else if rest, ok := strings.CutPrefix(item, "[x] "); ok {
+				item = "☑ " + rest
+			}
+			b.WriteString(indent + "  • " + renderInline(item) + "\n")
+		default:
+			if m := orderedPattern.FindStringSubmatch(trimmed); m != nil {
+				indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+				b.WriteString(indent + "  " + m[1] + ". " + renderInline(m[2]) + "\n")
+			} else {
+				b.WriteString(renderInline(line) + "\n")
+			}
+		}
+	}
+	return b.String()
+}
+
+// openingFence reports whether line opens a fenced code block, returning the
+// fence that closes it and the info string's language
+func openingFence(line string) (fence, lang string, ok bool) {
+	for _, f := range []string{"```", "~~~"} {
+		if rest, found := strings.CutPrefix(line, f); found {
+			return f, strings.TrimSpace(strings.TrimLeft(rest, f[:1])), true
+		}
+	}
+	return "", "", false
+}
+
+// renderInline styles code spans, bold, italics and links. Code spans are left
+// as written, so markup inside them is not interpreted.
+func renderInline(text string) string {
+	var b strings.Builder
+	last := 0
+	for _, loc := range codeSpanPattern.FindAllStringIndex(text, -1) {
+		b.WriteString(renderEmphasis(text[last:loc[0]]))
+		b.WriteString(ansiCyan + text[loc[0]+1:loc[1]-1] + ansiReset)
+		last = loc[1]
+	}
+	b.WriteString(renderEmphasis(text[last:]))
+	return b.String()
+}
+
+func renderEmphasis(text string) string {
+	text = linkPattern.ReplaceAllString(text, ansiUnderline+"$1"+ansiReset+ansiDim+" ($2)"+ansiReset)
+	text = boldPattern.ReplaceAllString(text, ansiBold+"$1$2"+ansiReset)
+	return italicPattern.ReplaceAllString(text, "$1$3"+ansiItalic+"$2$4"+ansiReset)
+}
+
+// tableCells splits a table row into its trimmed cells
+func tableCells(row string) []string {
+	row = strings.TrimSuffix(strings.TrimPrefix(row, "|"), "|")
+	cells := strings.Split(row, "|")
+	for i, cell := range cells {
+		cells[i] = renderInline(strings.TrimSpace(cell))
+	}
+	return cells
+}
+
+// renderTable draws rows with box characters, padding each column to its
+// widest cell and bolding the header row
+func renderTable(rows [][]string) string {
+	columns := 0
+	for _, row := range rows {
+		columns = max(columns, len(row))
+	}
+	widths := make([]int, columns)
+	for _, row := range rows {
+		for c, cell := range row {
+			widths[c] = max(widths[c], visibleWidth(cell))
+		}
+	}
+
+	border := func(left, mid, right string) string {
+		parts := make([]string, columns)
+		for c, w := range widths {
+			parts[c] = strings.Repeat("─", w+2)
+		}
+		return ansiDim + left + strings.Join(parts, mid) + right + ansiReset + "\n"
+	}
+
+	var b strings.Builder
+	b.WriteString(border("┌", "┬", "┐"))
+	for r, row := range rows {
+		b.WriteString(ansiDim + "│" + ansiReset)
+		for c := range columns {
+			cell := ""
+			if c < len(row) {
+				cell = row[c]
+			}
+			if r == 0 {
+				cell = ansiBold + cell + ansiReset
+			}
+			fmt.Fprintf(&b, " %s%s %s│%s", cell, strings.Repeat(" ", widths[c]-visibleWidth(cell)), ansiDim, ansiReset)
+		}
+		b.WriteString("\n")
+		if r == 0 {
+			b.WriteString(border("├", "┼", "┤"))
+		}
+	}
+	b.WriteString(border("└", "┴", "┘"))
+	return b.String()
+}
+
+// visibleWidth returns the number of runes of s shown on screen, ignoring ANSI styles
+func visibleWidth(s string) int {
+	return runeLen(ansiEscapePattern.ReplaceAllString(s, ""))
+}
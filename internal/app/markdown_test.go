@@ -0,0 +1,152 @@
+package app
+
+import (
+	"bytes"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/fpt/go-gennai-cli/pkg/agent/events"
+	"github.com/fpt/go-gennai-cli/pkg/message"
+)
+
+func TestRenderMarkdown(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string // substrings of the output with ANSI styles removed
+		avoid []string
+	}{
+		{
+			name:  "headings and emphasis",
+			input: "# Summary\nThe **build** is *fixed*; see [docs](https://go.dev).",
+			want:  []string{"Summary\n", "The build is fixed; see docs (https://go.dev)."},
+			avoid: []string{"#", "**", "*fixed*", "]("},
+		},
+		{
+			name:  "lists",
+			input: "- first\n  - nested `a*b*c`\n1. step one\n- [x] done",
+			want:  []string{"  • first\n", "    • nested a*b*c\n", "  1. step one\n", "  • ☑ done\n"},
+		},
+		{
+			name:  "code block keeps markup",
+			input: "```go\nfunc main() { _ = **p }\n```\nafter",
+			want:  []string{"┌ go\n", "  func main() { _ = **p }\n", "after\n"},
+			avoid: []string{"```"},
+		},
+		{
+			name:  "mermaid source is labeled",
+			input: "```mermaid\ngraph TD; A-->B\n```",
+			want:  []string{"mermaid diagram (source)", "  graph TD; A-->B\n"},
+		},
+		{
+			name:  "table columns are aligned",
+			input: "| Name | Size |\n|------|-----:|\n| main.go | 12 KB |\n| a.go | `1` |",
+			want: []string{
+				"┌─────────┬───────┐\n",
+				"│ Name    │ Size  │\n",
+				"├─────────┼───────┤\n",
+				"│ main.go │ 12 KB │\n",
+				"│ a.go    │ 1     │\n",
+				"└─────────┴───────┘\n",
+			},
+			avoid: []string{"|---"},
+		},
+		{
+			name:  "snake_case and hashes stay as written",
+			input: "call load_user_config\n#include <stdio.h>",
+			want:  []string{"call load_user_config\n", "#include <stdio.h>\n"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ansiEscapePattern.ReplaceAllString(renderMarkdown(tt.input, 80), "")
+			for _, w := range tt.want {
+				if !strings.Contains(got, w) {
+					t.Errorf("expected %q in output:\n%s", w, got)
+				}
+			}
+			for _, a := range tt.avoid {
+				if strings.Contains(got, a) {
+					t.Errorf("expected no %q in output:\n%s", a, got)
+				}
+			}
+		})
+	}
+}
+
+func TestWriteResponse_Markdown(t *testing.T) {
+	runner := &ScenarioRunner{llmClient: &mockToolLLM{}}
+	answer := message.NewChatMessage(message.MessageTypeAssistant, "## Result\n**ok**")
+
+	var plain bytes.Buffer
+	runner.WriteResponse(&plain, answer)
+	if !strings.Contains(plain.String(), "## Result\n**ok**\n") {
+		t.Errorf("expected raw Markdown by default, got %q", plain.String())
+	}
+
+	runner.SetMarkdownOutput(true)
+	var rendered bytes.Buffer
+	runner.WriteResponse(&rendered, answer)
+	if strings.Contains(rendered.String(), "**") || !strings.Contains(rendered.String(), ansiBold+"ok"+ansiReset) {
+		t.Errorf("expected rendered Markdown, got %q", rendered.String())
+	}
+}
+
+// overlapWriter counts Write calls made while another is in progress
+type overlapWriter struct {
+	inflight atomic.Int32
+	overlaps atomic.Int32
+	mu       sync.Mutex
+	buf      bytes.Buffer
+}
+
+func (w *overlapWriter) Write(p []byte) (int, error) {
+	if w.inflight.Add(1) > 1 {
+		w.overlaps.Add(1)
+	}
+	defer w.inflight.Add(-1)
+	runtime.Gosched()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Write(p)
+}
+
+func TestEventHandlers_SerializeConcurrentChunks(t *testing.T) {
+	out := &overlapWriter{}
+	runner := &ScenarioRunner{llmClient: &mockToolLLM{}, out: out}
+	emitter := events.NewSimpleEventEmitter()
+	runner.setupEventHandlers(emitter)
+
+	// Thinking and response chunks are emitted from different goroutines
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		<-start
+		for range 200 {
+			emitter.EmitEvent(events.EventTypeThinkingChunk, events.ThinkingChunkData{Content: "t"})
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		<-start
+		for range 200 {
+			emitter.EmitEvent(events.EventTypeResponseChunk, events.ResponseChunkData{Content: "r"})
+		}
+	}()
+	close(start)
+	wg.Wait()
+
+	if n := out.overlaps.Load(); n > 0 {
+		t.Errorf("%d writes overlapped", n)
+	}
+	runner.WriteResponse(out, message.NewChatMessage(message.MessageTypeAssistant, strings.Repeat("r", 200)))
+	if got := strings.Count(out.buf.String(), "r"); got < 200 {
+		t.Errorf("expected every response chunk in the output, got %d", got)
+	}
+}
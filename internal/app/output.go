@@ -181,6 +181,14 @@ func WriteResponseHeader(w io.Writer, model string, colored bool) {
 	}
 }
 
+// terminalWidth returns the width of the terminal on stdout, or 80 when unknown.
+func terminalWidth() int {
+	if width, _, err := term.GetSize(int(os.Stdout.Fd())); err == nil && width > 0 {
+		return width
+	}
+	return 80
+}
+
 // runeLen returns the number of runes in s.
 func runeLen(s string) int { return utf8.RuneCountInString(s) }
 
@@ -6,11 +6,14 @@ import (
 	"io"
 	"os"
 	"os/signal"
+	"strconv"
 	"strings"
 	"syscall"
 
 	"github.com/chzyer/readline"
+	"github.com/fpt/go-gennai-cli/internal/tool"
 	"github.com/fpt/go-gennai-cli/pkg/agent/domain"
+	"github.com/fpt/go-gennai-cli/pkg/message"
 	"github.com/manifoldco/promptui"
 )
 
@@ -18,7 +21,7 @@ import (
 type SlashCommand struct {
 	Name        string
 	Description string
-	Handler     func(*ScenarioRunner) bool // Returns true if should exit
+	Handler     func(a *ScenarioRunner, args []string) bool // Returns true if should exit
 }
 
 // getSlashCommands returns all available slash commands
@@ -27,7 +30,7 @@ func getSlashCommands() []SlashCommand {
 		{
 			Name:        "help",
 			Description: "Show available commands and usage information",
-			Handler: func(a *ScenarioRunner) bool {
+			Handler: func(a *ScenarioRunner, args []string) bool {
 				showInteractiveHelp()
 				return false
 			},
@@ -35,7 +38,7 @@ func getSlashCommands() []SlashCommand {
 		{
 			Name:        "log",
 			Description: "Show conversation history (preview)",
-			Handler: func(a *ScenarioRunner) bool {
+			Handler: func(a *ScenarioRunner, args []string) bool {
 				history := a.GetConversationPreview(1000)
 				if strings.TrimSpace(history) == "" {
 					fmt.Println("📜 No conversation history found.")
@@ -48,7 +51,7 @@ func getSlashCommands() []SlashCommand {
 		{
 			Name:        "clear",
 			Description: "Clear conversation history and start fresh",
-			Handler: func(a *ScenarioRunner) bool {
+			Handler: func(a *ScenarioRunner, args []string) bool {
 				a.ClearHistory()
 				fmt.Println("🧹 Conversation history cleared.")
 				return false
@@ -57,15 +60,100 @@ func getSlashCommands() []SlashCommand {
 		{
 			Name:        "status",
 			Description: "Show current session status and statistics",
-			Handler: func(a *ScenarioRunner) bool {
+			Handler: func(a *ScenarioRunner, args []string) bool {
 				showStatus(a)
 				return false
 			},
 		},
+		{
+			Name:        "tools",
+			Description: "List the tools active for this session, grouped by source",
+			Handler: func(a *ScenarioRunner, args []string) bool {
+				fmt.Println(a.formatToolInventory(a.scenario))
+				return false
+			},
+		},
+		{
+			Name:        "session",
+			Description: "Manage named sessions: /session [list|switch NAME|new NAME]",
+			Handler: func(a *ScenarioRunner, args []string) bool {
+				handleSessionCommand(a, args)
+				return false
+			},
+		},
+		{
+			Name:        "model",
+			Description: "Show or switch the LLM: /model [BACKEND] MODEL",
+			Handler: func(a *ScenarioRunner, args []string) bool {
+				handleModelCommand(a, args)
+				return false
+			},
+		},
+		{
+			Name:        "retry",
+			Description: "Re-run the last request, dropping its answer: /retry [-m [BACKEND] MODEL]",
+			Handler: func(a *ScenarioRunner, args []string) bool {
+				handleRetryCommand(a, args)
+				return false
+			},
+		},
+		{
+			Name:        "export",
+			Description: "Save the conversation as a Markdown transcript: /export PATH",
+			Handler: func(a *ScenarioRunner, args []string) bool {
+				if len(args) != 1 {
+					fmt.Println("❌ Usage: /export PATH")
+					return false
+				}
+				if err := a.ExportTranscript(args[0]); err != nil {
+					fmt.Printf("❌ Export failed: %v\n", err)
+					return false
+				}
+				fmt.Printf("📄 Transcript saved to %s\n", args[0])
+				return false
+			},
+		},
+		{
+			Name:        "summary",
+			Description: "Recap the session as a handoff note, optionally saved to a file: /summary [PATH]",
+			Handler: func(a *ScenarioRunner, args []string) bool {
+				handleSummaryCommand(a, args)
+				return false
+			},
+		},
+		{
+			Name:        "scratchpad",
+			Description: "Show the agent's notes for this session, or clear them: /scratchpad [clear]",
+			Handler: func(a *ScenarioRunner, args []string) bool {
+				handleScratchpadCommand(a, args)
+				return false
+			},
+		},
+		{
+			Name:        "undo",
+			Description: "Revert the most recent file write or edit: /undo [COUNT]",
+			Handler: func(a *ScenarioRunner, args []string) bool {
+				handleUndoCommand(a, args)
+				return false
+			},
+		},
+		{
+			Name:        "dryrun",
+			Description: "Toggle dry-run mode (changes are collected as a patch instead of written)",
+			Handler: func(a *ScenarioRunner, args []string) bool {
+				a.SetDryRun(!a.DryRun())
+				if a.DryRun() {
+					fmt.Println("📝 Dry run enabled: file changes will be proposed, not written")
+				} else {
+					fmt.Println("✏️  Dry run disabled: file changes will be written")
+				}
+				return false
+			},
+		},
 		{
 			Name:        "quit",
 			Description: "Exit the interactive session",
-			Handler: func(a *ScenarioRunner) bool {
+			Handler: func(a *ScenarioRunner, args []string) bool {
 				fmt.Println("👋 Goodbye!")
 				return true
 			},
@@ -73,7 +161,7 @@ func getSlashCommands() []SlashCommand {
 		{
 			Name:        "exit",
 			Description: "Exit the interactive session (alias for quit)",
-			Handler: func(a *ScenarioRunner) bool {
+			Handler: func(a *ScenarioRunner, args []string) bool {
 				fmt.Println("👋 Goodbye!")
 				return true
 			},
@@ -100,7 +188,7 @@ func handleSlashCommand(input string, a *ScenarioRunner) bool {
 	// Find and execute the command
 	for _, cmd := range commands {
 		if cmd.Name == commandName {
-			return cmd.Handler(a)
+			return cmd.Handler(a, parts[1:])
 		}
 	}
 
@@ -153,11 +241,13 @@ func showCommandSelector(a *ScenarioRunner) bool {
 		fmt.Printf("Command selection failed: %v\n", err)
 		return false
 	}
-	return commands[i].Handler(a)
+	return commands[i].Handler(a, nil)
 }
 
 // StartInteractiveMode runs the readline-based REPL
 func StartInteractiveMode(ctx context.Context, a *ScenarioRunner, scenario string) {
+	a.scenario = scenario
+
 	// Configure readline with enhanced features
 	// Context display
 	contextDisplay := NewContextDisplay()
@@ -272,51 +362,53 @@ func StartInteractiveMode(ctx context.Context, a *ScenarioRunner, scenario strin
 			continue
 		}
 
-		// Execute via scenario runner with cancellable context
-		// Set up signal handling for Ctrl+C during execution
-		execCtx, cancel := context.WithCancel(ctx)
-		sigChan := make(chan os.Signal, 1)
-		signal.Notify(sigChan, syscall.SIGINT)
-
-		// Handle Ctrl+C during execution in a goroutine
-		go func() {
-			select {
-			case <-sigChan:
-				fmt.Println() // Move to new line after ^C
-				cancel()      // Cancel the execution context
-			case <-execCtx.Done():
-				// Execution finished, clean up
-			}
-		}()
+		runInterruptibly(ctx, a, func(ctx context.Context) (message.Message, error) {
+			return a.Invoke(ctx, pb.RawPrompt(), scenario)
+		})
 
-		response, invokeErr := a.Invoke(execCtx, pb.RawPrompt(), scenario)
+		// No placeholder state to reset
+	}
+}
 
-		// Check for cancellation BEFORE cleaning up
-		wasCanceled := execCtx.Err() == context.Canceled
+// runInterruptibly runs one agent invocation that Ctrl+C cancels, then prints
+// the response or error
+func runInterruptibly(ctx context.Context, a *ScenarioRunner, run func(ctx context.Context) (message.Message, error)) {
+	execCtx, cancel := context.WithCancel(ctx)
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT)
+
+	// Handle Ctrl+C during execution in a goroutine
+	go func() {
+		select {
+		case <-sigChan:
+			fmt.Println() // Move to new line after ^C
+			cancel()      // Cancel the execution context
+		case <-execCtx.Done():
+			// Execution finished, clean up
+		}
+	}()
 
-		// Clean up signal handling
-		signal.Stop(sigChan)
-		close(sigChan)
-		cancel()
+	response, invokeErr := run(execCtx)
 
-		if invokeErr != nil {
-			// Check if the error was due to cancellation
-			if wasCanceled {
-				fmt.Printf("🔄 Ready for next command.\n")
-			} else {
-				fmt.Printf("❌ Error: %v\n", invokeErr)
-			}
-			continue
-		}
-		// Print response via ScenarioRunner's writer with model header
-		w := a.OutWriter()
-		model := a.GetLLMClient().ModelID()
-		// Skyblue/bright-cyan header without icon
-		WriteResponseHeader(w, model, true)
-		fmt.Fprintln(w, response.Content())
+	// Check for cancellation BEFORE cleaning up
+	wasCanceled := execCtx.Err() == context.Canceled
 
-		// No placeholder state to reset
+	// Clean up signal handling
+	signal.Stop(sigChan)
+	close(sigChan)
+	cancel()
+
+	if invokeErr != nil {
+		// Check if the error was due to cancellation
+		if wasCanceled {
+			fmt.Printf("🔄 Ready for next command.\n")
+		} else {
+			fmt.Printf("❌ Error: %v\n", invokeErr)
+		}
+		return
 	}
+	// Print response via ScenarioRunner's writer with model header (unless already streamed)
+	a.WriteResponse(a.OutWriter(), response)
 }
 
 // createAutoCompleter creates an autocompletion function for readline
@@ -369,17 +461,172 @@ func showInteractiveHelp() {
 	fmt.Println("🔧 The agent will automatically use tools when needed!")
 }
 
-func showStatus(a *ScenarioRunner) {
-	fmt.Println("\n📊 Session Status:")
-	preview := a.GetConversationPreview(100)
-	if preview != "" {
-		userMsgCount := strings.Count(preview, "👤 You:")
-		assistantMsgCount := strings.Count(preview, "🤖 Assistant:")
-		fmt.Printf("  💬 Messages: %d from you, %d from assistant\n", userMsgCount, assistantMsgCount)
-	} else {
-		fmt.Println("  💬 Messages: No conversation history")
+// handleSessionCommand lists, switches, or creates named sessions for the project
+func handleSessionCommand(a *ScenarioRunner, args []string) {
+	sub := "list"
+	if len(args) > 0 {
+		sub = args[0]
+	}
+
+	switch sub {
+	case "list":
+		sessions, err := a.ListSessions()
+		if err != nil {
+			fmt.Printf("❌ Failed to list sessions: %v\n", err)
+			return
+		}
+		fmt.Println("\n🗂️  Sessions:")
+		if len(sessions) == 0 {
+			fmt.Println("  (none saved yet)")
+		}
+		for _, name := range sessions {
+			marker := " "
+			if name == a.SessionName() {
+				marker = "*"
+			}
+			fmt.Printf("  %s %s\n", marker, name)
+		}
+	case "switch", "new":
+		if len(args) < 2 {
+			fmt.Printf("❌ Usage: /session %s NAME\n", sub)
+			return
+		}
+		name := args[1]
+		if err := a.SwitchSession(name, sub == "new"); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			return
+		}
+		if sub == "new" {
+			fmt.Printf("🆕 Started new session: %s\n", name)
+		} else {
+			fmt.Printf("🔀 Switched to session: %s (%d messages)\n", name, len(a.GetMessageState().GetMessages()))
+		}
+	default:
+		fmt.Printf("❌ Unknown session command: %s\n", sub)
+		fmt.Println("💡 Usage: /session [list|switch NAME|new NAME]")
+	}
+}
+
+// handleModelCommand shows the active model or switches to another backend/model
+func handleModelCommand(a *ScenarioRunner, args []string) {
+	backend, model := a.CurrentModel()
+	switch len(args) {
+	case 0:
+		fmt.Printf("🧠 Backend: %s\n", backend)
+		fmt.Printf("🧠 Model: %s\n", model)
+		return
+	case 1:
+		model = args[0]
+	case 2:
+		backend, model = args[0], args[1]
+	default:
+		fmt.Println("❌ Usage: /model [BACKEND] MODEL")
+		return
+	}
+
+	if err := a.SwitchModel(context.Background(), backend, model); err != nil {
+		fmt.Printf("❌ Failed to switch model: %v\n", err)
+		return
+	}
+	backend, model = a.CurrentModel()
+	fmt.Printf("🔀 Switched to %s (%s); conversation history kept.\n", model, backend)
+}
+
+// handleRetryCommand re-runs the last user input, optionally on another model
+func handleRetryCommand(a *ScenarioRunner, args []string) {
+	var backend, model string
+	switch {
+	case len(args) == 0:
+	case args[0] == "-m" && len(args) == 2:
+		model = args[1]
+	case args[0] == "-m" && len(args) == 3:
+		backend, model = args[1], args[2]
+	default:
+		fmt.Println("❌ Usage: /retry [-m [BACKEND] MODEL]")
+		return
+	}
+	if a.lastInput == "" {
+		fmt.Println("❌ Nothing to retry yet.")
+		return
 	}
-	fmt.Println("  🔧 Tools: Available and active")
-	fmt.Println("  🧠 Agent: ReAct with scenario planning")
-	fmt.Println("  ⚡ Status: Ready for requests")
+	fmt.Println("🔁 Retrying the last request...")
+	runInterruptibly(context.Background(), a, func(ctx context.Context) (message.Message, error) {
+		return a.Retry(ctx, backend, model)
+	})
+}
+
+// handleSummaryCommand prints a handoff note for the session and, given a
+// PATH, saves it there too
+func handleSummaryCommand(a *ScenarioRunner, args []string) {
+	if len(args) > 1 {
+		fmt.Println("❌ Usage: /summary [PATH]")
+		return
+	}
+	var summary string
+	fmt.Println("📝 Summarizing the session...")
+	runInterruptibly(context.Background(), a, func(ctx context.Context) (message.Message, error) {
+		var err error
+		summary, err = a.SummarizeSession(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return message.NewChatMessage(message.MessageTypeAssistant, summary), nil
+	})
+	if summary == "" || len(args) == 0 {
+		return
+	}
+	if err := writeSessionSummary(args[0], summary); err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return
+	}
+	fmt.Printf("📝 Summary saved to %s\n", args[0])
+}
+
+// handleUndoCommand reverts the last COUNT (default 1) file changes, newest first
+func handleUndoCommand(a *ScenarioRunner, args []string) {
+	count := 1
+	if len(args) > 0 {
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n < 1 || len(args) > 1 {
+			fmt.Println("❌ Usage: /undo [COUNT]")
+			return
+		}
+		count = n
+	}
+	for i := 0; i < count; i++ {
+		summary, err := a.UndoLastEdit(context.Background())
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			return
+		}
+		fmt.Printf("↩️  %s\n", summary)
+	}
+}
+
+// handleScratchpadCommand shows or clears the scratchpad
+func handleScratchpadCommand(a *ScenarioRunner, args []string) {
+	switch {
+	case len(args) == 0:
+		notes := strings.TrimSpace(a.Scratchpad())
+		if notes == "" {
+			fmt.Println("🗒️  The scratchpad is empty.")
+			return
+		}
+		fmt.Printf("🗒️  Scratchpad (%d of %d bytes):\n%s\n", len(a.Scratchpad()), tool.MaxScratchpadBytes, notes)
+	case len(args) == 1 && args[0] == "clear":
+		if err := a.ClearScratchpad(); err != nil {
+			fmt.Printf("❌ Failed to clear the scratchpad: %v\n", err)
+			return
+		}
+		fmt.Println("🧹 Scratchpad cleared.")
+	default:
+		fmt.Println("❌ Usage: /scratchpad [clear]")
+	}
+}
+
+func showStatus(a *ScenarioRunner) {
+	st := collectSessionStatus(a.GetMessageState(), a.GetLLMClient())
+	st.Latency = a.SessionLatency()
+	st.Redactions = a.SecretScrubber().Total()
+	fmt.Print(st.format())
 }
@@ -2,12 +2,14 @@ package app
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	pkgErrors "github.com/pkg/errors"
@@ -26,6 +28,7 @@ import (
 	"github.com/fpt/go-gennai-cli/pkg/client"
 	pkgLogger "github.com/fpt/go-gennai-cli/pkg/logger"
 	"github.com/fpt/go-gennai-cli/pkg/message"
+	"github.com/fpt/go-gennai-cli/pkg/secret"
 )
 
 // Default maximum iterations for scenario execution
@@ -42,12 +45,20 @@ func init() {
 		// Convert between types
 		configScenarios := make(infra.ScenarioMap)
 		for name, scenario := range embeddedScenarios {
-			configScenarios[name] = infra.NewScenarioConfig(
+			scenarioConfig := infra.NewScenarioConfigWithMaxIterations(
 				scenario.Name,
 				scenario.Tools,
 				scenario.Description,
 				scenario.Prompt,
+				scenario.MaxIterations,
 			)
+			if scenario.Thinking != nil {
+				scenarioConfig.SetThinking(*scenario.Thinking)
+			}
+			if scenario.RequireReadBeforeWrite != nil {
+				scenarioConfig.SetRequireReadBeforeWrite(*scenario.RequireReadBeforeWrite)
+			}
+			configScenarios[name] = scenarioConfig
 		}
 
 		return configScenarios, nil
@@ -65,18 +76,61 @@ type ScenarioRunner struct {
 	llmClient        domain.LLM                      // Base LLM client
 	universalManager *tool.CompositeToolManager      // Universal tools (always available: todos, filesystem, bash, grep)
 	todoToolManager  *tool.TodoToolManager           // Direct access to TodoToolManager for aligner
+	scratchpad       *tool.ScratchpadToolManager     // Model's free-form notes, injected into each prompt
+	fsToolManager    *tool.FileSystemToolManager     // Direct access for blacklist checks (transcript redaction) and /undo
+	bashToolManager  *tool.BashToolManager           // Direct access for streaming command output
 	webToolManager   *tool.WebToolManager            // Optional web tools for web scenarios
+	gitToolManager   *tool.GitToolManager            // Optional read-only git tools
+	scrubber         *secret.Scrubber                // Redacts secrets from tool results (nil = disabled)
+	approvalPolicy   domain.ApprovalPolicy           // Which tool categories run freely, ask or are refused
 	mcpToolManagers  map[string]domain.ToolManager   // MCP tool managers by name
+	toolTimeouts     tool.ToolTimeoutConfig          // Per-tool execution budgets
 	fsRepo           repository.FilesystemRepository // Shared filesystem repository instance
+	selection        *ActionSelectionResponse        // Scenario chosen by SelectScenario (nil = chosen by the user)
 	workingDir       string
 	sharedState      domain.State      // Shared state for all agents
 	scenarios        infra.ScenarioMap // Loaded YAML scenarios
 	sessionFilePath  string            // Path to session state file for persistence
+	sessionName      string            // Active named session (empty = default)
 	settings         *config.Settings  // Application settings for configuration
 	logger           *pkgLogger.Logger // Structured logger for this component
 	out              io.Writer         // Output writer for streaming/printing
+	streamMu         sync.Mutex        // Serializes output from events; thinking and response chunks arrive on their own goroutines
 	thinkingStarted  bool              // Track if thinking has started for emoji handling
-	alwaysApprove    bool              // Track if user selected "Always" approve for this session
+	interactive      bool              // REPL session (colored response header)
+	streamStarted    bool              // Response text is being streamed to the writer
+	streamedResponse strings.Builder   // Text streamed since the last tool call
+	toolOutputLines  int               // Lines of the running tool's output already shown
+	alwaysApprove    bool              // Approve every tool call without asking (one-shot mode)
+	approvedAlways   map[string]bool   // Approval categories the user chose "Always" for this session
+	dryRun           bool              // Record file changes as proposals instead of writing them
+	verboseTools     bool              // Show tool results in full instead of truncated
+	markdownOutput   bool              // Render final answers as styled Markdown (terminal only)
+	timeLimit        time.Duration     // Wall-clock limit for each invocation (0 = none)
+	proposals        *proposalSet      // Changes proposed during dry-run turns
+	responseSchema   json.RawMessage   // JSON schema for respond-scenario answers (nil = freeform)
+	exportPath       string            // Markdown transcript rewritten after each invocation (empty = off)
+	scenario         string            // Scenario of the interactive session (for /tools)
+	lastInput        string            // Most recent user input, re-run by /retry
+	lastScenario     string            // Scenario of the most recent user input
+
+	// Optional machine-readable event stream (replaces human-formatted output when set)
+	eventSink chan<- events.AgentEvent
+	eventMu   sync.Mutex
+
+	// Statistics for the most recent invocation
+	lastRun        RunStats
+	sessionLatency react.Latency // LLM and tool time accumulated over the session (for /status)
+	statsMu        sync.Mutex
+}
+
+// RunStats summarizes one invocation for reporting
+type RunStats struct {
+	Usage     message.TokenUsage // tokens consumed across all LLM calls of the run
+	ToolCalls int                // tool calls started during the run
+	Latency   react.Latency      // time spent waiting for the LLM and running tools
+	Elapsed   time.Duration      // wall-clock duration of the run
+	Stopped   error              // why the run returned a partial result (domain.ErrMaxIterations, domain.ErrTimeLimit); nil when it finished
 }
 
 // WorkingDir returns the scenario runner's working directory
@@ -88,31 +142,41 @@ func (s *ScenarioRunner) FilesystemRepository() repository.FilesystemRepository
 // NewScenarioRunner creates a new ScenarioRunner with MCP tools, settings, and additional scenario paths
 func NewScenarioRunner(llmClient domain.LLM, workingDir string, mcpToolManagers map[string]domain.ToolManager, settings *config.Settings, logger *pkgLogger.Logger, out io.Writer, additionalScenarioPaths ...string) *ScenarioRunner {
 	fsRepo := infra.NewOSFilesystemRepository()
-	return NewScenarioRunnerWithOptions(llmClient, workingDir, mcpToolManagers, settings, logger, out, false, true, fsRepo, additionalScenarioPaths...)
+	return NewScenarioRunnerWithOptions(llmClient, workingDir, mcpToolManagers, settings, logger, out, false, true, "", fsRepo, additionalScenarioPaths...)
 }
 
-// NewScenarioRunnerWithOptions creates a new ScenarioRunner with session control options
-func NewScenarioRunnerWithOptions(llmClient domain.LLM, workingDir string, mcpToolManagers map[string]domain.ToolManager, settings *config.Settings, logger *pkgLogger.Logger, out io.Writer, skipSessionRestore bool, isInteractiveMode bool, fsRepo repository.FilesystemRepository, additionalScenarioPaths ...string) *ScenarioRunner {
+// NewScenarioRunnerWithOptions creates a new ScenarioRunner with session control options.
+// sessionName selects a named session file for the project (empty = default session).
+func NewScenarioRunnerWithOptions(llmClient domain.LLM, workingDir string, mcpToolManagers map[string]domain.ToolManager, settings *config.Settings, logger *pkgLogger.Logger, out io.Writer, skipSessionRestore bool, isInteractiveMode bool, sessionName string, fsRepo repository.FilesystemRepository, additionalScenarioPaths ...string) *ScenarioRunner {
 	// Create individual managers for universal tool manager
 	// Only create persistent todo manager in interactive mode
 	var todoToolManager *tool.TodoToolManager
+	var scratchpad *tool.ScratchpadToolManager
 	alwaysApprove := false
 	if isInteractiveMode {
 		todoToolManager = tool.NewTodoToolManager(workingDir)
+		scratchpad = tool.NewScratchpadToolManager(scratchpadFile(workingDir, sessionName, logger))
 	} else {
-		// For one-shot mode, create an in-memory-only todo manager
+		// For one-shot mode, create in-memory-only todo and scratchpad managers
 		todoToolManager = tool.NewInMemoryTodoToolManager()
+		scratchpad = tool.NewInMemoryScratchpadToolManager()
 		// Auto-approve in one-shot mode
 		alwaysApprove = true
 	}
 
 	fsConfig := infra.DefaultFileSystemConfig(workingDir)
+	fsConfig.MaxReadBytes = settings.Agent.ReadMaxBytes
+	fsConfig.HeadTailLines = settings.Agent.HeadTailLines
+	fsConfig.HeadTailMaxLines = settings.Agent.HeadTailMaxLines
+	fsConfig.DisabledValidators = settings.Agent.DisabledValidators
 	filesystemManager := tool.NewFileSystemToolManager(fsRepo, fsConfig, workingDir)
 
 	bashConfig := tool.BashConfig{
 		WorkingDir:          workingDir,
 		MaxDuration:         2 * time.Minute,
 		WhitelistedCommands: settings.Bash.WhitelistedCommands,
+		AllowedCommands:     settings.Bash.AllowedCommands,
+		DeniedCommands:      settings.Bash.DeniedCommands,
 	}
 	bashToolManager := tool.NewBashToolManager(bashConfig)
 
@@ -120,10 +184,45 @@ func NewScenarioRunnerWithOptions(llmClient domain.LLM, workingDir string, mcpTo
 	searchToolManager := tool.NewSearchToolManager(tool.SearchConfig{WorkingDir: workingDir})
 
 	// Create universal tool manager (always available tools)
-	universalManager := tool.NewCompositeToolManager(todoToolManager, filesystemManager, bashToolManager, searchToolManager)
+	universalManager := tool.NewCompositeToolManager(todoToolManager, scratchpad, filesystemManager, bashToolManager, searchToolManager)
+	toolTimeouts := toolTimeoutConfig(settings.Agent.ToolTimeouts)
+	universalManager.SetToolTimeouts(toolTimeouts)
 
 	// Create optional web tool manager for web scenarios
-	webToolManager := tool.NewWebToolManager()
+	searchProvider, err := tool.NewSearchProvider(settings.Web.SearchProvider, settings.Web.SearXNGURL)
+	if err != nil {
+		logger.Warn("Web search disabled", "error", err)
+	}
+	webToolManager := tool.NewWebToolManagerWithConfig(tool.WebConfig{
+		SearchProvider: searchProvider,
+		FetchCache: tool.FetchCacheConfig{
+			Disabled:   settings.Web.DisableFetchCache,
+			TTL:        time.Duration(settings.Web.FetchCacheTTLSeconds) * time.Second,
+			MaxEntries: settings.Web.FetchCacheMaxEntries,
+		},
+		RespectRobots: settings.Web.RespectsRobots(),
+		PerHostRPS:    settings.Web.PerHostRPS,
+		Offline:       settings.Offline,
+
+		MaxResponseBytes: settings.Web.FetchMaxBytes,
+		MaxOutputChars:   settings.Web.FetchMaxChars,
+		MaxLinks:         settings.Web.FetchMaxLinks,
+	})
+
+	// Create optional read-only git tool manager for scenarios that request it
+	gitToolManager := tool.NewGitToolManager(tool.GitConfig{WorkingDir: workingDir})
+
+	// Redact secrets from tool results before they are stored in the session
+	scrubber, err := settings.Secrets.Scrubber()
+	if err != nil {
+		logger.Warn("Secret redaction disabled", "error", err)
+	}
+	filesystemManager.SetSecretScrubber(scrubber)
+
+	approvalPolicy, err := domain.ParseApprovalPolicy(settings.ApprovalPolicy)
+	if err != nil {
+		logger.Warn("Invalid approval policy, using the default", "error", err)
+	}
 
 	// Load scenario configurations (built-in + additional)
 	scenarios, err := infra.LoadScenarios(additionalScenarioPaths...)
@@ -141,7 +240,7 @@ func NewScenarioRunnerWithOptions(llmClient domain.LLM, workingDir string, mcpTo
 	if isInteractiveMode {
 		// Try to get session file path for persistence
 		if userConfig, err := config.DefaultUserConfig(); err == nil {
-			if sessionPath, err := userConfig.GetProjectSessionFile(workingDir); err == nil {
+			if sessionPath, err := userConfig.GetProjectNamedSessionFile(workingDir, sessionName); err == nil {
 				sessionFilePath = sessionPath
 				// Create repository and inject it into MessageState
 				messageRepo := infra.NewMessageHistoryRepository(sessionFilePath)
@@ -180,17 +279,26 @@ func NewScenarioRunnerWithOptions(llmClient domain.LLM, workingDir string, mcpTo
 	return &ScenarioRunner{
 		llmClient:        llmClient,
 		universalManager: universalManager,
+		toolTimeouts:     toolTimeouts,
 		todoToolManager:  todoToolManager,
+		scratchpad:       scratchpad,
+		fsToolManager:    filesystemManager,
+		bashToolManager:  bashToolManager,
 		webToolManager:   webToolManager.(*tool.WebToolManager),
+		gitToolManager:   gitToolManager,
+		scrubber:         scrubber,
+		approvalPolicy:   approvalPolicy,
 		mcpToolManagers:  mcpToolManagers,
 		fsRepo:           fsRepo,
 		workingDir:       workingDir,
 		sharedState:      sharedState,
 		scenarios:        scenarios,
 		sessionFilePath:  sessionFilePath,
+		sessionName:      sessionName,
 		settings:         settings,
 		logger:           logger.WithComponent("scenario-runner"),
 		out:              out,
+		interactive:      isInteractiveMode,
 		alwaysApprove:    alwaysApprove,
 	}
 }
@@ -202,14 +310,30 @@ func (s *ScenarioRunner) Invoke(ctx context.Context, userInput string, scenarioN
 		return nil, fmt.Errorf("scenario '%s' not found", scenarioName)
 	}
 
-	// Execute scenario directly with CLI reasoning
-	return s.executeScenario(ctx, userInput, scenarioName, "Scenario specified directly via CLI")
+	s.lastInput, s.lastScenario = userInput, scenarioName
+
+	// The ReAct loop turns the deadline into a partial result with a notice
+	if s.timeLimit > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeLimit)
+		defer cancel()
+	}
+
+	// Execute scenario directly with CLI reasoning, or the model's when it selected the scenario
+	reasoning := "Scenario specified directly via CLI"
+	if s.selection != nil && s.selection.Action == scenarioName {
+		reasoning = s.selection.Reasoning
+	}
+	return s.executeScenario(ctx, userInput, scenarioName, reasoning)
 }
 
 // executeScenario handles the common execution logic for both Invoke and InvokeWithScenario
 func (s *ScenarioRunner) executeScenario(ctx context.Context, userInput string, scenarioName string, reasoning string) (message.Message, error) {
 	// Step 1: Create scenario-specific tool manager and ReAct client
 	toolManager := s.getToolManagerForScenario(scenarioName)
+	if s.dryRun {
+		toolManager = newDryRunToolManager(toolManager, s.proposals)
+	}
 
 	// Create LLM client with scenario-specific tools
 	llmWithTools, err := client.NewClientWithToolManager(s.llmClient, toolManager)
@@ -220,13 +344,30 @@ func (s *ScenarioRunner) executeScenario(ctx context.Context, userInput string,
 	aligner := NewScenarioAligner(s.todoToolManager) // Use scenario aligner for message alignment
 
 	// Create ReAct client for tool calling execution with shared state
-	maxIterations := DefaultScenarioMaxIterations // default fallback
-	if s.settings != nil && s.settings.Agent.MaxIterations > 0 {
-		maxIterations = s.settings.Agent.MaxIterations
-	}
+	maxIterations := s.maxIterationsForScenario(scenarioName)
 	// Create ReAct client which returns its own event emitter, then set up event handlers
 	reactClient, eventEmitter := react.NewReAct(llmWithTools, toolManager, s.sharedState, aligner, maxIterations)
+	reactClient.SetToolResultTruncation(s.toolOutputTruncation())
+	reactClient.SetToolConcurrency(s.toolConcurrency())
+	reactClient.SetTokenBudget(s.tokenBudget())
+	reactClient.SetMaxRepeatedToolCalls(s.maxRepeatedToolCalls())
+	reactClient.SetMaxToolResultChars(s.maxToolResultChars())
+	reactClient.SetSecretScrubber(s.scrubber)
+	reactClient.SetIterationLimitHandler(s.iterationLimitHandler())
+	reactClient.SetApprovalPolicy(s.approvalPolicy)
+	if scenario, exists := s.scenarios[scenarioName]; exists {
+		reactClient.SetThinking(scenario.Thinking())
+		if s.fsToolManager != nil {
+			s.fsToolManager.SetRequireReadBeforeWrite(scenario.RequireReadBeforeWrite())
+		}
+	}
+	if s.fsToolManager != nil {
+		s.fsToolManager.SetReadImages(supportsVision(s.llmClient))
+	}
 	s.setupEventHandlers(eventEmitter)
+	s.resetRunStats()
+	defer s.recordRunUsage(reactClient, time.Now())
+	defer s.autoExportTranscript()
 
 	// Step 2: Execute the scenario through ReAct
 	actionResp := &ActionSelectionResponse{
@@ -234,6 +375,10 @@ func (s *ScenarioRunner) executeScenario(ctx context.Context, userInput string,
 		Reasoning: reasoning,
 	}
 
+	// Prepend the configured house-style preamble, re-inserting it when it changed
+	// or was summarized away by compaction
+	s.ensureMarkedSystemMessage("[[SYSTEM_PREAMBLE]]\n", s.systemPreamble())
+
 	// Prepare a stable system prompt (scenario header) and insert only when changed
 	// Render with empty userInput so the header remains stable across turns;
 	// include workingDir and reasoning so those parts remain accurate.
@@ -241,20 +386,7 @@ func (s *ScenarioRunner) executeScenario(ctx context.Context, userInput string,
 		systemPrompt := scenarioConfig.RenderPrompt("", actionResp.Reasoning, s.workingDir)
 		if systemPrompt != "" {
 			// Use a discoverable marker so we can detect previous insertion
-			marker := fmt.Sprintf("[[SCENARIO_PROMPT:%s]]\n", actionResp.Action)
-			candidate := marker + systemPrompt
-
-			// Find the most recent matching marker message
-			var lastMatched string
-			for _, msg := range s.sharedState.GetMessages() {
-				if msg.Type() == message.MessageTypeSystem && strings.HasPrefix(msg.Content(), marker) {
-					lastMatched = msg.Content()
-				}
-			}
-
-			if lastMatched == "" || lastMatched != candidate {
-				s.sharedState.AddMessage(message.NewSystemMessage(candidate))
-			}
+			s.ensureMarkedSystemMessage(fmt.Sprintf("[[SCENARIO_PROMPT:%s]]\n", actionResp.Action), systemPrompt)
 		}
 	}
 
@@ -265,6 +397,11 @@ func (s *ScenarioRunner) executeScenario(ctx context.Context, userInput string,
 			userPrompt = fmt.Sprintf("%s\n\n## Current Todos:\n%s\n\nUse TodoWrite tool to update todos as you progress.", userPrompt, todosContext)
 		}
 	}
+	if s.scratchpad != nil {
+		if notes := s.scratchpad.GetScratchpadForPrompt(); notes != "" {
+			userPrompt = fmt.Sprintf("%s\n\n## Scratchpad (your notes from earlier turns):\n%s", userPrompt, notes)
+		}
+	}
 
 	// Expand line-based includes in the user prompt: lines starting with @filename
 	if strings.Contains(userPrompt, "@") {
@@ -300,25 +437,45 @@ func (s *ScenarioRunner) executeScenario(ctx context.Context, userInput string,
 		userPrompt = strings.Join(out, "\n")
 	}
 
-	result, err := reactClient.Run(ctx, userPrompt)
-
-	// Handle multiple approval workflows in sequence
-	var approvalErrors []error
-	for err != nil && pkgErrors.Is(err, react.ErrWaitingForApproval) {
-		result, err = s.handleApprovalWorkflow(ctx, reactClient)
-		if err != nil && !pkgErrors.Is(err, react.ErrWaitingForApproval) {
-			approvalErrors = append(approvalErrors, err)
-		}
+	selfReview := s.selfReviewEnabled()
+	if selfReview {
+		s.fsToolManager.TrackChanges()
+	}
+	validationFixes := s.validationFixRounds() > 0
+	if validationFixes {
+		s.fsToolManager.ClearValidationFailures()
 	}
 
+	result, err := s.runWithApproval(ctx, reactClient, userPrompt)
 	if err != nil {
-		if len(approvalErrors) > 0 {
-			return nil, fmt.Errorf("action execution failed: %w", errors.Join(append(approvalErrors, err)...))
-		}
 		return nil, fmt.Errorf("action execution failed: %w", err)
 	}
 	defer reactClient.Close()
 
+	// A run stopped by the iteration or time limit returns its partial result
+	// as is: further passes would run past the limit
+	if validationFixes && reactClient.StopReason() == nil {
+		if result, err = s.runValidationFixes(ctx, reactClient, result); err != nil {
+			return nil, err
+		}
+	}
+
+	if selfReview && reactClient.StopReason() == nil {
+		if result, err = s.runSelfReview(ctx, reactClient, result); err != nil {
+			return nil, err
+		}
+	}
+
+	if s.responseSchema != nil && scenarioName == respondScenario {
+		if result, err = s.structureResponse(ctx, userInput, result); err != nil {
+			return nil, err
+		}
+	}
+
+	if s.dryRun {
+		s.printProposedPatch()
+	}
+
 	// Save session state after successful interaction
 	if s.sessionFilePath != "" {
 		if saveErr := s.sharedState.SaveToFile(); saveErr != nil {
@@ -330,32 +487,67 @@ func (s *ScenarioRunner) executeScenario(ctx context.Context, userInput string,
 	return result, nil
 }
 
-// handleApprovalWorkflow handles the write confirmation workflow when the agent is waiting for approval
+// runWithApproval runs the prompt through the ReAct client, handling each
+// approval the run waits for in sequence
+func (s *ScenarioRunner) runWithApproval(ctx context.Context, reactClient *react.ReAct, prompt string) (message.Message, error) {
+	result, err := reactClient.Run(ctx, prompt)
+
+	var approvalErrors []error
+	for err != nil && pkgErrors.Is(err, react.ErrWaitingForApproval) {
+		result, err = s.handleApprovalWorkflow(ctx, reactClient)
+		if err != nil && !pkgErrors.Is(err, react.ErrWaitingForApproval) {
+			approvalErrors = append(approvalErrors, err)
+		}
+	}
+
+	if err != nil {
+		if len(approvalErrors) > 0 {
+			return nil, errors.Join(append(approvalErrors, err)...)
+		}
+		return nil, err
+	}
+	return result, nil
+}
+
+// handleApprovalWorkflow asks the user to approve the pending tool call when
+// the approval policy requires it
 func (s *ScenarioRunner) handleApprovalWorkflow(ctx context.Context, reactClient domain.ReAct) (message.Message, error) {
 	writer := s.OutWriter()
+	s.endResponseStream(writer)
+
+	// Dry-run tools only record proposals, so there is nothing to approve
+	if s.dryRun {
+		return reactClient.Resume(ctx)
+	}
+
+	categories := reactClient.GetPendingApprovalCategories()
 
-	// If "Always" was previously selected, auto-approve
-	if s.alwaysApprove {
+	// If "Always" was previously selected for these categories, auto-approve
+	if s.alwaysApprove || s.allApprovedAlways(categories) {
 		fmt.Fprintf(writer, "✅ Proceeding (Always selected)...\n\n")
 		return reactClient.Resume(ctx)
 	}
 
 	// Get the pending tool call details
 	lastMessage := reactClient.GetLastMessage()
+	heading := approvalHeading(categories)
 
 	// Check if we can interact with the user (has a proper terminal)
 	stat, err := os.Stdin.Stat()
 	if err != nil || (stat.Mode()&os.ModeCharDevice) == 0 {
 		// Not interactive mode - auto-approve
-		fmt.Fprintf(writer, "\n📝 About to write file(s):\n")
+		fmt.Fprintf(writer, "\n%s\n", heading)
 		fmt.Fprintf(writer, "📋 %s\n", lastMessage.TruncatedString())
 		fmt.Fprintf(writer, "✅ Proceeding (non-interactive mode)...\n\n")
 		return reactClient.Resume(ctx)
 	}
 
-	// Display the pending action
-	fmt.Fprintf(writer, "\n📝 About to write file(s):\n")
+	// Display the pending action, with a diff of the proposed changes for file edits
+	fmt.Fprintf(writer, "\n%s\n", heading)
 	fmt.Fprintf(writer, "📋 %s\n\n", lastMessage.TruncatedString())
+	if diff := s.pendingChangeDiff(ctx, reactClient.GetPendingToolCall()); diff != "" {
+		fmt.Fprintf(writer, "%s\n", diff)
+	}
 
 	// Create promptui select with horizontal-style options
 	prompt := promptui.Select{
@@ -383,8 +575,14 @@ func (s *ScenarioRunner) handleApprovalWorkflow(ctx context.Context, reactClient
 		return reactClient.Resume(ctx)
 
 	case "Always":
-		s.alwaysApprove = true // Set flag for this session
-		fmt.Fprintf(writer, "✅ Proceeding (will auto-approve future file operations this session)...\n\n")
+		// Remember the choice for these categories only; others still ask
+		if s.approvedAlways == nil {
+			s.approvedAlways = make(map[string]bool)
+		}
+		for _, category := range categories {
+			s.approvedAlways[category] = true
+		}
+		fmt.Fprintf(writer, "✅ Proceeding (will auto-approve future %s operations this session)...\n\n", strings.Join(categories, "/"))
 		return reactClient.Resume(ctx)
 
 	case "No":
@@ -401,11 +599,260 @@ func (s *ScenarioRunner) handleApprovalWorkflow(ctx context.Context, reactClient
 	}
 }
 
+// allApprovedAlways reports whether the user chose "Always" for every category
+func (s *ScenarioRunner) allApprovedAlways(categories []string) bool {
+	if len(categories) == 0 {
+		return false
+	}
+	for _, category := range categories {
+		if !s.approvedAlways[category] {
+			return false
+		}
+	}
+	return true
+}
+
+// approvalHeading describes what the pending tool call is about to do
+func approvalHeading(categories []string) string {
+	if len(categories) != 1 {
+		return "📝 About to run tool calls that need approval:"
+	}
+	switch categories[0] {
+	case domain.ApprovalCategoryDelete:
+		return "🗑️  About to delete:"
+	case domain.ApprovalCategoryBash:
+		return "💻 About to run a command:"
+	case domain.ApprovalCategoryMCP:
+		return "🔌 About to call an MCP tool:"
+	default:
+		return "📝 About to write file(s):"
+	}
+}
+
+// SetDryRun enables or disables dry-run mode. In dry-run mode file changes are
+// recorded as proposals and printed as a consolidated patch after each request.
+func (s *ScenarioRunner) SetDryRun(enabled bool) {
+	s.dryRun = enabled
+	if s.proposals == nil {
+		s.proposals = newProposalSet(s.fsRepo, s.workingDir)
+	}
+	s.proposals.reset()
+}
+
+// DryRun reports whether dry-run mode is enabled
+func (s *ScenarioRunner) DryRun() bool { return s.dryRun }
+
+// SetVerboseTools shows tool results in full (still scrubbed of secrets)
+// instead of keeping only the head and tail of large outputs
+func (s *ScenarioRunner) SetVerboseTools(enabled bool) {
+	s.verboseTools = enabled
+}
+
+// SetMarkdownOutput renders final answers as styled Markdown for an ANSI
+// terminal. Answers are then shown once complete instead of streamed, since
+// tables and code blocks can only be laid out in full.
+func (s *ScenarioRunner) SetMarkdownOutput(enabled bool) {
+	s.markdownOutput = enabled
+}
+
+// SetTimeLimit bounds the wall-clock time of each Invoke. When it runs out,
+// in-flight tool calls are cancelled and the run returns its partial result
+// with a notice. 0 means no limit.
+func (s *ScenarioRunner) SetTimeLimit(d time.Duration) {
+	s.timeLimit = max(d, 0)
+}
+
+// offline reports whether network tools are disabled
+func (s *ScenarioRunner) offline() bool {
+	return s.settings != nil && s.settings.Offline
+}
+
+// UndoLastEdit reverts the most recent file write or edit made by the tools
+func (s *ScenarioRunner) UndoLastEdit(ctx context.Context) (string, error) {
+	if s.fsToolManager == nil {
+		return "", fmt.Errorf("nothing to undo")
+	}
+	return s.fsToolManager.UndoLastEdit(ctx)
+}
+
+// printProposedPatch writes the consolidated patch of proposed changes and clears them
+func (s *ScenarioRunner) printProposedPatch() {
+	writer := s.OutWriter()
+	patch := s.proposals.patch()
+	s.proposals.reset()
+	if patch == "" {
+		fmt.Fprintf(writer, "\n📝 Dry run: no file changes proposed.\n")
+		return
+	}
+	fmt.Fprintf(writer, "\n📝 Dry run: proposed changes (nothing was written; apply with `git apply`):\n\n%s", patch)
+}
+
 // ClearHistory clears the conversation history
 func (s *ScenarioRunner) ClearHistory() {
 	// Clear the shared state which affects all scenarios
 	// This also clears persisted session data via the repository
 	s.sharedState.Clear()
+	s.lastInput = ""
+	s.resetSessionLatency()
+}
+
+// Retry removes the last user turn and its answer from the conversation and
+// runs the same input again. A non-empty model (and optionally backend)
+// switches the LLM first, as /model does.
+func (s *ScenarioRunner) Retry(ctx context.Context, backend, model string) (message.Message, error) {
+	if s.lastInput == "" {
+		return nil, fmt.Errorf("nothing to retry yet")
+	}
+	if model != "" {
+		if err := s.SwitchModel(ctx, backend, model); err != nil {
+			return nil, fmt.Errorf("failed to switch model: %w", err)
+		}
+	}
+	s.sharedState.RemoveLastTurn()
+	return s.Invoke(ctx, s.lastInput, s.lastScenario)
+}
+
+// SessionName returns the active session name
+func (s *ScenarioRunner) SessionName() string {
+	if s.sessionName == "" {
+		return config.DefaultSessionName
+	}
+	return s.sessionName
+}
+
+// ListSessions returns the saved session names for the current project
+func (s *ScenarioRunner) ListSessions() ([]string, error) {
+	userConfig, err := config.DefaultUserConfig()
+	if err != nil {
+		return nil, err
+	}
+	return userConfig.ListProjectSessions(s.workingDir)
+}
+
+// SwitchSession saves the current session and activates the named one.
+// When fresh is true the named session starts empty (discarding any saved state);
+// otherwise its saved state is loaded if present.
+func (s *ScenarioRunner) SwitchSession(name string, fresh bool) error {
+	if s.sessionFilePath == "" {
+		return fmt.Errorf("session persistence is not enabled in this mode")
+	}
+
+	userConfig, err := config.DefaultUserConfig()
+	if err != nil {
+		return err
+	}
+	sessionPath, err := userConfig.GetProjectNamedSessionFile(s.workingDir, name)
+	if err != nil {
+		return err
+	}
+
+	// Persist the current session before swapping the repository
+	if err := s.sharedState.SaveToFile(); err != nil {
+		s.logger.Warn("Failed to save session state", "session_file", s.sessionFilePath, "error", err)
+	}
+
+	newState := state.NewMessageStateWithRepository(infra.NewMessageHistoryRepository(sessionPath))
+	if fresh {
+		newState.Clear()
+	} else if err := newState.LoadFromFile(); err != nil {
+		return fmt.Errorf("failed to load session %s: %w", name, err)
+	}
+
+	s.sharedState = newState
+	s.lastInput = ""
+	s.sessionFilePath = sessionPath
+	s.sessionName = name
+	s.loadSessionScratchpad()
+	s.resetSessionLatency()
+	return nil
+}
+
+// ContinueSession makes a one-shot runner use the project's persisted session
+// (the named one, if set): saved history is loaded, and each successful turn
+// is saved back, as in interactive mode.
+func (s *ScenarioRunner) ContinueSession() error {
+	if s.sessionFilePath != "" {
+		return nil // already persistent
+	}
+
+	userConfig, err := config.DefaultUserConfig()
+	if err != nil {
+		return err
+	}
+	sessionPath, err := userConfig.GetProjectNamedSessionFile(s.workingDir, s.sessionName)
+	if err != nil {
+		return err
+	}
+
+	newState := state.NewMessageStateWithRepository(infra.NewMessageHistoryRepository(sessionPath))
+	if err := newState.LoadFromFile(); err != nil {
+		s.logger.DebugWithIntention(pkgLogger.IntentionStatus, "Starting with new session",
+			"reason", "could not load existing session", "error", err)
+	} else {
+		s.logger.DebugWithIntention(pkgLogger.IntentionStatus, "Continuing session",
+			"message_count", len(newState.GetMessages()), "session_file", sessionPath)
+	}
+
+	s.sharedState = newState
+	s.sessionFilePath = sessionPath
+	s.loadSessionScratchpad()
+	return nil
+}
+
+// loadSessionScratchpad switches the scratchpad to the active session's notes
+func (s *ScenarioRunner) loadSessionScratchpad() {
+	if s.scratchpad == nil {
+		return
+	}
+	if err := s.scratchpad.UseFile(scratchpadFile(s.workingDir, s.sessionName, s.logger)); err != nil {
+		s.logger.Warn("Failed to load scratchpad", "session", s.SessionName(), "error", err)
+	}
+}
+
+// scratchpadFile returns the scratchpad file of a project session, or "" to
+// keep the notes in memory when the user config is unavailable
+func scratchpadFile(workingDir, sessionName string, logger *pkgLogger.Logger) string {
+	userConfig, err := config.DefaultUserConfig()
+	if err != nil {
+		logger.Warn("Could not access user config for the scratchpad", "error", err)
+		return ""
+	}
+	path, err := userConfig.GetProjectNamedScratchpadFile(workingDir, sessionName)
+	if err != nil {
+		logger.Warn("Could not get scratchpad file path", "error", err)
+		return ""
+	}
+	return path
+}
+
+// Scratchpad returns the model's notes for the current session
+func (s *ScenarioRunner) Scratchpad() string {
+	if s.scratchpad == nil {
+		return ""
+	}
+	return s.scratchpad.Content()
+}
+
+// ClearScratchpad removes the model's notes for the current session
+func (s *ScenarioRunner) ClearScratchpad() error {
+	if s.scratchpad == nil {
+		return nil
+	}
+	return s.scratchpad.Clear()
+}
+
+// toolTimeoutConfig converts tool_timeouts settings (seconds by tool name, with
+// "default" for unlisted tools) into tool execution budgets
+func toolTimeoutConfig(timeouts map[string]int) tool.ToolTimeoutConfig {
+	cfg := tool.ToolTimeoutConfig{PerTool: make(map[message.ToolName]time.Duration)}
+	for name, seconds := range timeouts {
+		if name == "default" {
+			cfg.Default = time.Duration(seconds) * time.Second
+			continue
+		}
+		cfg.PerTool[message.ToolName(name)] = time.Duration(seconds) * time.Second
+	}
+	return cfg
 }
 
 // getToolManagerForScenario returns the appropriate tool manager for a given scenario
@@ -417,11 +864,19 @@ func (s *ScenarioRunner) getToolManagerForScenario(scenario string) domain.ToolM
 	if scenarioConfig, exists := s.scenarios[scenario]; exists {
 		toolScope := scenarioConfig.GetToolScope()
 
-		// Add web tools if requested (for RESEARCH scenarios)
-		if toolScope.UseDefault { // "default" in old system meant web tools
+		// Add web tools if requested (for RESEARCH scenarios); offline mode
+		// overrides the scenario
+		if toolScope.UseDefault && s.offline() {
+			s.logger.DebugWithIntention(pkgLogger.IntentionConfig, "Web tools withheld in offline mode", "scenario", scenario)
+		} else if toolScope.UseDefault { // "default" in old system meant web tools
 			managers = append(managers, s.webToolManager)
 		}
 
+		// Add read-only git tools if requested
+		if toolScope.UseGit && s.gitToolManager != nil {
+			managers = append(managers, s.gitToolManager)
+		}
+
 		// Add MCP tools if requested
 		for _, mcpName := range toolScope.MCPTools {
 			if mcpName == "*" {
@@ -441,20 +896,41 @@ func (s *ScenarioRunner) getToolManagerForScenario(scenario string) domain.ToolM
 			}
 		}
 
-		// Return appropriate composite or single tool manager
-		if len(managers) == 1 {
-			// Only universal manager
-			return managers[0]
-		} else {
-			// Universal + optional managers, create composite
-			return tool.NewCompositeToolManager(managers...)
+		// list_available_tools lets the model inspect this scenario's tools
+		managers = append(managers, s.newToolInventoryManager(scenario))
+
+		// Universal + optional managers, create composite
+		composite := tool.NewCompositeToolManager(managers...)
+		composite.SetToolTimeouts(s.toolTimeouts)
+		if s.cacheToolResults() {
+			composite.EnableResultCache(s.workingDir)
 		}
+		return composite
 	}
 
-	// Fallback to universal manager only (todos, filesystem, bash, grep)
+	// Fallback to universal manager only (todos, filesystem, bash, grep); the
+	// cache lives in a per-call wrapper so it never outlasts the request
+	if s.cacheToolResults() {
+		composite := tool.NewCompositeToolManager(s.universalManager)
+		composite.SetToolTimeouts(s.toolTimeouts)
+		composite.EnableResultCache(s.workingDir)
+		return composite
+	}
 	return s.universalManager
 }
 
+// cacheToolResults reports whether repeated read-only tool calls within a
+// request reuse earlier results
+func (s *ScenarioRunner) cacheToolResults() bool {
+	return s.settings != nil && s.settings.Agent.CacheToolResults
+}
+
+// supportsVision reports whether llm accepts images, so Read can return them
+func supportsVision(llm domain.LLM) bool {
+	vision, ok := llm.(domain.VisionLLM)
+	return ok && vision.SupportsVision()
+}
+
 // InvokeWithOptions creates a ReAct client with universal tools and configured maxIterations
 // This method creates a temporary ReAct client with universal tools
 func (s *ScenarioRunner) InvokeWithOptions(ctx context.Context, prompt string) (message.Message, error) {
@@ -472,7 +948,22 @@ func (s *ScenarioRunner) InvokeWithOptions(ctx context.Context, prompt string) (
 	}
 	// Create ReAct client which returns its own event emitter, then set up event handlers
 	reactClient, eventEmitter := react.NewReAct(llmWithTools, s.universalManager, s.sharedState, aligner, maxIterations)
+	reactClient.SetToolResultTruncation(s.toolOutputTruncation())
+	reactClient.SetToolConcurrency(s.toolConcurrency())
+	reactClient.SetTokenBudget(s.tokenBudget())
+	reactClient.SetMaxRepeatedToolCalls(s.maxRepeatedToolCalls())
+	reactClient.SetMaxToolResultChars(s.maxToolResultChars())
+	reactClient.SetSecretScrubber(s.scrubber)
+	reactClient.SetIterationLimitHandler(s.iterationLimitHandler())
+	reactClient.SetApprovalPolicy(s.approvalPolicy)
+	// No scenario opts out here, so the read-before-write check applies
+	if s.fsToolManager != nil {
+		s.fsToolManager.SetRequireReadBeforeWrite(true)
+		s.fsToolManager.SetReadImages(supportsVision(s.llmClient))
+	}
 	s.setupEventHandlers(eventEmitter)
+	s.resetRunStats()
+	defer s.recordRunUsage(reactClient, time.Now())
 
 	result, err := reactClient.Run(ctx, prompt)
 
@@ -545,6 +1036,91 @@ func (s *ScenarioRunner) GetLLMClient() domain.LLM {
 	return s.llmClient
 }
 
+// CurrentModel returns the active LLM backend and model
+func (s *ScenarioRunner) CurrentModel() (backend, model string) {
+	return s.settings.LLM.Backend, s.settings.LLM.Model
+}
+
+// SwitchModel replaces the LLM client with one for the given backend and model,
+// keeping the conversation state. An empty backend keeps the current backend.
+// On failure the current client stays active.
+func (s *ScenarioRunner) SwitchModel(ctx context.Context, backend, model string) error {
+	llm := s.settings.LLM
+	if backend != "" && backend != llm.Backend {
+		llm = config.GetDefaultLLMSettingsForBackend(backend)
+		// GetDefaultLLMSettingsForBackend falls back to ollama for unknown names
+		if llm.Backend != backend {
+			return fmt.Errorf("unsupported LLM backend: %s (must be 'ollama', 'anthropic', 'openai', 'azure', or 'gemini')", backend)
+		}
+	}
+	if model != "" {
+		llm.Model = model
+	}
+
+	candidate := *s.settings
+	candidate.LLM = llm
+	if err := config.ValidateSettings(&candidate); err != nil {
+		return err
+	}
+
+	llmClient, err := NewLLMClient(ctx, llm, s.logger)
+	if err != nil {
+		return err
+	}
+	// Keep recording or replaying with the new model
+	if cached, ok := s.llmClient.(*client.CachingLLM); ok {
+		llmClient = cached.WithLLM(llmClient)
+	}
+	s.llmClient = llmClient
+	s.settings.LLM = llm
+	return nil
+}
+
+// endResponseStream finishes the line of narration streamed before a tool call
+func (s *ScenarioRunner) endResponseStream(w io.Writer) {
+	s.streamMu.Lock()
+	defer s.streamMu.Unlock()
+	s.endResponseStreamLocked(w)
+}
+
+// endResponseStreamLocked is endResponseStream with streamMu held
+func (s *ScenarioRunner) endResponseStreamLocked(w io.Writer) {
+	if s.markdownOutput && s.streamedResponse.Len() > 0 {
+		WriteResponseHeader(w, s.llmClient.ModelID(), s.interactive)
+		fmt.Fprint(w, renderMarkdown(s.streamedResponse.String(), terminalWidth()))
+		s.streamedResponse.Reset()
+		return
+	}
+	if s.streamStarted {
+		fmt.Fprintln(w)
+		s.streamStarted = false
+		s.streamedResponse.Reset()
+	}
+}
+
+// WriteResponse prints the final response under the model header. When the
+// answer was already streamed to the writer only the closing newline is added.
+func (s *ScenarioRunner) WriteResponse(w io.Writer, response message.Message) {
+	s.streamMu.Lock()
+	defer s.streamMu.Unlock()
+	streamed := s.streamStarted && s.streamedResponse.String() == response.Content()
+	if s.streamStarted {
+		fmt.Fprintln(w)
+	}
+	s.streamStarted = false
+	s.streamedResponse.Reset()
+	if streamed {
+		return
+	}
+	// Nothing was streamed, or the stream was incomplete (e.g. a retried request)
+	WriteResponseHeader(w, s.llmClient.ModelID(), s.interactive)
+	if s.markdownOutput {
+		fmt.Fprint(w, renderMarkdown(response.Content(), terminalWidth()))
+		return
+	}
+	fmt.Fprintln(w, response.Content())
+}
+
 // OutWriter returns the output writer used for streaming thinking/log lines
 func (s *ScenarioRunner) OutWriter() io.Writer {
 	if s.out != nil {
@@ -553,43 +1129,257 @@ func (s *ScenarioRunner) OutWriter() io.Writer {
 	return os.Stdout
 }
 
+// maxIterationsForScenario returns the effective ReAct loop limit: the scenario's own
+// max_iterations if set, otherwise the global agent setting, otherwise the default
+func (s *ScenarioRunner) maxIterationsForScenario(scenarioName string) int {
+	maxIterations := DefaultScenarioMaxIterations
+	source := "default"
+	if s.settings != nil && s.settings.Agent.MaxIterations > 0 {
+		maxIterations = s.settings.Agent.MaxIterations
+		source = "settings"
+	}
+	if scenario, exists := s.scenarios[scenarioName]; exists && scenario.MaxIterations() > 0 {
+		maxIterations = scenario.MaxIterations()
+		source = "scenario"
+	}
+	s.logger.DebugWithIntention(pkgLogger.IntentionConfig, "Effective max iterations",
+		"scenario", scenarioName, "max_iterations", maxIterations, "source", source)
+	return maxIterations
+}
+
+// toolOutputTruncation returns the configured truncation for displayed tool results
+func (s *ScenarioRunner) toolOutputTruncation() message.TruncationConfig {
+	cfg := message.DefaultTruncationConfig()
+	if s.settings != nil {
+		cfg = s.settings.Agent.ToolOutputTruncation()
+	}
+	cfg.Disabled = s.verboseTools
+	return cfg
+}
+
+// ensureMarkedSystemMessage adds marker+content as a system message unless the most
+// recent system message carrying the same marker already has identical content
+func (s *ScenarioRunner) ensureMarkedSystemMessage(marker, content string) {
+	if content == "" {
+		return
+	}
+	candidate := marker + content
+
+	// Find the most recent matching marker message
+	var lastMatched string
+	for _, msg := range s.sharedState.GetMessages() {
+		if msg.Type() == message.MessageTypeSystem && strings.HasPrefix(msg.Content(), marker) {
+			lastMatched = msg.Content()
+		}
+	}
+
+	if lastMatched != candidate {
+		s.sharedState.AddMessage(message.NewSystemMessage(candidate))
+	}
+}
+
+// systemPreamble resolves the configured preamble, logging (not failing) on errors
+func (s *ScenarioRunner) systemPreamble() string {
+	if s.settings == nil {
+		return ""
+	}
+	preamble, err := s.settings.Agent.ResolveSystemPreamble(s.workingDir)
+	if err != nil {
+		s.logger.Warn("Ignoring system preamble", "error", err)
+		return ""
+	}
+	return preamble
+}
+
+// toolConcurrency returns the configured worker count for batched read-only tool calls
+func (s *ScenarioRunner) toolConcurrency() int {
+	if s.settings == nil {
+		return 1
+	}
+	return s.settings.Agent.ToolConcurrency
+}
+
+// maxRepeatedToolCalls returns how many identical tool calls in a row are executed
+func (s *ScenarioRunner) maxRepeatedToolCalls() int {
+	if s.settings == nil {
+		return react.DefaultMaxRepeatedToolCalls
+	}
+	return s.settings.Agent.MaxRepeatedToolCalls
+}
+
+// maxToolResultChars returns how much of each tool result is kept in state (0 = unlimited)
+func (s *ScenarioRunner) maxToolResultChars() int {
+	if s.settings == nil {
+		return 0
+	}
+	return s.settings.Agent.MaxToolResultChars
+}
+
+// tokenBudget returns the configured per-run token limit and pricing
+func (s *ScenarioRunner) tokenBudget() react.TokenBudget {
+	if s.settings == nil {
+		return react.TokenBudget{}
+	}
+	return react.TokenBudget{
+		MaxTotalTokens:  s.settings.Agent.MaxTotalTokens,
+		InputCostPer1K:  s.settings.Agent.InputCostPer1K,
+		OutputCostPer1K: s.settings.Agent.OutputCostPer1K,
+	}
+}
+
+// LastRunStats returns token usage and tool-call counts for the most recent invocation
+func (s *ScenarioRunner) LastRunStats() RunStats {
+	s.statsMu.Lock()
+	defer s.statsMu.Unlock()
+	return s.lastRun
+}
+
+// resetRunStats clears the statistics at the start of an invocation
+func (s *ScenarioRunner) resetRunStats() {
+	s.statsMu.Lock()
+	defer s.statsMu.Unlock()
+	s.lastRun = RunStats{}
+}
+
+// recordRunUsage adds the tokens and time the finished ReAct loop consumed
+func (s *ScenarioRunner) recordRunUsage(reactClient *react.ReAct, start time.Time) {
+	s.statsMu.Lock()
+	defer s.statsMu.Unlock()
+	usage := reactClient.TotalUsage()
+	s.lastRun.Usage.InputTokens += usage.InputTokens
+	s.lastRun.Usage.OutputTokens += usage.OutputTokens
+	s.lastRun.Usage.TotalTokens += usage.TotalTokens
+	latency := reactClient.Latency()
+	s.lastRun.Latency.Add(latency)
+	s.lastRun.Elapsed += time.Since(start)
+	s.lastRun.Stopped = reactClient.StopReason()
+	s.sessionLatency.Add(latency)
+}
+
+// SecretScrubber returns the scrubber applied to tool results, or nil when redaction is disabled
+func (s *ScenarioRunner) SecretScrubber() *secret.Scrubber {
+	return s.scrubber
+}
+
+// SessionLatency returns the LLM and tool time accumulated since the session started
+func (s *ScenarioRunner) SessionLatency() react.Latency {
+	s.statsMu.Lock()
+	defer s.statsMu.Unlock()
+	var l react.Latency
+	l.Add(s.sessionLatency)
+	return l
+}
+
+// resetSessionLatency forgets the accumulated time when the conversation is replaced
+func (s *ScenarioRunner) resetSessionLatency() {
+	s.statsMu.Lock()
+	defer s.statsMu.Unlock()
+	s.sessionLatency = react.Latency{}
+}
+
+// countToolCall records a started tool call; tools may run concurrently
+func (s *ScenarioRunner) countToolCall() {
+	s.statsMu.Lock()
+	defer s.statsMu.Unlock()
+	s.lastRun.ToolCalls++
+}
+
+// StreamEvents forwards every agent event (tool start, tool result, thinking chunk,
+// response) to ch instead of formatting it for the terminal, so programmatic
+// consumers can drive the agent. Pass nil to restore human-readable output; once
+// StreamEvents(nil) returns no further events are sent and ch may be closed.
+func (s *ScenarioRunner) StreamEvents(ch chan<- events.AgentEvent) {
+	s.eventMu.Lock()
+	defer s.eventMu.Unlock()
+	s.eventSink = ch
+}
+
+// streamingEvents reports whether events go to a stream sink instead of the writer
+func (s *ScenarioRunner) streamingEvents() bool {
+	s.eventMu.Lock()
+	defer s.eventMu.Unlock()
+	return s.eventSink != nil
+}
+
+// forwardEvent sends the event to the stream sink if one is set
+func (s *ScenarioRunner) forwardEvent(event events.AgentEvent) bool {
+	s.eventMu.Lock()
+	defer s.eventMu.Unlock()
+	if s.eventSink == nil {
+		return false
+	}
+	s.eventSink <- event
+	return true
+}
+
 // setupEventHandlers configures event handlers to convert events back to output format
 func (s *ScenarioRunner) setupEventHandlers(emitter events.EventEmitter) {
+	s.streamMu.Lock()
+	s.streamStarted = false
+	s.streamedResponse.Reset()
+	s.streamMu.Unlock()
+	if s.bashToolManager != nil {
+		s.bashToolManager.SetOutputHandler(func(line string) {
+			// The tool result is scrubbed (and counted) by the agent loop; streamed
+			// lines reach the terminal and event streams before it
+			line = s.scrubber.RedactUncounted(line)
+			emitter.EmitEvent(events.EventTypeToolOutput, events.ToolOutputData{ToolName: "bash", Line: line})
+		})
+	}
 	emitter.AddHandler(func(event events.AgentEvent) {
+		if event.Type == events.EventTypeToolCallStart {
+			s.countToolCall()
+		}
+		if s.forwardEvent(event) {
+			return
+		}
+
 		writer := s.OutWriter()
 		if writer == nil {
 			return
 		}
+		s.streamMu.Lock()
+		defer s.streamMu.Unlock()
 
 		switch event.Type {
 		case events.EventTypeToolCallStart:
+			// Text streamed before a tool call was narration, not the answer
+			s.endResponseStreamLocked(writer)
 			if data, ok := event.Data.(events.ToolCallStartData); ok {
 				fmt.Fprintf(writer, "🔧 Running tool %s %v\n", data.ToolName, data.Arguments)
 			}
+			s.toolOutputLines = 0
+
+		case events.EventTypeToolOutput:
+			if data, ok := event.Data.(events.ToolOutputData); ok {
+				fmt.Fprintf(writer, "\x1b[90m│ %s\x1b[0m\n", data.Line)
+				s.toolOutputLines++
+			}
 
 		case events.EventTypeToolResult:
 			if data, ok := event.Data.(events.ToolResultData); ok {
+				streamed := s.toolOutputLines > 0
+				s.toolOutputLines = 0
+				if streamed {
+					// The output was shown as it arrived; for failures repeat
+					// only the summary line (exit code or timeout)
+					if data.IsError {
+						summary, _, _ := strings.Cut(data.Content, "\n")
+						fmt.Fprintf(writer, "❌ %s\n", summary)
+					}
+					return
+				}
 				if data.Content == "" {
 					fmt.Fprintln(writer, "↳ (no output)")
-				} else if data.IsError {
-					// Show error messages with error icon
-					lines := strings.Split(data.Content, "\n")
-					for _, line := range lines {
-						fmt.Fprintf(writer, "❌ %s\n", line)
-					}
 				} else {
-					// Show successful results with arrow
-					lines := strings.Split(data.Content, "\n")
-					maxLines := 5
-					if len(lines) > maxLines {
-						fmt.Fprintf(writer, "↳ ...(%d more lines)\n", len(lines)-maxLines)
-						lines = lines[len(lines)-maxLines:]
+					// Keep head and tail of large outputs so error summaries and final status stay visible
+					content := message.TruncateHeadTail(data.Content, s.toolOutputTruncation())
+					prefix := "↳"
+					if data.IsError {
+						prefix = "❌"
 					}
-					for _, line := range lines {
-						if len(line) > 80 {
-							line = line[:77] + "..."
-						}
-						fmt.Fprintf(writer, "↳ %s\n", line)
+					for _, line := range strings.Split(content, "\n") {
+						fmt.Fprintf(writer, "%s %s\n", prefix, line)
 					}
 				}
 			}
@@ -605,6 +1395,25 @@ func (s *ScenarioRunner) setupEventHandlers(emitter events.EventEmitter) {
 				fmt.Fprintf(writer, "\x1b[90m%s", data.Content)
 			}
 
+		case events.EventTypeResponseChunk:
+			if data, ok := event.Data.(events.ResponseChunkData); ok {
+				if s.thinkingStarted {
+					fmt.Fprint(writer, "\x1b[0m\n") // End thinking before the answer
+					s.thinkingStarted = false
+				}
+				if s.markdownOutput {
+					// Held back until complete: rendered before the next tool call or by WriteResponse
+					s.streamedResponse.WriteString(data.Content)
+					break
+				}
+				if !s.streamStarted {
+					WriteResponseHeader(writer, s.llmClient.ModelID(), s.interactive)
+					s.streamStarted = true
+				}
+				fmt.Fprint(writer, data.Content)
+				s.streamedResponse.WriteString(data.Content)
+			}
+
 		case events.EventTypeResponse:
 			// Reset thinking state when response is complete
 			if s.thinkingStarted {
@@ -616,6 +1425,18 @@ func (s *ScenarioRunner) setupEventHandlers(emitter events.EventEmitter) {
 			if data, ok := event.Data.(events.ErrorData); ok {
 				fmt.Fprintf(writer, "❌ Error: %v\n", data.Error)
 			}
+
+		case events.EventTypeUsage:
+			if data, ok := event.Data.(events.UsageData); ok {
+				line := fmt.Sprintf("📊 Tokens used: %d (in %d, out %d)", data.TotalTokens, data.InputTokens, data.OutputTokens)
+				if data.MaxTotalTokens > 0 {
+					line += fmt.Sprintf(" of %d budget", data.MaxTotalTokens)
+				}
+				if data.EstimatedCost > 0 {
+					line += fmt.Sprintf(", est. cost $%.4f", data.EstimatedCost)
+				}
+				fmt.Fprintln(writer, line)
+			}
 		}
 	})
 }
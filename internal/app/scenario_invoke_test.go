@@ -2,6 +2,7 @@ package app
 
 import (
 	"context"
+	"io"
 	"testing"
 	"time"
 
@@ -9,6 +10,7 @@ import (
 	"github.com/fpt/go-gennai-cli/internal/tool"
 	"github.com/fpt/go-gennai-cli/pkg/agent/domain"
 	"github.com/fpt/go-gennai-cli/pkg/agent/state"
+	pkgLogger "github.com/fpt/go-gennai-cli/pkg/logger"
 	"github.com/fpt/go-gennai-cli/pkg/message"
 )
 
@@ -67,6 +69,7 @@ func TestInvokeWithScenario(t *testing.T) {
 		workingDir:       workingDir,
 		scenarios:        mockScenarios,
 		sessionFilePath:  "", // No session persistence in tests
+		logger:           pkgLogger.NewLoggerWithConsoleWriter(pkgLogger.LogLevelInfo, io.Discard),
 	}
 
 	t.Run("Valid scenario", func(t *testing.T) {
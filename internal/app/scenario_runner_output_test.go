@@ -9,9 +9,12 @@ import (
 
 	"github.com/fpt/go-gennai-cli/internal/config"
 	"github.com/fpt/go-gennai-cli/internal/infra"
+	"github.com/fpt/go-gennai-cli/internal/tool"
 	"github.com/fpt/go-gennai-cli/pkg/agent/domain"
+	"github.com/fpt/go-gennai-cli/pkg/agent/events"
 	pkgLogger "github.com/fpt/go-gennai-cli/pkg/logger"
 	"github.com/fpt/go-gennai-cli/pkg/message"
+	"github.com/fpt/go-gennai-cli/pkg/secret"
 )
 
 // mockToolLLM is a minimal ToolCallingLLM that emits thinking content and returns a simple response.
@@ -47,7 +50,7 @@ func TestScenarioRunner_OutputWriter_Thinking(t *testing.T) {
 	settings := config.GetDefaultSettings()
 
 	fsRepo := infra.NewOSFilesystemRepository()
-	runner := NewScenarioRunnerWithOptions(llm, ".", map[string]domain.ToolManager{}, settings, logger, &buf, true, true, fsRepo)
+	runner := NewScenarioRunnerWithOptions(llm, ".", map[string]domain.ToolManager{}, settings, logger, &buf, true, true, "", fsRepo)
 
 	// Invoke using universal tools path (avoids scenario YAMLs)
 	_, err := runner.InvokeWithOptions(context.Background(), "hello")
@@ -72,3 +75,25 @@ func TestScenarioRunner_OutputWriter_Thinking(t *testing.T) {
 		t.Fatalf("expected thinking content in output, got: %q", out)
 	}
 }
+
+// Streamed bash lines reach the terminal before the scrubbed tool result
+func TestScenarioRunner_RedactsStreamedBashOutput(t *testing.T) {
+	scrubber, err := secret.NewScrubber(secret.Options{IgnoreEnv: true, Patterns: []string{`internal-[0-9a-f]{8}`}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	bashManager := tool.NewBashToolManager(tool.BashConfig{WorkingDir: t.TempDir(), MaxDuration: 10 * time.Second})
+	runner := &ScenarioRunner{llmClient: &mockToolLLM{}, out: &buf, bashToolManager: bashManager, scrubber: scrubber}
+	runner.setupEventHandlers(events.NewSimpleEventEmitter())
+
+	if _, err := bashManager.CallTool(context.Background(), "bash", message.ToolArgumentValues{"command": "echo key=internal-deadbeef"}); err != nil {
+		t.Fatal(err)
+	}
+	if out := buf.String(); strings.Contains(out, "deadbeef") || !strings.Contains(out, "key="+secret.Redacted) {
+		t.Errorf("expected the streamed line redacted, got %q", out)
+	}
+	if scrubber.Total() != 0 {
+		t.Errorf("streamed lines should not count as redactions, got %d", scrubber.Total())
+	}
+}
@@ -0,0 +1,55 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/fpt/go-gennai-cli/pkg/agent/domain"
+	"github.com/fpt/go-gennai-cli/pkg/client"
+	pkgLogger "github.com/fpt/go-gennai-cli/pkg/logger"
+	"github.com/fpt/go-gennai-cli/pkg/message"
+)
+
+const scenarioSelectionPrompt = `Pick the scenario best suited to the user's request. Available scenarios:
+
+%s
+Answer with the scenario name exactly as listed and a short reason for the choice.`
+
+// SelectScenario asks the LLM which loaded scenario fits the user input best.
+// The choice is remembered so Invoke passes its reasoning to the scenario prompt.
+func (s *ScenarioRunner) SelectScenario(ctx context.Context, userInput string) (ActionSelectionResponse, error) {
+	structured, err := client.NewStructuredClient[ActionSelectionResponse](s.llmClient)
+	if err != nil {
+		return ActionSelectionResponse{}, err
+	}
+
+	names := make([]string, 0, len(s.scenarios))
+	for name := range s.scenarios {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	var list strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&list, "- %s: %s\n", name, s.scenarios[name].Description())
+	}
+
+	messages := []message.Message{
+		message.NewSystemMessage(fmt.Sprintf(scenarioSelectionPrompt, list.String())),
+		message.NewChatMessage(message.MessageTypeUser, userInput),
+	}
+	selection, err := structured.ChatWithStructure(ctx, messages, false, nil)
+	s.addRunUsage(structured)
+	if err != nil {
+		return ActionSelectionResponse{}, fmt.Errorf("scenario selection failed: %w", domain.LLMError(err))
+	}
+
+	selection.Action = strings.ToUpper(strings.TrimSpace(selection.Action))
+	if _, exists := s.scenarios[selection.Action]; !exists {
+		return ActionSelectionResponse{}, fmt.Errorf("model selected unknown scenario %q", selection.Action)
+	}
+	s.logger.DebugWithIntention(pkgLogger.IntentionDebug, "Selected scenario", "scenario", selection.Action, "reasoning", selection.Reasoning)
+	s.selection = &selection
+	return selection, nil
+}
@@ -0,0 +1,72 @@
+package app
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/fpt/go-gennai-cli/internal/infra"
+	pkgLogger "github.com/fpt/go-gennai-cli/pkg/logger"
+	"github.com/fpt/go-gennai-cli/pkg/message"
+)
+
+// mockSelectionLLM answers scenario selection with a fixed choice
+type mockSelectionLLM struct {
+	choice   ActionSelectionResponse
+	received []message.Message
+}
+
+func (m *mockSelectionLLM) Chat(ctx context.Context, messages []message.Message, enableThinking bool, thinkingChan chan<- string) (message.Message, error) {
+	return message.NewChatMessage(message.MessageTypeAssistant, m.choice.Action), nil
+}
+
+func (m *mockSelectionLLM) ChatWithStructure(ctx context.Context, messages []message.Message, enableThinking bool, thinkingChan chan<- string) (ActionSelectionResponse, error) {
+	m.received = messages
+	return m.choice, nil
+}
+
+func (m *mockSelectionLLM) ModelID() string { return "mock-selection" }
+
+func TestSelectScenario(t *testing.T) {
+	scenarios := make(infra.ScenarioMap)
+	scenarios["CODE"] = infra.NewScenarioConfig("CODE", "default", "Comprehensive coding assistant", "{{userInput}}")
+	scenarios["RESEARCH"] = infra.NewScenarioConfig("RESEARCH", "default", "Web research and information gathering", "{{userInput}}")
+
+	tests := []struct {
+		name    string
+		choice  ActionSelectionResponse
+		want    string
+		wantErr bool
+	}{
+		{name: "known scenario", choice: ActionSelectionResponse{Action: "RESEARCH", Reasoning: "needs current web sources"}, want: "RESEARCH"},
+		{name: "case-insensitive", choice: ActionSelectionResponse{Action: " code ", Reasoning: "changes the repository"}, want: "CODE"},
+		{name: "unknown scenario", choice: ActionSelectionResponse{Action: "DEPLOY", Reasoning: "ships the build"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			llm := &mockSelectionLLM{choice: tt.choice}
+			runner := &ScenarioRunner{
+				llmClient: llm,
+				scenarios: scenarios,
+				logger:    pkgLogger.NewLoggerWithConsoleWriter(pkgLogger.LogLevelInfo, io.Discard),
+			}
+			got, err := runner.SelectScenario(context.Background(), "compare Go web frameworks")
+			if tt.wantErr {
+				if err == nil || runner.selection != nil {
+					t.Fatalf("expected an error and no remembered selection, got %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SelectScenario: %v", err)
+			}
+			if got.Action != tt.want || runner.selection == nil || runner.selection.Reasoning != tt.choice.Reasoning {
+				t.Errorf("got %+v, want %s with its reasoning remembered", got, tt.want)
+			}
+			if prompt := llm.received[0].Content(); !strings.Contains(prompt, "- CODE: Comprehensive coding assistant") || !strings.Contains(prompt, "- RESEARCH:") {
+				t.Errorf("expected the scenarios listed in the prompt, got %q", prompt)
+			}
+		})
+	}
+}
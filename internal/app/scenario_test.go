@@ -3,12 +3,17 @@ package app
 import (
 	"context"
 	"encoding/json"
+	"io"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/fpt/go-gennai-cli/internal/config"
 	"github.com/fpt/go-gennai-cli/internal/infra"
 	"github.com/fpt/go-gennai-cli/internal/tool"
+	"github.com/fpt/go-gennai-cli/pkg/agent/domain"
 	"github.com/fpt/go-gennai-cli/pkg/agent/state"
+	pkgLogger "github.com/fpt/go-gennai-cli/pkg/logger"
 	"github.com/fpt/go-gennai-cli/pkg/message"
 )
 
@@ -168,6 +173,103 @@ func TestScenarioBasedToolSelection(t *testing.T) {
 	}
 }
 
+func TestOfflineModeWithholdsWebTools(t *testing.T) {
+	scenarios := make(infra.ScenarioMap)
+	scenarios["RESEARCH"] = infra.NewScenarioConfig("RESEARCH", "default", "Research", "Mock prompt")
+
+	runner := &ScenarioRunner{
+		llmClient:        &mockLLM{},
+		universalManager: tool.NewCompositeToolManager(tool.NewInMemoryTodoToolManager()),
+		webToolManager:   tool.NewWebToolManager().(*tool.WebToolManager),
+		sharedState:      state.NewMessageState(),
+		scenarios:        scenarios,
+		settings:         &config.Settings{Offline: true},
+		logger:           pkgLogger.NewLoggerWithConsoleWriter(pkgLogger.LogLevelInfo, io.Discard),
+	}
+
+	tools := runner.getToolManagerForScenario("RESEARCH").GetTools()
+	for _, name := range []message.ToolName{"WebFetch", "WebSearch"} {
+		if _, exists := tools[name]; exists {
+			t.Errorf("offline mode should withhold %s even though the scenario requests web tools", name)
+		}
+	}
+}
+
+func TestToolInventoryGroupsBySource(t *testing.T) {
+	scenarios := make(infra.ScenarioMap)
+	scenarios["RESEARCH"] = infra.NewScenarioConfig("RESEARCH", "default", "Research", "Mock prompt")
+	scenarios["CODE"] = infra.NewScenarioConfig("CODE", "filesystem", "Code", "Mock prompt")
+
+	runner := &ScenarioRunner{
+		llmClient:        &mockLLM{},
+		universalManager: tool.NewCompositeToolManager(tool.NewInMemoryTodoToolManager()),
+		webToolManager:   tool.NewWebToolManager().(*tool.WebToolManager),
+		mcpToolManagers:  map[string]domain.ToolManager{},
+		sharedState:      state.NewMessageState(),
+		scenarios:        scenarios,
+		logger:           pkgLogger.NewLoggerWithConsoleWriter(pkgLogger.LogLevelInfo, io.Discard),
+	}
+
+	sources := func(scenario string) []string {
+		var out []string
+		for _, g := range runner.toolGroups(scenario) {
+			out = append(out, g.source)
+		}
+		return out
+	}
+	if got := strings.Join(sources("RESEARCH"), ","); got != "universal,web,meta" {
+		t.Errorf("RESEARCH sources = %s, want universal,web,meta", got)
+	}
+	if got := strings.Join(sources("CODE"), ","); got != "universal,meta" {
+		t.Errorf("CODE sources = %s, want universal,meta", got)
+	}
+
+	// The model can inspect the same listing through list_available_tools
+	res, err := runner.getToolManagerForScenario("RESEARCH").CallTool(context.Background(), "list_available_tools", message.ToolArgumentValues{})
+	if err != nil || res.Error != "" {
+		t.Fatalf("list_available_tools failed: %v %s", err, res.Error)
+	}
+	for _, want := range []string{"Tools for scenario RESEARCH", "web (2)", "• WebFetch —", "• todo_write —", "• list_available_tools —"} {
+		if !strings.Contains(res.Text, want) {
+			t.Errorf("inventory missing %q:\n%s", want, res.Text)
+		}
+	}
+}
+
+// TestContinueSession verifies that one-shot runs with --continue share the persisted session
+func TestContinueSession(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	workDir := t.TempDir()
+	newRunner := func() *ScenarioRunner {
+		return &ScenarioRunner{
+			workingDir:  workDir,
+			sharedState: state.NewMessageState(),
+			logger:      pkgLogger.NewLoggerWithConsoleWriter(pkgLogger.LogLevelInfo, io.Discard),
+		}
+	}
+
+	first := newRunner()
+	if err := first.ContinueSession(); err != nil {
+		t.Fatalf("ContinueSession failed: %v", err)
+	}
+	if first.sessionFilePath == "" {
+		t.Fatal("expected session persistence to be enabled")
+	}
+	first.sharedState.AddMessage(message.NewChatMessage(message.MessageTypeUser, "remember the number 42"))
+	if err := first.sharedState.SaveToFile(); err != nil {
+		t.Fatalf("SaveToFile failed: %v", err)
+	}
+
+	second := newRunner()
+	if err := second.ContinueSession(); err != nil {
+		t.Fatalf("ContinueSession failed: %v", err)
+	}
+	messages := second.sharedState.GetMessages()
+	if len(messages) != 1 || messages[0].Content() != "remember the number 42" {
+		t.Errorf("expected the previous turn to be restored, got %d messages", len(messages))
+	}
+}
+
 // TestCompositeToolManager tests the composite tool manager functionality
 func TestCompositeToolManager(t *testing.T) {
 	// Create individual tool managers with safe subdirectory
@@ -273,3 +375,60 @@ func TestMCPToolIntegration(t *testing.T) {
 		})
 	}
 }
+
+func TestSwitchModel_KeepsClientOnFailure(t *testing.T) {
+	t.Setenv("ANTHROPIC_API_KEY", "")
+	llmClient := &mockLLM{}
+	runner := &ScenarioRunner{
+		llmClient:   llmClient,
+		sharedState: state.NewMessageState(),
+		settings:    config.GetDefaultSettings(),
+	}
+
+	if err := runner.SwitchModel(context.Background(), "bogus", "some-model"); err == nil {
+		t.Error("expected error for unknown backend")
+	}
+	if err := runner.SwitchModel(context.Background(), "anthropic", "claude-sonnet-4-0"); err == nil {
+		t.Error("expected error for missing API key")
+	}
+
+	if runner.GetLLMClient() != llmClient {
+		t.Error("expected the original client to remain active")
+	}
+	backend, model := runner.CurrentModel()
+	if backend != "ollama" || model != "gpt-oss:latest" {
+		t.Errorf("expected settings unchanged, got %s/%s", backend, model)
+	}
+}
+
+func TestEnsureMarkedSystemMessage(t *testing.T) {
+	runner := &ScenarioRunner{sharedState: state.NewMessageState()}
+	countMarked := func() int {
+		n := 0
+		for _, msg := range runner.sharedState.GetMessages() {
+			if msg.Type() == message.MessageTypeSystem && strings.HasPrefix(msg.Content(), "[[SYSTEM_PREAMBLE]]\n") {
+				n++
+			}
+		}
+		return n
+	}
+
+	runner.ensureMarkedSystemMessage("[[SYSTEM_PREAMBLE]]\n", "prefer table-driven tests")
+	runner.ensureMarkedSystemMessage("[[SYSTEM_PREAMBLE]]\n", "prefer table-driven tests")
+	if n := countMarked(); n != 1 {
+		t.Fatalf("expected preamble to be inserted once, got %d", n)
+	}
+
+	runner.ensureMarkedSystemMessage("[[SYSTEM_PREAMBLE]]\n", "")
+	if n := countMarked(); n != 1 {
+		t.Errorf("expected empty preamble to be ignored, got %d", n)
+	}
+
+	// After compaction replaces older messages, the preamble is restored on the next turn
+	runner.sharedState.Clear()
+	runner.sharedState.AddMessage(message.NewSummarySystemMessage("# Previous Conversation Summary"))
+	runner.ensureMarkedSystemMessage("[[SYSTEM_PREAMBLE]]\n", "prefer table-driven tests")
+	if n := countMarked(); n != 1 {
+		t.Errorf("expected preamble to be restored after compaction, got %d", n)
+	}
+}
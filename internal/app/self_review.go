@@ -0,0 +1,105 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/fpt/go-gennai-cli/pkg/agent/react"
+	"github.com/fpt/go-gennai-cli/pkg/message"
+)
+
+// maxSelfReviewDiffBytes caps the diff sent to the model for review
+const maxSelfReviewDiffBytes = 64 * 1024
+
+// selfReviewApproval is the first line of a review that found nothing to fix
+const selfReviewApproval = "LGTM"
+
+const selfReviewPrompt = `Review the changes you just made. This is the diff of every file you changed in this task:
+
+%s
+Find bugs, missed edge cases, and style issues that don't match the surrounding code. Don't change any files in this step.
+If there is nothing worth fixing, answer with the single line ` + selfReviewApproval + `. Otherwise list each issue with the file and what should change.`
+
+const selfReviewFixPrompt = "Fix the issues from your review. Leave anything else unchanged."
+
+// selfReviewEnabled reports whether finished tasks get a self-review pass.
+// Dry runs write nothing, so there is no diff to review.
+func (s *ScenarioRunner) selfReviewEnabled() bool {
+	return s.settings != nil && s.settings.Agent.SelfReview && s.fsToolManager != nil && !s.dryRun
+}
+
+// runSelfReview feeds the diff of the task's file changes back to the model
+// and asks it to critique them. When fix rounds are configured and the review
+// finds issues, the model fixes them and the new diff is reviewed again. The
+// returned message is the original answer followed by the review.
+func (s *ScenarioRunner) runSelfReview(ctx context.Context, reactClient *react.ReAct, result message.Message) (message.Message, error) {
+	diff, files := s.fsToolManager.ChangesDiff(ctx)
+	if files == 0 {
+		return result, nil
+	}
+
+	// Show the answer now so the review follows it instead of replacing it
+	writer := s.OutWriter()
+	printing := !s.streamingEvents()
+	if printing {
+		s.WriteResponse(writer, result)
+	}
+
+	rounds := s.settings.Agent.SelfReviewFixRounds
+	var review message.Message
+	for round := 0; ; round++ {
+		if printing {
+			fmt.Fprintf(writer, "\n🔍 Self-review of %d changed file(s)...\n", files)
+		}
+		var err error
+		review, err = s.runWithApproval(ctx, reactClient, fmt.Sprintf(selfReviewPrompt, truncateReviewDiff(diff)))
+		if err != nil {
+			return nil, fmt.Errorf("self-review failed: %w", err)
+		}
+		if round >= rounds || reviewApproved(review.Content()) || reactClient.StopReason() != nil {
+			break
+		}
+
+		if printing {
+			s.WriteResponse(writer, review)
+			fmt.Fprintf(writer, "\n🔧 Fixing review findings (round %d of %d)...\n", round+1, rounds)
+		}
+		if _, err := s.runWithApproval(ctx, reactClient, selfReviewFixPrompt); err != nil {
+			return nil, fmt.Errorf("self-review fix failed: %w", err)
+		}
+		if reactClient.StopReason() != nil {
+			break
+		}
+		if diff, files = s.fsToolManager.ChangesDiff(ctx); files == 0 {
+			// The fixes reverted every change, so there is nothing left to review
+			break
+		}
+	}
+
+	combined := fmt.Sprintf("%s\n\n## Self-review\n\n%s", result.Content(), review.Content())
+	// The answer was already written above, so only the streamed review counts
+	// as shown when the caller writes the combined response
+	s.streamMu.Lock()
+	if s.streamStarted && s.streamedResponse.String() == review.Content() {
+		s.streamedResponse.Reset()
+		s.streamedResponse.WriteString(combined)
+	}
+	s.streamMu.Unlock()
+	return message.NewChatMessage(message.MessageTypeAssistant, combined), nil
+}
+
+// reviewApproved reports whether a review found nothing to fix
+func reviewApproved(review string) bool {
+	first, _, _ := strings.Cut(strings.TrimSpace(review), "\n")
+	return strings.EqualFold(strings.Trim(strings.TrimSpace(first), "*.!"), selfReviewApproval)
+}
+
+// truncateReviewDiff keeps the start of a diff too large to send for review
+func truncateReviewDiff(diff string) string {
+	if len(diff) <= maxSelfReviewDiffBytes {
+		return diff
+	}
+	cut := strings.LastIndexByte(diff[:maxSelfReviewDiffBytes], '\n') + 1
+	return diff[:cut] + fmt.Sprintf("(diff truncated: %d of %d bytes shown)\n", cut, len(diff))
+}
@@ -0,0 +1,39 @@
+package app
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReviewApproved(t *testing.T) {
+	cases := map[string]bool{
+		"LGTM":                            true,
+		"  lgtm.\n":                       true,
+		"**LGTM**":                        true,
+		"LGTM\nNothing else to change.":   true,
+		"- main.go: the error is ignored": false,
+		"Mostly LGTM, but the loop is off by one": false,
+	}
+	for review, want := range cases {
+		if got := reviewApproved(review); got != want {
+			t.Errorf("reviewApproved(%q) = %v, want %v", review, got, want)
+		}
+	}
+}
+
+func TestTruncateReviewDiff(t *testing.T) {
+	small := "--- a/x\n+++ b/x\n"
+	if got := truncateReviewDiff(small); got != small {
+		t.Errorf("expected small diff unchanged, got %q", got)
+	}
+
+	large := strings.Repeat("+line of an added file\n", maxSelfReviewDiffBytes/10)
+	got := truncateReviewDiff(large)
+	if len(got) > maxSelfReviewDiffBytes+100 {
+		t.Errorf("expected diff cut near %d bytes, got %d", maxSelfReviewDiffBytes, len(got))
+	}
+	body, note, _ := strings.Cut(got, "(diff truncated")
+	if !strings.HasSuffix(body, "\n") || note == "" {
+		t.Errorf("expected the cut at a line end followed by a note, got tail %q", got[len(got)-80:])
+	}
+}
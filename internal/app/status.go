@@ -0,0 +1,124 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/fpt/go-gennai-cli/pkg/agent/domain"
+	"github.com/fpt/go-gennai-cli/pkg/agent/react"
+	"github.com/fpt/go-gennai-cli/pkg/message"
+)
+
+// Message types in the order /status lists them
+var statusMessageTypes = []struct {
+	Type  message.MessageType
+	Label string
+}{
+	{message.MessageTypeUser, "user"},
+	{message.MessageTypeAssistant, "assistant"},
+	{message.MessageTypeToolCall, "tool call"},
+	{message.MessageTypeToolCallBatch, "tool batch"},
+	{message.MessageTypeToolResult, "tool result"},
+	{message.MessageTypeReasoning, "reasoning"},
+	{message.MessageTypeSystem, "system"},
+}
+
+// sessionStatus is the activity and cost summary shown by /status
+type sessionStatus struct {
+	MessagesByType map[message.MessageType]int
+	ToolCalls      map[message.ToolName]int
+
+	InputTokens, OutputTokens, TotalTokens int
+
+	ContextTokens, ContextMax, ContextPercent int
+	ContextWindow                             int // model context window, known even before the first message
+
+	Latency    react.Latency // time spent waiting for the LLM and running tools this session
+	Redactions int           // secrets removed from tool results
+}
+
+// collectSessionStatus reads message counts, token usage and tool calls from
+// the conversation state, and context utilization as shown in the REPL prompt
+func collectSessionStatus(state domain.State, llmClient domain.LLM) sessionStatus {
+	st := sessionStatus{
+		MessagesByType: make(map[message.MessageType]int),
+		ToolCalls:      make(map[message.ToolName]int),
+	}
+	for _, msg := range state.GetMessages() {
+		st.MessagesByType[msg.Type()]++
+		switch m := msg.(type) {
+		case *message.ToolCallMessage:
+			st.ToolCalls[m.ToolName()]++
+		case *message.ToolCallBatchMessage:
+			for _, call := range m.Calls() {
+				st.ToolCalls[call.ToolName()]++
+			}
+		}
+	}
+	st.InputTokens, st.OutputTokens, st.TotalTokens = state.GetTotalTokenUsage()
+	display := NewContextDisplay()
+	st.ContextTokens, st.ContextMax, st.ContextPercent = display.CalculateUsageDetails(state, llmClient)
+	st.ContextWindow = display.ContextWindow(llmClient)
+	return st
+}
+
+// format renders the status as compact emoji-labeled lines
+func (st sessionStatus) format() string {
+	var b strings.Builder
+	b.WriteString("\n📊 Session Status:\n")
+
+	total := 0
+	var parts []string
+	for _, t := range statusMessageTypes {
+		if n := st.MessagesByType[t.Type]; n > 0 {
+			total += n
+			parts = append(parts, fmt.Sprintf("%d %s", n, t.Label))
+		}
+	}
+	if total == 0 {
+		b.WriteString("  💬 Messages: No conversation history\n")
+	} else {
+		fmt.Fprintf(&b, "  💬 Messages: %d (%s)\n", total, strings.Join(parts, ", "))
+	}
+
+	names := make([]message.ToolName, 0, len(st.ToolCalls))
+	calls := 0
+	for name, n := range st.ToolCalls {
+		names = append(names, name)
+		calls += n
+	}
+	if calls == 0 {
+		b.WriteString("  🔧 Tool calls: none\n")
+	} else {
+		// Most used first, ties by name so the output is stable
+		sort.Slice(names, func(i, j int) bool {
+			if st.ToolCalls[names[i]] != st.ToolCalls[names[j]] {
+				return st.ToolCalls[names[i]] > st.ToolCalls[names[j]]
+			}
+			return names[i] < names[j]
+		})
+		perTool := make([]string, len(names))
+		for i, name := range names {
+			perTool[i] = fmt.Sprintf("%s×%d", name, st.ToolCalls[name])
+		}
+		fmt.Fprintf(&b, "  🔧 Tool calls: %d (%s)\n", calls, strings.Join(perTool, ", "))
+	}
+
+	fmt.Fprintf(&b, "  🪙 Tokens: %d in / %d out / %d total\n", st.InputTokens, st.OutputTokens, st.TotalTokens)
+	if st.ContextMax > 0 {
+		fmt.Fprintf(&b, "  🧠 Context: %d/%d tokens (%d%%)\n", st.ContextTokens, st.ContextMax, st.ContextPercent)
+	} else {
+		b.WriteString("  🧠 Context: empty\n")
+	}
+	if st.ContextWindow > 0 {
+		fmt.Fprintf(&b, "  📐 Context window: %d tokens\n", st.ContextWindow)
+	}
+	if st.Latency.LLMCalls > 0 || st.Latency.ToolCalls > 0 {
+		fmt.Fprintf(&b, "  ⏱️ Time: %s\n", st.Latency.Summary())
+	}
+	if st.Redactions > 0 {
+		fmt.Fprintf(&b, "  🔒 Secrets redacted: %d\n", st.Redactions)
+	}
+	return b.String()
+}
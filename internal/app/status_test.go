@@ -0,0 +1,66 @@
+package app
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fpt/go-gennai-cli/pkg/agent/react"
+	"github.com/fpt/go-gennai-cli/pkg/agent/state"
+	"github.com/fpt/go-gennai-cli/pkg/message"
+)
+
+func TestCollectSessionStatus(t *testing.T) {
+	st := state.NewMessageState()
+	st.AddMessage(message.NewChatMessage(message.MessageTypeUser, "list the files"))
+	st.AddMessage(message.NewToolCallMessage("bash", message.ToolArgumentValues{"command": "ls"}))
+	st.AddMessage(message.NewToolCallBatch([]*message.ToolCallMessage{
+		message.NewToolCallMessage("read_file", message.ToolArgumentValues{"file_path": "a.go"}),
+		message.NewToolCallMessage("read_file", message.ToolArgumentValues{"file_path": "b.go"}),
+	}))
+	answer := message.NewChatMessage(message.MessageTypeAssistant, "done")
+	answer.SetTokenUsage(100, 20, 120)
+	st.AddMessage(answer)
+
+	status := collectSessionStatus(st, nil)
+	if got := status.MessagesByType[message.MessageTypeUser]; got != 1 {
+		t.Errorf("user messages = %d, want 1", got)
+	}
+	if got := status.ToolCalls["read_file"]; got != 2 {
+		t.Errorf("read_file calls = %d, want 2", got)
+	}
+	if status.InputTokens != 100 || status.OutputTokens != 20 || status.TotalTokens != 120 {
+		t.Errorf("tokens = %d/%d/%d, want 100/20/120", status.InputTokens, status.OutputTokens, status.TotalTokens)
+	}
+	if status.ContextMax == 0 || status.ContextTokens == 0 {
+		t.Errorf("expected context utilization, got %d/%d", status.ContextTokens, status.ContextMax)
+	}
+
+	status.Latency = react.Latency{LLMCalls: 1, LLMTime: 2 * time.Second}
+	status.Redactions = 2
+	out := status.format()
+	for _, want := range []string{
+		"💬 Messages: 4 (1 user, 1 assistant, 1 tool call, 1 tool batch)",
+		"🔧 Tool calls: 3 (read_file×2, bash×1)",
+		"🪙 Tokens: 100 in / 20 out / 120 total",
+		"🧠 Context: ",
+		"⏱️ Time: LLM 2s (1 calls), no tool calls",
+		"🔒 Secrets redacted: 2",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("status output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestSessionStatusFormatEmpty(t *testing.T) {
+	out := collectSessionStatus(state.NewMessageState(), nil).format()
+	for _, want := range []string{"No conversation history", "Tool calls: none", "Context: empty", "Context window: "} {
+		if !strings.Contains(out, want) {
+			t.Errorf("status output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "Time:") {
+		t.Errorf("status should omit timing before any calls:\n%s", out)
+	}
+}
@@ -0,0 +1,92 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	pkgErrors "github.com/pkg/errors"
+
+	"github.com/fpt/go-gennai-cli/pkg/agent/domain"
+	"github.com/fpt/go-gennai-cli/pkg/client"
+	pkgLogger "github.com/fpt/go-gennai-cli/pkg/logger"
+	"github.com/fpt/go-gennai-cli/pkg/message"
+)
+
+// respondScenario is the scenario whose final answer a response schema constrains
+const respondScenario = "RESPOND"
+
+// SetResponseSchema constrains the final answer of the respond scenario to a
+// JSON schema. Pass nil to return freeform text again.
+func (s *ScenarioRunner) SetResponseSchema(schema json.RawMessage) {
+	s.responseSchema = schema
+}
+
+// structureResponse turns the freeform answer into JSON matching the response
+// schema using the backend's structured-output feature. The JSON is validated
+// and the model is asked once more, with the problems listed, if it does not
+// conform. Backends without structured output keep the freeform answer.
+func (s *ScenarioRunner) structureResponse(ctx context.Context, userInput string, answer message.Message) (message.Message, error) {
+	schemaClient, err := client.NewSchemaClient(s.llmClient)
+	if err != nil {
+		if pkgErrors.Is(err, domain.ErrSchemaUnsupported) {
+			s.logger.Warn("Backend has no structured output; returning freeform answer", "error", err)
+			return answer, nil
+		}
+		return nil, err
+	}
+
+	messages := []message.Message{
+		message.NewSystemMessage("You convert answers into JSON. Output only JSON that conforms to this JSON schema:\n" + string(s.responseSchema)),
+		message.NewChatMessage(message.MessageTypeUser, userInput),
+		message.NewChatMessage(message.MessageTypeAssistant, answer.Content()),
+		message.NewChatMessage(message.MessageTypeUser, "Restate your answer above as JSON that conforms to the schema. Keep its content; do not add anything that is not in the answer."),
+	}
+
+	var problems []string
+	for attempt := 0; attempt < 2; attempt++ {
+		if attempt > 0 {
+			messages = append(messages, message.NewChatMessage(message.MessageTypeUser,
+				"That JSON does not conform to the schema:\n- "+strings.Join(problems, "\n- ")+"\nReturn corrected JSON only."))
+		}
+
+		text, err := schemaClient.ChatWithSchema(ctx, messages, s.responseSchema)
+		s.addRunUsage(schemaClient)
+		if err != nil {
+			if pkgErrors.Is(err, domain.ErrSchemaUnsupported) {
+				s.logger.Warn("Backend has no structured output; returning freeform answer", "error", err)
+				return answer, nil
+			}
+			return nil, fmt.Errorf("structured response failed: %w", domain.LLMError(err))
+		}
+
+		problems, err = client.ValidateJSON([]byte(text), s.responseSchema)
+		if err != nil {
+			return nil, err
+		}
+		if len(problems) == 0 {
+			return message.NewChatMessage(message.MessageTypeAssistant, strings.TrimSpace(text)), nil
+		}
+		s.logger.DebugWithIntention(pkgLogger.IntentionDebug, "Structured response does not match schema", "attempt", attempt+1, "problems", len(problems))
+		messages = append(messages, message.NewChatMessage(message.MessageTypeAssistant, text))
+	}
+	return nil, fmt.Errorf("structured response does not match the schema: %s", strings.Join(problems, "; "))
+}
+
+// addRunUsage adds the tokens of a call made outside the ReAct loop to the run statistics
+func (s *ScenarioRunner) addRunUsage(llm domain.LLM) {
+	provider, ok := llm.(domain.TokenUsageProvider)
+	if !ok {
+		return
+	}
+	usage, ok := provider.LastTokenUsage()
+	if !ok {
+		return
+	}
+	s.statsMu.Lock()
+	defer s.statsMu.Unlock()
+	s.lastRun.Usage.InputTokens += usage.InputTokens
+	s.lastRun.Usage.OutputTokens += usage.OutputTokens
+	s.lastRun.Usage.TotalTokens += usage.TotalTokens
+}
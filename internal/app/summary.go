@@ -0,0 +1,28 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/fpt/go-gennai-cli/pkg/agent/state"
+)
+
+// SummarizeSession asks the current model for a handoff note on the
+// conversation so far: goals, changes by file, decisions, open questions and
+// next steps. The conversation itself is left unchanged.
+func (s *ScenarioRunner) SummarizeSession(ctx context.Context) (string, error) {
+	messages := s.sharedState.GetMessages()
+	if len(messages) == 0 {
+		return "", fmt.Errorf("nothing to summarize yet")
+	}
+	return state.CreateHandoffSummary(ctx, s.llmClient, messages)
+}
+
+// writeSessionSummary saves a summary from SummarizeSession as a Markdown file
+func writeSessionSummary(path, summary string) error {
+	if err := os.WriteFile(path, []byte(summary+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to write summary: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/fpt/go-gennai-cli/internal/tool"
+	"github.com/fpt/go-gennai-cli/pkg/agent/domain"
+	"github.com/fpt/go-gennai-cli/pkg/message"
+)
+
+// maxInventoryDescription bounds each tool description in the tool listing
+const maxInventoryDescription = 120
+
+// toolGroup is a set of active tools that share a source
+type toolGroup struct {
+	source string // "universal", "web", "git", "mcp:<server>" or "meta"
+	tools  []message.Tool
+}
+
+// toolGroups lists the tools active for scenario, grouped by the manager
+// (and for MCP tools, the server) that provides them
+func (s *ScenarioRunner) toolGroups(scenario string) []toolGroup {
+	// Copy the active set so claiming tools never mutates a manager's own map
+	active := make(map[message.ToolName]message.Tool)
+	for name, t := range s.getToolManagerForScenario(scenario).GetTools() {
+		active[name] = t
+	}
+
+	var sources []string
+	bySource := make(map[string][]message.Tool)
+	claim := func(source string, provider map[message.ToolName]message.Tool) {
+		for name := range provider {
+			t, ok := active[name]
+			if !ok {
+				continue
+			}
+			if _, seen := bySource[source]; !seen {
+				sources = append(sources, source)
+			}
+			bySource[source] = append(bySource[source], t)
+			delete(active, name)
+		}
+	}
+
+	claim("universal", s.universalManager.GetTools())
+	if s.webToolManager != nil {
+		claim("web", s.webToolManager.GetTools())
+	}
+	if s.gitToolManager != nil {
+		claim("git", s.gitToolManager.GetTools())
+	}
+	claimedServers := make(map[string]bool)
+	serverNames := make([]string, 0, len(s.mcpToolManagers))
+	for name := range s.mcpToolManagers {
+		serverNames = append(serverNames, name)
+	}
+	sort.Strings(serverNames)
+	for _, name := range serverNames {
+		// Every server may share one manager, so ask it which server owns each tool
+		mcpManager, ok := s.mcpToolManagers[name].(domain.MCPToolManager)
+		if !ok {
+			claim("mcp:"+name, s.mcpToolManagers[name].GetTools())
+			continue
+		}
+		for _, server := range mcpManager.ListServers() {
+			if claimedServers[server] {
+				continue
+			}
+			claimedServers[server] = true
+			serverTools, err := mcpManager.GetMCPTools(server)
+			if err != nil {
+				continue
+			}
+			provided := make(map[message.ToolName]message.Tool, len(serverTools))
+			for _, t := range serverTools {
+				provided[t.Name()] = t
+			}
+			claim("mcp:"+server, provided)
+		}
+	}
+	claim("meta", active)
+
+	groups := make([]toolGroup, 0, len(sources))
+	for _, source := range sources {
+		tools := bySource[source]
+		sort.Slice(tools, func(i, j int) bool { return tools[i].Name() < tools[j].Name() })
+		groups = append(groups, toolGroup{source: source, tools: tools})
+	}
+	return groups
+}
+
+// formatToolInventory renders the active tools for /tools and list_available_tools
+func (s *ScenarioRunner) formatToolInventory(scenario string) string {
+	groups := s.toolGroups(scenario)
+	total := 0
+	for _, g := range groups {
+		total += len(g.tools)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "🔧 Tools for scenario %s (%d):\n", scenario, total)
+	for _, g := range groups {
+		label := g.source
+		if server, ok := strings.CutPrefix(g.source, "mcp:"); ok {
+			label = fmt.Sprintf("MCP server %q", server)
+		}
+		fmt.Fprintf(&b, "\n%s (%d)\n", label, len(g.tools))
+		for _, t := range g.tools {
+			fmt.Fprintf(&b, "  • %s — %s\n", t.Name(), summarizeToolDescription(string(t.Description())))
+		}
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// summarizeToolDescription keeps the first line of a description, shortened
+func summarizeToolDescription(desc string) string {
+	desc = strings.TrimSpace(desc)
+	if i := strings.IndexByte(desc, '\n'); i >= 0 {
+		desc = strings.TrimSpace(desc[:i])
+	}
+	if r := []rune(desc); len(r) > maxInventoryDescription {
+		desc = string(r[:maxInventoryDescription-1]) + "…"
+	}
+	return desc
+}
+
+// newToolInventoryManager provides list_available_tools for scenario
+func (s *ScenarioRunner) newToolInventoryManager(scenario string) *tool.InventoryToolManager {
+	return tool.NewInventoryToolManager(func() string { return s.formatToolInventory(scenario) })
+}
@@ -0,0 +1,54 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/fpt/go-gennai-cli/pkg/agent/react"
+	"github.com/fpt/go-gennai-cli/pkg/message"
+)
+
+const validationFixPrompt = `These files you wrote or edited still fail validation:
+
+%s
+Fix the failures, then summarize what you changed. If a failure is unrelated to your task or cannot be fixed, say so instead of retrying.`
+
+// validationFixRounds returns how many times a task is sent back to fix files
+// that still fail post-edit validation (0 = never). Dry runs write nothing,
+// so there is nothing to validate.
+func (s *ScenarioRunner) validationFixRounds() int {
+	if s.settings == nil || s.fsToolManager == nil || s.dryRun {
+		return 0
+	}
+	return s.settings.Agent.ValidationFixRounds
+}
+
+// runValidationFixes checks the files written or edited during the task
+// again and, while some still fail validation, feeds the failures back to the
+// model and asks it to fix them, up to the configured number of rounds. The
+// returned message is the model's last answer.
+func (s *ScenarioRunner) runValidationFixes(ctx context.Context, reactClient *react.ReAct, result message.Message) (message.Message, error) {
+	rounds := s.validationFixRounds()
+	writer := s.OutWriter()
+	printing := !s.streamingEvents()
+
+	for round := 1; round <= rounds; round++ {
+		report, files := s.fsToolManager.RecheckValidationFailures(ctx)
+		if files == 0 {
+			break
+		}
+		if printing {
+			s.WriteResponse(writer, result)
+			fmt.Fprintf(writer, "\n🔧 %d file(s) fail validation, fixing (round %d of %d)...\n", files, round, rounds)
+		}
+		var err error
+		result, err = s.runWithApproval(ctx, reactClient, fmt.Sprintf(validationFixPrompt, report))
+		if err != nil {
+			return nil, fmt.Errorf("validation fix failed: %w", err)
+		}
+		if reactClient.StopReason() != nil {
+			break
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,152 @@
+package app
+
+import (
+	"context"
+	"io/fs"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fpt/go-gennai-cli/internal/tool"
+)
+
+const (
+	// watchPollInterval is how often the watched files are scanned
+	watchPollInterval = 500 * time.Millisecond
+	// watchDebounce is the quiet period after a change before it is reported,
+	// so an editor's save burst or a formatter run triggers one re-run
+	watchDebounce = 300 * time.Millisecond
+)
+
+// fileStamp identifies a version of a watched file
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// FileWatcher detects changes to the files under a directory that match a
+// glob. It polls modification times, which needs no platform support and
+// works the same on network and container filesystems.
+type FileWatcher struct {
+	root     string
+	pattern  string
+	match    *regexp.Regexp
+	interval time.Duration
+	debounce time.Duration
+}
+
+// NewFileWatcher creates a watcher for the files under root matching pattern.
+// A pattern without "/" matches file names at any depth ("*.go"); otherwise it
+// matches the slash-separated path relative to root, where "**" spans
+// directories ("internal/**/*.go").
+func NewFileWatcher(root, pattern string) (*FileWatcher, error) {
+	match, err := tool.CompilePathGlob(pattern)
+	if err != nil {
+		return nil, err
+	}
+	return &FileWatcher{
+		root:     root,
+		pattern:  pattern,
+		match:    match,
+		interval: watchPollInterval,
+		debounce: watchDebounce,
+	}, nil
+}
+
+// Pattern returns the glob being watched
+func (w *FileWatcher) Pattern() string { return w.pattern }
+
+// WaitForChange blocks until a matching file is created, modified or removed,
+// then returns the changed paths (relative to root, sorted) once no further
+// change has happened for the debounce period. The baseline is taken when it
+// is called, so changes made before the call (e.g. by the agent's own edits
+// in the previous run) do not trigger. It returns ctx.Err() when ctx is done.
+func (w *FileWatcher) WaitForChange(ctx context.Context) ([]string, error) {
+	baseline := w.snapshot()
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+		current := w.snapshot()
+		if len(changedPaths(baseline, current)) == 0 {
+			continue
+		}
+
+		// Wait for the burst to settle, then report everything that changed
+		for {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(w.debounce):
+			}
+			next := w.snapshot()
+			settled := len(changedPaths(current, next)) == 0
+			current = next
+			if settled {
+				break
+			}
+		}
+		if changed := changedPaths(baseline, current); len(changed) > 0 {
+			return changed, nil
+		}
+		// Changes that reverted themselves (e.g. a temp file) are not reported
+		baseline = current
+	}
+}
+
+// snapshot records the matching files under root. Hidden directories such as
+// .git are skipped; unreadable entries are ignored.
+func (w *FileWatcher) snapshot() map[string]fileStamp {
+	files := make(map[string]fileStamp)
+	_ = filepath.WalkDir(w.root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if path != w.root && (strings.HasPrefix(d.Name(), ".") || d.Name() == "node_modules") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(w.root, path)
+		if err != nil {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		if !w.match.MatchString(rel) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		files[rel] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+		return nil
+	})
+	return files
+}
+
+// changedPaths lists the paths that were added, removed or modified between
+// two snapshots, sorted
+func changedPaths(before, after map[string]fileStamp) []string {
+	var changed []string
+	for path, stamp := range after {
+		if old, ok := before[path]; !ok || !old.modTime.Equal(stamp.modTime) || old.size != stamp.size {
+			changed = append(changed, path)
+		}
+	}
+	for path := range before {
+		if _, ok := after[path]; !ok {
+			changed = append(changed, path)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
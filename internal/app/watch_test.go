@@ -0,0 +1,59 @@
+package app
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestFileWatcherWaitForChange(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("main.go", "package main")
+	write("README.md", "readme")
+	write(".git/index.go", "hidden")
+
+	w, err := NewFileWatcher(dir, "*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.interval, w.debounce = 10*time.Millisecond, 30*time.Millisecond
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	result := make(chan []string, 1)
+	go func() {
+		changed, _ := w.WaitForChange(ctx)
+		result <- changed
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	write("README.md", "not watched")
+	write(".git/index.go", "skipped directory")
+	write("pkg/util.go", "package pkg")
+	write("main.go", "package main // changed")
+
+	changed := <-result
+	if want := []string{"main.go", "pkg/util.go"}; !slices.Equal(changed, want) {
+		t.Errorf("changed = %v, want %v", changed, want)
+	}
+
+	// Cancellation ends the wait
+	cancelled, stop := context.WithCancel(context.Background())
+	stop()
+	if _, err := w.WaitForChange(cancelled); err != context.Canceled {
+		t.Errorf("WaitForChange after cancel = %v, want context.Canceled", err)
+	}
+}
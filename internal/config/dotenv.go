@@ -0,0 +1,127 @@
+package config
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// dotEnvFileName is the optional file of provider keys, in the project and in ~/.gennai
+const dotEnvFileName = ".env"
+
+// apiKeyEnv maps each backend to the environment variable holding its API key
+var apiKeyEnv = map[string]string{
+	"anthropic": "ANTHROPIC_API_KEY",
+	"openai":    "OPENAI_API_KEY",
+	"azure":     "AZURE_OPENAI_API_KEY",
+	"gemini":    "GEMINI_API_KEY",
+}
+
+// endpointEnv are the other variables the clients read. They decide where API
+// keys are sent, so only ~/.gennai/.env may set them: a .env in a cloned
+// repository must not redirect your exported keys to another server.
+var endpointEnv = map[string]bool{
+	"OPENAI_BASE_URL":          true,
+	"AZURE_OPENAI_ENDPOINT":    true,
+	"AZURE_OPENAI_DEPLOYMENT":  true,
+	"AZURE_OPENAI_MODEL":       true,
+	"AZURE_OPENAI_API_VERSION": true,
+	"OLLAMA_HOST":              true,
+}
+
+// LoadDotEnv sets provider variables from <workingDir>/.env and then
+// ~/.gennai/.env. Variables that are already set, including by the project
+// file, are never overridden, and other variables in the files (a project's
+// own DATABASE_URL, say) are ignored. Missing files are skipped, and a file
+// that fails to parse does not stop the other from loading. It returns the
+// names of the variables it set and the errors of both files joined.
+func LoadDotEnv(workingDir string) ([]string, error) {
+	var loaded []string
+	load := func(path string, allowEndpoints bool) error {
+		values, err := readDotEnv(path)
+		if err != nil {
+			return err
+		}
+		for _, kv := range values {
+			if !isAPIKeyEnv(kv[0]) && !(allowEndpoints && endpointEnv[kv[0]]) {
+				continue
+			}
+			if _, set := os.LookupEnv(kv[0]); set {
+				continue
+			}
+			if err := os.Setenv(kv[0], kv[1]); err != nil {
+				return err
+			}
+			loaded = append(loaded, kv[0])
+		}
+		return nil
+	}
+
+	projectErr := load(filepath.Join(workingDir, dotEnvFileName), false)
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return loaded, projectErr
+	}
+	return loaded, errors.Join(projectErr, load(filepath.Join(homeDir, ".gennai", dotEnvFileName), true))
+}
+
+// MissingAPIKey returns the API key variable the backend needs but which is
+// not set, or "" when the key is present or the backend needs none
+func MissingAPIKey(backend string) string {
+	name, ok := apiKeyEnv[backend]
+	if !ok || os.Getenv(name) != "" {
+		return ""
+	}
+	return name
+}
+
+func isAPIKeyEnv(name string) bool {
+	for _, key := range apiKeyEnv {
+		if key == name {
+			return true
+		}
+	}
+	return false
+}
+
+// readDotEnv parses KEY=VALUE lines, allowing an "export " prefix, # comments
+// and single or double quoted values. A missing file yields no values.
+func readDotEnv(path string) ([][2]string, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var values [][2]string
+	scanner := bufio.NewScanner(file)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" || strings.ContainsAny(key, " \t") {
+			return nil, fmt.Errorf("%s:%d: expected KEY=VALUE", path, lineNo)
+		}
+		value = strings.TrimSpace(value)
+		if n := len(value); n >= 2 && (value[0] == '"' || value[0] == '\'') && value[n-1] == value[0] {
+			value = value[1 : n-1]
+		} else if i := strings.Index(value, " #"); i >= 0 {
+			value = strings.TrimSpace(value[:i])
+		}
+		values = append(values, [2]string{key, value})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return values, nil
+}
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestLoadDotEnv(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	project := t.TempDir()
+	for _, name := range []string{"ANTHROPIC_API_KEY", "OPENAI_API_KEY", "GEMINI_API_KEY", "OPENAI_BASE_URL", "OLLAMA_HOST", "DATABASE_URL"} {
+		t.Setenv(name, "")
+		os.Unsetenv(name)
+	}
+	t.Setenv("GEMINI_API_KEY", "from-shell")
+
+	write := func(path, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(filepath.Join(project, ".env"), `# project keys
+export ANTHROPIC_API_KEY="sk-ant-project"
+GEMINI_API_KEY=from-project
+OPENAI_BASE_URL=https://attacker.example
+DATABASE_URL=postgres://localhost/app
+`)
+	write(filepath.Join(home, ".gennai", ".env"), `ANTHROPIC_API_KEY=sk-ant-home
+OPENAI_API_KEY='sk-openai-home'
+OLLAMA_HOST=http://gpu-box:11434 # shared server
+`)
+
+	loaded, err := LoadDotEnv(project)
+	if err != nil {
+		t.Fatalf("LoadDotEnv: %v", err)
+	}
+	want := map[string]string{
+		"ANTHROPIC_API_KEY": "sk-ant-project",       // the project file wins over the home file
+		"GEMINI_API_KEY":    "from-shell",           // set variables are never overridden
+		"OPENAI_API_KEY":    "sk-openai-home",       // quotes are stripped
+		"OLLAMA_HOST":       "http://gpu-box:11434", // endpoints load from the home file
+		"OPENAI_BASE_URL":   "",                     // but not from the project file
+		"DATABASE_URL":      "",                     // unrelated variables are ignored
+	}
+	for name, value := range want {
+		if got := os.Getenv(name); got != value {
+			t.Errorf("%s = %q, want %q", name, got, value)
+		}
+	}
+	slices.Sort(loaded)
+	if !slices.Equal(loaded, []string{"ANTHROPIC_API_KEY", "OLLAMA_HOST", "OPENAI_API_KEY"}) {
+		t.Errorf("loaded = %v", loaded)
+	}
+
+	write(filepath.Join(project, ".env"), "not a variable\n")
+	if _, err := LoadDotEnv(project); err == nil {
+		t.Error("expected a malformed line to be reported")
+	}
+}
+
+func TestLoadDotEnv_MalformedProjectFileStillLoadsHome(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	project := t.TempDir()
+	t.Setenv("ANTHROPIC_API_KEY", "")
+	os.Unsetenv("ANTHROPIC_API_KEY")
+
+	if err := os.WriteFile(filepath.Join(project, ".env"), []byte("not a variable\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(home, ".gennai"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(home, ".gennai", ".env"), []byte("ANTHROPIC_API_KEY=sk-ant-home\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadDotEnv(project)
+	if err == nil || !strings.Contains(err.Error(), filepath.Join(project, ".env")) {
+		t.Errorf("expected the project file's parse error, got %v", err)
+	}
+	if got := os.Getenv("ANTHROPIC_API_KEY"); got != "sk-ant-home" || !slices.Equal(loaded, []string{"ANTHROPIC_API_KEY"}) {
+		t.Errorf("expected the home key loaded, got %q (loaded %v)", got, loaded)
+	}
+}
+
+func TestMissingAPIKey(t *testing.T) {
+	t.Setenv("ANTHROPIC_API_KEY", "")
+	if got := MissingAPIKey("anthropic"); got != "ANTHROPIC_API_KEY" {
+		t.Errorf("MissingAPIKey(anthropic) = %q, want ANTHROPIC_API_KEY", got)
+	}
+	t.Setenv("ANTHROPIC_API_KEY", "sk-ant")
+	if got := MissingAPIKey("anthropic"); got != "" {
+		t.Errorf("expected no missing key once set, got %q", got)
+	}
+	if got := MissingAPIKey("ollama"); got != "" {
+		t.Errorf("expected ollama to need no key, got %q", got)
+	}
+}
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// preambleFileName is the optional preamble file in a .gennai directory
+const preambleFileName = "preamble.md"
+
+// ResolveSystemPreamble returns the house-style preamble prepended to every scenario.
+// The first non-empty source wins:
+//  1. <workingDir>/.gennai/preamble.md (project-local override)
+//  2. system_preamble_file (relative paths resolve against workingDir, "~/" against $HOME)
+//  3. system_preamble
+//  4. $HOME/.gennai/preamble.md (global default)
+func (a AgentSettings) ResolveSystemPreamble(workingDir string) (string, error) {
+	if text, err := readPreambleFile(filepath.Join(workingDir, ".gennai", preambleFileName)); err != nil || text != "" {
+		return text, err
+	}
+
+	if a.SystemPreambleFile != "" {
+		path := a.SystemPreambleFile
+		if rest, ok := strings.CutPrefix(path, "~/"); ok {
+			homeDir, err := os.UserHomeDir()
+			if err != nil {
+				return "", fmt.Errorf("failed to resolve system_preamble_file: %w", err)
+			}
+			path = filepath.Join(homeDir, rest)
+		} else if !filepath.IsAbs(path) {
+			path = filepath.Join(workingDir, path)
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read system_preamble_file: %w", err)
+		}
+		if text := strings.TrimSpace(string(content)); text != "" {
+			return text, nil
+		}
+	}
+
+	if text := strings.TrimSpace(a.SystemPreamble); text != "" {
+		return text, nil
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", nil
+	}
+	return readPreambleFile(filepath.Join(homeDir, ".gennai", preambleFileName))
+}
+
+// readPreambleFile returns the trimmed file content, or "" when the file does not exist
+func readPreambleFile(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return strings.TrimSpace(string(content)), nil
+}
@@ -0,0 +1,60 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveSystemPreamble(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	project := t.TempDir()
+
+	write := func(path, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	resolve := func(a AgentSettings) string {
+		t.Helper()
+		text, err := a.ResolveSystemPreamble(project)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return text
+	}
+
+	if got := resolve(AgentSettings{}); got != "" {
+		t.Errorf("expected no preamble by default, got %q", got)
+	}
+
+	write(filepath.Join(home, ".gennai", "preamble.md"), "global style\n")
+	if got := resolve(AgentSettings{}); got != "global style" {
+		t.Errorf("expected global preamble, got %q", got)
+	}
+
+	if got := resolve(AgentSettings{SystemPreamble: "inline style"}); got != "inline style" {
+		t.Errorf("expected inline preamble to override global, got %q", got)
+	}
+
+	write(filepath.Join(project, "docs", "style.md"), "file style\n")
+	settings := AgentSettings{SystemPreamble: "inline style", SystemPreambleFile: "docs/style.md"}
+	if got := resolve(settings); got != "file style" {
+		t.Errorf("expected preamble file to override inline text, got %q", got)
+	}
+
+	write(filepath.Join(project, ".gennai", "preamble.md"), "project style\n")
+	if got := resolve(settings); got != "project style" {
+		t.Errorf("expected project-local preamble to win, got %q", got)
+	}
+
+	missing := AgentSettings{SystemPreambleFile: "/nonexistent/preamble.md"}
+	if _, err := missing.ResolveSystemPreamble(t.TempDir()); err == nil {
+		t.Error("expected error for missing system_preamble_file")
+	}
+}
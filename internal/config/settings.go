@@ -3,13 +3,20 @@ package config
 import (
 	"encoding/json"
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/fpt/go-gennai-cli/internal/infra"
 	"github.com/fpt/go-gennai-cli/internal/repository"
 	"github.com/fpt/go-gennai-cli/pkg/agent/domain"
 	pkgLogger "github.com/fpt/go-gennai-cli/pkg/logger"
+	"github.com/fpt/go-gennai-cli/pkg/message"
+	"github.com/fpt/go-gennai-cli/pkg/secret"
 )
 
 // Default maximum iterations for agents
@@ -21,6 +28,22 @@ type Settings struct {
 	MCP   MCPSettings   `json:"mcp"`
 	Agent AgentSettings `json:"agent"`
 	Bash  BashSettings  `json:"bash,omitempty"`
+	Web   WebSettings   `json:"web,omitempty"`
+
+	// Secrets controls redaction of credentials from tool output and logs
+	Secrets SecretSettings `json:"secrets,omitempty"`
+
+	// ApprovalPolicy maps tool categories ("write", "delete", "bash", "mcp")
+	// to "always" (run freely), "ask" (wait for approval) or "never" (refuse)
+	ApprovalPolicy map[string]string `json:"approval_policy,omitempty"`
+
+	// Offline disables every network tool (WebFetch, WebSearch, HTTP/SSE MCP
+	// servers) regardless of what scenarios request; only the LLM is contacted
+	Offline bool `json:"offline,omitempty"`
+
+	// CABundle is a PEM file of extra trusted CAs for API and web requests,
+	// for networks that intercept TLS; proxies come from HTTP(S)_PROXY/NO_PROXY
+	CABundle string `json:"ca_bundle,omitempty"`
 
 	// Repository for persistence (nil for in-memory only)
 	settingsRepository repository.SettingsRepository `json:"-"`
@@ -28,27 +51,120 @@ type Settings struct {
 
 // LLMSettings contains LLM client configuration
 type LLMSettings struct {
-	Backend   string `json:"backend"`              // "ollama", "anthropic", "openai", or "gemini"
-	Model     string `json:"model"`                // model name
-	BaseURL   string `json:"base_url,omitempty"`   // for ollama or openai (Azure)
-	Thinking  bool   `json:"thinking,omitempty"`   // enable thinking mode
-	MaxTokens int    `json:"max_tokens,omitempty"` // maximum tokens for model responses (0 = use model default)
+	Backend          string `json:"backend"`                       // "ollama", "anthropic", "openai", "azure", or "gemini"
+	Model            string `json:"model"`                         // model name (deployment name for azure)
+	BaseURL          string `json:"base_url,omitempty"`            // for ollama, openai, or azure (resource endpoint)
+	APIVersion       string `json:"api_version,omitempty"`         // Azure OpenAI api-version (azure; empty = client default)
+	Thinking         bool   `json:"thinking,omitempty"`            // enable thinking mode
+	ThinkingBudget   int    `json:"thinking_budget,omitempty"`     // tokens for extended thinking (anthropic, gemini; 0 = use client default)
+	MaxTokens        int    `json:"max_tokens,omitempty"`          // maximum tokens for model responses (0 = use model default)
+	RetryMaxAttempts int    `json:"retry_max_attempts,omitempty"`  // attempts for transient API errors (0 = use client default)
+	RetryBaseDelayMs int    `json:"retry_base_delay_ms,omitempty"` // initial backoff delay in milliseconds (0 = use client default)
+	PromptCaching    bool   `json:"prompt_caching,omitempty"`      // use provider prompt caching for the stable system prompt (anthropic, openai)
+	KeepAlive        string `json:"keep_alive,omitempty"`          // how long ollama keeps the model loaded after a request ("30m", "-1" = forever; empty = server default)
+	NumCtx           int    `json:"num_ctx,omitempty"`             // context window ollama allocates for the model (0 = the model's known context window)
+}
+
+// KeepAliveDuration parses keep_alive: a duration such as "30m" or a number
+// of seconds. Negative values keep the model loaded indefinitely.
+func (l LLMSettings) KeepAliveDuration() (time.Duration, error) {
+	if seconds, err := strconv.Atoi(l.KeepAlive); err == nil {
+		return time.Duration(seconds) * time.Second, nil
+	}
+	d, err := time.ParseDuration(l.KeepAlive)
+	if err != nil {
+		return 0, fmt.Errorf("invalid keep_alive %q (use a duration such as \"30m\", seconds, or \"-1\" to keep the model loaded)", l.KeepAlive)
+	}
+	return d, nil
 }
 
 // MCPSettings contains MCP server configuration
 type MCPSettings struct {
-	Servers []domain.MCPServerConfig `json:"servers,omitempty"`
+	Servers       []domain.MCPServerConfig `json:"servers,omitempty"`
+	PoolSize      int                      `json:"pool_size,omitempty"`      // connections kept per server (0 = 1)
+	MaxConcurrent int                      `json:"max_concurrent,omitempty"` // calls in flight per server (0 = unlimited)
 }
 
 // AgentSettings contains agent behavior configuration
 type AgentSettings struct {
-	MaxIterations int    `json:"max_iterations"`
-	LogLevel      string `json:"log_level"`
+	MaxIterations        int            `json:"max_iterations"`
+	LogLevel             string         `json:"log_level"`
+	LogFile              string         `json:"log_file,omitempty"`                // also write structured logs to this rotating file
+	LogFileLevel         string         `json:"log_file_level,omitempty"`          // level for log_file, independent of log_level (default "debug")
+	LogFileMaxSizeMB     int            `json:"log_file_max_size_mb,omitempty"`    // rotate log_file at this size (0 = default 10)
+	LogFileMaxBackups    *int           `json:"log_file_max_backups,omitempty"`    // rotated log files to keep (default 3; 0 keeps none)
+	ToolOutputHeadLines  int            `json:"tool_output_head_lines,omitempty"`  // lines kept from the start of large tool output (0 = default)
+	ToolOutputTailLines  int            `json:"tool_output_tail_lines,omitempty"`  // lines kept from the end of large tool output (0 = default)
+	ToolOutputMaxTokens  int            `json:"tool_output_max_tokens,omitempty"`  // token budget before tool output is truncated (0 = default)
+	MaxToolResultChars   int            `json:"max_tool_result_chars,omitempty"`   // characters of each tool result kept in the conversation (0 = unlimited)
+	ReadMaxBytes         int            `json:"read_max_bytes,omitempty"`          // Read tool output size before paging is required (0 = default)
+	HeadTailLines        int            `json:"head_tail_lines,omitempty"`         // lines file_head and file_tail return when no count is given (0 = 100)
+	HeadTailMaxLines     int            `json:"head_tail_max_lines,omitempty"`     // most lines file_head and file_tail return (0 = 2000)
+	ToolTimeouts         map[string]int `json:"tool_timeouts,omitempty"`           // seconds per tool name; "default" applies to unlisted tools. bash and run_tests always get their own timeout; write tools are never cut off
+	ToolConcurrency      int            `json:"tool_concurrency,omitempty"`        // concurrent read-only calls per tool batch (0 or 1 = sequential)
+	SystemPreamble       string         `json:"system_preamble,omitempty"`         // house-style instructions prepended to every scenario
+	SystemPreambleFile   string         `json:"system_preamble_file,omitempty"`    // file containing the preamble (overrides system_preamble)
+	MaxTotalTokens       int            `json:"max_total_tokens,omitempty"`        // stop a run once it has used this many tokens (0 = unlimited)
+	InputCostPer1K       float64        `json:"input_cost_per_1k,omitempty"`       // price per 1,000 input tokens for cost estimates
+	OutputCostPer1K      float64        `json:"output_cost_per_1k,omitempty"`      // price per 1,000 output tokens for cost estimates
+	DisabledValidators   []string       `json:"disabled_validators,omitempty"`     // post-edit validators to skip ("go", "python", "javascript", "rust", "json", "yaml", "toml")
+	MaxRepeatedToolCalls int            `json:"max_repeated_tool_calls,omitempty"` // identical tool calls in a row that run before repeats are answered from the last result (0 = default 3)
+	IterationExtension   int            `json:"iteration_extension,omitempty"`     // iterations added when the user continues past the limit in interactive mode (0 = default 10)
+	SelfReview           bool           `json:"self_review,omitempty"`             // after a task that changed files, have the model review its own diff
+	SelfReviewFixRounds  int            `json:"self_review_fix_rounds,omitempty"`  // times the model may fix review findings and be reviewed again (0 = review only)
+	CacheToolResults     bool           `json:"cache_tool_results,omitempty"`      // reuse results of repeated read-only tool calls within a request until a write touches their files
+	ValidationFixRounds  int            `json:"validation_fix_rounds,omitempty"`   // times a task is sent back to fix files that still fail post-edit validation (0 = off)
+}
+
+// ToolOutputTruncation returns the truncation config for displaying tool output
+func (a AgentSettings) ToolOutputTruncation() message.TruncationConfig {
+	return message.TruncationConfig{
+		HeadLines: a.ToolOutputHeadLines,
+		TailLines: a.ToolOutputTailLines,
+		MaxTokens: a.ToolOutputMaxTokens,
+	}
 }
 
 // BashSettings contains bash tool configuration
 type BashSettings struct {
 	WhitelistedCommands []string `json:"whitelisted_commands,omitempty"` // Commands that don't require approval
+	AllowedCommands     []string `json:"allowed_commands,omitempty"`     // If set, only matching commands may run
+	DeniedCommands      []string `json:"denied_commands,omitempty"`      // Matching commands are always refused ("re:" prefix for regex)
+}
+
+// WebSettings contains web tool configuration
+type WebSettings struct {
+	SearchProvider       string  `json:"search_provider,omitempty"`         // "duckduckgo", "searxng", or empty to disable WebSearch
+	SearXNGURL           string  `json:"searxng_url,omitempty"`             // base URL of a SearXNG instance (for searxng provider)
+	DisableFetchCache    bool    `json:"disable_fetch_cache,omitempty"`     // refetch pages on every WebFetch call
+	FetchCacheTTLSeconds int     `json:"fetch_cache_ttl_seconds,omitempty"` // how long fetched pages stay cached (0 = default)
+	FetchCacheMaxEntries int     `json:"fetch_cache_max_entries,omitempty"` // maximum cached pages (0 = default)
+	RespectRobots        *bool   `json:"respect_robots,omitempty"`          // refuse WebFetch URLs disallowed by robots.txt (default true)
+	PerHostRPS           float64 `json:"per_host_rps,omitempty"`            // WebFetch requests per second to one host (0 = unlimited)
+	FetchMaxBytes        int64   `json:"fetch_max_bytes,omitempty"`         // WebFetch reads at most this much of a response (0 = 5 MB)
+	FetchMaxChars        int     `json:"fetch_max_chars,omitempty"`         // WebFetch output is truncated beyond this length (0 = 100000)
+	FetchMaxLinks        int     `json:"fetch_max_links,omitempty"`         // links listed at the end of markdown output (0 = 10)
+}
+
+// SecretSettings controls the redaction of credentials from tool results
+// (and so from the saved session) and from logs
+type SecretSettings struct {
+	Disabled  bool     `json:"disabled,omitempty"`   // keep tool output and logs unredacted
+	Patterns  []string `json:"patterns,omitempty"`   // regular expressions redacted in addition to the built-in credential formats
+	IgnoreEnv bool     `json:"ignore_env,omitempty"` // don't redact values of secret-looking environment variables
+}
+
+// Scrubber builds the secret scrubber for these settings, or nil when redaction is disabled
+func (s SecretSettings) Scrubber() (*secret.Scrubber, error) {
+	if s.Disabled {
+		return nil, nil
+	}
+	return secret.NewScrubber(secret.Options{Patterns: s.Patterns, IgnoreEnv: s.IgnoreEnv})
+}
+
+// RespectsRobots reports whether WebFetch honors robots.txt, which it does unless disabled
+func (w WebSettings) RespectsRobots() bool {
+	return w.RespectRobots == nil || *w.RespectRobots
 }
 
 // NewSettings creates new settings with in-memory repository
@@ -238,6 +354,15 @@ func GetDefaultLLMSettingsForBackend(backend string) LLMSettings {
 			Thinking:  true,
 			MaxTokens: 0,
 		}
+	case "azure":
+		// Azure serves models by deployment name, which has no sensible default
+		return LLMSettings{
+			Backend:   "azure",
+			Model:     os.Getenv("AZURE_OPENAI_DEPLOYMENT"),
+			BaseURL:   "",
+			Thinking:  true,
+			MaxTokens: 0,
+		}
 	case "gemini":
 		return LLMSettings{
 			Backend:   "gemini",
@@ -281,33 +406,40 @@ func applyDefaults(settings *Settings) {
 // ValidateSettings validates the settings configuration
 func ValidateSettings(settings *Settings) error {
 	// Validate LLM settings
-	if settings.LLM.Backend != "ollama" && settings.LLM.Backend != "anthropic" && settings.LLM.Backend != "openai" && settings.LLM.Backend != "gemini" {
-		return fmt.Errorf("unsupported LLM backend: %s (must be 'ollama', 'anthropic', 'openai', or 'gemini')", settings.LLM.Backend)
+	if settings.LLM.Backend != "ollama" && settings.LLM.Backend != "anthropic" && settings.LLM.Backend != "openai" && settings.LLM.Backend != "azure" && settings.LLM.Backend != "gemini" {
+		return fmt.Errorf("unsupported LLM backend: %s (must be 'ollama', 'anthropic', 'openai', 'azure', or 'gemini')", settings.LLM.Backend)
+	}
+
+	if settings.LLM.Backend == "azure" {
+		if settings.LLM.Model == "" {
+			return fmt.Errorf("Azure OpenAI deployment name is required (set AZURE_OPENAI_DEPLOYMENT or pass -m DEPLOYMENT)")
+		}
+		if os.Getenv("AZURE_OPENAI_ENDPOINT") == "" && settings.LLM.BaseURL == "" {
+			return fmt.Errorf("Azure OpenAI endpoint is required (set AZURE_OPENAI_ENDPOINT or base_url, e.g. https://<resource>.openai.azure.com)")
+		}
 	}
 
 	if settings.LLM.Model == "" {
 		return fmt.Errorf("LLM model is required")
 	}
 
-	if settings.LLM.Backend == "anthropic" {
-		// Check environment variable for API key
-		if os.Getenv("ANTHROPIC_API_KEY") == "" {
-			return fmt.Errorf("Anthropic API key is required (set ANTHROPIC_API_KEY environment variable)")
-		}
+	// Check the API key before any client is created
+	if name := MissingAPIKey(settings.LLM.Backend); name != "" {
+		return fmt.Errorf("%s is not set: the %s backend needs an API key (export it, or add it to .env in the project or ~/.gennai/.env)", name, settings.LLM.Backend)
 	}
 
-	if settings.LLM.Backend == "openai" {
-		// Check environment variable for API key
-		if os.Getenv("OPENAI_API_KEY") == "" {
-			return fmt.Errorf("OpenAI API key is required (set OPENAI_API_KEY environment variable)")
+	if settings.LLM.KeepAlive != "" {
+		if _, err := settings.LLM.KeepAliveDuration(); err != nil {
+			return err
 		}
 	}
 
-	if settings.LLM.Backend == "gemini" {
-		// Check environment variable for API key
-		if os.Getenv("GEMINI_API_KEY") == "" {
-			return fmt.Errorf("Gemini API key is required (set GEMINI_API_KEY environment variable)")
-		}
+	if settings.LLM.ThinkingBudget < 0 {
+		return fmt.Errorf("thinking_budget must not be negative")
+	}
+
+	if settings.LLM.NumCtx < 0 {
+		return fmt.Errorf("num_ctx must not be negative")
 	}
 
 	// Validate Agent settings
@@ -315,6 +447,97 @@ func ValidateSettings(settings *Settings) error {
 		return fmt.Errorf("max_iterations must be positive")
 	}
 
+	// Validate Web settings
+	switch settings.Web.SearchProvider {
+	case "", "duckduckgo":
+	case "searxng":
+		if settings.Web.SearXNGURL == "" {
+			return fmt.Errorf("searxng_url is required when search_provider is 'searxng'")
+		}
+	default:
+		return fmt.Errorf("unsupported search provider: %s (must be 'duckduckgo' or 'searxng')", settings.Web.SearchProvider)
+	}
+
+	if _, err := domain.ParseApprovalPolicy(settings.ApprovalPolicy); err != nil {
+		return err
+	}
+
+	for _, pattern := range settings.Secrets.Patterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid secrets pattern %q: %v", pattern, err)
+		}
+	}
+
+	if settings.Agent.LogFileLevel != "" {
+		switch pkgLogger.LogLevel(settings.Agent.LogFileLevel) {
+		case pkgLogger.LogLevelDebug, pkgLogger.LogLevelInfo, pkgLogger.LogLevelWarn, pkgLogger.LogLevelError:
+		default:
+			return fmt.Errorf("unsupported log_file_level: %s (must be debug, info, warn or error)", settings.Agent.LogFileLevel)
+		}
+	}
+	if settings.Agent.LogFileMaxSizeMB < 0 || (settings.Agent.LogFileMaxBackups != nil && *settings.Agent.LogFileMaxBackups < 0) {
+		return fmt.Errorf("log_file_max_size_mb and log_file_max_backups must not be negative")
+	}
+
+	if settings.Agent.ToolConcurrency < 0 {
+		return fmt.Errorf("tool_concurrency must not be negative")
+	}
+
+	if settings.Agent.MaxToolResultChars < 0 {
+		return fmt.Errorf("max_tool_result_chars must not be negative")
+	}
+
+	if settings.Agent.MaxRepeatedToolCalls < 0 {
+		return fmt.Errorf("max_repeated_tool_calls must not be negative")
+	}
+
+	if settings.Agent.SelfReviewFixRounds < 0 {
+		return fmt.Errorf("self_review_fix_rounds must not be negative")
+	}
+
+	if settings.Agent.ValidationFixRounds < 0 {
+		return fmt.Errorf("validation_fix_rounds must not be negative")
+	}
+
+	if settings.Agent.HeadTailLines < 0 || settings.Agent.HeadTailMaxLines < 0 {
+		return fmt.Errorf("head_tail_lines and head_tail_max_lines must not be negative")
+	}
+
+	if settings.Agent.MaxTotalTokens < 0 || settings.Agent.InputCostPer1K < 0 || settings.Agent.OutputCostPer1K < 0 {
+		return fmt.Errorf("max_total_tokens, input_cost_per_1k and output_cost_per_1k must not be negative")
+	}
+
+	for name, seconds := range settings.Agent.ToolTimeouts {
+		if seconds <= 0 {
+			return fmt.Errorf("tool_timeouts[%q] must be positive", name)
+		}
+	}
+
+	if settings.Web.PerHostRPS < 0 {
+		return fmt.Errorf("per_host_rps must not be negative")
+	}
+
+	if settings.Web.FetchCacheTTLSeconds < 0 || settings.Web.FetchCacheMaxEntries < 0 {
+		return fmt.Errorf("fetch_cache_ttl_seconds and fetch_cache_max_entries must not be negative")
+	}
+
+	if settings.Web.FetchMaxBytes < 0 || settings.Web.FetchMaxChars < 0 || settings.Web.FetchMaxLinks < 0 {
+		return fmt.Errorf("fetch_max_bytes, fetch_max_chars and fetch_max_links must not be negative")
+	}
+
+	// Validate Bash command rules
+	for _, pattern := range append(append([]string{}, settings.Bash.AllowedCommands...), settings.Bash.DeniedCommands...) {
+		if expr, ok := strings.CutPrefix(strings.TrimSpace(pattern), "re:"); ok {
+			if _, err := regexp.Compile(expr); err != nil {
+				return fmt.Errorf("invalid bash command rule %q: %w", pattern, err)
+			}
+		}
+	}
+
+	if settings.MCP.PoolSize < 0 || settings.MCP.MaxConcurrent < 0 {
+		return fmt.Errorf("mcp pool_size and max_concurrent must not be negative")
+	}
+
 	// Validate MCP server configurations
 	for _, serverConfig := range settings.MCP.Servers {
 		if err := ValidateMCPServerConfig(serverConfig); err != nil {
@@ -355,15 +578,18 @@ func ValidateMCPServerConfig(config domain.MCPServerConfig) error {
 		return fmt.Errorf("server name is required")
 	}
 
-	switch config.Type {
+	switch config.TransportType() {
 	case domain.MCPServerTypeStdio:
 		if config.Command == "" {
 			return fmt.Errorf("command is required for stdio servers")
 		}
-	case domain.MCPServerTypeSSE:
+	case domain.MCPServerTypeHTTP, domain.MCPServerTypeSSE:
 		if config.URL == "" {
 			return fmt.Errorf("URL is required for HTTP/SSE servers")
 		}
+		if u, err := url.Parse(config.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("URL must be an absolute http or https URL: %s", config.URL)
+		}
 	default:
 		return fmt.Errorf("unsupported server type: %s", config.Type)
 	}
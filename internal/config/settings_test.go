@@ -3,7 +3,11 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/fpt/go-gennai-cli/pkg/agent/domain"
 )
 
 func TestCreateDefaultSettingsFile(t *testing.T) {
@@ -77,3 +81,84 @@ func TestLoadSettingsCreatesFileWhenNoneExists(t *testing.T) {
 		t.Fatal("Settings file was not created in home directory")
 	}
 }
+
+func TestValidateMCPServerConfigTransports(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  domain.MCPServerConfig
+		wantErr bool
+	}{
+		{"stdio", domain.MCPServerConfig{Name: "a", Type: domain.MCPServerTypeStdio, Command: "srv"}, false},
+		{"stdio without command", domain.MCPServerConfig{Name: "a", Type: domain.MCPServerTypeStdio}, true},
+		{"http", domain.MCPServerConfig{Name: "a", Type: domain.MCPServerTypeHTTP, URL: "https://example.com/mcp"}, false},
+		{"http without url", domain.MCPServerConfig{Name: "a", Type: domain.MCPServerTypeHTTP}, true},
+		{"http with relative url", domain.MCPServerConfig{Name: "a", Type: domain.MCPServerTypeHTTP, URL: "example.com/mcp"}, true},
+		{"http with other scheme", domain.MCPServerConfig{Name: "a", Type: domain.MCPServerTypeHTTP, URL: "ftp://example.com/mcp"}, true},
+		{"inferred http", domain.MCPServerConfig{Name: "a", URL: "http://localhost:8080/mcp"}, false},
+		{"inferred stdio", domain.MCPServerConfig{Name: "a", Command: "srv"}, false},
+		{"unknown type", domain.MCPServerConfig{Name: "a", Type: "websocket", URL: "http://localhost"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateMCPServerConfig(tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateMCPServerConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateSettingsAzure(t *testing.T) {
+	t.Setenv("AZURE_OPENAI_DEPLOYMENT", "my-gpt4o")
+	t.Setenv("AZURE_OPENAI_ENDPOINT", "")
+	t.Setenv("AZURE_OPENAI_API_KEY", "")
+
+	settings := GetDefaultSettings()
+	settings.LLM = GetDefaultLLMSettingsForBackend("azure")
+	if settings.LLM.Backend != "azure" || settings.LLM.Model != "my-gpt4o" {
+		t.Fatalf("unexpected azure defaults: %+v", settings.LLM)
+	}
+
+	steps := []struct {
+		apply   func()
+		wantErr string
+	}{
+		{func() {}, "AZURE_OPENAI_ENDPOINT"},
+		{func() { settings.LLM.BaseURL = "https://r.openai.azure.com" }, "AZURE_OPENAI_API_KEY"},
+		{func() { t.Setenv("AZURE_OPENAI_API_KEY", "k") }, ""},
+		{func() { settings.LLM.Model = "" }, "AZURE_OPENAI_DEPLOYMENT"},
+	}
+	for i, step := range steps {
+		step.apply()
+		err := ValidateSettings(settings)
+		if step.wantErr == "" {
+			if err != nil {
+				t.Errorf("step %d: unexpected error: %v", i, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), step.wantErr) {
+			t.Errorf("step %d: expected error containing %q, got %v", i, step.wantErr, err)
+		}
+	}
+}
+
+func TestKeepAliveDuration(t *testing.T) {
+	for value, want := range map[string]time.Duration{
+		"30m": 30 * time.Minute,
+		"600": 10 * time.Minute,
+		"-1":  -time.Second,
+		"0":   0,
+	} {
+		got, err := LLMSettings{KeepAlive: value}.KeepAliveDuration()
+		if err != nil || got != want {
+			t.Errorf("KeepAliveDuration(%q) = %v, %v; want %v", value, got, err, want)
+		}
+	}
+
+	settings := GetDefaultSettings()
+	settings.LLM.KeepAlive = "forever"
+	if err := ValidateSettings(settings); err == nil || !strings.Contains(err.Error(), "invalid keep_alive") {
+		t.Errorf("expected an invalid keep_alive error, got %v", err)
+	}
+}
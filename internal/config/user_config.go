@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
 	"time"
 
@@ -97,14 +99,78 @@ func (c *UserConfig) GetProjectTodoFile(projectPath string) (string, error) {
 	return filepath.Join(projectDir, "todos.json"), nil
 }
 
+// DefaultSessionName is the session used when no name is given; it maps to session.json
+const DefaultSessionName = "session"
+
+// sessionNamePattern restricts session names to safe file name characters
+var sessionNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// ValidateSessionName checks that a session name is usable as a file name
+func ValidateSessionName(name string) error {
+	if !sessionNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid session name %q: use letters, digits, '-' or '_'", name)
+	}
+	if name == "todos" {
+		return fmt.Errorf("session name %q is reserved", name)
+	}
+	return nil
+}
+
 // GetProjectSessionFile returns the session state file path for a specific project
 func (c *UserConfig) GetProjectSessionFile(projectPath string) (string, error) {
+	return c.GetProjectNamedSessionFile(projectPath, DefaultSessionName)
+}
+
+// GetProjectNamedSessionFile returns the state file path for a named session of a project.
+// An empty name selects the default session.
+func (c *UserConfig) GetProjectNamedSessionFile(projectPath, name string) (string, error) {
+	if name == "" {
+		name = DefaultSessionName
+	}
+	if err := ValidateSessionName(name); err != nil {
+		return "", err
+	}
+
 	projectDir, err := c.GetProjectDataDir(projectPath)
 	if err != nil {
 		return "", err
 	}
 
-	return filepath.Join(projectDir, "session.json"), nil
+	return filepath.Join(projectDir, name+".json"), nil
+}
+
+// GetProjectNamedScratchpadFile returns the scratchpad notes file for a named
+// session of a project. An empty name selects the default session.
+func (c *UserConfig) GetProjectNamedScratchpadFile(projectPath, name string) (string, error) {
+	sessionFile, err := c.GetProjectNamedSessionFile(projectPath, name)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(sessionFile, ".json") + ".scratchpad.md", nil
+}
+
+// ListProjectSessions returns the names of saved sessions for a project, sorted
+func (c *UserConfig) ListProjectSessions(projectPath string) ([]string, error) {
+	projectDir, err := c.GetProjectDataDir(projectPath)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(projectDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read project directory: %w", err)
+	}
+
+	var sessions []string
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".json")
+		if !ok || entry.IsDir() || ValidateSessionName(name) != nil {
+			continue
+		}
+		sessions = append(sessions, name)
+	}
+	sort.Strings(sessions)
+	return sessions, nil
 }
 
 // GetProjectHistoryFile returns the readline history file path for a specific project
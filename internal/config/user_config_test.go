@@ -0,0 +1,69 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestGetProjectNamedSessionFile(t *testing.T) {
+	base := t.TempDir()
+	uc := &UserConfig{BaseDir: base, ProjectsDir: filepath.Join(base, "projects")}
+	project := t.TempDir()
+
+	defaultPath, err := uc.GetProjectSessionFile(project)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if filepath.Base(defaultPath) != "session.json" {
+		t.Errorf("expected default session file session.json, got %s", defaultPath)
+	}
+
+	named, err := uc.GetProjectNamedSessionFile(project, "refactor")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if filepath.Dir(named) != filepath.Dir(defaultPath) || filepath.Base(named) != "refactor.json" {
+		t.Errorf("expected refactor.json next to default session, got %s", named)
+	}
+
+	scratchpad, err := uc.GetProjectNamedScratchpadFile(project, "refactor")
+	if err != nil || scratchpad != filepath.Join(filepath.Dir(named), "refactor.scratchpad.md") {
+		t.Errorf("expected refactor.scratchpad.md next to the session file, got %s (%v)", scratchpad, err)
+	}
+
+	for _, bad := range []string{"../escape", "a/b", "todos", "with space"} {
+		if _, err := uc.GetProjectNamedSessionFile(project, bad); err == nil {
+			t.Errorf("expected error for session name %q", bad)
+		}
+	}
+}
+
+func TestListProjectSessions(t *testing.T) {
+	base := t.TempDir()
+	uc := &UserConfig{BaseDir: base, ProjectsDir: filepath.Join(base, "projects")}
+	project := t.TempDir()
+
+	for _, name := range []string{"session", "feature-b", "alpha"} {
+		path, err := uc.GetProjectNamedSessionFile(project, name)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := os.WriteFile(path, []byte("{}"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Non-session files in the project directory are ignored
+	todoPath, _ := uc.GetProjectTodoFile(project)
+	_ = os.WriteFile(todoPath, []byte("{}"), 0644)
+
+	sessions, err := uc.ListProjectSessions(project)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []string{"alpha", "feature-b", "session"}
+	if !reflect.DeepEqual(sessions, expected) {
+		t.Errorf("expected %v, got %v", expected, sessions)
+	}
+}
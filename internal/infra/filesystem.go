@@ -2,6 +2,7 @@ package infra
 
 import (
 	"context"
+	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
@@ -71,11 +72,31 @@ func (r *OSFilesystemRepository) ReadFile(ctx context.Context, path string) ([]b
 	return os.ReadFile(path)
 }
 
+// Open opens a file for reading
+func (r *OSFilesystemRepository) Open(ctx context.Context, path string) (io.ReadSeekCloser, error) {
+	return os.Open(path)
+}
+
 // WriteFile writes data to a file
 func (r *OSFilesystemRepository) WriteFile(ctx context.Context, path string, data []byte, perm fs.FileMode) error {
 	return os.WriteFile(path, data, perm)
 }
 
+// Chmod changes the permission bits of a file
+func (r *OSFilesystemRepository) Chmod(ctx context.Context, path string, perm fs.FileMode) error {
+	return os.Chmod(path, perm)
+}
+
+// Rename moves a file, replacing newPath if it exists
+func (r *OSFilesystemRepository) Rename(ctx context.Context, oldPath, newPath string) error {
+	return os.Rename(oldPath, newPath)
+}
+
+// Remove deletes a file or an empty directory
+func (r *OSFilesystemRepository) Remove(ctx context.Context, path string) error {
+	return os.Remove(path)
+}
+
 // Stat returns file information
 func (r *OSFilesystemRepository) Stat(ctx context.Context, path string) (fs.FileInfo, error) {
 	return os.Stat(path)
@@ -0,0 +1,84 @@
+package infra
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	gitContextTimeout     = 5 * time.Second
+	gitContextStatusLines = 50 // changed files listed by {{gitStatus}}
+	gitContextCommits     = 5  // commits listed by {{recentCommits}}
+)
+
+// gitContext provides the git template variables of a scenario prompt. Each
+// value is looked up on first use and cached, so a render only shells out for
+// the variables the prompt uses, and only once. Outside a git repository every
+// value is empty.
+type gitContext struct {
+	branch        func() string
+	status        func() string
+	recentCommits func() string
+}
+
+func newGitContext(dir string) *gitContext {
+	return &gitContext{
+		branch: sync.OnceValue(func() string {
+			branch := runGit(dir, "rev-parse", "--abbrev-ref", "HEAD")
+			if branch == "HEAD" {
+				return fmt.Sprintf("(detached at %s)", runGit(dir, "rev-parse", "--short", "HEAD"))
+			}
+			return branch
+		}),
+		status: sync.OnceValue(func() string {
+			if runGit(dir, "rev-parse", "--is-inside-work-tree") != "true" {
+				return ""
+			}
+			status := runGit(dir, "status", "--short")
+			if status == "" {
+				return "(clean)"
+			}
+			lines := strings.Split(status, "\n")
+			if len(lines) > gitContextStatusLines {
+				status = strings.Join(lines[:gitContextStatusLines], "\n") +
+					fmt.Sprintf("\n... (%d more changed files)", len(lines)-gitContextStatusLines)
+			}
+			return status
+		}),
+		recentCommits: sync.OnceValue(func() string {
+			return runGit(dir, "log", "--oneline", "-n", fmt.Sprint(gitContextCommits))
+		}),
+	}
+}
+
+// render replaces the git template variables in prompt
+func (g *gitContext) render(prompt string) string {
+	for placeholder, value := range map[string]func() string{
+		"{{gitBranch}}":     g.branch,
+		"{{gitStatus}}":     g.status,
+		"{{recentCommits}}": g.recentCommits,
+	} {
+		if strings.Contains(prompt, placeholder) {
+			prompt = strings.ReplaceAll(prompt, placeholder, value())
+		}
+	}
+	return prompt
+}
+
+// runGit returns the trimmed output of a git command in dir, or "" when it
+// fails (git missing, not a repository, no commits yet)
+func runGit(dir string, args ...string) string {
+	ctx, cancel := context.WithTimeout(context.Background(), gitContextTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimRight(string(out), "\n")
+}
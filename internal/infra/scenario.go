@@ -14,18 +14,37 @@ import (
 
 // ScenarioConfig represents a scenario configuration from YAML
 type ScenarioConfig struct {
-	name        string `yaml:"-"` // Set during loading
-	tools       string `yaml:"tools"`
-	description string `yaml:"description"`
-	prompt      string `yaml:"prompt"`
+	name          string `yaml:"-"` // Set during loading
+	tools         string `yaml:"tools"`
+	description   string `yaml:"description"`
+	prompt        string `yaml:"prompt"`
+	maxIterations int    `yaml:"max_iterations"`
+	noThinking    bool   `yaml:"-"` // thinking: false in YAML
+	noReadCheck   bool   `yaml:"-"` // require_read_before_write: false in YAML
+}
+
+// scenarioFile mirrors the YAML layout of a scenario entry for decoding
+type scenarioFile struct {
+	Tools                  string `yaml:"tools"`
+	Description            string `yaml:"description"`
+	Prompt                 string `yaml:"prompt"`
+	MaxIterations          int    `yaml:"max_iterations"`
+	Thinking               *bool  `yaml:"thinking"`
+	RequireReadBeforeWrite *bool  `yaml:"require_read_before_write"`
 }
 
 func NewScenarioConfig(name, tools, description, prompt string) *ScenarioConfig {
+	return NewScenarioConfigWithMaxIterations(name, tools, description, prompt, 0)
+}
+
+// NewScenarioConfigWithMaxIterations creates a scenario config with a scenario-specific iteration limit
+func NewScenarioConfigWithMaxIterations(name, tools, description, prompt string, maxIterations int) *ScenarioConfig {
 	return &ScenarioConfig{
-		name:        name,
-		tools:       tools,
-		description: description,
-		prompt:      prompt,
+		name:          name,
+		tools:         tools,
+		description:   description,
+		prompt:        prompt,
+		maxIterations: maxIterations,
 	}
 }
 
@@ -46,6 +65,32 @@ func (s *ScenarioConfig) Prompt() string {
 	return s.prompt
 }
 
+func (s *ScenarioConfig) MaxIterations() int {
+	return s.maxIterations
+}
+
+// Thinking reports whether the model may use extended thinking in this scenario
+func (s *ScenarioConfig) Thinking() bool {
+	return !s.noThinking
+}
+
+// SetThinking enables or disables model thinking for the scenario (enabled by default)
+func (s *ScenarioConfig) SetThinking(enabled bool) {
+	s.noThinking = !enabled
+}
+
+// RequireReadBeforeWrite reports whether existing files must be read before
+// they are overwritten or edited in this scenario
+func (s *ScenarioConfig) RequireReadBeforeWrite() bool {
+	return !s.noReadCheck
+}
+
+// SetRequireReadBeforeWrite enables or disables the read-before-write check for
+// the scenario (enabled by default)
+func (s *ScenarioConfig) SetRequireReadBeforeWrite(required bool) {
+	s.noReadCheck = !required
+}
+
 // GetToolScope parses the tools field and returns which tool managers to use
 func (s *ScenarioConfig) GetToolScope() domain.ToolScope {
 	scope := domain.ToolScope{
@@ -66,6 +111,8 @@ func (s *ScenarioConfig) GetToolScope() domain.ToolScope {
 			scope.UseTodo = true
 		} else if toolLower == "bash" {
 			scope.UseBash = true
+		} else if toolLower == "git" {
+			scope.UseGit = true
 		} else if strings.HasPrefix(toolLower, "mcp:") {
 			// Extract MCP tool name (remove "mcp:" prefix, preserve case)
 			mcpName := strings.TrimPrefix(tool, "mcp:")
@@ -80,7 +127,7 @@ func (s *ScenarioConfig) GetToolScope() domain.ToolScope {
 	}
 
 	// Default to using default tools if nothing specified
-	if !scope.UseFilesystem && !scope.UseDefault && !scope.UseTodo && !scope.UseBash && len(scope.MCPTools) == 0 {
+	if !scope.UseFilesystem && !scope.UseDefault && !scope.UseTodo && !scope.UseBash && !scope.UseGit && len(scope.MCPTools) == 0 {
 		scope.UseDefault = true
 	}
 
@@ -91,7 +138,8 @@ func (s *ScenarioConfig) GetToolScope() domain.ToolScope {
 func (s *ScenarioConfig) RenderPrompt(userInput, scenarioReason, workingDir string) string {
 	prompt := s.prompt
 
-	// Replace template variables
+	// Replace template variables, git ones first so user input is not expanded
+	prompt = newGitContext(workingDir).render(prompt)
 	prompt = strings.ReplaceAll(prompt, "{{userInput}}", userInput)
 	prompt = strings.ReplaceAll(prompt, "{{scenarioReason}}", scenarioReason)
 	prompt = strings.ReplaceAll(prompt, "{{workingDir}}", workingDir)
@@ -219,16 +267,22 @@ func loadScenarioFile(filePath string, scenarios ScenarioMap) error {
 	}
 
 	// Parse YAML
-	var fileScenarios map[string]ScenarioConfig
+	var fileScenarios map[string]scenarioFile
 	if err := yaml.Unmarshal(data, &fileScenarios); err != nil {
 		return fmt.Errorf("failed to parse scenario file %s: %w", filePath, err)
 	}
 
-	// Add scenarios to the map, setting the name and normalizing keys to uppercase for case-insensitive lookup
-	for scenarioName, scenarioConfig := range fileScenarios {
-		scenarioConfig.name = scenarioName              // Keep original name for display
-		normalizedName := strings.ToUpper(scenarioName) // Normalize key for case-insensitive lookup
-		scenarios[normalizedName] = &scenarioConfig
+	// Add scenarios to the map, keeping the original name and normalizing keys to uppercase for case-insensitive lookup
+	for scenarioName, sf := range fileScenarios {
+		normalizedName := strings.ToUpper(scenarioName)
+		scenario := NewScenarioConfigWithMaxIterations(scenarioName, sf.Tools, sf.Description, sf.Prompt, sf.MaxIterations)
+		if sf.Thinking != nil {
+			scenario.SetThinking(*sf.Thinking)
+		}
+		if sf.RequireReadBeforeWrite != nil {
+			scenario.SetRequireReadBeforeWrite(*sf.RequireReadBeforeWrite)
+		}
+		scenarios[normalizedName] = scenario
 	}
 
 	return nil
@@ -1,6 +1,10 @@
 package infra
 
 import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/fpt/go-gennai-cli/internal/scenarios"
@@ -73,6 +77,58 @@ func TestLoadScenariosWithOverride(t *testing.T) {
 	t.Logf("Loaded %d scenarios", len(scenarios))
 }
 
+func TestLoadScenariosFromPath_MaxIterations(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "custom.yaml")
+	content := `research:
+  tools: default, web
+  description: Deep research
+  max_iterations: 40
+  thinking: false
+  require_read_before_write: false
+  prompt: "Research {{userInput}}"
+quick:
+  tools: default
+  description: Quick answers
+  prompt: "Answer {{userInput}}"
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios, err := LoadScenariosFromPath(path)
+	if err != nil {
+		t.Fatalf("Failed to load scenarios: %v", err)
+	}
+
+	research, ok := scenarios.GetScenario("RESEARCH")
+	if !ok {
+		t.Fatal("Expected RESEARCH scenario")
+	}
+	if research.Name() != "research" || research.Tools() != "default, web" {
+		t.Errorf("Unexpected scenario fields: name=%q tools=%q", research.Name(), research.Tools())
+	}
+	if research.MaxIterations() != 40 {
+		t.Errorf("Expected max_iterations 40, got %d", research.MaxIterations())
+	}
+	if research.Thinking() {
+		t.Error("Expected thinking: false to disable thinking")
+	}
+	if research.RequireReadBeforeWrite() {
+		t.Error("Expected require_read_before_write: false to disable the check")
+	}
+
+	quick, _ := scenarios.GetScenario("QUICK")
+	if quick.MaxIterations() != 0 {
+		t.Errorf("Expected unset max_iterations to be 0, got %d", quick.MaxIterations())
+	}
+	if !quick.Thinking() {
+		t.Error("Expected thinking to be enabled by default")
+	}
+	if !quick.RequireReadBeforeWrite() {
+		t.Error("Expected read-before-write to be required by default")
+	}
+}
+
 func TestScenarioConfig_GetToolScope(t *testing.T) {
 	testCases := []struct {
 		tools            string
@@ -137,6 +193,13 @@ func TestScenarioConfig_GetToolScope(t *testing.T) {
 			expectMCPTools:   []string{"*"},
 			description:      "MCP wildcard pattern should be parsed correctly",
 		},
+		{
+			tools:            "filesystem, git",
+			expectFilesystem: true,
+			expectDefault:    false,
+			expectMCPTools:   []string{},
+			description:      "Git tools do not imply default tools",
+		},
 	}
 
 	for _, tc := range testCases {
@@ -225,3 +288,42 @@ func TestScenarioConfigMap_GetAvailableScenarios(t *testing.T) {
 		}
 	}
 }
+
+func TestScenarioConfig_RenderPromptGitContext(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	config := NewScenarioConfig("test", "default", "Test scenario",
+		"Branch: {{gitBranch}}\nStatus:\n{{gitStatus}}\nCommits:\n{{recentCommits}}")
+
+	// Outside a repository the variables are empty
+	dir := t.TempDir()
+	if got := config.RenderPrompt("", "", dir); got != "Branch: \nStatus:\n\nCommits:\n" {
+		t.Errorf("expected empty git context outside a repository, got %q", got)
+	}
+
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	git("init", "-q", "-b", "main")
+	if err := os.WriteFile(filepath.Join(dir, "a.txt"), []byte("a"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	git("add", "a.txt")
+	git("commit", "-q", "-m", "Add a")
+	if err := os.WriteFile(filepath.Join(dir, "b.txt"), []byte("b"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got := config.RenderPrompt("", "", dir)
+	for _, want := range []string{"Branch: main\n", "?? b.txt", "Add a"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q in rendered prompt, got:\n%s", want, got)
+		}
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/fpt/go-gennai-cli/internal/config"
 	"github.com/fpt/go-gennai-cli/internal/tool"
 	"github.com/fpt/go-gennai-cli/pkg/agent/domain"
+	pkgmcp "github.com/fpt/go-gennai-cli/pkg/agent/mcp"
 	pkgLogger "github.com/fpt/go-gennai-cli/pkg/logger"
 	"github.com/fpt/go-gennai-cli/pkg/message"
 )
@@ -26,6 +27,12 @@ func NewIntegration() *Integration {
 	}
 }
 
+// SetPoolOptions sets how many connections are kept per server and how many
+// calls may run on a server at once; it applies to servers added afterwards
+func (i *Integration) SetPoolOptions(poolSize, maxConcurrent int) {
+	i.toolManager.SetPoolOptions(pkgmcp.PoolOptions{Size: poolSize, MaxConcurrent: maxConcurrent})
+}
+
 // GetToolManager returns the MCP-enhanced tool manager
 func (i *Integration) GetToolManager() domain.ToolManager {
 	return i.toolManager
@@ -2,20 +2,29 @@ package repository
 
 import (
 	"context"
+	"io"
 	"io/fs"
 )
 
 // FileSystemConfig holds configuration for the filesystem tool manager
 type FileSystemConfig struct {
 	AllowedDirectories []string `json:"allowed_directories"` // Paths where file operations are allowed
-	BlacklistedFiles   []string `json:"blacklisted_files"`   // Files that cannot be read
+	BlacklistedFiles   []string `json:"blacklisted_files"`   // Files that cannot be read or changed
+	MaxReadBytes       int      `json:"max_read_bytes"`      // Read output size before truncation (0 = default)
+	DisabledValidators []string `json:"disabled_validators"` // Post-edit validators to skip ("go", "python", "javascript", "rust", "json", "yaml", "toml")
+	HeadTailLines      int      `json:"head_tail_lines"`     // Lines file_head and file_tail return by default (0 = default)
+	HeadTailMaxLines   int      `json:"head_tail_max_lines"` // Most lines file_head and file_tail return (0 = default)
 }
 
 // FilesystemRepository abstracts filesystem operations for the filesystem tool manager
 type FilesystemRepository interface {
 	// File operations
 	ReadFile(ctx context.Context, path string) ([]byte, error)
+	Open(ctx context.Context, path string) (io.ReadSeekCloser, error) // for reading part of a large file
 	WriteFile(ctx context.Context, path string, data []byte, perm fs.FileMode) error
+	Chmod(ctx context.Context, path string, perm fs.FileMode) error
+	Rename(ctx context.Context, oldPath, newPath string) error
+	Remove(ctx context.Context, path string) error // a file or an empty directory
 	Stat(ctx context.Context, path string) (fs.FileInfo, error)
 
 	// Directory operations
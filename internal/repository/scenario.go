@@ -6,6 +6,7 @@ type ToolScope struct {
 	UseDefault    bool
 	UseTodo       bool
 	UseBash       bool
+	UseGit        bool     // Read-only git tools (git_status, git_diff, git_log)
 	MCPTools      []string // List of MCP tool manager names (e.g., ["serverA", "serverB"])
 }
 
@@ -15,6 +16,9 @@ type Scenario interface {
 	Tools() string
 	Description() string
 	Prompt() string
+	MaxIterations() int           // Scenario-specific ReAct loop limit (0 = use global setting)
+	Thinking() bool               // Whether the model may use extended thinking (thinking: false disables it)
+	RequireReadBeforeWrite() bool // Whether existing files must be read before being written
 	GetToolScope() ToolScope
 	RenderPrompt(userInput, scenarioReason, workingDir string) string
 }
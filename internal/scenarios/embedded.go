@@ -12,10 +12,13 @@ var embeddedFiles embed.FS
 
 // ScenarioConfig represents a scenario configuration from YAML (duplicate to avoid import cycle)
 type ScenarioConfig struct {
-	Name        string `yaml:"-"` // Set during loading
-	Tools       string `yaml:"tools"`
-	Description string `yaml:"description"`
-	Prompt      string `yaml:"prompt"`
+	Name                   string `yaml:"-"` // Set during loading
+	Tools                  string `yaml:"tools"`
+	Description            string `yaml:"description"`
+	Prompt                 string `yaml:"prompt"`
+	MaxIterations          int    `yaml:"max_iterations,omitempty"`            // 0 = use global setting
+	Thinking               *bool  `yaml:"thinking,omitempty"`                  // nil = enabled
+	RequireReadBeforeWrite *bool  `yaml:"require_read_before_write,omitempty"` // nil = required
 }
 
 // ScenarioConfigMap represents all scenarios loaded from YAML files
@@ -0,0 +1,494 @@
+// Package server exposes scenario runners over HTTP so that a GUI (or any other
+// client) can drive the agent: prompts stream agent events back as server-sent
+// events, and each session keeps its own conversation.
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fpt/go-gennai-cli/pkg/agent/domain"
+	"github.com/fpt/go-gennai-cli/pkg/agent/events"
+	pkgLogger "github.com/fpt/go-gennai-cli/pkg/logger"
+	"github.com/fpt/go-gennai-cli/pkg/message"
+)
+
+var logger = pkgLogger.NewComponentLogger("server")
+
+// DefaultShutdownTimeout is how long running prompts may take to finish once
+// shutdown begins before they are canceled
+const DefaultShutdownTimeout = 30 * time.Second
+
+// validSessionID limits session IDs to names that are safe in URLs and logs
+var validSessionID = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// Runner is the part of app.ScenarioRunner the server drives
+type Runner interface {
+	Invoke(ctx context.Context, userInput string, scenarioName string) (message.Message, error)
+	StreamEvents(ch chan<- events.AgentEvent)
+	GetMessageState() domain.State
+	ClearHistory()
+}
+
+// RunnerFactory creates the runner for a new session
+type RunnerFactory func(sessionID string) (Runner, error)
+
+// Server maps session IDs to runners and serves them over HTTP:
+//
+//	GET    /health                  liveness check
+//	GET    /sessions                IDs of the open sessions
+//	POST   /sessions/{id}/prompt    run {"prompt", "scenario"}; streams events as SSE
+//	GET    /sessions/{id}/messages  conversation history
+//	DELETE /sessions/{id}           clear the history and close the session
+//
+// Prompts run tools without approval, so every request but /health must
+// carry the server's token as "Authorization: Bearer <token>", and requests
+// whose Host or Origin names another server (DNS rebinding, cross-site
+// forms) are refused.
+type Server struct {
+	newRunner       RunnerFactory
+	defaultScenario string
+	shutdownTimeout time.Duration
+	token           string
+
+	// bindIP and bindName are the listen address, used to check Host and
+	// Origin; unset, only loopback names are accepted
+	bindIP   net.IP
+	bindName string
+
+	mu       sync.Mutex
+	sessions map[string]*session
+}
+
+// session is one conversation; busy is held while a prompt runs, since the
+// runner and its state serve one request at a time. deleted is guarded by
+// Server.mu and set when the session is removed, so requests that looked it
+// up earlier don't use it once they get busy.
+type session struct {
+	runner  Runner
+	busy    sync.Mutex
+	deleted bool
+}
+
+// New creates a server that runs prompts without a scenario in defaultScenario.
+// It generates a new token; clients get it from Token.
+func New(newRunner RunnerFactory, defaultScenario string) *Server {
+	return &Server{
+		newRunner:       newRunner,
+		defaultScenario: defaultScenario,
+		shutdownTimeout: DefaultShutdownTimeout,
+		token:           rand.Text(),
+		sessions:        make(map[string]*session),
+	}
+}
+
+// Token returns the bearer token requests must carry
+func (s *Server) Token() string {
+	return s.token
+}
+
+// IsLoopbackAddr reports whether addr (host:port) listens on the local
+// machine only. An empty host listens on every interface.
+func IsLoopbackAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// Handler returns the HTTP handler serving the API
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /health", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+	mux.HandleFunc("GET /sessions", s.handleListSessions)
+	mux.HandleFunc("POST /sessions/{id}/prompt", s.handlePrompt)
+	mux.HandleFunc("GET /sessions/{id}/messages", s.handleMessages)
+	mux.HandleFunc("DELETE /sessions/{id}", s.handleDeleteSession)
+	return s.guard(mux)
+}
+
+// guard refuses requests for another host, from another origin, or without
+// the token
+func (s *Server) guard(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.allowedHost(r.Host) {
+			writeError(w, http.StatusForbidden, fmt.Sprintf("host %q is not served here", r.Host))
+			return
+		}
+		if origin := r.Header.Get("Origin"); origin != "" {
+			u, err := url.Parse(origin)
+			if err != nil || !s.allowedHost(u.Host) {
+				writeError(w, http.StatusForbidden, fmt.Sprintf("origin %q is not allowed", origin))
+				return
+			}
+		}
+		if r.URL.Path != "/health" && !s.authorized(r) {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeError(w, http.StatusUnauthorized, "missing or invalid bearer token")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// authorized checks the request's bearer token
+func (s *Server) authorized(r *http.Request) bool {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(strings.TrimSpace(token)), []byte(s.token)) == 1
+}
+
+// allowedHost reports whether a Host header (or an Origin's host) names this
+// server. Loopback names are accepted when listening on loopback or on every
+// interface, the listen name or IP always, and any IP literal when listening
+// on every interface; other DNS names are refused, as a rebinding attack
+// would use them.
+func (s *Server) allowedHost(hostport string) bool {
+	host := hostport
+	if h, _, err := net.SplitHostPort(hostport); err == nil {
+		host = h
+	}
+	host = strings.Trim(host, "[]")
+	if host == "" {
+		return false
+	}
+	if s.bindName != "" && strings.EqualFold(host, s.bindName) {
+		return true
+	}
+	localBind := s.bindIP == nil || s.bindIP.IsLoopback() || s.bindIP.IsUnspecified()
+	if strings.EqualFold(host, "localhost") {
+		return localBind
+	}
+	ip := net.ParseIP(host)
+	switch {
+	case ip == nil:
+		return false
+	case ip.IsLoopback():
+		return localBind
+	case s.bindIP != nil && s.bindIP.IsUnspecified():
+		return true
+	default:
+		return ip.Equal(s.bindIP)
+	}
+}
+
+// ListenAndServe serves the API on addr until ctx is done, then shuts down
+// gracefully: new requests are refused and running prompts get the shutdown
+// timeout to finish before they are canceled.
+func (s *Server) ListenAndServe(ctx context.Context, addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	if host, _, err := net.SplitHostPort(addr); err == nil && net.ParseIP(host) == nil {
+		s.bindName = host
+	}
+	return s.Serve(ctx, listener)
+}
+
+// Serve is ListenAndServe on an existing listener
+func (s *Server) Serve(ctx context.Context, listener net.Listener) error {
+	if tcpAddr, ok := listener.Addr().(*net.TCPAddr); ok {
+		s.bindIP = tcpAddr.IP
+	}
+
+	// Requests inherit runCtx, so canceling it stops prompts still running
+	// when the shutdown timeout expires
+	runCtx, cancelRuns := context.WithCancel(context.Background())
+	defer cancelRuns()
+
+	srv := &http.Server{
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext:       func(net.Listener) context.Context { return runCtx },
+	}
+	serveErr := make(chan error, 1)
+	go func() { serveErr <- srv.Serve(listener) }()
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+
+	logger.InfoWithIntention(pkgLogger.IntentionStatus, "Shutting down, waiting for running prompts", "timeout", s.shutdownTimeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
+	defer cancel()
+	err := srv.Shutdown(shutdownCtx)
+	if errors.Is(err, context.DeadlineExceeded) {
+		logger.Warn("Canceling prompts still running after the shutdown timeout")
+		cancelRuns()
+		err = srv.Close()
+	}
+	if serveErr := <-serveErr; !errors.Is(serveErr, http.ErrServerClosed) {
+		return serveErr
+	}
+	return err
+}
+
+// promptRequest is the body of POST /sessions/{id}/prompt
+type promptRequest struct {
+	Prompt   string `json:"prompt"`
+	Scenario string `json:"scenario,omitempty"`
+}
+
+// handlePrompt runs a prompt in the session, creating it on first use, and
+// streams every agent event as an SSE event named after its type. A failed run
+// ends with an "error" event; the stream closes when the run is over.
+func (s *Server) handlePrompt(w http.ResponseWriter, r *http.Request) {
+	// Browsers send form content types cross-site without a preflight
+	if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mediaType != "application/json" {
+		writeError(w, http.StatusUnsupportedMediaType, "Content-Type must be application/json")
+		return
+	}
+	var req promptRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid request body: %v", err))
+		return
+	}
+	if strings.TrimSpace(req.Prompt) == "" {
+		writeError(w, http.StatusBadRequest, "prompt is required")
+		return
+	}
+	scenario := strings.ToUpper(req.Scenario)
+	if scenario == "" {
+		scenario = s.defaultScenario
+	}
+
+	id := r.PathValue("id")
+	sess, status, err := s.session(id, true)
+	if err != nil {
+		writeError(w, status, err.Error())
+		return
+	}
+	if !sess.busy.TryLock() {
+		writeError(w, http.StatusConflict, "session is already running a prompt")
+		return
+	}
+	defer sess.busy.Unlock()
+	if s.isDeleted(sess) {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("session %q was deleted", id))
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "streaming is not supported")
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	logger.InfoWithIntention(pkgLogger.IntentionStatus, "Running prompt", "session", id, "scenario", scenario)
+	start := time.Now()
+
+	// The runner blocks on each event until it is written, so keep draining the
+	// channel until Invoke returns, even after the client has gone away
+	eventCh := make(chan events.AgentEvent)
+	done := make(chan error, 1)
+	sess.runner.StreamEvents(eventCh)
+	go func() {
+		_, err := sess.runner.Invoke(r.Context(), req.Prompt, scenario)
+		sess.runner.StreamEvents(nil)
+		done <- err
+	}()
+	for {
+		select {
+		case event := <-eventCh:
+			writeSSE(w, flusher, event)
+		case err := <-done:
+			if err != nil {
+				logger.Warn("Prompt failed", "session", id, "error", err)
+				writeSSE(w, flusher, events.AgentEvent{
+					Type:      events.EventTypeError,
+					Timestamp: time.Now(),
+					Data:      events.ErrorData{Error: err, Context: "prompt failed"},
+				})
+				return
+			}
+			logger.InfoWithIntention(pkgLogger.IntentionSuccess, "Prompt finished", "session", id, "elapsed", time.Since(start).Round(time.Millisecond))
+			return
+		}
+	}
+}
+
+// historyMessage is one message of GET /sessions/{id}/messages
+type historyMessage struct {
+	ID        string                     `json:"id"`
+	Type      string                     `json:"type"`
+	Content   string                     `json:"content"`
+	Thinking  string                     `json:"thinking,omitempty"`
+	ToolName  string                     `json:"tool_name,omitempty"`
+	Arguments message.ToolArgumentValues `json:"arguments,omitempty"`
+	Timestamp time.Time                  `json:"timestamp"`
+}
+
+func (s *Server) handleMessages(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	sess, status, err := s.session(id, false)
+	if err != nil {
+		writeError(w, status, err.Error())
+		return
+	}
+	if !sess.busy.TryLock() {
+		writeError(w, http.StatusConflict, "session is running a prompt; fetch the history when it has finished")
+		return
+	}
+	defer sess.busy.Unlock()
+	if s.isDeleted(sess) {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("session %q not found", id))
+		return
+	}
+
+	history := []historyMessage{}
+	for _, msg := range sess.runner.GetMessageState().GetMessages() {
+		history = append(history, toHistoryMessages(msg)...)
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"session_id": id, "messages": history})
+}
+
+// toHistoryMessages converts a message for the history response, expanding a
+// batch of tool calls into its calls
+func toHistoryMessages(msg message.Message) []historyMessage {
+	switch m := msg.(type) {
+	case *message.ToolCallBatchMessage:
+		var out []historyMessage
+		for _, call := range m.Calls() {
+			out = append(out, toHistoryMessages(call)...)
+		}
+		return out
+	case *message.ToolCallMessage:
+		return []historyMessage{{
+			ID:        m.ID(),
+			Type:      m.Type().String(),
+			Content:   m.Content(),
+			Thinking:  m.Thinking(),
+			ToolName:  string(m.ToolName()),
+			Arguments: m.ToolArguments(),
+			Timestamp: m.Timestamp(),
+		}}
+	default:
+		return []historyMessage{{
+			ID:        msg.ID(),
+			Type:      msg.Type().String(),
+			Content:   msg.Content(),
+			Thinking:  msg.Thinking(),
+			Timestamp: msg.Timestamp(),
+		}}
+	}
+}
+
+func (s *Server) handleDeleteSession(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	sess, status, err := s.session(id, false)
+	if err != nil {
+		writeError(w, status, err.Error())
+		return
+	}
+	if !sess.busy.TryLock() {
+		writeError(w, http.StatusConflict, "session is running a prompt")
+		return
+	}
+	defer sess.busy.Unlock()
+
+	s.mu.Lock()
+	if sess.deleted {
+		s.mu.Unlock()
+		writeError(w, http.StatusNotFound, fmt.Sprintf("session %q not found", id))
+		return
+	}
+	sess.deleted = true
+	delete(s.sessions, id)
+	s.mu.Unlock()
+	sess.runner.ClearHistory()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// isDeleted reports whether sess was removed after the request looked it up
+func (s *Server) isDeleted(sess *session) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return sess.deleted
+}
+
+func (s *Server) handleListSessions(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	ids := make([]string, 0, len(s.sessions))
+	for id := range s.sessions {
+		ids = append(ids, id)
+	}
+	s.mu.Unlock()
+	sort.Strings(ids)
+	writeJSON(w, http.StatusOK, map[string]any{"sessions": ids})
+}
+
+// session returns the session with id, creating it when create is set. On
+// failure it also returns the HTTP status to answer with.
+func (s *Server) session(id string, create bool) (*session, int, error) {
+	if !validSessionID.MatchString(id) {
+		return nil, http.StatusBadRequest, fmt.Errorf("invalid session ID %q (use up to 64 letters, digits, '-' or '_')", id)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if sess, ok := s.sessions[id]; ok {
+		return sess, 0, nil
+	}
+	if !create {
+		return nil, http.StatusNotFound, fmt.Errorf("session %q not found", id)
+	}
+	runner, err := s.newRunner(id)
+	if err != nil {
+		return nil, http.StatusInternalServerError, fmt.Errorf("failed to create session: %v", err)
+	}
+	sess := &session{runner: runner}
+	s.sessions[id] = sess
+	logger.InfoWithIntention(pkgLogger.IntentionStatus, "Session created", "session", id)
+	return sess, 0, nil
+}
+
+// writeSSE writes one event; write errors mean the client left, and the
+// request context then cancels the run
+func writeSSE(w http.ResponseWriter, flusher http.Flusher, event events.AgentEvent) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		logger.Warn("Failed to encode event", "type", event.Type, "error", err)
+		return
+	}
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data)
+	flusher.Flush()
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
@@ -0,0 +1,426 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fpt/go-gennai-cli/pkg/agent/domain"
+	"github.com/fpt/go-gennai-cli/pkg/agent/events"
+	"github.com/fpt/go-gennai-cli/pkg/agent/state"
+	"github.com/fpt/go-gennai-cli/pkg/message"
+)
+
+// fakeRunner answers every prompt with one tool event and a response, or
+// blocks until release is closed when it is set
+type fakeRunner struct {
+	mu        sync.Mutex
+	sink      chan<- events.AgentEvent
+	state     *state.MessageState
+	scenarios []string
+	release   chan struct{}
+	fail      error
+}
+
+func newFakeRunner() *fakeRunner {
+	return &fakeRunner{state: state.NewMessageState()}
+}
+
+func (f *fakeRunner) StreamEvents(ch chan<- events.AgentEvent) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sink = ch
+}
+
+func (f *fakeRunner) emit(t events.EventType, data interface{}) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.sink != nil {
+		f.sink <- events.AgentEvent{Type: t, Timestamp: time.Now(), Data: data}
+	}
+}
+
+func (f *fakeRunner) Invoke(ctx context.Context, userInput string, scenarioName string) (message.Message, error) {
+	f.scenarios = append(f.scenarios, scenarioName)
+	if f.release != nil {
+		select {
+		case <-f.release:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if f.fail != nil {
+		return nil, f.fail
+	}
+	f.state.AddMessage(message.NewChatMessage(message.MessageTypeUser, userInput))
+	call := message.NewToolCallMessage("Read", message.ToolArgumentValues{"file_path": "main.go"})
+	f.state.AddMessage(call)
+	f.emit(events.EventTypeToolCallStart, events.ToolCallStartData{ToolName: "Read", Arguments: call.ToolArguments()})
+	resp := message.NewChatMessage(message.MessageTypeAssistant, "answer to "+userInput)
+	f.state.AddMessage(resp)
+	f.emit(events.EventTypeResponse, events.ResponseData{Message: resp})
+	return resp, nil
+}
+
+func (f *fakeRunner) GetMessageState() domain.State { return f.state }
+
+func (f *fakeRunner) ClearHistory() { f.state.Clear() }
+
+type sseEvent struct {
+	name string
+	data map[string]any
+}
+
+// send makes a request carrying the server's token; a body is sent as JSON
+func send(srv *Server, method, url, body string) (*http.Response, error) {
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+srv.Token())
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return http.DefaultClient.Do(req)
+}
+
+func readSSE(t *testing.T, resp *http.Response) []sseEvent {
+	t.Helper()
+	var out []sseEvent
+	var name string
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			name = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			var data map[string]any
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &data); err != nil {
+				t.Errorf("invalid event data %q: %v", line, err)
+				continue
+			}
+			out = append(out, sseEvent{name: name, data: data})
+		}
+	}
+	return out
+}
+
+func TestServer_PromptHistoryAndDelete(t *testing.T) {
+	runners := map[string]*fakeRunner{}
+	srv := New(func(id string) (Runner, error) {
+		runners[id] = newFakeRunner()
+		return runners[id], nil
+	}, "CODE")
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	resp, err := send(srv, http.MethodPost, ts.URL+"/sessions/s1/prompt", `{"prompt":"hello","scenario":"respond"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("unexpected content type %q", ct)
+	}
+	got := readSSE(t, resp)
+	resp.Body.Close()
+	if len(got) != 2 || got[0].name != "tool_call_start" || got[1].name != "response" {
+		t.Fatalf("unexpected events %+v", got)
+	}
+	if msg := got[1].data["data"].(map[string]any)["message"].(map[string]any); msg["content"] != "answer to hello" {
+		t.Errorf("unexpected response event %+v", got[1].data)
+	}
+	if s := runners["s1"].scenarios; len(s) != 1 || s[0] != "RESPOND" {
+		t.Errorf("expected the scenario upper-cased, got %v", s)
+	}
+
+	// Without a scenario the default is used, in the same session
+	resp, _ = send(srv, http.MethodPost, ts.URL+"/sessions/s1/prompt", `{"prompt":"again"}`)
+	readSSE(t, resp)
+	resp.Body.Close()
+	if len(runners) != 1 || runners["s1"].scenarios[1] != "CODE" {
+		t.Errorf("expected the default scenario in the existing session, got %v", runners["s1"].scenarios)
+	}
+
+	resp, err = send(srv, http.MethodGet, ts.URL+"/sessions/s1/messages", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var history struct {
+		SessionID string           `json:"session_id"`
+		Messages  []historyMessage `json:"messages"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&history); err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if history.SessionID != "s1" || len(history.Messages) != 6 {
+		t.Fatalf("unexpected history %+v", history)
+	}
+	if m := history.Messages[1]; m.ToolName != "Read" || m.Arguments["file_path"] != "main.go" {
+		t.Errorf("expected the tool call with its arguments, got %+v", m)
+	}
+
+	resp, err = send(srv, http.MethodDelete, ts.URL+"/sessions/s1", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent || len(runners["s1"].state.GetMessages()) != 0 {
+		t.Errorf("expected the session to be cleared, got status %d", resp.StatusCode)
+	}
+	resp, _ = send(srv, http.MethodGet, ts.URL+"/sessions/s1/messages", "")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected a deleted session to be gone, got status %d", resp.StatusCode)
+	}
+}
+
+func TestServer_Errors(t *testing.T) {
+	runner := newFakeRunner()
+	runner.fail = errors.New("model unavailable")
+	srv := New(func(id string) (Runner, error) {
+		if id == "broken" {
+			return nil, errors.New("no client")
+		}
+		return runner, nil
+	}, "CODE")
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	post := func(path, body string) *http.Response {
+		t.Helper()
+		resp, err := send(srv, http.MethodPost, ts.URL+path, body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	for _, tt := range []struct {
+		path, body string
+		status     int
+	}{
+		{"/sessions/s1/prompt", `{"prompt":""}`, http.StatusBadRequest},
+		{"/sessions/s1/prompt", `not json`, http.StatusBadRequest},
+		{"/sessions/bad.id/prompt", `{"prompt":"hi"}`, http.StatusBadRequest},
+		{"/sessions/broken/prompt", `{"prompt":"hi"}`, http.StatusInternalServerError},
+	} {
+		resp := post(tt.path, tt.body)
+		resp.Body.Close()
+		if resp.StatusCode != tt.status {
+			t.Errorf("POST %s %s: expected status %d, got %d", tt.path, tt.body, tt.status, resp.StatusCode)
+		}
+	}
+
+	// A failed run ends the stream with an error event
+	resp := post("/sessions/s1/prompt", `{"prompt":"hi"}`)
+	got := readSSE(t, resp)
+	resp.Body.Close()
+	if len(got) != 1 || got[0].name != "error" || got[0].data["data"].(map[string]any)["error"] != "model unavailable" {
+		t.Errorf("expected an error event, got %+v", got)
+	}
+}
+
+func TestServer_BusySessionAndShutdown(t *testing.T) {
+	runner := newFakeRunner()
+	runner.release = make(chan struct{})
+	srv := New(func(id string) (Runner, error) { return runner, nil }, "CODE")
+	srv.shutdownTimeout = 100 * time.Millisecond
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, stop := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() { served <- srv.Serve(ctx, listener) }()
+	base := "http://" + listener.Addr().String()
+
+	// Start a prompt that blocks until the server shuts down
+	streamed := make(chan []sseEvent, 1)
+	go func() {
+		resp, err := send(srv, http.MethodPost, base+"/sessions/s1/prompt", `{"prompt":"slow"}`)
+		if err != nil {
+			streamed <- nil
+			return
+		}
+		defer resp.Body.Close()
+		streamed <- readSSE(t, resp)
+	}()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		resp, err := send(srv, http.MethodGet, base+"/sessions/s1/messages", "")
+		if err == nil {
+			resp.Body.Close()
+			if resp.StatusCode == http.StatusConflict {
+				break
+			}
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("prompt never started")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	resp, _ := send(srv, http.MethodPost, base+"/sessions/s1/prompt", `{"prompt":"second"}`)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusConflict {
+		t.Errorf("expected a second prompt on a busy session to conflict, got %d", resp.StatusCode)
+	}
+
+	// Shutdown cancels the prompt once the timeout expires
+	stop()
+	select {
+	case err := <-served:
+		if err != nil {
+			t.Errorf("unexpected shutdown error: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not shut down")
+	}
+	if got := <-streamed; len(got) > 0 && got[len(got)-1].name != "error" {
+		t.Errorf("expected the canceled prompt to end with an error event, got %+v", got)
+	}
+}
+
+func TestServer_RefusesUnauthorizedAndForeignRequests(t *testing.T) {
+	srv := New(func(id string) (Runner, error) { return newFakeRunner(), nil }, "CODE")
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	for _, tt := range []struct {
+		name        string
+		method      string
+		path        string
+		token       string
+		contentType string
+		host        string
+		origin      string
+		status      int
+	}{
+		{"health needs no token", http.MethodGet, "/health", "", "", "", "", http.StatusOK},
+		{"missing token", http.MethodGet, "/sessions", "", "", "", "", http.StatusUnauthorized},
+		{"wrong token", http.MethodGet, "/sessions", "nope", "", "", "", http.StatusUnauthorized},
+		{"token", http.MethodGet, "/sessions", srv.Token(), "", "", "", http.StatusOK},
+		{"form post", http.MethodPost, "/sessions/s1/prompt", srv.Token(), "application/x-www-form-urlencoded", "", "", http.StatusUnsupportedMediaType},
+		{"text post", http.MethodPost, "/sessions/s1/prompt", srv.Token(), "text/plain", "", "", http.StatusUnsupportedMediaType},
+		{"rebound host", http.MethodGet, "/sessions", srv.Token(), "", "attacker.example:8420", "", http.StatusForbidden},
+		{"localhost host", http.MethodGet, "/sessions", srv.Token(), "", "localhost:8420", "", http.StatusOK},
+		{"foreign origin", http.MethodGet, "/sessions", srv.Token(), "", "", "https://attacker.example", http.StatusForbidden},
+		{"local origin", http.MethodGet, "/sessions", srv.Token(), "", "", "http://localhost:3000", http.StatusOK},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(tt.method, ts.URL+tt.path, strings.NewReader(`{"prompt":"hi"}`))
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			if tt.host != "" {
+				req.Host = tt.host
+			}
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.status {
+				t.Errorf("expected status %d, got %d", tt.status, resp.StatusCode)
+			}
+		})
+	}
+
+	if other := New(nil, "CODE"); other.Token() == srv.Token() || len(srv.Token()) < 20 {
+		t.Errorf("expected a long random token per server, got %q and %q", srv.Token(), other.Token())
+	}
+}
+
+func TestServer_AllowedHost(t *testing.T) {
+	for _, tt := range []struct {
+		bindIP   string
+		bindName string
+		host     string
+		want     bool
+	}{
+		{"127.0.0.1", "", "127.0.0.1:8420", true},
+		{"127.0.0.1", "", "[::1]:8420", true},
+		{"127.0.0.1", "", "localhost", true},
+		{"127.0.0.1", "", "192.168.1.5:8420", false},
+		{"127.0.0.1", "", "attacker.example:8420", false},
+		{"0.0.0.0", "", "192.168.1.5:8420", true},
+		{"0.0.0.0", "", "localhost:8420", true},
+		{"0.0.0.0", "", "attacker.example:8420", false},
+		{"192.168.1.5", "", "192.168.1.5:8420", true},
+		{"192.168.1.5", "", "localhost:8420", false},
+		{"192.168.1.5", "devbox.lan", "devbox.lan:8420", true},
+		{"127.0.0.1", "", "", false},
+	} {
+		srv := &Server{bindIP: net.ParseIP(tt.bindIP), bindName: tt.bindName}
+		if got := srv.allowedHost(tt.host); got != tt.want {
+			t.Errorf("bound to %s (%q): allowedHost(%q) = %v, want %v", tt.bindIP, tt.bindName, tt.host, got, tt.want)
+		}
+	}
+}
+
+func TestIsLoopbackAddr(t *testing.T) {
+	for addr, want := range map[string]bool{
+		"127.0.0.1:8420": true,
+		"localhost:8420": true,
+		"[::1]:8420":     true,
+		":8420":          false,
+		"0.0.0.0:8420":   false,
+		"10.0.0.2:8420":  false,
+		"devbox.lan:80":  false,
+		"no-port":        false,
+	} {
+		if got := IsLoopbackAddr(addr); got != want {
+			t.Errorf("IsLoopbackAddr(%q) = %v, want %v", addr, got, want)
+		}
+	}
+}
+
+func TestServer_PromptOnDeletedSessionIsRefused(t *testing.T) {
+	runner := newFakeRunner()
+	srv := New(func(id string) (Runner, error) { return runner, nil }, "CODE")
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	resp, err := send(srv, http.MethodPost, ts.URL+"/sessions/s1/prompt", `{"prompt":"hi"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	readSSE(t, resp)
+	resp.Body.Close()
+	stale, _, _ := srv.session("s1", false)
+
+	resp, _ = send(srv, http.MethodDelete, ts.URL+"/sessions/s1", "")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent || !srv.isDeleted(stale) {
+		t.Fatalf("expected the session to be deleted, got status %d", resp.StatusCode)
+	}
+
+	// A request that looked the session up before the delete still holds it
+	srv.mu.Lock()
+	srv.sessions["s1"] = stale
+	srv.mu.Unlock()
+	resp, _ = send(srv, http.MethodPost, ts.URL+"/sessions/s1/prompt", `{"prompt":"again"}`)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected a prompt on a deleted session to get 404, got %d", resp.StatusCode)
+	}
+	if len(runner.scenarios) != 1 {
+		t.Errorf("expected the deleted session not to run, got %d runs", len(runner.scenarios))
+	}
+}
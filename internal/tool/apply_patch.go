@@ -0,0 +1,352 @@
+package tool
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/fpt/go-gennai-cli/pkg/message"
+)
+
+const devNull = "/dev/null"
+
+var hunkHeaderRe = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+
+// FilePatch is the part of a unified diff that applies to one file
+type FilePatch struct {
+	OldPath string // "/dev/null" when the patch creates the file
+	NewPath string // "/dev/null" when the patch deletes the file
+	hunks   []patchHunk
+}
+
+// patchHunk is one @@ section; lines keep their ' ', '-' or '+' prefix
+type patchHunk struct {
+	header    string
+	oldStart  int
+	lines     []string
+	noNewline bool // the new side ends without a trailing newline
+}
+
+// Path returns the path the patch applies to, without a/ or b/ prefixes
+func (p FilePatch) Path() string {
+	if p.NewPath == devNull {
+		return p.OldPath
+	}
+	return p.NewPath
+}
+
+// IsCreate reports whether the patch creates a new file
+func (p FilePatch) IsCreate() bool { return p.OldPath == devNull }
+
+// IsDelete reports whether the patch deletes the file
+func (p FilePatch) IsDelete() bool { return p.NewPath == devNull }
+
+// LineCounts returns the number of added and removed lines
+func (p FilePatch) LineCounts() (added, removed int) {
+	for _, h := range p.hunks {
+		for _, line := range h.lines {
+			switch line[0] {
+			case '+':
+				added++
+			case '-':
+				removed++
+			}
+		}
+	}
+	return added, removed
+}
+
+// ParseUnifiedDiff splits a unified diff (as produced by diff -u or git diff)
+// into per-file patches. Git headers such as "diff --git" and "index" are skipped.
+func ParseUnifiedDiff(patch string) ([]FilePatch, error) {
+	lines := strings.Split(strings.ReplaceAll(patch, "\r\n", "\n"), "\n")
+	var files []FilePatch
+	for i := 0; i < len(lines); i++ {
+		if !strings.HasPrefix(lines[i], "--- ") {
+			continue
+		}
+		if i+1 >= len(lines) || !strings.HasPrefix(lines[i+1], "+++ ") {
+			return nil, fmt.Errorf("line %d: expected +++ header after %q", i+2, lines[i])
+		}
+		fp := FilePatch{
+			OldPath: patchHeaderPath(lines[i][4:], "a/"),
+			NewPath: patchHeaderPath(lines[i+1][4:], "b/"),
+		}
+		if !fp.IsCreate() && !fp.IsDelete() && fp.OldPath != fp.NewPath {
+			return nil, fmt.Errorf("renaming %s to %s is not supported", fp.OldPath, fp.NewPath)
+		}
+		i += 2
+
+		for i < len(lines) && strings.HasPrefix(lines[i], "@@") {
+			hunk, next, err := parseHunk(lines, i)
+			if err != nil {
+				return nil, err
+			}
+			fp.hunks = append(fp.hunks, hunk)
+			i = next
+		}
+		if len(fp.hunks) == 0 {
+			return nil, fmt.Errorf("no hunks for %s", fp.Path())
+		}
+		files = append(files, fp)
+		i-- // the loop increment moves to the line after the last hunk
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no file headers (---/+++) found; expected a unified diff")
+	}
+	return files, nil
+}
+
+// patchHeaderPath extracts the path from a ---/+++ header value
+func patchHeaderPath(value, prefix string) string {
+	// Drop a trailing timestamp as written by diff -u
+	if tab := strings.IndexByte(value, '\t'); tab >= 0 {
+		value = value[:tab]
+	}
+	value = strings.TrimSpace(value)
+	if value == devNull {
+		return devNull
+	}
+	return strings.TrimPrefix(value, prefix)
+}
+
+// parseHunk reads the hunk starting at lines[start], using the header's line
+// counts to find its end. It returns the index of the first line after the hunk.
+func parseHunk(lines []string, start int) (patchHunk, int, error) {
+	m := hunkHeaderRe.FindStringSubmatch(lines[start])
+	if m == nil {
+		return patchHunk{}, 0, fmt.Errorf("line %d: malformed hunk header %q", start+1, lines[start])
+	}
+	hunk := patchHunk{header: m[0]}
+	hunk.oldStart, _ = strconv.Atoi(m[1])
+	oldCount, newCount := 1, 1
+	if m[2] != "" {
+		oldCount, _ = strconv.Atoi(m[2])
+	}
+	if m[4] != "" {
+		newCount, _ = strconv.Atoi(m[4])
+	}
+
+	i := start + 1
+	for ; i < len(lines) && (oldCount > 0 || newCount > 0); i++ {
+		line := lines[i]
+		if line == "" {
+			// Editors and models often strip the space from empty context lines
+			line = " "
+		}
+		switch line[0] {
+		case ' ':
+			oldCount--
+			newCount--
+		case '-':
+			oldCount--
+		case '+':
+			newCount--
+		case '\\':
+			continue
+		default:
+			return patchHunk{}, 0, fmt.Errorf("line %d: unexpected %q in hunk %s", i+1, lines[i], hunk.header)
+		}
+		hunk.lines = append(hunk.lines, line)
+	}
+	if oldCount != 0 || newCount != 0 {
+		return patchHunk{}, 0, fmt.Errorf("hunk %s: line counts do not match its content", hunk.header)
+	}
+	// A "\ No newline at end of file" marker after the last new-side line
+	if i < len(lines) && strings.HasPrefix(lines[i], `\`) {
+		if n := len(hunk.lines); n > 0 && hunk.lines[n-1][0] != '-' {
+			hunk.noNewline = true
+		}
+		i++
+	}
+	return hunk, i, nil
+}
+
+// Apply returns content with the patch applied. Every hunk's context and removed
+// lines must match the content exactly; a hunk may be found up to maxHunkOffset
+// lines away from its stated line (after the shift of the hunk before it) if
+// earlier edits moved the file's lines.
+func (p FilePatch) Apply(content string) (string, error) {
+	lines := splitPatchLines(content)
+	trailingNewline := content == "" || strings.HasSuffix(content, "\n")
+
+	var out []string
+	pos := 0   // next unconsumed line of the original content
+	shift := 0 // how far the previous hunk was found from its stated line
+	for n, h := range p.hunks {
+		var oldLines, newLines []string
+		for _, line := range h.lines {
+			if line[0] != '+' {
+				oldLines = append(oldLines, line[1:])
+			}
+			if line[0] != '-' {
+				newLines = append(newLines, line[1:])
+			}
+		}
+
+		// An empty old range starts after the stated line; otherwise at it
+		want := h.oldStart - 1
+		if len(oldLines) == 0 {
+			want = h.oldStart
+		}
+		at := findHunk(lines, oldLines, pos, want+shift)
+		if at < 0 {
+			return "", fmt.Errorf("hunk %d (%s) of %s does not match the current file content; Read the file and regenerate the patch", n+1, h.header, p.Path())
+		}
+		shift = at - want
+		out = append(out, lines[pos:at]...)
+		out = append(out, newLines...)
+		pos = at + len(oldLines)
+		// A hunk reaching the end of the file states whether the file ends with a newline
+		if pos == len(lines) {
+			trailingNewline = !h.noNewline
+		}
+	}
+	out = append(out, lines[pos:]...)
+
+	result := strings.Join(out, "\n")
+	if trailingNewline && len(out) > 0 {
+		result += "\n"
+	}
+	return result, nil
+}
+
+// maxHunkOffset bounds how far from its stated line a hunk may apply, so short
+// or repetitive hunks do not land on an unrelated match far away
+const maxHunkOffset = 100
+
+// findHunk returns where oldLines occur in lines at or after from and within
+// maxHunkOffset lines of want, preferring the position closest to want, or -1
+// if they do not occur there
+func findHunk(lines, oldLines []string, from, want int) int {
+	matchesAt := func(at int) bool {
+		if at < from || at+len(oldLines) > len(lines) {
+			return false
+		}
+		for i, line := range oldLines {
+			if lines[at+i] != line {
+				return false
+			}
+		}
+		return true
+	}
+	for offset := 0; offset <= maxHunkOffset; offset++ {
+		if matchesAt(want + offset) {
+			return want + offset
+		}
+		if offset > 0 && matchesAt(want-offset) {
+			return want - offset
+		}
+	}
+	return -1
+}
+
+// splitPatchLines splits content into lines without their trailing newline
+func splitPatchLines(content string) []string {
+	if content == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+}
+
+// handleApplyPatch applies a multi-file unified diff. All files are checked and
+// patched in memory first so a mismatch in any hunk leaves the tree untouched.
+func (m *FileSystemToolManager) handleApplyPatch(ctx context.Context, args message.ToolArgumentValues) (message.ToolResult, error) {
+	patchText, ok := args["patch"].(string)
+	if !ok || strings.TrimSpace(patchText) == "" {
+		return message.NewToolResultError("patch parameter is required"), nil
+	}
+	patches, err := ParseUnifiedDiff(patchText)
+	if err != nil {
+		return message.NewToolResultError(fmt.Sprintf("invalid patch: %v", err)), nil
+	}
+
+	type change struct {
+		patch   FilePatch
+		absPath string
+		content string
+	}
+	var changes []change
+	seen := make(map[string]bool)
+	for _, p := range patches {
+		absPath, err := m.resolvePath(p.Path())
+		if err != nil {
+			return message.NewToolResultError(fmt.Sprintf("failed to resolve path: %v", err)), nil
+		}
+		if seen[absPath] {
+			return message.NewToolResultError(fmt.Sprintf("patch touches %s more than once; combine its hunks into one file section", absPath)), nil
+		}
+		seen[absPath] = true
+		if err := m.isPathAllowed(absPath); err != nil {
+			return message.NewToolResultError(err.Error()), nil
+		}
+		if err := m.isFileBlacklisted(absPath); err != nil {
+			return message.NewToolResultError(err.Error()), nil
+		}
+
+		current := ""
+		if p.IsCreate() {
+			if exists, _ := m.fsRepo.Exists(ctx, absPath); exists {
+				return message.NewToolResultError(fmt.Sprintf("patch creates %s but the file already exists", absPath)), nil
+			}
+		} else {
+			if err := m.validateReadWriteSemantics(ctx, absPath); err != nil {
+				return message.NewToolResultError(err.Error()), nil
+			}
+			data, err := m.fsRepo.ReadFile(ctx, absPath)
+			if err != nil {
+				return message.NewToolResultError(fmt.Sprintf("failed to read file %s: %v", absPath, err)), nil
+			}
+			current = string(data)
+		}
+
+		updated, err := p.Apply(current)
+		if err != nil {
+			return message.NewToolResultError(fmt.Sprintf("patch rejected, no files were changed: %v", err)), nil
+		}
+		if p.IsDelete() && updated != "" {
+			return message.NewToolResultError(fmt.Sprintf("patch rejected, no files were changed: deletion of %s does not remove all of its content", absPath)), nil
+		}
+		if err := m.checkRedactedWrite(ctx, absPath, updated); err != nil {
+			return message.NewToolResultError(fmt.Sprintf("patch rejected, no files were changed: %v", err)), nil
+		}
+		if p.IsDelete() && len(current) > MaxUndoSnapshotBytes {
+			return message.NewToolResultError(fmt.Sprintf("patch rejected, no files were changed: %s is larger than %d bytes, so undo_last_edit could not restore its deletion", absPath, MaxUndoSnapshotBytes)), nil
+		}
+		changes = append(changes, change{patch: p, absPath: absPath, content: updated})
+	}
+
+	var summary []string
+	var validation strings.Builder
+	for _, c := range changes {
+		added, removed := c.patch.LineCounts()
+		m.snapshotBeforeWrite(ctx, c.absPath, "apply_patch")
+		switch {
+		case c.patch.IsDelete():
+			if err := m.fsRepo.Remove(ctx, c.absPath); err != nil {
+				return message.NewToolResultError(fmt.Sprintf("failed to delete %s: %v (changes before it were applied: %s)", c.absPath, err, strings.Join(summary, ", "))), nil
+			}
+			summary = append(summary, fmt.Sprintf("D %s (-%d)", c.absPath, removed))
+			continue
+		case c.patch.IsCreate():
+			if err := os.MkdirAll(filepath.Dir(c.absPath), 0755); err != nil {
+				return message.NewToolResultError(fmt.Sprintf("failed to create directory: %v", err)), nil
+			}
+			summary = append(summary, fmt.Sprintf("A %s (+%d)", c.absPath, added))
+		default:
+			summary = append(summary, fmt.Sprintf("M %s (+%d -%d)", c.absPath, added, removed))
+		}
+		if err := m.fsRepo.WriteFile(ctx, c.absPath, []byte(c.content), m.existingFileMode(ctx, c.absPath, defaultFileMode)); err != nil {
+			return message.NewToolResultError(fmt.Sprintf("failed to write file %s: %v (changes before it were applied: %s)", c.absPath, err, strings.Join(summary[:len(summary)-1], ", "))), nil
+		}
+		// Update read timestamp after successful write to allow sequential edits
+		m.recordFileRead(c.absPath)
+		validation.WriteString(m.autoValidateFile(ctx, c.absPath))
+	}
+
+	return message.NewToolResultText(fmt.Sprintf("Applied patch to %d file(s):\n%s%s", len(changes), strings.Join(summary, "\n"), validation.String())), nil
+}
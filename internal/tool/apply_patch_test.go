@@ -0,0 +1,193 @@
+package tool
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fpt/go-gennai-cli/internal/infra"
+	"github.com/fpt/go-gennai-cli/internal/repository"
+	"github.com/fpt/go-gennai-cli/pkg/message"
+)
+
+func TestFilePatch_Apply(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		patch   string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "multiple hunks",
+			content: "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\n",
+			patch:   "--- a/x\n+++ b/x\n@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n@@ -8,3 +8,4 @@\n h\n i\n j\n+k\n",
+			want:    "a\nB\nc\nd\ne\nf\ng\nh\ni\nj\nk\n",
+		},
+		{
+			name:    "hunk found at shifted position",
+			content: "new\nfirst\nsecond\nthird\n",
+			patch:   "--- a/x\n+++ b/x\n@@ -1,2 +1,2 @@\n first\n-second\n+2nd\n",
+			want:    "new\nfirst\n2nd\nthird\n",
+		},
+		{
+			name:    "later hunk follows the shift of the one before",
+			content: strings.Repeat("pad\n", 80) + "a\nb\n" + strings.Repeat("pad\n", 90) + "c\nd\n",
+			patch:   "--- a/x\n+++ b/x\n@@ -1,2 +1,2 @@\n a\n-b\n+B\n@@ -13,2 +13,2 @@\n c\n-d\n+D\n",
+			want:    strings.Repeat("pad\n", 80) + "a\nB\n" + strings.Repeat("pad\n", 90) + "c\nD\n",
+		},
+		{
+			name:    "hunk too far from its stated line",
+			content: strings.Repeat("pad\n", maxHunkOffset+1) + "first\nsecond\n",
+			patch:   "--- a/x\n+++ b/x\n@@ -1,2 +1,2 @@\n first\n-second\n+2nd\n",
+			wantErr: true,
+		},
+		{
+			name:    "create file",
+			content: "",
+			patch:   "--- /dev/null\n+++ b/x\n@@ -0,0 +1,2 @@\n+package x\n+\n",
+			want:    "package x\n\n",
+		},
+		{
+			name:    "no newline at end of file",
+			content: "a\nb\n",
+			patch:   "--- a/x\n+++ b/x\n@@ -1,2 +1,2 @@\n a\n-b\n+c\n\\ No newline at end of file\n",
+			want:    "a\nc",
+		},
+		{
+			name:    "stripped empty context line",
+			content: "a\n\nb\n",
+			patch:   "--- a/x\n+++ b/x\n@@ -1,3 +1,3 @@\n a\n\n-b\n+c\n",
+			want:    "a\n\nc\n",
+		},
+		{
+			name:    "context mismatch",
+			content: "a\nb\nc\n",
+			patch:   "--- a/x\n+++ b/x\n@@ -1,2 +1,2 @@\n a\n-x\n+y\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patches, err := ParseUnifiedDiff(tt.patch)
+			if err != nil {
+				t.Fatalf("ParseUnifiedDiff: %v", err)
+			}
+			got, err := patches[0].Apply(tt.content)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected an error, got %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Apply: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseUnifiedDiff_Errors(t *testing.T) {
+	for name, patch := range map[string]string{
+		"not a diff":      "just some text",
+		"bad counts":      "--- a/x\n+++ b/x\n@@ -1,3 +1,3 @@\n a\n",
+		"rename":          "--- a/x\n+++ b/y\n@@ -1 +1 @@\n-a\n+b\n",
+		"missing +++":     "--- a/x\n@@ -1 +1 @@\n-a\n+b\n",
+		"no hunks":        "--- a/x\n+++ b/x\n",
+		"stray hunk line": "--- a/x\n+++ b/x\n@@ -1,2 +1,2 @@\n a\n*b\n",
+	} {
+		if _, err := ParseUnifiedDiff(patch); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestFileSystemToolManager_ApplyPatch(t *testing.T) {
+	ctx := context.Background()
+	tempDir := t.TempDir()
+	config := repository.FileSystemConfig{
+		AllowedDirectories: []string{tempDir},
+		BlacklistedFiles:   []string{".env"},
+	}
+	manager := NewFileSystemToolManager(infra.NewOSFilesystemRepository(), config, tempDir)
+
+	writeFile := func(name, content string) string {
+		path := filepath.Join(tempDir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	aPath := writeFile("a.txt", "one\ntwo\nthree\n")
+	bPath := writeFile("b.txt", "keep\n")
+	gonePath := writeFile("gone.txt", "bye\n")
+	writeFile(".env", "SECRET=1\n")
+
+	applyPatch := func(patch string) message.ToolResult {
+		res, err := manager.CallTool(ctx, "apply_patch", message.ToolArgumentValues{"patch": patch})
+		if err != nil {
+			t.Fatalf("CallTool: %v", err)
+		}
+		return res
+	}
+
+	// Modified files must have been read first
+	patch := "--- a/a.txt\n+++ b/a.txt\n@@ -1,3 +1,3 @@\n one\n-two\n+TWO\n three\n"
+	if res := applyPatch(patch); !strings.Contains(res.Error, "read-write semantics violation") {
+		t.Fatalf("expected read-before-write error, got %+v", res)
+	}
+	for _, path := range []string{aPath, bPath, gonePath} {
+		if res, _ := manager.CallTool(ctx, "Read", message.ToolArgumentValues{"file_path": path}); res.Error != "" {
+			t.Fatalf("Read %s: %s", path, res.Error)
+		}
+	}
+
+	// A mismatch in any file rejects the whole patch
+	bad := patch + "--- a/b.txt\n+++ b/b.txt\n@@ -1 +1 @@\n-other\n+changed\n"
+	if res := applyPatch(bad); !strings.Contains(res.Error, "no files were changed") {
+		t.Fatalf("expected rejection, got %+v", res)
+	}
+	if data, _ := os.ReadFile(aPath); string(data) != "one\ntwo\nthree\n" {
+		t.Errorf("a.txt was modified by a rejected patch: %q", data)
+	}
+
+	// Blacklisted files are refused
+	secret := "--- a/.env\n+++ b/.env\n@@ -1 +1 @@\n-SECRET=1\n+SECRET=2\n"
+	if res := applyPatch(secret); !strings.Contains(res.Error, "file access denied") {
+		t.Errorf("expected blacklist error, got %+v", res)
+	}
+
+	// Modify, create and delete in one patch
+	good := patch +
+		"--- /dev/null\n+++ b/sub/new.txt\n@@ -0,0 +1,2 @@\n+hello\n+world\n" +
+		"--- a/gone.txt\n+++ /dev/null\n@@ -1 +0,0 @@\n-bye\n"
+	res := applyPatch(good)
+	if res.Error != "" {
+		t.Fatalf("apply_patch failed: %s", res.Error)
+	}
+	for _, want := range []string{"Applied patch to 3 file(s)", "M " + aPath + " (+1 -1)", "A " + filepath.Join(tempDir, "sub", "new.txt") + " (+2)", "D " + gonePath + " (-1)"} {
+		if !strings.Contains(res.Text, want) {
+			t.Errorf("expected %q in result:\n%s", want, res.Text)
+		}
+	}
+	if data, _ := os.ReadFile(aPath); string(data) != "one\nTWO\nthree\n" {
+		t.Errorf("unexpected a.txt content: %q", data)
+	}
+	if data, _ := os.ReadFile(filepath.Join(tempDir, "sub", "new.txt")); string(data) != "hello\nworld\n" {
+		t.Errorf("unexpected new.txt content: %q", data)
+	}
+	if _, err := os.Stat(gonePath); !os.IsNotExist(err) {
+		t.Errorf("gone.txt should have been deleted")
+	}
+
+	// Creating a file that already exists is refused
+	exists := "--- /dev/null\n+++ b/b.txt\n@@ -0,0 +1 @@\n+dup\n"
+	if res := applyPatch(exists); !strings.Contains(res.Error, "already exists") {
+		t.Errorf("expected already-exists error, got %+v", res)
+	}
+}
@@ -0,0 +1,130 @@
+package tool
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+)
+
+// Command output kept for the tool result: the start and the end, with the
+// middle of very long output dropped so state isn't flooded
+const (
+	bashOutputHeadBytes = 16 * 1024
+	bashOutputTailBytes = 48 * 1024
+)
+
+// bashOutputLineBytes caps each streamed line, and with it the unterminated
+// line held back while a command prints without a newline
+const bashOutputLineBytes = 4 * 1024
+
+// outputCollector is the stdout/stderr writer of a bash command. It keeps the
+// head and tail of the output and passes each complete line to onLine as it
+// arrives.
+type outputCollector struct {
+	mu      sync.Mutex
+	head    []byte
+	tail    []byte
+	dropped int    // bytes dropped between head and tail
+	partial []byte // unterminated last line, not yet passed to onLine
+	cut     bool   // partial overran bashOutputLineBytes; drop until the next newline
+	onLine  func(line string)
+}
+
+func newOutputCollector(onLine func(line string)) *outputCollector {
+	return &outputCollector{onLine: onLine}
+}
+
+func (c *outputCollector) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	rest := p
+	if room := bashOutputHeadBytes - len(c.head); room > 0 {
+		n := min(room, len(rest))
+		c.head = append(c.head, rest[:n]...)
+		rest = rest[n:]
+	}
+	c.tail = append(c.tail, rest...)
+	// Trim in batches so long output isn't copied on every write
+	if excess := len(c.tail) - bashOutputTailBytes; excess > bashOutputTailBytes {
+		c.dropped += excess
+		c.tail = append(c.tail[:0], c.tail[excess:]...)
+	}
+
+	if c.onLine != nil {
+		c.streamLines(p)
+	}
+	return len(p), nil
+}
+
+// streamLines passes the complete lines in p to onLine. A line longer than
+// bashOutputLineBytes is passed as soon as it reaches the cap, cut there,
+// and the rest of it is dropped.
+func (c *outputCollector) streamLines(p []byte) {
+	for len(p) > 0 {
+		i := bytes.IndexByte(p, '\n')
+		if c.cut {
+			if i < 0 {
+				return
+			}
+			c.cut = false
+			p = p[i+1:]
+			continue
+		}
+		if i < 0 {
+			c.partial = append(c.partial, p...)
+			if len(c.partial) > bashOutputLineBytes {
+				c.onLine(truncateLine(string(c.partial), bashOutputLineBytes))
+				c.partial = nil
+				c.cut = true
+			}
+			return
+		}
+		line := append(c.partial, p[:i]...)
+		c.onLine(truncateLine(string(bytes.TrimRight(line, "\r")), bashOutputLineBytes))
+		c.partial = c.partial[:0]
+		p = p[i+1:]
+	}
+}
+
+// flush passes a final unterminated line to onLine
+func (c *outputCollector) flush() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.onLine != nil && len(c.partial) > 0 {
+		c.onLine(string(c.partial))
+	}
+	c.partial = nil
+	c.cut = false
+}
+
+// String returns the kept output, marking where the middle was dropped
+func (c *outputCollector) String() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	tail := c.tail
+	dropped := c.dropped
+	if excess := len(tail) - bashOutputTailBytes; excess > 0 {
+		dropped += excess
+		tail = tail[excess:]
+	}
+	if dropped == 0 {
+		return string(c.head) + string(tail)
+	}
+	return fmt.Sprintf("%s\n... [%d bytes of output omitted] ...\n%s", c.head, dropped, tail)
+}
+
+// SetOutputHandler sets a function that receives each line of bash output as
+// the command runs, for progressive display. nil disables streaming.
+func (m *BashToolManager) SetOutputHandler(handler func(line string)) {
+	m.outputMu.Lock()
+	defer m.outputMu.Unlock()
+	m.outputHandler = handler
+}
+
+func (m *BashToolManager) currentOutputHandler() func(line string) {
+	m.outputMu.Lock()
+	defer m.outputMu.Unlock()
+	return m.outputHandler
+}
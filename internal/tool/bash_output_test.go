@@ -0,0 +1,105 @@
+package tool
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fpt/go-gennai-cli/pkg/message"
+)
+
+func TestBashToolManager_StreamsOutput(t *testing.T) {
+	m := NewBashToolManager(BashConfig{WorkingDir: t.TempDir()})
+	var mu sync.Mutex
+	var lines []string
+	m.SetOutputHandler(func(line string) {
+		mu.Lock()
+		defer mu.Unlock()
+		lines = append(lines, line)
+	})
+
+	res, err := m.CallTool(context.Background(), "bash", message.ToolArgumentValues{
+		"command": "echo one; echo two >&2; printf three",
+	})
+	if err != nil || res.Error != "" {
+		t.Fatalf("bash failed: %v %s", err, res.Error)
+	}
+	if res.Text != "one\ntwo\nthree" {
+		t.Errorf("result = %q", res.Text)
+	}
+	if got := strings.Join(lines, "|"); got != "one|two|three" {
+		t.Errorf("streamed lines = %q, want one|two|three", got)
+	}
+
+	// Without a handler nothing is streamed
+	m.SetOutputHandler(nil)
+	if res, _ := m.CallTool(context.Background(), "bash", message.ToolArgumentValues{"command": "echo quiet"}); res.Text != "quiet\n" {
+		t.Errorf("result = %q", res.Text)
+	}
+	if len(lines) != 3 {
+		t.Errorf("expected no lines after clearing the handler, got %v", lines)
+	}
+}
+
+func TestBashToolManager_TimeoutKeepsPartialOutput(t *testing.T) {
+	m := NewBashToolManager(BashConfig{WorkingDir: t.TempDir()})
+	start := time.Now()
+	res, err := m.CallTool(context.Background(), "bash", message.ToolArgumentValues{
+		"command": "echo started; sleep 5; echo never",
+		"timeout": float64(300),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if time.Since(start) > 4*time.Second {
+		t.Errorf("timed-out command took %v", time.Since(start))
+	}
+	if !strings.Contains(res.Error, "timed out after 300ms") || !strings.HasSuffix(res.Error, "Partial output before the timeout:\nstarted\n") {
+		t.Errorf("expected timeout notice with only the partial output, got %q", res.Error)
+	}
+}
+
+func TestOutputCollector_KeepsHeadAndTail(t *testing.T) {
+	c := newOutputCollector(nil)
+	for i := 0; i < 20000; i++ {
+		fmt.Fprintf(c, "line %05d\n", i)
+	}
+	out := c.String()
+	if !strings.HasPrefix(out, "line 00000\n") || !strings.HasSuffix(out, "line 19999\n") {
+		t.Errorf("expected the first and last lines to be kept")
+	}
+	if !strings.Contains(out, "bytes of output omitted") {
+		t.Error("expected an omission marker")
+	}
+	if len(out) > bashOutputHeadBytes+bashOutputTailBytes+100 {
+		t.Errorf("kept %d bytes, want at most head+tail", len(out))
+	}
+}
+
+func TestOutputCollector_CapsStreamedLines(t *testing.T) {
+	var lines []string
+	c := newOutputCollector(func(line string) { lines = append(lines, line) })
+	long := strings.Repeat("x", bashOutputLineBytes*3)
+	// An unterminated line arriving in small writes is held back only up to the cap
+	for i := 0; i < len(long); i += 100 {
+		fmt.Fprint(c, long[i:min(i+100, len(long))])
+		if len(c.partial) > bashOutputLineBytes {
+			t.Fatalf("held %d bytes of an unterminated line", len(c.partial))
+		}
+	}
+	fmt.Fprint(c, "\nshort\n"+long+"\n")
+	c.flush()
+
+	if len(lines) != 3 {
+		t.Fatalf("streamed %d lines, want 3", len(lines))
+	}
+	if len(lines[0]) != bashOutputLineBytes || lines[1] != "short" || len(lines[2]) != bashOutputLineBytes {
+		t.Errorf("streamed line lengths %d, %q, %d", len(lines[0]), lines[1], len(lines[2]))
+	}
+	if !strings.HasSuffix(c.String(), long+"\n") {
+		t.Error("expected the tool result to keep the full tail")
+	}
+}
@@ -0,0 +1,235 @@
+package tool
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// regexRulePrefix marks a command rule as a regular expression matched against
+// the full command line (e.g. `re:curl .*\|\s*(ba)?sh`)
+const regexRulePrefix = "re:"
+
+// commandRule is a single allowed/denied command pattern. Plain patterns match
+// the leading tokens of a command: the first token as a glob, the rest exactly
+// (e.g. "git push" matches "git push origin main", "rm*" matches "rmdir x").
+type commandRule struct {
+	pattern string
+	tokens  []string
+	regex   *regexp.Regexp
+}
+
+// compileCommandRules parses rule patterns, skipping blank and invalid entries
+func compileCommandRules(patterns []string) []commandRule {
+	rules := make([]commandRule, 0, len(patterns))
+	for _, p := range patterns {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		if expr, ok := strings.CutPrefix(p, regexRulePrefix); ok {
+			re, err := regexp.Compile(expr)
+			if err != nil {
+				logger.Warn("Ignoring invalid bash command rule", "pattern", p, "error", err)
+				continue
+			}
+			rules = append(rules, commandRule{pattern: p, regex: re})
+			continue
+		}
+		rules = append(rules, commandRule{pattern: p, tokens: strings.Fields(p)})
+	}
+	return rules
+}
+
+// matches reports whether the rule matches the full command or one of its segments
+func (r commandRule) matches(command string, segment []string) bool {
+	if r.regex != nil {
+		return r.regex.MatchString(command)
+	}
+	if len(segment) < len(r.tokens) {
+		return false
+	}
+	if ok, _ := filepath.Match(r.tokens[0], segment[0]); !ok {
+		return false
+	}
+	for i := 1; i < len(r.tokens); i++ {
+		if r.tokens[i] != segment[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// commandSegmentSeparators split compound shell commands into individual commands
+var commandSegmentSeparators = regexp.MustCompile(`&&|\|\||[;|&\n]|\$\(|` + "`")
+
+// splitCommandSegments returns the tokens of each simple command in a compound command
+func splitCommandSegments(command string) [][]string {
+	// Redirections such as 2>&1 and &> are not command separators
+	command = strings.NewReplacer(">&", ">", "&>", ">").Replace(command)
+
+	var segments [][]string
+	for _, part := range commandSegmentSeparators.Split(command, -1) {
+		fields := strings.Fields(strings.Trim(part, " \t()"))
+		if len(fields) > 0 {
+			segments = append(segments, fields)
+		}
+	}
+	return segments
+}
+
+// checkCommandPolicy enforces the denied and allowed command rules. Denied rules
+// win; when allowed rules exist, every command segment must match one of them.
+// With no rules configured every command is permitted.
+func checkCommandPolicy(command string, allowed, denied []commandRule) error {
+	segments := splitCommandSegments(command)
+
+	for _, rule := range denied {
+		if rule.regex != nil {
+			if rule.matches(command, nil) {
+				return fmt.Errorf("command refused: matches denied_commands rule %q", rule.pattern)
+			}
+			continue
+		}
+		for _, segment := range segments {
+			if rule.matches(command, segment) {
+				return fmt.Errorf("command refused: %q matches denied_commands rule %q", strings.Join(segment, " "), rule.pattern)
+			}
+		}
+	}
+
+	if len(allowed) == 0 {
+		return nil
+	}
+	for _, rule := range allowed {
+		if rule.regex != nil && rule.matches(command, nil) {
+			return nil
+		}
+	}
+	for _, segment := range segments {
+		permitted := false
+		for _, rule := range allowed {
+			if rule.regex == nil && rule.matches(command, segment) {
+				permitted = true
+				break
+			}
+		}
+		if !permitted {
+			return fmt.Errorf("command refused: %q does not match any allowed_commands rule", strings.Join(segment, " "))
+		}
+	}
+	return nil
+}
+
+// readOnlyCommandRules permit commands that inspect but do not modify the workspace
+var readOnlyCommandRules = compileCommandRules([]string{
+	"ls", "pwd", "cat", "head", "tail", "wc", "grep", "rg", "find", "tree", "file", "stat",
+	"echo", "which", "diff", "sort", "uniq",
+	"git status", "git log", "git diff", "git show", "git branch", "git blame",
+	"go vet", "go list", "go doc", "go version", "go env",
+})
+
+// readOnlyDeniedRules reject flags that make otherwise read-only commands write
+var readOnlyDeniedRules = compileCommandRules([]string{
+	`re:\bfind\b.*\s-(delete|exec|execdir|ok|okdir|fls|fprint\w*)\b`,
+	`re:\bgo env\b.*\s-[wu]\b`,
+})
+
+// outputFlagCommands write their output to a file given with -o or --output
+var outputFlagCommands = map[string]bool{
+	"sort": true, "tree": true, "git diff": true, "git log": true, "git show": true,
+}
+
+// gitBranchListFlags are the git branch flags that only list branches
+var gitBranchListFlags = map[string]bool{
+	"-a": true, "--all": true, "-r": true, "--remotes": true, "-v": true, "-vv": true, "--verbose": true,
+	"-l": true, "--list": true, "--show-current": true, "--no-color": true, "--color": true,
+}
+
+// CheckReadOnlyCommand returns an error unless every segment of command is a
+// known read-only command (used when file changes must not reach disk)
+func CheckReadOnlyCommand(command string) error {
+	if strings.ContainsAny(command, "><") {
+		return fmt.Errorf("command refused: redirection is not allowed for read-only commands")
+	}
+	if err := checkCommandPolicy(command, readOnlyCommandRules, readOnlyDeniedRules); err != nil {
+		return err
+	}
+	for _, segment := range splitCommandSegments(command) {
+		if err := checkReadOnlyArguments(segment); err != nil {
+			return fmt.Errorf("command refused: %q %v", strings.Join(segment, " "), err)
+		}
+	}
+	return nil
+}
+
+// checkReadOnlyArguments rejects the arguments that make an allowed read-only
+// command write a file
+func checkReadOnlyArguments(segment []string) error {
+	name, args := segment[0], segment[1:]
+	if name == "git" && len(args) > 0 {
+		name, args = "git "+args[0], args[1:]
+	}
+
+	if outputFlagCommands[name] {
+		for _, arg := range args {
+			if isOutputFlag(arg) {
+				return fmt.Errorf("writes its output to a file (%s)", arg)
+			}
+		}
+	}
+
+	switch name {
+	case "uniq":
+		// uniq INPUT OUTPUT writes OUTPUT; -f, -s and -w take a count
+		operands := 0
+		for i := 0; i < len(args); i++ {
+			switch arg := args[i]; {
+			case arg == "-f" || arg == "-s" || arg == "-w":
+				i++
+			case !strings.HasPrefix(arg, "-") || arg == "-":
+				operands++
+			}
+		}
+		if operands > 1 {
+			return fmt.Errorf("writes its second operand")
+		}
+	case "git branch":
+		listing := slices.ContainsFunc(args, func(arg string) bool { return arg == "--list" || arg == "-l" })
+		for _, arg := range args {
+			if strings.HasPrefix(arg, "-") && !gitBranchListFlags[arg] && !isShortFlagCluster(arg, "arvl") {
+				return fmt.Errorf("changes branches (%s); only listing flags are allowed", arg)
+			}
+			if !strings.HasPrefix(arg, "-") && !listing {
+				return fmt.Errorf("creates a branch; only listing is allowed")
+			}
+		}
+	}
+	return nil
+}
+
+// isOutputFlag reports whether arg is -o in any short-flag form (-o, -oFILE,
+// -uo) or --output, including the abbreviations getopt and git accept
+func isOutputFlag(arg string) bool {
+	if long, ok := strings.CutPrefix(arg, "--"); ok {
+		name, _, _ := strings.Cut(long, "=")
+		return name != "" && strings.HasPrefix("output", name)
+	}
+	return strings.HasPrefix(arg, "-") && strings.Contains(arg[1:], "o")
+}
+
+// isShortFlagCluster reports whether arg combines only the given short flags (e.g. -av)
+func isShortFlagCluster(arg, letters string) bool {
+	flags, ok := strings.CutPrefix(arg, "-")
+	if !ok || flags == "" || strings.HasPrefix(flags, "-") {
+		return false
+	}
+	for _, r := range flags {
+		if !strings.ContainsRune(letters, r) {
+			return false
+		}
+	}
+	return true
+}
@@ -6,6 +6,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	pkgLogger "github.com/fpt/go-gennai-cli/pkg/logger"
@@ -18,6 +19,12 @@ type BashToolManager struct {
 	workingDir          string
 	maxDuration         time.Duration
 	whitelistedCommands []string // Commands that don't require approval
+	allowedCommands     []commandRule
+	deniedCommands      []commandRule
+
+	// Receives output lines while a command runs (see SetOutputHandler)
+	outputHandler func(line string)
+	outputMu      sync.Mutex
 }
 
 // BashConfig holds configuration for the bash tool manager
@@ -25,6 +32,8 @@ type BashConfig struct {
 	WorkingDir          string        `json:"working_dir"`          // Working directory for commands
 	MaxDuration         time.Duration `json:"max_duration"`         // Maximum execution time (default: 2 minutes)
 	WhitelistedCommands []string      `json:"whitelisted_commands"` // Commands that don't require approval
+	AllowedCommands     []string      `json:"allowed_commands"`     // If set, only matching commands may run
+	DeniedCommands      []string      `json:"denied_commands"`      // Matching commands are always refused
 }
 
 // NewBashToolManager creates a new bash tool manager
@@ -38,6 +47,8 @@ func NewBashToolManager(config BashConfig) *BashToolManager {
 		workingDir:          config.WorkingDir,
 		maxDuration:         config.MaxDuration,
 		whitelistedCommands: config.WhitelistedCommands,
+		allowedCommands:     compileCommandRules(config.AllowedCommands),
+		deniedCommands:      compileCommandRules(config.DeniedCommands),
 	}
 
 	// Register bash tools
@@ -66,12 +77,19 @@ func (m *BashToolManager) CallTool(ctx context.Context, name message.ToolName, a
 	return handler(ctx, args)
 }
 
+// RegisterTool registers a tool that declares no effects (message.AccessUnknown)
 func (m *BashToolManager) RegisterTool(name message.ToolName, description message.ToolDescription, args []message.ToolArgument, handler func(ctx context.Context, args message.ToolArgumentValues) (message.ToolResult, error)) {
+	m.registerTool(name, message.ToolEffects{}, description, args, handler)
+}
+
+// registerTool registers a built-in tool with the effects it declares
+func (m *BashToolManager) registerTool(name message.ToolName, effects message.ToolEffects, description message.ToolDescription, args []message.ToolArgument, handler func(ctx context.Context, args message.ToolArgumentValues) (message.ToolResult, error)) {
 	tool := &bashTool{
 		name:        name,
 		description: description,
 		arguments:   args,
 		handler:     handler,
+		effects:     effects,
 	}
 	m.tools[name] = tool
 }
@@ -79,7 +97,7 @@ func (m *BashToolManager) RegisterTool(name message.ToolName, description messag
 // registerBashTools registers all bash command tools
 func (m *BashToolManager) registerBashTools() {
 	// Primary Bash tool
-	m.RegisterTool("bash", "Execute shell commands with timeout and error handling. Prefer tools over shell for file reads/search (use Read/Glob/Grep/LS). Provide a short description; quote paths with spaces.",
+	m.registerTool("bash", message.AnyChange, "Execute shell commands with timeout and error handling. Prefer tools over shell for file reads/search (use Read/Glob/Grep/LS). Provide a short description; quote paths with spaces.",
 		[]message.ToolArgument{
 			{
 				Name:        "command",
@@ -103,7 +121,7 @@ func (m *BashToolManager) registerBashTools() {
 		m.handleBash)
 
 	// Legacy compatibility - go_build
-	m.RegisterTool("go_build", "Build Go packages (legacy compatibility)",
+	m.registerTool("go_build", message.AnyChange, "Build Go packages (legacy compatibility)",
 		[]message.ToolArgument{
 			{
 				Name:        "package_path",
@@ -115,7 +133,7 @@ func (m *BashToolManager) registerBashTools() {
 		m.handleGoBuild)
 
 	// Legacy compatibility - go_run
-	m.RegisterTool("go_run", "Run Go programs (legacy compatibility)",
+	m.registerTool("go_run", message.AnyChange, "Run Go programs (legacy compatibility)",
 		[]message.ToolArgument{
 			{
 				Name:        "package_path",
@@ -132,7 +150,35 @@ func (m *BashToolManager) registerBashTools() {
 		},
 		m.handleGoRun)
 
+	// Structured Go test runner
+	m.registerTool("run_tests", message.AnyChange, "Run Go tests (go test -json) and report pass/fail/skip counts with the output of failing tests and build errors. Prefer this over bash for running Go tests after edits.",
+		[]message.ToolArgument{
+			{
+				Name:        "path",
+				Description: "Package pattern or directory to test (default: ./...)",
+				Required:    false,
+				Type:        "string",
+			},
+			{
+				Name:        "run",
+				Description: "Only run tests matching this regular expression (go test -run)",
+				Required:    false,
+				Type:        "string",
+			},
+			{
+				Name:        "timeout",
+				Description: "Optional timeout in milliseconds (max 600000ms / 10 minutes)",
+				Required:    false,
+				Type:        "number",
+			},
+		},
+		m.handleRunTests)
+
 	// Note: dedicated Grep tool is provided by SearchToolManager; avoid duplicating here.
+
+	// bash and run_tests enforce their own timeouts, which the tool budget must not cut short
+	m.tools["bash"].(*bashTool).timeout = m.commandTimeout
+	m.tools["run_tests"].(*bashTool).timeout = m.testTimeout
 }
 
 // resolvePath resolves a path relative to the working directory
@@ -183,6 +229,23 @@ func (m *BashToolManager) RequiresApproval(command string) bool {
 	return !m.IsCommandWhitelisted(command)
 }
 
+// commandTimeout returns the timeout of a bash call: the timeout argument, or
+// the manager's maxDuration
+func (m *BashToolManager) commandTimeout(args message.ToolArgumentValues) time.Duration {
+	timeout := m.maxDuration
+	if timeoutArg, ok := args["timeout"]; ok {
+		if timeoutMs, ok := timeoutArg.(float64); ok {
+			// Convert milliseconds to duration, with max limit
+			timeoutDuration := time.Duration(timeoutMs) * time.Millisecond
+			if timeoutDuration > 10*time.Minute {
+				timeoutDuration = 10 * time.Minute // Cap at 10 minutes
+			}
+			timeout = timeoutDuration
+		}
+	}
+	return timeout
+}
+
 // Main Bash handler
 func (m *BashToolManager) handleBash(ctx context.Context, args message.ToolArgumentValues) (message.ToolResult, error) {
 	command, ok := args["command"].(string)
@@ -196,24 +259,19 @@ func (m *BashToolManager) handleBash(ctx context.Context, args message.ToolArgum
 		description = desc
 	}
 
-	// Get optional timeout (default to manager's maxDuration)
-	timeout := m.maxDuration
-	if timeoutArg, ok := args["timeout"]; ok {
-		if timeoutMs, ok := timeoutArg.(float64); ok {
-			// Convert milliseconds to duration, with max limit
-			timeoutDuration := time.Duration(timeoutMs) * time.Millisecond
-			if timeoutDuration > 10*time.Minute {
-				timeoutDuration = 10 * time.Minute // Cap at 10 minutes
-			}
-			timeout = timeoutDuration
-		}
-	}
+	timeout := m.commandTimeout(args)
 
 	// Security validation - prevent dangerous commands
 	if err := m.validateCommand(command); err != nil {
 		return message.NewToolResultError(err.Error()), nil
 	}
 
+	// Configured allow/deny policy
+	if err := checkCommandPolicy(command, m.allowedCommands, m.deniedCommands); err != nil {
+		logger.WarnWithIntention(pkgLogger.IntentionWarning, "Bash command refused by policy", "command", command, "reason", err)
+		return message.NewToolResultError(err.Error()), nil
+	}
+
 	// Create context with timeout
 	cmdCtx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
@@ -311,6 +369,9 @@ func (m *BashToolManager) validateCommand(command string) error {
 	return nil
 }
 
+// bashWaitDelay bounds how long output is awaited after a command is killed
+const bashWaitDelay = 2 * time.Second
+
 // executeCommand executes a shell command and returns the output
 func (m *BashToolManager) executeCommand(ctx context.Context, command, description string) (string, error) {
 	// Log command execution
@@ -328,13 +389,27 @@ func (m *BashToolManager) executeCommand(ctx context.Context, command, descripti
 		cmd.Dir = m.workingDir
 	}
 
-	// Capture both stdout and stderr
-	output, err := cmd.CombinedOutput()
-	outputStr := string(output)
+	// Capture both stdout and stderr, streaming lines as they arrive
+	output := newOutputCollector(m.currentOutputHandler())
+	cmd.Stdout = output
+	cmd.Stderr = output
+	// Background processes holding the output open must not outlive the timeout
+	cmd.WaitDelay = bashWaitDelay
+
+	var limit time.Duration
+	if deadline, ok := ctx.Deadline(); ok {
+		limit = time.Until(deadline).Round(time.Millisecond)
+	}
+	err := cmd.Run()
+	output.flush()
+	outputStr := output.String()
 
 	// Handle different exit scenarios
 	if ctx.Err() == context.DeadlineExceeded {
-		return "", fmt.Errorf("command timed out after %v: %s", m.maxDuration, command)
+		if outputStr == "" {
+			return "", fmt.Errorf("command timed out after %v with no output: %s", limit, command)
+		}
+		return "", fmt.Errorf("command timed out after %v: %s\nPartial output before the timeout:\n%s", limit, command, outputStr)
 	}
 
 	if err != nil {
@@ -444,6 +519,8 @@ type bashTool struct {
 	description message.ToolDescription
 	arguments   []message.ToolArgument
 	handler     func(ctx context.Context, args message.ToolArgumentValues) (message.ToolResult, error)
+	effects     message.ToolEffects
+	timeout     func(args message.ToolArgumentValues) time.Duration // Set for tools that enforce their own timeout
 }
 
 func (t *bashTool) RawName() message.ToolName {
@@ -465,3 +542,14 @@ func (t *bashTool) Arguments() []message.ToolArgument {
 func (t *bashTool) Handler() func(ctx context.Context, args message.ToolArgumentValues) (message.ToolResult, error) {
 	return t.handler
 }
+
+func (t *bashTool) Effects() message.ToolEffects {
+	return t.effects
+}
+
+func (t *bashTool) ownTimeout(args message.ToolArgumentValues) (time.Duration, bool) {
+	if t.timeout == nil {
+		return 0, false
+	}
+	return t.timeout(args), true
+}
@@ -0,0 +1,81 @@
+package tool
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+
+	"github.com/fpt/go-gennai-cli/pkg/message"
+)
+
+const (
+	defaultBinaryPreviewBytes = 256
+	maxBinaryPreviewBytes     = 4096
+
+	// maxReadImageBytes keeps image reads under the providers' per-image limits
+	maxReadImageBytes = 5 * 1024 * 1024
+)
+
+// visionImageTypes are the image formats every vision-capable provider accepts
+var visionImageTypes = map[string]bool{
+	"image/png":  true,
+	"image/jpeg": true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// SetReadImages controls whether Read returns image files as images. Enable
+// it only when the current model supports vision; otherwise images are
+// summarized like any other binary file.
+func (m *FileSystemToolManager) SetReadImages(enabled bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.readImages = enabled
+}
+
+func (m *FileSystemToolManager) imageReadsEnabled() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.readImages
+}
+
+// binaryReadResult returns the Read result for an image or binary file, or
+// false when content should be shown as text. Binary files are summarized
+// rather than dumped into the context, with an optional hex or base64 preview
+// of their first bytes.
+func (m *FileSystemToolManager) binaryReadResult(path string, content []byte, args message.ToolArgumentValues) (message.ToolResult, bool) {
+	contentType := http.DetectContentType(content)
+	if visionImageTypes[contentType] && m.imageReadsEnabled() {
+		if len(content) <= maxReadImageBytes {
+			text := fmt.Sprintf("Image file %s (%s, %d bytes) is attached.", path, contentType, len(content))
+			return message.NewToolResultWithImages(text, []string{base64.StdEncoding.EncodeToString(content)}), true
+		}
+	} else if !isBinaryContent(content) && !mostlyInvalidUTF8(content) {
+		return message.ToolResult{}, false
+	}
+
+	summary := fmt.Sprintf("Binary file %s: %d bytes, type %s. Its content is not shown as text.", path, len(content), contentType)
+	if visionImageTypes[contentType] && len(content) > maxReadImageBytes {
+		summary += fmt.Sprintf(" The image is over the %d byte limit for image reads.", maxReadImageBytes)
+	}
+
+	previewBytes := defaultBinaryPreviewBytes
+	if v, ok := args["preview_bytes"].(float64); ok && v > 0 {
+		previewBytes = min(int(v), maxBinaryPreviewBytes)
+	}
+	head := content[:min(len(content), previewBytes)]
+
+	preview, _ := args["preview"].(string)
+	switch preview {
+	case "":
+		summary += " Pass preview=\"hex\" or preview=\"base64\" to see its first bytes."
+	case "hex":
+		summary += fmt.Sprintf("\n\nFirst %d bytes (hex):\n%s", len(head), hex.Dump(head))
+	case "base64":
+		summary += fmt.Sprintf("\n\nFirst %d bytes (base64):\n%s\n", len(head), base64.StdEncoding.EncodeToString(head))
+	default:
+		return message.NewToolResultError(fmt.Sprintf("invalid preview '%s', must be hex or base64", preview)), true
+	}
+	return message.NewToolResultText(summary), true
+}
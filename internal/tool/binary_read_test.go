@@ -0,0 +1,139 @@
+package tool
+
+import (
+	"context"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fpt/go-gennai-cli/internal/infra"
+	"github.com/fpt/go-gennai-cli/internal/repository"
+	"github.com/fpt/go-gennai-cli/pkg/message"
+)
+
+// pngHeader is the start of a PNG file, enough for content sniffing
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00\x00\x01\x00\x00\x00\x01\x08\x02\x00\x00\x00")
+
+func TestIsBinaryContent(t *testing.T) {
+	tests := []struct {
+		name    string
+		content []byte
+		want    bool
+	}{
+		{"empty", nil, false},
+		{"ascii", []byte("package main\n"), false},
+		{"utf8", []byte("こんにちは、世界\n"), false},
+		{"nul byte", []byte("abc\x00def"), true},
+		// Legacy encodings stay searchable and editable
+		{"mostly invalid utf8", []byte{0xff, 0xfe, 0xfd, 'a', 0x80, 0x81}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isBinaryContent(tt.content); got != tt.want {
+				t.Errorf("isBinaryContent() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMostlyInvalidUTF8(t *testing.T) {
+	tests := []struct {
+		name    string
+		content []byte
+		want    bool
+	}{
+		{"utf8", []byte("こんにちは、世界\n"), false},
+		{"mostly invalid utf8", []byte{0xff, 0xfe, 0xfd, 'a', 0x80, 0x81}, true},
+		{"one latin1 byte", []byte("caf\xe9 au lait, the usual order"), false},
+		// A character split by the 8KB sample boundary is not counted
+		{"split at sample end", append([]byte(strings.Repeat("a", 8191)), "é"...), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mostlyInvalidUTF8(tt.content); got != tt.want {
+				t.Errorf("mostlyInvalidUTF8() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRead_BinaryFiles(t *testing.T) {
+	dir := t.TempDir()
+	blob := filepath.Join(dir, "data.bin")
+	if err := os.WriteFile(blob, []byte("\x00\x01\x02\x03binary"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	image := filepath.Join(dir, "logo.png")
+	if err := os.WriteFile(image, pngHeader, 0644); err != nil {
+		t.Fatal(err)
+	}
+	manager := NewFileSystemToolManager(infra.NewOSFilesystemRepository(), repository.FileSystemConfig{}, dir)
+	ctx := context.Background()
+
+	read := func(args message.ToolArgumentValues) message.ToolResult {
+		t.Helper()
+		result, err := manager.CallTool(ctx, "Read", args)
+		if err != nil {
+			t.Fatalf("Read: %v", err)
+		}
+		return result
+	}
+
+	t.Run("Summary", func(t *testing.T) {
+		result := read(message.ToolArgumentValues{"file_path": blob})
+		if !strings.Contains(result.Text, "Binary file") || !strings.Contains(result.Text, "10 bytes") {
+			t.Errorf("expected a binary summary, got %q", result.Text)
+		}
+		if strings.Contains(result.Text, "\x01\x02") {
+			t.Errorf("expected raw bytes to be withheld, got %q", result.Text)
+		}
+	})
+
+	t.Run("HexPreview", func(t *testing.T) {
+		result := read(message.ToolArgumentValues{"file_path": blob, "preview": "hex", "preview_bytes": float64(4)})
+		if !strings.Contains(result.Text, "First 4 bytes (hex)") || !strings.Contains(result.Text, "00 01 02 03") {
+			t.Errorf("expected a 4-byte hex preview, got %q", result.Text)
+		}
+	})
+
+	t.Run("Base64Preview", func(t *testing.T) {
+		result := read(message.ToolArgumentValues{"file_path": blob, "preview": "base64"})
+		if !strings.Contains(result.Text, base64.StdEncoding.EncodeToString([]byte("\x00\x01\x02\x03binary"))) {
+			t.Errorf("expected a base64 preview, got %q", result.Text)
+		}
+	})
+
+	t.Run("InvalidPreview", func(t *testing.T) {
+		if result := read(message.ToolArgumentValues{"file_path": blob, "preview": "octal"}); result.Error == "" {
+			t.Error("expected an unknown preview format to be rejected")
+		}
+	})
+
+	t.Run("ImageWithoutVision", func(t *testing.T) {
+		result := read(message.ToolArgumentValues{"file_path": image})
+		if len(result.Images) != 0 || !strings.Contains(result.Text, "image/png") {
+			t.Errorf("expected a text summary naming the image type, got %+v", result)
+		}
+	})
+
+	t.Run("ImageWithVision", func(t *testing.T) {
+		manager.SetReadImages(true)
+		defer manager.SetReadImages(false)
+		result := read(message.ToolArgumentValues{"file_path": image})
+		if len(result.Images) != 1 || result.Images[0] != base64.StdEncoding.EncodeToString(pngHeader) {
+			t.Fatalf("expected the image to be attached, got %+v", result)
+		}
+	})
+
+	t.Run("TextUnchanged", func(t *testing.T) {
+		text := filepath.Join(dir, "notes.txt")
+		if err := os.WriteFile(text, []byte("hello"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if result := read(message.ToolArgumentValues{"file_path": text}); !strings.Contains(result.Text, "1\thello") {
+			t.Errorf("expected numbered text output, got %q", result.Text)
+		}
+	})
+}
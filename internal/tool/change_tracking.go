@@ -0,0 +1,76 @@
+package tool
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// TrackChanges starts recording the original state of every file the file
+// tools change, so ChangesDiff can show what a task did. It replaces any
+// earlier tracking.
+func (m *FileSystemToolManager) TrackChanges() {
+	m.undoMu.Lock()
+	defer m.undoMu.Unlock()
+	m.changeOrigins = make(map[string]editSnapshot)
+	m.changeOrder = nil
+}
+
+// trackChange keeps snap as the original state of its file unless the file
+// already changed since tracking began. Called with undoMu held.
+func (m *FileSystemToolManager) trackChange(snap editSnapshot) {
+	if m.changeOrigins == nil {
+		return
+	}
+	if _, seen := m.changeOrigins[snap.path]; seen {
+		return
+	}
+	m.changeOrigins[snap.path] = snap
+	m.changeOrder = append(m.changeOrder, snap.path)
+}
+
+// ChangesDiff returns a unified diff of the files changed since TrackChanges,
+// from their original content to what is on disk now, and how many files
+// differ. Changes made outside the file tools (e.g. through bash) are not seen.
+func (m *FileSystemToolManager) ChangesDiff(ctx context.Context) (string, int) {
+	m.undoMu.Lock()
+	origins := make([]editSnapshot, 0, len(m.changeOrder))
+	for _, path := range m.changeOrder {
+		origins = append(origins, m.changeOrigins[path])
+	}
+	m.undoMu.Unlock()
+
+	var b strings.Builder
+	changed := 0
+	for _, snap := range origins {
+		if snap.dir {
+			continue
+		}
+		name := snap.path
+		if rel, err := filepath.Rel(m.workingDir, snap.path); err == nil && !strings.HasPrefix(rel, "..") {
+			name = filepath.ToSlash(rel)
+		}
+		current := ""
+		if content, err := m.fsRepo.ReadFile(ctx, snap.path); err == nil {
+			current = string(content)
+		}
+		if snap.tooLarge {
+			fmt.Fprintf(&b, "(%s changed but is larger than %d bytes; not shown)\n", name, MaxUndoSnapshotBytes)
+			changed++
+			continue
+		}
+		if isBinaryContent(snap.content) || isBinaryContent([]byte(current)) {
+			if string(snap.content) != current {
+				fmt.Fprintf(&b, "(binary file %s changed; not shown)\n", name)
+				changed++
+			}
+			continue
+		}
+		if diff := UnifiedDiff(name, string(snap.content), current); diff != "" {
+			b.WriteString(diff)
+			changed++
+		}
+	}
+	return b.String(), changed
+}
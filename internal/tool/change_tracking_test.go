@@ -0,0 +1,52 @@
+package tool
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fpt/go-gennai-cli/internal/infra"
+	"github.com/fpt/go-gennai-cli/pkg/message"
+)
+
+func TestFileSystemToolManager_ChangesDiff(t *testing.T) {
+	dir := t.TempDir()
+	manager := NewFileSystemToolManager(infra.NewOSFilesystemRepository(), infra.DefaultFileSystemConfig(dir), dir)
+	ctx := context.Background()
+	call := func(name message.ToolName, args message.ToolArgumentValues) {
+		t.Helper()
+		if res, err := manager.CallTool(ctx, name, args); err != nil || res.Error != "" {
+			t.Fatalf("%s failed: %v %s", name, err, res.Error)
+		}
+	}
+	main := filepath.Join(dir, "main.txt")
+	if err := os.WriteFile(main, []byte("one\ntwo\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Changes before tracking starts are not reported
+	call("Write", message.ToolArgumentValues{"file_path": "early.txt", "content": "early\n"})
+	manager.TrackChanges()
+	if diff, n := manager.ChangesDiff(ctx); diff != "" || n != 0 {
+		t.Fatalf("expected no changes right after TrackChanges, got %d:\n%s", n, diff)
+	}
+
+	call("Read", message.ToolArgumentValues{"file_path": main})
+	call("Edit", message.ToolArgumentValues{"file_path": main, "old_string": "one", "new_string": "ONE"})
+	call("Edit", message.ToolArgumentValues{"file_path": main, "old_string": "two", "new_string": "TWO"})
+	call("Write", message.ToolArgumentValues{"file_path": "new.txt", "content": "created\n"})
+	call("Write", message.ToolArgumentValues{"file_path": "tmp.txt", "content": "scratch\n"})
+	call("undo_last_edit", message.ToolArgumentValues{})
+
+	diff, n := manager.ChangesDiff(ctx)
+	want := "--- a/main.txt\n+++ b/main.txt\n@@ -1,2 +1,2 @@\n-one\n-two\n+ONE\n+TWO\n" +
+		"--- /dev/null\n+++ b/new.txt\n@@ -0,0 +1,1 @@\n+created\n"
+	if n != 2 || diff != want {
+		t.Errorf("unexpected changes (%d files):\n%s\nwant:\n%s", n, diff, want)
+	}
+	if strings.Contains(diff, "early.txt") || strings.Contains(diff, "tmp.txt") {
+		t.Errorf("expected untracked and undone files to be left out:\n%s", diff)
+	}
+}
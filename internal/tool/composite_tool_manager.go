@@ -12,6 +12,8 @@ import (
 type CompositeToolManager struct {
 	managers []domain.ToolManager
 	toolsMap map[message.ToolName]message.Tool
+	timeouts ToolTimeoutConfig
+	cache    *resultCache // nil = results are not memoized
 }
 
 // NewCompositeToolManager creates a new composite tool manager from multiple managers
@@ -50,8 +52,35 @@ func (c *CompositeToolManager) CallTool(ctx context.Context, name message.ToolNa
 		return message.NewToolResultError(fmt.Sprintf("tool %s not found", name)), nil
 	}
 
-	handler := tool.Handler()
-	return handler(ctx, args)
+	if c.cache == nil {
+		return callWithTimeout(ctx, c.timeouts, name, tool, args)
+	}
+	effects := message.EffectsOf(tool)
+	key, cacheable := c.cache.key(name, effects, args)
+	if !cacheable {
+		defer c.cache.invalidate(effects, args)
+		return callWithTimeout(ctx, c.timeouts, name, tool, args)
+	}
+	if result, hit := c.cache.get(key); hit {
+		return result, nil
+	}
+	result, err := callWithTimeout(ctx, c.timeouts, name, tool, args)
+	if err == nil && result.Error == "" {
+		c.cache.put(key, effects, args, result)
+	}
+	return result, err
+}
+
+// EnableResultCache memoizes the results of deterministic read-only tools for
+// the lifetime of this manager. Relative paths in tool arguments are resolved
+// against workingDir to match writes with the results they make stale.
+func (c *CompositeToolManager) EnableResultCache(workingDir string) {
+	c.cache = newResultCache(workingDir)
+}
+
+// SetToolTimeouts configures the per-tool execution budgets applied by CallTool
+func (c *CompositeToolManager) SetToolTimeouts(timeouts ToolTimeoutConfig) {
+	c.timeouts = timeouts
 }
 
 // RegisterTool is not supported on composite managers since tools should be registered on the underlying managers
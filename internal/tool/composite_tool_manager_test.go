@@ -0,0 +1,164 @@
+package tool
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fpt/go-gennai-cli/pkg/message"
+)
+
+func TestCompositeToolManager_ToolTimeouts(t *testing.T) {
+	web := NewWebToolManager().(*WebToolManager)
+	web.RegisterTool("slow", "Blocks until cancelled", nil, func(ctx context.Context, args message.ToolArgumentValues) (message.ToolResult, error) {
+		<-ctx.Done()
+		return message.ToolResult{}, ctx.Err()
+	})
+	web.registerTool("stuck", message.UncachedRead, "Ignores its context", nil, func(ctx context.Context, args message.ToolArgumentValues) (message.ToolResult, error) {
+		time.Sleep(time.Second)
+		return message.NewToolResultText("late"), nil
+	})
+	web.RegisterTool("stubborn", "Ignores its context but may change files", nil, func(ctx context.Context, args message.ToolArgumentValues) (message.ToolResult, error) {
+		time.Sleep(100 * time.Millisecond)
+		return message.NewToolResultText("finished"), nil
+	})
+	web.registerTool("write", message.FileWrite, "Writes slowly", nil, func(ctx context.Context, args message.ToolArgumentValues) (message.ToolResult, error) {
+		time.Sleep(100 * time.Millisecond)
+		if ctx.Err() != nil {
+			return message.NewToolResultError("write cancelled"), nil
+		}
+		return message.NewToolResultText("written"), nil
+	})
+	web.RegisterTool("fast", "Returns immediately", nil, func(ctx context.Context, args message.ToolArgumentValues) (message.ToolResult, error) {
+		return message.NewToolResultText("ok"), nil
+	})
+	bash := NewBashToolManager(BashConfig{WorkingDir: t.TempDir(), MaxDuration: 2 * time.Second})
+
+	composite := NewCompositeToolManager(web, bash)
+	short := 20 * time.Millisecond
+	composite.SetToolTimeouts(ToolTimeoutConfig{
+		Default: time.Minute,
+		PerTool: map[message.ToolName]time.Duration{"slow": short, "stuck": short, "stubborn": short, "write": short, "bash": short},
+	})
+	ctx := context.Background()
+
+	for _, name := range []message.ToolName{"slow", "stuck"} {
+		start := time.Now()
+		result, err := composite.CallTool(ctx, name, nil)
+		if err != nil {
+			t.Fatalf("%s: expected timeout as a tool result, got error %v", name, err)
+		}
+		if !strings.Contains(result.Error, "exceeded its time budget") {
+			t.Errorf("%s: expected time budget error, got %+v", name, result)
+		}
+		if time.Since(start) > 500*time.Millisecond {
+			t.Errorf("%s: expected call to return at the deadline, took %s", name, time.Since(start))
+		}
+	}
+
+	// Tools that may change files are awaited, and write tools are never cancelled
+	if result, err := composite.CallTool(ctx, "stubborn", nil); err != nil || result.Text != "finished" {
+		t.Errorf("expected stubborn tool to be awaited, got %+v %v", result, err)
+	}
+	if result, err := composite.CallTool(ctx, "write", nil); err != nil || result.Text != "written" {
+		t.Errorf("expected write tool to finish uncancelled, got %+v %v", result, err)
+	}
+
+	// bash gets at least its own timeout
+	if result, err := composite.CallTool(ctx, "bash", message.ToolArgumentValues{"command": "sleep 0.1 && echo done"}); err != nil || !strings.Contains(result.Text, "done") {
+		t.Errorf("expected bash to run within its own timeout, got %+v %v", result, err)
+	}
+
+	if result, err := composite.CallTool(ctx, "fast", nil); err != nil || result.Text != "ok" {
+		t.Errorf("expected fast tool to succeed, got %+v %v", result, err)
+	}
+
+	// Cancellation by the caller is not reported as a timeout
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := composite.CallTool(cancelled, "slow", nil); err == nil {
+		t.Error("expected caller cancellation to be returned as an error")
+	}
+}
+
+func TestCompositeToolManager_ResultCache(t *testing.T) {
+	calls := map[message.ToolName]int{}
+	manager := NewWebToolManager().(*WebToolManager)
+	register := func(name message.ToolName, effects message.ToolEffects) {
+		manager.registerTool(name, effects, "Counts its calls", nil, func(ctx context.Context, args message.ToolArgumentValues) (message.ToolResult, error) {
+			calls[name]++
+			return message.NewToolResultText(fmt.Sprintf("%s #%d", name, calls[name])), nil
+		})
+	}
+	register("Read", message.CachedFileRead)
+	register("Glob", message.CachedSearch)
+	register("Write", message.FileWrite)
+	register("MultiEdit", message.WorkspaceWrite)
+	register("WebFetch", message.UncachedRead)
+	register("todo_write", message.AgentStateChange)
+	register("bash", message.AnyChange)
+	// Tools registered without effects, such as MCP tools, may change anything
+	manager.RegisterTool("mcp_tool", "Declares no effects", nil, func(ctx context.Context, args message.ToolArgumentValues) (message.ToolResult, error) {
+		return message.NewToolResultText("ok"), nil
+	})
+
+	composite := NewCompositeToolManager(manager)
+	composite.EnableResultCache("/work")
+	ctx := context.Background()
+	call := func(name message.ToolName, args message.ToolArgumentValues) string {
+		t.Helper()
+		result, err := composite.CallTool(ctx, name, args)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		return result.Text
+	}
+	expect := func(name message.ToolName, args message.ToolArgumentValues, want string) {
+		t.Helper()
+		if got := call(name, args); got != want {
+			t.Errorf("%s %v: expected %q, got %q", name, args, want, got)
+		}
+	}
+	readA := message.ToolArgumentValues{"file_path": "a.go"}
+	readB := message.ToolArgumentValues{"file_path": "/work/b.go"}
+	glob := message.ToolArgumentValues{"pattern": "*.go"}
+
+	expect("Read", readA, "Read #1")
+	expect("Read", message.ToolArgumentValues{"file_path": "/work/a.go"}, "Read #2") // different arguments
+	expect("Read", readA, "Read #1")
+	expect("Read", readB, "Read #3")
+	expect("Glob", glob, "Glob #1")
+	expect("Glob", glob, "Glob #1")
+
+	// Tools that change no files keep the cache
+	call("WebFetch", message.ToolArgumentValues{"url": "https://example.com"})
+	call("todo_write", message.ToolArgumentValues{"todos": []any{}})
+	expect("Read", readA, "Read #1")
+
+	// A write drops results read from that path and every listing, nothing else
+	call("Write", message.ToolArgumentValues{"file_path": "./a.go", "content": "x"})
+	expect("Read", readA, "Read #4")
+	expect("Read", readB, "Read #3")
+	expect("Glob", glob, "Glob #2")
+
+	// Writes to files not named in path arguments, bash and undeclared tools drop everything
+	call("MultiEdit", message.ToolArgumentValues{"edits": []any{}})
+	expect("Read", readB, "Read #5")
+	call("bash", message.ToolArgumentValues{"command": "go generate ./..."})
+	expect("Read", readB, "Read #6")
+	call("mcp_tool", nil)
+	expect("Read", readB, "Read #7")
+
+	// Without the cache every call runs
+	uncached := NewCompositeToolManager(manager)
+	for i := 0; i < 2; i++ {
+		if _, err := uncached.CallTool(ctx, "Glob", glob); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if calls["Glob"] != 4 {
+		t.Errorf("expected uncached calls to run, Glob ran %d times", calls["Glob"])
+	}
+}
@@ -0,0 +1,151 @@
+package tool
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	pkgLogger "github.com/fpt/go-gennai-cli/pkg/logger"
+	"github.com/fpt/go-gennai-cli/pkg/message"
+)
+
+// copyMovePaths resolves and checks the source and destination of copy_file and
+// move_file. It returns an error result when the operation must be refused.
+func (m *FileSystemToolManager) copyMovePaths(ctx context.Context, args message.ToolArgumentValues) (src, dst string, refusal *message.ToolResult) {
+	refuse := func(msg string) (string, string, *message.ToolResult) {
+		res := message.NewToolResultError(msg)
+		return "", "", &res
+	}
+
+	srcParam, _ := args["source_path"].(string)
+	dstParam, _ := args["destination_path"].(string)
+	if srcParam == "" || dstParam == "" {
+		return refuse("source_path and destination_path parameters are required")
+	}
+
+	var err error
+	for _, p := range []struct {
+		param string
+		path  *string
+	}{{srcParam, &src}, {dstParam, &dst}} {
+		if *p.path, err = m.resolvePath(p.param); err != nil {
+			return refuse(fmt.Sprintf("failed to resolve path: %v", err))
+		}
+		if err := m.isPathAllowed(*p.path); err != nil {
+			return refuse(err.Error())
+		}
+		if err := m.isFileBlacklisted(*p.path); err != nil {
+			return refuse(err.Error())
+		}
+	}
+	if src == dst {
+		return refuse("source_path and destination_path are the same file")
+	}
+
+	if isRegular, err := m.fsRepo.IsRegular(ctx, src); err != nil {
+		if os.IsNotExist(err) {
+			return refuse(fmt.Sprintf("file does not exist: %s", src))
+		}
+		return refuse(fmt.Sprintf("failed to check file status: %v", err))
+	} else if !isRegular {
+		return refuse(fmt.Sprintf("%s is not a regular file; only files can be copied or moved", src))
+	}
+
+	if info, err := m.fsRepo.Stat(ctx, dst); err == nil {
+		if info.IsDir() {
+			return refuse(fmt.Sprintf("destination %s is a directory; give the full destination file path", dst))
+		}
+		if overwrite, _ := args["overwrite"].(bool); !overwrite {
+			return refuse(fmt.Sprintf("destination %s already exists; pass overwrite: true to replace it", dst))
+		}
+	} else if !os.IsNotExist(err) {
+		return refuse(fmt.Sprintf("failed to check file status: %v", err))
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return refuse(fmt.Sprintf("failed to create directory: %v", err))
+	}
+	return src, dst, nil
+}
+
+// handleCopyFile copies a file, keeping its mode
+func (m *FileSystemToolManager) handleCopyFile(ctx context.Context, args message.ToolArgumentValues) (message.ToolResult, error) {
+	src, dst, refusal := m.copyMovePaths(ctx, args)
+	if refusal != nil {
+		return *refusal, nil
+	}
+
+	content, err := m.fsRepo.ReadFile(ctx, src)
+	if err != nil {
+		return message.NewToolResultError(fmt.Sprintf("failed to read file: %v", err)), nil
+	}
+	mode := m.existingFileMode(ctx, src, defaultFileMode)
+
+	m.snapshotBeforeWrite(ctx, dst, "copy_file")
+	if err := m.fsRepo.WriteFile(ctx, dst, content, mode); err != nil {
+		return message.NewToolResultError(fmt.Sprintf("failed to write file: %v", err)), nil
+	}
+	// WriteFile only applies the mode when creating the file
+	if err := m.fsRepo.Chmod(ctx, dst, mode); err != nil {
+		return message.NewToolResultError(fmt.Sprintf("copied %s but failed to set mode %04o: %v", dst, mode, err)), nil
+	}
+
+	// The copy has the content the model last read from the source
+	if m.wasRead(src) {
+		m.recordFileRead(dst)
+	}
+
+	logger.DebugWithIntention(pkgLogger.IntentionTool, "Copied file", "source", src, "destination", dst, "bytes", len(content))
+	return message.NewToolResultText(fmt.Sprintf("Copied %s to %s (%d bytes)", src, dst, len(content))), nil
+}
+
+// handleMoveFile renames a file, carrying its read timestamp to the new path
+// so it can be edited there without another Read
+func (m *FileSystemToolManager) handleMoveFile(ctx context.Context, args message.ToolArgumentValues) (message.ToolResult, error) {
+	src, dst, refusal := m.copyMovePaths(ctx, args)
+	if refusal != nil {
+		return *refusal, nil
+	}
+
+	// One undo entry moves the file back and restores what it replaced
+	snap, ok := m.takeSnapshot(ctx, dst, "move_file")
+	if !ok {
+		return message.NewToolResultError(fmt.Sprintf("refusing to move over %s: its content could not be kept for undo_last_edit", dst)), nil
+	}
+	snap.movedFrom = src
+	// The source disappears, so change tracking needs its content too
+	srcSnap, srcOK := m.takeSnapshot(ctx, src, "move_file")
+	if err := m.fsRepo.Rename(ctx, src, dst); err != nil {
+		return message.NewToolResultError(fmt.Sprintf("failed to move file: %v", err)), nil
+	}
+	if srcOK {
+		m.recordSnapshot(snap, srcSnap)
+	} else {
+		m.recordSnapshot(snap)
+	}
+	m.moveReadTimestamp(src, dst)
+
+	logger.DebugWithIntention(pkgLogger.IntentionTool, "Moved file", "source", src, "destination", dst)
+	return message.NewToolResultText(fmt.Sprintf("Moved %s to %s", src, dst)), nil
+}
+
+// moveReadTimestamp carries the read timestamp of a moved file to its new path
+func (m *FileSystemToolManager) moveReadTimestamp(from, to string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if readAt, ok := m.fileReadTimestamps[from]; ok {
+		m.fileReadTimestamps[to] = readAt
+	} else {
+		delete(m.fileReadTimestamps, to)
+	}
+	delete(m.fileReadTimestamps, from)
+}
+
+// wasRead reports whether path has been read (or written) this session
+func (m *FileSystemToolManager) wasRead(path string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	_, ok := m.fileReadTimestamps[path]
+	return ok
+}
@@ -0,0 +1,128 @@
+package tool
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fpt/go-gennai-cli/internal/infra"
+	"github.com/fpt/go-gennai-cli/internal/repository"
+	"github.com/fpt/go-gennai-cli/pkg/message"
+)
+
+func TestFileSystemToolManager_CopyAndMoveFile(t *testing.T) {
+	dir := t.TempDir()
+	config := repository.FileSystemConfig{BlacklistedFiles: []string{"*.env"}}
+	manager := NewFileSystemToolManager(infra.NewOSFilesystemRepository(), config, dir)
+	ctx := context.Background()
+	call := func(name message.ToolName, args message.ToolArgumentValues) message.ToolResult {
+		t.Helper()
+		res, err := manager.CallTool(ctx, name, args)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res
+	}
+	readBack := func(name string) string {
+		t.Helper()
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("read: %v", err)
+		}
+		return string(data)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "run.sh"), []byte("#!/bin/sh\necho hi\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "other.txt"), []byte("other\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// copy keeps the mode and creates parent directories
+	res := call("copy_file", message.ToolArgumentValues{"source_path": "run.sh", "destination_path": "scripts/bin/run.sh"})
+	if res.Error != "" {
+		t.Fatalf("copy_file failed: %s", res.Error)
+	}
+	if got := readBack("scripts/bin/run.sh"); got != "#!/bin/sh\necho hi\n" {
+		t.Errorf("copied content = %q", got)
+	}
+	if info, err := os.Stat(filepath.Join(dir, "scripts/bin/run.sh")); err != nil || info.Mode().Perm() != 0755 {
+		t.Errorf("copied mode = %v, %v; want 0755", info.Mode().Perm(), err)
+	}
+
+	// existing destinations are only replaced with overwrite
+	res = call("copy_file", message.ToolArgumentValues{"source_path": "other.txt", "destination_path": "run.sh"})
+	if !strings.Contains(res.Error, "already exists") {
+		t.Errorf("expected overwrite refusal, got %+v", res)
+	}
+	res = call("move_file", message.ToolArgumentValues{"source_path": "other.txt", "destination_path": "run.sh", "overwrite": true})
+	if res.Error != "" {
+		t.Fatalf("move_file with overwrite failed: %s", res.Error)
+	}
+	if got := readBack("run.sh"); got != "other\n" {
+		t.Errorf("overwritten content = %q", got)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "other.txt")); !os.IsNotExist(err) {
+		t.Errorf("expected source to be gone after move, stat error: %v", err)
+	}
+
+	// a file read before the move can be edited at its new path
+	call("Read", message.ToolArgumentValues{"file_path": "run.sh"})
+	if res = call("move_file", message.ToolArgumentValues{"source_path": "run.sh", "destination_path": "docs/notes.txt"}); res.Error != "" {
+		t.Fatalf("move_file failed: %s", res.Error)
+	}
+	res = call("Edit", message.ToolArgumentValues{"file_path": "docs/notes.txt", "old_string": "other", "new_string": "moved"})
+	if res.Error != "" {
+		t.Errorf("edit after move failed: %s", res.Error)
+	}
+	res = call("Edit", message.ToolArgumentValues{"file_path": "run.sh", "old_string": "other", "new_string": "x"})
+	if res.Error == "" {
+		t.Error("expected the old path to lose its read timestamp")
+	}
+
+	// undo reverts the edit, then each move in one step
+	res = call("undo_last_edit", message.ToolArgumentValues{"count": float64(2)})
+	if !strings.Contains(res.Text, "moved it back from "+filepath.Join(dir, "docs/notes.txt")) {
+		t.Errorf("unexpected undo summary: %+v", res)
+	}
+	if got := readBack("run.sh"); got != "other\n" {
+		t.Errorf("after undo run.sh = %q, want %q", got, "other\n")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "docs/notes.txt")); !os.IsNotExist(err) {
+		t.Errorf("expected undo to remove the moved file, stat error: %v", err)
+	}
+	res = call("undo_last_edit", message.ToolArgumentValues{})
+	if !strings.Contains(res.Text, "restored the 18 bytes it replaced") {
+		t.Errorf("unexpected undo summary: %+v", res)
+	}
+	if got := readBack("other.txt"); got != "other\n" {
+		t.Errorf("after undo other.txt = %q, want %q", got, "other\n")
+	}
+	if got := readBack("run.sh"); got != "#!/bin/sh\necho hi\n" {
+		t.Errorf("after undo run.sh = %q, want the content the move replaced", got)
+	}
+	if info, err := os.Stat(filepath.Join(dir, "run.sh")); err != nil || info.Mode().Perm() != 0755 {
+		t.Errorf("restored mode = %v, %v; want 0755", info.Mode().Perm(), err)
+	}
+
+	for _, tc := range []struct {
+		name string
+		args message.ToolArgumentValues
+		want string
+	}{
+		{"missing source", message.ToolArgumentValues{"source_path": "nope.txt", "destination_path": "x.txt"}, "does not exist"},
+		{"same file", message.ToolArgumentValues{"source_path": "run.sh", "destination_path": "./run.sh"}, "same file"},
+		{"blacklisted destination", message.ToolArgumentValues{"source_path": "run.sh", "destination_path": "secrets.env"}, "blacklisted"},
+		{"outside allowed dirs", message.ToolArgumentValues{"source_path": "run.sh", "destination_path": "/tmp/elsewhere.sh"}, "outside working directory"},
+		{"directory source", message.ToolArgumentValues{"source_path": "scripts", "destination_path": "lib"}, "not a regular file"},
+		{"directory destination", message.ToolArgumentValues{"source_path": "run.sh", "destination_path": "scripts", "overwrite": true}, "is a directory"},
+	} {
+		res := call("move_file", tc.args)
+		if !strings.Contains(res.Error, tc.want) {
+			t.Errorf("%s: error = %q, want it to mention %q", tc.name, res.Error, tc.want)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package tool
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	pkgLogger "github.com/fpt/go-gennai-cli/pkg/logger"
+	"github.com/fpt/go-gennai-cli/pkg/message"
+)
+
+// handleDeleteFile removes a file, or an empty directory when directory is set.
+// The file's content is kept on the undo stack so undo_last_edit restores it;
+// files too large to keep are refused.
+func (m *FileSystemToolManager) handleDeleteFile(ctx context.Context, args message.ToolArgumentValues) (message.ToolResult, error) {
+	pathParam, _ := args["file_path"].(string)
+	if pathParam == "" {
+		return message.NewToolResultError("file_path parameter is required"), nil
+	}
+
+	path, err := m.resolvePath(pathParam)
+	if err != nil {
+		return message.NewToolResultError(fmt.Sprintf("failed to resolve path: %v", err)), nil
+	}
+	if err := m.isPathAllowed(path); err != nil {
+		return message.NewToolResultError(err.Error()), nil
+	}
+	if err := m.isFileBlacklisted(path); err != nil {
+		return message.NewToolResultError(err.Error()), nil
+	}
+	if path == m.workingDir {
+		return message.NewToolResultError("refusing to delete the working directory"), nil
+	}
+
+	info, err := m.fsRepo.Stat(ctx, path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return message.NewToolResultError(fmt.Sprintf("file does not exist: %s", path)), nil
+		}
+		return message.NewToolResultError(fmt.Sprintf("failed to check file status: %v", err)), nil
+	}
+
+	if info.IsDir() {
+		if directory, _ := args["directory"].(bool); !directory {
+			return message.NewToolResultError(fmt.Sprintf("%s is a directory; pass directory: true to delete an empty directory", path)), nil
+		}
+		entries, err := m.fsRepo.ReadDir(ctx, path)
+		if err != nil {
+			return message.NewToolResultError(fmt.Sprintf("failed to read directory: %v", err)), nil
+		}
+		if len(entries) > 0 {
+			return message.NewToolResultError(fmt.Sprintf("directory %s is not empty (%d entries); delete its files first", path, len(entries))), nil
+		}
+	} else if !info.Mode().IsRegular() {
+		return message.NewToolResultError(fmt.Sprintf("%s is not a regular file", path)), nil
+	} else if info.Size() > MaxUndoSnapshotBytes {
+		// The content could not be kept, so the deletion could not be undone
+		return message.NewToolResultError(fmt.Sprintf("refusing to delete %s: it is larger than %d bytes, so undo_last_edit could not restore it", path, MaxUndoSnapshotBytes)), nil
+	}
+
+	if !m.snapshotBeforeWrite(ctx, path, "delete_file") {
+		return message.NewToolResultError(fmt.Sprintf("refusing to delete %s: its content could not be kept for undo_last_edit", path)), nil
+	}
+	if err := m.fsRepo.Remove(ctx, path); err != nil {
+		return message.NewToolResultError(fmt.Sprintf("failed to delete: %v", err)), nil
+	}
+
+	m.mu.Lock()
+	delete(m.fileReadTimestamps, path)
+	m.mu.Unlock()
+
+	logger.DebugWithIntention(pkgLogger.IntentionTool, "Deleted path", "path", path, "directory", info.IsDir())
+	if info.IsDir() {
+		return message.NewToolResultText(fmt.Sprintf("Deleted empty directory %s", path)), nil
+	}
+	return message.NewToolResultText(fmt.Sprintf("Deleted %s (%d bytes; undo_last_edit restores it)", path, info.Size())), nil
+}
@@ -0,0 +1,94 @@
+package tool
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fpt/go-gennai-cli/internal/infra"
+	"github.com/fpt/go-gennai-cli/internal/repository"
+	"github.com/fpt/go-gennai-cli/pkg/message"
+)
+
+func TestFileSystemToolManager_DeleteFile(t *testing.T) {
+	dir := t.TempDir()
+	config := repository.FileSystemConfig{BlacklistedFiles: []string{"*.env"}}
+	manager := NewFileSystemToolManager(infra.NewOSFilesystemRepository(), config, dir)
+	ctx := context.Background()
+	call := func(args message.ToolArgumentValues) message.ToolResult {
+		t.Helper()
+		res, err := manager.CallTool(ctx, "delete_file", args)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res
+	}
+
+	script := filepath.Join(dir, "bin", "run.sh")
+	if err := os.MkdirAll(filepath.Dir(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(script, []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "app.env"), []byte("TOKEN=x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if res := call(message.ToolArgumentValues{"file_path": "bin"}); !strings.Contains(res.Error, "is a directory") {
+		t.Errorf("expected directory refusal without the flag, got %+v", res)
+	}
+	if res := call(message.ToolArgumentValues{"file_path": "bin", "directory": true}); !strings.Contains(res.Error, "not empty") {
+		t.Errorf("expected non-empty directory refusal, got %+v", res)
+	}
+	if res := call(message.ToolArgumentValues{"file_path": "app.env"}); !strings.Contains(res.Error, "blacklisted") {
+		t.Errorf("expected blacklist refusal, got %+v", res)
+	}
+	if res := call(message.ToolArgumentValues{"file_path": "/etc/hosts"}); res.Error == "" {
+		t.Error("expected path outside allowed directories to be refused")
+	}
+	if res := call(message.ToolArgumentValues{"file_path": "missing.txt"}); !strings.Contains(res.Error, "does not exist") {
+		t.Errorf("expected missing file error, got %+v", res)
+	}
+	big := filepath.Join(dir, "big.bin")
+	if err := os.WriteFile(big, []byte(strings.Repeat("x", MaxUndoSnapshotBytes+1)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if res := call(message.ToolArgumentValues{"file_path": "big.bin"}); !strings.Contains(res.Error, "could not restore") {
+		t.Errorf("expected refusal of a file too large to undo, got %+v", res)
+	}
+	if _, err := os.Stat(big); err != nil {
+		t.Errorf("expected %s to be kept: %v", big, err)
+	}
+	if depth := manager.UndoDepth(); depth != 0 {
+		t.Errorf("UndoDepth = %d after refusals, want 0", depth)
+	}
+
+	if res := call(message.ToolArgumentValues{"file_path": "bin/run.sh"}); res.Error != "" {
+		t.Fatalf("delete_file failed: %s", res.Error)
+	}
+	if res := call(message.ToolArgumentValues{"file_path": "bin", "directory": true}); res.Error != "" {
+		t.Fatalf("deleting the empty directory failed: %s", res.Error)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "bin")); !os.IsNotExist(err) {
+		t.Fatalf("expected bin to be gone, stat error: %v", err)
+	}
+
+	// Undo recreates the directory, then the file with its content and mode
+	res, _ := manager.CallTool(ctx, "undo_last_edit", message.ToolArgumentValues{"count": float64(2)})
+	if !strings.Contains(res.Text, "recreated the directory") || !strings.Contains(res.Text, "Reverted delete_file of "+script) {
+		t.Errorf("unexpected undo summary: %+v", res)
+	}
+	info, err := os.Stat(script)
+	if err != nil {
+		t.Fatalf("expected %s to be restored: %v", script, err)
+	}
+	if info.Mode().Perm() != 0755 {
+		t.Errorf("restored mode = %04o, want 0755", info.Mode().Perm())
+	}
+	if data, _ := os.ReadFile(script); string(data) != "#!/bin/sh\n" {
+		t.Errorf("restored content = %q", data)
+	}
+}
@@ -0,0 +1,78 @@
+package tool
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fpt/go-gennai-cli/pkg/message"
+)
+
+// readDiffSide resolves, checks and reads one side of diff_files
+func (m *FileSystemToolManager) readDiffSide(ctx context.Context, param string) (string, error) {
+	path, err := m.resolvePath(param)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve path: %v", err)
+	}
+	if err := m.isPathAllowed(path); err != nil {
+		return "", err
+	}
+	if err := m.isFileBlacklisted(path); err != nil {
+		return "", err
+	}
+	content, err := m.fsRepo.ReadFile(ctx, path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf("file does not exist: %s", param)
+		}
+		return "", fmt.Errorf("failed to read file: %v", err)
+	}
+	if isBinaryContent(content) {
+		return "", fmt.Errorf("%s is a binary file and cannot be diffed", param)
+	}
+	return string(content), nil
+}
+
+// handleDiffFiles returns a unified diff from file_path to other_path, or to
+// the inline content when other_path is not given. It only reads, so it does
+// not count as a Read for read-before-write.
+func (m *FileSystemToolManager) handleDiffFiles(ctx context.Context, args message.ToolArgumentValues) (message.ToolResult, error) {
+	pathParam, _ := args["file_path"].(string)
+	if pathParam == "" {
+		return message.NewToolResultError("file_path parameter is required"), nil
+	}
+	otherParam, _ := args["other_path"].(string)
+	content, hasContent := args["content"].(string)
+	if (otherParam != "") == hasContent {
+		return message.NewToolResultError("pass exactly one of other_path or content to compare file_path against"), nil
+	}
+
+	oldText, err := m.readDiffSide(ctx, pathParam)
+	if err != nil {
+		return message.NewToolResultError(err.Error()), nil
+	}
+	newText, toName := content, "b/"+pathParam+" (content)"
+	if otherParam != "" {
+		if newText, err = m.readDiffSide(ctx, otherParam); err != nil {
+			return message.NewToolResultError(err.Error()), nil
+		}
+		toName = "b/" + otherParam
+	}
+
+	diff := unifiedDiff("a/"+pathParam, toName, oldText, newText)
+	if diff == "" {
+		return message.NewToolResultText("No differences"), nil
+	}
+	added, removed := 0, 0
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+		case strings.HasPrefix(line, "+"):
+			added++
+		case strings.HasPrefix(line, "-"):
+			removed++
+		}
+	}
+	return message.NewToolResultText(fmt.Sprintf("%d line(s) added, %d removed\n\n%s", added, removed, diff)), nil
+}
@@ -0,0 +1,73 @@
+package tool
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fpt/go-gennai-cli/internal/infra"
+	"github.com/fpt/go-gennai-cli/pkg/message"
+)
+
+func TestDiffFiles(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"old.go":  "package main\n\nfunc main() {}\n",
+		"new.go":  "package main\n\nfunc main() { run() }\n",
+		".env":    "TOKEN=x\n",
+		"app.bin": "\x00\x01\x02",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	manager := NewFileSystemToolManager(infra.NewOSFilesystemRepository(), infra.DefaultFileSystemConfig(dir), dir)
+	ctx := context.Background()
+	call := func(args message.ToolArgumentValues) message.ToolResult {
+		t.Helper()
+		res, err := manager.CallTool(ctx, "diff_files", args)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res
+	}
+
+	res := call(message.ToolArgumentValues{"file_path": "old.go", "other_path": "new.go"})
+	want := "1 line(s) added, 1 removed\n\n--- a/old.go\n+++ b/new.go\n@@ -1,3 +1,3 @@\n package main\n \n-func main() {}\n+func main() { run() }\n"
+	if res.Error != "" || res.Text != want {
+		t.Errorf("unexpected diff of two files:\n%s%s\nwant:\n%s", res.Error, res.Text, want)
+	}
+
+	res = call(message.ToolArgumentValues{"file_path": "old.go", "content": "package main\n"})
+	if !strings.Contains(res.Text, "+++ b/old.go (content)") || !strings.Contains(res.Text, "0 line(s) added, 2 removed") {
+		t.Errorf("unexpected diff against content:\n%s%s", res.Error, res.Text)
+	}
+
+	if res = call(message.ToolArgumentValues{"file_path": "old.go", "content": "package main\n\nfunc main() {}\n"}); res.Text != "No differences" {
+		t.Errorf("expected no differences, got %q %q", res.Text, res.Error)
+	}
+
+	// Diffing is not a Read, so it does not unlock writes
+	res, _ = manager.CallTool(ctx, "Write", message.ToolArgumentValues{"file_path": "old.go", "content": "x"})
+	if !strings.Contains(res.Error, "not read before write") {
+		t.Errorf("expected diff_files not to count as a read, got %q", res.Error)
+	}
+
+	for _, tt := range []struct {
+		args message.ToolArgumentValues
+		want string
+	}{
+		{message.ToolArgumentValues{"file_path": "old.go"}, "exactly one of"},
+		{message.ToolArgumentValues{"file_path": "old.go", "other_path": "new.go", "content": "x"}, "exactly one of"},
+		{message.ToolArgumentValues{"file_path": "old.go", "other_path": ".env"}, "blacklisted"},
+		{message.ToolArgumentValues{"file_path": "old.go", "other_path": filepath.Join(t.TempDir(), "x.go")}, "outside"},
+		{message.ToolArgumentValues{"file_path": "missing.go", "content": "x"}, "does not exist"},
+		{message.ToolArgumentValues{"file_path": "app.bin", "content": "x"}, "binary"},
+	} {
+		if res := call(tt.args); !strings.Contains(res.Error, tt.want) {
+			t.Errorf("diff_files %v: expected error containing %q, got %q", tt.args, tt.want, res.Error)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package tool
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultFileMode    fs.FileMode = 0644
+	executableFileMode fs.FileMode = 0755
+)
+
+// parseFileMode parses an octal mode such as "755", "0755" or "0o755". Modes are
+// clamped so the owner can always read and write and nobody else can write;
+// setuid, setgid and sticky bits are rejected.
+func parseFileMode(s string) (fs.FileMode, error) {
+	digits := strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(s), "0o"), "0O")
+	v, err := strconv.ParseUint(digits, 8, 32)
+	if err != nil || digits == "" {
+		return 0, fmt.Errorf("invalid mode %q: expected an octal permission like \"0644\" or \"0755\"", s)
+	}
+	if v > 0777 {
+		return 0, fmt.Errorf("invalid mode %q: only permission bits (at most 0777) may be set", s)
+	}
+	return fs.FileMode(v)&0755 | 0600, nil
+}
+
+// hasShebang reports whether content starts with an interpreter line
+func hasShebang(content string) bool {
+	return strings.HasPrefix(content, "#!")
+}
+
+// existingFileMode returns the permission bits of path, or fallback when it
+// does not exist, so rewrites keep the file's mode
+func (m *FileSystemToolManager) existingFileMode(ctx context.Context, path string, fallback fs.FileMode) fs.FileMode {
+	if info, err := m.fsRepo.Stat(ctx, path); err == nil {
+		return info.Mode().Perm()
+	}
+	return fallback
+}
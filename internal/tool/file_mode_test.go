@@ -0,0 +1,114 @@
+package tool
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fpt/go-gennai-cli/internal/infra"
+	"github.com/fpt/go-gennai-cli/internal/repository"
+	"github.com/fpt/go-gennai-cli/pkg/message"
+)
+
+func TestParseFileMode(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    fs.FileMode
+		wantErr bool
+	}{
+		{in: "0755", want: 0755},
+		{in: "644", want: 0644},
+		{in: "0o700", want: 0700},
+		{in: "0400", want: 0600},    // owner always keeps read/write
+		{in: "0777", want: 0755},    // group/other write is dropped
+		{in: "4755", wantErr: true}, // setuid
+		{in: "rwx", wantErr: true},
+		{in: "0899", wantErr: true},
+		{in: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseFileMode(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseFileMode(%q) = %04o, want error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("parseFileMode(%q) = %04o, %v; want %04o", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestFileSystemToolManager_WriteModes(t *testing.T) {
+	dir := t.TempDir()
+	manager := NewFileSystemToolManager(infra.NewOSFilesystemRepository(), repository.FileSystemConfig{}, dir)
+	ctx := context.Background()
+
+	call := func(name message.ToolName, args message.ToolArgumentValues) message.ToolResult {
+		t.Helper()
+		res, err := manager.CallTool(ctx, name, args)
+		if err != nil || res.Error != "" {
+			t.Fatalf("%s failed: %v %s", name, err, res.Error)
+		}
+		return res
+	}
+	modeOf := func(path string) fs.FileMode {
+		t.Helper()
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatalf("stat: %v", err)
+		}
+		return info.Mode().Perm()
+	}
+
+	// New scripts with a shebang line are executable; other files are 0644
+	script := filepath.Join(dir, "build.sh")
+	res := call("Write", message.ToolArgumentValues{"file_path": script, "content": "#!/bin/sh\necho hi\n"})
+	if got := modeOf(script); got != 0755 {
+		t.Errorf("shebang script mode = %04o, want 0755", got)
+	}
+	if !strings.Contains(res.Text, "(mode 0755)") {
+		t.Errorf("expected mode in result, got: %s", res.Text)
+	}
+	plain := filepath.Join(dir, "notes.txt")
+	call("Write", message.ToolArgumentValues{"file_path": plain, "content": "notes\n"})
+	if got := modeOf(plain); got != 0644 {
+		t.Errorf("plain file mode = %04o, want 0644", got)
+	}
+
+	// Edits and rewrites keep the existing mode
+	call("Edit", message.ToolArgumentValues{"file_path": script, "old_string": "hi", "new_string": "hello"})
+	call("Write", message.ToolArgumentValues{"file_path": script, "content": "#!/bin/sh\necho bye\n"})
+	if got := modeOf(script); got != 0755 {
+		t.Errorf("mode after edits = %04o, want 0755", got)
+	}
+
+	// An explicit mode is applied to existing files too
+	call("Write", message.ToolArgumentValues{"file_path": plain, "content": "notes\n", "mode": "0700"})
+	if got := modeOf(plain); got != 0700 {
+		t.Errorf("explicit mode = %04o, want 0700", got)
+	}
+
+	// Existing non-executable files with a shebang get a hint instead of a silent change
+	hook := filepath.Join(dir, "hook")
+	if err := os.WriteFile(hook, []byte("old\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	call("Read", message.ToolArgumentValues{"file_path": hook})
+	res = call("Write", message.ToolArgumentValues{"file_path": hook, "content": "#!/bin/sh\n"})
+	if got := modeOf(hook); got != 0644 {
+		t.Errorf("existing file mode changed to %04o", got)
+	}
+	if !strings.Contains(res.Text, `pass mode "0755"`) {
+		t.Errorf("expected executable hint, got: %s", res.Text)
+	}
+
+	res, _ = manager.CallTool(ctx, "Write", message.ToolArgumentValues{"file_path": plain, "content": "x", "mode": "abc"})
+	if !strings.Contains(res.Error, "invalid mode") {
+		t.Errorf("expected invalid mode error, got %+v", res)
+	}
+}
@@ -1,21 +1,28 @@
 package tool
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io/fs"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/fpt/go-gennai-cli/internal/repository"
 	pkgLogger "github.com/fpt/go-gennai-cli/pkg/logger"
 	"github.com/fpt/go-gennai-cli/pkg/message"
+	"github.com/fpt/go-gennai-cli/pkg/secret"
 	"github.com/pkg/errors"
 )
 
+// DefaultMaxReadBytes is the Read output size above which results are truncated
+const DefaultMaxReadBytes = 256 * 1024
+
 var errNotInAllowedDirectory = errors.New("file access denied: path is not within allowed directories")
 
 // FileSystemToolManager provides secure file system operations with safety controls
@@ -24,15 +31,36 @@ type FileSystemToolManager struct {
 	fsRepo repository.FilesystemRepository // Injected filesystem repository
 
 	// Access control
-	allowedDirectories []string // Directories where file operations are allowed
-	blacklistedFiles   []string // Files that cannot be read (to prevent secret leaks)
+	allowedDirectories []string       // Directories where file operations are allowed
+	blacklistedFiles   []string       // Files that cannot be read (to prevent secret leaks)
+	maxReadBytes       int            // Read output beyond this size is truncated with a paging notice
+	headTailLines      int            // Lines file_head and file_tail return by default
+	headTailMaxLines   int            // Most lines file_head and file_tail return
+	ignore             *projectIgnore // Per-project exclusions from .gennaiignore
+
+	// Post-edit validation
+	validators         []Validator       // Checks run on files after Write/Edit, dispatched by extension
+	validationFailures map[string]string // Report of each file whose last validation failed, guarded by mu
 
 	// Working directory context
 	workingDir string // Working directory for resolving relative paths
 
 	// Read-write semantics tracking
-	fileReadTimestamps map[string]time.Time // Track when files were last read
-	mu                 sync.RWMutex         // Thread safety for timestamp tracking
+	fileReadTimestamps  map[string]time.Time // Track when files were last read
+	skipReadBeforeWrite bool                 // Allow overwriting files that were not read first
+	readImages          bool                 // Return image files as images (the model supports vision)
+	mu                  sync.RWMutex         // Thread safety for timestamp tracking
+
+	// Undo history: content of files before each write or edit, newest last
+	undoStack []editSnapshot
+	undoMu    sync.Mutex
+
+	// Original state of the files changed since TrackChanges (nil = not tracking)
+	changeOrigins map[string]editSnapshot
+	changeOrder   []string
+
+	// Secrets hidden from tool results (nil = redaction off)
+	scrubber *secret.Scrubber
 
 	// Tool registry
 	tools map[message.ToolName]message.Tool
@@ -52,11 +80,27 @@ func NewFileSystemToolManager(fsRepo repository.FilesystemRepository, config rep
 		fsRepo:             fsRepo,
 		allowedDirectories: allowedDirs,
 		blacklistedFiles:   config.BlacklistedFiles,
+		maxReadBytes:       config.MaxReadBytes,
+		headTailLines:      config.HeadTailLines,
+		headTailMaxLines:   config.HeadTailMaxLines,
+		ignore:             newProjectIgnore(absWorkingDir),
+		validators:         newValidators(fsRepo, config.DisabledValidators),
 		workingDir:         absWorkingDir,
 		fileReadTimestamps: make(map[string]time.Time),
+		validationFailures: make(map[string]string),
 		tools:              make(map[message.ToolName]message.Tool),
 	}
 
+	if manager.maxReadBytes <= 0 {
+		manager.maxReadBytes = DefaultMaxReadBytes
+	}
+	if manager.headTailMaxLines <= 0 {
+		manager.headTailMaxLines = DefaultHeadTailMaxLines
+	}
+	if manager.headTailLines <= 0 {
+		manager.headTailLines = min(DefaultHeadTailLines, manager.headTailMaxLines)
+	}
+
 	// Register filesystem tools
 	manager.registerFileSystemTools()
 
@@ -112,12 +156,19 @@ func (m *FileSystemToolManager) CallTool(ctx context.Context, name message.ToolN
 	return handler(ctx, args)
 }
 
+// RegisterTool registers a tool that declares no effects (message.AccessUnknown)
 func (m *FileSystemToolManager) RegisterTool(name message.ToolName, description message.ToolDescription, args []message.ToolArgument, handler func(ctx context.Context, args message.ToolArgumentValues) (message.ToolResult, error)) {
+	m.registerTool(name, message.ToolEffects{}, description, args, handler)
+}
+
+// registerTool registers a built-in tool with the effects it declares
+func (m *FileSystemToolManager) registerTool(name message.ToolName, effects message.ToolEffects, description message.ToolDescription, args []message.ToolArgument, handler func(ctx context.Context, args message.ToolArgumentValues) (message.ToolResult, error)) {
 	tool := &fileSystemTool{
 		name:        name,
 		description: description,
 		arguments:   args,
 		handler:     handler,
+		effects:     effects,
 	}
 	m.tools[name] = tool
 }
@@ -125,24 +176,51 @@ func (m *FileSystemToolManager) RegisterTool(name message.ToolName, description
 // registerFileSystemTools registers all secure filesystem tools
 func (m *FileSystemToolManager) registerFileSystemTools() {
 	// Read with optional offset/limit and line-numbered output
-	m.RegisterTool("Read", "Read a file with optional offset/limit and line-numbered output",
+	m.registerTool("Read", message.CachedFileRead, "Read a file with optional offset/limit and line-numbered output. Binary files are summarized (size and type) instead of dumped; images are returned as images when the model supports vision.",
 		[]message.ToolArgument{
 			{Name: "file_path", Description: "Path to the file to read", Required: true, Type: "string"},
 			{Name: "offset", Description: "1-based line start (optional)", Required: false, Type: "number"},
 			{Name: "limit", Description: "Number of lines to return (optional)", Required: false, Type: "number"},
+			{Name: "max_bytes", Description: "Maximum bytes of output before truncating (optional)", Required: false, Type: "number"},
+			{Name: "preview", Description: "For binary files: \"hex\" or \"base64\" to show the first bytes (optional)", Required: false, Type: "string"},
+			{Name: "preview_bytes", Description: message.ToolDescription(fmt.Sprintf("Number of bytes to preview (default %d, max %d)", defaultBinaryPreviewBytes, maxBinaryPreviewBytes)), Required: false, Type: "number"},
 		},
 		m.handleRead)
 
+	// read_many_files: several files in one call, delimited like @includes
+	m.registerTool("read_many_files", message.CachedSearch, "Read several files in one call, each wrapped in ----- BEGIN path ----- / ----- END path ----- markers. Pass a list of paths and/or a path glob (e.g. \"internal/app/*.go\"). Counts as a Read of every returned file; output beyond max_bytes is truncated.",
+		[]message.ToolArgument{
+			{Name: "paths", Description: "Files to read", Required: false, Type: "array", Properties: map[string]any{"items": map[string]any{"type": "string"}}},
+			{Name: "glob", Description: "Path glob relative to the working directory, with ** for any depth (optional)", Required: false, Type: "string"},
+			{Name: "max_bytes", Description: "Maximum bytes of combined output before truncating (optional)", Required: false, Type: "number"},
+		},
+		m.handleReadManyFiles)
+
+	// file_head / file_tail: the start or end of a large file without reading all of it
+	m.registerTool("file_head", message.UncachedRead, message.ToolDescription(fmt.Sprintf("Return the first lines of a file, line-numbered, without reading the rest (e.g. the header of a large generated file). Counts as a Read. Default %d lines, at most %d.", m.headTailLines, m.headTailMaxLines)),
+		[]message.ToolArgument{
+			{Name: "file_path", Description: "Path to the file", Required: true, Type: "string"},
+			{Name: "lines", Description: "Number of lines to return (optional)", Required: false, Type: "number"},
+		},
+		m.handleFileHead)
+	m.registerTool("file_tail", message.UncachedRead, message.ToolDescription(fmt.Sprintf("Return the last lines of a file, reading backwards from its end (e.g. the latest errors in a long log). Counts as a Read. Default %d lines, at most %d.", m.headTailLines, m.headTailMaxLines)),
+		[]message.ToolArgument{
+			{Name: "file_path", Description: "Path to the file", Required: true, Type: "string"},
+			{Name: "lines", Description: "Number of lines to return (optional)", Required: false, Type: "number"},
+		},
+		m.handleFileTail)
+
 	// Write
-	m.RegisterTool("Write", "Write full content to a file",
+	m.registerTool("Write", message.FileWrite, "Write full content to a file",
 		[]message.ToolArgument{
 			{Name: "file_path", Description: "Path to the file to write", Required: true, Type: "string"},
 			{Name: "content", Description: "Full file content", Required: true, Type: "string"},
+			{Name: "mode", Description: "Octal permissions such as \"0755\" (default: keep an existing file's mode; new files get 0644, or 0755 when they start with a shebang line)", Required: false, Type: "string"},
 		},
 		m.handleWrite)
 
 	// Edit
-	m.RegisterTool("Edit", "Exact string replacement in a file (requires read-before-write semantics)",
+	m.registerTool("Edit", message.FileWrite, "Exact string replacement in a file (requires read-before-write semantics)",
 		[]message.ToolArgument{
 			{Name: "file_path", Description: "Path to the file to edit", Required: true, Type: "string"},
 			{Name: "old_string", Description: "Exact string to replace (unique unless replace_all)", Required: true, Type: "string"},
@@ -151,8 +229,58 @@ func (m *FileSystemToolManager) registerFileSystemTools() {
 		},
 		m.handleEdit)
 
+	// replace_lines: line-range replacement for files with repeated boilerplate
+	m.registerTool("replace_lines", message.FileWrite, "Replace a 1-based inclusive line range in a file with new content (requires prior Read). Empty new_content deletes the lines.",
+		[]message.ToolArgument{
+			{Name: "file_path", Description: "Path to the file to edit", Required: true, Type: "string"},
+			{Name: "start_line", Description: "First line to replace (1-based)", Required: true, Type: "number"},
+			{Name: "end_line", Description: "Last line to replace (1-based, inclusive)", Required: true, Type: "number"},
+			{Name: "new_content", Description: "Replacement text for the line range", Required: true, Type: "string"},
+		},
+		m.handleReplaceLines)
+
+	// apply_patch: multi-hunk, multi-file changes as a unified diff
+	m.registerTool("apply_patch", message.WorkspaceWrite, "Apply a unified diff (diff -u / git diff format) to one or more files. Every hunk's context must match exactly or the whole patch is rejected. Modified and deleted files require a prior Read; use /dev/null as the old path to create a file or as the new path to delete one.",
+		[]message.ToolArgument{
+			{Name: "patch", Description: "Unified diff with ---/+++ file headers and @@ hunks", Required: true, Type: "string"},
+		},
+		m.handleApplyPatch)
+
+	// diff_files: compare two files, or a file against proposed content
+	m.registerTool("diff_files", message.CachedFileRead, "Show a unified diff between two files, or between a file and inline content (e.g. to check an edit against the original). Read-only; does not count as a Read of either file.",
+		[]message.ToolArgument{
+			{Name: "file_path", Description: "Original file (the - side of the diff)", Required: true, Type: "string"},
+			{Name: "other_path", Description: "File to compare against (the + side); give this or content", Required: false, Type: "string"},
+			{Name: "content", Description: "Text to compare against instead of other_path", Required: false, Type: "string"},
+		},
+		m.handleDiffFiles)
+
+	// copy_file / move_file: reorganize files without a read-write-delete round trip
+	m.registerTool("copy_file", message.FileWrite, "Copy a file to a new path, creating parent directories as needed. Refuses to replace an existing destination unless overwrite is true.",
+		[]message.ToolArgument{
+			{Name: "source_path", Description: "File to copy", Required: true, Type: "string"},
+			{Name: "destination_path", Description: "Path of the copy", Required: true, Type: "string"},
+			{Name: "overwrite", Description: "Replace the destination if it exists (default false)", Required: false, Type: "boolean"},
+		},
+		m.handleCopyFile)
+	m.registerTool("move_file", message.FileWrite, "Move or rename a file, creating parent directories as needed. A file read before the move can be edited at its new path without reading it again. Refuses to replace an existing destination unless overwrite is true.",
+		[]message.ToolArgument{
+			{Name: "source_path", Description: "File to move", Required: true, Type: "string"},
+			{Name: "destination_path", Description: "New path for the file", Required: true, Type: "string"},
+			{Name: "overwrite", Description: "Replace the destination if it exists (default false)", Required: false, Type: "boolean"},
+		},
+		m.handleMoveFile)
+
+	// delete_file: removal that goes through approval and can be undone, unlike `rm` via bash
+	m.registerTool("delete_file", message.FileWrite, "Delete a file, or an empty directory when directory is true. Requires user approval; the deleted content can be restored with undo_last_edit. Files over 1 MiB are refused because they could not be restored. Use this instead of rm.",
+		[]message.ToolArgument{
+			{Name: "file_path", Description: "File (or empty directory) to delete", Required: true, Type: "string"},
+			{Name: "directory", Description: "Allow deleting an empty directory (default false)", Required: false, Type: "boolean"},
+		},
+		m.handleDeleteFile)
+
 	// LS with ignore globs
-	m.RegisterTool("LS", "List directory contents with optional ignore globs",
+	m.registerTool("LS", message.CachedSearch, "List directory contents with optional ignore globs",
 		[]message.ToolArgument{
 			{Name: "path", Description: "Directory path to list", Required: true, Type: "string"},
 			{Name: "ignore", Description: "Array of glob patterns to ignore", Required: false, Type: "array"},
@@ -160,7 +288,7 @@ func (m *FileSystemToolManager) registerFileSystemTools() {
 		m.handleLS)
 
 	// MultiEdit: apply multiple precise edits across files in one call
-	m.RegisterTool("MultiEdit", "Apply multiple exact string replacements across files in a single, atomic batch. Requires prior Read of target files.",
+	m.registerTool("MultiEdit", message.WorkspaceWrite, "Apply multiple exact string replacements across files in a single, atomic batch. Requires prior Read of target files.",
 		[]message.ToolArgument{
 			{
 				Name:        "edits",
@@ -195,6 +323,62 @@ func (m *FileSystemToolManager) registerFileSystemTools() {
 			},
 		},
 		m.handleMultiEdit)
+
+	// grep_content: regex content search with line numbers and context
+	m.registerTool("grep_content", message.CachedSearch, "Search file contents with a regular expression. Returns matches as file:line: content with optional surrounding context lines.",
+		[]message.ToolArgument{
+			{Name: "pattern", Description: "Regular expression (Go RE2 syntax)", Required: true, Type: "string"},
+			{Name: "path", Description: "File or directory to search (default: working directory)", Required: false, Type: "string"},
+			{Name: "context_lines", Description: "Lines of context before and after each match (default 0)", Required: false, Type: "number"},
+			{Name: "max_matches", Description: "Maximum number of matches to return (default 100)", Required: false, Type: "number"},
+		},
+		m.handleGrepContent)
+
+	// replace_across_files: regex find-and-replace over every file matching a glob
+	m.registerTool("replace_across_files", message.WorkspaceWrite, "Replace every match of a regular expression in all files matching a path glob (e.g. \"**/*.go\"), skipping blacklisted, ignored and binary files and files over 1 MiB, which undo_last_edit could not restore. Returns per-file replacement counts; with dry_run it returns the diffs without writing. Requires user approval unless dry_run is true; undo_last_edit reverts one file per call.",
+		[]message.ToolArgument{
+			{Name: "pattern", Description: "Regular expression to replace (Go RE2 syntax)", Required: true, Type: "string"},
+			{Name: "replacement", Description: "Replacement text; $1 or ${name} expand capture groups", Required: true, Type: "string"},
+			{Name: "glob", Description: "Files to change, relative to the working directory; \"*\" stays within a directory, \"**\" spans directories, and a pattern without \"/\" matches file names at any depth", Required: true, Type: "string"},
+			{Name: "dry_run", Description: "Return the diffs without writing (default false)", Required: false, Type: "boolean"},
+		},
+		m.handleReplaceAcrossFiles)
+
+	// directory_tree: one-shot indented overview of a directory hierarchy
+	m.registerTool("directory_tree", message.CachedSearch, "Show an indented directory tree (like `tree -L N`). Skips hidden, VCS and dependency directories and honors .gitignore and .gennaiignore.",
+		[]message.ToolArgument{
+			{Name: "path", Description: "Root directory (default: working directory)", Required: false, Type: "string"},
+			{Name: "max_depth", Description: "Maximum depth to descend (default 3)", Required: false, Type: "number"},
+			{Name: "exclude", Description: "Array of additional glob patterns to exclude", Required: false, Type: "array"},
+		},
+		m.handleDirectoryTree)
+
+	// summarize_path: structural outline of a file or one-line summaries of a directory's files
+	m.registerTool("summarize_path", message.CachedSearch, "Outline a file (Go: package, imports and top-level declarations with line numbers; other languages: headings and definitions) or list a directory's files with one-line summaries. Use it to orient before reading whole files.",
+		[]message.ToolArgument{
+			{Name: "path", Description: "File or directory to summarize (default: working directory)", Required: false, Type: "string"},
+			{Name: "max_depth", Description: "For directories, how many levels of files to list (default 2)", Required: false, Type: "number"},
+		},
+		m.handleSummarizePath)
+
+	// query_data: filter and aggregate rows of a local CSV or JSON-lines file
+	m.registerTool("query_data", message.CachedFileRead, "Query a local CSV/TSV or JSON-lines file: filter rows with `where`, pick columns with `select`, or count rows per `group_by` value (optionally summing a numeric column). Returns CSV.",
+		[]message.ToolArgument{
+			{Name: "file_path", Description: "Path to a .csv, .tsv, .jsonl, .ndjson or .json (one object per line) file", Required: true, Type: "string"},
+			{Name: "select", Description: "Array of columns to return (default: all)", Required: false, Type: "array"},
+			{Name: "where", Description: "Filter such as `status == \"open\" and amount > 100`; ops: ==, !=, >, >=, <, <=, contains", Required: false, Type: "string"},
+			{Name: "group_by", Description: "Column to group by; returns one row per value with its count", Required: false, Type: "string"},
+			{Name: "sum", Description: "Numeric column to sum per group (requires group_by)", Required: false, Type: "string"},
+			{Name: "limit", Description: "Maximum rows or groups to return (default 50, max 500)", Required: false, Type: "number"},
+		},
+		m.handleQueryData)
+
+	// undo_last_edit: revert recent writes and edits from the session's snapshots
+	m.registerTool("undo_last_edit", message.WorkspaceWrite, "Revert the most recent file write or edit (Write, Edit, MultiEdit, replace_lines, apply_patch, replace_across_files, copy_file, move_file, delete_file), restoring the previous content. Files created by the change are removed, and a moved file is moved back.",
+		[]message.ToolArgument{
+			{Name: "count", Description: "Number of changes to revert, newest first (default 1)", Required: false, Type: "number"},
+		},
+		m.handleUndoLastEdit)
 }
 
 // Security validation methods
@@ -261,9 +445,23 @@ func (m *FileSystemToolManager) isPathAllowed(path string) error {
 
 // isFileBlacklisted checks if a file is in the blacklist
 func (m *FileSystemToolManager) isFileBlacklisted(path string) error {
-	fileName := filepath.Base(path)
 	absPath := path // Expect path to already be absolute (resolved by caller)
 
+	if err := m.matchBlacklist(absPath); err != nil {
+		return err
+	}
+
+	if m.ignore.matches(absPath, false) {
+		return fmt.Errorf("file access denied: %s is excluded by %s", path, gennaiIgnoreFile)
+	}
+
+	return nil
+}
+
+// matchBlacklist checks an absolute path against the blacklisted file patterns
+func (m *FileSystemToolManager) matchBlacklist(absPath string) error {
+	fileName := filepath.Base(absPath)
+
 	for _, blacklisted := range m.blacklistedFiles {
 		// Check both filename and full path patterns
 		if matched, _ := filepath.Match(blacklisted, fileName); matched {
@@ -274,19 +472,43 @@ func (m *FileSystemToolManager) isFileBlacklisted(path string) error {
 		}
 		// Also check for exact matches
 		if fileName == blacklisted || absPath == blacklisted {
-			return fmt.Errorf("file access denied: %s is blacklisted", path)
+			return fmt.Errorf("file access denied: %s is blacklisted", absPath)
 		}
 	}
-
 	return nil
 }
 
+// IsBlacklisted reports whether a path, absolute or relative to the working
+// directory, matches one of the blacklisted file patterns
+func (m *FileSystemToolManager) IsBlacklisted(path string) bool {
+	absPath, err := m.abs(path)
+	if err != nil {
+		return false
+	}
+	return m.matchBlacklist(absPath) != nil
+}
+
+// SetRequireReadBeforeWrite turns the read-before-write check on (the default)
+// or off. With it off, existing files can be overwritten or edited without
+// being read first, or after changing on disk; allowed directories and the
+// blacklist are still enforced.
+func (m *FileSystemToolManager) SetRequireReadBeforeWrite(required bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.skipReadBeforeWrite = !required
+}
+
 // validateReadWriteSemantics checks if a write operation is safe based on read timestamps
 func (m *FileSystemToolManager) validateReadWriteSemantics(ctx context.Context, path string) error {
 	m.mu.RLock()
 	lastReadTime, wasRead := m.fileReadTimestamps[path]
+	skip := m.skipReadBeforeWrite
 	m.mu.RUnlock()
 
+	if skip {
+		return nil
+	}
+
 	if !wasRead {
 		return fmt.Errorf("read-write semantics violation: file %s was not read before write attempt", path)
 	}
@@ -349,6 +571,9 @@ func (m *FileSystemToolManager) handleReadFile(ctx context.Context, args message
 	// Record successful read for read-write semantics
 	m.recordFileRead(path)
 
+	if result, ok := m.binaryReadResult(path, content, args); ok {
+		return result, nil
+	}
 	return message.NewToolResultText(string(content)), nil
 }
 
@@ -379,18 +604,46 @@ func (m *FileSystemToolManager) handleWriteFile(ctx context.Context, args messag
 	if err := m.isPathAllowed(path); err != nil {
 		return message.NewToolResultError(err.Error()), nil
 	}
+	if err := m.isFileBlacklisted(path); err != nil {
+		return message.NewToolResultError(err.Error()), nil
+	}
+
+	// An explicit mode wins; otherwise existing files keep theirs and new
+	// scripts with a shebang line are made executable
+	var mode fs.FileMode
+	modeParam, hasMode := args["mode"].(string)
+	if hasMode && strings.TrimSpace(modeParam) != "" {
+		parsed, err := parseFileMode(modeParam)
+		if err != nil {
+			return message.NewToolResultError(err.Error()), nil
+		}
+		mode = parsed
+	} else {
+		hasMode = false
+	}
 
 	// Check if the file exists - only validate read-write semantics for existing files
-	if _, err := m.fsRepo.Stat(ctx, path); err == nil {
+	existed := false
+	if info, err := m.fsRepo.Stat(ctx, path); err == nil {
 		// File exists - validate read-write semantics
 		if err := m.validateReadWriteSemantics(ctx, path); err != nil {
 			return message.NewToolResultError(err.Error()), nil
 		}
+		existed = true
+		if !hasMode {
+			mode = info.Mode().Perm()
+		}
 	} else if !os.IsNotExist(err) {
 		// Other error (permission, etc.) - report it
 		return message.NewToolResultError(fmt.Sprintf("failed to check file status: %v", err)), nil
 	}
 	// If file doesn't exist (os.IsNotExist), allow creating new file without validation
+	if !existed && !hasMode {
+		mode = defaultFileMode
+		if hasShebang(content) {
+			mode = executableFileMode
+		}
+	}
 
 	// Create directory if it doesn't exist
 	dir := filepath.Dir(path)
@@ -398,10 +651,21 @@ func (m *FileSystemToolManager) handleWriteFile(ctx context.Context, args messag
 		return message.NewToolResultError(fmt.Sprintf("failed to create directory: %v", err)), nil
 	}
 
+	if err := m.checkRedactedWrite(ctx, path, content); err != nil {
+		return message.NewToolResultError(err.Error()), nil
+	}
+
 	// Perform the write operation
-	if err := m.fsRepo.WriteFile(ctx, path, []byte(content), 0644); err != nil {
+	m.snapshotBeforeWrite(ctx, path, "Write")
+	if err := m.fsRepo.WriteFile(ctx, path, []byte(content), mode); err != nil {
 		return message.NewToolResultError(fmt.Sprintf("failed to write file: %v", err)), nil
 	}
+	// WriteFile only applies the mode when creating the file
+	if existed && hasMode {
+		if err := m.fsRepo.Chmod(ctx, path, mode); err != nil {
+			return message.NewToolResultError(fmt.Sprintf("wrote %s but failed to set mode %04o: %v", path, mode, err)), nil
+		}
+	}
 
 	// Update read timestamp after successful write to allow sequential edits
 	m.recordFileRead(path)
@@ -409,7 +673,15 @@ func (m *FileSystemToolManager) handleWriteFile(ctx context.Context, args messag
 	// Run auto-validation based on file type
 	validationResult := m.autoValidateFile(ctx, path)
 
-	return message.NewToolResultText(fmt.Sprintf("Successfully wrote to %s%s", path, validationResult)), nil
+	var modeNote string
+	switch {
+	case hasMode || (!existed && mode == executableFileMode):
+		modeNote = fmt.Sprintf(" (mode %04o)", mode)
+	case hasShebang(content) && mode&0100 == 0:
+		modeNote = fmt.Sprintf(" (mode %04o; the file has a shebang line, pass mode \"0755\" to make it executable)", mode)
+	}
+
+	return message.NewToolResultText(fmt.Sprintf("Successfully wrote to %s%s%s", path, modeNote, validationResult)), nil
 }
 
 func (m *FileSystemToolManager) handleEnhancedEdit(ctx context.Context, args message.ToolArgumentValues) (message.ToolResult, error) {
@@ -501,8 +773,13 @@ func (m *FileSystemToolManager) handleEnhancedEdit(ctx context.Context, args mes
 		return message.NewToolResultError("no changes made to file - old_string and new_string may be identical"), nil
 	}
 
+	if err := m.checkRedactedWrite(ctx, absPath, newContent); err != nil {
+		return message.NewToolResultError(err.Error()), nil
+	}
+
 	// Write the modified content back to the file
-	if err := m.fsRepo.WriteFile(ctx, absPath, []byte(newContent), 0644); err != nil {
+	m.snapshotBeforeWrite(ctx, absPath, "Edit")
+	if err := m.fsRepo.WriteFile(ctx, absPath, []byte(newContent), m.existingFileMode(ctx, absPath, defaultFileMode)); err != nil {
 		return message.NewToolResultError(fmt.Sprintf("failed to write file %s: %v", absPath, err)), nil
 	}
 
@@ -528,6 +805,92 @@ func (m *FileSystemToolManager) handleEnhancedEdit(ctx context.Context, args mes
 		absPath, occurrenceInfo, oldLines, newLines, len(oldString), len(newString), validationResult)), nil
 }
 
+// handleReplaceLines replaces an inclusive line range with new content
+func (m *FileSystemToolManager) handleReplaceLines(ctx context.Context, args message.ToolArgumentValues) (message.ToolResult, error) {
+	filePath, ok := args["file_path"].(string)
+	if !ok {
+		return message.NewToolResultError("file_path parameter is required"), nil
+	}
+	startVal, ok := args["start_line"].(float64)
+	if !ok {
+		return message.NewToolResultError("start_line parameter is required"), nil
+	}
+	endVal, ok := args["end_line"].(float64)
+	if !ok {
+		return message.NewToolResultError("end_line parameter is required"), nil
+	}
+	newContent, ok := args["new_content"].(string)
+	if !ok {
+		return message.NewToolResultError("new_content parameter is required"), nil
+	}
+	startLine, endLine := int(startVal), int(endVal)
+
+	absPath, err := m.resolvePath(filePath)
+	if err != nil {
+		return message.NewToolResultError(fmt.Sprintf("failed to resolve path: %v", err)), nil
+	}
+	if err := m.isPathAllowed(absPath); err != nil {
+		return message.NewToolResultError(err.Error()), nil
+	}
+	if err := m.isFileBlacklisted(absPath); err != nil {
+		return message.NewToolResultError(err.Error()), nil
+	}
+	if err := m.validateReadWriteSemantics(ctx, absPath); err != nil {
+		return message.NewToolResultError(err.Error()), nil
+	}
+
+	content, err := m.fsRepo.ReadFile(ctx, absPath)
+	if err != nil {
+		return message.NewToolResultError(fmt.Sprintf("failed to read file %s: %v", absPath, err)), nil
+	}
+
+	fileContent := string(content)
+	hasTrailingNewline := strings.HasSuffix(fileContent, "\n")
+	lines := strings.Split(strings.TrimSuffix(fileContent, "\n"), "\n")
+	if fileContent == "" {
+		lines = nil
+	}
+
+	if startLine < 1 || endLine < startLine || endLine > len(lines) {
+		return message.NewToolResultError(fmt.Sprintf("invalid line range %d-%d: file %s has %d line(s)", startLine, endLine, absPath, len(lines))), nil
+	}
+
+	var replacement []string
+	if newContent != "" {
+		replacement = strings.Split(strings.TrimSuffix(newContent, "\n"), "\n")
+	}
+
+	updated := make([]string, 0, len(lines)-(endLine-startLine+1)+len(replacement))
+	updated = append(updated, lines[:startLine-1]...)
+	updated = append(updated, replacement...)
+	updated = append(updated, lines[endLine:]...)
+
+	result := strings.Join(updated, "\n")
+	if hasTrailingNewline && len(updated) > 0 {
+		result += "\n"
+	}
+	if result == fileContent {
+		return message.NewToolResultError("no changes made to file - new_content matches the existing lines"), nil
+	}
+
+	if err := m.checkRedactedWrite(ctx, absPath, result); err != nil {
+		return message.NewToolResultError(err.Error()), nil
+	}
+
+	m.snapshotBeforeWrite(ctx, absPath, "replace_lines")
+	if err := m.fsRepo.WriteFile(ctx, absPath, []byte(result), m.existingFileMode(ctx, absPath, defaultFileMode)); err != nil {
+		return message.NewToolResultError(fmt.Sprintf("failed to write file %s: %v", absPath, err)), nil
+	}
+
+	// Update read timestamp after successful edit to allow sequential edits
+	m.recordFileRead(absPath)
+
+	validationResult := m.autoValidateFile(ctx, absPath)
+
+	return message.NewToolResultText(fmt.Sprintf("Successfully edited %s\nReplaced lines %d-%d (%d line(s)) with %d line(s)\nFile now has %d line(s)%s",
+		absPath, startLine, endLine, endLine-startLine+1, len(replacement), len(updated), validationResult)), nil
+}
+
 // handleRead implements Read with optional paging and line numbering
 func (m *FileSystemToolManager) handleRead(ctx context.Context, args message.ToolArgumentValues) (message.ToolResult, error) {
 	pathParam, ok := args["file_path"].(string)
@@ -558,6 +921,10 @@ func (m *FileSystemToolManager) handleRead(ctx context.Context, args message.Too
 	// Record successful read
 	m.recordFileRead(path)
 
+	if result, ok := m.binaryReadResult(path, contentBytes, args); ok {
+		return result, nil
+	}
+
 	content := string(contentBytes)
 	lines := strings.Split(content, "\n")
 
@@ -598,16 +965,41 @@ func (m *FileSystemToolManager) handleRead(ctx context.Context, args message.Too
 		end = start
 	}
 
+	maxBytes := m.maxReadBytes
+	if v, ok := args["max_bytes"].(float64); ok && v > 0 {
+		maxBytes = int(v)
+	}
+
 	// Line-numbered output (cat -n style: spaces + line + tab + content)
 	var b strings.Builder
-	ln := start + 1
 	for i := start; i < end; i++ {
-		b.WriteString(fmt.Sprintf("%6d\t%s\n", ln, lines[i]))
-		ln++
+		line := fmt.Sprintf("%6d\t%s\n", i+1, lines[i])
+		if b.Len()+len(line) > maxBytes {
+			if i == start {
+				// A single oversized line is cut rather than returning nothing
+				b.WriteString(truncateLine(line, maxBytes) + "\n")
+				i++
+			}
+			b.WriteString(fmt.Sprintf("\n[Output truncated at %d bytes: showing lines %d-%d of %d (file is %d bytes). Call Read again with offset=%d and a limit to continue.]\n",
+				maxBytes, start+1, i, len(lines), len(contentBytes), i+1))
+			break
+		}
+		b.WriteString(line)
 	}
 	return message.NewToolResultText(b.String()), nil
 }
 
+// truncateLine cuts s to at most max bytes without splitting a UTF-8 character
+func truncateLine(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	for max > 0 && !utf8.RuneStart(s[max]) {
+		max--
+	}
+	return s[:max]
+}
+
 // handleWrite implements Write
 func (m *FileSystemToolManager) handleWrite(ctx context.Context, args message.ToolArgumentValues) (message.ToolResult, error) {
 	pathParam, ok := args["file_path"].(string)
@@ -620,6 +1012,7 @@ func (m *FileSystemToolManager) handleWrite(ctx context.Context, args message.To
 	return m.handleWriteFile(ctx, message.ToolArgumentValues{
 		"path":    pathParam,
 		"content": args["content"],
+		"mode":    args["mode"],
 	})
 }
 
@@ -673,7 +1066,7 @@ func (m *FileSystemToolManager) handleLS(ctx context.Context, args message.ToolA
 	b.WriteString(fmt.Sprintf("Contents of %s:\n", path))
 	for _, e := range entries {
 		name := e.Name()
-		if matchesIgnore(name) {
+		if matchesIgnore(name) || m.ignore.matches(filepath.Join(path, name), e.IsDir()) {
 			continue
 		}
 		if e.IsDir() {
@@ -760,194 +1153,352 @@ func (m *FileSystemToolManager) handleMultiEdit(ctx context.Context, args messag
 	return message.NewToolResultText("MultiEdit results:\n" + strings.Join(results, "\n")), nil
 }
 
-// fileSystemTool is a helper struct for filesystem tool registration
-type fileSystemTool struct {
-	name        message.ToolName
-	description message.ToolDescription
-	arguments   []message.ToolArgument
-	handler     func(ctx context.Context, args message.ToolArgumentValues) (message.ToolResult, error)
-}
+const (
+	defaultGrepMaxMatches = 100
+	maxGrepOutputChars    = 20000
+	maxGrepFileSize       = 1 << 20 // skip files larger than 1MB
 
-func (t *fileSystemTool) RawName() message.ToolName {
-	return t.name
-}
-
-func (t *fileSystemTool) Name() message.ToolName {
-	return t.name
-}
-
-func (t *fileSystemTool) Description() message.ToolDescription {
-	return t.description
-}
-
-func (t *fileSystemTool) Arguments() []message.ToolArgument {
-	return t.arguments
-}
-
-func (t *fileSystemTool) Handler() func(ctx context.Context, args message.ToolArgumentValues) (message.ToolResult, error) {
-	return t.handler
-}
-
-// ValidationResult represents the result of a Go validation check
-type ValidationResult struct {
-	Check   string `json:"check"`
-	Status  string `json:"status"` // "pass", "fail", "error"
-	Output  string `json:"output,omitempty"`
-	Summary string `json:"summary"`
-}
+	defaultTreeMaxDepth = 3
+	maxTreeEntries      = 500
+)
 
-// autoValidateFile performs automatic validation after write/edit operations based on file type
-func (m *FileSystemToolManager) autoValidateFile(ctx context.Context, filePath string) string {
-	// Get file extension
-	ext := strings.ToLower(filepath.Ext(filePath))
+// handleGrepContent searches file contents for a regex and reports matches with context
+func (m *FileSystemToolManager) handleGrepContent(ctx context.Context, args message.ToolArgumentValues) (message.ToolResult, error) {
+	pattern, ok := args["pattern"].(string)
+	if !ok || pattern == "" {
+		return message.NewToolResultError("pattern parameter is required"), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return message.NewToolResultError(fmt.Sprintf("invalid pattern: %v", err)), nil
+	}
 
-	switch ext {
-	case ".go":
-		return m.autoValidateGoFile(ctx, filePath)
-	default:
-		// No validation available for this file type
-		return ""
+	pathParam, _ := args["path"].(string)
+	if pathParam == "" {
+		pathParam = "."
+	}
+	root, resolveErr := m.resolvePath(pathParam)
+	if resolveErr != nil {
+		return message.NewToolResultError(fmt.Sprintf("failed to resolve path: %v", resolveErr)), nil
+	}
+	if err := m.isPathAllowed(root); err != nil {
+		return message.NewToolResultError(err.Error()), nil
 	}
-}
 
-// autoValidateGoFile performs automatic Go validation after write/edit operations
-func (m *FileSystemToolManager) autoValidateGoFile(ctx context.Context, filePath string) string {
-	// Find the directory containing go files
-	dir := filepath.Dir(filePath)
-	fileName := filepath.Base(filePath)
+	contextLines := 0
+	if v, ok := args["context_lines"].(float64); ok && v > 0 {
+		contextLines = int(v)
+	}
+	maxMatches := defaultGrepMaxMatches
+	if v, ok := args["max_matches"].(float64); ok && v > 0 {
+		maxMatches = int(v)
+	}
 
-	// Check if this looks like a Go project (has .go files)
-	hasGoFiles, err := m.hasGoFilesInDirectory(ctx, dir)
-	if err != nil || !hasGoFiles {
-		return ""
+	files, err := m.collectSearchableFiles(ctx, root)
+	if err != nil {
+		return message.NewToolResultError(fmt.Sprintf("failed to search: %v", err)), nil
 	}
 
-	results := []ValidationResult{}
+	var b strings.Builder
+	totalMatches, shown := 0, 0
+	truncated := false
+	for _, file := range files {
+		if ctx.Err() != nil {
+			return message.NewToolResultError("search cancelled"), nil
+		}
+		content, err := m.fsRepo.ReadFile(ctx, file)
+		if err != nil || isBinaryContent(content) {
+			continue
+		}
+
+		rel, relErr := filepath.Rel(m.workingDir, file)
+		if relErr != nil {
+			rel = file
+		}
+		lines := strings.Split(string(content), "\n")
+		lastPrinted := -1
+		for i, line := range lines {
+			if !re.MatchString(line) {
+				continue
+			}
+			totalMatches++
+			if shown >= maxMatches || truncated {
+				continue
+			}
 
-	// Run go vet on the specific file
-	vetResult := m.runGoVet(ctx, dir, fileName)
-	results = append(results, vetResult)
+			var group strings.Builder
+			start := max(i-contextLines, lastPrinted+1)
+			end := min(i+contextLines, len(lines)-1)
+			// Separate non-contiguous context groups like grep does
+			if contextLines > 0 && b.Len() > 0 && (lastPrinted < 0 || start > lastPrinted+1) {
+				group.WriteString("--\n")
+			}
+			for j := start; j <= end; j++ {
+				sep := "-"
+				if re.MatchString(lines[j]) {
+					sep = ":"
+				}
+				group.WriteString(fmt.Sprintf("%s%s%d%s %s\n", rel, sep, j+1, sep, lines[j]))
+			}
 
-	// Run go build -n (dry run) on the specific file
-	buildResult := m.runGoBuild(ctx, dir, fileName)
-	results = append(results, buildResult)
+			if b.Len()+group.Len() > maxGrepOutputChars {
+				truncated = true
+				continue
+			}
+			b.WriteString(group.String())
+			lastPrinted = end
+			shown++
+		}
+	}
 
-	// Format validation results
-	return m.formatValidationResults(results)
+	if totalMatches == 0 {
+		return message.NewToolResultText(fmt.Sprintf("No matches found for pattern %q", pattern)), nil
+	}
+	if omitted := totalMatches - shown; omitted > 0 {
+		b.WriteString(fmt.Sprintf("\n... %d more matches omitted (showing %d of %d). Narrow the pattern or path.\n", omitted, shown, totalMatches))
+	}
+	return message.NewToolResultText(b.String()), nil
 }
 
-// hasGoFilesInDirectory checks if directory contains .go files
-func (m *FileSystemToolManager) hasGoFilesInDirectory(ctx context.Context, dir string) (bool, error) {
-	entries, err := m.fsRepo.ReadDir(ctx, dir)
+// collectSearchableFiles returns regular files under root (or root itself) that pass
+// the allowed-directory and blacklist checks, skipping VCS and dependency directories
+func (m *FileSystemToolManager) collectSearchableFiles(ctx context.Context, root string) ([]string, error) {
+	isDir, err := m.fsRepo.IsDir(ctx, root)
 	if err != nil {
-		return false, err
+		return nil, err
+	}
+	if !isDir {
+		if err := m.isFileBlacklisted(root); err != nil {
+			return nil, err
+		}
+		return []string{root}, nil
 	}
 
-	for _, entry := range entries {
-		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".go") {
-			return true, nil
+	var files []string
+	var walk func(dir string) error
+	walk = func(dir string) error {
+		entries, err := m.fsRepo.ReadDir(ctx, dir)
+		if err != nil {
+			return err
 		}
+		for _, e := range entries {
+			full := filepath.Join(dir, e.Name())
+			if e.IsDir() {
+				if skipSearchDir(e.Name()) || m.ignore.matches(full, true) {
+					continue
+				}
+				if err := walk(full); err != nil {
+					return err
+				}
+				continue
+			}
+			if !e.Type().IsRegular() {
+				continue
+			}
+			if m.isPathAllowed(full) != nil || m.isFileBlacklisted(full) != nil {
+				continue
+			}
+			if info, err := e.Info(); err == nil && info.Size() > maxGrepFileSize {
+				continue
+			}
+			files = append(files, full)
+		}
+		return nil
+	}
+	if err := walk(root); err != nil {
+		return nil, err
 	}
-	return false, nil
+	return files, nil
 }
 
-// runGoVet executes go vet and returns the result
-func (m *FileSystemToolManager) runGoVet(ctx context.Context, dir string, fileName string) ValidationResult {
-	result := ValidationResult{
-		Check: "go vet - Static analysis to find suspicious constructs",
+// handleDirectoryTree renders an indented tree of a directory up to max_depth
+func (m *FileSystemToolManager) handleDirectoryTree(ctx context.Context, args message.ToolArgumentValues) (message.ToolResult, error) {
+	pathParam, _ := args["path"].(string)
+	if pathParam == "" {
+		pathParam = "."
+	}
+	root, resolveErr := m.resolvePath(pathParam)
+	if resolveErr != nil {
+		return message.NewToolResultError(fmt.Sprintf("failed to resolve path: %v", resolveErr)), nil
+	}
+	if err := m.isPathAllowed(root); err != nil {
+		return message.NewToolResultError(err.Error()), nil
+	}
+	if isDir, err := m.fsRepo.IsDir(ctx, root); err != nil || !isDir {
+		return message.NewToolResultError(fmt.Sprintf("not a directory: %s", root)), nil
 	}
 
-	// Validate the specific file that was written/edited
-	cmd := exec.CommandContext(ctx, "go", "vet", fileName)
-	cmd.Dir = dir
-
-	output, err := cmd.CombinedOutput()
-	outputStr := strings.TrimSpace(string(output))
+	maxDepth := defaultTreeMaxDepth
+	if v, ok := args["max_depth"].(float64); ok && v > 0 {
+		maxDepth = int(v)
+	}
 
-	if err != nil {
-		if outputStr != "" {
-			result.Status = "fail"
-			result.Output = outputStr
-			lines := strings.Split(outputStr, "\n")
-			result.Summary = fmt.Sprintf("Found %d vet issues", len(lines))
-		} else {
-			result.Status = "error"
-			result.Output = err.Error()
-			result.Summary = fmt.Sprintf("Could not run go vet: %v", err)
+	excludes := m.loadGitignorePatterns(ctx, root)
+	if raw, ok := args["exclude"].([]interface{}); ok {
+		for _, v := range raw {
+			if s, ok := v.(string); ok && s != "" {
+				excludes = append(excludes, s)
+			}
 		}
-	} else {
-		result.Status = "pass"
-		result.Summary = "No vet issues found"
 	}
 
-	return result
-}
+	var b strings.Builder
+	b.WriteString(root + "/\n")
+	entries := 0
+	truncated := false
 
-// runGoBuild executes go build -n (dry run) and returns the result
-func (m *FileSystemToolManager) runGoBuild(ctx context.Context, dir string, fileName string) ValidationResult {
-	result := ValidationResult{
-		Check: "go build -n - Check if code compiles without building",
+	var walk func(dir string, depth int, indent string) error
+	walk = func(dir string, depth int, indent string) error {
+		children, err := m.fsRepo.ReadDir(ctx, dir)
+		if err != nil {
+			return err
+		}
+		for _, e := range children {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			name := e.Name()
+			if strings.HasPrefix(name, ".") || (e.IsDir() && skipSearchDir(name)) {
+				continue
+			}
+			full := filepath.Join(dir, name)
+			rel, _ := filepath.Rel(root, full)
+			// Blacklisted files are hidden here as from every other tool
+			if matchesExcludePattern(excludes, rel, e.IsDir()) || m.ignore.matches(full, e.IsDir()) || m.matchBlacklist(full) != nil {
+				continue
+			}
+			if entries >= maxTreeEntries {
+				truncated = true
+				return nil
+			}
+			entries++
+			if e.IsDir() {
+				b.WriteString(indent + name + "/\n")
+				if depth < maxDepth {
+					if err := walk(full, depth+1, indent+"  "); err != nil {
+						return err
+					}
+					if truncated {
+						return nil
+					}
+				}
+			} else {
+				b.WriteString(indent + name + "\n")
+			}
+		}
+		return nil
+	}
+	if err := walk(root, 1, "  "); err != nil {
+		return message.NewToolResultError(fmt.Sprintf("failed to read directory: %v", err)), nil
 	}
 
-	// Validate the specific file that was written/edited
-	cmd := exec.CommandContext(ctx, "go", "build", "-n", fileName)
-	cmd.Dir = dir
-
-	output, err := cmd.CombinedOutput()
-	outputStr := strings.TrimSpace(string(output))
+	if truncated {
+		b.WriteString(fmt.Sprintf("... output truncated at %d entries; use a narrower path or smaller max_depth\n", maxTreeEntries))
+	}
+	return message.NewToolResultText(b.String()), nil
+}
 
+// loadGitignorePatterns reads simple exclude patterns from root/.gitignore.
+// Negation patterns are not supported and are skipped.
+func (m *FileSystemToolManager) loadGitignorePatterns(ctx context.Context, root string) []string {
+	content, err := m.fsRepo.ReadFile(ctx, filepath.Join(root, ".gitignore"))
 	if err != nil {
-		result.Status = "fail"
-		result.Output = outputStr
-		result.Summary = "Build would fail - compilation errors found"
-	} else {
-		result.Status = "pass"
-		result.Summary = "Code compiles successfully"
+		return nil
 	}
-
-	return result
+	return parseIgnorePatterns(string(content))
 }
 
-// formatValidationResults formats validation results into a readable string
-func (m *FileSystemToolManager) formatValidationResults(results []ValidationResult) string {
-	if len(results) == 0 {
-		return ""
+// matchesExcludePattern applies .gitignore-style matching to a path relative to
+// the tree root. A trailing "/" restricts a pattern to directories,
+// patterns containing "/" match the relative path, and others match the base name.
+func matchesExcludePattern(patterns []string, rel string, isDir bool) bool {
+	base := filepath.Base(rel)
+	for _, pat := range patterns {
+		pat = filepath.FromSlash(pat)
+		if strings.HasSuffix(pat, string(filepath.Separator)) {
+			if !isDir {
+				continue
+			}
+			pat = strings.TrimSuffix(pat, string(filepath.Separator))
+		}
+		if strings.ContainsRune(pat, filepath.Separator) {
+			if ok, _ := filepath.Match(strings.TrimPrefix(pat, string(filepath.Separator)), rel); ok {
+				return true
+			}
+			continue
+		}
+		if ok, _ := filepath.Match(pat, base); ok {
+			return true
+		}
 	}
+	return false
+}
 
-	var output strings.Builder
-	output.WriteString("\n\nGo Validation Results:\n")
+// skipSearchDir reports whether a directory should be excluded from content search
+func skipSearchDir(name string) bool {
+	switch name {
+	case ".git", "node_modules", "vendor", ".hg", ".svn":
+		return true
+	}
+	return false
+}
 
-	passed := 0
-	failed := 0
+// isBinaryContent detects binary data by a NUL byte in the first 8KB. Text in
+// a legacy encoding such as Latin-1 passes, so it can still be searched and
+// edited.
+func isBinaryContent(content []byte) bool {
+	return bytes.IndexByte(content[:min(len(content), 8192)], 0) >= 0
+}
 
-	for _, result := range results {
-		switch result.Status {
-		case "pass":
-			output.WriteString(fmt.Sprintf("PASS: %s: %s\n", result.Check, result.Summary))
-			passed++
-		case "fail":
-			output.WriteString(fmt.Sprintf("FAIL: %s: %s\n", result.Check, result.Summary))
-			if result.Output != "" {
-				// Limit output to prevent overwhelming response
-				lines := strings.Split(result.Output, "\n")
-				if len(lines) > 5 {
-					output.WriteString(fmt.Sprintf("```\n%s\n... (%d more lines)\n```\n",
-						strings.Join(lines[:5], "\n"), len(lines)-5))
-				} else {
-					output.WriteString(fmt.Sprintf("```\n%s\n```\n", result.Output))
-				}
+// mostlyInvalidUTF8 reports whether more than 10% of the first 8KB is not
+// valid UTF-8, as in a zip without NULs in its header. Read summarizes such
+// files instead of showing them.
+func mostlyInvalidUTF8(content []byte) bool {
+	sample := content[:min(len(content), 8192)]
+	invalid := 0
+	for i := 0; i < len(sample); {
+		r, size := utf8.DecodeRune(sample[i:])
+		if r == utf8.RuneError && size == 1 {
+			// A character cut off by the end of the sample is not invalid
+			if len(sample) < len(content) && !utf8.FullRune(sample[i:]) {
+				break
 			}
-			failed++
-		case "error":
-			output.WriteString(fmt.Sprintf("ERROR: %s: %s\n", result.Check, result.Summary))
+			invalid++
 		}
+		i += size
 	}
+	return invalid*10 > len(sample)
+}
 
-	if failed == 0 {
-		output.WriteString(fmt.Sprintf("\nAll %d validation checks passed.\n", passed))
-	} else {
-		output.WriteString(fmt.Sprintf("\nValidation Summary: %d passed, %d failed.\n", passed, failed))
-	}
+// fileSystemTool is a helper struct for filesystem tool registration
+type fileSystemTool struct {
+	name        message.ToolName
+	description message.ToolDescription
+	arguments   []message.ToolArgument
+	handler     func(ctx context.Context, args message.ToolArgumentValues) (message.ToolResult, error)
+	effects     message.ToolEffects
+}
+
+func (t *fileSystemTool) RawName() message.ToolName {
+	return t.name
+}
+
+func (t *fileSystemTool) Name() message.ToolName {
+	return t.name
+}
+
+func (t *fileSystemTool) Description() message.ToolDescription {
+	return t.description
+}
+
+func (t *fileSystemTool) Arguments() []message.ToolArgument {
+	return t.arguments
+}
+
+func (t *fileSystemTool) Handler() func(ctx context.Context, args message.ToolArgumentValues) (message.ToolResult, error) {
+	return t.handler
+}
 
-	return output.String()
+func (t *fileSystemTool) Effects() message.ToolEffects {
+	return t.effects
 }
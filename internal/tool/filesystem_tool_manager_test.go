@@ -2,6 +2,7 @@ package tool
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"path/filepath"
 	"reflect"
@@ -107,6 +108,28 @@ func TestFileSystemToolManager_SecurityFeatures(t *testing.T) {
 		}
 	})
 
+	t.Run("BlacklistedFileWrite", func(t *testing.T) {
+		// Blacklisted files can't be overwritten or created either
+		for _, path := range []string{secretFile, filepath.Join(allowedSubDir, "new.env")} {
+			result, err := manager.handleWriteFile(ctx, map[string]any{
+				"path":    path,
+				"content": "API_KEY=overwritten",
+			})
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+			if !strings.Contains(result.Error, "blacklisted") {
+				t.Errorf("Expected blacklist error writing %s, got: %+v", path, result)
+			}
+		}
+		if content, _ := os.ReadFile(secretFile); string(content) != "API_KEY=secret123" {
+			t.Errorf("Blacklisted file was modified: %q", content)
+		}
+		if _, err := os.Stat(filepath.Join(allowedSubDir, "new.env")); !os.IsNotExist(err) {
+			t.Errorf("Blacklisted file was created: %v", err)
+		}
+	})
+
 	t.Run("ReadWriteSemantics", func(t *testing.T) {
 		// Test 1: Writing a new file should succeed without prior read
 		newFile := filepath.Join(allowedSubDir, "new_file.txt")
@@ -258,6 +281,21 @@ func TestFileSystemToolManager_ToolRegistration(t *testing.T) {
 		"Edit",
 		"LS",
 		"MultiEdit",
+		"grep_content",
+		"directory_tree",
+		"summarize_path",
+		"replace_lines",
+		"apply_patch",
+		"query_data",
+		"undo_last_edit",
+		"copy_file",
+		"move_file",
+		"delete_file",
+		"replace_across_files",
+		"read_many_files",
+		"diff_files",
+		"file_head",
+		"file_tail",
 	}
 
 	toolsMap := manager.GetTools()
@@ -549,3 +587,301 @@ func TestFileSystemToolManager_IsPathAllowed(t *testing.T) {
 		}
 	})
 }
+
+func TestFileSystemToolManager_GrepContent(t *testing.T) {
+	tempDir := t.TempDir()
+	files := map[string]string{
+		"main.go":           "package main\n\nfunc main() {\n\tfmt.Println(\"hello\")\n}\n",
+		"sub/util.go":       "package sub\n\n// TODO: refactor\nfunc Helper() {}\n",
+		"secret.env":        "TODO=leak\n",
+		".git/config":       "TODO in git\n",
+		"sub/many_todos.md": strings.Repeat("TODO item\n", 5),
+	}
+	for name, content := range files {
+		path := filepath.Join(tempDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	config := repository.FileSystemConfig{
+		AllowedDirectories: []string{tempDir},
+		BlacklistedFiles:   []string{"*.env"},
+	}
+	manager := NewFileSystemToolManager(infra.NewOSFilesystemRepository(), config, tempDir)
+	ctx := context.Background()
+
+	t.Run("match with context", func(t *testing.T) {
+		result, err := manager.CallTool(ctx, "grep_content", message.ToolArgumentValues{
+			"pattern":       "Println",
+			"context_lines": float64(1),
+		})
+		if err != nil || result.Error != "" {
+			t.Fatalf("unexpected error: %v %s", err, result.Error)
+		}
+		for _, want := range []string{"main.go-3- func main() {", "main.go:4: \tfmt.Println(\"hello\")", "main.go-5- }"} {
+			if !strings.Contains(result.Text, want) {
+				t.Errorf("expected %q in output:\n%s", want, result.Text)
+			}
+		}
+	})
+
+	t.Run("skips blacklisted and vcs files and reports omitted", func(t *testing.T) {
+		result, err := manager.CallTool(ctx, "grep_content", message.ToolArgumentValues{
+			"pattern":     "TODO",
+			"max_matches": float64(2),
+		})
+		if err != nil || result.Error != "" {
+			t.Fatalf("unexpected error: %v %s", err, result.Error)
+		}
+		if strings.Contains(result.Text, "secret.env") || strings.Contains(result.Text, ".git") {
+			t.Errorf("blacklisted or VCS files should be skipped:\n%s", result.Text)
+		}
+		if !strings.Contains(result.Text, "4 more matches omitted") {
+			t.Errorf("expected omitted match count in output:\n%s", result.Text)
+		}
+	})
+
+	t.Run("rejects paths outside allowed directories", func(t *testing.T) {
+		result, _ := manager.CallTool(ctx, "grep_content", message.ToolArgumentValues{
+			"pattern": "x",
+			"path":    filepath.Dir(tempDir),
+		})
+		if result.Error == "" {
+			t.Errorf("expected error for path outside working directory")
+		}
+	})
+}
+
+func TestFileSystemToolManager_DirectoryTree(t *testing.T) {
+	tempDir := t.TempDir()
+	for _, name := range []string{
+		"main.go",
+		"cmd/app/main.go",
+		"cmd/app/deep/nested/file.go",
+		"node_modules/pkg/index.js",
+		".git/config",
+		"build/output.bin",
+		"debug.log",
+		"cmd/app/prod.env",
+	} {
+		path := filepath.Join(tempDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, ".gitignore"), []byte("# build artifacts\nbuild/\n*.log\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	config := repository.FileSystemConfig{AllowedDirectories: []string{tempDir}, BlacklistedFiles: []string{"*.env"}}
+	manager := NewFileSystemToolManager(infra.NewOSFilesystemRepository(), config, tempDir)
+	ctx := context.Background()
+
+	result, err := manager.CallTool(ctx, "directory_tree", message.ToolArgumentValues{"max_depth": float64(3)})
+	if err != nil || result.Error != "" {
+		t.Fatalf("unexpected error: %v %s", err, result.Error)
+	}
+	for _, want := range []string{"  cmd/\n", "    app/\n", "      deep/\n", "      main.go\n", "  main.go\n"} {
+		if !strings.Contains(result.Text, want) {
+			t.Errorf("expected %q in output:\n%s", want, result.Text)
+		}
+	}
+	for _, unwanted := range []string{"nested", "node_modules", ".git", "build", "debug.log", "prod.env"} {
+		if strings.Contains(result.Text, unwanted) {
+			t.Errorf("did not expect %q in output:\n%s", unwanted, result.Text)
+		}
+	}
+
+	result, _ = manager.CallTool(ctx, "directory_tree", message.ToolArgumentValues{
+		"max_depth": float64(1),
+		"exclude":   []interface{}{"main.go"},
+	})
+	if strings.Contains(result.Text, "main.go") || strings.Contains(result.Text, "app/") {
+		t.Errorf("expected depth-limited tree without excluded files:\n%s", result.Text)
+	}
+}
+
+func TestFileSystemToolManager_ReplaceLines(t *testing.T) {
+	tempDir := t.TempDir()
+	path := filepath.Join(tempDir, "notes.txt")
+	if err := os.WriteFile(path, []byte("one\ntwo\nthree\nfour\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	config := repository.FileSystemConfig{AllowedDirectories: []string{tempDir}}
+	manager := NewFileSystemToolManager(infra.NewOSFilesystemRepository(), config, tempDir)
+	ctx := context.Background()
+
+	args := message.ToolArgumentValues{
+		"file_path":   path,
+		"start_line":  float64(2),
+		"end_line":    float64(3),
+		"new_content": "TWO\nTHREE\nTHREE-B\n",
+	}
+
+	// Read-write semantics: the file must be read first
+	result, _ := manager.CallTool(ctx, "replace_lines", args)
+	if !strings.Contains(result.Error, "not read before write") {
+		t.Fatalf("expected read-before-write error, got %q", result.Error)
+	}
+
+	if result, _ := manager.CallTool(ctx, "Read", message.ToolArgumentValues{"file_path": path}); result.Error != "" {
+		t.Fatalf("read failed: %s", result.Error)
+	}
+	result, err := manager.CallTool(ctx, "replace_lines", args)
+	if err != nil || result.Error != "" {
+		t.Fatalf("unexpected error: %v %s", err, result.Error)
+	}
+	if !strings.Contains(result.Text, "Replaced lines 2-3 (2 line(s)) with 3 line(s)") {
+		t.Errorf("expected change statistics, got:\n%s", result.Text)
+	}
+	data, _ := os.ReadFile(path)
+	if string(data) != "one\nTWO\nTHREE\nTHREE-B\nfour\n" {
+		t.Errorf("unexpected content: %q", string(data))
+	}
+
+	// Out-of-range requests are rejected without modifying the file
+	result, _ = manager.CallTool(ctx, "replace_lines", message.ToolArgumentValues{
+		"file_path":   path,
+		"start_line":  float64(4),
+		"end_line":    float64(9),
+		"new_content": "x",
+	})
+	if !strings.Contains(result.Error, "invalid line range") {
+		t.Errorf("expected line range error, got %q", result.Error)
+	}
+
+	// Empty new_content deletes the range
+	result, _ = manager.CallTool(ctx, "replace_lines", message.ToolArgumentValues{
+		"file_path":   path,
+		"start_line":  float64(1),
+		"end_line":    float64(1),
+		"new_content": "",
+	})
+	if result.Error != "" {
+		t.Fatalf("unexpected error: %s", result.Error)
+	}
+	data, _ = os.ReadFile(path)
+	if string(data) != "TWO\nTHREE\nTHREE-B\nfour\n" {
+		t.Errorf("unexpected content after delete: %q", string(data))
+	}
+}
+
+func TestFileSystemToolManager_ReadTruncatesLargeFiles(t *testing.T) {
+	tempDir := t.TempDir()
+	path := filepath.Join(tempDir, "big.log")
+	var lines []string
+	for i := 1; i <= 100; i++ {
+		lines = append(lines, fmt.Sprintf("log line %03d", i))
+	}
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	config := repository.FileSystemConfig{AllowedDirectories: []string{tempDir}, MaxReadBytes: 200}
+	manager := NewFileSystemToolManager(infra.NewOSFilesystemRepository(), config, tempDir)
+	ctx := context.Background()
+
+	result, err := manager.CallTool(ctx, "Read", message.ToolArgumentValues{"file_path": path})
+	if err != nil || result.Error != "" {
+		t.Fatalf("unexpected error: %v %s", err, result.Error)
+	}
+	if !strings.Contains(result.Text, "log line 001") || strings.Contains(result.Text, "log line 100") {
+		t.Errorf("expected only the start of the file:\n%s", result.Text)
+	}
+	if !strings.Contains(result.Text, "Output truncated") || !strings.Contains(result.Text, "offset=") {
+		t.Errorf("expected a paging notice:\n%s", result.Text)
+	}
+
+	// Paging continues past the truncation point
+	result, _ = manager.CallTool(ctx, "Read", message.ToolArgumentValues{"file_path": path, "offset": float64(99), "limit": float64(2)})
+	if strings.Contains(result.Text, "truncated") || !strings.Contains(result.Text, "   100\tlog line 100") {
+		t.Errorf("expected the last lines without truncation:\n%s", result.Text)
+	}
+
+	// max_bytes overrides the configured limit
+	result, _ = manager.CallTool(ctx, "Read", message.ToolArgumentValues{"file_path": path, "max_bytes": float64(10000)})
+	if strings.Contains(result.Text, "truncated") || !strings.Contains(result.Text, "log line 100") {
+		t.Errorf("expected the whole file with a larger max_bytes:\n%s", result.Text)
+	}
+
+	// A truncated read still satisfies read-before-write for the whole file
+	result, _ = manager.CallTool(ctx, "Write", message.ToolArgumentValues{"file_path": path, "content": "replaced\n"})
+	if result.Error != "" {
+		t.Errorf("expected write after truncated read to succeed: %s", result.Error)
+	}
+}
+
+func TestFileSystemToolManager_IsBlacklisted(t *testing.T) {
+	dir := t.TempDir()
+	manager := NewFileSystemToolManager(infra.NewOSFilesystemRepository(), infra.DefaultFileSystemConfig(dir), dir)
+
+	for path, want := range map[string]bool{
+		".env":                           true,
+		"config/.env.production":         true,
+		filepath.Join(dir, "server.pem"): true,
+		"main.go":                        false,
+		"docs/README.md":                 false,
+	} {
+		if got := manager.IsBlacklisted(path); got != want {
+			t.Errorf("IsBlacklisted(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+func TestFileSystemToolManager_RequireReadBeforeWriteOff(t *testing.T) {
+	tempDir := t.TempDir()
+	path := filepath.Join(tempDir, "main.go")
+	if err := os.WriteFile(path, []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	manager := NewFileSystemToolManager(infra.NewOSFilesystemRepository(), infra.DefaultFileSystemConfig(tempDir), tempDir)
+	manager.SetRequireReadBeforeWrite(false)
+	ctx := context.Background()
+
+	// Unread files can be overwritten and edited
+	result, _ := manager.CallTool(ctx, "Write", message.ToolArgumentValues{"file_path": path, "content": "package app\n"})
+	if result.Error != "" {
+		t.Fatalf("expected write without a read to succeed: %s", result.Error)
+	}
+	result, _ = manager.CallTool(ctx, "Edit", message.ToolArgumentValues{"file_path": path, "old_string": "app", "new_string": "tool"})
+	if result.Error != "" {
+		t.Fatalf("expected edit without a read to succeed: %s", result.Error)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "package tool\n" {
+		t.Errorf("unexpected content: %q", string(data))
+	}
+
+	// Allowed directories and the blacklist still apply
+	envPath := filepath.Join(tempDir, ".env")
+	if err := os.WriteFile(envPath, []byte("TOKEN=x\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	result, _ = manager.CallTool(ctx, "Edit", message.ToolArgumentValues{"file_path": envPath, "old_string": "x", "new_string": "y"})
+	if !strings.Contains(result.Error, "blacklisted") {
+		t.Errorf("expected a blacklisted edit to be rejected, got %q", result.Error)
+	}
+	result, _ = manager.CallTool(ctx, "Write", message.ToolArgumentValues{"file_path": filepath.Join(t.TempDir(), "out.go"), "content": "package out\n"})
+	if result.Error == "" {
+		t.Error("expected a write outside the allowed directories to be rejected")
+	}
+
+	// Turning the check back on restores read-before-write
+	manager.SetRequireReadBeforeWrite(true)
+	other := filepath.Join(tempDir, "other.go")
+	if err := os.WriteFile(other, []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	result, _ = manager.CallTool(ctx, "Write", message.ToolArgumentValues{"file_path": other, "content": "package other\n"})
+	if !strings.Contains(result.Error, "not read before write") {
+		t.Errorf("expected read-before-write error, got %q", result.Error)
+	}
+}
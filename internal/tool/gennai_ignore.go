@@ -0,0 +1,118 @@
+package tool
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// gennaiIgnoreFile lists per-project paths hidden from filesystem and search tools
+const gennaiIgnoreFile = ".gennaiignore"
+
+// projectIgnore holds the patterns of a project's .gennaiignore, reloading them
+// whenever the file changes
+type projectIgnore struct {
+	root string
+
+	mu       sync.Mutex
+	modTime  time.Time
+	size     int64
+	patterns []string
+}
+
+// newProjectIgnore returns the .gennaiignore matcher for a project root
+func newProjectIgnore(root string) *projectIgnore {
+	if abs, err := filepath.Abs(root); err == nil {
+		root = abs
+	}
+	return &projectIgnore{root: root}
+}
+
+// current returns the patterns, reloading the file if it was added, changed or removed
+func (p *projectIgnore) current() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	info, err := os.Stat(filepath.Join(p.root, gennaiIgnoreFile))
+	if err != nil {
+		p.patterns, p.modTime, p.size = nil, time.Time{}, 0
+		return nil
+	}
+	if info.ModTime().Equal(p.modTime) && info.Size() == p.size {
+		return p.patterns
+	}
+	content, err := os.ReadFile(filepath.Join(p.root, gennaiIgnoreFile))
+	if err != nil {
+		return p.patterns
+	}
+	p.patterns = parseIgnorePatterns(string(content))
+	p.modTime, p.size = info.ModTime(), info.Size()
+	return p.patterns
+}
+
+// matches reports whether an absolute path, or any directory containing it,
+// is excluded. Paths outside the project root never match.
+func (p *projectIgnore) matches(path string, isDir bool) bool {
+	if p == nil {
+		return false
+	}
+	patterns := p.current()
+	if len(patterns) == 0 {
+		return false
+	}
+	rel, err := filepath.Rel(p.root, path)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return false
+	}
+
+	parts := strings.Split(rel, string(filepath.Separator))
+	for i := range parts {
+		prefix := filepath.Join(parts[:i+1]...)
+		last := i == len(parts)-1
+		if matchesExcludePattern(patterns, prefix, !last || isDir) {
+			return true
+		}
+	}
+	return false
+}
+
+// filterLines drops output lines whose leading path is excluded. Relative paths
+// are resolved against base; sep, when non-empty, ends the path within a line
+// (e.g. ":" for grep output).
+func (p *projectIgnore) filterLines(text, base, sep string) string {
+	if p == nil || len(p.current()) == 0 || text == "" {
+		return text
+	}
+	lines := strings.Split(text, "\n")
+	kept := lines[:0]
+	for _, line := range lines {
+		path := line
+		if sep != "" {
+			path, _, _ = strings.Cut(line, sep)
+		}
+		if path != "" && !filepath.IsAbs(path) {
+			path = filepath.Join(base, path)
+		}
+		if path != "" && p.matches(path, false) {
+			continue
+		}
+		kept = append(kept, line)
+	}
+	return strings.Join(kept, "\n")
+}
+
+// parseIgnorePatterns extracts gitignore-style patterns, skipping blank lines,
+// comments and unsupported negations
+func parseIgnorePatterns(content string) []string {
+	var patterns []string
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "!") {
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+	return patterns
+}
@@ -0,0 +1,102 @@
+package tool
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fpt/go-gennai-cli/internal/infra"
+	"github.com/fpt/go-gennai-cli/internal/repository"
+	"github.com/fpt/go-gennai-cli/pkg/message"
+)
+
+func TestProjectIgnore_Matches(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, gennaiIgnoreFile), []byte("# local\nbuild/\n*.secret\ndocs/private/*\n!keep.secret\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ig := newProjectIgnore(root)
+
+	tests := []struct {
+		rel   string
+		isDir bool
+		want  bool
+	}{
+		{"build", true, true},
+		{"build/out/app.bin", false, true},
+		{"build", false, false},
+		{"cmd/api.secret", false, true},
+		{"docs/private/plan.md", false, true},
+		{"docs/public/plan.md", false, false},
+		{"main.go", false, false},
+	}
+	for _, tt := range tests {
+		if got := ig.matches(filepath.Join(root, tt.rel), tt.isDir); got != tt.want {
+			t.Errorf("matches(%q, %v) = %v, want %v", tt.rel, tt.isDir, got, tt.want)
+		}
+	}
+	if ig.matches(filepath.Join(filepath.Dir(root), "other.secret"), false) {
+		t.Error("paths outside the root should never match")
+	}
+}
+
+func TestFileSystemToolManager_GennaiIgnore(t *testing.T) {
+	tempDir := t.TempDir()
+	write := func(rel, content string) {
+		t.Helper()
+		path := filepath.Join(tempDir, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("main.go", "package main // needle\n")
+	write("fixtures/big.json", "{\"needle\": true}\n")
+	write("notes.txt", "needle\n")
+
+	config := repository.FileSystemConfig{AllowedDirectories: []string{tempDir}}
+	manager := NewFileSystemToolManager(infra.NewOSFilesystemRepository(), config, tempDir)
+	ctx := context.Background()
+
+	// Without .gennaiignore everything is visible
+	result, _ := manager.CallTool(ctx, "Read", message.ToolArgumentValues{"file_path": "notes.txt"})
+	if result.Error != "" {
+		t.Fatalf("expected read to succeed before ignore file exists: %s", result.Error)
+	}
+
+	write(gennaiIgnoreFile, "fixtures/\nnotes.txt\n")
+
+	result, _ = manager.CallTool(ctx, "Read", message.ToolArgumentValues{"file_path": "notes.txt"})
+	if !strings.Contains(result.Error, gennaiIgnoreFile) {
+		t.Errorf("expected read of ignored file to be denied, got %+v", result)
+	}
+
+	result, _ = manager.CallTool(ctx, "LS", message.ToolArgumentValues{"path": tempDir})
+	if strings.Contains(result.Text, "fixtures") || strings.Contains(result.Text, "notes.txt") || !strings.Contains(result.Text, "main.go") {
+		t.Errorf("unexpected LS output:\n%s", result.Text)
+	}
+
+	result, _ = manager.CallTool(ctx, "directory_tree", message.ToolArgumentValues{})
+	if strings.Contains(result.Text, "fixtures") || strings.Contains(result.Text, "notes.txt") {
+		t.Errorf("unexpected tree output:\n%s", result.Text)
+	}
+
+	result, _ = manager.CallTool(ctx, "grep_content", message.ToolArgumentValues{"pattern": "needle"})
+	if strings.Contains(result.Text, "big.json") || strings.Contains(result.Text, "notes.txt") || !strings.Contains(result.Text, "main.go") {
+		t.Errorf("unexpected grep output:\n%s", result.Text)
+	}
+
+	// Changes to .gennaiignore are picked up on the next call
+	write(gennaiIgnoreFile, "fixtures/\n")
+	future := time.Now().Add(time.Second)
+	_ = os.Chtimes(filepath.Join(tempDir, gennaiIgnoreFile), future, future)
+	result, _ = manager.CallTool(ctx, "Read", message.ToolArgumentValues{"file_path": "notes.txt"})
+	if result.Error != "" {
+		t.Errorf("expected read to succeed after ignore file changed: %s", result.Error)
+	}
+}
@@ -0,0 +1,253 @@
+package tool
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	pkgLogger "github.com/fpt/go-gennai-cli/pkg/logger"
+	"github.com/fpt/go-gennai-cli/pkg/message"
+)
+
+const (
+	defaultGitLogCount = 10
+	maxGitLogCount     = 100
+)
+
+// GitToolManager provides read-only git inspection tools (status, diff, log).
+// It never runs commands that change the repository.
+type GitToolManager struct {
+	tools       map[message.ToolName]message.Tool
+	workingDir  string
+	maxDuration time.Duration
+}
+
+// GitConfig holds configuration for the git tool manager
+type GitConfig struct {
+	WorkingDir  string        `json:"working_dir"`  // Directory git runs in
+	MaxDuration time.Duration `json:"max_duration"` // Maximum execution time per command (default: 30 seconds)
+}
+
+// NewGitToolManager creates a new git tool manager
+func NewGitToolManager(config GitConfig) *GitToolManager {
+	if config.MaxDuration == 0 {
+		config.MaxDuration = 30 * time.Second
+	}
+
+	manager := &GitToolManager{
+		tools:       make(map[message.ToolName]message.Tool),
+		workingDir:  config.WorkingDir,
+		maxDuration: config.MaxDuration,
+	}
+
+	manager.registerGitTools()
+
+	return manager
+}
+
+// Implement domain.ToolManager interface
+func (m *GitToolManager) GetTool(name message.ToolName) (message.Tool, bool) {
+	tool, exists := m.tools[name]
+	return tool, exists
+}
+
+func (m *GitToolManager) GetTools() map[message.ToolName]message.Tool {
+	return m.tools
+}
+
+func (m *GitToolManager) CallTool(ctx context.Context, name message.ToolName, args message.ToolArgumentValues) (message.ToolResult, error) {
+	tool, exists := m.tools[name]
+	if !exists {
+		return message.NewToolResultError(fmt.Sprintf("tool %s not found", name)), nil
+	}
+
+	handler := tool.Handler()
+	return handler(ctx, args)
+}
+
+// RegisterTool registers a tool that declares no effects (message.AccessUnknown)
+func (m *GitToolManager) RegisterTool(name message.ToolName, description message.ToolDescription, args []message.ToolArgument, handler func(ctx context.Context, args message.ToolArgumentValues) (message.ToolResult, error)) {
+	m.registerTool(name, message.ToolEffects{}, description, args, handler)
+}
+
+// registerTool registers a built-in tool with the effects it declares
+func (m *GitToolManager) registerTool(name message.ToolName, effects message.ToolEffects, description message.ToolDescription, args []message.ToolArgument, handler func(ctx context.Context, args message.ToolArgumentValues) (message.ToolResult, error)) {
+	tool := &gitTool{
+		name:        name,
+		description: description,
+		arguments:   args,
+		handler:     handler,
+		effects:     effects,
+	}
+	m.tools[name] = tool
+}
+
+// registerGitTools registers the read-only git tools
+func (m *GitToolManager) registerGitTools() {
+	m.registerTool("git_status", message.UncachedRead, "Show the current branch and changed, staged and untracked files (git status --short --branch). Read-only.",
+		[]message.ToolArgument{},
+		m.handleGitStatus)
+
+	m.registerTool("git_diff", message.UncachedRead, "Show uncommitted changes as a unified diff, optionally limited to a path or to staged changes. Read-only.",
+		[]message.ToolArgument{
+			{
+				Name:        "path",
+				Description: "File or directory to limit the diff to (default: whole repository)",
+				Required:    false,
+				Type:        "string",
+			},
+			{
+				Name:        "staged",
+				Description: "Show staged changes (git diff --staged) instead of unstaged ones",
+				Required:    false,
+				Type:        "boolean",
+			},
+		},
+		m.handleGitDiff)
+
+	m.registerTool("git_log", message.UncachedRead, "Show recent commits as one line each (hash, date, author, subject). Read-only.",
+		[]message.ToolArgument{
+			{
+				Name:        "count",
+				Description: message.ToolDescription(fmt.Sprintf("Number of commits to show (default: %d, max: %d)", defaultGitLogCount, maxGitLogCount)),
+				Required:    false,
+				Type:        "number",
+			},
+			{
+				Name:        "path",
+				Description: "Only show commits touching this file or directory",
+				Required:    false,
+				Type:        "string",
+			},
+		},
+		m.handleGitLog)
+}
+
+func (m *GitToolManager) handleGitStatus(ctx context.Context, args message.ToolArgumentValues) (message.ToolResult, error) {
+	output, result := m.runGit(ctx, "status", "--short", "--branch")
+	if result != nil {
+		return *result, nil
+	}
+	// Only the branch line means nothing changed
+	if !strings.Contains(output, "\n") {
+		output += "\nWorking tree clean"
+	}
+	return message.NewToolResultText(output), nil
+}
+
+func (m *GitToolManager) handleGitDiff(ctx context.Context, args message.ToolArgumentValues) (message.ToolResult, error) {
+	gitArgs := []string{"diff", "--no-color", "--no-ext-diff"}
+	staged, _ := args["staged"].(bool)
+	if staged {
+		gitArgs = append(gitArgs, "--staged")
+	}
+	path, _ := args["path"].(string)
+	if path != "" {
+		if strings.HasPrefix(path, "-") {
+			return message.NewToolResultError("path must be a file or directory, not a git option"), nil
+		}
+		gitArgs = append(gitArgs, "--", path)
+	}
+
+	output, result := m.runGit(ctx, gitArgs...)
+	if result != nil {
+		return *result, nil
+	}
+	if output == "" {
+		if staged {
+			return message.NewToolResultText("No staged changes"), nil
+		}
+		return message.NewToolResultText("No unstaged changes (use staged=true to see staged changes)"), nil
+	}
+	return message.NewToolResultText(output), nil
+}
+
+func (m *GitToolManager) handleGitLog(ctx context.Context, args message.ToolArgumentValues) (message.ToolResult, error) {
+	count := defaultGitLogCount
+	if n, ok := args["count"].(float64); ok && n > 0 {
+		count = min(int(n), maxGitLogCount)
+	}
+	gitArgs := []string{"log", "--no-color", fmt.Sprintf("--max-count=%d", count), "--date=short", "--pretty=format:%h %ad %an: %s"}
+	path, _ := args["path"].(string)
+	if path != "" {
+		if strings.HasPrefix(path, "-") {
+			return message.NewToolResultError("path must be a file or directory, not a git option"), nil
+		}
+		gitArgs = append(gitArgs, "--", path)
+	}
+
+	output, result := m.runGit(ctx, gitArgs...)
+	if result != nil {
+		return *result, nil
+	}
+	if output == "" {
+		return message.NewToolResultText("No commits found"), nil
+	}
+	return message.NewToolResultText(output), nil
+}
+
+// runGit runs git in the working directory. On failure it returns a tool result
+// to hand back to the model: a short note for non-repositories, or git's own
+// error message otherwise.
+func (m *GitToolManager) runGit(ctx context.Context, args ...string) (string, *message.ToolResult) {
+	cmdCtx, cancel := context.WithTimeout(ctx, m.maxDuration)
+	defer cancel()
+
+	logger.InfoWithIntention(pkgLogger.IntentionTool, "Running git", "args", strings.Join(args, " "))
+	cmd := exec.CommandContext(cmdCtx, "git", args...)
+	if m.workingDir != "" {
+		cmd.Dir = m.workingDir
+	}
+	// Never page or prompt; the output is consumed by the model
+	cmd.Env = append(os.Environ(), "GIT_PAGER=cat", "GIT_TERMINAL_PROMPT=0", "LC_ALL=C")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	if cmdCtx.Err() == context.DeadlineExceeded {
+		result := message.NewToolResultError(fmt.Sprintf("git %s timed out after %v", args[0], m.maxDuration))
+		return "", &result
+	}
+	if err != nil {
+		errOutput := strings.TrimSpace(stderr.String())
+		var result message.ToolResult
+		switch {
+		case strings.Contains(errOutput, "not a git repository"):
+			dir := m.workingDir
+			if dir == "" {
+				dir = "."
+			}
+			result = message.NewToolResultError(fmt.Sprintf("%s is not inside a git repository, so there is no git status, diff or history to show", dir))
+		case errOutput != "":
+			// Keep the first line; git's hints that follow are aimed at humans
+			result = message.NewToolResultError(fmt.Sprintf("git %s failed: %s", args[0], strings.SplitN(errOutput, "\n", 2)[0]))
+		default:
+			result = message.NewToolResultError(fmt.Sprintf("git %s failed: %v", args[0], err))
+		}
+		return "", &result
+	}
+	return strings.TrimRight(stdout.String(), "\n"), nil
+}
+
+type gitTool struct {
+	name        message.ToolName
+	description message.ToolDescription
+	arguments   []message.ToolArgument
+	handler     func(ctx context.Context, args message.ToolArgumentValues) (message.ToolResult, error)
+	effects     message.ToolEffects
+}
+
+func (t *gitTool) RawName() message.ToolName            { return t.name }
+func (t *gitTool) Name() message.ToolName               { return t.name }
+func (t *gitTool) Description() message.ToolDescription { return t.description }
+func (t *gitTool) Arguments() []message.ToolArgument    { return t.arguments }
+func (t *gitTool) Handler() func(ctx context.Context, args message.ToolArgumentValues) (message.ToolResult, error) {
+	return t.handler
+}
+
+func (t *gitTool) Effects() message.ToolEffects { return t.effects }
@@ -0,0 +1,83 @@
+package tool
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fpt/go-gennai-cli/pkg/message"
+)
+
+func runTestGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=Test", "GIT_AUTHOR_EMAIL=test@example.com",
+		"GIT_COMMITTER_NAME=Test", "GIT_COMMITTER_EMAIL=test@example.com")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+}
+
+func TestGitToolManager(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	ctx := context.Background()
+	dir := t.TempDir()
+	runTestGit(t, dir, "init", "-q", "-b", "main")
+	if err := os.WriteFile(filepath.Join(dir, "a.txt"), []byte("one\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runTestGit(t, dir, "add", "a.txt")
+	runTestGit(t, dir, "commit", "-q", "-m", "Add a.txt")
+
+	m := NewGitToolManager(GitConfig{WorkingDir: dir})
+
+	res, _ := m.CallTool(ctx, "git_status", message.ToolArgumentValues{})
+	if res.Error != "" || !strings.Contains(res.Text, "## main") || !strings.Contains(res.Text, "Working tree clean") {
+		t.Errorf("unexpected clean status: text=%q error=%q", res.Text, res.Error)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "a.txt"), []byte("two\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	res, _ = m.CallTool(ctx, "git_status", message.ToolArgumentValues{})
+	if !strings.Contains(res.Text, " M a.txt") {
+		t.Errorf("expected modified file in status, got %q", res.Text)
+	}
+	res, _ = m.CallTool(ctx, "git_diff", message.ToolArgumentValues{"path": "a.txt"})
+	if !strings.Contains(res.Text, "-one") || !strings.Contains(res.Text, "+two") {
+		t.Errorf("expected diff of a.txt, got %q", res.Text)
+	}
+	res, _ = m.CallTool(ctx, "git_diff", message.ToolArgumentValues{"staged": true})
+	if res.Text != "No staged changes" {
+		t.Errorf("expected no staged changes, got %q", res.Text)
+	}
+	res, _ = m.CallTool(ctx, "git_diff", message.ToolArgumentValues{"path": "--output=x"})
+	if res.Error == "" {
+		t.Error("expected option-like path to be refused")
+	}
+
+	res, _ = m.CallTool(ctx, "git_log", message.ToolArgumentValues{"count": float64(5)})
+	if !strings.Contains(res.Text, "Test: Add a.txt") {
+		t.Errorf("expected commit in log, got %q", res.Text)
+	}
+}
+
+func TestGitToolManager_NotARepository(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := t.TempDir()
+	t.Setenv("GIT_CEILING_DIRECTORIES", filepath.Dir(dir))
+	m := NewGitToolManager(GitConfig{WorkingDir: dir})
+
+	res, _ := m.CallTool(context.Background(), "git_status", message.ToolArgumentValues{})
+	if !strings.Contains(res.Error, "is not inside a git repository") {
+		t.Errorf("expected friendly non-repository message, got text=%q error=%q", res.Text, res.Error)
+	}
+}
@@ -0,0 +1,250 @@
+package tool
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	pkgLogger "github.com/fpt/go-gennai-cli/pkg/logger"
+	"github.com/fpt/go-gennai-cli/pkg/message"
+)
+
+const (
+	maxReportedFailures    = 20 // failing tests listed with their output
+	maxFailureOutputLines  = 40 // lines of output kept per failing test (the last ones)
+	maxBuildFailureLines   = 60 // lines of compiler output kept per package
+	maxTestTimeout         = 10 * time.Minute
+	goTestEventBufferBytes = 1024 * 1024
+)
+
+// goTestEvent is one line of `go test -json` (test2json) output
+type goTestEvent struct {
+	Action     string
+	Package    string
+	Test       string
+	Output     string
+	ImportPath string // set on build-output events
+}
+
+// goTestFailure is a failing test and the output it produced
+type goTestFailure struct {
+	Package string
+	Test    string
+	Output  []string
+}
+
+// goTestReport summarizes a `go test -json` run
+type goTestReport struct {
+	Passed, Failed, Skipped int
+	PackagesPassed          int
+	Failures                []goTestFailure
+	FailedPackages          map[string][]string // package failures not attributed to a test (build errors, panics in init)
+}
+
+// parseGoTestJSON reads test2json events and collects counts and failing
+// output. Counts are for top-level tests; failing subtests are listed instead
+// of their parent. Lines that are not JSON (e.g. from a wrapper) are ignored.
+func parseGoTestJSON(r io.Reader) goTestReport {
+	report := goTestReport{FailedPackages: make(map[string][]string)}
+	testOutput := make(map[string][]string)    // package + "\x00" + test
+	packageOutput := make(map[string][]string) // package-level lines and build output
+	var failed []goTestFailure
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), goTestEventBufferBytes)
+	for scanner.Scan() {
+		var ev goTestEvent
+		if err := json.Unmarshal(scanner.Bytes(), &ev); err != nil {
+			continue
+		}
+		pkg := ev.Package
+		if pkg == "" {
+			pkg = ev.ImportPath
+		}
+		key := pkg + "\x00" + ev.Test
+		topLevel := !strings.Contains(ev.Test, "/")
+
+		switch ev.Action {
+		case "output", "build-output":
+			if ev.Test != "" {
+				testOutput[key] = append(testOutput[key], strings.TrimRight(ev.Output, "\n"))
+			} else {
+				packageOutput[pkg] = append(packageOutput[pkg], strings.TrimRight(ev.Output, "\n"))
+			}
+		case "pass":
+			if ev.Test == "" {
+				report.PackagesPassed++
+			} else if topLevel {
+				report.Passed++
+			}
+			delete(testOutput, key)
+		case "skip":
+			if ev.Test != "" && topLevel {
+				report.Skipped++
+			}
+			delete(testOutput, key)
+		case "fail", "build-fail":
+			if ev.Test == "" {
+				report.FailedPackages[pkg] = packageOutput[pkg]
+				continue
+			}
+			if topLevel {
+				report.Failed++
+			}
+			failed = append(failed, goTestFailure{Package: pkg, Test: ev.Test, Output: testOutput[key]})
+			delete(testOutput, key)
+		}
+	}
+
+	// A failing parent is explained by its failing subtests; list only the leaves
+	for i, f := range failed {
+		leaf := true
+		for j, other := range failed {
+			if i != j && other.Package == f.Package && strings.HasPrefix(other.Test, f.Test+"/") {
+				leaf = false
+				break
+			}
+		}
+		if leaf {
+			report.Failures = append(report.Failures, f)
+		}
+	}
+	// Packages whose failure is already explained by failing tests need no extra output
+	for _, f := range report.Failures {
+		delete(report.FailedPackages, f.Package)
+	}
+	return report
+}
+
+// format renders the report for the model. Output from passing tests is
+// dropped; failing output is limited to its last lines.
+func (r goTestReport) format(target string, elapsed time.Duration) string {
+	var b strings.Builder
+	status := "PASS"
+	if r.Failed > 0 || len(r.FailedPackages) > 0 {
+		status = "FAIL"
+	}
+	fmt.Fprintf(&b, "go test %s: %s — %d passed, %d failed, %d skipped", target, status, r.Passed, r.Failed, r.Skipped)
+	if n := len(r.FailedPackages); n > 0 {
+		fmt.Fprintf(&b, ", %d package(s) failed to build or run", n)
+	}
+	fmt.Fprintf(&b, " (%s)\n", elapsed.Round(time.Millisecond))
+
+	if len(r.FailedPackages) > 0 {
+		packages := make([]string, 0, len(r.FailedPackages))
+		for pkg := range r.FailedPackages {
+			packages = append(packages, pkg)
+		}
+		sort.Strings(packages)
+		b.WriteString("\nFailed packages:\n")
+		for _, pkg := range packages {
+			fmt.Fprintf(&b, "--- FAIL %s\n", pkg)
+			writeTail(&b, r.FailedPackages[pkg], maxBuildFailureLines)
+		}
+	}
+
+	if len(r.Failures) > 0 {
+		b.WriteString("\nFailed tests:\n")
+		for i, f := range r.Failures {
+			if i == maxReportedFailures {
+				fmt.Fprintf(&b, "... %d more failing test(s) not shown; narrow the run with the run argument\n", len(r.Failures)-i)
+				break
+			}
+			fmt.Fprintf(&b, "--- FAIL %s %s\n", f.Package, f.Test)
+			writeTail(&b, f.Output, maxFailureOutputLines)
+		}
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// writeTail writes the last max lines, noting how many were omitted
+func writeTail(b *strings.Builder, lines []string, max int) {
+	if len(lines) > max {
+		fmt.Fprintf(b, "    ... %d earlier line(s) omitted\n", len(lines)-max)
+		lines = lines[len(lines)-max:]
+	}
+	for _, line := range lines {
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+}
+
+// testTimeout returns the timeout of a run_tests call, capped at maxTestTimeout
+func (m *BashToolManager) testTimeout(args message.ToolArgumentValues) time.Duration {
+	timeout := m.maxDuration
+	if timeoutMs, ok := args["timeout"].(float64); ok && timeoutMs > 0 {
+		timeout = time.Duration(timeoutMs) * time.Millisecond
+	}
+	if timeout <= 0 || timeout > maxTestTimeout {
+		timeout = maxTestTimeout
+	}
+	return timeout
+}
+
+// handleRunTests runs `go test -json` in the working directory and returns a
+// structured summary instead of the raw output
+func (m *BashToolManager) handleRunTests(ctx context.Context, args message.ToolArgumentValues) (message.ToolResult, error) {
+	target := "./..."
+	if path, ok := args["path"].(string); ok && strings.TrimSpace(path) != "" {
+		target = strings.TrimSpace(path)
+	}
+	if strings.HasPrefix(target, "-") {
+		return message.NewToolResultError("path must be a package pattern or directory, not a flag"), nil
+	}
+
+	timeout := m.testTimeout(args)
+
+	cmdArgs := []string{"test", "-json"}
+	if run, ok := args["run"].(string); ok && run != "" {
+		cmdArgs = append(cmdArgs, "-run", run)
+	}
+	cmdArgs = append(cmdArgs, target)
+
+	// The configured allow/deny policy applies as if the command ran through bash
+	if err := checkCommandPolicy("go "+strings.Join(cmdArgs, " "), m.allowedCommands, m.deniedCommands); err != nil {
+		return message.NewToolResultError(err.Error()), nil
+	}
+
+	cmdCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	logger.InfoWithIntention(pkgLogger.IntentionTool, "Running Go tests", "target", target, "timeout", timeout)
+	cmd := exec.CommandContext(cmdCtx, "go", cmdArgs...)
+	if m.workingDir != "" {
+		cmd.Dir = m.workingDir
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	start := time.Now()
+	err := cmd.Run()
+	elapsed := time.Since(start)
+
+	report := parseGoTestJSON(&stdout)
+	result := report.format(target, elapsed)
+
+	if cmdCtx.Err() == context.DeadlineExceeded {
+		return message.NewToolResultError(fmt.Sprintf("go test timed out after %v; results so far:\n%s", timeout, result)), nil
+	}
+	if err != nil {
+		if _, ok := err.(*exec.ExitError); !ok {
+			return message.NewToolResultError(fmt.Sprintf("failed to run go test: %v", err)), nil
+		}
+	}
+	// Errors that never reach test2json, such as a bad package pattern
+	if errOutput := strings.TrimSpace(stderr.String()); errOutput != "" {
+		result += "\n\ngo test stderr:\n" + errOutput
+	}
+	if err != nil && report.Passed+report.Failed+report.Skipped+len(report.FailedPackages) == 0 && stderr.Len() == 0 {
+		result += "\n\ngo test exited with " + err.Error()
+	}
+	return message.NewToolResultText(result), nil
+}
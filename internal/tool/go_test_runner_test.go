@@ -0,0 +1,101 @@
+package tool
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fpt/go-gennai-cli/pkg/message"
+)
+
+func TestParseGoTestJSON(t *testing.T) {
+	events := `{"Action":"run","Package":"example/a","Test":"TestOK"}
+{"Action":"output","Package":"example/a","Test":"TestOK","Output":"=== RUN   TestOK\n"}
+{"Action":"pass","Package":"example/a","Test":"TestOK"}
+{"Action":"run","Package":"example/a","Test":"TestTable"}
+{"Action":"run","Package":"example/a","Test":"TestTable/empty"}
+{"Action":"output","Package":"example/a","Test":"TestTable/empty","Output":"    a_test.go:12: got 1, want 0\n"}
+{"Action":"fail","Package":"example/a","Test":"TestTable/empty"}
+{"Action":"fail","Package":"example/a","Test":"TestTable"}
+{"Action":"skip","Package":"example/a","Test":"TestSlow"}
+{"Action":"fail","Package":"example/a"}
+not json
+{"ImportPath":"example/b","Action":"build-output","Output":"b/b.go:3:1: syntax error\n"}
+{"ImportPath":"example/b","Action":"build-fail"}
+{"Action":"output","Package":"example/b","Output":"FAIL\texample/b [build failed]\n"}
+{"Action":"fail","Package":"example/b"}
+{"Action":"pass","Package":"example/c"}
+`
+	report := parseGoTestJSON(strings.NewReader(events))
+
+	if report.Passed != 1 || report.Failed != 1 || report.Skipped != 1 {
+		t.Errorf("counts = %d passed, %d failed, %d skipped; want 1, 1, 1", report.Passed, report.Failed, report.Skipped)
+	}
+	if len(report.Failures) != 1 || report.Failures[0].Test != "TestTable/empty" {
+		t.Fatalf("expected only the failing subtest, got %+v", report.Failures)
+	}
+	if _, ok := report.FailedPackages["example/a"]; ok {
+		t.Errorf("package with failing tests should not be reported separately")
+	}
+
+	out := report.format("./...", time.Second)
+	for _, want := range []string{"FAIL — 1 passed, 1 failed, 1 skipped", "--- FAIL example/a TestTable/empty", "got 1, want 0", "--- FAIL example/b", "syntax error"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("report missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "=== RUN   TestOK") {
+		t.Errorf("report should drop output of passing tests:\n%s", out)
+	}
+}
+
+func TestGoTestReport_TruncatesFailureOutput(t *testing.T) {
+	var lines []string
+	for i := 0; i < maxFailureOutputLines+10; i++ {
+		lines = append(lines, "line")
+	}
+	report := goTestReport{Failed: 1, Failures: []goTestFailure{{Package: "p", Test: "TestX", Output: lines}}}
+	out := report.format("./...", 0)
+	if !strings.Contains(out, "10 earlier line(s) omitted") {
+		t.Errorf("expected omitted-lines note:\n%s", out)
+	}
+}
+
+func TestBashToolManager_RunTests(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go toolchain not available")
+	}
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":    "module example.com/sample\n\ngo 1.21\n",
+		"sample.go": "package sample\n\nfunc Add(a, b int) int { return a + b }\n",
+		"sample_test.go": "package sample\n\nimport \"testing\"\n\n" +
+			"func TestAdd(t *testing.T) {\n\tif Add(1, 2) != 3 {\n\t\tt.Fatal(\"bad\")\n\t}\n}\n\n" +
+			"func TestBroken(t *testing.T) {\n\tt.Errorf(\"expected failure marker\")\n}\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	manager := NewBashToolManager(BashConfig{WorkingDir: dir, MaxDuration: 2 * time.Minute})
+
+	result, err := manager.CallTool(context.Background(), "run_tests", message.ToolArgumentValues{})
+	if err != nil || result.Error != "" {
+		t.Fatalf("run_tests failed: %v %s", err, result.Error)
+	}
+	for _, want := range []string{"FAIL — 1 passed, 1 failed", "TestBroken", "expected failure marker"} {
+		if !strings.Contains(result.Text, want) {
+			t.Errorf("result missing %q:\n%s", want, result.Text)
+		}
+	}
+
+	result, _ = manager.CallTool(context.Background(), "run_tests", message.ToolArgumentValues{"run": "TestAdd"})
+	if !strings.Contains(result.Text, "PASS — 1 passed, 0 failed") {
+		t.Errorf("expected filtered run to pass:\n%s", result.Text)
+	}
+}
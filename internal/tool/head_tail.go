@@ -0,0 +1,173 @@
+package tool
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/fpt/go-gennai-cli/pkg/message"
+)
+
+// Line counts for file_head and file_tail
+const (
+	DefaultHeadTailLines    = 100
+	DefaultHeadTailMaxLines = 2000
+)
+
+// tailChunkBytes is how much file_tail reads at a time, backwards from the end
+const tailChunkBytes = 64 * 1024
+
+// handleFileHead returns the first lines of a file, reading no further
+func (m *FileSystemToolManager) handleFileHead(ctx context.Context, args message.ToolArgumentValues) (message.ToolResult, error) {
+	return m.handleHeadTail(ctx, args, false)
+}
+
+// handleFileTail returns the last lines of a file, reading backwards from its end
+func (m *FileSystemToolManager) handleFileTail(ctx context.Context, args message.ToolArgumentValues) (message.ToolResult, error) {
+	return m.handleHeadTail(ctx, args, true)
+}
+
+func (m *FileSystemToolManager) handleHeadTail(ctx context.Context, args message.ToolArgumentValues, tail bool) (message.ToolResult, error) {
+	pathParam, ok := args["file_path"].(string)
+	if !ok || pathParam == "" {
+		return message.NewToolResultError("file_path parameter is required"), nil
+	}
+	path, resolveErr := m.resolvePath(pathParam)
+	if resolveErr != nil {
+		return message.NewToolResultError(fmt.Sprintf("failed to resolve path: %v", resolveErr)), nil
+	}
+	if err := m.isPathAllowed(path); err != nil {
+		return message.NewToolResultError(err.Error()), nil
+	}
+	if err := m.isFileBlacklisted(path); err != nil {
+		return message.NewToolResultError(err.Error()), nil
+	}
+
+	n := m.headTailLines
+	if v, ok := args["lines"].(float64); ok && v > 0 {
+		n = int(v)
+	}
+	n = min(n, m.headTailMaxLines)
+
+	file, err := m.fsRepo.Open(ctx, path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			m.recordFileRead(path)
+			return message.NewToolResultError(fmt.Sprintf("file does not exist: %s", path)), nil
+		}
+		return message.NewToolResultError(fmt.Sprintf("failed to read file: %v", err)), nil
+	}
+	defer file.Close()
+
+	size, err := file.Seek(0, io.SeekEnd)
+	if err != nil {
+		return message.NewToolResultError(fmt.Sprintf("failed to read file: %v", err)), nil
+	}
+	var lines []string
+	var more bool
+	if tail {
+		lines, more, err = readTailLines(file, size, n, m.maxReadBytes)
+	} else {
+		if _, err = file.Seek(0, io.SeekStart); err == nil {
+			lines, more, err = readHeadLines(file, n, m.maxReadBytes)
+		}
+	}
+	if err != nil {
+		return message.NewToolResultError(fmt.Sprintf("failed to read file: %v", err)), nil
+	}
+	m.recordFileRead(path)
+
+	if isBinaryContent([]byte(strings.Join(lines, "\n"))) {
+		return message.NewToolResultError(fmt.Sprintf("%s is a binary file; use Read to see its type and size", path)), nil
+	}
+
+	var b strings.Builder
+	if tail {
+		b.WriteString(fmt.Sprintf("Last %d line(s) of %s (%d bytes):\n", len(lines), path, size))
+		for _, line := range lines {
+			b.WriteString(line + "\n")
+		}
+		if more {
+			b.WriteString("\n[Earlier lines not shown. Pass a larger lines value to see more.]\n")
+		}
+		return message.NewToolResultText(b.String()), nil
+	}
+
+	b.WriteString(fmt.Sprintf("First %d line(s) of %s (%d bytes):\n", len(lines), path, size))
+	for i, line := range lines {
+		b.WriteString(fmt.Sprintf("%6d\t%s\n", i+1, line))
+	}
+	if more {
+		b.WriteString(fmt.Sprintf("\n[File continues. Call Read with offset=%d and a limit to see more.]\n", len(lines)+1))
+	}
+	return message.NewToolResultText(b.String()), nil
+}
+
+// readHeadLines reads up to n lines from r, stopping early once maxBytes are
+// read. more reports whether the file has further content.
+func readHeadLines(r io.Reader, n, maxBytes int) (lines []string, more bool, err error) {
+	reader := bufio.NewReader(r)
+	read := 0
+	for len(lines) < n && read < maxBytes {
+		line, err := reader.ReadString('\n')
+		read += len(line)
+		if line != "" {
+			lines = append(lines, truncateLine(strings.TrimRight(line, "\r\n"), maxBytes))
+		}
+		if err == io.EOF {
+			return lines, false, nil
+		}
+		if err != nil {
+			return nil, false, err
+		}
+	}
+	_, err = reader.Peek(1)
+	return lines, err == nil, nil
+}
+
+// readTailLines reads the last n lines of a file of the given size by reading
+// chunks backwards from the end, at most maxBytes in total. more reports
+// whether the file has earlier lines.
+func readTailLines(r io.ReadSeeker, size int64, n, maxBytes int) (lines []string, more bool, err error) {
+	var buf []byte
+	offset := size
+	for offset > 0 && len(buf) < maxBytes {
+		chunk := min(int64(tailChunkBytes), offset)
+		offset -= chunk
+		part := make([]byte, chunk)
+		if _, err := r.Seek(offset, io.SeekStart); err != nil {
+			return nil, false, err
+		}
+		if _, err := io.ReadFull(r, part); err != nil {
+			return nil, false, err
+		}
+		buf = append(part, buf...)
+		// n lines need n line breaks before them, ignoring the file's final one
+		if bytes.Count(bytes.TrimSuffix(buf, []byte("\n")), []byte("\n")) >= n {
+			break
+		}
+	}
+
+	text := strings.TrimSuffix(string(buf), "\n")
+	if text == "" {
+		return nil, offset > 0, nil
+	}
+	lines = strings.Split(text, "\n")
+	if offset > 0 {
+		// The first line read may start mid-line
+		lines = lines[1:]
+		more = true
+	}
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+		more = true
+	}
+	for i, line := range lines {
+		lines[i] = truncateLine(strings.TrimSuffix(line, "\r"), maxBytes)
+	}
+	return lines, more, nil
+}
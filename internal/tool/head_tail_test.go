@@ -0,0 +1,81 @@
+package tool
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fpt/go-gennai-cli/internal/infra"
+	"github.com/fpt/go-gennai-cli/internal/repository"
+	"github.com/fpt/go-gennai-cli/pkg/message"
+)
+
+func TestFileHeadTail(t *testing.T) {
+	dir := t.TempDir()
+	var content strings.Builder
+	for i := 1; i <= 50000; i++ {
+		fmt.Fprintf(&content, "2026-10-16T12:00:00Z INFO request %d served\n", i)
+	}
+	log := filepath.Join(dir, "server.txt")
+	if err := os.WriteFile(log, []byte(content.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+	manager := NewFileSystemToolManager(infra.NewOSFilesystemRepository(), repository.FileSystemConfig{HeadTailLines: 5, HeadTailMaxLines: 20}, dir)
+	ctx := context.Background()
+
+	call := func(name message.ToolName, args message.ToolArgumentValues) message.ToolResult {
+		t.Helper()
+		result, err := manager.CallTool(ctx, name, args)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if result.Error != "" {
+			t.Fatalf("%s failed: %s", name, result.Error)
+		}
+		return result
+	}
+
+	head := call("file_head", message.ToolArgumentValues{"file_path": log})
+	if !strings.Contains(head.Text, "     1\t2026-10-16T12:00:00Z INFO request 1 served") ||
+		!strings.Contains(head.Text, "request 5 served") || strings.Contains(head.Text, "request 6 served") {
+		t.Errorf("expected the first 5 numbered lines, got:\n%s", head.Text)
+	}
+	if !strings.Contains(head.Text, "offset=6") {
+		t.Errorf("expected a pointer to the rest of the file, got:\n%s", head.Text)
+	}
+
+	tail := call("file_tail", message.ToolArgumentValues{"file_path": log, "lines": float64(3)})
+	lines := strings.Split(strings.TrimSpace(tail.Text), "\n")
+	if len(lines) < 4 || !strings.HasSuffix(lines[1], "request 49998 served") || !strings.HasSuffix(lines[3], "request 50000 served") {
+		t.Errorf("expected the last 3 lines, got:\n%s", tail.Text)
+	}
+
+	// Requests beyond the cap are limited
+	capped := call("file_tail", message.ToolArgumentValues{"file_path": log, "lines": float64(1000)})
+	if !strings.HasPrefix(capped.Text, "Last 20 line(s)") {
+		t.Errorf("expected the tail capped at 20 lines, got:\n%s", capped.Text)
+	}
+
+	// Counts as a Read for read-before-write
+	manager.mu.RLock()
+	_, read := manager.fileReadTimestamps[log]
+	manager.mu.RUnlock()
+	if !read {
+		t.Error("expected file_tail to record the read")
+	}
+
+	short := filepath.Join(dir, "short.txt")
+	if err := os.WriteFile(short, []byte("one\ntwo"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	whole := call("file_tail", message.ToolArgumentValues{"file_path": short, "lines": float64(10)})
+	if whole.Text != fmt.Sprintf("Last 2 line(s) of %s (7 bytes):\none\ntwo\n", short) {
+		t.Errorf("expected the whole short file, got %q", whole.Text)
+	}
+	if res, _ := manager.CallTool(ctx, "file_head", message.ToolArgumentValues{"file_path": filepath.Join(dir, "missing.txt")}); !strings.Contains(res.Error, "does not exist") {
+		t.Errorf("expected a missing file error, got %+v", res)
+	}
+}
@@ -0,0 +1,88 @@
+package tool
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/fpt/go-gennai-cli/pkg/message"
+)
+
+// InventoryToolManager provides list_available_tools, which lets the model
+// inspect the tools active for its scenario and where each one comes from
+type InventoryToolManager struct {
+	tools     map[message.ToolName]message.Tool
+	inventory func() string
+}
+
+// NewInventoryToolManager creates a manager whose list_available_tools tool
+// returns the text produced by inventory
+func NewInventoryToolManager(inventory func() string) *InventoryToolManager {
+	manager := &InventoryToolManager{
+		tools:     make(map[message.ToolName]message.Tool),
+		inventory: inventory,
+	}
+
+	manager.registerTool("list_available_tools", message.UncachedRead, "List the tools available in this session with their descriptions, grouped by source (built-in, web, git, or the MCP server providing them). Read-only.",
+		[]message.ToolArgument{},
+		func(ctx context.Context, args message.ToolArgumentValues) (message.ToolResult, error) {
+			return message.NewToolResultText(manager.inventory()), nil
+		})
+
+	return manager
+}
+
+// Implement domain.ToolManager interface
+func (m *InventoryToolManager) GetTool(name message.ToolName) (message.Tool, bool) {
+	tool, exists := m.tools[name]
+	return tool, exists
+}
+
+func (m *InventoryToolManager) GetTools() map[message.ToolName]message.Tool {
+	return m.tools
+}
+
+func (m *InventoryToolManager) CallTool(ctx context.Context, name message.ToolName, args message.ToolArgumentValues) (message.ToolResult, error) {
+	tool, exists := m.tools[name]
+	if !exists {
+		return message.NewToolResultError(fmt.Sprintf("tool %s not found", name)), nil
+	}
+
+	handler := tool.Handler()
+	return handler(ctx, args)
+}
+
+// RegisterTool registers a tool that declares no effects (message.AccessUnknown)
+func (m *InventoryToolManager) RegisterTool(name message.ToolName, description message.ToolDescription, args []message.ToolArgument, handler func(ctx context.Context, args message.ToolArgumentValues) (message.ToolResult, error)) {
+	m.registerTool(name, message.ToolEffects{}, description, args, handler)
+}
+
+// registerTool registers a built-in tool with the effects it declares
+func (m *InventoryToolManager) registerTool(name message.ToolName, effects message.ToolEffects, description message.ToolDescription, args []message.ToolArgument, handler func(ctx context.Context, args message.ToolArgumentValues) (message.ToolResult, error)) {
+	m.tools[name] = &inventoryTool{
+		name:        name,
+		description: description,
+		arguments:   args,
+		handler:     handler,
+		effects:     effects,
+	}
+}
+
+type inventoryTool struct {
+	name        message.ToolName
+	description message.ToolDescription
+	arguments   []message.ToolArgument
+	handler     func(ctx context.Context, args message.ToolArgumentValues) (message.ToolResult, error)
+	effects     message.ToolEffects
+}
+
+func (t *inventoryTool) RawName() message.ToolName            { return t.name }
+func (t *inventoryTool) Name() message.ToolName               { return t.name }
+func (t *inventoryTool) Description() message.ToolDescription { return t.description }
+func (t *inventoryTool) Arguments() []message.ToolArgument    { return t.arguments }
+func (t *inventoryTool) Handler() func(ctx context.Context, args message.ToolArgumentValues) (message.ToolResult, error) {
+	return t.handler
+}
+
+func (t *inventoryTool) Effects() message.ToolEffects {
+	return t.effects
+}
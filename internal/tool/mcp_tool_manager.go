@@ -18,8 +18,9 @@ type MCPEnhancedToolManager struct {
 	tools map[message.ToolName]message.Tool
 
 	// MCP server management
-	servers map[string]*mcp.MCPClientWrapper
+	servers map[string]*mcp.ClientPool
 	configs map[string]domain.MCPServerConfig
+	pool    mcp.PoolOptions // connections and concurrency per server
 
 	// Thread safety
 	mu sync.RWMutex
@@ -32,12 +33,20 @@ type MCPEnhancedToolManager struct {
 func NewMCPEnhancedToolManager() *MCPEnhancedToolManager {
 	return &MCPEnhancedToolManager{
 		tools:    make(map[message.ToolName]message.Tool),
-		servers:  make(map[string]*mcp.MCPClientWrapper),
+		servers:  make(map[string]*mcp.ClientPool),
 		configs:  make(map[string]domain.MCPServerConfig),
 		mcpTools: make(map[string][]message.Tool),
 	}
 }
 
+// SetPoolOptions sets the connection pool size and concurrency cap used for
+// servers added afterwards
+func (m *MCPEnhancedToolManager) SetPoolOptions(opts mcp.PoolOptions) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pool = opts
+}
+
 // AddServer adds and connects to an MCP server
 func (m *MCPEnhancedToolManager) AddServer(ctx context.Context, config domain.MCPServerConfig) error {
 	m.mu.Lock()
@@ -48,13 +57,8 @@ func (m *MCPEnhancedToolManager) AddServer(ctx context.Context, config domain.MC
 		return fmt.Errorf("server %s already exists", config.Name)
 	}
 
-	// Create MCP client
-	mcpClient, err := mcp.NewMCPClient(config)
-	if err != nil {
-		return fmt.Errorf("failed to create MCP client for %s: %w", config.Name, err)
-	}
-
-	// Start the client connection
+	// Create the connection pool; Start opens the first connection
+	mcpClient := mcp.NewClientPool(config, m.pool)
 	if err := mcpClient.Start(ctx); err != nil {
 		return fmt.Errorf("failed to start MCP server %s: %w", config.Name, err)
 	}
@@ -237,7 +241,7 @@ func (m *MCPEnhancedToolManager) ReadMCPResource(ctx context.Context, serverName
 }
 
 // loadToolsFromServer loads tools from an MCP server and registers them
-func (m *MCPEnhancedToolManager) loadToolsFromServer(ctx context.Context, serverName string, client *mcp.MCPClientWrapper) error {
+func (m *MCPEnhancedToolManager) loadToolsFromServer(ctx context.Context, serverName string, client *mcp.ClientPool) error {
 	// List tools from the server
 	request := mcpapi.ListToolsRequest{}
 	result, err := client.ListTools(ctx, request)
@@ -0,0 +1,36 @@
+package tool
+
+import "testing"
+
+func TestCompilePathGlob(t *testing.T) {
+	tests := []struct {
+		pattern, path string
+		want          bool
+	}{
+		{"*.go", "main.go", true},
+		{"*.go", "internal/app/watch.go", true},
+		{"*.go", "main.go.orig", false},
+		{"internal/**/*.go", "internal/app/watch.go", true},
+		{"internal/**/*.go", "internal/watch.go", true},
+		{"internal/**/*.go", "pkg/agent/react.go", false},
+		{"internal/*.go", "internal/app/watch.go", false},
+		{"./pkg/**", "pkg/message/message.go", true},
+		{"*_test.[gG]o", "watch_test.go", true},
+		{"file?.txt", "file1.txt", true},
+	}
+	for _, tt := range tests {
+		re, err := CompilePathGlob(tt.pattern)
+		if err != nil {
+			t.Fatalf("CompilePathGlob(%q) failed: %v", tt.pattern, err)
+		}
+		if got := re.MatchString(tt.path); got != tt.want {
+			t.Errorf("pattern %q on %q = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"", "  ", "src/[abc.go"} {
+		if _, err := CompilePathGlob(bad); err == nil {
+			t.Errorf("CompilePathGlob(%q) should fail", bad)
+		}
+	}
+}
@@ -0,0 +1,382 @@
+package tool
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/fpt/go-gennai-cli/pkg/message"
+)
+
+const (
+	maxQueryFileBytes     = 20 << 20 // refuse to load larger data files
+	maxQueryRowsScanned   = 100000
+	defaultQueryLimit     = 50
+	maxQueryLimit         = 500
+	maxQueryOutputChars   = 20000
+	queryGroupCountColumn = "count"
+)
+
+// queryCondition is one "field op value" clause of a where filter
+type queryCondition struct {
+	field, op, value string
+}
+
+// Clauses are joined with "and"; values may be quoted
+var (
+	queryAndSplit = regexp.MustCompile(`(?i)\s+and\s+`)
+	queryClause   = regexp.MustCompile(`^\s*([\w.\- ]+?)\s*(==|!=|>=|<=|>|<|=|\bcontains\b)\s*(.+?)\s*$`)
+)
+
+// parseQueryWhere parses filters like `status == "open" and amount > 100`
+func parseQueryWhere(where string) ([]queryCondition, error) {
+	if strings.TrimSpace(where) == "" {
+		return nil, nil
+	}
+	var conds []queryCondition
+	for _, clause := range queryAndSplit.Split(where, -1) {
+		m := queryClause.FindStringSubmatch(clause)
+		if m == nil {
+			return nil, fmt.Errorf("invalid where clause %q: expected `field op value` with op one of ==, !=, >, >=, <, <=, contains", strings.TrimSpace(clause))
+		}
+		op := m[2]
+		if op == "=" {
+			op = "=="
+		}
+		value := m[3]
+		if unquoted, err := strconv.Unquote(value); err == nil {
+			value = unquoted
+		} else if len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'' {
+			value = value[1 : len(value)-1]
+		}
+		conds = append(conds, queryCondition{field: m[1], op: op, value: value})
+	}
+	return conds, nil
+}
+
+// matches compares numerically when both sides are numbers, otherwise as strings
+func (c queryCondition) matches(row map[string]string) bool {
+	actual, ok := row[c.field]
+	if !ok {
+		return false
+	}
+	if c.op == "contains" {
+		return strings.Contains(strings.ToLower(actual), strings.ToLower(c.value))
+	}
+
+	cmp := strings.Compare(actual, c.value)
+	a, errA := strconv.ParseFloat(actual, 64)
+	b, errB := strconv.ParseFloat(c.value, 64)
+	if errA == nil && errB == nil {
+		switch {
+		case a < b:
+			cmp = -1
+		case a > b:
+			cmp = 1
+		default:
+			cmp = 0
+		}
+	}
+	switch c.op {
+	case "==":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	}
+	return false
+}
+
+// readQueryRows loads rows from CSV/TSV or JSON lines, returning the columns in
+// file order (CSV header) or sorted order (JSON). It stops after limit rows.
+func readQueryRows(path string, data []byte, limit int) (columns []string, rows []map[string]string, truncated bool, err error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv", ".tsv":
+		r := csv.NewReader(bytes.NewReader(data))
+		if strings.EqualFold(filepath.Ext(path), ".tsv") {
+			r.Comma = '\t'
+		}
+		r.FieldsPerRecord = -1
+		columns, err = r.Read()
+		if err != nil {
+			return nil, nil, false, fmt.Errorf("failed to read CSV header: %w", err)
+		}
+		for {
+			record, err := r.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, nil, false, fmt.Errorf("failed to parse CSV: %w", err)
+			}
+			if len(rows) >= limit {
+				return columns, rows, true, nil
+			}
+			row := make(map[string]string, len(columns))
+			for i, col := range columns {
+				if i < len(record) {
+					row[col] = record[i]
+				}
+			}
+			rows = append(rows, row)
+		}
+		return columns, rows, false, nil
+
+	case ".jsonl", ".ndjson", ".json":
+		seen := make(map[string]bool)
+		dec := json.NewDecoder(bytes.NewReader(data))
+		for line := 1; ; line++ {
+			var obj map[string]any
+			if err := dec.Decode(&obj); err == io.EOF {
+				break
+			} else if err != nil {
+				return nil, nil, false, fmt.Errorf("failed to parse JSON record %d (expected one object per line): %w", line, err)
+			}
+			if len(rows) >= limit {
+				truncated = true
+				break
+			}
+			row := make(map[string]string, len(obj))
+			for k, v := range obj {
+				row[k] = queryValueString(v)
+				if !seen[k] {
+					seen[k] = true
+					columns = append(columns, k)
+				}
+			}
+			rows = append(rows, row)
+		}
+		sort.Strings(columns)
+		return columns, rows, truncated, nil
+
+	default:
+		return nil, nil, false, fmt.Errorf("unsupported data file %s: expected .csv, .tsv, .jsonl, .ndjson or .json (JSON lines)", filepath.Base(path))
+	}
+}
+
+// queryValueString renders a JSON value the way it would appear in a CSV cell
+func queryValueString(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(val)
+	default:
+		b, _ := json.Marshal(val)
+		return string(b)
+	}
+}
+
+// handleQueryData filters, projects and groups rows of a local CSV or JSON-lines file
+func (m *FileSystemToolManager) handleQueryData(ctx context.Context, args message.ToolArgumentValues) (message.ToolResult, error) {
+	filePath, ok := args["file_path"].(string)
+	if !ok || filePath == "" {
+		return message.NewToolResultError("file_path parameter is required"), nil
+	}
+	absPath, err := m.resolvePath(filePath)
+	if err != nil {
+		return message.NewToolResultError(fmt.Sprintf("failed to resolve path: %v", err)), nil
+	}
+	if err := m.isPathAllowed(absPath); err != nil {
+		return message.NewToolResultError(err.Error()), nil
+	}
+	if err := m.isFileBlacklisted(absPath); err != nil {
+		return message.NewToolResultError(err.Error()), nil
+	}
+
+	conds, err := parseQueryWhere(stringArg(args, "where"))
+	if err != nil {
+		return message.NewToolResultError(err.Error()), nil
+	}
+	groupBy := stringArg(args, "group_by")
+	sumColumn := stringArg(args, "sum")
+	if sumColumn != "" && groupBy == "" {
+		return message.NewToolResultError("sum requires group_by"), nil
+	}
+	limit := defaultQueryLimit
+	if v, ok := args["limit"].(float64); ok && v > 0 {
+		limit = min(int(v), maxQueryLimit)
+	}
+
+	info, err := m.fsRepo.Stat(ctx, absPath)
+	if err != nil {
+		return message.NewToolResultError(fmt.Sprintf("failed to stat file %s: %v", absPath, err)), nil
+	}
+	if info.Size() > maxQueryFileBytes {
+		return message.NewToolResultError(fmt.Sprintf("file %s is %d bytes; query_data loads at most %d bytes", absPath, info.Size(), maxQueryFileBytes)), nil
+	}
+	data, err := m.fsRepo.ReadFile(ctx, absPath)
+	if err != nil {
+		return message.NewToolResultError(fmt.Sprintf("failed to read file %s: %v", absPath, err)), nil
+	}
+	columns, rows, scanCapped, err := readQueryRows(absPath, data, maxQueryRowsScanned)
+	if err != nil {
+		return message.NewToolResultError(err.Error()), nil
+	}
+
+	known := make(map[string]bool, len(columns))
+	for _, c := range columns {
+		known[c] = true
+	}
+	selected := columns
+	if sel := stringListArg(args, "select"); len(sel) > 0 {
+		selected = sel
+	}
+	for _, name := range append(append([]string{groupBy, sumColumn}, selected...), condFields(conds)...) {
+		if name != "" && !known[name] {
+			return message.NewToolResultError(fmt.Sprintf("unknown column %q; columns are: %s", name, strings.Join(columns, ", "))), nil
+		}
+	}
+
+	var matched []map[string]string
+	for _, row := range rows {
+		keep := true
+		for _, c := range conds {
+			if !c.matches(row) {
+				keep = false
+				break
+			}
+		}
+		if keep {
+			matched = append(matched, row)
+		}
+	}
+
+	header, table := selected, make([][]string, 0, min(len(matched), limit))
+	if groupBy != "" {
+		header, table = groupQueryRows(matched, groupBy, sumColumn)
+		if len(table) > limit {
+			table = table[:limit]
+		}
+	} else {
+		for _, row := range matched {
+			if len(table) >= limit {
+				break
+			}
+			record := make([]string, len(selected))
+			for i, col := range selected {
+				record[i] = row[col]
+			}
+			table = append(table, record)
+		}
+	}
+
+	var out bytes.Buffer
+	w := csv.NewWriter(&out)
+	_ = w.Write(header)
+	shown := 0
+	for _, record := range table {
+		if out.Len() > maxQueryOutputChars {
+			break
+		}
+		_ = w.Write(record)
+		w.Flush()
+		shown++
+	}
+	w.Flush()
+
+	var summary strings.Builder
+	fmt.Fprintf(&summary, "Scanned %d row(s), %d matched", len(rows), len(matched))
+	if scanCapped {
+		fmt.Fprintf(&summary, " (stopped scanning at %d rows)", maxQueryRowsScanned)
+	}
+	if groupBy != "" {
+		fmt.Fprintf(&summary, ", %d group(s)", len(table))
+	}
+	fmt.Fprintf(&summary, "; showing %d.\n\n", shown)
+	return message.NewToolResultText(summary.String() + out.String()), nil
+}
+
+// groupQueryRows counts rows per group, and sums sumColumn when given. Groups
+// are ordered by count, largest first.
+func groupQueryRows(rows []map[string]string, groupBy, sumColumn string) ([]string, [][]string) {
+	type group struct {
+		key   string
+		count int
+		sum   float64
+	}
+	groups := make(map[string]*group)
+	var order []*group
+	for _, row := range rows {
+		key := row[groupBy]
+		g, ok := groups[key]
+		if !ok {
+			g = &group{key: key}
+			groups[key] = g
+			order = append(order, g)
+		}
+		g.count++
+		if sumColumn != "" {
+			if v, err := strconv.ParseFloat(row[sumColumn], 64); err == nil {
+				g.sum += v
+			}
+		}
+	}
+	sort.SliceStable(order, func(i, j int) bool { return order[i].count > order[j].count })
+
+	header := []string{groupBy, queryGroupCountColumn}
+	if sumColumn != "" {
+		header = append(header, "sum_"+sumColumn)
+	}
+	table := make([][]string, 0, len(order))
+	for _, g := range order {
+		record := []string{g.key, strconv.Itoa(g.count)}
+		if sumColumn != "" {
+			record = append(record, strconv.FormatFloat(g.sum, 'f', -1, 64))
+		}
+		table = append(table, record)
+	}
+	return header, table
+}
+
+func condFields(conds []queryCondition) []string {
+	fields := make([]string, len(conds))
+	for i, c := range conds {
+		fields[i] = c.field
+	}
+	return fields
+}
+
+func stringArg(args message.ToolArgumentValues, name string) string {
+	s, _ := args[name].(string)
+	return strings.TrimSpace(s)
+}
+
+// stringListArg accepts an array of strings or a comma-separated string
+func stringListArg(args message.ToolArgumentValues, name string) []string {
+	var out []string
+	switch v := args[name].(type) {
+	case []any:
+		for _, item := range v {
+			if s, ok := item.(string); ok && strings.TrimSpace(s) != "" {
+				out = append(out, strings.TrimSpace(s))
+			}
+		}
+	case string:
+		for _, s := range strings.Split(v, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				out = append(out, s)
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,141 @@
+package tool
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fpt/go-gennai-cli/internal/infra"
+	"github.com/fpt/go-gennai-cli/internal/repository"
+	"github.com/fpt/go-gennai-cli/pkg/message"
+)
+
+func TestParseQueryWhere(t *testing.T) {
+	conds, err := parseQueryWhere(`status == "open" AND amount >= 10 and name contains 'ann'`)
+	if err != nil {
+		t.Fatalf("parseQueryWhere failed: %v", err)
+	}
+	want := []queryCondition{
+		{field: "status", op: "==", value: "open"},
+		{field: "amount", op: ">=", value: "10"},
+		{field: "name", op: "contains", value: "ann"},
+	}
+	if len(conds) != len(want) {
+		t.Fatalf("got %d conditions, want %d: %+v", len(conds), len(want), conds)
+	}
+	for i := range want {
+		if conds[i] != want[i] {
+			t.Errorf("condition %d = %+v, want %+v", i, conds[i], want[i])
+		}
+	}
+
+	if _, err := parseQueryWhere("amount"); err == nil {
+		t.Error("expected an error for a clause without an operator")
+	}
+}
+
+func TestQueryConditionNumericComparison(t *testing.T) {
+	row := map[string]string{"amount": "9"}
+	// Numeric, not lexical: "9" > "10" as strings but 9 < 10 as numbers
+	if (queryCondition{field: "amount", op: ">", value: "10"}).matches(row) {
+		t.Error("expected 9 > 10 to be false")
+	}
+	if !(queryCondition{field: "amount", op: "<", value: "10"}).matches(row) {
+		t.Error("expected 9 < 10 to be true")
+	}
+}
+
+func TestFileSystemToolManager_QueryData(t *testing.T) {
+	dir := t.TempDir()
+	csvData := "name,status,amount\nann,open,10\nbob,closed,5\ncat,open,7\ndan,open,3\n"
+	if err := os.WriteFile(filepath.Join(dir, "orders.csv"), []byte(csvData), 0644); err != nil {
+		t.Fatal(err)
+	}
+	jsonl := `{"team":"a","hours":2}` + "\n" + `{"team":"b","hours":1.5}` + "\n" + `{"team":"a","hours":3}` + "\n"
+	if err := os.WriteFile(filepath.Join(dir, "log.jsonl"), []byte(jsonl), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "secret.csv"), []byte("k,v\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	config := repository.FileSystemConfig{BlacklistedFiles: []string{"secret*"}}
+	manager := NewFileSystemToolManager(infra.NewOSFilesystemRepository(), config, dir)
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		args    message.ToolArgumentValues
+		want    []string
+		wantErr string
+	}{
+		{
+			name: "where and select",
+			args: message.ToolArgumentValues{"file_path": "orders.csv", "where": `status == open and amount > 5`, "select": []any{"name", "amount"}},
+			want: []string{"Scanned 4 row(s), 2 matched", "name,amount\nann,10\ncat,7\n"},
+		},
+		{
+			name: "limit",
+			args: message.ToolArgumentValues{"file_path": "orders.csv", "limit": float64(1)},
+			want: []string{"showing 1.", "name,status,amount\nann,open,10\n"},
+		},
+		{
+			name: "group by count",
+			args: message.ToolArgumentValues{"file_path": "orders.csv", "group_by": "status"},
+			want: []string{"status,count\nopen,3\nclosed,1\n"},
+		},
+		{
+			name: "group by sum on json lines",
+			args: message.ToolArgumentValues{"file_path": "log.jsonl", "group_by": "team", "sum": "hours"},
+			want: []string{"team,count,sum_hours\na,2,5\nb,1,1.5\n"},
+		},
+		{
+			name:    "unknown column",
+			args:    message.ToolArgumentValues{"file_path": "orders.csv", "select": "name,price"},
+			wantErr: `unknown column "price"`,
+		},
+		{
+			name:    "blacklisted file",
+			args:    message.ToolArgumentValues{"file_path": "secret.csv"},
+			wantErr: "blacklisted",
+		},
+		{
+			name:    "outside allowed directories",
+			args:    message.ToolArgumentValues{"file_path": "/etc/passwd.csv"},
+			wantErr: "outside working directory",
+		},
+		{
+			name:    "unsupported format",
+			args:    message.ToolArgumentValues{"file_path": "orders.txt"},
+			wantErr: "unsupported data file",
+		},
+	}
+	if err := os.WriteFile(filepath.Join(dir, "orders.txt"), []byte(csvData), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := manager.CallTool(ctx, "query_data", tt.args)
+			if err != nil {
+				t.Fatalf("CallTool returned error: %v", err)
+			}
+			if tt.wantErr != "" {
+				if !strings.Contains(res.Error, tt.wantErr) {
+					t.Errorf("expected error containing %q, got %+v", tt.wantErr, res)
+				}
+				return
+			}
+			if res.Error != "" {
+				t.Fatalf("unexpected error: %s", res.Error)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(res.Text, want) {
+					t.Errorf("result missing %q:\n%s", want, res.Text)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package tool
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fpt/go-gennai-cli/pkg/message"
+)
+
+// maxReadManyFiles is the number of files one read_many_files call may return
+const maxReadManyFiles = 50
+
+// readManyTargets resolves the paths and glob arguments into absolute paths,
+// each paired with the name shown in its BEGIN/END markers
+func (m *FileSystemToolManager) readManyTargets(ctx context.Context, args message.ToolArgumentValues) (paths, names []string, err error) {
+	seen := make(map[string]bool)
+	add := func(path, name string) {
+		if !seen[path] {
+			seen[path] = true
+			paths = append(paths, path)
+			names = append(names, name)
+		}
+	}
+
+	if raw, ok := args["paths"].([]interface{}); ok {
+		for _, v := range raw {
+			p, ok := v.(string)
+			if !ok || p == "" {
+				continue
+			}
+			path, err := m.resolvePath(p)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to resolve path %s: %v", p, err)
+			}
+			add(path, p)
+		}
+	}
+
+	if glob, _ := args["glob"].(string); glob != "" {
+		match, err := CompilePathGlob(glob)
+		if err != nil {
+			return nil, nil, err
+		}
+		files, err := m.collectSearchableFiles(ctx, m.workingDir)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to list files: %v", err)
+		}
+		for _, file := range files {
+			rel, err := filepath.Rel(m.workingDir, file)
+			if err == nil && match.MatchString(filepath.ToSlash(rel)) {
+				add(file, filepath.ToSlash(rel))
+			}
+		}
+	}
+	return paths, names, nil
+}
+
+// handleReadManyFiles returns the content of several files in one result, each
+// delimited like an @include in the prompt. Every file passes the same checks
+// as Read, and the combined output is capped at max_bytes.
+func (m *FileSystemToolManager) handleReadManyFiles(ctx context.Context, args message.ToolArgumentValues) (message.ToolResult, error) {
+	paths, names, err := m.readManyTargets(ctx, args)
+	if err != nil {
+		return message.NewToolResultError(err.Error()), nil
+	}
+	if len(paths) == 0 {
+		if _, ok := args["glob"].(string); ok {
+			return message.NewToolResultText("No files match the glob"), nil
+		}
+		return message.NewToolResultError("paths or glob parameter is required"), nil
+	}
+	if len(paths) > maxReadManyFiles {
+		return message.NewToolResultError(fmt.Sprintf("%d files requested, more than the limit of %d; narrow the list or glob", len(paths), maxReadManyFiles)), nil
+	}
+
+	maxBytes := m.maxReadBytes
+	if v, ok := args["max_bytes"].(float64); ok && v > 0 {
+		maxBytes = int(v)
+	}
+
+	var b strings.Builder
+	var skipped, omitted []string
+	for i, path := range paths {
+		name := names[i]
+		if ctx.Err() != nil {
+			return message.NewToolResultError("read cancelled"), nil
+		}
+		if len(omitted) > 0 {
+			omitted = append(omitted, name)
+			continue
+		}
+		if err := m.isPathAllowed(path); err != nil {
+			skipped = append(skipped, fmt.Sprintf("%s: %v", name, err))
+			continue
+		}
+		if err := m.isFileBlacklisted(path); err != nil {
+			skipped = append(skipped, fmt.Sprintf("%s: %v", name, err))
+			continue
+		}
+		content, err := m.fsRepo.ReadFile(ctx, path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				m.recordFileRead(path)
+				skipped = append(skipped, name+": file does not exist")
+			} else {
+				skipped = append(skipped, fmt.Sprintf("%s: failed to read file: %v", name, err))
+			}
+			continue
+		}
+		if isBinaryContent(content) {
+			skipped = append(skipped, name+": binary file")
+			continue
+		}
+
+		begin, end := "----- BEGIN "+name+" -----\n", "----- END "+name+" -----\n"
+		text := string(content)
+		if !strings.HasSuffix(text, "\n") {
+			text += "\n"
+		}
+		remaining := maxBytes - b.Len() - len(begin) - len(end)
+		if remaining < len(text) {
+			if remaining <= 0 {
+				omitted = append(omitted, name)
+				continue
+			}
+			// Cut the file that crosses the budget at a line boundary and stop there
+			text = truncateLine(text, remaining)
+			if nl := strings.LastIndex(text, "\n"); nl >= 0 {
+				text = text[:nl+1]
+			}
+			text += fmt.Sprintf("[truncated: %d of %d bytes shown; Read it with offset/limit for the rest]\n", len(text), len(content))
+			omitted = append(omitted, name)
+		}
+		m.recordFileRead(path)
+		b.WriteString(begin + text + end)
+	}
+
+	if len(omitted) > 0 {
+		fmt.Fprintf(&b, "\n[Output truncated at %d bytes. Not fully included: %s]\n", maxBytes, strings.Join(omitted, ", "))
+	}
+	if len(skipped) > 0 {
+		fmt.Fprintf(&b, "\n[Skipped %d file(s):\n  %s]\n", len(skipped), strings.Join(skipped, "\n  "))
+	}
+	return message.NewToolResultText(b.String()), nil
+}
@@ -0,0 +1,93 @@
+package tool
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/fpt/go-gennai-cli/internal/infra"
+	"github.com/fpt/go-gennai-cli/internal/repository"
+	"github.com/fpt/go-gennai-cli/pkg/message"
+)
+
+func TestFileSystemToolManager_ReadManyFiles(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"a.go":       "package a\n",
+		"sub/b.go":   "package b\n",
+		"notes.txt":  "notes",
+		"secret.go":  "package secret\n",
+		"image.png":  "\x89PNG\x00\x00",
+		"big/big.go": strings.Repeat("// filler line\n", 40),
+		"wide.txt":   strings.Repeat("日本語", 200),
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	config := repository.FileSystemConfig{BlacklistedFiles: []string{"secret.go"}}
+	manager := NewFileSystemToolManager(infra.NewOSFilesystemRepository(), config, dir)
+	ctx := context.Background()
+	call := func(args message.ToolArgumentValues) message.ToolResult {
+		t.Helper()
+		res, err := manager.CallTool(ctx, "read_many_files", args)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res
+	}
+
+	res := call(message.ToolArgumentValues{"paths": []interface{}{"a.go", "notes.txt", "secret.go", "image.png", "missing.go"}})
+	if res.Error != "" {
+		t.Fatalf("unexpected error: %s", res.Error)
+	}
+	for _, want := range []string{
+		"----- BEGIN a.go -----\npackage a\n----- END a.go -----\n",
+		"----- BEGIN notes.txt -----\nnotes\n----- END notes.txt -----\n",
+		"Skipped 3 file(s)", "secret.go: ", "image.png: binary file", "missing.go: file does not exist",
+	} {
+		if !strings.Contains(res.Text, want) {
+			t.Errorf("output missing %q:\n%s", want, res.Text)
+		}
+	}
+	if strings.Contains(res.Text, "package secret") {
+		t.Errorf("blacklisted content returned:\n%s", res.Text)
+	}
+
+	// Every returned file counts as read, so it can be edited right away
+	res, _ = manager.CallTool(ctx, "Edit", message.ToolArgumentValues{"file_path": "a.go", "old_string": "package a", "new_string": "package a2"})
+	if res.Error != "" {
+		t.Errorf("edit after read_many_files failed: %s", res.Error)
+	}
+
+	// A glob is combined with explicit paths without duplicates
+	res = call(message.ToolArgumentValues{"paths": []interface{}{"a.go"}, "glob": "**/*.go"})
+	if strings.Count(res.Text, "BEGIN a.go") != 1 || !strings.Contains(res.Text, "BEGIN sub/b.go") || strings.Contains(res.Text, "BEGIN secret.go") {
+		t.Errorf("unexpected glob output:\n%s", res.Text)
+	}
+
+	// The combined output is capped
+	res = call(message.ToolArgumentValues{"paths": []interface{}{"big/big.go", "a.go"}, "max_bytes": float64(300)})
+	if len(res.Text) > 500 || !strings.Contains(res.Text, "[truncated: ") || !strings.Contains(res.Text, "Not fully included: big/big.go, a.go") {
+		t.Errorf("expected a truncated result:\n%s", res.Text)
+	}
+
+	// A single long line is cut on a character boundary
+	res = call(message.ToolArgumentValues{"paths": []interface{}{"wide.txt"}, "max_bytes": float64(302)})
+	if !strings.Contains(res.Text, "[truncated: ") || !utf8.ValidString(res.Text) {
+		t.Errorf("expected valid UTF-8 truncated at a character boundary:\n%q", res.Text)
+	}
+
+	if res := call(message.ToolArgumentValues{}); !strings.Contains(res.Error, "paths or glob") {
+		t.Errorf("expected a missing-argument error, got %+v", res)
+	}
+}
@@ -0,0 +1,32 @@
+package tool
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/fpt/go-gennai-cli/pkg/secret"
+)
+
+// SetSecretScrubber tells the manager which secrets tool results hide from the
+// model, so writes that would replace one with secret.Redacted are refused
+func (m *FileSystemToolManager) SetSecretScrubber(scrubber *secret.Scrubber) {
+	m.scrubber = scrubber
+}
+
+// checkRedactedWrite refuses new content for path that adds the Redacted
+// placeholder while the file holds a secret. The model only saw the
+// placeholder, so content copied from a Read would overwrite the real value.
+func (m *FileSystemToolManager) checkRedactedWrite(ctx context.Context, path, updated string) error {
+	if m.scrubber == nil || !strings.Contains(updated, secret.Redacted) {
+		return nil
+	}
+	current := ""
+	if data, err := m.fsRepo.ReadFile(ctx, path); err == nil {
+		current = string(data)
+	}
+	if strings.Count(updated, secret.Redacted) <= strings.Count(current, secret.Redacted) || m.scrubber.Count(current) == 0 {
+		return nil
+	}
+	return fmt.Errorf("refusing to write %s: the new content contains %s where the file has a secret hidden from you, so the secret would be lost; change only the lines around it (e.g. with Edit) and leave the secret's line as it is", path, secret.Redacted)
+}
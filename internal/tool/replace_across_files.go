@@ -0,0 +1,166 @@
+package tool
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	pkgLogger "github.com/fpt/go-gennai-cli/pkg/logger"
+	"github.com/fpt/go-gennai-cli/pkg/message"
+)
+
+const (
+	maxReplaceFiles      = 200   // files one replace_across_files call may change
+	maxReplaceDiffChars  = 20000 // dry-run diff output before truncating
+	replaceDiffTruncated = "\n... diff truncated; narrow the glob to see the rest\n"
+)
+
+// fileReplacement is the substitution planned for one file
+type fileReplacement struct {
+	path, rel     string
+	before, after string
+	count         int
+}
+
+// planReplaceAcrossFiles finds the files matching glob whose content matches
+// pattern and computes their replaced content. Files too large to snapshot for
+// undo are skipped. It returns an error result when the call must be refused.
+func (m *FileSystemToolManager) planReplaceAcrossFiles(ctx context.Context, args message.ToolArgumentValues) ([]fileReplacement, *message.ToolResult) {
+	refuse := func(msg string) ([]fileReplacement, *message.ToolResult) {
+		res := message.NewToolResultError(msg)
+		return nil, &res
+	}
+
+	pattern, _ := args["pattern"].(string)
+	if pattern == "" {
+		return refuse("pattern parameter is required")
+	}
+	replacement, ok := args["replacement"].(string)
+	if !ok {
+		return refuse("replacement parameter is required (use an empty string to delete matches)")
+	}
+	glob, _ := args["glob"].(string)
+	if glob == "" {
+		return refuse("glob parameter is required")
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return refuse(fmt.Sprintf("invalid pattern: %v", err))
+	}
+	match, err := CompilePathGlob(glob)
+	if err != nil {
+		return refuse(err.Error())
+	}
+
+	files, err := m.collectSearchableFiles(ctx, m.workingDir)
+	if err != nil {
+		return refuse(fmt.Sprintf("failed to list files: %v", err))
+	}
+
+	var plan []fileReplacement
+	for _, file := range files {
+		if ctx.Err() != nil {
+			return refuse("replace cancelled")
+		}
+		rel, err := filepath.Rel(m.workingDir, file)
+		if err != nil || !match.MatchString(filepath.ToSlash(rel)) {
+			continue
+		}
+		content, err := m.fsRepo.ReadFile(ctx, file)
+		if err != nil || isBinaryContent(content) || len(content) > MaxUndoSnapshotBytes {
+			continue
+		}
+		before := string(content)
+		count := len(re.FindAllStringIndex(before, -1))
+		if count == 0 {
+			continue
+		}
+		after := re.ReplaceAllString(before, replacement)
+		if after == before {
+			continue
+		}
+		plan = append(plan, fileReplacement{path: file, rel: rel, before: before, after: after, count: count})
+	}
+
+	if len(plan) > maxReplaceFiles {
+		return refuse(fmt.Sprintf("pattern matches in %d files, more than the limit of %d; narrow the glob or pattern", len(plan), maxReplaceFiles))
+	}
+	return plan, nil
+}
+
+// handleReplaceAcrossFiles applies a regex substitution to every matching file.
+// With dry_run it only returns the diffs.
+func (m *FileSystemToolManager) handleReplaceAcrossFiles(ctx context.Context, args message.ToolArgumentValues) (message.ToolResult, error) {
+	plan, refusal := m.planReplaceAcrossFiles(ctx, args)
+	if refusal != nil {
+		return *refusal, nil
+	}
+	if len(plan) == 0 {
+		pattern, _ := args["pattern"].(string)
+		glob, _ := args["glob"].(string)
+		return message.NewToolResultText(fmt.Sprintf("No matches for pattern %q in files matching %q; nothing changed", pattern, glob)), nil
+	}
+
+	total := 0
+	var summary strings.Builder
+	for _, r := range plan {
+		total += r.count
+		fmt.Fprintf(&summary, "%s: %d replacement(s)\n", r.rel, r.count)
+	}
+
+	if dryRun, _ := args["dry_run"].(bool); dryRun {
+		var b strings.Builder
+		fmt.Fprintf(&b, "Dry run: %d replacement(s) in %d file(s) would be made, nothing was written.\n%s\n", total, len(plan), summary.String())
+		diff := replacementDiff(plan)
+		if len(diff) > maxReplaceDiffChars {
+			diff = diff[:maxReplaceDiffChars] + replaceDiffTruncated
+		}
+		b.WriteString(diff)
+		return message.NewToolResultText(b.String()), nil
+	}
+
+	var failed []string
+	written := 0
+	for _, r := range plan {
+		if !m.snapshotBeforeWrite(ctx, r.path, "replace_across_files") {
+			failed = append(failed, fmt.Sprintf("%s: its content could not be kept for undo, so it was not changed", r.rel))
+			continue
+		}
+		// WriteFile keeps the mode of an existing file
+		if err := m.fsRepo.WriteFile(ctx, r.path, []byte(r.after), defaultFileMode); err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", r.rel, err))
+			continue
+		}
+		m.recordFileRead(r.path)
+		written++
+	}
+
+	logger.DebugWithIntention(pkgLogger.IntentionTool, "Replaced across files", "files", written, "replacements", total)
+	var b strings.Builder
+	fmt.Fprintf(&b, "Made %d replacement(s) in %d file(s) (undo_last_edit with count %d reverts them):\n%s", total, written, written, summary.String())
+	if len(failed) > 0 {
+		fmt.Fprintf(&b, "\nFailed to write %d file(s):\n%s\n", len(failed), strings.Join(failed, "\n"))
+	}
+	return message.NewToolResultText(b.String()), nil
+}
+
+// ReplaceAcrossFilesDiff returns the unified diff a replace_across_files call
+// would make, or "" when it would change nothing or be refused
+func (m *FileSystemToolManager) ReplaceAcrossFilesDiff(ctx context.Context, args message.ToolArgumentValues) string {
+	plan, refusal := m.planReplaceAcrossFiles(ctx, args)
+	if refusal != nil {
+		return ""
+	}
+	return replacementDiff(plan)
+}
+
+func replacementDiff(plan []fileReplacement) string {
+	var b strings.Builder
+	for _, r := range plan {
+		b.WriteString(UnifiedDiff(r.rel, r.before, r.after))
+	}
+	return b.String()
+}
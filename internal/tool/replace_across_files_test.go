@@ -0,0 +1,123 @@
+package tool
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fpt/go-gennai-cli/internal/infra"
+	"github.com/fpt/go-gennai-cli/internal/repository"
+	"github.com/fpt/go-gennai-cli/pkg/message"
+)
+
+func TestFileSystemToolManager_ReplaceAcrossFiles(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"main.go":          "package main\n\nfunc oldName() {}\n\nvar _ = oldName\n",
+		"pkg/util/util.go": "package util\n\n// oldName is documented\n",
+		"notes.txt":        "oldName in prose\n",
+		"secret.go":        "package main\n\nvar oldName = 1\n",
+		"vendor/dep.go":    "package dep\n\nfunc oldName() {}\n",
+		"blob.go":          "oldName\x00binary",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".gennaiignore"), []byte("vendor/\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Too large to snapshot, so undo could not restore it
+	large := "package main\n\nvar oldName = 1\n" + strings.Repeat("//\n", MaxUndoSnapshotBytes/3)
+	if err := os.WriteFile(filepath.Join(dir, "large.go"), []byte(large), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	config := repository.FileSystemConfig{BlacklistedFiles: []string{"secret.go"}}
+	manager := NewFileSystemToolManager(infra.NewOSFilesystemRepository(), config, dir)
+	ctx := context.Background()
+	call := func(args message.ToolArgumentValues) message.ToolResult {
+		t.Helper()
+		res, err := manager.CallTool(ctx, "replace_across_files", args)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res
+	}
+	readBack := func(name string) string {
+		t.Helper()
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+
+	args := message.ToolArgumentValues{"pattern": `\boldName\b`, "replacement": "newName", "glob": "*.go", "dry_run": true}
+	res := call(args)
+	if res.Error != "" {
+		t.Fatalf("dry run failed: %s", res.Error)
+	}
+	for _, want := range []string{"main.go: 2 replacement(s)", "pkg/util/util.go: 1 replacement(s)", "-func oldName() {}", "+func newName() {}"} {
+		if !strings.Contains(res.Text, want) {
+			t.Errorf("dry run output missing %q:\n%s", want, res.Text)
+		}
+	}
+	for _, skipped := range []string{"secret.go", "vendor", "blob.go", "notes.txt", "large.go"} {
+		if strings.Contains(res.Text, skipped) {
+			t.Errorf("dry run should skip %s:\n%s", skipped, res.Text)
+		}
+	}
+	if got := readBack("main.go"); got != files["main.go"] {
+		t.Errorf("dry run wrote main.go: %q", got)
+	}
+	if diff := manager.ReplaceAcrossFilesDiff(ctx, args); !strings.Contains(diff, "+++ b/pkg/util/util.go") {
+		t.Errorf("unexpected preview diff:\n%s", diff)
+	}
+
+	delete(args, "dry_run")
+	args["replacement"] = "${0}V2"
+	res = call(args)
+	if res.Error != "" || !strings.Contains(res.Text, "Made 3 replacement(s) in 2 file(s)") {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	if got := readBack("main.go"); got != "package main\n\nfunc oldNameV2() {}\n\nvar _ = oldNameV2\n" {
+		t.Errorf("main.go = %q", got)
+	}
+	if got := readBack("secret.go"); got != files["secret.go"] {
+		t.Errorf("blacklisted file was changed: %q", got)
+	}
+	if got := readBack("large.go"); got != large {
+		t.Errorf("file too large to undo was changed")
+	}
+
+	// Each file is its own undo snapshot
+	manager.CallTool(ctx, "undo_last_edit", message.ToolArgumentValues{"count": float64(2)})
+	if got := readBack("main.go"); got != files["main.go"] {
+		t.Errorf("after undo main.go = %q", got)
+	}
+
+	for _, tc := range []struct {
+		args message.ToolArgumentValues
+		want string
+	}{
+		{message.ToolArgumentValues{"pattern": "(", "replacement": "x", "glob": "*.go"}, "invalid pattern"},
+		{message.ToolArgumentValues{"pattern": "x", "replacement": "x", "glob": "src/[a.go"}, "unterminated"},
+		{message.ToolArgumentValues{"pattern": "x", "glob": "*.go"}, "replacement parameter is required"},
+	} {
+		if res := call(tc.args); !strings.Contains(res.Error, tc.want) {
+			t.Errorf("args %v: error = %q, want it to mention %q", tc.args, res.Error, tc.want)
+		}
+	}
+	if res := call(message.ToolArgumentValues{"pattern": "absent", "replacement": "x", "glob": "**"}); res.Error != "" || !strings.Contains(res.Text, "nothing changed") {
+		t.Errorf("expected no-match message, got %+v", res)
+	}
+}
@@ -0,0 +1,107 @@
+package tool
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"sync"
+
+	"github.com/fpt/go-gennai-cli/pkg/message"
+)
+
+// pathArguments are the argument names that hold file paths
+var pathArguments = []string{"file_path", "other_path", "source_path", "destination_path", "path"}
+
+// cachedResult is a memoized tool result and the files it was read from
+type cachedResult struct {
+	result message.ToolResult
+	paths  []string // nil = may depend on any file
+}
+
+// resultCache memoizes results of cacheable tools by tool name and arguments.
+// Path-scoped writes drop the results read from those paths; other writes and
+// tools that may change anything (bash, apply_patch, MCP tools, ...) drop
+// everything. The tools' declared effects decide which case applies.
+type resultCache struct {
+	mu         sync.Mutex
+	workingDir string
+	entries    map[string]cachedResult
+}
+
+func newResultCache(workingDir string) *resultCache {
+	return &resultCache{workingDir: workingDir, entries: make(map[string]cachedResult)}
+}
+
+// key returns the cache key for a call, or false when the tool is not cacheable
+func (c *resultCache) key(name message.ToolName, effects message.ToolEffects, args message.ToolArgumentValues) (string, bool) {
+	if !effects.Cacheable {
+		return "", false
+	}
+	// Map keys marshal in sorted order, so equal arguments give equal keys
+	encoded, err := json.Marshal(args)
+	if err != nil {
+		return "", false
+	}
+	return string(name) + "\x00" + string(encoded), true
+}
+
+func (c *resultCache) get(key string) (message.ToolResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	return entry.result, ok
+}
+
+func (c *resultCache) put(key string, effects message.ToolEffects, args message.ToolArgumentValues, result message.ToolResult) {
+	var paths []string
+	if effects.PathScoped {
+		paths = c.argumentPaths(args)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = cachedResult{result: result, paths: paths}
+}
+
+// invalidate drops the results a call to a non-cacheable tool may have made stale
+func (c *resultCache) invalidate(effects message.ToolEffects, args message.ToolArgumentValues) {
+	if effects.Access == message.AccessRead || effects.Access == message.AccessAgentState {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if effects.Access != message.AccessWrite || !effects.PathScoped {
+		c.entries = make(map[string]cachedResult)
+		return
+	}
+	written := make(map[string]bool)
+	for _, path := range c.argumentPaths(args) {
+		written[path] = true
+	}
+	for key, entry := range c.entries {
+		if entry.paths == nil {
+			delete(c.entries, key)
+			continue
+		}
+		for _, path := range entry.paths {
+			if written[path] {
+				delete(c.entries, key)
+				break
+			}
+		}
+	}
+}
+
+// argumentPaths returns the cleaned absolute paths named in args
+func (c *resultCache) argumentPaths(args message.ToolArgumentValues) []string {
+	var paths []string
+	for _, name := range pathArguments {
+		path, ok := args[name].(string)
+		if !ok || path == "" {
+			continue
+		}
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(c.workingDir, path)
+		}
+		paths = append(paths, filepath.Clean(path))
+	}
+	return paths
+}
@@ -0,0 +1,215 @@
+package tool
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/fpt/go-gennai-cli/pkg/message"
+)
+
+// MaxScratchpadBytes caps the scratchpad, which is added to every prompt
+const MaxScratchpadBytes = 4096
+
+// ScratchpadToolManager provides the scratchpad tool: free-form notes the
+// model keeps for itself across turns. The notes are injected into each
+// prompt, so they survive compaction.
+type ScratchpadToolManager struct {
+	tools map[message.ToolName]message.Tool
+
+	mu      sync.Mutex
+	path    string // file the notes persist to ("" = in memory only)
+	content string
+}
+
+// NewScratchpadToolManager creates a scratchpad persisted to path, loading
+// any notes already saved there
+func NewScratchpadToolManager(path string) *ScratchpadToolManager {
+	manager := &ScratchpadToolManager{tools: make(map[message.ToolName]message.Tool)}
+	if err := manager.UseFile(path); err != nil {
+		logger.Warn("Failed to load scratchpad, starting empty", "path", path, "error", err)
+	}
+	manager.registerScratchpadTools()
+	return manager
+}
+
+// NewInMemoryScratchpadToolManager creates a scratchpad that is not persisted
+func NewInMemoryScratchpadToolManager() *ScratchpadToolManager {
+	return NewScratchpadToolManager("")
+}
+
+// UseFile switches the scratchpad to the notes saved at path, e.g. when the
+// session changes. An empty path keeps the current notes in memory only.
+func (m *ScratchpadToolManager) UseFile(path string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.path = path
+	if path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			m.content = ""
+			return nil
+		}
+		return err
+	}
+	m.content = string(data)
+	return nil
+}
+
+// Content returns the current notes
+func (m *ScratchpadToolManager) Content() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.content
+}
+
+// Clear removes all notes
+func (m *ScratchpadToolManager) Clear() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.save("")
+}
+
+// GetScratchpadForPrompt returns the notes for injection into prompt context
+func (m *ScratchpadToolManager) GetScratchpadForPrompt() string {
+	return strings.TrimSpace(m.Content())
+}
+
+// Implement domain.ToolManager interface
+func (m *ScratchpadToolManager) GetTool(name message.ToolName) (message.Tool, bool) {
+	tool, exists := m.tools[name]
+	return tool, exists
+}
+
+func (m *ScratchpadToolManager) GetTools() map[message.ToolName]message.Tool {
+	return m.tools
+}
+
+func (m *ScratchpadToolManager) CallTool(ctx context.Context, name message.ToolName, args message.ToolArgumentValues) (message.ToolResult, error) {
+	tool, exists := m.tools[name]
+	if !exists {
+		return message.NewToolResultError(fmt.Sprintf("tool %s not found", name)), nil
+	}
+
+	handler := tool.Handler()
+	return handler(ctx, args)
+}
+
+// RegisterTool registers a tool that declares no effects (message.AccessUnknown)
+func (m *ScratchpadToolManager) RegisterTool(name message.ToolName, description message.ToolDescription, args []message.ToolArgument, handler func(ctx context.Context, args message.ToolArgumentValues) (message.ToolResult, error)) {
+	m.registerTool(name, message.ToolEffects{}, description, args, handler)
+}
+
+// registerTool registers a built-in tool with the effects it declares
+func (m *ScratchpadToolManager) registerTool(name message.ToolName, effects message.ToolEffects, description message.ToolDescription, args []message.ToolArgument, handler func(ctx context.Context, args message.ToolArgumentValues) (message.ToolResult, error)) {
+	m.tools[name] = &scratchpadTool{
+		name:        name,
+		description: description,
+		arguments:   args,
+		handler:     handler,
+		effects:     effects,
+	}
+}
+
+func (m *ScratchpadToolManager) registerScratchpadTools() {
+	m.registerTool("scratchpad", message.AgentStateChange, message.ToolDescription(fmt.Sprintf("Keep short notes for yourself that persist across turns and survive history compaction, e.g. where the config lives or how the project builds. The notes are shown with every request, so record facts you would otherwise re-derive, not progress (use todo_write for tasks). Actions: read, append (adds text as a new line), clear. Limited to %d bytes; clear and re-append a condensed version when full.", MaxScratchpadBytes)),
+		[]message.ToolArgument{
+			{
+				Name:        "action",
+				Description: "One of: read, append, clear",
+				Required:    true,
+				Type:        "string",
+			},
+			{
+				Name:        "text",
+				Description: "The note to add (append only)",
+				Required:    false,
+				Type:        "string",
+			},
+		},
+		m.handleScratchpad)
+}
+
+func (m *ScratchpadToolManager) handleScratchpad(ctx context.Context, args message.ToolArgumentValues) (message.ToolResult, error) {
+	action, _ := args["action"].(string)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	switch action {
+	case "read":
+		if strings.TrimSpace(m.content) == "" {
+			return message.NewToolResultText("The scratchpad is empty."), nil
+		}
+		return message.NewToolResultText(m.content), nil
+	case "append":
+		text, _ := args["text"].(string)
+		text = strings.TrimSpace(text)
+		if text == "" {
+			return message.NewToolResultError("text is required for append"), nil
+		}
+		updated := text + "\n"
+		if m.content != "" {
+			updated = strings.TrimRight(m.content, "\n") + "\n" + updated
+		}
+		if len(updated) > MaxScratchpadBytes {
+			return message.NewToolResultError(fmt.Sprintf("scratchpad would grow to %d bytes (limit %d); clear it and append a condensed version", len(updated), MaxScratchpadBytes)), nil
+		}
+		if err := m.save(updated); err != nil {
+			return message.NewToolResultError(fmt.Sprintf("failed to save scratchpad: %v", err)), nil
+		}
+		return message.NewToolResultText(fmt.Sprintf("Note added (%d of %d bytes used).", len(updated), MaxScratchpadBytes)), nil
+	case "clear":
+		if err := m.save(""); err != nil {
+			return message.NewToolResultError(fmt.Sprintf("failed to clear scratchpad: %v", err)), nil
+		}
+		return message.NewToolResultText("Scratchpad cleared."), nil
+	default:
+		return message.NewToolResultError(fmt.Sprintf("invalid action '%s', must be read, append, or clear", action)), nil
+	}
+}
+
+// save replaces the notes and persists them; the caller holds m.mu
+func (m *ScratchpadToolManager) save(content string) error {
+	if m.path != "" {
+		if content == "" {
+			if err := os.Remove(m.path); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		} else {
+			if err := os.MkdirAll(filepath.Dir(m.path), 0755); err != nil {
+				return err
+			}
+			if err := os.WriteFile(m.path, []byte(content), 0644); err != nil {
+				return err
+			}
+		}
+	}
+	m.content = content
+	return nil
+}
+
+type scratchpadTool struct {
+	name        message.ToolName
+	description message.ToolDescription
+	arguments   []message.ToolArgument
+	handler     func(ctx context.Context, args message.ToolArgumentValues) (message.ToolResult, error)
+	effects     message.ToolEffects
+}
+
+func (t *scratchpadTool) RawName() message.ToolName            { return t.name }
+func (t *scratchpadTool) Name() message.ToolName               { return t.name }
+func (t *scratchpadTool) Description() message.ToolDescription { return t.description }
+func (t *scratchpadTool) Arguments() []message.ToolArgument    { return t.arguments }
+func (t *scratchpadTool) Handler() func(ctx context.Context, args message.ToolArgumentValues) (message.ToolResult, error) {
+	return t.handler
+}
+
+func (t *scratchpadTool) Effects() message.ToolEffects {
+	return t.effects
+}
@@ -0,0 +1,58 @@
+package tool
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fpt/go-gennai-cli/pkg/message"
+)
+
+func TestScratchpadToolManager(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "session.scratchpad.md")
+	m := NewScratchpadToolManager(path)
+
+	res, _ := m.CallTool(ctx, "scratchpad", message.ToolArgumentValues{"action": "read"})
+	if res.Error != "" || !strings.Contains(res.Text, "empty") {
+		t.Errorf("expected an empty scratchpad, got %+v", res)
+	}
+
+	for _, note := range []string{"config lives in internal/config", "build with make build"} {
+		res, _ = m.CallTool(ctx, "scratchpad", message.ToolArgumentValues{"action": "append", "text": note})
+		if res.Error != "" {
+			t.Fatalf("append failed: %s", res.Error)
+		}
+	}
+	want := "config lives in internal/config\nbuild with make build"
+	if got := m.GetScratchpadForPrompt(); got != want {
+		t.Errorf("GetScratchpadForPrompt() = %q, want %q", got, want)
+	}
+
+	// Notes persist to the session file
+	if got := NewScratchpadToolManager(path).GetScratchpadForPrompt(); got != want {
+		t.Errorf("reloaded scratchpad = %q, want %q", got, want)
+	}
+
+	res, _ = m.CallTool(ctx, "scratchpad", message.ToolArgumentValues{"action": "append", "text": strings.Repeat("x", MaxScratchpadBytes)})
+	if !strings.Contains(res.Error, "limit") {
+		t.Errorf("expected an append over the size limit to fail, got %+v", res)
+	}
+	if got := m.GetScratchpadForPrompt(); got != want {
+		t.Errorf("expected a rejected append to leave the notes unchanged, got %q", got)
+	}
+
+	res, _ = m.CallTool(ctx, "scratchpad", message.ToolArgumentValues{"action": "clear"})
+	if res.Error != "" || m.Content() != "" {
+		t.Errorf("expected clear to empty the scratchpad, got %+v and %q", res, m.Content())
+	}
+	if got := NewScratchpadToolManager(path).Content(); got != "" {
+		t.Errorf("expected clear to remove the saved notes, got %q", got)
+	}
+
+	res, _ = m.CallTool(ctx, "scratchpad", message.ToolArgumentValues{"action": "rewrite"})
+	if res.Error == "" {
+		t.Error("expected an unknown action to fail")
+	}
+}
@@ -3,6 +3,7 @@ package tool
 import (
 	"context"
 	"fmt"
+	"os"
 	"os/exec"
 	"path/filepath"
 	"sort"
@@ -16,6 +17,7 @@ import (
 type SearchToolManager struct {
 	tools      map[message.ToolName]message.Tool
 	workingDir string
+	ignore     *projectIgnore // Per-project exclusions from .gennaiignore
 }
 
 type SearchConfig struct {
@@ -26,6 +28,7 @@ func NewSearchToolManager(cfg SearchConfig) domain.ToolManager {
 	m := &SearchToolManager{
 		tools:      make(map[message.ToolName]message.Tool),
 		workingDir: cfg.WorkingDir,
+		ignore:     newProjectIgnore(cfg.WorkingDir),
 	}
 	m.register()
 	return m
@@ -35,8 +38,15 @@ func (m *SearchToolManager) GetTool(name message.ToolName) (message.Tool, bool)
 	return m.tools[name], m.tools[name] != nil
 }
 func (m *SearchToolManager) GetTools() map[message.ToolName]message.Tool { return m.tools }
+
+// RegisterTool registers a tool that declares no effects (message.AccessUnknown)
 func (m *SearchToolManager) RegisterTool(name message.ToolName, desc message.ToolDescription, args []message.ToolArgument, handler func(ctx context.Context, args message.ToolArgumentValues) (message.ToolResult, error)) {
-	m.tools[name] = &searchTool{name: name, description: desc, arguments: args, handler: handler}
+	m.registerTool(name, message.ToolEffects{}, desc, args, handler)
+}
+
+// registerTool registers a built-in tool with the effects it declares
+func (m *SearchToolManager) registerTool(name message.ToolName, effects message.ToolEffects, desc message.ToolDescription, args []message.ToolArgument, handler func(ctx context.Context, args message.ToolArgumentValues) (message.ToolResult, error)) {
+	m.tools[name] = &searchTool{name: name, description: desc, arguments: args, handler: handler, effects: effects}
 }
 func (m *SearchToolManager) CallTool(ctx context.Context, name message.ToolName, args message.ToolArgumentValues) (message.ToolResult, error) {
 	t, ok := m.tools[name]
@@ -48,14 +58,14 @@ func (m *SearchToolManager) CallTool(ctx context.Context, name message.ToolName,
 
 func (m *SearchToolManager) register() {
 	// Glob tool: fast file listing by pattern
-	m.RegisterTool("Glob", "Find files by glob pattern (e.g., **/*.go)",
+	m.registerTool("Glob", message.CachedSearch, "Find files by glob pattern (e.g., **/*.go)",
 		[]message.ToolArgument{
 			{Name: "pattern", Description: "Glob pattern to match", Required: true, Type: "string"},
 			{Name: "path", Description: "Base directory (optional)", Required: false, Type: "string"},
 		}, m.handleGlob)
 
 	// Grep tool: ripgrep-style content search
-	m.RegisterTool("Grep", "Search file contents using ripgrep-compatible flags",
+	m.registerTool("Grep", message.CachedSearch, "Search file contents using ripgrep-compatible flags",
 		[]message.ToolArgument{
 			{Name: "pattern", Description: "Regex pattern to search", Required: true, Type: "string"},
 			{Name: "path", Description: "File/dir to search (optional)", Required: false, Type: "string"},
@@ -115,7 +125,7 @@ func (m *SearchToolManager) handleGlob(ctx context.Context, args message.ToolArg
 				b.WriteString(f)
 				b.WriteString("\n")
 			}
-			return message.NewToolResultText(strings.TrimSuffix(b.String(), "\n")), nil
+			return message.NewToolResultText(m.ignore.filterLines(strings.TrimSuffix(b.String(), "\n"), base, "")), nil
 		}
 		// fall through to find on error
 	}
@@ -136,7 +146,7 @@ func (m *SearchToolManager) handleGlob(ctx context.Context, args message.ToolArg
 	if err != nil {
 		return message.NewToolResultError(fmt.Sprintf("find failed: %v\nOutput: %s", err, string(out))), nil
 	}
-	return message.NewToolResultText(strings.TrimSpace(string(out))), nil
+	return message.NewToolResultText(m.ignore.filterLines(strings.TrimSpace(string(out)), base, "")), nil
 }
 
 // handleGrep executes ripgrep when available; falls back to grep
@@ -154,6 +164,9 @@ func (m *SearchToolManager) handleGrep(ctx context.Context, args message.ToolArg
 		}
 		base = rp
 	}
+	if info, err := os.Stat(base); err == nil && m.ignore.matches(base, info.IsDir()) {
+		return message.NewToolResultError(fmt.Sprintf("search denied: %s is excluded by %s", base, gennaiIgnoreFile)), nil
+	}
 
 	outputMode := "files_with_matches"
 	if om, ok := args["output_mode"].(string); ok && om != "" {
@@ -214,7 +227,7 @@ func (m *SearchToolManager) handleGrep(ctx context.Context, args message.ToolArg
 			}
 			return message.NewToolResultError(fmt.Sprintf("rg failed: %v\nOutput: %s", err, string(out))), nil
 		}
-		text := string(out)
+		text := m.ignore.filterLines(string(out), base, ":")
 		// head_limit trim
 		if v, ok := args["head_limit"].(float64); ok && int(v) > 0 {
 			lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
@@ -251,7 +264,7 @@ func (m *SearchToolManager) handleGrep(ctx context.Context, args message.ToolArg
 		}
 		return message.NewToolResultError(fmt.Sprintf("grep failed: %v\nOutput: %s", err, string(out))), nil
 	}
-	text := string(out)
+	text := m.ignore.filterLines(string(out), base, ":")
 	if v, ok := args["head_limit"].(float64); ok && int(v) > 0 {
 		lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
 		if len(lines) > int(v) {
@@ -266,6 +279,7 @@ type searchTool struct {
 	description message.ToolDescription
 	arguments   []message.ToolArgument
 	handler     func(ctx context.Context, args message.ToolArgumentValues) (message.ToolResult, error)
+	effects     message.ToolEffects
 }
 
 func (t *searchTool) RawName() message.ToolName            { return t.name }
@@ -275,3 +289,5 @@ func (t *searchTool) Arguments() []message.ToolArgument    { return t.arguments
 func (t *searchTool) Handler() func(ctx context.Context, args message.ToolArgumentValues) (message.ToolResult, error) {
 	return t.handler
 }
+
+func (t *searchTool) Effects() message.ToolEffects { return t.effects }
@@ -0,0 +1,339 @@
+package tool
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/fpt/go-gennai-cli/pkg/message"
+)
+
+const (
+	maxSummarizeChars     = 12000 // output cap for summarize_path
+	maxSummarizeFiles     = 200   // files listed for a directory
+	defaultSummarizeDepth = 2     // directory levels listed by default
+	maxSummaryLineBytes   = 100   // length of a file's one-line summary
+)
+
+// outlinePatterns find top-level definitions in non-Go sources, by extension
+var outlinePatterns = map[string]*regexp.Regexp{
+	".md":   regexp.MustCompile(`^#{1,6}\s+\S`),
+	".py":   regexp.MustCompile(`^\s*(?:async\s+def|def|class)\s+\w+`),
+	".js":   regexp.MustCompile(`^\s*(?:export\s+(?:default\s+)?)?(?:async\s+)?(?:function\*?\s+\w+|class\s+\w+|(?:const|let|var)\s+\w+\s*=\s*(?:async\s*)?(?:\([^)]*\)|\w+)\s*=>)`),
+	".ts":   regexp.MustCompile(`^\s*(?:export\s+(?:default\s+)?)?(?:abstract\s+)?(?:async\s+)?(?:function\*?\s+\w+|class\s+\w+|interface\s+\w+|type\s+\w+\s*=|enum\s+\w+|(?:const|let)\s+\w+\s*=\s*(?:async\s*)?\([^)]*\)\s*(?::[^=]+)?=>)`),
+	".rs":   regexp.MustCompile(`^\s*(?:pub(?:\([^)]*\))?\s+)?(?:async\s+)?(?:fn|struct|enum|trait|impl|mod|type)\b`),
+	".java": regexp.MustCompile(`^\s*(?:public|protected|private|static|abstract|final|\s)*(?:class|interface|enum|record)\s+\w+|^\s+(?:public|protected|private)[\w<>\[\],\s]*\s+\w+\s*\(`),
+	".rb":   regexp.MustCompile(`^\s*(?:class|module|def)\s+\S`),
+	".sh":   regexp.MustCompile(`^\s*(?:function\s+\w+|\w+\s*\(\)\s*\{)`),
+	".c":    regexp.MustCompile(`^(?:static\s+|extern\s+|inline\s+)*(?:struct\s+\w+\s*\{|typedef\b|[A-Za-z_][\w\s\*]*\s\**\w+\s*\([^;]*$)`),
+}
+
+// outlineAliases share a pattern with a listed extension
+var outlineAliases = map[string]string{
+	".markdown": ".md", ".jsx": ".js", ".mjs": ".js", ".cjs": ".js", ".tsx": ".ts",
+	".kt": ".java", ".scala": ".java", ".cs": ".java", ".bash": ".sh", ".zsh": ".sh",
+	".h": ".c", ".cc": ".c", ".cpp": ".c", ".hpp": ".c",
+}
+
+// outlinePattern returns the definition pattern for a file name, or nil
+func outlinePattern(name string) *regexp.Regexp {
+	ext := strings.ToLower(filepath.Ext(name))
+	if alias, ok := outlineAliases[ext]; ok {
+		ext = alias
+	}
+	return outlinePatterns[ext]
+}
+
+// handleSummarizePath outlines a file or lists a directory's files with one-line summaries
+func (m *FileSystemToolManager) handleSummarizePath(ctx context.Context, args message.ToolArgumentValues) (message.ToolResult, error) {
+	pathParam := stringArg(args, "path")
+	if pathParam == "" {
+		pathParam = "."
+	}
+	absPath, err := m.resolvePath(pathParam)
+	if err != nil {
+		return message.NewToolResultError(fmt.Sprintf("failed to resolve path: %v", err)), nil
+	}
+	if err := m.isPathAllowed(absPath); err != nil {
+		return message.NewToolResultError(err.Error()), nil
+	}
+	isDir, err := m.fsRepo.IsDir(ctx, absPath)
+	if err != nil {
+		return message.NewToolResultError(fmt.Sprintf("failed to access path: %v", err)), nil
+	}
+
+	var out string
+	if isDir {
+		maxDepth := defaultSummarizeDepth
+		if v, ok := args["max_depth"].(float64); ok && v > 0 {
+			maxDepth = int(v)
+		}
+		out, err = m.summarizeDirectory(ctx, absPath, maxDepth)
+	} else {
+		if err := m.isFileBlacklisted(absPath); err != nil {
+			return message.NewToolResultError(err.Error()), nil
+		}
+		out, err = m.outlineFile(ctx, absPath)
+	}
+	if err != nil {
+		return message.NewToolResultError(err.Error()), nil
+	}
+
+	if len(out) > maxSummarizeChars {
+		out = out[:maxSummarizeChars]
+		if i := strings.LastIndexByte(out, '\n'); i > 0 {
+			out = out[:i+1]
+		}
+		out += fmt.Sprintf("... output truncated at %d characters; summarize a narrower path\n", maxSummarizeChars)
+	}
+	return message.NewToolResultText(out), nil
+}
+
+// outlineFile renders the structural outline of one file
+func (m *FileSystemToolManager) outlineFile(ctx context.Context, path string) (string, error) {
+	content, err := m.fsRepo.ReadFile(ctx, path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read file: %v", err)
+	}
+	if isBinaryContent(content) {
+		return "", fmt.Errorf("%s is a binary file", path)
+	}
+
+	lines := bytes.Count(content, []byte("\n"))
+	header := fmt.Sprintf("%s (%d lines)\n", path, lines)
+	if strings.HasSuffix(path, ".go") {
+		if outline, err := outlineGo(path, content); err == nil {
+			return header + outline, nil
+		}
+		// Fall back to the generic outline for files that do not parse
+	}
+	if pattern := outlinePattern(path); pattern != nil {
+		if outline := outlineByPattern(content, pattern); outline != "" {
+			return header + outline, nil
+		}
+	}
+	return header + "(no outline available for this file type; use Read with offset/limit)\n", nil
+}
+
+// outlineGo lists the package, imports and top-level declarations of a Go file
+// with their line numbers
+func outlineGo(path string, content []byte) (string, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, content, parser.ParseComments|parser.SkipObjectResolution)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "package %s\n", file.Name.Name)
+	if doc := firstSentence(file.Doc.Text()); doc != "" {
+		fmt.Fprintf(&b, "  // %s\n", doc)
+	}
+	if len(file.Imports) > 0 {
+		imports := make([]string, len(file.Imports))
+		for i, spec := range file.Imports {
+			imports[i] = strings.Trim(spec.Path.Value, `"`)
+		}
+		fmt.Fprintf(&b, "imports: %s\n", strings.Join(imports, ", "))
+	}
+
+	for _, decl := range file.Decls {
+		line := fset.Position(decl.Pos()).Line
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			fmt.Fprintf(&b, "L%d: %s\n", line, goFuncSignature(fset, d))
+		case *ast.GenDecl:
+			if d.Tok == token.IMPORT {
+				continue
+			}
+			for _, spec := range d.Specs {
+				specLine := fset.Position(spec.Pos()).Line
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					fmt.Fprintf(&b, "L%d: type %s %s\n", specLine, s.Name.Name, goTypeKind(s.Type))
+				case *ast.ValueSpec:
+					names := make([]string, len(s.Names))
+					for i, n := range s.Names {
+						names[i] = n.Name
+					}
+					fmt.Fprintf(&b, "L%d: %s %s\n", specLine, d.Tok, strings.Join(names, ", "))
+				}
+			}
+		}
+	}
+	return b.String(), nil
+}
+
+// goFuncSignature prints a function declaration without its body
+func goFuncSignature(fset *token.FileSet, fn *ast.FuncDecl) string {
+	sig := *fn
+	sig.Body = nil
+	sig.Doc = nil
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, fset, &sig); err != nil {
+		return "func " + fn.Name.Name
+	}
+	return strings.Join(strings.Fields(buf.String()), " ")
+}
+
+// goTypeKind describes a type expression briefly, e.g. "struct (3 fields)"
+func goTypeKind(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StructType:
+		return fmt.Sprintf("struct (%d fields)", t.Fields.NumFields())
+	case *ast.InterfaceType:
+		return fmt.Sprintf("interface (%d methods)", t.Methods.NumFields())
+	case *ast.FuncType:
+		return "func"
+	case *ast.MapType:
+		return "map"
+	case *ast.ArrayType:
+		return "slice"
+	case *ast.ChanType:
+		return "chan"
+	case *ast.Ident:
+		return t.Name
+	case *ast.SelectorExpr:
+		if pkg, ok := t.X.(*ast.Ident); ok {
+			return pkg.Name + "." + t.Sel.Name
+		}
+	}
+	return ""
+}
+
+// outlineByPattern lists the lines matching a definition pattern with their
+// line numbers
+func outlineByPattern(content []byte, pattern *regexp.Regexp) string {
+	var b strings.Builder
+	for i, line := range strings.Split(string(content), "\n") {
+		if pattern.MatchString(line) {
+			fmt.Fprintf(&b, "L%d: %s\n", i+1, truncateLine(strings.TrimRight(strings.TrimSpace(line), "{: "), 160))
+		}
+	}
+	return b.String()
+}
+
+// summarizeDirectory lists the files under dir up to maxDepth levels, each
+// with a one-line summary
+func (m *FileSystemToolManager) summarizeDirectory(ctx context.Context, dir string, maxDepth int) (string, error) {
+	files, err := m.collectSearchableFiles(ctx, dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to read directory: %v", err)
+	}
+
+	var b strings.Builder
+	b.WriteString(dir + "/\n")
+	listed, skipped := 0, 0
+	for _, path := range files {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		rel, _ := filepath.Rel(dir, path)
+		if strings.Count(rel, string(filepath.Separator)) >= maxDepth || isHiddenPath(rel) {
+			continue
+		}
+		if listed >= maxSummarizeFiles {
+			skipped++
+			continue
+		}
+		listed++
+		content, err := m.fsRepo.ReadFile(ctx, path)
+		if err != nil {
+			continue
+		}
+		fmt.Fprintf(&b, "  %s — %s\n", filepath.ToSlash(rel), summarizeFileContent(rel, content))
+	}
+	if listed == 0 {
+		b.WriteString("  (no readable files)\n")
+	}
+	if skipped > 0 {
+		fmt.Fprintf(&b, "... %d more files not listed; summarize a subdirectory or lower max_depth\n", skipped)
+	}
+	return b.String(), nil
+}
+
+// isHiddenPath reports whether any element of a relative path starts with "."
+func isHiddenPath(rel string) bool {
+	for _, part := range strings.Split(rel, string(filepath.Separator)) {
+		if strings.HasPrefix(part, ".") {
+			return true
+		}
+	}
+	return false
+}
+
+// summarizeFileContent returns a one-line description of a file: a Go file's
+// package doc or declarations, a Markdown title, or its first definitions
+func summarizeFileContent(name string, content []byte) string {
+	if isBinaryContent(content) {
+		return fmt.Sprintf("binary, %d bytes", len(content))
+	}
+	lines := bytes.Count(content, []byte("\n"))
+
+	var summary string
+	if strings.HasSuffix(name, ".go") {
+		summary = summarizeGoFile(name, content)
+	} else if pattern := outlinePattern(name); pattern != nil {
+		var defs []string
+		for _, line := range strings.Split(string(content), "\n") {
+			if pattern.MatchString(line) {
+				defs = append(defs, strings.TrimRight(strings.TrimSpace(line), "{: "))
+			}
+		}
+		if len(defs) > 0 {
+			summary = strings.Join(defs, "; ")
+		}
+	}
+	if summary == "" {
+		summary = fmt.Sprintf("%d lines", lines)
+	} else {
+		summary = fmt.Sprintf("%s (%d lines)", summary, lines)
+	}
+	return truncateLine(summary, maxSummaryLineBytes)
+}
+
+// summarizeGoFile describes a Go file by its package doc, or its package and
+// top-level names
+func summarizeGoFile(name string, content []byte) string {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, name, content, parser.ParseComments|parser.SkipObjectResolution)
+	if err != nil {
+		return ""
+	}
+	if doc := firstSentence(file.Doc.Text()); doc != "" {
+		return fmt.Sprintf("package %s: %s", file.Name.Name, doc)
+	}
+	var names []string
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			names = append(names, d.Name.Name)
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				if s, ok := spec.(*ast.TypeSpec); ok {
+					names = append(names, s.Name.Name)
+				}
+			}
+		}
+	}
+	if len(names) == 0 {
+		return "package " + file.Name.Name
+	}
+	return fmt.Sprintf("package %s: %s", file.Name.Name, strings.Join(names, ", "))
+}
+
+// firstSentence returns the first sentence of a doc comment on one line
+func firstSentence(doc string) string {
+	doc = strings.Join(strings.Fields(doc), " ")
+	if i := strings.Index(doc, ". "); i >= 0 {
+		doc = doc[:i+1]
+	}
+	return doc
+}
@@ -0,0 +1,127 @@
+package tool
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fpt/go-gennai-cli/internal/infra"
+	"github.com/fpt/go-gennai-cli/internal/repository"
+	"github.com/fpt/go-gennai-cli/pkg/message"
+)
+
+const summarizeGoSource = `// Package server serves the API. It has more to say.
+package server
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// Server handles requests
+type Server struct {
+	addr string
+	mux  *http.ServeMux
+}
+
+type Handler interface {
+	Serve() error
+}
+
+const defaultAddr = ":8080"
+
+var ErrClosed, ErrBusy = fmt.Errorf("closed"), fmt.Errorf("busy")
+
+// New creates a server
+func New(addr string) *Server {
+	return &Server{addr: addr}
+}
+
+func (s *Server) Start(ctx interface{}, retries int) (bool, error) {
+	return false, nil
+}
+`
+
+func TestFileSystemToolManager_SummarizePath(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("server/server.go", summarizeGoSource)
+	write("README.md", "# Project\n\nIntro\n\n## Usage\n")
+	write("scripts/build.py", "import os\n\nclass Builder:\n    def run(self):\n        pass\n")
+	write("secret.env", "TOKEN=abc")
+	write("deep/a/b/c.txt", "too deep")
+
+	config := repository.FileSystemConfig{BlacklistedFiles: []string{"*.env"}}
+	m := NewFileSystemToolManager(infra.NewOSFilesystemRepository(), config, dir)
+	call := func(args message.ToolArgumentValues) message.ToolResult {
+		t.Helper()
+		res, err := m.CallTool(context.Background(), "summarize_path", args)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res
+	}
+
+	res := call(message.ToolArgumentValues{"path": "server/server.go"})
+	if res.Error != "" {
+		t.Fatalf("unexpected error: %s", res.Error)
+	}
+	for _, want := range []string{
+		"package server",
+		"// Package server serves the API.",
+		"imports: fmt, net/http",
+		"L10: type Server struct (2 fields)",
+		"type Handler interface (1 methods)",
+		"const defaultAddr",
+		"var ErrClosed, ErrBusy",
+		"L24: func New(addr string) *Server",
+		"func (s *Server) Start(ctx interface{}, retries int) (bool, error)",
+	} {
+		if !strings.Contains(res.Text, want) {
+			t.Errorf("Go outline missing %q:\n%s", want, res.Text)
+		}
+	}
+	if strings.Contains(res.Text, "return &Server") {
+		t.Errorf("outline should not include function bodies:\n%s", res.Text)
+	}
+
+	res = call(message.ToolArgumentValues{"path": "scripts/build.py"})
+	if !strings.Contains(res.Text, "L3: class Builder") || !strings.Contains(res.Text, "L4: def run(self)") {
+		t.Errorf("unexpected Python outline:\n%s", res.Text)
+	}
+
+	res = call(message.ToolArgumentValues{})
+	for _, want := range []string{
+		"README.md — # Project; ## Usage",
+		"server/server.go — package server: Package server serves the API.",
+		"scripts/build.py — class Builder; def run(self)",
+	} {
+		if !strings.Contains(res.Text, want) {
+			t.Errorf("directory summary missing %q:\n%s", want, res.Text)
+		}
+	}
+	if strings.Contains(res.Text, "secret.env") || strings.Contains(res.Text, "c.txt") {
+		t.Errorf("directory summary should skip blacklisted and deep files:\n%s", res.Text)
+	}
+	if res = call(message.ToolArgumentValues{"max_depth": float64(4)}); !strings.Contains(res.Text, "deep/a/b/c.txt — 0 lines") {
+		t.Errorf("expected deep file with max_depth 4:\n%s", res.Text)
+	}
+
+	if res = call(message.ToolArgumentValues{"path": "secret.env"}); res.Error == "" {
+		t.Error("expected blacklisted file to be refused")
+	}
+	if res = call(message.ToolArgumentValues{"path": "/etc"}); res.Error == "" {
+		t.Error("expected path outside allowed directories to be refused")
+	}
+}
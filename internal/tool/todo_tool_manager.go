@@ -98,12 +98,19 @@ func (m *TodoToolManager) CallTool(ctx context.Context, name message.ToolName, a
 	return handler(ctx, args)
 }
 
+// RegisterTool registers a tool that declares no effects (message.AccessUnknown)
 func (m *TodoToolManager) RegisterTool(name message.ToolName, description message.ToolDescription, args []message.ToolArgument, handler func(ctx context.Context, args message.ToolArgumentValues) (message.ToolResult, error)) {
+	m.registerTool(name, message.ToolEffects{}, description, args, handler)
+}
+
+// registerTool registers a built-in tool with the effects it declares
+func (m *TodoToolManager) registerTool(name message.ToolName, effects message.ToolEffects, description message.ToolDescription, args []message.ToolArgument, handler func(ctx context.Context, args message.ToolArgumentValues) (message.ToolResult, error)) {
 	tool := &todoTool{
 		name:        name,
 		description: description,
 		arguments:   args,
 		handler:     handler,
+		effects:     effects,
 	}
 	m.tools[name] = tool
 }
@@ -130,7 +137,7 @@ func (m *TodoToolManager) IsAllCompleted() bool {
 func (m *TodoToolManager) registerTodoTools() {
 	// TodoWrite - Write/update todo list
 	// Note: TodoRead removed - todos are injected into prompt context instead
-	m.RegisterTool("todo_write", "Write or update the todo list with tasks and their status. Use statuses: pending, in_progress, completed (accepts 'done' as completed). Keep ≤5 items.",
+	m.registerTool("todo_write", message.AgentStateChange, "Write or update the todo list with tasks and their status. Use statuses: pending, in_progress, completed (accepts 'done' as completed). Keep ≤5 items.",
 		[]message.ToolArgument{
 			{
 				Name:        "todos",
@@ -145,13 +152,13 @@ func (m *TodoToolManager) registerTodoTools() {
 // registerTaskTools registers small compatibility stubs for task tools
 func (m *TodoToolManager) registerTaskTools() {
 	// exit_plan_mode: acknowledge plan and signal ready
-	m.RegisterTool("exit_plan_mode", "Acknowledge plan and exit planning mode (stub).",
+	m.registerTool("exit_plan_mode", message.AgentStateChange, "Acknowledge plan and exit planning mode (stub).",
 		[]message.ToolArgument{
 			{Name: "plan", Description: "Concise implementation plan", Required: true, Type: "string"},
 		}, m.handleExitPlanMode)
 
-	// Task: sub-agent launcher (stub)
-	m.RegisterTool("Task", "Launch a sub-agent (stub). Not supported; use Glob/Grep/Read/WebFetch directly.",
+	// Task: sub-agent launcher (stub); a sub-agent may call any tool
+	m.registerTool("Task", message.AnyChange, "Launch a sub-agent (stub). Not supported; use Glob/Grep/Read/WebFetch directly.",
 		[]message.ToolArgument{
 			{Name: "description", Description: "Short task description", Required: true, Type: "string"},
 			{Name: "prompt", Description: "Detailed task for the agent", Required: true, Type: "string"},
@@ -344,6 +351,7 @@ type todoTool struct {
 	description message.ToolDescription
 	arguments   []message.ToolArgument
 	handler     func(ctx context.Context, args message.ToolArgumentValues) (message.ToolResult, error)
+	effects     message.ToolEffects
 }
 
 func (t *todoTool) RawName() message.ToolName {
@@ -365,3 +373,7 @@ func (t *todoTool) Arguments() []message.ToolArgument {
 func (t *todoTool) Handler() func(ctx context.Context, args message.ToolArgumentValues) (message.ToolResult, error) {
 	return t.handler
 }
+
+func (t *todoTool) Effects() message.ToolEffects {
+	return t.effects
+}
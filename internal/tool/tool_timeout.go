@@ -0,0 +1,98 @@
+package tool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	pkgLogger "github.com/fpt/go-gennai-cli/pkg/logger"
+	"github.com/fpt/go-gennai-cli/pkg/message"
+)
+
+// DefaultToolTimeout bounds tool calls that have no configured timeout
+const DefaultToolTimeout = 5 * time.Minute
+
+// ToolTimeoutConfig holds per-tool execution budgets
+type ToolTimeoutConfig struct {
+	Default time.Duration                      // Budget for tools without an entry (0 = DefaultToolTimeout)
+	PerTool map[message.ToolName]time.Duration // Budgets by tool name
+}
+
+// timeoutFor returns the budget for the named tool
+func (c ToolTimeoutConfig) timeoutFor(name message.ToolName) time.Duration {
+	if d, ok := c.PerTool[name]; ok && d > 0 {
+		return d
+	}
+	if c.Default > 0 {
+		return c.Default
+	}
+	return DefaultToolTimeout
+}
+
+// ownTimeoutGrace lets a tool with its own timeout report it, with any partial
+// output, before the budget cancels it
+const ownTimeoutGrace = 5 * time.Second
+
+// selfTimedTool is implemented by tools that enforce a timeout of their own,
+// such as bash and run_tests
+type selfTimedTool interface {
+	ownTimeout(args message.ToolArgumentValues) (time.Duration, bool)
+}
+
+// budgetFor returns the budget of a call: the configured one, raised to cover
+// the tool's own timeout
+func (c ToolTimeoutConfig) budgetFor(name message.ToolName, tool message.Tool, args message.ToolArgumentValues) time.Duration {
+	timeout := c.timeoutFor(name)
+	if timed, ok := tool.(selfTimedTool); ok {
+		if own, ok := timed.ownTimeout(args); ok && own+ownTimeoutGrace > timeout {
+			timeout = own + ownTimeoutGrace
+		}
+	}
+	return timeout
+}
+
+// callWithTimeout runs a tool with its budget. A call that exceeds it yields an
+// error result so the agent loop continues; cancellation of the parent context
+// is still returned as an error. Write tools have no budget, so a change is
+// never cut off halfway.
+func callWithTimeout(ctx context.Context, cfg ToolTimeoutConfig, name message.ToolName, tool message.Tool, args message.ToolArgumentValues) (message.ToolResult, error) {
+	access := message.EffectsOf(tool).Access
+	if access == message.AccessWrite {
+		return tool.Handler()(ctx, args)
+	}
+
+	timeout := cfg.budgetFor(name, tool, args)
+	toolCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	type callResult struct {
+		result message.ToolResult
+		err    error
+	}
+	done := make(chan callResult, 1)
+	go func() {
+		result, err := tool.Handler()(toolCtx, args)
+		done <- callResult{result, err}
+	}()
+
+	// Read-only handlers that ignore their context are abandoned rather than
+	// stalling the turn. Any other tool may still be changing something, so it
+	// is awaited: its result must not arrive after the agent has moved on.
+	var res callResult
+	select {
+	case res = <-done:
+	case <-toolCtx.Done():
+		if access == message.AccessRead {
+			res = callResult{err: toolCtx.Err()}
+		} else {
+			res = <-done
+		}
+	}
+
+	if ctx.Err() == nil && errors.Is(toolCtx.Err(), context.DeadlineExceeded) && (res.err != nil || res.result.Error != "") {
+		logger.WarnWithIntention(pkgLogger.IntentionWarning, "Tool exceeded its time budget", "tool", name, "timeout", timeout)
+		return message.NewToolResultError(fmt.Sprintf("tool %s exceeded its time budget of %s and was cancelled", name, timeout)), nil
+	}
+	return res.result, res.err
+}
@@ -0,0 +1,178 @@
+package tool
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	pkgLogger "github.com/fpt/go-gennai-cli/pkg/logger"
+	"github.com/fpt/go-gennai-cli/pkg/message"
+)
+
+const (
+	// MaxUndoSnapshotBytes is the largest file whose previous content is kept for undo
+	MaxUndoSnapshotBytes = 1024 * 1024
+	// maxUndoSnapshots bounds the undo stack; the oldest snapshots are dropped first
+	maxUndoSnapshots = 50
+)
+
+// editSnapshot is a file's state before a write or edit
+type editSnapshot struct {
+	path      string
+	tool      string
+	existed   bool        // false when the change created the file; undo removes it
+	content   []byte      // previous content when existed
+	mode      fs.FileMode // previous permissions when existed
+	dir       bool        // the path was an (empty) directory; undo recreates it
+	tooLarge  bool        // the file exceeded MaxUndoSnapshotBytes and could not be kept
+	movedFrom string      // set by move_file: undo moves the file back here, then restores path
+}
+
+// snapshotBeforeWrite records path's current content so the change about to be
+// made by tool can be undone. It reports whether undo can restore that content.
+func (m *FileSystemToolManager) snapshotBeforeWrite(ctx context.Context, path, tool string) bool {
+	snap, ok := m.takeSnapshot(ctx, path, tool)
+	if !ok {
+		return false
+	}
+	m.recordSnapshot(snap)
+	return !snap.tooLarge
+}
+
+// takeSnapshot reads path's current state. It reports false when the state
+// could not be read.
+func (m *FileSystemToolManager) takeSnapshot(ctx context.Context, path, tool string) (editSnapshot, bool) {
+	snap := editSnapshot{path: path, tool: tool}
+	info, err := m.fsRepo.Stat(ctx, path)
+	switch {
+	case os.IsNotExist(err):
+	case err != nil:
+		logger.DebugWithIntention(pkgLogger.IntentionDebug, "Skipping undo snapshot", "path", path, "error", err)
+		return snap, false
+	case info.IsDir():
+		snap.existed, snap.dir, snap.mode = true, true, info.Mode().Perm()
+	case info.Size() > MaxUndoSnapshotBytes:
+		snap.existed, snap.tooLarge = true, true
+	default:
+		content, err := m.fsRepo.ReadFile(ctx, path)
+		if err != nil {
+			logger.DebugWithIntention(pkgLogger.IntentionDebug, "Skipping undo snapshot", "path", path, "error", err)
+			return snap, false
+		}
+		snap.existed, snap.content, snap.mode = true, content, info.Mode().Perm()
+	}
+	return snap, true
+}
+
+// recordSnapshot pushes snap onto the undo stack. Other files the same change
+// touches are passed as alsoChanged so change tracking sees them too.
+func (m *FileSystemToolManager) recordSnapshot(snap editSnapshot, alsoChanged ...editSnapshot) {
+	m.undoMu.Lock()
+	defer m.undoMu.Unlock()
+	for _, other := range alsoChanged {
+		m.trackChange(other)
+	}
+	m.trackChange(snap)
+	m.undoStack = append(m.undoStack, snap)
+	if len(m.undoStack) > maxUndoSnapshots {
+		m.undoStack = m.undoStack[len(m.undoStack)-maxUndoSnapshots:]
+	}
+}
+
+// UndoLastEdit restores the file changed by the most recent write or edit and
+// describes what was reverted. Repeated calls walk further down the stack.
+func (m *FileSystemToolManager) UndoLastEdit(ctx context.Context) (string, error) {
+	m.undoMu.Lock()
+	defer m.undoMu.Unlock()
+	if len(m.undoStack) == 0 {
+		return "", fmt.Errorf("nothing to undo")
+	}
+	snap := m.undoStack[len(m.undoStack)-1]
+	m.undoStack = m.undoStack[:len(m.undoStack)-1]
+
+	switch {
+	case snap.movedFrom != "":
+		return m.undoMove(ctx, snap)
+	case snap.tooLarge:
+		return "", fmt.Errorf("cannot undo %s of %s: the file was larger than %d bytes, so its previous content was not kept", snap.tool, snap.path, MaxUndoSnapshotBytes)
+	case !snap.existed:
+		if err := m.fsRepo.Remove(ctx, snap.path); err != nil && !os.IsNotExist(err) {
+			return "", fmt.Errorf("failed to remove %s: %w", snap.path, err)
+		}
+		return fmt.Sprintf("Reverted %s of %s: removed the file it created", snap.tool, snap.path), nil
+	case snap.dir:
+		if err := os.MkdirAll(snap.path, snap.mode); err != nil {
+			return "", fmt.Errorf("failed to recreate %s: %w", snap.path, err)
+		}
+		return fmt.Sprintf("Reverted %s of %s: recreated the directory", snap.tool, snap.path), nil
+	default:
+		// The file may have been deleted along with its directory
+		if err := os.MkdirAll(filepath.Dir(snap.path), 0755); err != nil {
+			return "", fmt.Errorf("failed to restore %s: %w", snap.path, err)
+		}
+		if err := m.fsRepo.WriteFile(ctx, snap.path, snap.content, snap.mode); err != nil {
+			return "", fmt.Errorf("failed to restore %s: %w", snap.path, err)
+		}
+		// The restored content is what the model last saw, so allow editing it again
+		m.recordFileRead(snap.path)
+		return fmt.Sprintf("Reverted %s of %s: restored %d bytes", snap.tool, snap.path, len(snap.content)), nil
+	}
+}
+
+// undoMove moves a file back to where move_file found it, then restores the
+// file the move replaced, if any. Called with undoMu held.
+func (m *FileSystemToolManager) undoMove(ctx context.Context, snap editSnapshot) (string, error) {
+	if err := os.MkdirAll(filepath.Dir(snap.movedFrom), 0755); err != nil {
+		return "", fmt.Errorf("failed to move %s back: %w", snap.path, err)
+	}
+	if err := m.fsRepo.Rename(ctx, snap.path, snap.movedFrom); err != nil {
+		return "", fmt.Errorf("failed to move %s back to %s: %w", snap.path, snap.movedFrom, err)
+	}
+	m.moveReadTimestamp(snap.path, snap.movedFrom)
+	summary := fmt.Sprintf("Reverted move_file of %s: moved it back from %s", snap.movedFrom, snap.path)
+
+	switch {
+	case !snap.existed:
+		return summary, nil
+	case snap.tooLarge:
+		return "", fmt.Errorf("%s, but the file it replaced there was larger than %d bytes, so its content was not kept", summary, MaxUndoSnapshotBytes)
+	default:
+		if err := m.fsRepo.WriteFile(ctx, snap.path, snap.content, snap.mode); err != nil {
+			return "", fmt.Errorf("%s, but failed to restore the file it replaced: %w", summary, err)
+		}
+		m.recordFileRead(snap.path)
+		return fmt.Sprintf("%s and restored the %d bytes it replaced", summary, len(snap.content)), nil
+	}
+}
+
+// UndoDepth returns the number of changes that can be undone
+func (m *FileSystemToolManager) UndoDepth() int {
+	m.undoMu.Lock()
+	defer m.undoMu.Unlock()
+	return len(m.undoStack)
+}
+
+// handleUndoLastEdit reverts the last count file changes, newest first
+func (m *FileSystemToolManager) handleUndoLastEdit(ctx context.Context, args message.ToolArgumentValues) (message.ToolResult, error) {
+	count := 1
+	if v, ok := args["count"].(float64); ok && v >= 1 {
+		count = int(v)
+	}
+
+	var reverted []string
+	for i := 0; i < count; i++ {
+		summary, err := m.UndoLastEdit(ctx)
+		if err != nil {
+			if len(reverted) == 0 {
+				return message.NewToolResultError(err.Error()), nil
+			}
+			reverted = append(reverted, fmt.Sprintf("Stopped: %v", err))
+			break
+		}
+		reverted = append(reverted, summary)
+	}
+	return message.NewToolResultText(strings.Join(reverted, "\n")), nil
+}
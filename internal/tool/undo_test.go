@@ -0,0 +1,95 @@
+package tool
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fpt/go-gennai-cli/internal/infra"
+	"github.com/fpt/go-gennai-cli/internal/repository"
+	"github.com/fpt/go-gennai-cli/pkg/message"
+)
+
+func TestFileSystemToolManager_UndoLastEdit(t *testing.T) {
+	dir := t.TempDir()
+	manager := NewFileSystemToolManager(infra.NewOSFilesystemRepository(), repository.FileSystemConfig{}, dir)
+	ctx := context.Background()
+	path := filepath.Join(dir, "main.go")
+
+	call := func(name message.ToolName, args message.ToolArgumentValues) message.ToolResult {
+		t.Helper()
+		res, err := manager.CallTool(ctx, name, args)
+		if err != nil || res.Error != "" {
+			t.Fatalf("%s failed: %v %s", name, err, res.Error)
+		}
+		return res
+	}
+	readBack := func() string {
+		t.Helper()
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("read: %v", err)
+		}
+		return string(data)
+	}
+
+	call("Write", message.ToolArgumentValues{"file_path": path, "content": "one\n"})
+	call("Edit", message.ToolArgumentValues{"file_path": path, "old_string": "one", "new_string": "two"})
+	call("Edit", message.ToolArgumentValues{"file_path": path, "old_string": "two", "new_string": "three"})
+	if depth := manager.UndoDepth(); depth != 3 {
+		t.Fatalf("UndoDepth = %d, want 3", depth)
+	}
+
+	res := call("undo_last_edit", message.ToolArgumentValues{})
+	if !strings.Contains(res.Text, "Reverted Edit of "+path) {
+		t.Errorf("unexpected undo summary: %s", res.Text)
+	}
+	if got := readBack(); got != "two\n" {
+		t.Errorf("after one undo content = %q, want %q", got, "two\n")
+	}
+
+	// The restored file can be edited again without another Read
+	call("Edit", message.ToolArgumentValues{"file_path": path, "old_string": "two", "new_string": "four"})
+	call("undo_last_edit", message.ToolArgumentValues{})
+
+	res = call("undo_last_edit", message.ToolArgumentValues{"count": float64(2)})
+	if !strings.Contains(res.Text, "removed the file it created") {
+		t.Errorf("expected the created file to be removed, got: %s", res.Text)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected %s to be removed, stat error: %v", path, err)
+	}
+
+	res, _ = manager.CallTool(ctx, "undo_last_edit", message.ToolArgumentValues{})
+	if !strings.Contains(res.Error, "nothing to undo") {
+		t.Errorf("expected nothing to undo, got %+v", res)
+	}
+}
+
+func TestFileSystemToolManager_UndoSkipsLargeFiles(t *testing.T) {
+	dir := t.TempDir()
+	manager := NewFileSystemToolManager(infra.NewOSFilesystemRepository(), repository.FileSystemConfig{}, dir)
+	ctx := context.Background()
+	path := filepath.Join(dir, "big.txt")
+	if err := os.WriteFile(path, []byte(strings.Repeat("x", MaxUndoSnapshotBytes+1)), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	manager.recordFileRead(path)
+	res, _ := manager.CallTool(ctx, "Write", message.ToolArgumentValues{"file_path": path, "content": "small"})
+	if res.Error != "" {
+		t.Fatalf("Write failed: %s", res.Error)
+	}
+
+	if _, err := manager.UndoLastEdit(ctx); err == nil || !strings.Contains(err.Error(), "not kept") {
+		t.Errorf("expected a too-large error, got %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "small" {
+		t.Errorf("file should be left as written, got %d bytes", len(data))
+	}
+	if depth := manager.UndoDepth(); depth != 0 {
+		t.Errorf("UndoDepth = %d, want 0", depth)
+	}
+}
@@ -0,0 +1,43 @@
+package tool
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	t.Run("modified line with context", func(t *testing.T) {
+		oldText := "a\nb\nc\nd\ne\nf\ng\nh\n"
+		newText := "a\nb\nc\nd\nE\nf\ng\nh\n"
+		want := "--- a/x.txt\n+++ b/x.txt\n@@ -2,7 +2,7 @@\n b\n c\n d\n-e\n+E\n f\n g\n h\n"
+		if got := UnifiedDiff("x.txt", oldText, newText); got != want {
+			t.Errorf("unexpected diff:\n%s\nwant:\n%s", got, want)
+		}
+	})
+
+	t.Run("new file is all added", func(t *testing.T) {
+		want := "--- /dev/null\n+++ b/new.go\n@@ -0,0 +1,2 @@\n+package main\n+\n"
+		if got := UnifiedDiff("new.go", "", "package main\n\n"); got != want {
+			t.Errorf("unexpected diff:\n%s\nwant:\n%s", got, want)
+		}
+	})
+
+	t.Run("distant changes produce separate hunks", func(t *testing.T) {
+		var lines []string
+		for i := 0; i < 20; i++ {
+			lines = append(lines, "line")
+		}
+		oldText := strings.Join(lines, "\n")
+		lines[1], lines[18] = "first", "last"
+		got := UnifiedDiff("f", oldText, strings.Join(lines, "\n"))
+		if n := strings.Count(got, "@@ -"); n != 2 {
+			t.Errorf("expected 2 hunks, got %d:\n%s", n, got)
+		}
+	})
+
+	t.Run("identical content", func(t *testing.T) {
+		if got := UnifiedDiff("f", "same\n", "same\n"); got != "" {
+			t.Errorf("expected empty diff, got %q", got)
+		}
+	})
+}
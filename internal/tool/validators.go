@@ -0,0 +1,535 @@
+package tool
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/fpt/go-gennai-cli/internal/repository"
+	pkgLogger "github.com/fpt/go-gennai-cli/pkg/logger"
+	"gopkg.in/yaml.v3"
+)
+
+// ValidationResult represents the result of a validation check
+type ValidationResult struct {
+	Check   string `json:"check"`
+	Status  string `json:"status"` // "pass", "fail", "error"
+	Output  string `json:"output,omitempty"`
+	Summary string `json:"summary"`
+}
+
+// Validator checks a file after it was written or edited. Validators whose
+// toolchain is not installed return no results, so the edit is reported as-is.
+type Validator interface {
+	Name() string                // settings name, e.g. "go"
+	Label() string               // heading for the results, e.g. "Go"
+	CanValidate(ext string) bool // ext is lower-case and includes the dot
+	Validate(ctx context.Context, dir, file string) []ValidationResult
+}
+
+// newValidators returns the built-in validators except the disabled ones
+func newValidators(fsRepo repository.FilesystemRepository, disabled []string) []Validator {
+	all := []Validator{
+		&goValidator{fsRepo: fsRepo},
+		pythonValidator{},
+		javaScriptValidator{},
+		rustValidator{},
+		&dataValidator{fsRepo: fsRepo, name: "json", label: "JSON", exts: []string{".json"}, parse: checkJSON, skip: isJSONCFile},
+		&dataValidator{fsRepo: fsRepo, name: "yaml", label: "YAML", exts: []string{".yaml", ".yml"}, parse: checkYAML},
+		&dataValidator{fsRepo: fsRepo, name: "toml", label: "TOML", exts: []string{".toml"}, parse: checkTOML},
+	}
+	known := make(map[string]bool)
+	var names []string
+	for _, v := range all {
+		known[v.Name()] = true
+		names = append(names, v.Name())
+	}
+	skip := make(map[string]bool)
+	for _, name := range disabled {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !known[name] {
+			logger.WarnWithIntention(pkgLogger.IntentionWarning, "Unknown validator in disabled_validators",
+				"name", name, "known", strings.Join(names, ", "))
+		}
+		skip[name] = true
+	}
+
+	var enabled []Validator
+	for _, v := range all {
+		if !skip[v.Name()] {
+			enabled = append(enabled, v)
+		}
+	}
+	return enabled
+}
+
+// autoValidateFile performs automatic validation after write/edit operations based on file type
+func (m *FileSystemToolManager) autoValidateFile(ctx context.Context, filePath string) string {
+	ext := strings.ToLower(filepath.Ext(filePath))
+	dir := filepath.Dir(filePath)
+	fileName := filepath.Base(filePath)
+
+	var output strings.Builder
+	failed := false
+	for _, v := range m.validators {
+		if !v.CanValidate(ext) {
+			continue
+		}
+		results := v.Validate(ctx, dir, fileName)
+		output.WriteString(formatValidationResults(v.Label(), results))
+		for _, result := range results {
+			failed = failed || result.Status == "fail"
+		}
+	}
+	m.recordValidation(filePath, failed, output.String())
+	return output.String()
+}
+
+// recordValidation remembers the report of a file that failed validation and
+// forgets it once the file passes
+func (m *FileSystemToolManager) recordValidation(filePath string, failed bool, report string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if failed {
+		m.validationFailures[filePath] = report
+	} else {
+		delete(m.validationFailures, filePath)
+	}
+}
+
+// ClearValidationFailures forgets earlier validation failures, so that only
+// files written or edited from now on are reported
+func (m *FileSystemToolManager) ClearValidationFailures() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	clear(m.validationFailures)
+}
+
+// RecheckValidationFailures validates the files whose last post-edit check
+// failed again, since they may have been fixed with other tools, and returns
+// the reports of those still failing with the number of files
+func (m *FileSystemToolManager) RecheckValidationFailures(ctx context.Context) (string, int) {
+	m.mu.RLock()
+	paths := make([]string, 0, len(m.validationFailures))
+	for path := range m.validationFailures {
+		paths = append(paths, path)
+	}
+	m.mu.RUnlock()
+	slices.Sort(paths)
+
+	var report strings.Builder
+	files := 0
+	for _, path := range paths {
+		if exists, err := m.fsRepo.Exists(ctx, path); err == nil && !exists {
+			m.recordValidation(path, false, "")
+			continue
+		}
+		output := m.autoValidateFile(ctx, path)
+		m.mu.RLock()
+		_, failing := m.validationFailures[path]
+		m.mu.RUnlock()
+		if failing {
+			report.WriteString(fmt.Sprintf("### %s%s\n", path, output))
+			files++
+		}
+	}
+	return report.String(), files
+}
+
+// goValidator runs go vet and go build -n on the edited file
+type goValidator struct {
+	fsRepo repository.FilesystemRepository
+}
+
+func (v *goValidator) Name() string                { return "go" }
+func (v *goValidator) Label() string               { return "Go" }
+func (v *goValidator) CanValidate(ext string) bool { return ext == ".go" }
+
+func (v *goValidator) Validate(ctx context.Context, dir, fileName string) []ValidationResult {
+	// Check if this looks like a Go project (has .go files)
+	hasGoFiles, err := v.hasGoFilesInDirectory(ctx, dir)
+	if err != nil || !hasGoFiles {
+		return nil
+	}
+
+	results := []ValidationResult{}
+
+	// Run go vet on the specific file
+	vetResult := v.runGoVet(ctx, dir, fileName)
+	results = append(results, vetResult)
+
+	// Run go build -n (dry run) on the specific file
+	buildResult := v.runGoBuild(ctx, dir, fileName)
+	results = append(results, buildResult)
+
+	return results
+}
+
+// hasGoFilesInDirectory checks if directory contains .go files
+func (v *goValidator) hasGoFilesInDirectory(ctx context.Context, dir string) (bool, error) {
+	entries, err := v.fsRepo.ReadDir(ctx, dir)
+	if err != nil {
+		return false, err
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".go") {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// runGoVet executes go vet and returns the result
+func (v *goValidator) runGoVet(ctx context.Context, dir string, fileName string) ValidationResult {
+	result := ValidationResult{
+		Check: "go vet - Static analysis to find suspicious constructs",
+	}
+
+	// Validate the specific file that was written/edited
+	cmd := exec.CommandContext(ctx, "go", "vet", fileName)
+	cmd.Dir = dir
+
+	output, err := cmd.CombinedOutput()
+	outputStr := strings.TrimSpace(string(output))
+
+	if err != nil {
+		if outputStr != "" {
+			result.Status = "fail"
+			result.Output = outputStr
+			lines := strings.Split(outputStr, "\n")
+			result.Summary = fmt.Sprintf("Found %d vet issues", len(lines))
+		} else {
+			result.Status = "error"
+			result.Output = err.Error()
+			result.Summary = fmt.Sprintf("Could not run go vet: %v", err)
+		}
+	} else {
+		result.Status = "pass"
+		result.Summary = "No vet issues found"
+	}
+
+	return result
+}
+
+// runGoBuild executes go build -n (dry run) and returns the result
+func (v *goValidator) runGoBuild(ctx context.Context, dir string, fileName string) ValidationResult {
+	result := ValidationResult{
+		Check: "go build -n - Check if code compiles without building",
+	}
+
+	// Validate the specific file that was written/edited
+	cmd := exec.CommandContext(ctx, "go", "build", "-n", fileName)
+	cmd.Dir = dir
+
+	output, err := cmd.CombinedOutput()
+	outputStr := strings.TrimSpace(string(output))
+
+	if err != nil {
+		result.Status = "fail"
+		result.Output = outputStr
+		result.Summary = "Build would fail - compilation errors found"
+	} else {
+		result.Status = "pass"
+		result.Summary = "Code compiles successfully"
+	}
+
+	return result
+}
+
+// pythonValidator syntax-checks Python files and lints them with ruff when installed
+type pythonValidator struct{}
+
+func (pythonValidator) Name() string                { return "python" }
+func (pythonValidator) Label() string               { return "Python" }
+func (pythonValidator) CanValidate(ext string) bool { return ext == ".py" }
+
+func (pythonValidator) Validate(ctx context.Context, dir, fileName string) []ValidationResult {
+	var results []ValidationResult
+	if python := firstExecutable("python3", "python"); python != "" {
+		// Same check as py_compile, without writing __pycache__ into the project
+		results = append(results, runValidationCommand(ctx, dir,
+			"python compile - Check syntax", "Syntax is valid", "Syntax errors found",
+			python, "-c", "import sys; compile(open(sys.argv[1], 'rb').read(), sys.argv[1], 'exec')", fileName))
+	}
+	if ruff := firstExecutable("ruff"); ruff != "" {
+		results = append(results, runValidationCommand(ctx, dir,
+			"ruff check - Lint for errors and suspicious code", "No lint issues found", "Lint issues found",
+			ruff, "check", "--quiet", "--output-format", "concise", fileName))
+	}
+	return results
+}
+
+// javaScriptValidator type-checks TypeScript with tsc, syntax-checks JavaScript
+// with node, and runs eslint when the project configures it
+type javaScriptValidator struct{}
+
+func (javaScriptValidator) Name() string  { return "javascript" }
+func (javaScriptValidator) Label() string { return "JavaScript/TypeScript" }
+func (javaScriptValidator) CanValidate(ext string) bool {
+	switch ext {
+	case ".js", ".mjs", ".cjs", ".jsx", ".ts", ".mts", ".cts", ".tsx":
+		return true
+	}
+	return false
+}
+
+func (javaScriptValidator) Validate(ctx context.Context, dir, fileName string) []ValidationResult {
+	var results []ValidationResult
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".ts", ".mts", ".cts", ".tsx":
+		if tsc := nodePackageExecutable(dir, "tsc"); tsc != "" {
+			// With a tsconfig.json the whole project is checked using its settings
+			args := []string{"--noEmit", fileName}
+			checkDir := dir
+			if root := findUp(dir, "tsconfig.json"); root != "" {
+				args = []string{"--noEmit", "-p", root}
+				checkDir = root
+			}
+			results = append(results, runValidationCommand(ctx, checkDir,
+				"tsc --noEmit - Type check", "No type errors found", "Type errors found", tsc, args...))
+		}
+	case ".js", ".mjs", ".cjs":
+		if node := firstExecutable("node"); node != "" {
+			results = append(results, runValidationCommand(ctx, dir,
+				"node --check - Check syntax", "Syntax is valid", "Syntax errors found", node, "--check", fileName))
+		}
+	}
+
+	eslintConfigs := []string{"eslint.config.js", "eslint.config.mjs", "eslint.config.cjs", "eslint.config.ts",
+		".eslintrc", ".eslintrc.js", ".eslintrc.cjs", ".eslintrc.json", ".eslintrc.yml", ".eslintrc.yaml"}
+	if findUp(dir, eslintConfigs...) != "" {
+		if eslint := nodePackageExecutable(dir, "eslint"); eslint != "" {
+			results = append(results, runValidationCommand(ctx, dir,
+				"eslint - Lint for errors and style issues", "No lint issues found", "Lint issues found",
+				eslint, "--format", "unix", fileName))
+		}
+	}
+	return results
+}
+
+// rustValidator runs cargo check in the crate containing the file
+type rustValidator struct{}
+
+func (rustValidator) Name() string                { return "rust" }
+func (rustValidator) Label() string               { return "Rust" }
+func (rustValidator) CanValidate(ext string) bool { return ext == ".rs" }
+
+func (rustValidator) Validate(ctx context.Context, dir, fileName string) []ValidationResult {
+	root := findUp(dir, "Cargo.toml")
+	cargo := firstExecutable("cargo")
+	if root == "" || cargo == "" {
+		return nil
+	}
+	return []ValidationResult{runValidationCommand(ctx, root,
+		"cargo check - Check if the crate compiles", "Code compiles successfully", "Build would fail - compilation errors found",
+		cargo, "check", "--quiet", "--message-format", "short")}
+}
+
+// dataValidator parse-checks configuration and data files in process. The file
+// is kept as written; a parse error is reported so the agent can fix it.
+type dataValidator struct {
+	fsRepo repository.FilesystemRepository
+	name   string
+	label  string
+	exts   []string
+	parse  func(data []byte) error    // returns an error locating the first problem
+	skip   func(fileName string) bool // files with the extension but another syntax
+}
+
+func (v *dataValidator) Name() string  { return v.name }
+func (v *dataValidator) Label() string { return v.label }
+func (v *dataValidator) CanValidate(ext string) bool {
+	return slices.Contains(v.exts, ext)
+}
+
+func (v *dataValidator) Validate(ctx context.Context, dir, fileName string) []ValidationResult {
+	if v.skip != nil && v.skip(fileName) {
+		return nil
+	}
+	data, err := v.fsRepo.ReadFile(ctx, filepath.Join(dir, fileName))
+	if err != nil {
+		return nil
+	}
+	result := ValidationResult{Check: v.name + " parse - Check syntax"}
+	if err := v.parse(data); err != nil {
+		result.Status = "fail"
+		result.Output = err.Error()
+		result.Summary = "Invalid " + v.label
+	} else {
+		result.Status = "pass"
+		result.Summary = "Syntax is valid"
+	}
+	return []ValidationResult{result}
+}
+
+// checkJSON parses a JSON document, locating syntax errors by line and column
+func checkJSON(data []byte) error {
+	var v any
+	err := json.Unmarshal(data, &v)
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		// Offset is just past the offending byte
+		line, col := lineAndColumn(data, max(int(syntaxErr.Offset)-1, 0))
+		return fmt.Errorf("line %d, column %d: %s", line, col, syntaxErr.Error())
+	}
+	return err
+}
+
+// isJSONCFile reports whether a .json file is conventionally JSON with
+// comments and trailing commas, like tsconfig.json, which checkJSON would
+// reject
+func isJSONCFile(fileName string) bool {
+	name := strings.ToLower(fileName)
+	return strings.HasPrefix(name, "tsconfig") || strings.HasPrefix(name, "jsconfig")
+}
+
+// lineAndColumn converts a byte offset to a 1-based line and column
+func lineAndColumn(data []byte, offset int) (int, int) {
+	offset = min(offset, len(data))
+	before := data[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	col := offset - bytes.LastIndexByte(before, '\n')
+	return line, col
+}
+
+// checkYAML parses every document in a YAML stream; errors include the line
+func checkYAML(data []byte) error {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var node yaml.Node
+		if err := dec.Decode(&node); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+}
+
+// checkTOML decodes a TOML document, locating syntax errors by line and column
+func checkTOML(data []byte) error {
+	var v map[string]any
+	_, err := toml.Decode(string(data), &v)
+	var parseErr toml.ParseError
+	if errors.As(err, &parseErr) {
+		return fmt.Errorf("line %d, column %d: %s", parseErr.Position.Line, parseErr.Position.Col, parseErr.Message)
+	}
+	return err
+}
+
+// runValidationCommand runs a check and maps a non-zero exit with output to "fail"
+// and any other failure to "error"
+func runValidationCommand(ctx context.Context, dir, check, passSummary, failSummary, name string, args ...string) ValidationResult {
+	result := ValidationResult{Check: check}
+
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	outputStr := strings.TrimSpace(string(output))
+
+	switch {
+	case err == nil:
+		result.Status = "pass"
+		result.Summary = passSummary
+	case outputStr != "":
+		result.Status = "fail"
+		result.Output = outputStr
+		result.Summary = failSummary
+	default:
+		result.Status = "error"
+		result.Output = err.Error()
+		result.Summary = fmt.Sprintf("Could not run %s: %v", filepath.Base(name), err)
+	}
+	return result
+}
+
+// firstExecutable returns the path of the first named tool found on PATH,
+// or "" if none is installed
+func firstExecutable(names ...string) string {
+	for _, name := range names {
+		if path, err := exec.LookPath(name); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// nodePackageExecutable returns the path of a JavaScript tool installed in a
+// node_modules/.bin above dir, falling back to PATH. Only npm-installed tools
+// are looked up this way, so a project can't shadow python or cargo.
+func nodePackageExecutable(dir, name string) string {
+	if binDir := findUp(dir, filepath.Join("node_modules", ".bin", name)); binDir != "" {
+		return filepath.Join(binDir, "node_modules", ".bin", name)
+	}
+	return firstExecutable(name)
+}
+
+// findUp returns the nearest directory at or above dir that contains one of
+// the named entries, or "" if there is none
+func findUp(dir string, names ...string) string {
+	for {
+		for _, name := range names {
+			if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+				return dir
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// formatValidationResults formats validation results into a readable string
+func formatValidationResults(label string, results []ValidationResult) string {
+	if len(results) == 0 {
+		return ""
+	}
+
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("\n\n%s Validation Results:\n", label))
+
+	passed := 0
+	failed := 0
+
+	for _, result := range results {
+		switch result.Status {
+		case "pass":
+			output.WriteString(fmt.Sprintf("PASS: %s: %s\n", result.Check, result.Summary))
+			passed++
+		case "fail":
+			output.WriteString(fmt.Sprintf("FAIL: %s: %s\n", result.Check, result.Summary))
+			if result.Output != "" {
+				// Limit output to prevent overwhelming response
+				lines := strings.Split(result.Output, "\n")
+				if len(lines) > 5 {
+					output.WriteString(fmt.Sprintf("```\n%s\n... (%d more lines)\n```\n",
+						strings.Join(lines[:5], "\n"), len(lines)-5))
+				} else {
+					output.WriteString(fmt.Sprintf("```\n%s\n```\n", result.Output))
+				}
+			}
+			failed++
+		case "error":
+			output.WriteString(fmt.Sprintf("ERROR: %s: %s\n", result.Check, result.Summary))
+		}
+	}
+
+	if failed == 0 {
+		output.WriteString(fmt.Sprintf("\nAll %d validation checks passed.\n", passed))
+	} else {
+		output.WriteString(fmt.Sprintf("\nValidation Summary: %d passed, %d failed.\n", passed, failed))
+	}
+
+	return output.String()
+}
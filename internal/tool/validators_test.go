@@ -0,0 +1,164 @@
+package tool
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fpt/go-gennai-cli/internal/infra"
+)
+
+func TestNewValidators_Disabled(t *testing.T) {
+	validators := newValidators(infra.NewOSFilesystemRepository(), []string{"Python", " rust ", "unknown"})
+	var names []string
+	for _, v := range validators {
+		names = append(names, v.Name())
+	}
+	if got := strings.Join(names, ","); got != "go,javascript,json,yaml,toml" {
+		t.Errorf("enabled validators = %s, want go,javascript,json,yaml,toml", got)
+	}
+}
+
+func TestAutoValidateFile_Python(t *testing.T) {
+	if _, err := exec.LookPath("python3"); err != nil {
+		t.Skip("python3 not available")
+	}
+	dir := t.TempDir()
+	path := filepath.Join(dir, "broken.py")
+	if err := os.WriteFile(path, []byte("def f(:\n    pass\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	config := infra.DefaultFileSystemConfig(dir)
+	manager := NewFileSystemToolManager(infra.NewOSFilesystemRepository(), config, dir)
+
+	out := manager.autoValidateFile(context.Background(), path)
+	if !strings.Contains(out, "Python Validation Results") || !strings.Contains(out, "FAIL: python compile") {
+		t.Errorf("expected a failing Python syntax check, got:\n%s", out)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "__pycache__")); !os.IsNotExist(err) {
+		t.Errorf("validation should not write __pycache__")
+	}
+
+	config.DisabledValidators = []string{"python"}
+	manager = NewFileSystemToolManager(infra.NewOSFilesystemRepository(), config, dir)
+	if out := manager.autoValidateFile(context.Background(), path); out != "" {
+		t.Errorf("expected no output with the python validator disabled, got:\n%s", out)
+	}
+}
+
+func TestAutoValidateFile_UnknownExtension(t *testing.T) {
+	dir := t.TempDir()
+	manager := NewFileSystemToolManager(infra.NewOSFilesystemRepository(), infra.DefaultFileSystemConfig(dir), dir)
+	if out := manager.autoValidateFile(context.Background(), filepath.Join(dir, "notes.txt")); out != "" {
+		t.Errorf("expected no validation for .txt, got:\n%s", out)
+	}
+}
+
+func TestAutoValidateFile_DataFormats(t *testing.T) {
+	dir := t.TempDir()
+	manager := NewFileSystemToolManager(infra.NewOSFilesystemRepository(), infra.DefaultFileSystemConfig(dir), dir)
+
+	tests := []struct {
+		file    string
+		content string
+		want    string
+	}{
+		{"ok.json", `{"a": [1, 2]}`, "PASS: json parse"},
+		{"bad.json", "{\n  \"a\": 1,\n}\n", "line 3, column 1"},
+		{"ok.yaml", "a: 1\n---\nb: [1, 2]\n", "PASS: yaml parse"},
+		{"bad.yml", "a: 1\nb: [1, 2\n", "yaml: line 1: did not find expected ',' or ']'"},
+		{"ok.toml", "[a]\nb = 1\n", "PASS: toml parse"},
+		{"bad.toml", "[a]\nb = nope\n", "line 2, column 5"},
+		{"dup.toml", "a = 1\na = 2\n", "line 2"},
+		{"tsconfig.json", "{\n  // comments are allowed here\n  \"strict\": true,\n}\n", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			path := filepath.Join(dir, tt.file)
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			out := manager.autoValidateFile(context.Background(), path)
+			if tt.want == "" && out != "" {
+				t.Errorf("expected %s to be skipped, got:\n%s", tt.file, out)
+			}
+			if !strings.Contains(out, tt.want) {
+				t.Errorf("expected %q in validation output, got:\n%s", tt.want, out)
+			}
+		})
+	}
+
+	config := infra.DefaultFileSystemConfig(dir)
+	config.DisabledValidators = []string{"yaml"}
+	manager = NewFileSystemToolManager(infra.NewOSFilesystemRepository(), config, dir)
+	if out := manager.autoValidateFile(context.Background(), filepath.Join(dir, "bad.yml")); out != "" {
+		t.Errorf("expected no output with the yaml validator disabled, got:\n%s", out)
+	}
+}
+
+func TestRecheckValidationFailures(t *testing.T) {
+	dir := t.TempDir()
+	manager := NewFileSystemToolManager(infra.NewOSFilesystemRepository(), infra.DefaultFileSystemConfig(dir), dir)
+	ctx := context.Background()
+
+	bad := filepath.Join(dir, "bad.json")
+	gone := filepath.Join(dir, "gone.json")
+	for _, path := range []string{bad, gone} {
+		if err := os.WriteFile(path, []byte("{,}"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		manager.autoValidateFile(ctx, path)
+	}
+
+	report, files := manager.RecheckValidationFailures(ctx)
+	if files != 2 || !strings.Contains(report, "### "+bad) || !strings.Contains(report, "FAIL: json parse") {
+		t.Fatalf("expected both files reported, got %d:\n%s", files, report)
+	}
+
+	// Fixed with another tool, or deleted: neither is reported again
+	if err := os.WriteFile(bad, []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(gone); err != nil {
+		t.Fatal(err)
+	}
+	if report, files = manager.RecheckValidationFailures(ctx); files != 0 {
+		t.Errorf("expected no failures after the fix, got %d:\n%s", files, report)
+	}
+
+	if err := os.WriteFile(bad, []byte("{,}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	manager.autoValidateFile(ctx, bad)
+	manager.ClearValidationFailures()
+	if _, files = manager.RecheckValidationFailures(ctx); files != 0 {
+		t.Errorf("expected cleared failures to be forgotten, got %d", files)
+	}
+}
+
+func TestExecutableLookup_NodeModulesOnlyForJavaScriptTools(t *testing.T) {
+	dir := t.TempDir()
+	bin := filepath.Join(dir, "node_modules", ".bin")
+	if err := os.MkdirAll(bin, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"eslint", "python3"} {
+		if err := os.WriteFile(filepath.Join(bin, name), []byte("#!/bin/sh\n"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sub := filepath.Join(dir, "src")
+	if err := os.Mkdir(sub, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := nodePackageExecutable(sub, "eslint"); got != filepath.Join(bin, "eslint") {
+		t.Errorf("eslint = %q, want the project's copy", got)
+	}
+	if got := firstExecutable("python3"); strings.HasPrefix(got, dir) {
+		t.Errorf("python3 = %q, a project's node_modules must not shadow it", got)
+	}
+}
@@ -0,0 +1,117 @@
+package tool
+
+import (
+	"container/list"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Default WebFetch cache limits
+const (
+	DefaultFetchCacheTTL        = 15 * time.Minute
+	DefaultFetchCacheMaxEntries = 64
+)
+
+// FetchCacheConfig controls the in-memory WebFetch cache.
+// Zero TTL and MaxEntries fall back to the defaults above.
+type FetchCacheConfig struct {
+	Disabled   bool
+	TTL        time.Duration
+	MaxEntries int
+}
+
+// fetchCacheEntry is a cached WebFetch result
+type fetchCacheEntry struct {
+	url       string
+	markdown  string
+	fetchedAt time.Time
+}
+
+// fetchCache is a URL-keyed LRU cache of converted pages with a TTL
+type fetchCache struct {
+	mu         sync.Mutex
+	ttl        time.Duration
+	maxEntries int
+	order      *list.List // front = most recently used
+	entries    map[string]*list.Element
+	now        func() time.Time
+}
+
+// newFetchCache returns a cache for cfg, or nil when caching is disabled
+func newFetchCache(cfg FetchCacheConfig) *fetchCache {
+	if cfg.Disabled {
+		return nil
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = DefaultFetchCacheTTL
+	}
+	if cfg.MaxEntries <= 0 {
+		cfg.MaxEntries = DefaultFetchCacheMaxEntries
+	}
+	return &fetchCache{
+		ttl:        cfg.TTL,
+		maxEntries: cfg.MaxEntries,
+		order:      list.New(),
+		entries:    make(map[string]*list.Element),
+		now:        time.Now,
+	}
+}
+
+// get returns the cached markdown for url if present and not expired
+func (c *fetchCache) get(url string) (string, bool) {
+	if c == nil {
+		return "", false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[url]
+	if !ok {
+		return "", false
+	}
+	entry := elem.Value.(*fetchCacheEntry)
+	if c.now().Sub(entry.fetchedAt) > c.ttl {
+		c.order.Remove(elem)
+		delete(c.entries, url)
+		return "", false
+	}
+	c.order.MoveToFront(elem)
+	return entry.markdown, true
+}
+
+// put stores markdown for url, evicting the least recently used entry when full
+func (c *fetchCache) put(url, markdown string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[url]; ok {
+		entry := elem.Value.(*fetchCacheEntry)
+		entry.markdown = markdown
+		entry.fetchedAt = c.now()
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.entries[url] = c.order.PushFront(&fetchCacheEntry{url: url, markdown: markdown, fetchedAt: c.now()})
+	for c.order.Len() > c.maxEntries {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*fetchCacheEntry).url)
+	}
+}
+
+// isNoStore reports whether the response forbids caching via Cache-Control: no-store
+func isNoStore(header http.Header) bool {
+	for _, value := range header.Values("Cache-Control") {
+		for _, directive := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(directive), "no-store") {
+				return true
+			}
+		}
+	}
+	return false
+}
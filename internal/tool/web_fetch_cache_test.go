@@ -0,0 +1,82 @@
+package tool
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fpt/go-gennai-cli/pkg/message"
+)
+
+func TestFetchCache_LRUAndTTL(t *testing.T) {
+	now := time.Now()
+	c := newFetchCache(FetchCacheConfig{TTL: time.Minute, MaxEntries: 2})
+	c.now = func() time.Time { return now }
+
+	c.put("a", "A")
+	c.put("b", "B")
+	if _, ok := c.get("a"); !ok { // a becomes most recently used
+		t.Fatal("expected a to be cached")
+	}
+	c.put("c", "C") // evicts b
+	if _, ok := c.get("b"); ok {
+		t.Error("expected b to be evicted")
+	}
+	if got, ok := c.get("c"); !ok || got != "C" {
+		t.Errorf("expected c cached, got %q %v", got, ok)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, ok := c.get("a"); ok {
+		t.Error("expected a to expire after TTL")
+	}
+
+	if newFetchCache(FetchCacheConfig{Disabled: true}) != nil {
+		t.Error("expected nil cache when disabled")
+	}
+}
+
+func TestWebFetch_Cache(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		if r.URL.Path == "/nostore" {
+			w.Header().Set("Cache-Control", "private, no-store")
+		}
+		fmt.Fprintf(w, "<html><head><title>Page</title></head><body><main><p>hello %s</p></main></body></html>", r.URL.Path)
+	}))
+	defer srv.Close()
+
+	m := NewWebToolManagerWithConfig(WebConfig{})
+	fetch := func(path string) string {
+		t.Helper()
+		res, err := m.CallTool(context.Background(), "WebFetch", message.ToolArgumentValues{"url": srv.URL + path})
+		if err != nil || res.Error != "" {
+			t.Fatalf("fetch failed: %v %s", err, res.Error)
+		}
+		return res.Text
+	}
+
+	first := fetch("/doc")
+	second := fetch("/doc")
+	if hits.Load() != 1 {
+		t.Errorf("expected one request for repeated fetch, got %d", hits.Load())
+	}
+	if strings.HasPrefix(first, "(cached)") || !strings.HasPrefix(second, "(cached)") {
+		t.Errorf("expected cache indicator only on second fetch:\n%s\n---\n%s", first, second)
+	}
+	if !strings.Contains(second, "hello /doc") {
+		t.Errorf("expected cached content, got %q", second)
+	}
+
+	fetch("/nostore")
+	fetch("/nostore")
+	if hits.Load() != 3 {
+		t.Errorf("expected no-store responses to bypass the cache, got %d requests", hits.Load())
+	}
+}
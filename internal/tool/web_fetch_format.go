@@ -0,0 +1,138 @@
+package tool
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// WebFetch output formats
+const (
+	fetchFormatMarkdown = "markdown"
+	fetchFormatText     = "text"
+	fetchFormatHTML     = "html"
+)
+
+var blankLineRuns = regexp.MustCompile(`\n{3,}`)
+
+// fetchCacheKey keys cached pages by URL plus any non-default rendering options
+func fetchCacheKey(urlStr, format, selector string) string {
+	if format == fetchFormatMarkdown && selector == "" {
+		return urlStr
+	}
+	return urlStr + "\x00" + format + "\x00" + selector
+}
+
+// renderFetchedPage converts a fetched document to the requested format,
+// limited to the elements matching selector when one is given
+func (m *WebToolManager) renderFetchedPage(doc *goquery.Document, baseURL *url.URL, format, selector string) (string, error) {
+	if format != fetchFormatMarkdown {
+		doc.Find("script, style, noscript, template, iframe, svg").Remove()
+	}
+
+	if selector == "" {
+		switch format {
+		case fetchFormatText:
+			return m.capFetchOutput(visibleText(doc.Find("body"))), nil
+		case fetchFormatHTML:
+			html, err := doc.Html()
+			if err != nil {
+				return "", fmt.Errorf("failed to render HTML: %v", err)
+			}
+			return m.capFetchOutput(html), nil
+		default:
+			return m.capFetchOutput(m.convertToMarkdown(doc, baseURL)), nil
+		}
+	}
+
+	selection := doc.Find(selector)
+	if selection.Length() == 0 {
+		return "", fmt.Errorf("selector %q matched no elements", selector)
+	}
+	var b strings.Builder
+	switch format {
+	case fetchFormatText:
+		selection.Each(func(i int, s *goquery.Selection) {
+			b.WriteString(visibleText(s) + "\n\n")
+		})
+	case fetchFormatHTML:
+		selection.Each(func(i int, s *goquery.Selection) {
+			if html, err := goquery.OuterHtml(s); err == nil {
+				b.WriteString(html + "\n")
+			}
+		})
+	default:
+		if title := strings.TrimSpace(doc.Find("title").First().Text()); title != "" {
+			b.WriteString(fmt.Sprintf("# %s\n\n", title))
+		}
+		// processElement renders an element's children, so render each match
+		// from a fragment where it is the only child
+		selection.Each(func(i int, s *goquery.Selection) {
+			html, err := goquery.OuterHtml(s)
+			if err != nil {
+				return
+			}
+			fragment, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+			if err != nil {
+				return
+			}
+			m.processElement(fragment.Find("body"), &b, baseURL, 0)
+			b.WriteString("\n\n")
+		})
+	}
+	return m.capFetchOutput(strings.TrimSpace(b.String())), nil
+}
+
+// visibleText returns the text of a selection with whitespace collapsed, a line
+// per block element and tab-separated table cells so tables keep their shape
+func visibleText(selection *goquery.Selection) string {
+	var b strings.Builder
+	var walk func(s *goquery.Selection)
+	walk = func(s *goquery.Selection) {
+		s.Contents().Each(func(i int, node *goquery.Selection) {
+			name := goquery.NodeName(node)
+			switch name {
+			case "#text":
+				if text := strings.Join(strings.Fields(node.Text()), " "); text != "" {
+					if b.Len() > 0 && !strings.HasSuffix(b.String(), "\n") && !strings.HasSuffix(b.String(), "\t") {
+						b.WriteString(" ")
+					}
+					b.WriteString(text)
+				}
+				return
+			case "#comment":
+				return
+			case "br":
+				b.WriteString("\n")
+				return
+			}
+			walk(node)
+			switch name {
+			case "td", "th":
+				b.WriteString("\t")
+			case "p", "div", "li", "tr", "h1", "h2", "h3", "h4", "h5", "h6", "pre", "blockquote",
+				"section", "article", "header", "footer", "table", "ul", "ol", "dl", "dt", "dd", "form":
+				b.WriteString("\n")
+			}
+		})
+	}
+	walk(selection)
+
+	lines := strings.Split(b.String(), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	return strings.TrimSpace(blankLineRuns.ReplaceAllString(strings.Join(lines, "\n"), "\n\n"))
+}
+
+// capFetchOutput truncates output to maxOutputChars
+func (m *WebToolManager) capFetchOutput(s string) string {
+	if len(s) <= m.maxOutputChars {
+		return s
+	}
+	shown := truncateLine(s, m.maxOutputChars)
+	return fmt.Sprintf("%s\n\n... (truncated: %d of %d characters shown; use selector to narrow the page)", shown, len(shown), len(s))
+}
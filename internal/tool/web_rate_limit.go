@@ -0,0 +1,57 @@
+package tool
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// hostRateLimiter spaces requests to the same host at least interval apart
+type hostRateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     map[string]time.Time // earliest start of the next request per host
+	now      func() time.Time
+}
+
+// newHostRateLimiter returns a limiter allowing rps requests per second per
+// host, or nil (no limit) when rps is not positive
+func newHostRateLimiter(rps float64) *hostRateLimiter {
+	if rps <= 0 {
+		return nil
+	}
+	return &hostRateLimiter{
+		interval: time.Duration(float64(time.Second) / rps),
+		next:     make(map[string]time.Time),
+		now:      time.Now,
+	}
+}
+
+// wait blocks until a request to host may start, reserving the slot. It
+// returns how long it waited, or ctx.Err() if ctx ends first.
+func (l *hostRateLimiter) wait(ctx context.Context, host string) (time.Duration, error) {
+	if l == nil {
+		return 0, nil
+	}
+	l.mu.Lock()
+	now := l.now()
+	start := l.next[host]
+	if start.Before(now) {
+		start = now
+	}
+	l.next[host] = start.Add(l.interval)
+	l.mu.Unlock()
+
+	delay := start.Sub(now)
+	if delay <= 0 {
+		return 0, nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	case <-timer.C:
+		return delay, nil
+	}
+}
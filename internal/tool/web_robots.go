@@ -0,0 +1,199 @@
+package tool
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fpt/go-gennai-cli/pkg/httpclient"
+	pkgLogger "github.com/fpt/go-gennai-cli/pkg/logger"
+)
+
+// robotsUserAgent is the product token matched against robots.txt User-agent
+// lines; groups for it take precedence over "*"
+const robotsUserAgent = "gennai"
+
+// robotsFetchTimeout bounds the robots.txt request made before a host's first fetch
+const robotsFetchTimeout = 10 * time.Second
+
+// maxRobotsBytes caps how much of a robots.txt file is read
+const maxRobotsBytes = 512 * 1024
+
+// robotsRule is one Allow or Disallow line
+type robotsRule struct {
+	allow   bool
+	pattern string
+}
+
+// robotsRules are the rules that apply to gennai on one host. A nil value
+// allows everything.
+type robotsRules struct {
+	rules []robotsRule
+}
+
+// allowed reports whether path (including any query) may be fetched. The
+// longest matching pattern wins and Allow wins a tie, as in RFC 9309.
+func (r *robotsRules) allowed(path string) bool {
+	if r == nil {
+		return true
+	}
+	if path == "" {
+		path = "/"
+	}
+	best, allow := -1, true
+	for _, rule := range r.rules {
+		if !robotsPatternMatches(rule.pattern, path) {
+			continue
+		}
+		if n := len(rule.pattern); n > best || (n == best && rule.allow) {
+			best, allow = n, rule.allow
+		}
+	}
+	return allow
+}
+
+// robotsPatternMatches matches a robots.txt path pattern, where "*" matches
+// any sequence and a trailing "$" anchors the end
+func robotsPatternMatches(pattern, path string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	pattern = strings.TrimSuffix(pattern, "$")
+	parts := strings.Split(pattern, "*")
+
+	if !strings.HasPrefix(path, parts[0]) {
+		return false
+	}
+	rest := path[len(parts[0]):]
+	for i, part := range parts[1:] {
+		last := i == len(parts)-2
+		if last && anchored {
+			return strings.HasSuffix(rest, part)
+		}
+		idx := strings.Index(rest, part)
+		if idx < 0 {
+			return false
+		}
+		rest = rest[idx+len(part):]
+	}
+	return !anchored || rest == ""
+}
+
+// parseRobots extracts the rules for gennai from a robots.txt body: the
+// groups naming gennai if any, otherwise the "*" groups
+func parseRobots(body io.Reader) *robotsRules {
+	var own, wildcard []robotsRule
+	var agents []string
+	inRules := false // a rule line ends the User-agent list of a group
+
+	scanner := bufio.NewScanner(body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			if inRules {
+				agents, inRules = nil, false
+			}
+			agents = append(agents, strings.ToLower(value))
+		case "allow", "disallow":
+			inRules = true
+			if value == "" {
+				// "Disallow:" with no path allows everything
+				continue
+			}
+			rule := robotsRule{allow: key == "allow", pattern: value}
+			for _, agent := range agents {
+				switch {
+				case agent == "*":
+					wildcard = append(wildcard, rule)
+				case agent == robotsUserAgent:
+					own = append(own, rule)
+				}
+			}
+		}
+	}
+
+	if own != nil {
+		return &robotsRules{rules: own}
+	}
+	return &robotsRules{rules: wildcard}
+}
+
+// robotsCache fetches robots.txt once per scheme and host for the session
+type robotsCache struct {
+	mu    sync.Mutex
+	hosts map[string]*robotsRules
+}
+
+func newRobotsCache() *robotsCache {
+	return &robotsCache{hosts: make(map[string]*robotsRules)}
+}
+
+// allowed reports whether target may be fetched according to its host's robots.txt
+func (c *robotsCache) allowed(ctx context.Context, target *url.URL) bool {
+	origin := target.Scheme + "://" + target.Host
+
+	c.mu.Lock()
+	rules, ok := c.hosts[origin]
+	c.mu.Unlock()
+	if !ok {
+		var definitive bool
+		rules, definitive = fetchRobots(ctx, origin)
+		if definitive {
+			c.mu.Lock()
+			c.hosts[origin] = rules
+			c.mu.Unlock()
+		}
+	}
+	return rules.allowed(target.RequestURI())
+}
+
+// fetchRobots downloads and parses origin's robots.txt. A missing file or a
+// failed request allows everything. definitive reports whether the answer
+// holds for the session: a parsed file or a 4xx does, while network errors,
+// cancellations and server errors are retried on the next fetch.
+func fetchRobots(ctx context.Context, origin string) (rules *robotsRules, definitive bool) {
+	robotsURL := origin + "/robots.txt"
+	req, err := http.NewRequestWithContext(ctx, "GET", robotsURL, nil)
+	if err != nil {
+		return nil, false
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Compatible Web Fetcher Bot; "+robotsUserAgent+")")
+
+	resp, err := httpclient.New(robotsFetchTimeout).Do(req)
+	if err != nil {
+		logger.DebugWithIntention(pkgLogger.IntentionTool, "robots.txt unavailable, allowing fetch", "url", robotsURL, "error", err)
+		return nil, false
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		return parseRobots(io.LimitReader(resp.Body, maxRobotsBytes)), true
+	case resp.StatusCode >= 400 && resp.StatusCode < 500:
+		logger.DebugWithIntention(pkgLogger.IntentionTool, "No robots.txt rules", "url", robotsURL, "status", resp.StatusCode)
+		return nil, true
+	default:
+		logger.DebugWithIntention(pkgLogger.IntentionTool, "robots.txt unavailable, allowing fetch", "url", robotsURL, "status", resp.StatusCode)
+		return nil, false
+	}
+}
+
+// robotsDisallowedMessage explains a fetch refused by robots.txt
+func robotsDisallowedMessage(target *url.URL) string {
+	return fmt.Sprintf("fetch of %s is disallowed by %s://%s/robots.txt; find another source or ask the user for the content", target.String(), target.Scheme, target.Host)
+}
@@ -0,0 +1,185 @@
+package tool
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fpt/go-gennai-cli/pkg/message"
+)
+
+func TestParseRobots(t *testing.T) {
+	body := `# example
+User-agent: *
+Disallow: /private/
+Allow: /private/public-*.html$
+Disallow: /*.pdf$
+
+User-agent: otherbot
+Disallow: /
+`
+	rules := parseRobots(strings.NewReader(body))
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"/", true},
+		{"/docs/index.html", true},
+		{"/private/", false},
+		{"/private/secret.html", false},
+		{"/private/public-faq.html", true},
+		{"/private/public-faq.html?x=1", false},
+		{"/files/report.pdf", false},
+		{"/files/report.pdf?download=1", true},
+	}
+	for _, tt := range tests {
+		if got := rules.allowed(tt.path); got != tt.want {
+			t.Errorf("allowed(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+
+	// A group naming gennai replaces the "*" rules
+	own := parseRobots(strings.NewReader("User-agent: *\nDisallow: /\n\nUser-agent: Gennai\nDisallow: /admin\n"))
+	if !own.allowed("/docs") || own.allowed("/admin/users") {
+		t.Error("expected the gennai group to take precedence over *")
+	}
+
+	// An empty Disallow allows everything
+	if !parseRobots(strings.NewReader("User-agent: *\nDisallow:\n")).allowed("/anything") {
+		t.Error("empty Disallow should allow everything")
+	}
+	var none *robotsRules
+	if !none.allowed("/x") {
+		t.Error("nil rules should allow everything")
+	}
+}
+
+func TestWebFetch_RespectsRobots(t *testing.T) {
+	var robotsHits, pageHits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			robotsHits.Add(1)
+			fmt.Fprint(w, "User-agent: *\nDisallow: /private\n")
+			return
+		}
+		pageHits.Add(1)
+		fmt.Fprintf(w, "<html><body><main><p>page %s</p></main></body></html>", r.URL.Path)
+	}))
+	defer srv.Close()
+
+	m := NewWebToolManagerWithConfig(WebConfig{RespectRobots: true, FetchCache: FetchCacheConfig{Disabled: true}})
+	fetch := func(path string) message.ToolResult {
+		t.Helper()
+		res, err := m.CallTool(context.Background(), "WebFetch", message.ToolArgumentValues{"url": srv.URL + path})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res
+	}
+
+	if res := fetch("/private/data"); !strings.Contains(res.Error, "disallowed by") {
+		t.Errorf("expected robots refusal, got %+v", res)
+	}
+	if res := fetch("/public"); res.Error != "" || !strings.Contains(res.Text, "page /public") {
+		t.Errorf("expected allowed fetch, got %+v", res)
+	}
+	fetch("/public")
+	if robotsHits.Load() != 1 {
+		t.Errorf("robots.txt fetched %d times, want once per host", robotsHits.Load())
+	}
+	if pageHits.Load() != 2 {
+		t.Errorf("page requests = %d, want 2 (disallowed URL not fetched)", pageHits.Load())
+	}
+
+	// Ignoring robots fetches the page
+	m = NewWebToolManagerWithConfig(WebConfig{})
+	if res := fetch("/private/data"); res.Error != "" {
+		t.Errorf("expected fetch without robots check, got %+v", res)
+	}
+}
+
+func TestRobotsCache_CachesOnlyDefinitiveAnswers(t *testing.T) {
+	var robotsHits atomic.Int32
+	var status atomic.Int32
+	status.Store(http.StatusServiceUnavailable)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		robotsHits.Add(1)
+		if code := int(status.Load()); code != http.StatusOK {
+			w.WriteHeader(code)
+			return
+		}
+		fmt.Fprint(w, "User-agent: *\nDisallow: /private\n")
+	}))
+	defer srv.Close()
+
+	c := newRobotsCache()
+	target, _ := url.Parse(srv.URL + "/private/data")
+
+	// A server error allows the fetch but is asked again next time
+	if !c.allowed(context.Background(), target) {
+		t.Error("expected a 503 to allow the fetch")
+	}
+	status.Store(http.StatusOK)
+	if c.allowed(context.Background(), target) {
+		t.Error("expected the rules fetched after the 503 to apply")
+	}
+
+	// A cancelled request isn't cached either
+	other, _ := url.Parse(strings.Replace(srv.URL, "127.0.0.1", "localhost", 1) + "/private/data")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if !c.allowed(ctx, other) {
+		t.Error("expected a cancelled robots request to allow the fetch")
+	}
+	if c.allowed(context.Background(), other) {
+		t.Error("expected the cancelled request not to be cached as allow-all")
+	}
+
+	// A 404 is cached as allow-all
+	status.Store(http.StatusNotFound)
+	c = newRobotsCache()
+	before := robotsHits.Load()
+	c.allowed(context.Background(), target)
+	c.allowed(context.Background(), target)
+	if got := robotsHits.Load() - before; got != 1 {
+		t.Errorf("robots.txt fetched %d times after a 404, want once", got)
+	}
+}
+
+func TestHostRateLimiter(t *testing.T) {
+	if newHostRateLimiter(0) != nil {
+		t.Error("expected no limiter for rps 0")
+	}
+	var none *hostRateLimiter
+	if waited, err := none.wait(context.Background(), "example.com"); waited != 0 || err != nil {
+		t.Errorf("nil limiter waited %v, %v", waited, err)
+	}
+
+	l := newHostRateLimiter(20) // 50ms apart
+	ctx := context.Background()
+	if waited, _ := l.wait(ctx, "a.example"); waited != 0 {
+		t.Errorf("first request waited %v", waited)
+	}
+	if waited, _ := l.wait(ctx, "b.example"); waited != 0 {
+		t.Errorf("other host waited %v", waited)
+	}
+	start := time.Now()
+	if waited, _ := l.wait(ctx, "a.example"); waited <= 0 {
+		t.Error("second request to the same host should wait")
+	}
+	if elapsed := time.Since(start); elapsed < 30*time.Millisecond {
+		t.Errorf("second request waited only %v", elapsed)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := l.wait(cancelled, "a.example"); err != context.Canceled {
+		t.Errorf("wait with cancelled context = %v, want context.Canceled", err)
+	}
+}
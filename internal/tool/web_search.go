@@ -0,0 +1,212 @@
+package tool
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+
+	"github.com/fpt/go-gennai-cli/pkg/httpclient"
+)
+
+// searchTimeout bounds a single search provider request
+const searchTimeout = 15 * time.Second
+
+// SearchResult is a single ranked web search hit
+type SearchResult struct {
+	Title   string
+	URL     string
+	Snippet string
+}
+
+// SearchProvider is a pluggable web search backend used by the WebSearch tool.
+// Implementations return results ranked best-first.
+type SearchProvider interface {
+	Name() string
+	Search(ctx context.Context, query string) ([]SearchResult, error)
+}
+
+// NewSearchProvider creates a search provider by name. An empty name returns nil
+// (WebSearch then falls back to the stub message).
+func NewSearchProvider(name, searxngURL string) (SearchProvider, error) {
+	switch name {
+	case "":
+		return nil, nil
+	case "duckduckgo":
+		return NewDuckDuckGoProvider(), nil
+	case "searxng":
+		if searxngURL == "" {
+			return nil, fmt.Errorf("searxng provider requires a base URL")
+		}
+		return NewSearXNGProvider(searxngURL), nil
+	default:
+		return nil, fmt.Errorf("unsupported search provider: %s", name)
+	}
+}
+
+// DuckDuckGoProvider searches via the DuckDuckGo HTML endpoint (no API key required)
+type DuckDuckGoProvider struct {
+	endpoint string
+	client   *http.Client
+}
+
+// NewDuckDuckGoProvider creates a DuckDuckGo HTML search provider
+func NewDuckDuckGoProvider() *DuckDuckGoProvider {
+	return &DuckDuckGoProvider{
+		endpoint: "https://html.duckduckgo.com/html/",
+		client:   httpclient.New(searchTimeout),
+	}
+}
+
+func (p *DuckDuckGoProvider) Name() string { return "duckduckgo" }
+
+func (p *DuckDuckGoProvider) Search(ctx context.Context, query string) ([]SearchResult, error) {
+	reqURL := p.endpoint + "?q=" + url.QueryEscape(query)
+	req, err := http.NewRequestWithContext(ctx, "GET", reqURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Compatible Web Fetcher Bot)")
+	req.Header.Set("Accept", "text/html")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("search request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP error %d: %s", resp.StatusCode, resp.Status)
+	}
+
+	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse search results: %w", err)
+	}
+
+	var results []SearchResult
+	doc.Find(".result").Each(func(i int, s *goquery.Selection) {
+		link := s.Find("a.result__a").First()
+		href, ok := link.Attr("href")
+		if !ok {
+			return
+		}
+		results = append(results, SearchResult{
+			Title:   strings.TrimSpace(link.Text()),
+			URL:     unwrapDuckDuckGoURL(href),
+			Snippet: strings.TrimSpace(s.Find(".result__snippet").First().Text()),
+		})
+	})
+
+	return results, nil
+}
+
+// unwrapDuckDuckGoURL extracts the target URL from DuckDuckGo's redirect links
+func unwrapDuckDuckGoURL(href string) string {
+	if strings.HasPrefix(href, "//") {
+		href = "https:" + href
+	}
+	parsed, err := url.Parse(href)
+	if err != nil {
+		return href
+	}
+	if target := parsed.Query().Get("uddg"); target != "" {
+		return target
+	}
+	return href
+}
+
+// SearXNGProvider searches via a SearXNG instance's JSON API
+type SearXNGProvider struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewSearXNGProvider creates a SearXNG search provider for the given instance URL
+func NewSearXNGProvider(baseURL string) *SearXNGProvider {
+	return &SearXNGProvider{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client:  httpclient.New(searchTimeout),
+	}
+}
+
+func (p *SearXNGProvider) Name() string { return "searxng" }
+
+func (p *SearXNGProvider) Search(ctx context.Context, query string) ([]SearchResult, error) {
+	reqURL := fmt.Sprintf("%s/search?q=%s&format=json", p.baseURL, url.QueryEscape(query))
+	req, err := http.NewRequestWithContext(ctx, "GET", reqURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("search request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP error %d: %s", resp.StatusCode, resp.Status)
+	}
+
+	var payload struct {
+		Results []struct {
+			Title   string `json:"title"`
+			URL     string `json:"url"`
+			Content string `json:"content"`
+		} `json:"results"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return nil, fmt.Errorf("failed to decode search results: %w", err)
+	}
+
+	results := make([]SearchResult, 0, len(payload.Results))
+	for _, r := range payload.Results {
+		results = append(results, SearchResult{
+			Title:   strings.TrimSpace(r.Title),
+			URL:     r.URL,
+			Snippet: strings.TrimSpace(r.Content),
+		})
+	}
+	return results, nil
+}
+
+// filterSearchResults applies allowed/blocked domain lists, preserving rank order.
+// A domain matches its subdomains (e.g. "go.dev" matches "pkg.go.dev").
+func filterSearchResults(results []SearchResult, allowed, blocked []string) []SearchResult {
+	filtered := make([]SearchResult, 0, len(results))
+	for _, r := range results {
+		parsed, err := url.Parse(r.URL)
+		if err != nil || parsed.Host == "" {
+			continue
+		}
+		host := strings.ToLower(parsed.Hostname())
+		if len(allowed) > 0 && !matchesAnyDomain(host, allowed) {
+			continue
+		}
+		if matchesAnyDomain(host, blocked) {
+			continue
+		}
+		filtered = append(filtered, r)
+	}
+	return filtered
+}
+
+func matchesAnyDomain(host string, domains []string) bool {
+	for _, d := range domains {
+		d = strings.ToLower(strings.TrimSpace(d))
+		if d == "" {
+			continue
+		}
+		if host == d || strings.HasSuffix(host, "."+d) {
+			return true
+		}
+	}
+	return false
+}
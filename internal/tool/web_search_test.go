@@ -0,0 +1,99 @@
+package tool
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/fpt/go-gennai-cli/pkg/message"
+)
+
+func TestFilterSearchResults(t *testing.T) {
+	results := []SearchResult{
+		{Title: "Go", URL: "https://go.dev/doc"},
+		{Title: "pkg", URL: "https://pkg.go.dev/net/http"},
+		{Title: "Blog", URL: "https://example.com/go"},
+		{Title: "Spam", URL: "https://spam.example.com/"},
+	}
+
+	tests := []struct {
+		name     string
+		allowed  []string
+		blocked  []string
+		expected []string
+	}{
+		{"no filters", nil, nil, []string{"Go", "pkg", "Blog", "Spam"}},
+		{"allowed includes subdomains", []string{"go.dev"}, nil, []string{"Go", "pkg"}},
+		{"blocked subdomain", nil, []string{"spam.example.com"}, []string{"Go", "pkg", "Blog"}},
+		{"allowed and blocked", []string{"example.com"}, []string{"spam.example.com"}, []string{"Blog"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := filterSearchResults(results, tt.allowed, tt.blocked)
+			if len(got) != len(tt.expected) {
+				t.Fatalf("expected %d results, got %d", len(tt.expected), len(got))
+			}
+			for i, r := range got {
+				if r.Title != tt.expected[i] {
+					t.Errorf("result %d: expected %s, got %s", i, tt.expected[i], r.Title)
+				}
+			}
+		})
+	}
+}
+
+func TestUnwrapDuckDuckGoURL(t *testing.T) {
+	href := "//duckduckgo.com/l/?uddg=https%3A%2F%2Fgo.dev%2Fdoc%2F&rut=abc"
+	if got := unwrapDuckDuckGoURL(href); got != "https://go.dev/doc/" {
+		t.Errorf("expected https://go.dev/doc/, got %s", got)
+	}
+	if got := unwrapDuckDuckGoURL("https://go.dev/"); got != "https://go.dev/" {
+		t.Errorf("expected direct URL to be unchanged, got %s", got)
+	}
+}
+
+func TestWebSearchWithSearXNG(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("format") != "json" {
+			t.Errorf("expected format=json, got %s", r.URL.RawQuery)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"results":[
+			{"title":"Effective Go","url":"https://go.dev/doc/effective_go","content":"Tips for writing clear Go."},
+			{"title":"Blocked","url":"https://blocked.example/go","content":"nope"}
+		]}`))
+	}))
+	defer server.Close()
+
+	manager := NewWebToolManagerWithSearchProvider(NewSearXNGProvider(server.URL))
+	result, err := manager.CallTool(context.Background(), "WebSearch", message.ToolArgumentValues{
+		"query":           "effective go",
+		"blocked_domains": []any{"blocked.example"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Error != "" {
+		t.Fatalf("unexpected tool error: %s", result.Error)
+	}
+	if !strings.Contains(result.Text, "1. Effective Go") || !strings.Contains(result.Text, "https://go.dev/doc/effective_go") {
+		t.Errorf("expected ranked result in output, got:\n%s", result.Text)
+	}
+	if strings.Contains(result.Text, "blocked.example") {
+		t.Errorf("blocked domain should be filtered out, got:\n%s", result.Text)
+	}
+}
+
+func TestWebSearchStubWithoutProvider(t *testing.T) {
+	manager := NewWebToolManager()
+	result, err := manager.CallTool(context.Background(), "WebSearch", message.ToolArgumentValues{"query": "golang"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(result.Text, "WebSearch not available") {
+		t.Errorf("expected stub message, got %q", result.Text)
+	}
+}
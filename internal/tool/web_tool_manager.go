@@ -1,8 +1,10 @@
 package tool
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"strings"
@@ -10,18 +12,83 @@ import (
 
 	"github.com/PuerkitoBio/goquery"
 	"github.com/fpt/go-gennai-cli/pkg/agent/domain"
+	"github.com/fpt/go-gennai-cli/pkg/httpclient"
+	pkgLogger "github.com/fpt/go-gennai-cli/pkg/logger"
 	"github.com/fpt/go-gennai-cli/pkg/message"
 )
 
 // WebToolManager provides web-related tools for search, navigation, and content fetching
 type WebToolManager struct {
-	tools map[message.ToolName]message.Tool
+	tools          map[message.ToolName]message.Tool
+	searchProvider SearchProvider   // nil means WebSearch is unavailable
+	fetchCache     *fetchCache      // nil means WebFetch results are not cached
+	robots         *robotsCache     // nil means robots.txt is not consulted
+	rateLimiter    *hostRateLimiter // nil means requests are not throttled
+	offline        bool             // every tool refuses to make requests
+
+	maxResponseBytes int64 // WebFetch reads at most this much of a response body
+	maxOutputChars   int   // WebFetch output beyond this is truncated
+	maxLinks         int   // links listed under "Important Links" in markdown output
 }
 
+// WebConfig holds configuration for web tools
+type WebConfig struct {
+	SearchProvider SearchProvider // nil keeps WebSearch as an informative stub
+	FetchCache     FetchCacheConfig
+	RespectRobots  bool    // WebFetch refuses URLs disallowed by the host's robots.txt
+	PerHostRPS     float64 // WebFetch requests per second per host (0 = unlimited)
+	Offline        bool    // WebFetch and WebSearch refuse instead of making requests
+
+	MaxResponseBytes int64 // WebFetch response body limit (0 = DefaultFetchMaxBytes)
+	MaxOutputChars   int   // WebFetch output limit (0 = DefaultFetchMaxChars)
+	MaxLinks         int   // links extracted into markdown output (0 = DefaultFetchMaxLinks)
+}
+
+// maxSearchResults caps the number of results returned by WebSearch
+const maxSearchResults = 10
+
+// WebFetch limits that protect memory and the context window from huge pages
+const (
+	DefaultFetchMaxBytes = 5 * 1024 * 1024
+	DefaultFetchMaxChars = 100000
+	DefaultFetchMaxLinks = 10
+)
+
 // NewWebToolManager creates a new web tool manager with all web-related tools
 func NewWebToolManager() domain.ToolManager {
+	return NewWebToolManagerWithSearchProvider(nil)
+}
+
+// NewWebToolManagerWithSearchProvider creates a web tool manager whose WebSearch tool
+// uses the given provider. A nil provider keeps WebSearch as an informative stub.
+func NewWebToolManagerWithSearchProvider(provider SearchProvider) domain.ToolManager {
+	return NewWebToolManagerWithConfig(WebConfig{SearchProvider: provider})
+}
+
+// NewWebToolManagerWithConfig creates a web tool manager from the given configuration
+func NewWebToolManagerWithConfig(config WebConfig) domain.ToolManager {
 	m := &WebToolManager{
-		tools: make(map[message.ToolName]message.Tool),
+		tools:          make(map[message.ToolName]message.Tool),
+		searchProvider: config.SearchProvider,
+		fetchCache:     newFetchCache(config.FetchCache),
+		rateLimiter:    newHostRateLimiter(config.PerHostRPS),
+		offline:        config.Offline,
+
+		maxResponseBytes: config.MaxResponseBytes,
+		maxOutputChars:   config.MaxOutputChars,
+		maxLinks:         config.MaxLinks,
+	}
+	if config.RespectRobots {
+		m.robots = newRobotsCache()
+	}
+	if m.maxResponseBytes <= 0 {
+		m.maxResponseBytes = DefaultFetchMaxBytes
+	}
+	if m.maxOutputChars <= 0 {
+		m.maxOutputChars = DefaultFetchMaxChars
+	}
+	if m.maxLinks <= 0 {
+		m.maxLinks = DefaultFetchMaxLinks
 	}
 
 	// Register all web-related tools
@@ -30,21 +97,38 @@ func NewWebToolManager() domain.ToolManager {
 }
 
 func (m *WebToolManager) registerWebTools() {
+	if m.offline {
+		m.registerTool("WebFetch", message.UncachedRead, "Unavailable: gennai is running in offline mode.",
+			[]message.ToolArgument{{Name: "url", Description: "URL of the webpage to fetch", Required: true, Type: "string"}},
+			m.handleOffline)
+		m.registerTool("WebSearch", message.UncachedRead, "Unavailable: gennai is running in offline mode.",
+			[]message.ToolArgument{{Name: "query", Description: "Search query", Required: true, Type: "string"}},
+			m.handleOffline)
+		return
+	}
+
 	// WebFetch (preferred)
-	m.RegisterTool("WebFetch", "Fetch a webpage over HTTP(S) and return main content as markdown. Follows typical headers; supply specific URLs.",
+	m.registerTool("WebFetch", message.UncachedRead, "Fetch a webpage over HTTP(S) and return main content as markdown. Follows typical headers; supply specific URLs. Use format=text or format=html when markdown loses structure such as data tables.",
 		[]message.ToolArgument{
 			{Name: "url", Description: "URL of the webpage to fetch and convert to markdown", Required: true, Type: "string"},
+			{Name: "format", Description: "Output format: markdown (default), text (visible text; table cells tab-separated) or html (cleaned HTML without scripts/styles)", Required: false, Type: "string"},
+			{Name: "selector", Description: "CSS selector limiting output to the matching elements, e.g. \"table.results\" or \"#main\"", Required: false, Type: "string"},
 		},
 		m.handleFetchWeb)
 
-	// WebSearch (stub): declare interface compatibility; return informative message
-	m.RegisterTool("WebSearch", "Search the web (stub). Not implemented in this build. Provide URLs or use WebFetch with a concrete link.",
-		[]message.ToolArgument{
-			{Name: "query", Description: "Search query", Required: true, Type: "string"},
-			{Name: "allowed_domains", Description: "Only include results from these domains", Required: false, Type: "array"},
-			{Name: "blocked_domains", Description: "Exclude results from these domains", Required: false, Type: "array"},
-		},
-		m.handleWebSearchStub)
+	searchArgs := []message.ToolArgument{
+		{Name: "query", Description: "Search query", Required: true, Type: "string"},
+		{Name: "allowed_domains", Description: "Only include results from these domains", Required: false, Type: "array"},
+		{Name: "blocked_domains", Description: "Exclude results from these domains", Required: false, Type: "array"},
+	}
+	if m.searchProvider == nil {
+		// WebSearch (stub): declare interface compatibility; return informative message
+		m.registerTool("WebSearch", message.UncachedRead, "Search the web (stub). Not implemented in this build. Provide URLs or use WebFetch with a concrete link.",
+			searchArgs, m.handleWebSearchStub)
+		return
+	}
+	m.registerTool("WebSearch", message.UncachedRead, "Search the web and return a ranked list of results (title, URL, snippet). Use WebFetch to read a result.",
+		searchArgs, m.handleWebSearch)
 }
 
 // Implement domain.ToolManager interface
@@ -66,21 +150,37 @@ func (m *WebToolManager) CallTool(ctx context.Context, name message.ToolName, ar
 	return tool.Handler()(ctx, args)
 }
 
+// RegisterTool registers a tool that declares no effects (message.AccessUnknown)
 func (m *WebToolManager) RegisterTool(name message.ToolName, description message.ToolDescription, arguments []message.ToolArgument, handler func(ctx context.Context, args message.ToolArgumentValues) (message.ToolResult, error)) {
+	m.registerTool(name, message.ToolEffects{}, description, arguments, handler)
+}
+
+// registerTool registers a built-in tool with the effects it declares
+func (m *WebToolManager) registerTool(name message.ToolName, effects message.ToolEffects, description message.ToolDescription, arguments []message.ToolArgument, handler func(ctx context.Context, args message.ToolArgumentValues) (message.ToolResult, error)) {
 	m.tools[name] = &webTool{
 		name:        name,
 		description: description,
 		arguments:   arguments,
 		handler:     handler,
+		effects:     effects,
 	}
 }
 
-// handleFetchWeb fetches a webpage and converts it to markdown
+// handleFetchWeb fetches a webpage and converts it to markdown, text or cleaned HTML
 func (m *WebToolManager) handleFetchWeb(ctx context.Context, args message.ToolArgumentValues) (message.ToolResult, error) {
 	urlStr, ok := args["url"].(string)
 	if !ok {
 		return message.NewToolResultError("url parameter is required and must be a string"), nil
 	}
+	format := fetchFormatMarkdown
+	if f, _ := args["format"].(string); f != "" {
+		format = strings.ToLower(strings.TrimSpace(f))
+	}
+	if format != fetchFormatMarkdown && format != fetchFormatText && format != fetchFormatHTML {
+		return message.NewToolResultError(fmt.Sprintf("invalid format %q: must be markdown, text or html", format)), nil
+	}
+	selector, _ := args["selector"].(string)
+	selector = strings.TrimSpace(selector)
 
 	// Validate and parse URL
 	parsedURL, err := url.Parse(urlStr)
@@ -92,9 +192,28 @@ func (m *WebToolManager) handleFetchWeb(ctx context.Context, args message.ToolAr
 		return message.NewToolResultError("invalid URL scheme: must be http or https"), nil
 	}
 
-	// Create HTTP client with timeout and proper headers
-	client := &http.Client{
-		Timeout: 30 * time.Second,
+	cacheKey := fetchCacheKey(urlStr, format, selector)
+	if cached, ok := m.fetchCache.get(cacheKey); ok {
+		logger.InfoWithIntention(pkgLogger.IntentionTool, "WebFetch cache hit", "url", urlStr, "cached", true)
+		return message.NewToolResultText(fmt.Sprintf("(cached) %s\n\n%s", urlStr, cached)), nil
+	}
+
+	if m.robots != nil && !m.robots.allowed(ctx, parsedURL) {
+		logger.InfoWithIntention(pkgLogger.IntentionTool, "WebFetch disallowed by robots.txt", "url", urlStr)
+		return message.NewToolResultError(robotsDisallowedMessage(parsedURL)), nil
+	}
+	waited, err := m.rateLimiter.wait(ctx, parsedURL.Host)
+	if err != nil {
+		return message.NewToolResultError(fmt.Sprintf("fetch cancelled while rate limited: %v", err)), nil
+	}
+	if waited > 0 {
+		logger.DebugWithIntention(pkgLogger.IntentionTool, "WebFetch rate limited", "host", parsedURL.Host, "waited", waited)
+	}
+
+	// Create HTTP client with timeout; the shared transport honors proxy and CA settings
+	client := httpclient.New(30 * time.Second)
+	if proxy := httpclient.ProxyFor(urlStr); proxy != "" {
+		logger.DebugWithIntention(pkgLogger.IntentionTool, "WebFetch using proxy", "url", urlStr, "proxy", proxy)
 	}
 
 	// Create request with proper headers
@@ -120,16 +239,102 @@ func (m *WebToolManager) handleFetchWeb(ctx context.Context, args message.ToolAr
 		return message.NewToolResultError(fmt.Sprintf("HTTP error %d: %s", resp.StatusCode, resp.Status)), nil
 	}
 
-	// Parse HTML with goquery
-	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	// Read at most maxResponseBytes; one more byte tells whether there was more
+	body, err := io.ReadAll(io.LimitReader(resp.Body, m.maxResponseBytes+1))
+	if err != nil {
+		return message.NewToolResultError(fmt.Sprintf("failed to read webpage: %v", err)), nil
+	}
+	truncated := int64(len(body)) > m.maxResponseBytes
+	if truncated {
+		body = body[:m.maxResponseBytes]
+		logger.InfoWithIntention(pkgLogger.IntentionTool, "WebFetch response truncated", "url", urlStr, "max_bytes", m.maxResponseBytes)
+	}
+
+	// Parse HTML with goquery; a cut-off document still parses
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(body))
 	if err != nil {
 		return message.NewToolResultError(fmt.Sprintf("failed to parse HTML: %v", err)), nil
 	}
 
-	// Convert to markdown
-	markdown := m.convertToMarkdown(doc, parsedURL)
+	content, err := m.renderFetchedPage(doc, parsedURL, format, selector)
+	if err != nil {
+		return message.NewToolResultError(err.Error()), nil
+	}
+	if truncated {
+		content += fmt.Sprintf("\n\n... (page truncated: only the first %d bytes of the response were read)", m.maxResponseBytes)
+	}
+
+	if !isNoStore(resp.Header) {
+		m.fetchCache.put(cacheKey, content)
+	}
+	logger.DebugWithIntention(pkgLogger.IntentionTool, "WebFetch completed", "url", urlStr, "format", format, "cached", false)
+
+	return message.NewToolResultText(content), nil
+}
+
+// handleWebSearch queries the configured search provider and formats ranked results
+func (m *WebToolManager) handleWebSearch(ctx context.Context, args message.ToolArgumentValues) (message.ToolResult, error) {
+	query, ok := args["query"].(string)
+	if !ok || strings.TrimSpace(query) == "" {
+		return message.NewToolResultError("query parameter is required and must be a string"), nil
+	}
+
+	allowed := stringSliceArg(args["allowed_domains"])
+	blocked := stringSliceArg(args["blocked_domains"])
+
+	searchCtx, cancel := context.WithTimeout(ctx, searchTimeout)
+	defer cancel()
+
+	results, err := m.searchProvider.Search(searchCtx, query)
+	if err != nil {
+		return message.NewToolResultError(fmt.Sprintf("web search failed (%s): %v", m.searchProvider.Name(), err)), nil
+	}
+
+	results = filterSearchResults(results, allowed, blocked)
+	if len(results) > maxSearchResults {
+		results = results[:maxSearchResults]
+	}
+	if len(results) == 0 {
+		return message.NewToolResultText(fmt.Sprintf("No results found for %q.", query)), nil
+	}
 
-	return message.NewToolResultText(markdown), nil
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("Search results for %q:\n\n", query))
+	for i, r := range results {
+		b.WriteString(fmt.Sprintf("%d. %s\n   %s\n", i+1, r.Title, r.URL))
+		if r.Snippet != "" {
+			b.WriteString(fmt.Sprintf("   %s\n", r.Snippet))
+		}
+		b.WriteString("\n")
+	}
+	return message.NewToolResultText(strings.TrimRight(b.String(), "\n")), nil
+}
+
+// stringSliceArg converts an array argument (or comma-separated string) to a string slice
+func stringSliceArg(v any) []string {
+	switch val := v.(type) {
+	case []any:
+		out := make([]string, 0, len(val))
+		for _, item := range val {
+			if s, ok := item.(string); ok && s != "" {
+				out = append(out, s)
+			}
+		}
+		return out
+	case []string:
+		return val
+	case string:
+		if val == "" {
+			return nil
+		}
+		return strings.Split(val, ",")
+	}
+	return nil
+}
+
+// handleOffline refuses network access in offline mode
+func (m *WebToolManager) handleOffline(ctx context.Context, args message.ToolArgumentValues) (message.ToolResult, error) {
+	return message.NewToolResultError("network access is disabled: gennai is running in offline mode. Work from local files instead."), nil
 }
 
 // handleWebSearchStub returns a compatibility message explaining unavailability
@@ -322,35 +527,33 @@ func (m *WebToolManager) extractLinks(doc *goquery.Document, baseURL *url.URL) [
 	seen := make(map[string]bool)
 
 	// Find important links (excluding navigation)
-	doc.Find("a[href]").Each(func(i int, s *goquery.Selection) {
+	doc.Find("a[href]").EachWithBreak(func(i int, s *goquery.Selection) bool {
 		href, _ := s.Attr("href")
 		text := strings.TrimSpace(s.Text())
 
 		// Skip empty links or navigation links
 		if text == "" || len(text) > 100 {
-			return
+			return true
 		}
 
 		// Skip common navigation patterns
 		lowerText := strings.ToLower(text)
 		if strings.Contains(lowerText, "home") || strings.Contains(lowerText, "about") ||
 			strings.Contains(lowerText, "contact") || strings.Contains(lowerText, "menu") {
-			return
+			return true
 		}
 
 		// Resolve to absolute URL
 		absoluteURL := m.resolveURL(href, baseURL)
 		if absoluteURL == "" || seen[absoluteURL] {
-			return
+			return true
 		}
 
 		seen[absoluteURL] = true
 		links = append(links, Link{Text: text, URL: absoluteURL})
 
 		// Limit to prevent overwhelming output
-		if len(links) >= 10 {
-			return
-		}
+		return len(links) < m.maxLinks
 	})
 
 	return links
@@ -362,6 +565,7 @@ type webTool struct {
 	description message.ToolDescription
 	arguments   []message.ToolArgument
 	handler     func(ctx context.Context, args message.ToolArgumentValues) (message.ToolResult, error)
+	effects     message.ToolEffects
 }
 
 func (t *webTool) RawName() message.ToolName {
@@ -380,6 +584,10 @@ func (t *webTool) Handler() func(ctx context.Context, args message.ToolArgumentV
 	return t.handler
 }
 
+func (t *webTool) Effects() message.ToolEffects {
+	return t.effects
+}
+
 func (t *webTool) Arguments() []message.ToolArgument {
 	return t.arguments
 }
@@ -0,0 +1,144 @@
+package tool
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/fpt/go-gennai-cli/pkg/message"
+)
+
+func TestWebToolManager_Offline(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Write([]byte("<html><body>hello</body></html>"))
+	}))
+	defer server.Close()
+
+	manager := NewWebToolManagerWithConfig(WebConfig{Offline: true})
+	ctx := context.Background()
+
+	for name, args := range map[message.ToolName]message.ToolArgumentValues{
+		"WebFetch":  {"url": server.URL},
+		"WebSearch": {"query": "golang"},
+	} {
+		res, err := manager.CallTool(ctx, name, args)
+		if err != nil {
+			t.Fatalf("%s returned error: %v", name, err)
+		}
+		if !strings.Contains(res.Error, "offline mode") {
+			t.Errorf("%s: expected an offline refusal, got %+v", name, res)
+		}
+	}
+	if n := requests.Load(); n != 0 {
+		t.Errorf("expected no outbound requests in offline mode, got %d", n)
+	}
+}
+
+func TestWebFetch_FormatsAndSelector(t *testing.T) {
+	page := `<html><head><title>Stats</title><style>.x{color:red}</style></head><body>
+<nav>Menu</nav>
+<main><h1>Results</h1><p>Quarterly   numbers.</p>
+<table id="data"><tr><th>Region</th><th>Sales</th></tr><tr><td>North</td><td>10</td></tr><tr><td>South</td><td>7</td></tr></table>
+</main><script>track()</script></body></html>`
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Write([]byte(page))
+	}))
+	defer server.Close()
+
+	manager := NewWebToolManagerWithConfig(WebConfig{})
+	fetch := func(args message.ToolArgumentValues) message.ToolResult {
+		t.Helper()
+		args["url"] = server.URL
+		res, err := manager.CallTool(context.Background(), "WebFetch", args)
+		if err != nil {
+			t.Fatalf("WebFetch returned error: %v", err)
+		}
+		return res
+	}
+
+	text := fetch(message.ToolArgumentValues{"format": "text"})
+	for _, want := range []string{"Quarterly numbers.", "Region\tSales", "North\t10", "South\t7"} {
+		if !strings.Contains(text.Text, want) {
+			t.Errorf("text output missing %q:\n%s", want, text.Text)
+		}
+	}
+	if strings.Contains(text.Text, "track()") || strings.Contains(text.Text, "color:red") {
+		t.Errorf("text output should not include scripts or styles:\n%s", text.Text)
+	}
+
+	html := fetch(message.ToolArgumentValues{"format": "html", "selector": "#data"})
+	if !strings.HasPrefix(html.Text, `<table id="data">`) || strings.Contains(html.Text, "<main>") {
+		t.Errorf("expected only the selected table, got:\n%s", html.Text)
+	}
+
+	markdown := fetch(message.ToolArgumentValues{"selector": "h1"})
+	if !strings.Contains(markdown.Text, "# Results") || strings.Contains(markdown.Text, "Quarterly") {
+		t.Errorf("expected markdown of the selected heading only, got:\n%s", markdown.Text)
+	}
+
+	// Each format/selector combination is cached separately
+	if n := hits.Load(); n != 3 {
+		t.Errorf("expected 3 requests, got %d", n)
+	}
+
+	if res := fetch(message.ToolArgumentValues{"selector": ".missing"}); !strings.Contains(res.Error, "matched no elements") {
+		t.Errorf("expected a no-match error, got %+v", res)
+	}
+	if res := fetch(message.ToolArgumentValues{"format": "pdf"}); !strings.Contains(res.Error, "invalid format") {
+		t.Errorf("expected an invalid format error, got %+v", res)
+	}
+}
+
+func TestWebFetch_Limits(t *testing.T) {
+	var page strings.Builder
+	page.WriteString("<html><head><title>Big</title></head><body><main><p>Start of the page.</p>")
+	for i := range 50 {
+		fmt.Fprintf(&page, `<p><a href="/doc/%d">Document %d</a></p>`, i, i)
+	}
+	page.WriteString(strings.Repeat("<p>filler text for a pathological page</p>", 2000))
+	page.WriteString("<p>End of the page.</p></main></body></html>")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(page.String()))
+	}))
+	defer server.Close()
+
+	fetch := func(config WebConfig, args message.ToolArgumentValues) string {
+		t.Helper()
+		config.FetchCache.Disabled = true
+		args["url"] = server.URL
+		res, err := NewWebToolManagerWithConfig(config).CallTool(context.Background(), "WebFetch", args)
+		if err != nil || res.Error != "" {
+			t.Fatalf("WebFetch failed: %v %s", err, res.Error)
+		}
+		return res.Text
+	}
+
+	full := fetch(WebConfig{}, message.ToolArgumentValues{})
+	if n := strings.Count(full, "- [Document"); n != DefaultFetchMaxLinks {
+		t.Errorf("expected %d important links by default, got %d", DefaultFetchMaxLinks, n)
+	}
+
+	limited := fetch(WebConfig{MaxLinks: 3, MaxOutputChars: 2000}, message.ToolArgumentValues{})
+	if n := strings.Count(limited, "- [Document"); n > 3 {
+		t.Errorf("expected at most 3 links, got %d", n)
+	}
+	if len(limited) > 2200 || !strings.Contains(limited, "truncated: 2000 of") {
+		t.Errorf("expected markdown cut at 2000 characters, got %d:\n%s", len(limited), limited[len(limited)-120:])
+	}
+
+	cut := fetch(WebConfig{MaxResponseBytes: 4096}, message.ToolArgumentValues{"format": "text"})
+	if !strings.Contains(cut, "Start of the page.") || strings.Contains(cut, "End of the page.") {
+		t.Errorf("expected only the start of the response, got:\n%s", cut)
+	}
+	if !strings.Contains(cut, "only the first 4096 bytes of the response were read") {
+		t.Errorf("expected a truncation notice, got tail:\n%s", cut[len(cut)-120:])
+	}
+}
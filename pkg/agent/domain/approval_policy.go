@@ -0,0 +1,68 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ApprovalMode is what happens when the agent calls a tool of an approval category
+type ApprovalMode string
+
+const (
+	ApprovalAlways ApprovalMode = "always" // run without asking
+	ApprovalAsk    ApprovalMode = "ask"    // wait for the user's approval
+	ApprovalNever  ApprovalMode = "never"  // refuse the call
+)
+
+// Approval categories of tools with side effects
+const (
+	ApprovalCategoryWrite  = "write"  // tools that declare message.AccessWrite, except delete_file
+	ApprovalCategoryDelete = "delete" // delete_file
+	ApprovalCategoryBash   = "bash"   // bash commands outside the whitelist
+	ApprovalCategoryMCP    = "mcp"    // tools provided by MCP servers
+)
+
+// ApprovalPolicy maps approval categories to modes; categories it does not
+// list follow DefaultApprovalPolicy
+type ApprovalPolicy map[string]ApprovalMode
+
+// DefaultApprovalPolicy asks before changing files or running commands outside
+// the bash whitelist, and lets MCP tools run
+var DefaultApprovalPolicy = ApprovalPolicy{
+	ApprovalCategoryWrite:  ApprovalAsk,
+	ApprovalCategoryDelete: ApprovalAsk,
+	ApprovalCategoryBash:   ApprovalAsk,
+	ApprovalCategoryMCP:    ApprovalAlways,
+}
+
+// ParseApprovalPolicy validates an approval_policy setting of category names
+// to "always", "ask" or "never"
+func ParseApprovalPolicy(raw map[string]string) (ApprovalPolicy, error) {
+	categories := make([]string, 0, len(raw))
+	for category := range raw {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+
+	policy := make(ApprovalPolicy, len(raw))
+	for _, category := range categories {
+		if _, ok := DefaultApprovalPolicy[category]; !ok {
+			return nil, fmt.Errorf("unknown approval_policy category %q (must be write, delete, bash or mcp)", category)
+		}
+		switch mode := ApprovalMode(raw[category]); mode {
+		case ApprovalAlways, ApprovalAsk, ApprovalNever:
+			policy[category] = mode
+		default:
+			return nil, fmt.Errorf("invalid approval_policy mode %q for %s (must be always, ask or never)", raw[category], category)
+		}
+	}
+	return policy, nil
+}
+
+// Mode returns the mode for a category
+func (p ApprovalPolicy) Mode(category string) ApprovalMode {
+	if mode, ok := p[category]; ok {
+		return mode
+	}
+	return DefaultApprovalPolicy[category]
+}
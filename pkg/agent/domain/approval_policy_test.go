@@ -0,0 +1,32 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseApprovalPolicy(t *testing.T) {
+	policy, err := ParseApprovalPolicy(map[string]string{"bash": "never", "mcp": "ask"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for category, want := range map[string]ApprovalMode{
+		ApprovalCategoryBash:   ApprovalNever,
+		ApprovalCategoryMCP:    ApprovalAsk,
+		ApprovalCategoryWrite:  ApprovalAsk,
+		ApprovalCategoryDelete: ApprovalAsk,
+	} {
+		if got := policy.Mode(category); got != want {
+			t.Errorf("Mode(%s) = %s, want %s", category, got, want)
+		}
+	}
+
+	for raw, want := range map[string]string{
+		"network": "unknown approval_policy category",
+		"bash":    "invalid approval_policy mode",
+	} {
+		if _, err := ParseApprovalPolicy(map[string]string{raw: "sometimes"}); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("ParseApprovalPolicy(%s): expected %q error, got %v", raw, want, err)
+		}
+	}
+}
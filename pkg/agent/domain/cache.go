@@ -2,9 +2,15 @@ package domain
 
 import (
 	"context"
+
 	"github.com/fpt/go-gennai-cli/pkg/message"
+	"github.com/pkg/errors"
 )
 
+// ErrLLMCacheMiss marks a request that a replaying response cache has no
+// recorded response for. The request was not sent to the provider.
+var ErrLLMCacheMiss = errors.New("no recorded LLM response for this request")
+
 // CacheKeyProvider is an optional extension that LLM clients can implement to
 // provide a deterministic cache key for a given request. The key should
 // incorporate all inputs that affect the model's output: model ID/version,
@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ErrContextLengthExceeded marks a request rejected because the conversation
+// does not fit the model's context window. Clients may wrap it; errors from
+// provider SDKs are also recognized by their messages (see IsContextLengthError).
+var ErrContextLengthExceeded = errors.New("context length exceeded")
+
+// contextLengthErrorPatterns are lower-cased fragments of provider errors that
+// reject a request for being too long
+var contextLengthErrorPatterns = []string{
+	// Anthropic: 400 invalid_request_error / 413 request_too_large
+	"prompt is too long",
+	"exceed context limit",
+	"request_too_large",
+	// OpenAI: 400 context_length_exceeded
+	"context_length_exceeded",
+	"maximum context length",
+	"string_above_max_length",
+	// Gemini: 400 INVALID_ARGUMENT
+	"exceeds the maximum number of tokens",
+	// Ollama and OpenAI-compatible servers
+	"context window",
+	"too many tokens",
+}
+
+// IsContextLengthError reports whether err means the request exceeded the
+// model's context window
+func IsContextLengthError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, ErrContextLengthExceeded) {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	for _, pattern := range contextLengthErrorPatterns {
+		if strings.Contains(msg, pattern) {
+			return true
+		}
+	}
+	return false
+}
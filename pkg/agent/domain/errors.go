@@ -0,0 +1,113 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Errors that end a run, for callers that handle failure modes differently.
+// Match them with errors.Is; the wrapping error carries the details.
+var (
+	// ErrLLMAuth marks a request the LLM provider rejected for missing,
+	// invalid or insufficient credentials. Retrying will not help.
+	ErrLLMAuth = errors.New("LLM authentication failed")
+
+	// ErrContextOverflow marks a conversation that does not fit the model's
+	// context window even after compaction. It is ErrContextLengthExceeded.
+	ErrContextOverflow = ErrContextLengthExceeded
+
+	// ErrToolFailure marks a run stopped because tool calls could not be
+	// handled. A tool that runs and fails does not end the run; the model
+	// sees its error as the tool result.
+	ErrToolFailure = errors.New("tool failure")
+
+	// ErrMaxIterations marks a run that reached its iteration limit. The run
+	// still returns its partial result; the ReAct loop reports this error as
+	// the reason it stopped.
+	ErrMaxIterations = errors.New("iteration limit reached")
+
+	// ErrTimeLimit marks a run stopped by its wall-clock limit (--time-limit).
+	// Like ErrMaxIterations, the run still returns its partial result.
+	ErrTimeLimit = errors.New("time limit reached")
+)
+
+// authErrorPatterns are lower-cased fragments of provider errors that reject a
+// request for its credentials
+var authErrorPatterns = []string{
+	// Anthropic: 401 authentication_error / 403 permission_error
+	"authentication_error",
+	"invalid x-api-key",
+	"permission_error",
+	// OpenAI and Azure OpenAI: 401 invalid_api_key
+	"invalid_api_key",
+	"incorrect api key",
+	"401 unauthorized",
+	// Gemini: 400 API_KEY_INVALID / 403 PERMISSION_DENIED
+	"api key not valid",
+	"api_key_invalid",
+	"permission_denied",
+}
+
+// IsLLMAuthError reports whether err means the LLM provider rejected the
+// request's credentials
+func IsLLMAuthError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, ErrLLMAuth) {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	for _, pattern := range authErrorPatterns {
+		if strings.Contains(msg, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// LLMError marks an error returned by an LLM client with the domain error it
+// matches, so callers can tell an auth failure or context overflow apart with
+// errors.Is. The provider message patterns are only applied here, to errors
+// known to come from the LLM; other errors are returned unchanged.
+func LLMError(err error) error {
+	switch {
+	case err == nil, errors.Is(err, ErrLLMAuth), errors.Is(err, ErrContextOverflow):
+		return err
+	case IsLLMAuthError(err):
+		return fmt.Errorf("%w: %w", ErrLLMAuth, err)
+	case IsContextLengthError(err):
+		return fmt.Errorf("%w: %w", ErrContextOverflow, err)
+	}
+	return err
+}
+
+// ErrorKind names the failure mode of err for machine-readable output
+// ("llm_auth", "context_overflow", "tool_failure", "max_iterations",
+// "time_limit", "cancelled", "llm_cache_miss"), or returns "" when it matches none of them.
+// Only the sentinel errors count: LLM client errors are marked with them by
+// LLMError, so a tool error that mentions "permission_denied" is not an auth failure.
+func ErrorKind(err error) string {
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, context.Canceled):
+		return "cancelled"
+	case errors.Is(err, ErrToolFailure):
+		return "tool_failure"
+	case errors.Is(err, ErrMaxIterations):
+		return "max_iterations"
+	case errors.Is(err, ErrTimeLimit):
+		return "time_limit"
+	case errors.Is(err, ErrLLMCacheMiss):
+		return "llm_cache_miss"
+	case errors.Is(err, ErrLLMAuth):
+		return "llm_auth"
+	case errors.Is(err, ErrContextOverflow):
+		return "context_overflow"
+	}
+	return ""
+}
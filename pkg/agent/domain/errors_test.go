@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestErrorKind(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{nil, ""},
+		{errors.New("connection refused"), ""},
+		{fmt.Errorf("run: %w", ErrLLMAuth), "llm_auth"},
+		{LLMError(errors.New(`401 Unauthorized {"error":{"code":"invalid_api_key","message":"Incorrect API key provided"}}`)), "llm_auth"},
+		{LLMError(errors.New("Error 400, Message: API key not valid. Please pass a valid API key., Status: INVALID_ARGUMENT")), "llm_auth"},
+		{errors.Wrap(ErrContextOverflow, "even after compaction"), "context_overflow"},
+		{LLMError(errors.New("This model's maximum context length is 8192 tokens")), "context_overflow"},
+		{fmt.Errorf("%w: stopped after 3 invalid calls", ErrToolFailure), "tool_failure"},
+		// Provider patterns only count in errors from the LLM client
+		{errors.New("API key not valid"), ""},
+		{fmt.Errorf("%w: MCP server returned PERMISSION_DENIED", ErrToolFailure), "tool_failure"},
+		{errors.Wrapf(ErrMaxIterations, "stopped after %d iterations", 10), "max_iterations"},
+		{errors.Wrap(ErrTimeLimit, "stopped after 5m0s"), "time_limit"},
+		{fmt.Errorf("%w: request 3f2a", ErrLLMCacheMiss), "llm_cache_miss"},
+		{fmt.Errorf("failed: %w", context.Canceled), "cancelled"},
+	}
+	for _, tt := range tests {
+		if got := ErrorKind(tt.err); got != tt.want {
+			t.Errorf("ErrorKind(%v) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package domain
 
 import (
 	"context"
+	"encoding/json"
 
 	"github.com/pkg/errors"
 
@@ -10,6 +11,9 @@ import (
 
 var ErrInvalidClientType = errors.New("invalid client type for tool calling")
 
+// ErrSchemaUnsupported is returned when a client cannot constrain its output to a JSON schema
+var ErrSchemaUnsupported = errors.New("structured output with a JSON schema is not supported by this client")
+
 // LLM represents the base language model interface for basic chat functionality
 type LLM interface {
 	// Chat sends a message to the LLM and returns the response
@@ -37,6 +41,15 @@ type StructuredLLM[T any] interface {
 	ChatWithStructure(ctx context.Context, messages []message.Message, enableThinking bool, thinkingChan chan<- string) (T, error)
 }
 
+// SchemaLLM represents a language model whose answer can be constrained by a
+// JSON schema supplied at runtime (rather than derived from a Go type)
+type SchemaLLM interface {
+	LLM
+
+	// ChatWithSchema sends messages to the LLM and returns its answer as JSON text
+	ChatWithSchema(ctx context.Context, messages []message.Message, schema json.RawMessage) (string, error)
+}
+
 // VisionLLM extends LLM with vision capabilities for image analysis
 type VisionLLM interface {
 	LLM
@@ -44,3 +57,18 @@ type VisionLLM interface {
 	// SupportsVision returns true if this client supports vision/image analysis
 	SupportsVision() bool
 }
+
+// ResponseStreamer is an optional extension for clients that can stream the
+// text of a response as it is generated. While a channel is set, each text
+// delta is sent to it; the returned message still carries the full content.
+// Passing nil stops streaming.
+type ResponseStreamer interface {
+	SetResponseChannel(ch chan<- string)
+}
+
+// ThinkingBudgetConfigurator is an optional extension for clients whose
+// provider accepts a token budget for extended thinking. A budget of 0 keeps
+// the client default; implementations raise budgets below the provider minimum.
+type ThinkingBudgetConfigurator interface {
+	SetThinkingBudget(tokens int)
+}
@@ -40,11 +40,13 @@ type MCPServerConfig struct {
 	Enabled bool   `json:"enabled"`
 
 	// Connection configuration
-	Type    MCPServerType `json:"type"`              // stdio, http, sse, oauth
-	Command string        `json:"command,omitempty"` // For stdio servers
-	Args    []string      `json:"args,omitempty"`    // Command arguments
-	Env     []string      `json:"env,omitempty"`     // Environment variables
-	URL     string        `json:"url,omitempty"`     // For HTTP/SSE servers
+	Type        MCPServerType     `json:"type"`                   // stdio, http or sse (empty: http when url is set, otherwise stdio)
+	Command     string            `json:"command,omitempty"`      // For stdio servers
+	Args        []string          `json:"args,omitempty"`         // Command arguments
+	Env         []string          `json:"env,omitempty"`          // Environment variables
+	URL         string            `json:"url,omitempty"`          // For HTTP/SSE servers
+	BearerToken string            `json:"bearer_token,omitempty"` // For HTTP/SSE servers; $VAR references are expanded from the environment
+	Headers     map[string]string `json:"headers,omitempty"`      // Extra HTTP headers for HTTP/SSE servers
 
 	// Tool filtering
 	AllowedTools []string `json:"allowed_tools,omitempty"` // If specified, only these tools will be loaded
@@ -55,9 +57,28 @@ type MCPServerType string
 
 const (
 	MCPServerTypeStdio MCPServerType = "stdio"
+	MCPServerTypeHTTP  MCPServerType = "http" // Streamable HTTP transport
 	MCPServerTypeSSE   MCPServerType = "sse"
 )
 
+// TransportType returns the configured transport, inferring it when type is
+// omitted: a server with a URL uses HTTP, otherwise stdio
+func (c MCPServerConfig) TransportType() MCPServerType {
+	if c.Type != "" {
+		return c.Type
+	}
+	if c.URL != "" {
+		return MCPServerTypeHTTP
+	}
+	return MCPServerTypeStdio
+}
+
+// IsRemote reports whether the server is reached over the network (HTTP or SSE)
+func (c MCPServerConfig) IsRemote() bool {
+	t := c.TransportType()
+	return t == MCPServerTypeHTTP || t == MCPServerTypeSSE
+}
+
 // MCPToolManager manages tools from multiple MCP servers
 type MCPToolManager interface {
 	ToolManager
@@ -22,7 +22,8 @@ type ReAct interface {
 	Close()
 	GetStatus() AgentStatus
 	GetLastMessage() message.Message
-	GetPendingToolCall() message.Message // Get the currently pending tool call
+	GetPendingToolCall() message.Message    // Get the currently pending tool call
+	GetPendingApprovalCategories() []string // Approval categories (write, bash, ...) of the pending tool call
 	ClearHistory()
 	GetConversationSummary() string
 }
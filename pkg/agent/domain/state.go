@@ -15,8 +15,17 @@ type State interface {
 	CleanupMandatory() error
 	// CompactIfNeeded performs compaction only if token usage exceeds threshold
 	CompactIfNeeded(ctx context.Context, llm LLM, maxTokens int, thresholdPercent float64) error
+	// CompactNow compacts immediately regardless of usage (e.g. after a context-length error)
+	CompactNow(ctx context.Context, llm LLM) error
+	// TrimToTokens drops the oldest non-system messages until the estimated size fits
+	// maxTokens, keeping the latest user request and everything after it
+	TrimToTokens(maxTokens int) int
 	GetValidConversationHistory(maxMessages int) []message.Message
 	RemoveMessagesBySource(source message.MessageSource) int
+	// RemoveUnpairedToolCalls removes tool calls without a result (e.g. after cancellation)
+	RemoveUnpairedToolCalls() int
+	// RemoveLastTurn removes the latest user request and everything after it
+	RemoveLastTurn() int
 	// GetTotalTokenUsage returns the total token usage across all messages
 	GetTotalTokenUsage() (inputTokens, outputTokens, totalTokens int)
 	// Context persistence using repository
@@ -1,8 +1,10 @@
 package events
 
 import (
+	"encoding/json"
 	"time"
 
+	"github.com/fpt/go-gennai-cli/pkg/agent/domain"
 	"github.com/fpt/go-gennai-cli/pkg/message"
 )
 
@@ -11,11 +13,14 @@ type EventType string
 
 const (
 	EventTypeThinkingChunk EventType = "thinking_chunk"
+	EventTypeResponseChunk EventType = "response_chunk"
 	EventTypeToolCallStart EventType = "tool_call_start"
 	EventTypeToolCallEnd   EventType = "tool_call_end"
 	EventTypeToolResult    EventType = "tool_result"
+	EventTypeToolOutput    EventType = "tool_output"
 	EventTypeResponse      EventType = "response"
 	EventTypeError         EventType = "error"
+	EventTypeUsage         EventType = "usage"
 )
 
 // AgentEvent represents a structured event from the agent
@@ -38,6 +43,13 @@ type ThinkingChunkData struct {
 	Content string `json:"content"`
 }
 
+// ResponseChunkData contains a piece of response text as it is generated. Text
+// streamed before a tool call is narration; the final response repeats the
+// complete answer.
+type ResponseChunkData struct {
+	Content string `json:"content"`
+}
+
 // ToolCallStartData contains information about a tool call starting
 type ToolCallStartData struct {
 	ToolName  string                     `json:"tool_name"`
@@ -54,10 +66,28 @@ type ToolCallEndData struct {
 
 // ToolResultData contains tool execution results
 type ToolResultData struct {
+	ToolName string        `json:"tool_name"`
+	CallID   string        `json:"call_id,omitempty"`
+	Content  string        `json:"content"`
+	IsError  bool          `json:"is_error"`
+	Duration time.Duration `json:"-"` // time the tool ran (0 when it was not executed); serialized as duration_ms
+}
+
+// MarshalJSON serializes the duration in milliseconds as "duration_ms", which
+// readers outside Go can use without knowing time.Duration counts nanoseconds
+func (d ToolResultData) MarshalJSON() ([]byte, error) {
+	type plain ToolResultData
+	return json.Marshal(struct {
+		plain
+		DurationMs int64 `json:"duration_ms,omitempty"`
+	}{plain(d), d.Duration.Milliseconds()})
+}
+
+// ToolOutputData contains one line of output from a running tool, streamed
+// before its ToolResult
+type ToolOutputData struct {
 	ToolName string `json:"tool_name"`
-	CallID   string `json:"call_id,omitempty"`
-	Content  string `json:"content"`
-	IsError  bool   `json:"is_error"`
+	Line     string `json:"line"`
 }
 
 // ResponseData contains the final agent response
@@ -65,12 +95,58 @@ type ResponseData struct {
 	Message message.Message `json:"message"`
 }
 
+// MarshalJSON serializes the message's public fields (Message is an interface
+// backed by types with unexported fields)
+func (d ResponseData) MarshalJSON() ([]byte, error) {
+	type responseMessage struct {
+		ID       string `json:"id"`
+		Type     string `json:"type"`
+		Content  string `json:"content"`
+		Thinking string `json:"thinking,omitempty"`
+	}
+	var out struct {
+		Message *responseMessage `json:"message"`
+	}
+	if d.Message != nil {
+		out.Message = &responseMessage{
+			ID:       d.Message.ID(),
+			Type:     d.Message.Type().String(),
+			Content:  d.Message.Content(),
+			Thinking: d.Message.Thinking(),
+		}
+	}
+	return json.Marshal(out)
+}
+
+// UsageData contains the running token usage of a run and its estimated cost
+type UsageData struct {
+	InputTokens    int     `json:"input_tokens"`
+	OutputTokens   int     `json:"output_tokens"`
+	TotalTokens    int     `json:"total_tokens"`
+	EstimatedCost  float64 `json:"estimated_cost,omitempty"`   // 0 when no pricing is configured
+	MaxTotalTokens int     `json:"max_total_tokens,omitempty"` // 0 = unlimited
+}
+
 // ErrorData contains error information
 type ErrorData struct {
 	Error   error  `json:"error"`
 	Context string `json:"context,omitempty"`
 }
 
+// MarshalJSON serializes the error as its message string, with its failure
+// mode (see domain.ErrorKind) as "kind" when it has one
+func (d ErrorData) MarshalJSON() ([]byte, error) {
+	out := struct {
+		Error   string `json:"error"`
+		Kind    string `json:"kind,omitempty"`
+		Context string `json:"context,omitempty"`
+	}{Kind: domain.ErrorKind(d.Error), Context: d.Context}
+	if d.Error != nil {
+		out.Error = d.Error.Error()
+	}
+	return json.Marshal(out)
+}
+
 // EventHandler is a function that processes agent events
 type EventHandler func(event AgentEvent)
 
@@ -0,0 +1,62 @@
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fpt/go-gennai-cli/pkg/agent/domain"
+	"github.com/fpt/go-gennai-cli/pkg/message"
+)
+
+func TestAgentEventJSON(t *testing.T) {
+	ts := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name  string
+		event AgentEvent
+		want  []string
+	}{
+		{
+			name:  "tool start",
+			event: AgentEvent{Type: EventTypeToolCallStart, Timestamp: ts, Data: ToolCallStartData{ToolName: "Read", Arguments: message.ToolArgumentValues{"file_path": "main.go"}}},
+			want:  []string{`"type":"tool_call_start"`, `"tool_name":"Read"`, `"file_path":"main.go"`},
+		},
+		{
+			name:  "tool result",
+			event: AgentEvent{Type: EventTypeToolResult, Timestamp: ts, Data: ToolResultData{ToolName: "bash", Content: "ok", Duration: 1500 * time.Millisecond}},
+			want:  []string{`"type":"tool_result"`, `"tool_name":"bash"`, `"duration_ms":1500`},
+		},
+		{
+			name:  "response",
+			event: AgentEvent{Type: EventTypeResponse, Timestamp: ts, Data: ResponseData{Message: message.NewChatMessage(message.MessageTypeAssistant, "All tests pass")}},
+			want:  []string{`"type":"response"`, `"content":"All tests pass"`, `"type":"assistant"`},
+		},
+		{
+			name:  "error",
+			event: AgentEvent{Type: EventTypeError, Timestamp: ts, Data: ErrorData{Error: errors.New("boom"), Context: "run"}},
+			want:  []string{`"type":"error"`, `"error":"boom"`, `"context":"run"`},
+		},
+		{
+			name:  "error with kind",
+			event: AgentEvent{Type: EventTypeError, Timestamp: ts, Data: ErrorData{Error: fmt.Errorf("run failed: %w", domain.ErrToolFailure)}},
+			want:  []string{`"error":"run failed: tool failure"`, `"kind":"tool_failure"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.event)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(string(data), want) {
+					t.Errorf("expected %s in %s", want, data)
+				}
+			}
+		})
+	}
+}
@@ -3,10 +3,12 @@ package mcp
 import (
 	"context"
 	"fmt"
+	"os"
 
 	"github.com/fpt/go-gennai-cli/pkg/agent/domain"
 	pkgLogger "github.com/fpt/go-gennai-cli/pkg/logger"
 	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
 	mcpapi "github.com/mark3labs/mcp-go/mcp"
 )
 
@@ -24,18 +26,27 @@ func NewMCPClient(config domain.MCPServerConfig) (*MCPClientWrapper, error) {
 	var mcpClient *client.Client
 	var err error
 
-	switch config.Type {
+	switch config.TransportType() {
 	case domain.MCPServerTypeStdio:
 		mcpClient, err = client.NewStdioMCPClient(config.Command, config.Env, config.Args...)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create stdio MCP client: %w", err)
 		}
 
+	case domain.MCPServerTypeHTTP:
+		if config.URL == "" {
+			return nil, fmt.Errorf("URL is required for HTTP MCP server")
+		}
+		mcpClient, err = client.NewStreamableHttpClient(config.URL, transport.WithHTTPHeaders(httpHeaders(config)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create HTTP MCP client: %w", err)
+		}
+
 	case domain.MCPServerTypeSSE:
 		if config.URL == "" {
 			return nil, fmt.Errorf("URL is required for SSE MCP server")
 		}
-		mcpClient, err = client.NewSSEMCPClient(config.URL)
+		mcpClient, err = client.NewSSEMCPClient(config.URL, transport.WithHeaders(httpHeaders(config)))
 		if err != nil {
 			return nil, fmt.Errorf("failed to create SSE MCP client: %w", err)
 		}
@@ -50,6 +61,19 @@ func NewMCPClient(config domain.MCPServerConfig) (*MCPClientWrapper, error) {
 	}, nil
 }
 
+// httpHeaders returns the configured headers plus the bearer token, with
+// environment variable references expanded so secrets can stay out of settings
+func httpHeaders(config domain.MCPServerConfig) map[string]string {
+	headers := make(map[string]string, len(config.Headers)+1)
+	for name, value := range config.Headers {
+		headers[name] = os.ExpandEnv(value)
+	}
+	if token := os.ExpandEnv(config.BearerToken); token != "" {
+		headers["Authorization"] = "Bearer " + token
+	}
+	return headers
+}
+
 // Start initializes the MCP client connection
 func (w *MCPClientWrapper) Start(ctx context.Context) error {
 	// Start the client connection
@@ -90,6 +114,11 @@ func (w *MCPClientWrapper) IsInitialized() bool {
 	return w.client.IsInitialized()
 }
 
+// Ping checks that the MCP server is still responding
+func (w *MCPClientWrapper) Ping(ctx context.Context) error {
+	return w.client.Ping(ctx)
+}
+
 // ListTools lists available tools from the MCP server
 func (w *MCPClientWrapper) ListTools(ctx context.Context, request mcpapi.ListToolsRequest) (*mcpapi.ListToolsResult, error) {
 	return w.client.ListTools(ctx, request)
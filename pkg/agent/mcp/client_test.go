@@ -0,0 +1,75 @@
+package mcp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fpt/go-gennai-cli/pkg/agent/domain"
+	mcpapi "github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func TestHTTPHeaders(t *testing.T) {
+	t.Setenv("TEST_MCP_TOKEN", "secret")
+	headers := httpHeaders(domain.MCPServerConfig{
+		BearerToken: "${TEST_MCP_TOKEN}",
+		Headers:     map[string]string{"X-Team": "core"},
+	})
+	if got := headers["Authorization"]; got != "Bearer secret" {
+		t.Errorf("Authorization = %q, want %q", got, "Bearer secret")
+	}
+	if got := headers["X-Team"]; got != "core" {
+		t.Errorf("X-Team = %q, want %q", got, "core")
+	}
+
+	if headers := httpHeaders(domain.MCPServerConfig{}); len(headers) != 0 {
+		t.Errorf("expected no headers without a token, got %v", headers)
+	}
+}
+
+func TestNewMCPClientStreamableHTTP(t *testing.T) {
+	mcpServer := server.NewMCPServer("test", "1.0.0", server.WithToolCapabilities(false))
+	mcpServer.AddTool(mcpapi.NewTool("echo", mcpapi.WithDescription("Echo")),
+		func(ctx context.Context, request mcpapi.CallToolRequest) (*mcpapi.CallToolResult, error) {
+			return mcpapi.NewToolResultText("ok"), nil
+		})
+	handler := server.NewStreamableHTTPServer(mcpServer)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	}))
+	defer ts.Close()
+
+	// Type is omitted: a URL implies the HTTP transport
+	client, err := NewMCPClient(domain.MCPServerConfig{Name: "remote", URL: ts.URL, BearerToken: "token"})
+	if err != nil {
+		t.Fatalf("NewMCPClient failed: %v", err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+	if err := client.Start(ctx); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	result, err := client.ListTools(ctx, mcpapi.ListToolsRequest{})
+	if err != nil {
+		t.Fatalf("ListTools failed: %v", err)
+	}
+	if len(result.Tools) != 1 || result.Tools[0].Name != "echo" {
+		t.Errorf("unexpected tools: %+v", result.Tools)
+	}
+
+	unauthorized, err := NewMCPClient(domain.MCPServerConfig{Name: "remote", Type: domain.MCPServerTypeHTTP, URL: ts.URL})
+	if err != nil {
+		t.Fatalf("NewMCPClient failed: %v", err)
+	}
+	defer unauthorized.Close()
+	if err := unauthorized.Start(ctx); err == nil {
+		t.Error("expected Start to fail without a bearer token")
+	}
+}
@@ -0,0 +1,231 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fpt/go-gennai-cli/pkg/agent/domain"
+	mcpapi "github.com/mark3labs/mcp-go/mcp"
+)
+
+// healthCheckTimeout bounds the ping sent after a failed request
+const healthCheckTimeout = 5 * time.Second
+
+// PoolOptions controls how many connections are kept to one MCP server and how
+// many requests may be in flight at once
+type PoolOptions struct {
+	Size          int // connections per server (0 = 1)
+	MaxConcurrent int // requests in flight per server (0 = unlimited)
+}
+
+// poolConn is one connection of a ClientPool
+type poolConn interface {
+	domain.MCPClient
+	Ping(ctx context.Context) error
+}
+
+// pooledConn is a pool slot; conn is nil until the slot is first used and
+// after a failed health check, so the next request reconnects
+type pooledConn struct {
+	mu   sync.Mutex
+	conn poolConn
+}
+
+// ClientPool implements domain.MCPClient over a set of reusable connections to
+// one MCP server. Requests are spread over the connections round-robin, capped
+// at MaxConcurrent, and a connection that stops answering is replaced on its
+// next use instead of failing the rest of the session.
+type ClientPool struct {
+	config domain.MCPServerConfig
+	dial   func(domain.MCPServerConfig) (poolConn, error)
+	slots  []*pooledConn
+	next   atomic.Uint64
+	sem    chan struct{} // nil when concurrency is unlimited
+}
+
+// NewClientPool creates a pool for the server. No connection is made until Start.
+func NewClientPool(config domain.MCPServerConfig, opts PoolOptions) *ClientPool {
+	return newClientPool(config, opts, func(config domain.MCPServerConfig) (poolConn, error) {
+		return NewMCPClient(config)
+	})
+}
+
+func newClientPool(config domain.MCPServerConfig, opts PoolOptions, dial func(domain.MCPServerConfig) (poolConn, error)) *ClientPool {
+	p := &ClientPool{
+		config: config,
+		dial:   dial,
+		slots:  make([]*pooledConn, max(opts.Size, 1)),
+	}
+	for i := range p.slots {
+		p.slots[i] = &pooledConn{}
+	}
+	if opts.MaxConcurrent > 0 {
+		p.sem = make(chan struct{}, opts.MaxConcurrent)
+	}
+	return p
+}
+
+// Start connects the first slot so configuration errors surface immediately;
+// the remaining connections are opened on first use
+func (p *ClientPool) Start(ctx context.Context) error {
+	_, err := p.slots[0].get(ctx, p)
+	return err
+}
+
+// get returns the slot's connection, connecting it if needed
+func (s *pooledConn) get(ctx context.Context, p *ClientPool) (poolConn, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn != nil && s.conn.IsInitialized() {
+		return s.conn, nil
+	}
+	if s.conn != nil {
+		_ = s.conn.Close()
+		s.conn = nil
+	}
+
+	conn, err := p.dial(p.config)
+	if err != nil {
+		return nil, err
+	}
+	if err := conn.Start(ctx); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	s.conn = conn
+	return conn, nil
+}
+
+// checkHealth pings the connection after a failed request and drops it when
+// the server no longer answers
+func (s *pooledConn) checkHealth(conn poolConn, serverName string) {
+	ctx, cancel := context.WithTimeout(context.Background(), healthCheckTimeout)
+	defer cancel()
+	if err := conn.Ping(ctx); err == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == conn {
+		_ = conn.Close()
+		s.conn = nil
+		logger.Warn("MCP server connection lost; reconnecting on next call", "server", serverName)
+	}
+}
+
+// do runs fn on the next connection, respecting the concurrency cap
+func (p *ClientPool) do(ctx context.Context, fn func(conn poolConn) error) error {
+	if p.sem != nil {
+		select {
+		case p.sem <- struct{}{}:
+			defer func() { <-p.sem }()
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	slot := p.slots[int(p.next.Add(1)-1)%len(p.slots)]
+	conn, err := slot.get(ctx, p)
+	if err != nil {
+		return fmt.Errorf("MCP server %s is unavailable: %w", p.config.Name, err)
+	}
+	err = fn(conn)
+	if err != nil && !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) {
+		slot.checkHealth(conn, p.config.Name)
+	}
+	return err
+}
+
+// Close closes every open connection
+func (p *ClientPool) Close() error {
+	var errs []error
+	for _, s := range p.slots {
+		s.mu.Lock()
+		if s.conn != nil {
+			errs = append(errs, s.conn.Close())
+			s.conn = nil
+		}
+		s.mu.Unlock()
+	}
+	return errors.Join(errs...)
+}
+
+// IsInitialized reports whether any connection is open
+func (p *ClientPool) IsInitialized() bool {
+	return p.firstConn() != nil
+}
+
+// firstConn returns an open connection, or nil when none is
+func (p *ClientPool) firstConn() poolConn {
+	for _, s := range p.slots {
+		s.mu.Lock()
+		conn := s.conn
+		s.mu.Unlock()
+		if conn != nil {
+			return conn
+		}
+	}
+	return nil
+}
+
+// ListTools lists available tools from the MCP server
+func (p *ClientPool) ListTools(ctx context.Context, request mcpapi.ListToolsRequest) (result *mcpapi.ListToolsResult, err error) {
+	err = p.do(ctx, func(conn poolConn) error {
+		result, err = conn.ListTools(ctx, request)
+		return err
+	})
+	return result, err
+}
+
+// CallTool calls a tool on the MCP server
+func (p *ClientPool) CallTool(ctx context.Context, request mcpapi.CallToolRequest) (result *mcpapi.CallToolResult, err error) {
+	err = p.do(ctx, func(conn poolConn) error {
+		result, err = conn.CallTool(ctx, request)
+		return err
+	})
+	return result, err
+}
+
+// ListResources lists available resources from the MCP server
+func (p *ClientPool) ListResources(ctx context.Context, request mcpapi.ListResourcesRequest) (result *mcpapi.ListResourcesResult, err error) {
+	err = p.do(ctx, func(conn poolConn) error {
+		result, err = conn.ListResources(ctx, request)
+		return err
+	})
+	return result, err
+}
+
+// ReadResource reads a resource from the MCP server
+func (p *ClientPool) ReadResource(ctx context.Context, request mcpapi.ReadResourceRequest) (result *mcpapi.ReadResourceResult, err error) {
+	err = p.do(ctx, func(conn poolConn) error {
+		result, err = conn.ReadResource(ctx, request)
+		return err
+	})
+	return result, err
+}
+
+// GetServerCapabilities returns the server capabilities
+func (p *ClientPool) GetServerCapabilities() mcpapi.ServerCapabilities {
+	if conn := p.firstConn(); conn != nil {
+		return conn.GetServerCapabilities()
+	}
+	return mcpapi.ServerCapabilities{}
+}
+
+// GetSessionId returns the session ID of the first open connection
+func (p *ClientPool) GetSessionId() string {
+	if conn := p.firstConn(); conn != nil {
+		return conn.GetSessionId()
+	}
+	return ""
+}
+
+// GetConfig returns the server configuration
+func (p *ClientPool) GetConfig() domain.MCPServerConfig {
+	return p.config
+}
@@ -0,0 +1,171 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fpt/go-gennai-cli/pkg/agent/domain"
+	mcpapi "github.com/mark3labs/mcp-go/mcp"
+)
+
+// fakeConn is a poolConn whose calls and health can be scripted
+type fakeConn struct {
+	id       int
+	callErr  error
+	pingErr  error
+	closed   atomic.Bool
+	delay    time.Duration
+	inFlight *atomic.Int32
+	peak     *atomic.Int32
+}
+
+func (c *fakeConn) Start(ctx context.Context) error { return nil }
+func (c *fakeConn) Close() error                    { c.closed.Store(true); return nil }
+func (c *fakeConn) IsInitialized() bool             { return !c.closed.Load() }
+func (c *fakeConn) Ping(ctx context.Context) error  { return c.pingErr }
+func (c *fakeConn) GetSessionId() string            { return "" }
+func (c *fakeConn) GetServerCapabilities() mcpapi.ServerCapabilities {
+	return mcpapi.ServerCapabilities{}
+}
+func (c *fakeConn) ListTools(ctx context.Context, request mcpapi.ListToolsRequest) (*mcpapi.ListToolsResult, error) {
+	return &mcpapi.ListToolsResult{}, nil
+}
+func (c *fakeConn) ListResources(ctx context.Context, request mcpapi.ListResourcesRequest) (*mcpapi.ListResourcesResult, error) {
+	return &mcpapi.ListResourcesResult{}, nil
+}
+func (c *fakeConn) ReadResource(ctx context.Context, request mcpapi.ReadResourceRequest) (*mcpapi.ReadResourceResult, error) {
+	return &mcpapi.ReadResourceResult{}, nil
+}
+
+func (c *fakeConn) CallTool(ctx context.Context, request mcpapi.CallToolRequest) (*mcpapi.CallToolResult, error) {
+	if c.inFlight != nil {
+		n := c.inFlight.Add(1)
+		defer c.inFlight.Add(-1)
+		for {
+			peak := c.peak.Load()
+			if n <= peak || c.peak.CompareAndSwap(peak, n) {
+				break
+			}
+		}
+	}
+	time.Sleep(c.delay)
+	if c.callErr != nil {
+		return nil, c.callErr
+	}
+	return mcpapi.NewToolResultText("ok"), nil
+}
+
+// fakeDialer records the connections a pool opens
+type fakeDialer struct {
+	mu    sync.Mutex
+	conns []*fakeConn
+	setup func(c *fakeConn)
+}
+
+func (d *fakeDialer) dial(domain.MCPServerConfig) (poolConn, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	c := &fakeConn{id: len(d.conns)}
+	if d.setup != nil {
+		d.setup(c)
+	}
+	d.conns = append(d.conns, c)
+	return c, nil
+}
+
+func (d *fakeDialer) count() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.conns)
+}
+
+func TestClientPool_OpensConnectionsLazilyAndReusesThem(t *testing.T) {
+	d := &fakeDialer{}
+	pool := newClientPool(domain.MCPServerConfig{Name: "test"}, PoolOptions{Size: 2}, d.dial)
+	ctx := context.Background()
+
+	if err := pool.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if d.count() != 1 {
+		t.Fatalf("Start opened %d connections, want 1", d.count())
+	}
+	for range 6 {
+		if _, err := pool.CallTool(ctx, mcpapi.CallToolRequest{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if d.count() != 2 {
+		t.Errorf("expected the pool to stop at 2 connections, opened %d", d.count())
+	}
+
+	if err := pool.Close(); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range d.conns {
+		if !c.closed.Load() {
+			t.Errorf("connection %d left open after Close", c.id)
+		}
+	}
+}
+
+func TestClientPool_CapsConcurrentCalls(t *testing.T) {
+	var inFlight, peak atomic.Int32
+	d := &fakeDialer{setup: func(c *fakeConn) {
+		c.delay, c.inFlight, c.peak = 20*time.Millisecond, &inFlight, &peak
+	}}
+	pool := newClientPool(domain.MCPServerConfig{Name: "test"}, PoolOptions{Size: 3, MaxConcurrent: 2}, d.dial)
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := pool.CallTool(context.Background(), mcpapi.CallToolRequest{}); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if got := peak.Load(); got != 2 {
+		t.Errorf("peak concurrent calls = %d, want 2", got)
+	}
+}
+
+func TestClientPool_ReconnectsAfterLostConnection(t *testing.T) {
+	d := &fakeDialer{}
+	pool := newClientPool(domain.MCPServerConfig{Name: "test"}, PoolOptions{}, d.dial)
+	ctx := context.Background()
+	if err := pool.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// A tool error from a healthy server keeps the connection
+	d.conns[0].callErr = errors.New("invalid params")
+	if _, err := pool.CallTool(ctx, mcpapi.CallToolRequest{}); err == nil {
+		t.Fatal("expected the call error to be returned")
+	}
+	if d.conns[0].closed.Load() {
+		t.Fatal("a healthy connection should be kept")
+	}
+
+	// A server that stops answering fails this call and is replaced on the next
+	d.conns[0].callErr = errors.New("broken pipe")
+	d.conns[0].pingErr = errors.New("broken pipe")
+	if _, err := pool.CallTool(ctx, mcpapi.CallToolRequest{}); err == nil {
+		t.Fatal("expected the call error to be returned")
+	}
+	if !d.conns[0].closed.Load() {
+		t.Fatal("expected the dead connection to be closed")
+	}
+	if _, err := pool.CallTool(ctx, mcpapi.CallToolRequest{}); err != nil {
+		t.Fatalf("expected the next call to reconnect, got %v", err)
+	}
+	if d.count() != 2 {
+		t.Errorf("expected one reconnection, opened %d connections", d.count())
+	}
+}
@@ -0,0 +1,88 @@
+package react
+
+import (
+	"fmt"
+
+	"github.com/fpt/go-gennai-cli/pkg/agent/domain"
+	pkgLogger "github.com/fpt/go-gennai-cli/pkg/logger"
+	"github.com/fpt/go-gennai-cli/pkg/message"
+)
+
+// SetApprovalPolicy sets which tool categories run freely, wait for approval
+// or are refused. nil uses domain.DefaultApprovalPolicy.
+func (r *ReAct) SetApprovalPolicy(policy domain.ApprovalPolicy) {
+	r.approvalPolicy = policy
+}
+
+// GetPendingApprovalCategories returns the categories of the calls waiting
+// for approval
+func (r *ReAct) GetPendingApprovalCategories() []string {
+	return r.pendingApproval
+}
+
+// approvalCategory returns the approval category of a call, or "" for calls
+// that never need approval
+func (r *ReAct) approvalCategory(call *message.ToolCallMessage) string {
+	name := call.ToolName()
+	switch name {
+	case "delete_file":
+		return domain.ApprovalCategoryDelete
+	case "bash":
+		if r.bashCommandRequiresApproval(call) {
+			return domain.ApprovalCategoryBash
+		}
+		return ""
+	case "replace_across_files":
+		// Batch replacements need approval unless they only preview
+		if dryRun, _ := call.ToolArguments()["dry_run"].(bool); dryRun {
+			return ""
+		}
+	}
+	tool, ok := r.toolManager.GetTools()[name]
+	if !ok {
+		return ""
+	}
+	if _, isMCP := tool.(*domain.MCPToolAdapter); isMCP {
+		return domain.ApprovalCategoryMCP
+	}
+	if message.EffectsOf(tool).Access == message.AccessWrite {
+		return domain.ApprovalCategoryWrite
+	}
+	return ""
+}
+
+// categoriesToApprove returns the categories of the calls in a response that
+// the policy wants approved first, or nil when it can run right away
+func (r *ReAct) categoriesToApprove(resp message.Message) []string {
+	var calls []*message.ToolCallMessage
+	switch resp := resp.(type) {
+	case *message.ToolCallMessage:
+		calls = []*message.ToolCallMessage{resp}
+	case *message.ToolCallBatchMessage:
+		calls = resp.Calls()
+	}
+
+	var categories []string
+	seen := make(map[string]bool)
+	for _, call := range calls {
+		category := r.approvalCategory(call)
+		if category != "" && !seen[category] && r.approvalPolicy.Mode(category) == domain.ApprovalAsk {
+			seen[category] = true
+			categories = append(categories, category)
+		}
+	}
+	return categories
+}
+
+// refuseByPolicy answers a call whose category the policy never allows, or
+// returns nil when the call may run
+func (r *ReAct) refuseByPolicy(call *message.ToolCallMessage) message.Message {
+	category := r.approvalCategory(call)
+	if category == "" || r.approvalPolicy.Mode(category) != domain.ApprovalNever {
+		return nil
+	}
+	reactLogger.InfoWithIntention(pkgLogger.IntentionWarning, "Tool call refused by approval policy", "tool", call.ToolName(), "category", category)
+	return message.NewToolResultMessage(call.ID(), "", fmt.Sprintf(
+		"Refused: the approval policy does not allow %s tool calls (approval_policy %q is \"never\"). Do not retry it; continue another way or tell the user what is needed.",
+		category, category))
+}
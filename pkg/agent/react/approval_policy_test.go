@@ -0,0 +1,126 @@
+package react
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/fpt/go-gennai-cli/pkg/agent/domain"
+	"github.com/fpt/go-gennai-cli/pkg/agent/state"
+	"github.com/fpt/go-gennai-cli/pkg/message"
+	mcpapi "github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestReAct_ApprovalPolicy(t *testing.T) {
+	mcpTool := domain.NewMCPToolAdapter(mcpapi.Tool{Name: "query_db"}, "db", nil)
+	writeArgs := []message.ToolArgument{
+		{Name: "file_path", Required: true, Type: "string"},
+		{Name: "content", Required: true, Type: "string"},
+	}
+	newReAct := func(policy domain.ApprovalPolicy, resp message.Message) (*ReAct, *[]message.ToolName) {
+		var called []message.ToolName
+		answered := false
+		llm := &mockLLM{chatFunc: func(ctx context.Context, messages []message.Message) (message.Message, error) {
+			if answered {
+				return message.NewChatMessage(message.MessageTypeAssistant, "done"), nil
+			}
+			answered = true
+			return resp, nil
+		}}
+		tools := &mockToolManager{
+			getToolsFunc: func() map[message.ToolName]message.Tool {
+				return map[message.ToolName]message.Tool{
+					"Write":       &schemaTool{name: "Write", args: writeArgs, effects: message.FileWrite},
+					"delete_file": &schemaTool{name: "delete_file", args: writeArgs[:1], effects: message.FileWrite},
+					"Read":        &schemaTool{name: "Read", args: writeArgs[:1], effects: message.CachedFileRead},
+					"query_db":    mcpTool,
+				}
+			},
+			callToolFunc: func(ctx context.Context, name message.ToolName, args message.ToolArgumentValues) (message.ToolResult, error) {
+				called = append(called, name)
+				return message.NewToolResultText("ok"), nil
+			},
+		}
+		react, _ := NewReAct(llm, tools, state.NewMessageState(), &mockAligner{}, 10)
+		react.SetApprovalPolicy(policy)
+		return react, &called
+	}
+	write := func() *message.ToolCallMessage {
+		return message.NewToolCallMessage("Write", message.ToolArgumentValues{"file_path": "a.go", "content": "x"})
+	}
+
+	t.Run("ask waits for approval", func(t *testing.T) {
+		react, called := newReAct(nil, write())
+		_, err := react.Run(context.Background(), "write a.go")
+		if !errors.Is(err, ErrWaitingForApproval) {
+			t.Fatalf("expected to wait for approval, got %v", err)
+		}
+		if got := react.GetPendingApprovalCategories(); !slices.Equal(got, []string{domain.ApprovalCategoryWrite}) {
+			t.Errorf("expected pending categories [write], got %v", got)
+		}
+		if result, err := react.Resume(context.Background()); err != nil || result.Content() != "done" {
+			t.Fatalf("expected the approved run to finish, got %v %v", result, err)
+		}
+		if !slices.Equal(*called, []message.ToolName{"Write"}) || react.GetPendingApprovalCategories() != nil {
+			t.Errorf("expected Write to run once approved, got %v", *called)
+		}
+	})
+
+	t.Run("always runs without asking", func(t *testing.T) {
+		react, called := newReAct(domain.ApprovalPolicy{domain.ApprovalCategoryWrite: domain.ApprovalAlways}, write())
+		if _, err := react.Run(context.Background(), "write a.go"); err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(*called, []message.ToolName{"Write"}) {
+			t.Errorf("expected Write to run, got %v", *called)
+		}
+	})
+
+	t.Run("never refuses", func(t *testing.T) {
+		react, called := newReAct(domain.ApprovalPolicy{domain.ApprovalCategoryDelete: domain.ApprovalNever},
+			message.NewToolCallMessage("delete_file", message.ToolArgumentValues{"file_path": "a.go"}))
+		if _, err := react.Run(context.Background(), "delete a.go"); err != nil {
+			t.Fatal(err)
+		}
+		if len(*called) != 0 {
+			t.Errorf("expected delete_file not to run, got %v", *called)
+		}
+		refused := false
+		for _, msg := range react.state.GetMessages() {
+			if msg.Type() == message.MessageTypeToolResult && strings.Contains(msg.Content(), "does not allow delete tool calls") {
+				refused = true
+			}
+		}
+		if !refused {
+			t.Error("expected a refusal result in the history")
+		}
+	})
+
+	t.Run("batch asks for each category", func(t *testing.T) {
+		batch := message.NewToolCallBatch([]*message.ToolCallMessage{
+			message.NewToolCallMessage("Read", message.ToolArgumentValues{"file_path": "a.go"}),
+			message.NewToolCallMessage("bash", message.ToolArgumentValues{"command": "rm -rf build"}),
+			message.NewToolCallMessage("bash", message.ToolArgumentValues{"command": "go test ./..."}),
+			message.NewToolCallMessage("query_db", message.ToolArgumentValues{}),
+		})
+		react, called := newReAct(domain.ApprovalPolicy{domain.ApprovalCategoryMCP: domain.ApprovalAsk}, batch)
+		if _, err := react.Run(context.Background(), "clean up"); !errors.Is(err, ErrWaitingForApproval) {
+			t.Fatalf("expected to wait for approval, got %v", err)
+		}
+		if got := react.GetPendingApprovalCategories(); !slices.Equal(got, []string{domain.ApprovalCategoryBash, domain.ApprovalCategoryMCP}) {
+			t.Errorf("expected pending categories [bash mcp], got %v", got)
+		}
+		if len(*called) != 0 {
+			t.Errorf("expected nothing to run before approval, got %v", *called)
+		}
+
+		// Declining answers every call of the batch
+		react.CancelPendingToolCall()
+		messages := react.state.GetMessages()
+		if len(messages) != 9 || !strings.Contains(messages[8].Content(), "cancelled by user") {
+			t.Errorf("expected each call paired with a declined result, got %d messages", len(messages))
+		}
+	})
+}
@@ -0,0 +1,150 @@
+package react
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/fpt/go-gennai-cli/pkg/message"
+)
+
+// maxInvalidArgumentRetries is how many consecutive tool calls with invalid
+// arguments are answered with validation feedback before the run is stopped
+const maxInvalidArgumentRetries = 3
+
+// validateToolArguments checks args against the tool's declared arguments and
+// returns one line per problem (missing required or wrong-typed arguments)
+func validateToolArguments(tool message.Tool, args message.ToolArgumentValues) []string {
+	var problems []string
+	declared := make(map[string]bool)
+	for _, arg := range tool.Arguments() {
+		name := string(arg.Name)
+		declared[name] = true
+		value, ok := args[name]
+		if !ok || value == nil {
+			if arg.Required {
+				problems = append(problems, fmt.Sprintf("missing required argument %q (%s): %s", name, arg.Type, arg.Description))
+			}
+			continue
+		}
+		if !argumentHasType(value, arg.Type) {
+			problems = append(problems, fmt.Sprintf("argument %q must be %s, got %s", name, arg.Type, message.JSONTypeName(value)))
+		}
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+
+	// Undeclared arguments are harmless on their own but often explain a missing
+	// one (e.g. "path" instead of "file_path")
+	var unknown []string
+	for name := range args {
+		if !declared[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	for _, name := range unknown {
+		if v, ok := args[name].(string); ok && strings.HasPrefix(strings.TrimSpace(v), "{") {
+			problems = append(problems, fmt.Sprintf("unexpected argument %q holding a JSON string: pass the arguments as a JSON object, not encoded in a string", name))
+			continue
+		}
+		problems = append(problems, fmt.Sprintf("unexpected argument %q (not accepted by this tool)", name))
+	}
+	return problems
+}
+
+// expandWrappedArguments handles models that send all arguments as one
+// JSON-encoded string under an undeclared key ({"arguments": "{\"file_path\": ...}"}).
+// When the string decodes to an object, args is replaced by that object in
+// place so the recorded call shows the corrected arguments. A declared string
+// argument holding JSON (such as the content of a JSON file) is left alone.
+func expandWrappedArguments(tool message.Tool, args message.ToolArgumentValues) bool {
+	if len(args) != 1 {
+		return false
+	}
+	var key string
+	var value any
+	for key, value = range args {
+	}
+	for _, arg := range tool.Arguments() {
+		if string(arg.Name) == key {
+			return false
+		}
+	}
+	encoded, ok := value.(string)
+	if !ok || !strings.HasPrefix(strings.TrimSpace(encoded), "{") {
+		return false
+	}
+	expanded, err := message.ParseToolArguments(encoded)
+	if err != nil {
+		return false
+	}
+	delete(args, key)
+	for name, v := range expanded {
+		args[name] = v
+	}
+	return true
+}
+
+// argumentHasType reports whether value matches a JSON schema type. Arrays
+// and objects may also arrive as JSON-encoded strings.
+func argumentHasType(value any, schemaType string) bool {
+	if message.MatchesJSONType(value, schemaType) {
+		return true
+	}
+	encoded, ok := value.(string)
+	if !ok {
+		return false
+	}
+	switch schemaType {
+	case "array":
+		var decoded []any
+		return json.Unmarshal([]byte(encoded), &decoded) == nil
+	case "object":
+		var decoded map[string]any
+		return json.Unmarshal([]byte(encoded), &decoded) == nil
+	}
+	return false
+}
+
+// formatArgumentErrors builds the tool result shown to the model for invalid arguments
+func formatArgumentErrors(name message.ToolName, tool message.Tool, problems []string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Invalid arguments for tool %s:\n", name)
+	for _, p := range problems {
+		fmt.Fprintf(&b, "- %s\n", p)
+	}
+	var expected []string
+	for _, arg := range tool.Arguments() {
+		spec := fmt.Sprintf("%s (%s", arg.Name, arg.Type)
+		if arg.Required {
+			spec += ", required"
+		}
+		expected = append(expected, spec+")")
+	}
+	if len(expected) > 0 {
+		fmt.Fprintf(&b, "Expected arguments: %s\n", strings.Join(expected, ", "))
+	}
+	b.WriteString("The tool was not run. Call it again with corrected arguments.")
+	return b.String()
+}
+
+// recordArgumentValidation updates the streak of consecutive invalid tool calls
+func (r *ReAct) recordArgumentValidation(valid bool) {
+	r.argMu.Lock()
+	defer r.argMu.Unlock()
+	if valid {
+		r.invalidArgStreak = 0
+	} else {
+		r.invalidArgStreak++
+	}
+}
+
+// tooManyInvalidArguments reports whether the model keeps sending invalid arguments
+func (r *ReAct) tooManyInvalidArguments() bool {
+	r.argMu.Lock()
+	defer r.argMu.Unlock()
+	return r.invalidArgStreak >= maxInvalidArgumentRetries
+}
@@ -0,0 +1,196 @@
+package react
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/fpt/go-gennai-cli/pkg/agent/state"
+	"github.com/fpt/go-gennai-cli/pkg/message"
+)
+
+// schemaTool is a tool with declared arguments for validation tests
+type schemaTool struct {
+	name    message.ToolName
+	args    []message.ToolArgument
+	effects message.ToolEffects
+}
+
+func (t *schemaTool) RawName() message.ToolName            { return t.name }
+func (t *schemaTool) Name() message.ToolName               { return t.name }
+func (t *schemaTool) Description() message.ToolDescription { return "test tool" }
+func (t *schemaTool) Arguments() []message.ToolArgument    { return t.args }
+func (t *schemaTool) Handler() func(ctx context.Context, args message.ToolArgumentValues) (message.ToolResult, error) {
+	return nil
+}
+func (t *schemaTool) Effects() message.ToolEffects { return t.effects }
+
+var readTool = &schemaTool{name: "Read", args: []message.ToolArgument{
+	{Name: "file_path", Description: "Absolute path to the file", Required: true, Type: "string"},
+	{Name: "limit", Description: "Lines to read", Type: "number"},
+	{Name: "edits", Description: "Edits to apply", Type: "array"},
+}}
+
+func TestValidateToolArguments(t *testing.T) {
+	tests := []struct {
+		name string
+		args message.ToolArgumentValues
+		want []string
+	}{
+		{"valid", message.ToolArgumentValues{"file_path": "/a.go", "limit": float64(10)}, nil},
+		{"optional omitted", message.ToolArgumentValues{"file_path": "/a.go"}, nil},
+		{"array as JSON string", message.ToolArgumentValues{"file_path": "/a.go", "edits": `[{"old":"a"}]`}, nil},
+		{
+			"missing required with misnamed argument",
+			message.ToolArgumentValues{"path": "/a.go"},
+			[]string{`missing required argument "file_path" (string)`, `unexpected argument "path"`},
+		},
+		{
+			"wrong type",
+			message.ToolArgumentValues{"file_path": "/a.go", "limit": "ten"},
+			[]string{`argument "limit" must be number, got string`},
+		},
+		{
+			"array not JSON",
+			message.ToolArgumentValues{"file_path": "/a.go", "edits": "replace a with b"},
+			[]string{`argument "edits" must be array, got string`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problems := validateToolArguments(readTool, tt.args)
+			if len(problems) != len(tt.want) {
+				t.Fatalf("expected %d problems, got %q", len(tt.want), problems)
+			}
+			for i, want := range tt.want {
+				if !strings.Contains(problems[i], want) {
+					t.Errorf("problem %d: expected %q in %q", i, want, problems[i])
+				}
+			}
+		})
+	}
+}
+
+func TestReAct_InvalidArgumentsFeedbackAndCap(t *testing.T) {
+	mockLLM := &mockLLM{}
+	mockLLM.chatFunc = func(ctx context.Context, messages []message.Message) (message.Message, error) {
+		return message.NewToolCallMessage("Read", message.ToolArgumentValues{"path": "/a.go"}), nil
+	}
+	handlerCalls := 0
+	mockToolManager := &mockToolManager{
+		getToolsFunc: func() map[message.ToolName]message.Tool {
+			return map[message.ToolName]message.Tool{"Read": readTool}
+		},
+		callToolFunc: func(ctx context.Context, name message.ToolName, args message.ToolArgumentValues) (message.ToolResult, error) {
+			handlerCalls++
+			return message.NewToolResultText("contents"), nil
+		},
+	}
+
+	react, _ := NewReAct(mockLLM, mockToolManager, state.NewMessageState(), &mockAligner{}, 10)
+	_, err := react.Run(context.Background(), "Read a.go")
+	if err == nil || !strings.Contains(err.Error(), "consecutive tool calls with invalid arguments") {
+		t.Fatalf("expected the run to stop after repeated invalid calls, got %v", err)
+	}
+	if handlerCalls != 0 {
+		t.Errorf("tool handler should not run with invalid arguments, ran %d times", handlerCalls)
+	}
+
+	results := 0
+	for _, msg := range react.state.GetMessages() {
+		if msg.Type() == message.MessageTypeToolResult {
+			results++
+			if !strings.Contains(msg.Content(), `missing required argument "file_path"`) {
+				t.Errorf("expected actionable feedback, got %q", msg.Content())
+			}
+		}
+	}
+	if results != maxInvalidArgumentRetries {
+		t.Errorf("expected %d feedback results, got %d", maxInvalidArgumentRetries, results)
+	}
+}
+
+func TestExpandWrappedArguments(t *testing.T) {
+	writeTool := &schemaTool{name: "Write", args: []message.ToolArgument{
+		{Name: "file_path", Required: true, Type: "string"},
+		{Name: "content", Required: true, Type: "string"},
+	}}
+	tests := []struct {
+		name     string
+		tool     message.Tool
+		args     message.ToolArgumentValues
+		expanded bool
+		want     message.ToolArgumentValues
+	}{
+		{
+			name: "object",
+			tool: readTool,
+			args: message.ToolArgumentValues{"file_path": "/a.go", "limit": float64(10)},
+			want: message.ToolArgumentValues{"file_path": "/a.go", "limit": float64(10)},
+		},
+		{
+			name:     "object as a JSON string",
+			tool:     readTool,
+			args:     message.ToolArgumentValues{"arguments": `{"file_path": "/a.go", "limit": 10}`},
+			expanded: true,
+			want:     message.ToolArgumentValues{"file_path": "/a.go", "limit": float64(10)},
+		},
+		{
+			name: "declared argument holding JSON",
+			tool: writeTool,
+			args: message.ToolArgumentValues{"content": `{"name": "app"}`},
+			want: message.ToolArgumentValues{"content": `{"name": "app"}`},
+		},
+		{
+			name: "string that is not JSON",
+			tool: readTool,
+			args: message.ToolArgumentValues{"arguments": "{file_path: /a.go}"},
+			want: message.ToolArgumentValues{"arguments": "{file_path: /a.go}"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := expandWrappedArguments(tt.tool, tt.args); got != tt.expanded {
+				t.Errorf("expandWrappedArguments = %v, want %v", got, tt.expanded)
+			}
+			if len(tt.args) != len(tt.want) {
+				t.Fatalf("args = %v, want %v", tt.args, tt.want)
+			}
+			for k, v := range tt.want {
+				if tt.args[k] != v {
+					t.Errorf("args[%q] = %v, want %v", k, tt.args[k], v)
+				}
+			}
+		})
+	}
+}
+
+func TestReAct_RunsToolWithArgumentsSentAsJSONString(t *testing.T) {
+	mockLLM := &mockLLM{}
+	calls := 0
+	mockLLM.chatFunc = func(ctx context.Context, messages []message.Message) (message.Message, error) {
+		calls++
+		if calls == 1 {
+			return message.NewToolCallMessage("Read", message.ToolArgumentValues{"arguments": `{"file_path": "/a.go"}`}), nil
+		}
+		return message.NewChatMessage(message.MessageTypeAssistant, "done"), nil
+	}
+	var received message.ToolArgumentValues
+	mockToolManager := &mockToolManager{
+		getToolsFunc: func() map[message.ToolName]message.Tool {
+			return map[message.ToolName]message.Tool{"Read": readTool}
+		},
+		callToolFunc: func(ctx context.Context, name message.ToolName, args message.ToolArgumentValues) (message.ToolResult, error) {
+			received = args
+			return message.NewToolResultText("package main"), nil
+		},
+	}
+
+	react, _ := NewReAct(mockLLM, mockToolManager, state.NewMessageState(), &mockAligner{}, 10)
+	if _, err := react.Run(context.Background(), "Read a.go"); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if received["file_path"] != "/a.go" {
+		t.Errorf("expected the tool to receive the decoded arguments, got %v", received)
+	}
+}
@@ -0,0 +1,100 @@
+package react
+
+import (
+	"fmt"
+
+	"github.com/fpt/go-gennai-cli/pkg/agent/events"
+	"github.com/fpt/go-gennai-cli/pkg/message"
+)
+
+// TokenBudget limits the tokens a run may consume and prices them for reporting.
+// The zero value is unlimited and reports nothing.
+type TokenBudget struct {
+	MaxTotalTokens  int     // abort the run once this many tokens are used (0 = unlimited)
+	InputCostPer1K  float64 // price per 1,000 input tokens, for cost estimates
+	OutputCostPer1K float64 // price per 1,000 output tokens, for cost estimates
+}
+
+// enabled reports whether usage should be tracked and reported
+func (b TokenBudget) enabled() bool {
+	return b.MaxTotalTokens > 0 || b.InputCostPer1K > 0 || b.OutputCostPer1K > 0
+}
+
+// cost estimates the price of the given usage
+func (b TokenBudget) cost(usage message.TokenUsage) float64 {
+	return float64(usage.InputTokens)/1000*b.InputCostPer1K + float64(usage.OutputTokens)/1000*b.OutputCostPer1K
+}
+
+// SetTokenBudget configures the token budget for subsequent runs
+func (r *ReAct) SetTokenBudget(b TokenBudget) {
+	r.budget = b
+}
+
+// TotalUsage returns the tokens consumed by this ReAct instance so far
+func (r *ReAct) TotalUsage() message.TokenUsage {
+	return r.totalUsage
+}
+
+// addUsage accumulates usage from one LLM call and reports the running total
+func (r *ReAct) addUsage(usage message.TokenUsage) {
+	total := usage.TotalTokens
+	if total == 0 {
+		total = usage.InputTokens + usage.OutputTokens
+	}
+	r.totalUsage.InputTokens += usage.InputTokens
+	r.totalUsage.OutputTokens += usage.OutputTokens
+	r.totalUsage.TotalTokens += total
+
+	if !r.budget.enabled() {
+		return
+	}
+	r.eventEmitter.EmitEvent(events.EventTypeUsage, events.UsageData{
+		InputTokens:    r.totalUsage.InputTokens,
+		OutputTokens:   r.totalUsage.OutputTokens,
+		TotalTokens:    r.totalUsage.TotalTokens,
+		EstimatedCost:  r.budget.cost(r.totalUsage),
+		MaxTotalTokens: r.budget.MaxTotalTokens,
+	})
+}
+
+// budgetExceeded reports whether the configured token budget has been used up
+func (r *ReAct) budgetExceeded() bool {
+	return r.budget.MaxTotalTokens > 0 && r.totalUsage.TotalTokens >= r.budget.MaxTotalTokens
+}
+
+// stopForBudget ends the run with the most recent assistant output, if any.
+// Tool calls in resp are not executed or recorded, so the history holds no
+// unanswered calls.
+func (r *ReAct) stopForBudget(resp message.Message) message.Message {
+	partial := ""
+	if chat, ok := resp.(*message.ChatMessage); ok {
+		partial = chat.Content()
+	} else {
+		partial = r.latestAssistantText()
+	}
+
+	notice := fmt.Sprintf("Stopped: token budget exhausted (%d of %d tokens used", r.totalUsage.TotalTokens, r.budget.MaxTotalTokens)
+	if cost := r.budget.cost(r.totalUsage); cost > 0 {
+		notice += fmt.Sprintf(", estimated cost $%.4f", cost)
+	}
+	notice += "). Raise agent.max_total_tokens to allow longer runs."
+	if partial != "" {
+		notice += "\n\nPartial result:\n" + partial
+	}
+
+	result := message.NewChatMessage(message.MessageTypeAssistant, notice)
+	r.state.AddMessage(result)
+	return result
+}
+
+// latestAssistantText returns the most recent assistant or reasoning text
+// produced since the user's request, or "" when there is none
+func (r *ReAct) latestAssistantText() string {
+	messages := r.state.GetMessages()
+	for i := len(messages) - 1; i >= 0 && messages[i].Type() != message.MessageTypeUser; i-- {
+		if t := messages[i].Type(); (t == message.MessageTypeAssistant || t == message.MessageTypeReasoning) && messages[i].Content() != "" {
+			return messages[i].Content()
+		}
+	}
+	return ""
+}
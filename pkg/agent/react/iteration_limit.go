@@ -0,0 +1,56 @@
+package react
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/fpt/go-gennai-cli/pkg/agent/domain"
+	pkgLogger "github.com/fpt/go-gennai-cli/pkg/logger"
+	"github.com/fpt/go-gennai-cli/pkg/message"
+	"github.com/pkg/errors"
+)
+
+// IterationLimitHandler is consulted when a run reaches its iteration limit.
+// It returns how many more iterations to allow; 0 ends the run with the
+// partial result.
+type IterationLimitHandler func(ctx context.Context, limit int) int
+
+// SetIterationLimitHandler sets the handler asked whether to continue past
+// the iteration limit (e.g. by prompting the user). nil always stops.
+func (r *ReAct) SetIterationLimitHandler(h IterationLimitHandler) {
+	r.iterLimitHandler = h
+}
+
+// extendIterations asks the handler for more iterations and raises the limit
+// accordingly, returning how many were added
+func (r *ReAct) extendIterations(ctx context.Context) int {
+	if r.iterLimitHandler == nil || ctx.Err() != nil {
+		return 0
+	}
+	extra := r.iterLimitHandler(ctx, r.maxIterations)
+	if extra <= 0 {
+		return 0
+	}
+	r.maxIterations += extra
+	reactLogger.InfoWithIntention(pkgLogger.IntentionStatus, "Iteration limit extended",
+		"added", extra, "max_iterations", r.maxIterations)
+	return extra
+}
+
+// stopForIterationLimit ends the run with the most recent assistant output, if
+// any, instead of failing. The last response's tool calls were already
+// answered, so the history stays valid.
+func (r *ReAct) stopForIterationLimit() message.Message {
+	reactLogger.WarnWithIntention(pkgLogger.IntentionWarning, "Iteration limit reached, returning partial result",
+		"max_iterations", r.maxIterations)
+
+	notice := fmt.Sprintf("Stopped: reached the iteration limit (%d) before finishing. Raise agent.max_iterations (or the scenario's max_iterations) to allow longer runs.", r.maxIterations)
+	if partial := r.latestAssistantText(); partial != "" {
+		notice += "\n\nPartial result:\n" + partial
+	}
+
+	r.stopReason = errors.Wrapf(domain.ErrMaxIterations, "stopped after %d iterations", r.maxIterations)
+	result := message.NewChatMessage(message.MessageTypeAssistant, notice)
+	r.state.AddMessage(result)
+	return result
+}
@@ -0,0 +1,129 @@
+package react
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxLatencySummaryTools is the number of slowest tools named in Summary
+const maxLatencySummaryTools = 5
+
+// Latency is the wall-clock time spent waiting for the LLM and running tools
+type Latency struct {
+	LLMCalls  int
+	LLMTime   time.Duration
+	ToolCalls int
+	ToolTime  time.Duration
+	ByTool    map[string]ToolLatency
+}
+
+// ToolLatency is the time spent in one tool
+type ToolLatency struct {
+	Calls int
+	Time  time.Duration
+}
+
+// Add accumulates other into l
+func (l *Latency) Add(other Latency) {
+	l.LLMCalls += other.LLMCalls
+	l.LLMTime += other.LLMTime
+	l.ToolCalls += other.ToolCalls
+	l.ToolTime += other.ToolTime
+	for name, t := range other.ByTool {
+		l.addTool(name, t.Calls, t.Time)
+	}
+}
+
+func (l *Latency) addTool(name string, calls int, d time.Duration) {
+	if l.ByTool == nil {
+		l.ByTool = make(map[string]ToolLatency)
+	}
+	t := l.ByTool[name]
+	t.Calls += calls
+	t.Time += d
+	l.ByTool[name] = t
+}
+
+// Summary describes where the time went, naming the slowest tools, e.g.
+// "LLM 8.2s (3 calls), tools 2.1s (4 calls: bash 1.9s, Read 0.2s)"
+func (l Latency) Summary() string {
+	summary := fmt.Sprintf("LLM %s (%d calls)", roundLatency(l.LLMTime), l.LLMCalls)
+	if l.ToolCalls == 0 {
+		return summary + ", no tool calls"
+	}
+
+	names := make([]string, 0, len(l.ByTool))
+	for name := range l.ByTool {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if l.ByTool[names[i]].Time != l.ByTool[names[j]].Time {
+			return l.ByTool[names[i]].Time > l.ByTool[names[j]].Time
+		}
+		return names[i] < names[j]
+	})
+	var perTool []string
+	for i, name := range names {
+		if i == maxLatencySummaryTools {
+			perTool = append(perTool, fmt.Sprintf("%d more", len(names)-i))
+			break
+		}
+		perTool = append(perTool, fmt.Sprintf("%s %s", name, roundLatency(l.ByTool[name].Time)))
+	}
+	return fmt.Sprintf("%s, tools %s (%d calls: %s)", summary, roundLatency(l.ToolTime), l.ToolCalls, strings.Join(perTool, ", "))
+}
+
+// roundLatency keeps durations readable: milliseconds below a second, tenths above
+func roundLatency(d time.Duration) time.Duration {
+	if d < time.Second {
+		return d.Round(time.Millisecond)
+	}
+	return d.Round(100 * time.Millisecond)
+}
+
+// toolTiming is the measured duration of one tool call, kept until its result is emitted
+type toolTiming struct {
+	name     string
+	duration time.Duration
+}
+
+// Latency returns the time spent in LLM calls and tool calls by this ReAct instance
+func (r *ReAct) Latency() Latency {
+	r.latencyMu.Lock()
+	defer r.latencyMu.Unlock()
+	var l Latency
+	l.Add(r.latency)
+	return l
+}
+
+// recordLLMLatency adds the duration of one LLM call
+func (r *ReAct) recordLLMLatency(d time.Duration) {
+	r.latencyMu.Lock()
+	defer r.latencyMu.Unlock()
+	r.latency.LLMCalls++
+	r.latency.LLMTime += d
+}
+
+// recordToolLatency adds the duration of one tool call; tools may run concurrently
+func (r *ReAct) recordToolLatency(callID, name string, d time.Duration) {
+	r.latencyMu.Lock()
+	defer r.latencyMu.Unlock()
+	r.latency.ToolCalls++
+	r.latency.ToolTime += d
+	r.latency.addTool(name, 1, d)
+	if r.toolTimings == nil {
+		r.toolTimings = make(map[string]toolTiming)
+	}
+	r.toolTimings[callID] = toolTiming{name: name, duration: d}
+}
+
+// takeToolTiming returns and forgets the timing of a call, if it was executed
+func (r *ReAct) takeToolTiming(callID string) (toolTiming, bool) {
+	r.latencyMu.Lock()
+	defer r.latencyMu.Unlock()
+	t, ok := r.toolTimings[callID]
+	delete(r.toolTimings, callID)
+	return t, ok
+}
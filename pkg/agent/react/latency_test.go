@@ -0,0 +1,69 @@
+package react
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fpt/go-gennai-cli/pkg/agent/events"
+	"github.com/fpt/go-gennai-cli/pkg/agent/state"
+	"github.com/fpt/go-gennai-cli/pkg/message"
+)
+
+func TestReAct_RecordsLatency(t *testing.T) {
+	calls := 0
+	llm := &mockLLM{chatFunc: func(ctx context.Context, messages []message.Message) (message.Message, error) {
+		calls++
+		time.Sleep(5 * time.Millisecond)
+		if calls == 1 {
+			return message.NewToolCallMessage("slow_tool", message.ToolArgumentValues{}), nil
+		}
+		return message.NewChatMessage(message.MessageTypeAssistant, "done"), nil
+	}}
+	tools := &mockToolManager{callToolFunc: func(ctx context.Context, name message.ToolName, args message.ToolArgumentValues) (message.ToolResult, error) {
+		time.Sleep(20 * time.Millisecond)
+		return message.NewToolResultText("ok"), nil
+	}}
+
+	react, emitter := NewReAct(llm, tools, state.NewMessageState(), &mockAligner{}, 10)
+	var result events.ToolResultData
+	emitter.AddHandler(func(event events.AgentEvent) {
+		if data, ok := event.Data.(events.ToolResultData); ok {
+			result = data
+		}
+	})
+	if _, err := react.Run(context.Background(), "go"); err != nil {
+		t.Fatal(err)
+	}
+
+	latency := react.Latency()
+	if latency.LLMCalls != 2 || latency.LLMTime < 10*time.Millisecond {
+		t.Errorf("LLM latency = %d calls / %v, want 2 calls / at least 10ms", latency.LLMCalls, latency.LLMTime)
+	}
+	if latency.ToolCalls != 1 || latency.ByTool["slow_tool"].Time < 20*time.Millisecond {
+		t.Errorf("tool latency = %+v, want one slow_tool call of at least 20ms", latency)
+	}
+	if result.ToolName != "slow_tool" || result.Duration < 20*time.Millisecond {
+		t.Errorf("tool result event = %+v, want slow_tool with its duration", result)
+	}
+}
+
+func TestLatencySummary(t *testing.T) {
+	var l Latency
+	l.Add(Latency{LLMCalls: 2, LLMTime: 3 * time.Second})
+	if got, want := l.Summary(), "LLM 3s (2 calls), no tool calls"; got != want {
+		t.Errorf("Summary() = %q, want %q", got, want)
+	}
+
+	l.Add(Latency{
+		LLMCalls: 1, LLMTime: 1234 * time.Millisecond,
+		ToolCalls: 3, ToolTime: 2500 * time.Millisecond,
+		ByTool: map[string]ToolLatency{
+			"bash": {Calls: 1, Time: 2400 * time.Millisecond},
+			"Read": {Calls: 2, Time: 100 * time.Millisecond},
+		},
+	})
+	if got, want := l.Summary(), "LLM 4.2s (3 calls), tools 2.5s (3 calls: bash 2.4s, Read 100ms)"; got != want {
+		t.Errorf("Summary() = %q, want %q", got, want)
+	}
+}
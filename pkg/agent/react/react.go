@@ -3,13 +3,16 @@ package react
 import (
 	"context"
 	"fmt"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/fpt/go-gennai-cli/pkg/agent/domain"
 	"github.com/fpt/go-gennai-cli/pkg/agent/events"
 	pkgLogger "github.com/fpt/go-gennai-cli/pkg/logger"
 	"github.com/fpt/go-gennai-cli/pkg/message"
+	"github.com/fpt/go-gennai-cli/pkg/secret"
 	"github.com/pkg/errors"
 )
 
@@ -32,6 +35,27 @@ type ReAct struct {
 	status           domain.AgentStatus
 	currentIteration int // current iteration count
 	pendingToolCall  message.Message
+	truncation       message.TruncationConfig // head/tail truncation for emitted tool results
+	toolConcurrency  int                      // max concurrent read-only calls within a batch (1 = sequential)
+	budget           TokenBudget              // token limit and pricing (zero = unlimited)
+	totalUsage       message.TokenUsage       // tokens consumed across all LLM calls
+	argMu            sync.Mutex               // guards invalidArgStreak during concurrent batches
+	invalidArgStreak int                      // consecutive tool calls rejected for invalid arguments
+	repeatMu         sync.Mutex               // guards repeats and loopWarning during concurrent batches
+	repeats          repetitionTracker        // consecutive identical tool calls
+	maxRepeatedCalls int                      // identical calls in a row that are executed
+	loopWarning      string                   // note for the next LLM call after a skipped repeat
+	latencyMu        sync.Mutex               // guards latency and toolTimings during concurrent batches
+	latency          Latency                  // time spent in LLM and tool calls
+	toolTimings      map[string]toolTiming    // durations of executed calls whose results are not yet emitted
+	noThinking       bool                     // ask the LLM not to use extended thinking
+	scrubber         *secret.Scrubber         // redacts secrets from tool results (nil = off)
+	iterLimitHandler IterationLimitHandler    // asked to extend the run at the iteration limit (nil = stop)
+	approvalPolicy   domain.ApprovalPolicy    // which tool categories wait for approval or are refused (nil = default)
+	pendingApproval  []string                 // approval categories of pendingToolCall
+	stopReason       error                    // why the run ended with a partial result (nil = finished)
+	maxResultChars   int                      // characters of a tool result kept in state (0 = unlimited)
+	runStart         time.Time                // when the current run began, for the time limit notice
 }
 
 // Ensure ReAct implements domain.ReAct interface
@@ -43,16 +67,46 @@ var reactLogger = pkgLogger.NewComponentLogger("react")
 func NewReAct(llmClient domain.LLM, toolManager domain.ToolManager, sharedState domain.State, aligner domain.Aligner, maxIterations int) (*ReAct, events.EventEmitter) {
 	eventEmitter := events.NewSimpleEventEmitter()
 	reactClient := &ReAct{
-		llmClient:     llmClient,
-		toolManager:   toolManager,
-		state:         sharedState,
-		aligner:       aligner,
-		maxIterations: maxIterations,
-		eventEmitter:  eventEmitter,
+		llmClient:        llmClient,
+		toolManager:      toolManager,
+		state:            sharedState,
+		aligner:          aligner,
+		maxIterations:    maxIterations,
+		eventEmitter:     eventEmitter,
+		truncation:       message.DefaultTruncationConfig(),
+		toolConcurrency:  1,
+		maxRepeatedCalls: DefaultMaxRepeatedToolCalls,
 	}
 	return reactClient, eventEmitter
 }
 
+// SetToolResultTruncation configures how large tool results are truncated in emitted events.
+// The result kept in the conversation state is only capped by SetMaxToolResultChars.
+func (r *ReAct) SetToolResultTruncation(cfg message.TruncationConfig) {
+	r.truncation = cfg
+}
+
+// SetToolConcurrency sets how many read-only tool calls in a batch may run at once.
+// Values below 1 run every call sequentially.
+func (r *ReAct) SetToolConcurrency(n int) {
+	if n < 1 {
+		n = 1
+	}
+	r.toolConcurrency = n
+}
+
+// SetThinking enables or disables extended thinking on LLM calls (enabled by default).
+// Clients whose model cannot think ignore it.
+func (r *ReAct) SetThinking(enabled bool) {
+	r.noThinking = !enabled
+}
+
+// SetSecretScrubber redacts secrets from tool results before they reach the
+// conversation state, events and the LLM. nil disables redaction.
+func (r *ReAct) SetSecretScrubber(s *secret.Scrubber) {
+	r.scrubber = s
+}
+
 // GetLastMessage returns the last message in the conversation without exposing state
 func (r *ReAct) GetLastMessage() message.Message {
 	return r.state.GetLastMessage()
@@ -103,43 +157,127 @@ func (r *ReAct) GetConversationSummary() string {
 
 // chatWithThinkingIfSupported uses thinking if the LLM client supports it
 func (r *ReAct) chatWithThinkingIfSupported(ctx context.Context, messages []message.Message, thinkingChan chan<- string) (message.Message, error) {
-	return r.llmClient.Chat(ctx, messages, true, thinkingChan)
+	return r.llmClient.Chat(ctx, messages, !r.noThinking, thinkingChan)
 }
 
 // chatWithToolChoice uses tool choice control if the LLM client supports it
 func (r *ReAct) chatWithToolChoice(ctx context.Context, messages []message.Message, toolChoice domain.ToolChoice, thinkingChan chan<- string) (message.Message, error) {
 	// Check if the client supports tool calling with tool choice
 	if toolClient, ok := r.llmClient.(domain.ToolCallingLLM); ok {
-		return toolClient.ChatWithToolChoice(ctx, messages, toolChoice, true, thinkingChan)
+		return toolClient.ChatWithToolChoice(ctx, messages, toolChoice, !r.noThinking, thinkingChan)
 	}
 
 	// If the client doesn't support tool choice, fall back to regular chat
 	// This ensures compatibility with non-tool-calling clients
-	return r.llmClient.Chat(ctx, messages, true, thinkingChan)
+	return r.llmClient.Chat(ctx, messages, !r.noThinking, thinkingChan)
+}
+
+// chat sends messages using tool calling if available, otherwise thinking/regular chat
+func (r *ReAct) chat(ctx context.Context, messages []message.Message) (message.Message, error) {
+	defer r.streamResponse()()
+	defer func(start time.Time) { r.recordLLMLatency(time.Since(start)) }(time.Now())
+
+	// Check if we have tools available and should use tool calling
+	if r.toolManager != nil && len(r.toolManager.GetTools()) > 0 {
+		// Use tool choice auto to let the LLM decide when to use tools
+		return r.chatWithToolChoice(ctx, messages, domain.ToolChoice{Type: domain.ToolChoiceAuto}, r.thinkingChan)
+	}
+	// Fall back to thinking if supported, otherwise regular chat
+	return r.chatWithThinkingIfSupported(ctx, messages, r.thinkingChan)
+}
+
+// streamResponse routes response text from clients that can stream it to
+// ResponseChunk events for the duration of one chat call. The returned function
+// ends streaming and waits until every chunk has been emitted, so chunks always
+// precede the events that follow the call.
+func (r *ReAct) streamResponse() func() {
+	streamer, ok := r.llmClient.(domain.ResponseStreamer)
+	if !ok {
+		return func() {}
+	}
+	responseChan := make(chan string, 64)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for chunk := range responseChan {
+			r.eventEmitter.EmitEvent(events.EventTypeResponseChunk, events.ResponseChunkData{
+				Content: chunk,
+			})
+		}
+	}()
+	streamer.SetResponseChannel(responseChan)
+
+	return func() {
+		streamer.SetResponseChannel(nil)
+		close(responseChan)
+		<-done
+	}
+}
+
+// overflowTrimPercent is the share of the context window kept when trimming
+// history after compaction failed to make a request fit
+const overflowTrimPercent = 0.5
+
+// recoverFromContextOverflow handles a request rejected for exceeding the context
+// window: it compacts and retries once, then drops the oldest messages and retries
+// again. The provider's raw error is only logged.
+func (r *ReAct) recoverFromContextOverflow(ctx context.Context, cause error) (message.Message, error) {
+	reactLogger.DebugWithIntention(pkgLogger.IntentionDebug, "Context-length error from LLM", "error", cause)
+	reactLogger.WarnWithIntention(pkgLogger.IntentionWarning, "Conversation exceeded the model's context window; compacting and retrying")
+	if err := r.state.CompactNow(ctx, r.llmClient); err != nil {
+		reactLogger.DebugWithIntention(pkgLogger.IntentionDebug, "Forced compaction failed", "error", err)
+	}
+	resp, err := r.chat(ctx, r.state.GetMessages())
+	if err == nil || !domain.IsContextLengthError(err) {
+		return resp, err
+	}
+
+	removed := r.state.TrimToTokens(int(float64(r.estimateContextWindow()) * overflowTrimPercent))
+	reactLogger.WarnWithIntention(pkgLogger.IntentionWarning, "Still too long after compaction; dropped oldest messages and retrying", "removed", removed)
+	resp, err = r.chat(ctx, r.state.GetMessages())
+	if err != nil && domain.IsContextLengthError(err) {
+		reactLogger.DebugWithIntention(pkgLogger.IntentionDebug, "Context-length error after trimming", "error", err)
+		return nil, errors.Wrap(domain.ErrContextLengthExceeded, "the conversation does not fit the model's context window even after compaction; start a new session or clear the history")
+	}
+	return resp, err
 }
 
 // annotateAndLogUsage attaches token usage (when available) to the response message
 // and prints a concise usage line for quick visibility.
 func (r *ReAct) annotateAndLogUsage(resp message.Message) {
-	// Only log usage for assistant/reasoning messages to avoid repeating the
-	// same usage for tool call placeholders (no new model tokens consumed yet).
-	switch resp.Type() {
-	case message.MessageTypeToolCall, message.MessageTypeToolCallBatch:
+	usageProvider, ok := r.llmClient.(domain.TokenUsageProvider)
+	if !ok {
+		return
+	}
+	usage, ok := usageProvider.LastTokenUsage()
+	if !ok {
 		return
 	}
 
-	// Get token usage if available
-	if usageProvider, ok := r.llmClient.(domain.TokenUsageProvider); ok {
-		if usage, ok2 := usageProvider.LastTokenUsage(); ok2 {
-			// Attach to message for persistence in state
-			resp.SetTokenUsage(usage.InputTokens, usage.OutputTokens, usage.TotalTokens)
-			// Note: Token and context display moved to context display below input prompt
-		}
+	// Every call counts toward the budget, including those that produced tool calls
+	r.addUsage(usage)
+
+	// Only attach usage to assistant/reasoning messages to avoid repeating the
+	// same usage on tool call placeholders.
+	switch resp.Type() {
+	case message.MessageTypeToolCall, message.MessageTypeToolCallBatch:
+		return
 	}
+	// Attach to message for persistence in state
+	resp.SetTokenUsage(usage.InputTokens, usage.OutputTokens, usage.TotalTokens)
+	// Note: Token and context display moved to context display below input prompt
 }
 
 // Run processes input using the configured maxIterations
 func (r *ReAct) Run(ctx context.Context, input string) (message.Message, error) {
+	// A follow-up prompt on the same client gets its own iterations and channel
+	r.currentIteration = 0
+	r.stopReason = nil
+	r.runStart = time.Now()
+	if r.thinkingChan != nil {
+		close(r.thinkingChan)
+	}
+
 	// Create internal thinking channel to convert string chunks to ThinkingChunk events
 	r.thinkingChan = make(chan string, 10)
 	go func() {
@@ -158,8 +296,13 @@ func (r *ReAct) Run(ctx context.Context, input string) (message.Message, error)
 	r.state.AddMessage(userMessage)
 
 	r.status = domain.AgentStatusRunning
+	r.recordArgumentValidation(true)
 	msg, err := r.runInternal(ctx)
 	if err != nil {
+		r.discardInterruptedToolCalls(ctx)
+		if timedOut(ctx) {
+			return r.stopForTimeLimit(), nil
+		}
 		return nil, errors.Wrapf(err, "failed to run internal processing")
 	}
 
@@ -173,27 +316,60 @@ func (r *ReAct) Resume(ctx context.Context) (message.Message, error) {
 	if r.pendingToolCall != nil {
 		resp := r.pendingToolCall
 		r.pendingToolCall = nil
+		r.pendingApproval = nil
 
 		done, err := r.processResponse(ctx, r.currentIteration, resp)
 		if err != nil {
+			r.discardInterruptedToolCalls(ctx)
+			if timedOut(ctx) {
+				return r.stopForTimeLimit(), nil
+			}
 			return nil, err
 		}
 		if done {
 			r.status = domain.AgentStatusCompleted
+			r.eventEmitter.EmitEvent(events.EventTypeResponse, events.ResponseData{Message: resp})
 			return resp, nil
 		}
+		if r.tooManyInvalidArguments() {
+			return nil, fmt.Errorf("%w: stopped after %d consecutive tool calls with invalid arguments", domain.ErrToolFailure, maxInvalidArgumentRetries)
+		}
 	}
 
 	msg, err := r.runInternal(ctx)
 	if err != nil {
+		r.discardInterruptedToolCalls(ctx)
+		if timedOut(ctx) {
+			return r.stopForTimeLimit(), nil
+		}
 		return nil, errors.Wrapf(err, "failed to run internal processing")
 	}
 
 	return msg, nil
 }
 
+// discardInterruptedToolCalls drops tool calls left without a result when the
+// context was canceled mid-turn, so the next turn starts from a valid history
+func (r *ReAct) discardInterruptedToolCalls(ctx context.Context) {
+	if ctx.Err() == nil {
+		return
+	}
+	if removed := r.state.RemoveUnpairedToolCalls(); removed > 0 {
+		reactLogger.InfoWithIntention(pkgLogger.IntentionCancel, "Removed interrupted tool calls from history", "count", removed)
+	}
+}
+
 func (r *ReAct) Close() {
-	close(r.thinkingChan)
+	if r.thinkingChan != nil {
+		close(r.thinkingChan)
+		r.thinkingChan = nil
+	}
+}
+
+// StopReason returns why the last run ended with a partial result instead of
+// an answer (domain.ErrMaxIterations, domain.ErrTimeLimit), or nil when it finished
+func (r *ReAct) StopReason() error {
+	return r.stopReason
 }
 
 func (r *ReAct) GetStatus() domain.AgentStatus {
@@ -217,8 +393,15 @@ func (r *ReAct) CancelPendingToolCall() {
 			// Add the declined result to state to complete the pair
 			r.state.AddMessage(declinedResult)
 		}
+		if batch, ok := r.pendingToolCall.(*message.ToolCallBatchMessage); ok {
+			for _, call := range batch.Calls() {
+				r.state.AddMessage(call)
+				r.state.AddMessage(message.NewToolResultMessage(call.ID(), "", "Operation cancelled by user"))
+			}
+		}
 
 		r.pendingToolCall = nil
+		r.pendingApproval = nil
 	}
 	r.status = domain.AgentStatusRunning
 }
@@ -255,21 +438,17 @@ func (r *ReAct) runInternal(ctx context.Context) (message.Message, error) {
 		maxTokensEstimate := r.estimateContextWindow()
 		const compactionThreshold = 70.0 // 70% threshold
 		if err := r.state.CompactIfNeeded(ctx, r.llmClient, maxTokensEstimate, compactionThreshold); err != nil {
-			return nil, fmt.Errorf("failed to compact messages when needed: %w", err)
+			return nil, fmt.Errorf("failed to compact messages when needed: %w", domain.LLMError(err))
 		}
+		// A note about a skipped repeated call goes to this request only, not into the history
 		messages := r.state.GetMessages()
+		if warning := r.takeLoopWarning(); warning != "" {
+			messages = append(slices.Clip(messages), message.NewAlignerSystemMessage(warning))
+		}
 
-		// Use tool calling if available, otherwise fall back to thinking/regular chat
-		var resp message.Message
-		var err error
-
-		// Check if we have tools available and should use tool calling
-		if r.toolManager != nil && len(r.toolManager.GetTools()) > 0 {
-			// Use tool choice auto to let the LLM decide when to use tools
-			resp, err = r.chatWithToolChoice(ctx, messages, domain.ToolChoice{Type: domain.ToolChoiceAuto}, r.thinkingChan)
-		} else {
-			// Fall back to thinking if supported, otherwise regular chat
-			resp, err = r.chatWithThinkingIfSupported(ctx, messages, r.thinkingChan)
+		resp, err := r.chat(ctx, messages)
+		if err != nil && domain.IsContextLengthError(err) {
+			resp, err = r.recoverFromContextOverflow(ctx, err)
 		}
 
 		if err != nil {
@@ -278,31 +457,29 @@ func (r *ReAct) runInternal(ctx context.Context) (message.Message, error) {
 				reactLogger.InfoWithIntention(pkgLogger.IntentionCancel, "Operation cancelled by user during LLM call. History preserved.")
 				return nil, ctx.Err()
 			}
-			return nil, fmt.Errorf("failed to get response from LLM client: %w", err)
+			return nil, fmt.Errorf("failed to get response from LLM client: %w", domain.LLMError(err))
 		}
 
-		// Clear waiting indicator and show minified response
-		fmt.Print("\r                    \r") // Clear the "Thinking..." line
 		// Annotate and log token usage when available
 		r.annotateAndLogUsage(resp)
 
-		// Check tool call if it requires user's approval (file writing operations and bash commands)
-		if toolCall, ok := resp.(*message.ToolCallMessage); ok {
-			toolName := string(toolCall.ToolName())
-
-			// Check for file operations that require approval
-			requiresApproval := toolName == "Write" || toolName == "Edit" || toolName == "MultiEdit"
-
-			// Check for bash commands that may require approval
-			if !requiresApproval && (toolName == "bash") {
-				requiresApproval = r.bashCommandRequiresApproval(toolCall)
-			}
+		// Stop once the token budget is spent, unless this response is already the final answer
+		if r.budgetExceeded() && resp.Type() != message.MessageTypeAssistant {
+			reactLogger.WarnWithIntention(pkgLogger.IntentionWarning, "Token budget exhausted, stopping run",
+				"used", r.totalUsage.TotalTokens, "limit", r.budget.MaxTotalTokens)
+			result := r.stopForBudget(resp)
+			r.status = domain.AgentStatusCompleted
+			r.eventEmitter.EmitEvent(events.EventTypeResponse, events.ResponseData{Message: result})
+			return result, nil
+		}
 
-			if requiresApproval {
-				r.pendingToolCall = toolCall
-				r.status = domain.AgentStatusWaitingForApproval
-				return nil, ErrWaitingForApproval
-			}
+		// Wait for the user's approval when the policy asks for it (file changes,
+		// unlisted bash commands, ...), for single calls and batches alike
+		if categories := r.categoriesToApprove(resp); len(categories) > 0 {
+			r.pendingToolCall = resp
+			r.pendingApproval = categories
+			r.status = domain.AgentStatusWaitingForApproval
+			return nil, ErrWaitingForApproval
 		}
 
 		done, err := r.processResponse(ctx, r.currentIteration, resp)
@@ -311,12 +488,22 @@ func (r *ReAct) runInternal(ctx context.Context) (message.Message, error) {
 		}
 		if done {
 			r.status = domain.AgentStatusCompleted
+			r.eventEmitter.EmitEvent(events.EventTypeResponse, events.ResponseData{Message: resp})
 			return resp, nil
 		}
+		if r.tooManyInvalidArguments() {
+			return nil, fmt.Errorf("%w: stopped after %d consecutive tool calls with invalid arguments", domain.ErrToolFailure, maxInvalidArgumentRetries)
+		}
+	}
+
+	if r.extendIterations(ctx) > 0 {
+		return r.runInternal(ctx)
 	}
 
-	// TBD: If it exhausted with tool calls, we might want to drop it to prevent Anthropic's error.
-	return nil, fmt.Errorf("exceeded maximum loop limit (%d) without a valid response", r.maxIterations)
+	result := r.stopForIterationLimit()
+	r.status = domain.AgentStatusCompleted
+	r.eventEmitter.EmitEvent(events.EventTypeResponse, events.ResponseData{Message: result})
+	return result, nil
 }
 
 // processResponse processes input using the configured maxIterations
@@ -361,7 +548,12 @@ func (r *ReAct) processResponse(ctx context.Context, currentIter int, resp messa
 		})
 		msg, err := r.handleToolCall(ctx, toolCall)
 		if err != nil {
-			return done, fmt.Errorf("failed to handle tool call: %w", err)
+			return done, fmt.Errorf("%w: failed to handle tool call: %w", domain.ErrToolFailure, err)
+		}
+		// A call cut short by the time limit has no usable result; leave it
+		// unpaired so it is removed with the other interrupted calls
+		if timedOut(ctx) {
+			return done, ctx.Err()
 		}
 
 		// Show truncated tool result
@@ -374,10 +566,9 @@ func (r *ReAct) processResponse(ctx context.Context, currentIter int, resp messa
 
 	case *message.ToolCallBatchMessage:
 		// Execute multiple tools within a single model turn to reduce loops
-		batch := resp
-		calls := batch.Calls()
-		for _, call := range calls {
-			// Check for cancellation before each tool in the batch
+		calls := resp.Calls()
+		for start := 0; start < len(calls); {
+			// Check for cancellation before each group in the batch
 			select {
 			case <-ctx.Done():
 				reactLogger.InfoWithIntention(pkgLogger.IntentionCancel, "Operation cancelled by user during batch tool execution. History preserved.")
@@ -385,20 +576,38 @@ func (r *ReAct) processResponse(ctx context.Context, currentIter int, resp messa
 			default:
 			}
 
-			// Add each tool call message to state for transcript consistency
-			r.state.AddMessage(call)
-			// Emit tool call start event for batch call
-			r.eventEmitter.EmitEvent(events.EventTypeToolCallStart, events.ToolCallStartData{
-				ToolName:  string(call.ToolName()),
-				Arguments: r.summarizeToolArgs(call.ToolArguments()),
-				CallID:    "", // Could add call ID if needed
-			})
-			msg, err := r.handleToolCall(ctx, call)
+			// Consecutive read-only calls form a group that may run concurrently;
+			// any other call runs alone to preserve read-write ordering
+			end := start + 1
+			if r.readOnly(calls[start].ToolName()) {
+				for end < len(calls) && r.readOnly(calls[end].ToolName()) {
+					end++
+				}
+			}
+			group := calls[start:end]
+
+			for _, call := range group {
+				// Emit tool call start event for batch call
+				r.eventEmitter.EmitEvent(events.EventTypeToolCallStart, events.ToolCallStartData{
+					ToolName:  string(call.ToolName()),
+					Arguments: r.summarizeToolArgs(call.ToolArguments()),
+					CallID:    "", // Could add call ID if needed
+				})
+			}
+			results, err := r.handleToolCallGroup(ctx, group)
 			if err != nil {
-				return done, fmt.Errorf("failed to handle tool call (batch): %w", err)
+				return done, fmt.Errorf("%w: failed to handle tool call (batch): %w", domain.ErrToolFailure, err)
+			}
+			if timedOut(ctx) {
+				return done, ctx.Err()
+			}
+			// Add calls and results to state in the model's order regardless of completion order
+			for i, call := range group {
+				r.state.AddMessage(call)
+				r.printTruncatedToolResult(results[i])
+				r.state.AddMessage(results[i])
 			}
-			r.printTruncatedToolResult(msg)
-			r.state.AddMessage(msg)
+			start = end
 		}
 		// After executing the batch, continue the loop to let the model consume results
 	default:
@@ -408,30 +617,116 @@ func (r *ReAct) processResponse(ctx context.Context, currentIter int, resp messa
 	return done, nil
 }
 
+// readOnly reports whether a tool declares that it changes nothing, so its
+// calls may run concurrently within a batch
+func (r *ReAct) readOnly(name message.ToolName) bool {
+	tool, ok := r.toolManager.GetTools()[name]
+	return ok && message.EffectsOf(tool).Access == message.AccessRead
+}
+
 func (r *ReAct) handleToolCall(ctx context.Context, toolCall *message.ToolCallMessage) (message.Message, error) {
 	id := toolCall.ID()
 	toolName := toolCall.ToolName()
 	toolArgs := toolCall.ToolArguments()
 
+	// Reject calls that do not match the tool's declared arguments with feedback
+	// the model can act on, instead of the handler's first failed type assertion
+	if tool, ok := r.toolManager.GetTools()[toolName]; ok {
+		if expandWrappedArguments(tool, toolArgs) {
+			reactLogger.DebugWithIntention(pkgLogger.IntentionDebug, "Expanded tool arguments sent as a JSON string", "tool", toolName)
+		}
+		problems := validateToolArguments(tool, toolArgs)
+		r.recordArgumentValidation(len(problems) == 0)
+		if len(problems) > 0 {
+			reactLogger.DebugWithIntention(pkgLogger.IntentionDebug, "Rejected tool call with invalid arguments", "tool", toolName, "problems", len(problems))
+			return message.NewToolResultMessage(id, "", formatArgumentErrors(toolName, tool, problems)), nil
+		}
+	}
+
+	if refused := r.refuseByPolicy(toolCall); refused != nil {
+		return refused, nil
+	}
+
+	// Answer a stuck loop of identical calls from the previous result
+	if repeated, ok := r.checkRepeatedCall(toolCall); ok {
+		return repeated, nil
+	}
+
+	resp := r.executeToolCall(ctx, toolCall)
+	r.recordCallResult(toolCall, resp)
+	return resp, nil
+}
+
+// executeToolCall runs the tool and converts its result (or failure) to a tool result message
+func (r *ReAct) executeToolCall(ctx context.Context, toolCall *message.ToolCallMessage) message.Message {
+	id := toolCall.ID()
+
 	// Execute tool and get structured result
-	toolResult, err := r.toolManager.CallTool(ctx, toolName, toolArgs)
+	start := time.Now()
+	toolResult, err := r.toolManager.CallTool(ctx, toolCall.ToolName(), toolCall.ToolArguments())
+	r.recordToolLatency(id, string(toolCall.ToolName()), time.Since(start))
 	if err != nil {
 		// Don't return an error - create a tool result message with the error instead
 		// This allows the agent to continue and let the LLM see the error message
-		return message.NewToolResultMessage(id, "", fmt.Sprintf("Tool execution failed: %v", err)), nil
+		return message.NewToolResultMessage(id, "", r.scrubToolOutput(toolCall.ToolName(), fmt.Sprintf("Tool execution failed: %v", err)))
 	}
+	toolResult.Text = r.scrubToolOutput(toolCall.ToolName(), toolResult.Text)
+	toolResult.Error = r.scrubToolOutput(toolCall.ToolName(), toolResult.Error)
+	toolResult.Text = r.limitToolResult(toolCall.ToolName(), toolResult.Text)
 
 	// Handle structured tool result
-	var resp message.Message
 	if len(toolResult.Images) > 0 {
-		resp = message.NewToolResultMessageWithImages(id, toolResult.Text, toolResult.Images, toolResult.Error)
+		return message.NewToolResultMessageWithImages(id, toolResult.Text, toolResult.Images, toolResult.Error)
 	} else if toolResult.Error != "" {
-		resp = message.NewToolResultMessage(id, "", toolResult.Error)
-	} else {
-		resp = message.NewToolResultMessage(id, toolResult.Text, "")
+		return message.NewToolResultMessage(id, "", toolResult.Error)
 	}
+	return message.NewToolResultMessage(id, toolResult.Text, "")
+}
 
-	return resp, nil
+// scrubToolOutput redacts secrets from tool output, reporting how many were found
+func (r *ReAct) scrubToolOutput(toolName message.ToolName, text string) string {
+	text, n := r.scrubber.Scrub(text)
+	if n > 0 {
+		reactLogger.InfoWithIntention(pkgLogger.IntentionStatus, "Redacted secrets from tool output", "tool", toolName, "redactions", n)
+	}
+	return text
+}
+
+// handleToolCallGroup executes calls with up to toolConcurrency workers and
+// returns their results in call order
+func (r *ReAct) handleToolCallGroup(ctx context.Context, calls []*message.ToolCallMessage) ([]message.Message, error) {
+	results := make([]message.Message, len(calls))
+	errs := make([]error, len(calls))
+
+	workers := min(r.toolConcurrency, len(calls))
+	if workers <= 1 {
+		for i, call := range calls {
+			if results[i], errs[i] = r.handleToolCall(ctx, call); errs[i] != nil {
+				return nil, errs[i]
+			}
+		}
+		return results, nil
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, workers)
+	for i, call := range calls {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, call *message.ToolCallMessage) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i], errs[i] = r.handleToolCall(ctx, call)
+		}(i, call)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
 }
 
 // printTruncatedToolResult emits tool result events
@@ -439,12 +734,16 @@ func (r *ReAct) printTruncatedToolResult(msg message.Message) {
 	content := strings.TrimRight(msg.Content(), "\n")
 	isError := strings.HasPrefix(content, "Error:")
 
+	// Calls answered without running the tool (invalid arguments, repeats) have no timing
+	timing, _ := r.takeToolTiming(msg.ID())
+
 	// Emit tool result event
 	r.eventEmitter.EmitEvent(events.EventTypeToolResult, events.ToolResultData{
-		ToolName: "", // Tool name would need to be tracked separately
+		ToolName: timing.name,
 		CallID:   "", // Call ID would need to be tracked separately
-		Content:  content,
+		Content:  message.TruncateHeadTail(content, r.truncation),
 		IsError:  isError,
+		Duration: timing.duration,
 	})
 }
 
@@ -546,12 +845,15 @@ func (r *ReAct) emitEventWithIteration(eventType events.EventType, data interfac
 	}
 }
 
-// estimateContextWindow estimates the context window size based on common model patterns
+// estimateContextWindow returns the model's context window as reported by the
+// LLM client, falling back to an estimate based on the client type
 func (r *ReAct) estimateContextWindow() int {
-	// This is a conservative estimation based on common model types
-	// In the future, this should be replaced with dynamic model capability detection
+	if provider, ok := r.llmClient.(domain.ContextWindowProvider); ok {
+		if window := provider.MaxContextTokens(); window > 0 {
+			return window
+		}
+	}
 
-	// Try to get client type information if possible
 	clientType := fmt.Sprintf("%T", r.llmClient)
 
 	switch {
@@ -0,0 +1,102 @@
+package react
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	pkgLogger "github.com/fpt/go-gennai-cli/pkg/logger"
+	"github.com/fpt/go-gennai-cli/pkg/message"
+)
+
+const (
+	// DefaultMaxRepeatedToolCalls is how many identical tool calls in a row run
+	// before further repeats are answered from the previous result
+	DefaultMaxRepeatedToolCalls = 3
+
+	// repeatedResultPreviewChars limits the previous result quoted back to the model
+	repeatedResultPreviewChars = 500
+)
+
+// repetitionTracker follows consecutive identical tool calls (same name and
+// arguments) so a model stuck in a loop can be told to change course
+type repetitionTracker struct {
+	lastKey    string
+	count      int    // consecutive calls with lastKey
+	lastResult string // result text of the most recent executed call with lastKey
+}
+
+// SetMaxRepeatedToolCalls sets how many identical tool calls in a row are
+// executed; later repeats return the previous result with a note instead.
+// Values below 1 use DefaultMaxRepeatedToolCalls.
+func (r *ReAct) SetMaxRepeatedToolCalls(n int) {
+	if n < 1 {
+		n = DefaultMaxRepeatedToolCalls
+	}
+	r.maxRepeatedCalls = n
+}
+
+// toolCallKey identifies a call by tool name and arguments; map keys are
+// marshalled in sorted order, so equal arguments give equal keys
+func toolCallKey(call *message.ToolCallMessage) string {
+	args, err := json.Marshal(call.ToolArguments())
+	if err != nil {
+		args = []byte(fmt.Sprintf("%v", call.ToolArguments()))
+	}
+	return string(call.ToolName()) + "\x00" + string(args)
+}
+
+// checkRepeatedCall records the call and, once it has been repeated more than
+// maxRepeatedCalls times in a row, returns the result to use instead of running it
+func (r *ReAct) checkRepeatedCall(call *message.ToolCallMessage) (message.Message, bool) {
+	r.repeatMu.Lock()
+	defer r.repeatMu.Unlock()
+
+	key := toolCallKey(call)
+	if key != r.repeats.lastKey {
+		r.repeats = repetitionTracker{lastKey: key, count: 1}
+		return nil, false
+	}
+	r.repeats.count++
+	if r.repeats.count <= r.maxRepeatedCalls {
+		return nil, false
+	}
+
+	reactLogger.WarnWithIntention(pkgLogger.IntentionWarning, "Skipping repeated identical tool call",
+		"tool", call.ToolName(), "repeats", r.repeats.count)
+	r.loopWarning = fmt.Sprintf("You have called %s with the same arguments %d times in a row and it keeps returning the same result. "+
+		"Repeating it will not change the outcome. Use the result you already have, try a different tool or different arguments, "+
+		"or give your final answer.", call.ToolName(), r.repeats.count)
+	return message.NewToolResultMessage(call.ID(), "", fmt.Sprintf(
+		"Not run again: this exact %s call was already made %d times in a row. Its previous result was:\n%s",
+		call.ToolName(), r.repeats.count-1, previewResult(r.repeats.lastResult))), true
+}
+
+// recordCallResult remembers the result of an executed call for later repeats
+func (r *ReAct) recordCallResult(call *message.ToolCallMessage, result message.Message) {
+	r.repeatMu.Lock()
+	defer r.repeatMu.Unlock()
+	if toolCallKey(call) == r.repeats.lastKey {
+		r.repeats.lastResult = result.Content()
+	}
+}
+
+// takeLoopWarning returns and clears the pending note about a repeated call
+func (r *ReAct) takeLoopWarning() string {
+	r.repeatMu.Lock()
+	defer r.repeatMu.Unlock()
+	warning := r.loopWarning
+	r.loopWarning = ""
+	return warning
+}
+
+func previewResult(result string) string {
+	result = strings.TrimSpace(result)
+	if result == "" {
+		return "(empty)"
+	}
+	if len(result) > repeatedResultPreviewChars {
+		return result[:repeatedResultPreviewChars] + "\n... (truncated)"
+	}
+	return result
+}
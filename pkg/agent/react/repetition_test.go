@@ -0,0 +1,77 @@
+package react
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/fpt/go-gennai-cli/pkg/agent/state"
+	"github.com/fpt/go-gennai-cli/pkg/message"
+)
+
+func TestReAct_RepeatedToolCallsAreCapped(t *testing.T) {
+	mockLLM := &mockLLM{}
+	mockToolManager := &mockToolManager{}
+
+	llmCalls := 0
+	var sawWarning bool
+	mockLLM.chatFunc = func(ctx context.Context, messages []message.Message) (message.Message, error) {
+		llmCalls++
+		for _, msg := range messages {
+			if msg.Source() == message.MessageSourceAligner && strings.Contains(msg.Content(), "same arguments") {
+				sawWarning = true
+			}
+		}
+		if llmCalls <= 4 {
+			return message.NewToolCallMessage("Read", message.ToolArgumentValues{"file_path": "main.go", "limit": float64(10)}), nil
+		}
+		return message.NewChatMessage(message.MessageTypeAssistant, "done"), nil
+	}
+	executions := 0
+	mockToolManager.callToolFunc = func(ctx context.Context, name message.ToolName, args message.ToolArgumentValues) (message.ToolResult, error) {
+		executions++
+		return message.NewToolResultText("package main"), nil
+	}
+
+	r, _ := NewReAct(mockLLM, mockToolManager, state.NewMessageState(), &mockAligner{}, 10)
+	r.SetMaxRepeatedToolCalls(3)
+
+	if _, err := r.Run(context.Background(), "read main.go"); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if executions != 3 {
+		t.Errorf("expected the tool to run 3 times before repeats are skipped, ran %d", executions)
+	}
+	if !sawWarning {
+		t.Error("expected a note about the repeated call in the next LLM request")
+	}
+
+	var last *message.ToolResultMessage
+	for _, msg := range r.state.GetMessages() {
+		if res, ok := msg.(*message.ToolResultMessage); ok {
+			last = res
+		}
+		if msg.Source() == message.MessageSourceAligner {
+			t.Errorf("loop note should not remain in the conversation: %q", msg.Content())
+		}
+	}
+	if last == nil || !strings.Contains(last.Error, "Not run again") || !strings.Contains(last.Error, "package main") {
+		t.Errorf("skipped call should quote the previous result, got %+v", last)
+	}
+}
+
+func TestReAct_RepeatCountResetsOnDifferentCall(t *testing.T) {
+	r, _ := NewReAct(&mockLLM{}, &mockToolManager{}, state.NewMessageState(), &mockAligner{}, 10)
+	r.SetMaxRepeatedToolCalls(2)
+
+	a := message.ToolArgumentValues{"path": "a"}
+	b := message.ToolArgumentValues{"path": "b"}
+	for i, args := range []message.ToolArgumentValues{a, a, b, a, a} {
+		if _, skipped := r.checkRepeatedCall(message.NewToolCallMessage("LS", args)); skipped {
+			t.Errorf("call %d should run: no more than 2 identical calls in a row", i)
+		}
+	}
+	if _, skipped := r.checkRepeatedCall(message.NewToolCallMessage("LS", a)); !skipped {
+		t.Error("third identical call in a row should be skipped")
+	}
+}
@@ -0,0 +1,33 @@
+package react
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	pkgLogger "github.com/fpt/go-gennai-cli/pkg/logger"
+	"github.com/fpt/go-gennai-cli/pkg/message"
+)
+
+// SetMaxToolResultChars caps the characters of each tool result kept in the
+// conversation state. Longer results keep their start and end with a note
+// about the omitted middle. Values below 1 keep results in full.
+func (r *ReAct) SetMaxToolResultChars(n int) {
+	r.maxResultChars = max(n, 0)
+}
+
+// limitToolResult shortens text over maxResultChars, keeping the first three
+// quarters of the budget from its start and the rest from its end so both
+// leading matches and a trailing summary or error survive
+func (r *ReAct) limitToolResult(toolName message.ToolName, text string) string {
+	if r.maxResultChars == 0 || utf8.RuneCountInString(text) <= r.maxResultChars {
+		return text
+	}
+	runes := []rune(text)
+	head := r.maxResultChars * 3 / 4
+	tail := r.maxResultChars - head
+	omitted := len(runes) - head - tail
+	reactLogger.DebugWithIntention(pkgLogger.IntentionDebug, "Truncated tool result kept in state", "tool", toolName, "chars", len(runes), "omitted", omitted)
+	return fmt.Sprintf("%s\n\n[... %d characters omitted: the result was longer than max_tool_result_chars (%d). "+
+		"Re-run %s with a narrower query, such as a more specific pattern or path, or an offset and limit, to see the omitted part ...]\n\n%s",
+		string(runes[:head]), omitted, r.maxResultChars, toolName, string(runes[len(runes)-tail:]))
+}
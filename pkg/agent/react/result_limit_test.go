@@ -0,0 +1,58 @@
+package react
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/fpt/go-gennai-cli/pkg/agent/state"
+	"github.com/fpt/go-gennai-cli/pkg/message"
+)
+
+func TestReAct_MaxToolResultChars(t *testing.T) {
+	mockLLM := &mockLLM{}
+	mockToolManager := &mockToolManager{}
+
+	grepOutput := "first match\n" + strings.Repeat("src/match.go:1: x\n", 1000) + "1000 matches"
+	calls := 0
+	mockLLM.chatFunc = func(ctx context.Context, messages []message.Message) (message.Message, error) {
+		calls++
+		if calls == 1 {
+			return message.NewToolCallMessage("Grep", message.ToolArgumentValues{"pattern": "x"}), nil
+		}
+		return message.NewChatMessage(message.MessageTypeAssistant, "done"), nil
+	}
+	mockToolManager.callToolFunc = func(ctx context.Context, name message.ToolName, args message.ToolArgumentValues) (message.ToolResult, error) {
+		return message.NewToolResultText(grepOutput), nil
+	}
+
+	r, _ := NewReAct(mockLLM, mockToolManager, state.NewMessageState(), &mockAligner{}, 10)
+	r.SetMaxToolResultChars(400)
+	if _, err := r.Run(context.Background(), "find x"); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	var stored *message.ToolResultMessage
+	for _, msg := range r.state.GetMessages() {
+		if res, ok := msg.(*message.ToolResultMessage); ok {
+			stored = res
+		}
+	}
+	if stored == nil {
+		t.Fatal("expected a tool result in state")
+	}
+	if !strings.HasPrefix(stored.Result, "first match") || !strings.HasSuffix(stored.Result, "1000 matches") {
+		t.Errorf("expected the start and end of the result to be kept, got %q", stored.Result)
+	}
+	if !strings.Contains(stored.Result, "characters omitted") || !strings.Contains(stored.Result, "Re-run Grep") {
+		t.Errorf("expected a truncation note, got %q", stored.Result)
+	}
+	if len(stored.Result) > 700 {
+		t.Errorf("expected the stored result near the 400 character cap, got %d", len(stored.Result))
+	}
+
+	r.SetMaxToolResultChars(0)
+	if got := r.limitToolResult("Grep", grepOutput); got != grepOutput {
+		t.Error("expected a zero limit to keep the result in full")
+	}
+}
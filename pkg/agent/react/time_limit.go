@@ -0,0 +1,40 @@
+package react
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/fpt/go-gennai-cli/pkg/agent/domain"
+	"github.com/fpt/go-gennai-cli/pkg/agent/events"
+	pkgLogger "github.com/fpt/go-gennai-cli/pkg/logger"
+	"github.com/fpt/go-gennai-cli/pkg/message"
+	"github.com/pkg/errors"
+)
+
+// timedOut reports whether ctx ended because its deadline passed rather than
+// being cancelled
+func timedOut(ctx context.Context) bool {
+	return errors.Is(ctx.Err(), context.DeadlineExceeded)
+}
+
+// stopForTimeLimit ends a run whose context deadline passed with the most
+// recent assistant output, if any, instead of a bare context error. Tool calls
+// the deadline interrupted were already removed, so the history stays valid.
+func (r *ReAct) stopForTimeLimit() message.Message {
+	elapsed := time.Since(r.runStart).Round(time.Second)
+	reactLogger.WarnWithIntention(pkgLogger.IntentionWarning, "Time limit reached, returning partial result",
+		"elapsed", elapsed, "iterations", r.currentIteration)
+
+	notice := fmt.Sprintf("Stopped: time limit reached after %s before finishing. Raise --time-limit to allow longer runs.", elapsed)
+	if partial := r.latestAssistantText(); partial != "" {
+		notice += "\n\nPartial result:\n" + partial
+	}
+
+	r.stopReason = errors.Wrapf(domain.ErrTimeLimit, "stopped after %s", elapsed)
+	result := message.NewChatMessage(message.MessageTypeAssistant, notice)
+	r.state.AddMessage(result)
+	r.status = domain.AgentStatusCompleted
+	r.eventEmitter.EmitEvent(events.EventTypeResponse, events.ResponseData{Message: result})
+	return result
+}
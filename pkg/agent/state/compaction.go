@@ -2,7 +2,9 @@ package state
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/fpt/go-gennai-cli/pkg/agent/domain"
@@ -333,58 +335,118 @@ func (c *MessageState) createLLMSummary(ctx context.Context, llm domain.LLM, mes
 	// Build conversation text for summarization
 	var conversationBuilder strings.Builder
 	conversationBuilder.WriteString("Previous conversation to summarize:\n\n")
+	writeSummaryTranscript(&conversationBuilder, messages, false)
+
+	// Create summarization prompt
+	summaryPrompt := fmt.Sprintf(`Please create a concise summary of the following conversation. Focus on:
+1. Main topics discussed
+2. Key findings or results
+3. Important context that should be preserved
+4. Any ongoing tasks or decisions
+
+Keep the summary under 200 words and preserve essential context for continuing the conversation.
+
+%s
+
+Summary:`, conversationBuilder.String())
+
+	// Use LLM to create summary
+	summaryMessage := message.NewChatMessage(message.MessageTypeUser, summaryPrompt)
+	response, err := llm.Chat(ctx, []message.Message{summaryMessage}, false, nil) // Summary doesn't need thinking
+	if err != nil {
+		return "", fmt.Errorf("failed to generate LLM summary: %w", err)
+	}
+
+	return response.Content(), nil
+}
+
+// maxSummaryToolText is the length tool results and tool arguments are cut to
+// in the transcript given to the summarizer
+const maxSummaryToolText = 200
+
+// writeSummaryTranscript renders messages as plain text for a summarization
+// prompt. With toolArgs, tool calls (including batched ones) list their
+// arguments so the summary can say which files were touched.
+func writeSummaryTranscript(b *strings.Builder, messages []message.Message, toolArgs bool) {
+	writeToolCall := func(call *message.ToolCallMessage) {
+		if !toolArgs {
+			b.WriteString(fmt.Sprintf("Tool used: %s\n", call.ToolName()))
+			return
+		}
+		b.WriteString(fmt.Sprintf("Tool used: %s %s\n", call.ToolName(), summaryToolArgs(call.ToolArguments())))
+	}
 
 	for _, msg := range messages {
 		switch msg.Type() {
 		case message.MessageTypeUser:
-			conversationBuilder.WriteString(fmt.Sprintf("User: %s\n", msg.Content()))
+			b.WriteString(fmt.Sprintf("User: %s\n", msg.Content()))
 		case message.MessageTypeAssistant:
 			// Only include actual responses, not tool calls
 			if len(msg.Content()) > 0 && !strings.HasPrefix(msg.Content(), "Tool call:") {
-				conversationBuilder.WriteString(fmt.Sprintf("Assistant: %s\n", msg.Content()))
+				b.WriteString(fmt.Sprintf("Assistant: %s\n", msg.Content()))
 			}
 		case message.MessageTypeToolCall:
 			if toolMsg, ok := msg.(*message.ToolCallMessage); ok {
-				conversationBuilder.WriteString(fmt.Sprintf("Tool used: %s\n", toolMsg.ToolName()))
+				writeToolCall(toolMsg)
+			}
+		case message.MessageTypeToolCallBatch:
+			if batch, ok := msg.(*message.ToolCallBatchMessage); ok && toolArgs {
+				for _, call := range batch.Calls() {
+					writeToolCall(call)
+				}
 			}
 		case message.MessageTypeToolResult:
 			if toolResult, ok := msg.(*message.ToolResultMessage); ok {
-				result := toolResult.Result
-				if len(result) > 200 {
-					result = result[:200] + "..."
-				}
+				result := truncateSummaryText(toolResult.Result)
 
 				// Drop all images from older messages to save tokens - recent messages keep the latest images
 				if len(msg.Images()) > 0 {
-					conversationBuilder.WriteString(fmt.Sprintf("Tool result: %s [Image data truncated for token efficiency]\n", result))
+					b.WriteString(fmt.Sprintf("Tool result: %s [Image data truncated for token efficiency]\n", result))
 				} else {
-					conversationBuilder.WriteString(fmt.Sprintf("Tool result: %s\n", result))
+					b.WriteString(fmt.Sprintf("Tool result: %s\n", result))
 				}
 			}
 		}
 	}
+}
 
-	// Create summarization prompt
-	summaryPrompt := fmt.Sprintf(`Please create a concise summary of the following conversation. Focus on:
-1. Main topics discussed
-2. Key findings or results
-3. Important context that should be preserved
-4. Any ongoing tasks or decisions
-
-Keep the summary under 200 words and preserve essential context for continuing the conversation.
+// summaryToolArgs renders tool arguments as key=value pairs. Paths come first
+// and are never cut, so the summary can name every file touched; other
+// values, such as file content, are truncated.
+func summaryToolArgs(args message.ToolArgumentValues) string {
+	var paths, others []string
+	for key := range args {
+		if isPathArgument(key) {
+			paths = append(paths, key)
+		} else {
+			others = append(others, key)
+		}
+	}
+	slices.Sort(paths)
+	slices.Sort(others)
 
-%s
+	parts := make([]string, 0, len(args))
+	for _, key := range paths {
+		value, _ := json.Marshal(args[key])
+		parts = append(parts, key+"="+string(value))
+	}
+	for _, key := range others {
+		value, _ := json.Marshal(args[key])
+		parts = append(parts, key+"="+truncateSummaryText(string(value)))
+	}
+	return strings.Join(parts, " ")
+}
 
-Summary:`, conversationBuilder.String())
+// isPathArgument reports whether a tool argument names files or directories
+func isPathArgument(key string) bool {
+	return key == "path" || key == "paths" || key == "directory" || strings.HasSuffix(key, "_path")
+}
 
-	// Use LLM to create summary
-	summaryMessage := message.NewChatMessage(message.MessageTypeUser, summaryPrompt)
-	response, err := llm.Chat(ctx, []message.Message{summaryMessage}, false, nil) // Summary doesn't need thinking
-	if err != nil {
-		return "", fmt.Errorf("failed to generate LLM summary: %w", err)
+func truncateSummaryText(s string) string {
+	if len(s) > maxSummaryToolText {
+		return s[:maxSummaryToolText] + "..."
 	}
-
-	return response.Content(), nil
+	return s
 }
 
 // createBasicMessageSummary creates a simple fallback summary of messages
@@ -0,0 +1,52 @@
+package state
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/fpt/go-gennai-cli/pkg/agent/domain"
+	"github.com/fpt/go-gennai-cli/pkg/message"
+)
+
+// CreateHandoffSummary asks the LLM for a structured recap of the session, so
+// someone (or a fresh session) can pick up the work without the full history
+func CreateHandoffSummary(ctx context.Context, llm domain.LLM, messages []message.Message) (string, error) {
+	if len(messages) == 0 {
+		return "", fmt.Errorf("no conversation to summarize")
+	}
+
+	var conversationBuilder strings.Builder
+	conversationBuilder.WriteString("Session to summarize:\n\n")
+	writeSummaryTranscript(&conversationBuilder, messages, true)
+
+	handoffPrompt := fmt.Sprintf(`Write a handoff note for the following coding session, so that someone who was not present can continue the work. Use these Markdown sections:
+
+## Goals
+What the user set out to do.
+
+## Changes by file
+One bullet per file that was created or modified, saying what changed. Write "None" if no files were changed.
+
+## Decisions
+Choices made along the way and why.
+
+## Open questions
+Anything unresolved, uncertain or waiting on the user.
+
+## Next steps
+Concrete remaining work, in order.
+
+Be brief and factual. Only include what the session shows; do not invent changes.
+
+%s
+
+Handoff note:`, conversationBuilder.String())
+
+	request := message.NewChatMessage(message.MessageTypeUser, handoffPrompt)
+	response, err := llm.Chat(ctx, []message.Message{request}, false, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate handoff summary: %w", err)
+	}
+	return strings.TrimSpace(response.Content()), nil
+}
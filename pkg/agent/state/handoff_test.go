@@ -0,0 +1,68 @@
+package state
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/fpt/go-gennai-cli/pkg/message"
+)
+
+func TestCreateHandoffSummary(t *testing.T) {
+	var prompt string
+	llm := &mockLLM{chatFunc: func(ctx context.Context, messages []message.Message) (message.Message, error) {
+		prompt = messages[0].Content()
+		return message.NewChatMessage(message.MessageTypeAssistant, "\n## Goals\nFix the parser\n"), nil
+	}}
+
+	longPath := strings.Repeat("deep/", 50) + "parser.go"
+	edit := message.NewToolCallMessage("Edit", message.ToolArgumentValues{
+		"old_string": strings.Repeat("x", 500), "new_string": "y", "file_path": longPath,
+	})
+	batch := message.NewToolCallBatch([]*message.ToolCallMessage{
+		message.NewToolCallMessage("Write", message.ToolArgumentValues{"file_path": "parser_test.go"}),
+	})
+	messages := []message.Message{
+		message.NewChatMessage(message.MessageTypeUser, "Fix the parser"),
+		edit,
+		message.NewToolResultMessage(edit.ID(), "ok", ""),
+		batch,
+		message.NewChatMessage(message.MessageTypeAssistant, "Done"),
+	}
+
+	summary, err := CreateHandoffSummary(context.Background(), llm, messages)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary != "## Goals\nFix the parser" {
+		t.Errorf("unexpected summary %q", summary)
+	}
+	for _, want := range []string{
+		"## Changes by file", "## Next steps",
+		"User: Fix the parser",
+		// The path comes first and is kept whole; only the content is cut
+		`Tool used: Edit file_path="` + longPath + `" new_string="y" old_string="xxx`,
+		`Tool used: Write file_path="parser_test.go"`,
+		"Assistant: Done",
+	} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt missing %q:\n%s", want, prompt)
+		}
+	}
+
+	if strings.Contains(prompt, strings.Repeat("x", maxSummaryToolText)) {
+		t.Error("expected the edit content to be truncated")
+	}
+
+	if _, err := CreateHandoffSummary(context.Background(), llm, nil); err == nil {
+		t.Error("expected an error for an empty session")
+	}
+
+	failing := &mockLLM{chatFunc: func(ctx context.Context, messages []message.Message) (message.Message, error) {
+		return nil, errors.New("offline")
+	}}
+	if _, err := CreateHandoffSummary(context.Background(), failing, messages); err == nil || !strings.Contains(err.Error(), "offline") {
+		t.Errorf("expected the LLM error, got %v", err)
+	}
+}
@@ -0,0 +1,118 @@
+package state
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"unicode/utf8"
+
+	"github.com/fpt/go-gennai-cli/pkg/agent/domain"
+	pkgLogger "github.com/fpt/go-gennai-cli/pkg/logger"
+	"github.com/fpt/go-gennai-cli/pkg/message"
+)
+
+// CompactNow summarizes older messages immediately, regardless of token usage.
+// Used when the provider rejected a request for exceeding the context window,
+// which means the usage-based estimate cannot be trusted.
+func (c *MessageState) CompactNow(ctx context.Context, llm domain.LLM) error {
+	logger.InfoWithIntention(pkgLogger.IntentionStatus, "Forcing compaction after context-length error",
+		"message_count", len(c.Messages))
+	return c.performCompaction(ctx, llm)
+}
+
+// TrimToTokens drops the oldest non-system messages until the estimated size
+// fits maxTokens. The latest user request and everything after it are kept, so
+// if they alone are too large, their biggest tool results are truncated instead.
+// Tool calls are removed together with their results. It returns the number of
+// messages removed.
+func (c *MessageState) TrimToTokens(maxTokens int) int {
+	if maxTokens <= 0 {
+		return 0
+	}
+	total := 0
+	for _, msg := range c.Messages {
+		total += contentTokens(msg)
+	}
+
+	// The current request starts at the last user message
+	protectFrom := 0
+	for i := len(c.Messages) - 1; i >= 0; i-- {
+		if c.Messages[i].Type() == message.MessageTypeUser {
+			protectFrom = i
+			break
+		}
+	}
+
+	dropped := make(map[string]bool) // IDs of removed tool calls, whose results go too
+	kept := make([]message.Message, 0, len(c.Messages))
+	removed := 0
+	for i, msg := range c.Messages {
+		drop := false
+		switch {
+		case msg.Type() == message.MessageTypeToolResult && dropped[msg.ID()]:
+			drop = true
+		case i >= protectFrom || msg.Type() == message.MessageTypeSystem:
+		case total > maxTokens:
+			drop = true
+			if msg.Type() == message.MessageTypeToolCall {
+				dropped[msg.ID()] = true
+			}
+		}
+		if drop {
+			total -= contentTokens(msg)
+			removed++
+			continue
+		}
+		kept = append(kept, msg)
+	}
+	c.Messages = kept
+
+	if total > maxTokens {
+		total = c.truncateLargestToolResults(total, maxTokens)
+	}
+
+	logger.InfoWithIntention(pkgLogger.IntentionStatus, "Trimmed conversation to fit context window",
+		"removed_messages", removed, "estimated_tokens", total, "max_tokens", maxTokens)
+	return removed
+}
+
+// truncateLargestToolResults shortens tool results, largest first, until the
+// estimated total fits maxTokens. It returns the new estimate.
+func (c *MessageState) truncateLargestToolResults(total, maxTokens int) int {
+	var indexes []int
+	for i, msg := range c.Messages {
+		if _, ok := msg.(*message.ToolResultMessage); ok {
+			indexes = append(indexes, i)
+		}
+	}
+	sort.Slice(indexes, func(a, b int) bool {
+		return contentTokens(c.Messages[indexes[a]]) > contentTokens(c.Messages[indexes[b]])
+	})
+
+	const keepChars = 2000
+	for _, i := range indexes {
+		if total <= maxTokens {
+			break
+		}
+		result := c.Messages[i].(*message.ToolResultMessage)
+		if len(result.Result) <= keepChars {
+			continue
+		}
+		cut := keepChars
+		for cut > 0 && !utf8.RuneStart(result.Result[cut]) {
+			cut--
+		}
+		before := contentTokens(result)
+		truncated := fmt.Sprintf("%s\n\n[Tool result truncated from %d characters to fit the context window]", result.Result[:cut], len(result.Result))
+		c.Messages[i] = message.NewToolResultMessage(result.ID(), truncated, result.Error)
+		total -= before - contentTokens(c.Messages[i])
+	}
+	return total
+}
+
+// contentTokens estimates the tokens a message occupies in the prompt. Unlike
+// estimateTokensFromMessages it ignores stored usage, which describes the whole
+// request that produced the message rather than the message itself.
+func contentTokens(msg message.Message) int {
+	return (len(msg.Content())+len(msg.Thinking()))/4 + 8
+}
@@ -0,0 +1,79 @@
+package state
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fpt/go-gennai-cli/pkg/message"
+)
+
+func TestTrimToTokens_DropsOldestKeepingPairsAndCurrentRequest(t *testing.T) {
+	big := strings.Repeat("x", 4000) // ~1000 tokens
+	s := NewMessageState()
+	s.AddMessage(message.NewSystemMessage("system prompt"))
+	s.AddMessage(message.NewChatMessage(message.MessageTypeUser, "old request"))
+	oldCall := message.NewToolCallMessage("Read", message.ToolArgumentValues{"file_path": "a"})
+	s.AddMessage(oldCall)
+	s.AddMessage(message.NewToolResultMessage(oldCall.ID(), big, ""))
+	s.AddMessage(message.NewChatMessage(message.MessageTypeAssistant, big))
+	s.AddMessage(message.NewChatMessage(message.MessageTypeUser, "current request"))
+	newCall := message.NewToolCallMessage("Read", message.ToolArgumentValues{"file_path": "b"})
+	s.AddMessage(newCall)
+	s.AddMessage(message.NewToolResultMessage(newCall.ID(), "small", ""))
+
+	removed := s.TrimToTokens(500)
+	if removed != 4 {
+		t.Errorf("expected 4 messages removed, got %d", removed)
+	}
+	var types []message.MessageType
+	for _, msg := range s.GetMessages() {
+		types = append(types, msg.Type())
+	}
+	want := []message.MessageType{message.MessageTypeSystem, message.MessageTypeUser, message.MessageTypeToolCall, message.MessageTypeToolResult}
+	if len(types) != len(want) {
+		t.Fatalf("expected %v, got %v", want, types)
+	}
+	for i := range want {
+		if types[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, types)
+		}
+	}
+	if s.GetMessages()[1].Content() != "current request" {
+		t.Errorf("the current request must be kept")
+	}
+}
+
+func TestTrimToTokens_StopsOnceUnderBudget(t *testing.T) {
+	s := NewMessageState()
+	s.AddMessage(message.NewChatMessage(message.MessageTypeUser, strings.Repeat("a", 400)))
+	s.AddMessage(message.NewChatMessage(message.MessageTypeAssistant, "short"))
+	s.AddMessage(message.NewChatMessage(message.MessageTypeUser, "now"))
+
+	if removed := s.TrimToTokens(100); removed != 1 {
+		t.Errorf("expected only the oversized oldest message to be removed, got %d", removed)
+	}
+	if removed := s.TrimToTokens(0); removed != 0 {
+		t.Errorf("a zero budget should be a no-op, got %d", removed)
+	}
+}
+
+func TestTrimToTokens_TruncatesHugeCurrentToolResult(t *testing.T) {
+	s := NewMessageState()
+	s.AddMessage(message.NewChatMessage(message.MessageTypeUser, "read the log"))
+	call := message.NewToolCallMessage("Read", message.ToolArgumentValues{"file_path": "huge.log"})
+	s.AddMessage(call)
+	s.AddMessage(message.NewToolResultMessage(call.ID(), strings.Repeat("log line\n", 10000), ""))
+
+	s.TrimToTokens(1000)
+	messages := s.GetMessages()
+	if len(messages) != 3 {
+		t.Fatalf("expected the current request to be kept, got %d messages", len(messages))
+	}
+	result := messages[2]
+	if result.ID() != call.ID() || !strings.Contains(result.Content(), "[Tool result truncated from 90000 characters") {
+		t.Errorf("expected a truncated result for the same call, got %q", result.Content()[:min(len(result.Content()), 80)])
+	}
+	if len(result.Content()) > 3000 {
+		t.Errorf("result still too large: %d characters", len(result.Content()))
+	}
+}
@@ -151,6 +151,44 @@ func (c *MessageState) GetValidConversationHistory(maxMessages int) []message.Me
 	return validMessages
 }
 
+// RemoveUnpairedToolCalls removes tool calls that never received a result, such
+// as a call whose execution was interrupted. Providers reject a history containing
+// them, so this keeps the next request valid. It returns the number removed.
+func (c *MessageState) RemoveUnpairedToolCalls() int {
+	answered := make(map[string]bool)
+	for _, msg := range c.Messages {
+		if msg.Type() == message.MessageTypeToolResult {
+			answered[msg.ID()] = true
+		}
+	}
+
+	kept := make([]message.Message, 0, len(c.Messages))
+	removed := 0
+	for _, msg := range c.Messages {
+		if msg.Type() == message.MessageTypeToolCall && !answered[msg.ID()] {
+			removed++
+			continue
+		}
+		kept = append(kept, msg)
+	}
+	c.Messages = kept
+	return removed
+}
+
+// RemoveLastTurn removes the latest user request and every message after it
+// (the assistant's answer and its tool calls), so the request can be retried
+// from the preceding history. It returns the number of messages removed.
+func (c *MessageState) RemoveLastTurn() int {
+	for i := len(c.Messages) - 1; i >= 0; i-- {
+		if c.Messages[i].Type() == message.MessageTypeUser {
+			removed := len(c.Messages) - i
+			c.Messages = c.Messages[:i]
+			return removed
+		}
+	}
+	return 0
+}
+
 // SaveToFile saves the message state using the repository
 func (c *MessageState) SaveToFile() error {
 	if c.historyRepo == nil {
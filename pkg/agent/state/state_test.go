@@ -324,3 +324,51 @@ func TestMessageStateBasicOperations(t *testing.T) {
 		t.Fatal("Tool result type not preserved")
 	}
 }
+
+func TestRemoveUnpairedToolCalls(t *testing.T) {
+	state := NewMessageState()
+	answered := message.NewToolCallMessage("read", message.ToolArgumentValues{})
+	interrupted := message.NewToolCallMessage("write", message.ToolArgumentValues{})
+
+	state.AddMessage(message.NewChatMessage(message.MessageTypeUser, "Hello"))
+	state.AddMessage(answered)
+	state.AddMessage(message.NewToolResultMessage(answered.ID(), "ok", ""))
+	state.AddMessage(interrupted)
+
+	if removed := state.RemoveUnpairedToolCalls(); removed != 1 {
+		t.Fatalf("expected 1 removed tool call, got %d", removed)
+	}
+	messages := state.GetMessages()
+	if len(messages) != 3 || messages[1].ID() != answered.ID() || messages[2].Type() != message.MessageTypeToolResult {
+		t.Errorf("unexpected history after removal: %d messages", len(messages))
+	}
+	if removed := state.RemoveUnpairedToolCalls(); removed != 0 {
+		t.Errorf("expected nothing left to remove, got %d", removed)
+	}
+}
+
+func TestRemoveLastTurn(t *testing.T) {
+	state := NewMessageState()
+	call := message.NewToolCallMessage("read", message.ToolArgumentValues{})
+
+	state.AddMessage(message.NewChatMessage(message.MessageTypeUser, "first"))
+	state.AddMessage(message.NewChatMessage(message.MessageTypeAssistant, "first answer"))
+	state.AddMessage(message.NewChatMessage(message.MessageTypeUser, "second"))
+	state.AddMessage(call)
+	state.AddMessage(message.NewToolResultMessage(call.ID(), "ok", ""))
+	state.AddMessage(message.NewAlignerSystemMessage("keep going"))
+	state.AddMessage(message.NewChatMessage(message.MessageTypeAssistant, "second answer"))
+
+	if removed := state.RemoveLastTurn(); removed != 5 {
+		t.Fatalf("expected 5 removed messages, got %d", removed)
+	}
+	messages := state.GetMessages()
+	if len(messages) != 2 || messages[1].Content() != "first answer" {
+		t.Errorf("unexpected history after removal: %d messages", len(messages))
+	}
+
+	state.RemoveLastTurn()
+	if removed := state.RemoveLastTurn(); removed != 0 || len(state.GetMessages()) != 0 {
+		t.Errorf("expected an empty history with nothing left to remove, got %d removed", removed)
+	}
+}
@@ -10,11 +10,15 @@ import (
 	"github.com/anthropics/anthropic-sdk-go"
 	"github.com/anthropics/anthropic-sdk-go/option"
 	"github.com/fpt/go-gennai-cli/pkg/agent/domain"
+	"github.com/fpt/go-gennai-cli/pkg/httpclient"
+	pkgLogger "github.com/fpt/go-gennai-cli/pkg/logger"
 	"github.com/fpt/go-gennai-cli/pkg/message"
 )
 
 const (
-	defaultMaxTokens = 8192
+	defaultMaxTokens      = 8192
+	defaultThinkingBudget = 2048 // thinking tokens when no budget is configured
+	minThinkingBudget     = 1024 // smallest budget the API accepts
 )
 
 // AnthropicCore contains shared Anthropic client resources and core functionality
@@ -23,6 +27,9 @@ type AnthropicCore struct {
 	client    *anthropic.Client
 	model     string
 	maxTokens int
+	retry     RetryConfig
+
+	thinkingBudget int // tokens for extended thinking (0 = defaultThinkingBudget)
 }
 
 // NewAnthropicCore creates a new Anthropic core with shared resources
@@ -37,8 +44,11 @@ func NewAnthropicCoreWithTokens(model string, maxTokens int) (*AnthropicCore, er
 		return nil, fmt.Errorf("ANTHROPIC_API_KEY environment variable not set")
 	}
 
+	// Retries are handled by withRetry so that streams are re-established cleanly
 	client := anthropic.NewClient(
 		option.WithAPIKey(apiKey),
+		option.WithMaxRetries(0),
+		option.WithHTTPClient(httpclient.New(0)),
 	)
 
 	// Use default if maxTokens is 0 or negative
@@ -51,9 +61,44 @@ func NewAnthropicCoreWithTokens(model string, maxTokens int) (*AnthropicCore, er
 		client:    &client,
 		model:     model,
 		maxTokens: maxTokens,
+		retry:     DefaultRetryConfig(),
 	}, nil
 }
 
+// SetThinkingBudget sets the tokens available for extended thinking
+// (domain.ThinkingBudgetConfigurator). 0 restores the default.
+func (c *AnthropicCore) SetThinkingBudget(tokens int) {
+	c.thinkingBudget = tokens
+	budget, ok := c.thinkingBudgetTokens()
+	switch {
+	case !ok:
+		anthropicLogger.Warn("Thinking is disabled: max_tokens leaves no room for the minimum thinking budget",
+			"max_tokens", c.maxTokens, "min_budget", minThinkingBudget)
+	case tokens > 0 && int(budget) != tokens:
+		anthropicLogger.Warn("Adjusted thinking budget to the range the API accepts",
+			"requested", tokens, "budget", budget, "max_tokens", c.maxTokens)
+	}
+}
+
+// thinkingBudgetTokens returns the configured budget raised to the API minimum
+// and kept below max_tokens, which also has to cover the answer. It reports
+// false when max_tokens is no larger than the minimum budget, so thinking
+// can't be enabled at all.
+func (c *AnthropicCore) thinkingBudgetTokens() (int64, bool) {
+	if c.maxTokens <= minThinkingBudget {
+		return 0, false
+	}
+	budget := c.thinkingBudget
+	if budget <= 0 {
+		budget = defaultThinkingBudget
+	}
+	budget = max(budget, minThinkingBudget)
+	if budget >= c.maxTokens {
+		budget = max(c.maxTokens-minThinkingBudget, minThinkingBudget)
+	}
+	return int64(budget), true
+}
+
 // AnthropicClient handles communication with Claude models
 // Implements domain.ToolCallingLLM interfaces for tool calling
 type AnthropicClient struct {
@@ -64,6 +109,9 @@ type AnthropicClient struct {
 	lastUsage message.TokenUsage
 	sessionID string
 	cacheOpts domain.ModelSideCacheOptions
+
+	// Receives text deltas while set (domain.ResponseStreamer)
+	responseChan chan<- string
 }
 
 // NewAnthropicClient creates a new Anthropic client with tool calling and thinking capabilities
@@ -84,6 +132,20 @@ func NewAnthropicClientWithTokens(model string, maxTokens int) (domain.ToolCalli
 	}, nil
 }
 
+// NewAnthropicClientWithRetry creates a new Anthropic client with configurable maxTokens and
+// retry behavior for transient API errors
+func NewAnthropicClientWithRetry(model string, maxTokens int, retry RetryConfig) (domain.ToolCallingLLM, error) {
+	core, err := NewAnthropicCoreWithTokens(model, maxTokens)
+	if err != nil {
+		return nil, err
+	}
+	core.retry = retry.withDefaults()
+
+	return &AnthropicClient{
+		AnthropicCore: core,
+	}, nil
+}
+
 // NewAnthropicClientFromCore creates a new Anthropic client from shared core
 func NewAnthropicClientFromCore(core *AnthropicCore) domain.ToolCallingLLM {
 	return &AnthropicClient{
@@ -116,6 +178,27 @@ func (c *AnthropicClient) ConfigureModelSideCache(opts domain.ModelSideCacheOpti
 	c.cacheOpts = opts
 }
 
+// ResponseStreamer implementation
+func (c *AnthropicClient) SetResponseChannel(ch chan<- string) { c.responseChan = ch }
+
+// captureUsage records token usage from a response. With prompt caching,
+// InputTokens excludes the cached prefix, so cache reads and writes are added
+// back to report the full prompt size, and cache activity is logged.
+func (c *AnthropicClient) captureUsage(usage anthropic.Usage) {
+	input := usage.InputTokens + usage.CacheReadInputTokens + usage.CacheCreationInputTokens
+	c.lastUsage = message.TokenUsage{
+		InputTokens:  int(input),
+		OutputTokens: int(usage.OutputTokens),
+		TotalTokens:  int(input + usage.OutputTokens),
+	}
+	if usage.CacheReadInputTokens > 0 || usage.CacheCreationInputTokens > 0 {
+		anthropicLogger.DebugWithIntention(pkgLogger.IntentionStatus, "Prompt cache usage",
+			"cache_read_tokens", usage.CacheReadInputTokens,
+			"cache_creation_tokens", usage.CacheCreationInputTokens,
+			"uncached_input_tokens", usage.InputTokens)
+	}
+}
+
 // IsToolCapable checks if the Anthropic client supports native tool calling
 func (c *AnthropicClient) IsToolCapable() bool {
 	// Anthropic API always supports native tool calling
@@ -149,15 +232,20 @@ func (c *AnthropicClient) ChatWithToolChoice(ctx context.Context, messages []mes
 		anthropicToolChoice := convertToolChoiceToAnthropic(toolChoice)
 		messageParams.ToolChoice = anthropicToolChoice
 	}
+	if c.cacheOpts.PromptCachingEnabled {
+		applyPromptCacheBreakpoints(&messageParams)
+	}
 
-	// Determine if we should enable thinking (only for supported models)
-	shouldEnableThinking := supportsThinking(c.model)
+	// Determine if we should enable thinking (only when requested and supported)
+	shouldEnableThinking := enableThinking && supportsThinking(c.model)
 
-	// Add thinking configuration if supported
+	// Add thinking configuration if requested and supported
+	budget, budgetFits := c.thinkingBudgetTokens()
+	shouldEnableThinking = shouldEnableThinking && budgetFits
 	if shouldEnableThinking {
 		messageParams.Thinking = anthropic.ThinkingConfigParamUnion{
 			OfEnabled: &anthropic.ThinkingConfigEnabledParam{
-				BudgetTokens: int64(2048), // Set a reasonable thinking budget (minimum 1024)
+				BudgetTokens: budget,
 			},
 		}
 	}
@@ -198,15 +286,20 @@ func (c *AnthropicClient) Chat(ctx context.Context, messages []message.Message,
 		Model:     claudeModel,
 		Tools:     tools,
 	}
+	if c.cacheOpts.PromptCachingEnabled {
+		applyPromptCacheBreakpoints(&messageParams)
+	}
 
 	// Determine if we should enable thinking (only for supported models)
 	shouldEnableThinking := enableThinking && supportsThinking(c.model)
 
 	// Add thinking configuration if requested and supported
+	budget, budgetFits := c.thinkingBudgetTokens()
+	shouldEnableThinking = shouldEnableThinking && budgetFits
 	if shouldEnableThinking {
 		messageParams.Thinking = anthropic.ThinkingConfigParamUnion{
 			OfEnabled: &anthropic.ThinkingConfigEnabledParam{
-				BudgetTokens: int64(2048), // Set a reasonable thinking budget (minimum 1024)
+				BudgetTokens: budget,
 			},
 		}
 	}
@@ -215,69 +308,46 @@ func (c *AnthropicClient) Chat(ctx context.Context, messages []message.Message,
 	return c.chatWithStreaming(ctx, messageParams, shouldEnableThinking, enableThinking, thinkingChan)
 }
 
-// chatWithStreaming handles streaming generation with progressive thinking display using Message.Accumulate pattern
-func (c *AnthropicClient) chatWithStreaming(ctx context.Context, messageParams anthropic.MessageNewParams, showThinking bool, enableThinking bool, thinkingChan chan<- string) (message.Message, error) {
-	// Create streaming request
-	stream := c.client.Messages.NewStreaming(ctx, messageParams)
-
-	// Use Message.Accumulate pattern for proper streaming handling
-	var acc anthropic.Message
-	var thinkingBuilder strings.Builder
-	var signatureBuilder strings.Builder
+// streamResult holds the accumulated output of a single streaming attempt
+type streamResult struct {
+	acc       anthropic.Message
+	thinking  string
+	signature string
+}
 
-	// Process streaming events
-	for stream.Next() {
-		event := stream.Current()
+// chatWithStreaming handles streaming generation with progressive thinking display using Message.Accumulate pattern.
+// Transient failures are retried with backoff; each attempt re-opens the stream from scratch.
+func (c *AnthropicClient) chatWithStreaming(ctx context.Context, messageParams anthropic.MessageNewParams, showThinking bool, enableThinking bool, thinkingChan chan<- string) (message.Message, error) {
+	var thinkingOut chan<- string
+	if enableThinking {
+		thinkingOut = thinkingChan
+	}
 
-		// Accumulate the event into the message
-		if err := acc.Accumulate(event); err != nil {
-			return nil, fmt.Errorf("failed to accumulate streaming event: %w", err)
+	thinkingEmitted, textEmitted := false, false
+	result, err := withRetry(ctx, c.retry, func(attempt int) (*streamResult, error) {
+		// Content already shown by a failed attempt must not be shown again
+		out, responseOut := thinkingOut, c.responseChan
+		if thinkingEmitted {
+			out = nil
 		}
-
-		// Handle thinking display for progressive feedback
-		switch eventData := event.AsAny().(type) {
-		case anthropic.ContentBlockDeltaEvent:
-			if delta, ok := eventData.Delta.AsAny().(anthropic.ThinkingDelta); ok {
-				// Thinking content - show progressively
-				if delta.Thinking != "" && showThinking {
-					// Send thinking content to channel if enabled
-					if enableThinking && thinkingChan != nil {
-						message.SendThinkingContent(thinkingChan, delta.Thinking)
-					}
-
-					// Accumulate thinking content
-					thinkingBuilder.WriteString(delta.Thinking)
-				}
-			} else if delta, ok := eventData.Delta.AsAny().(anthropic.SignatureDelta); ok {
-				// Signature content - accumulate but don't display
-				if delta.Signature != "" {
-					signatureBuilder.WriteString(delta.Signature)
-				}
-			}
-
-		case anthropic.ContentBlockStartEvent:
-			if block, ok := eventData.ContentBlock.AsAny().(anthropic.ThinkingBlock); ok {
-				// Thinking block started - send initial thinking content if present
-				if block.Thinking != "" && showThinking {
-					// Send thinking content to channel if enabled
-					if enableThinking && thinkingChan != nil {
-						message.SendThinkingContent(thinkingChan, block.Thinking)
-					}
-					thinkingBuilder.WriteString(block.Thinking)
-				}
-			}
+		if textEmitted {
+			responseOut = nil
 		}
+		res, emitted, err := c.streamOnce(ctx, messageParams, showThinking, out, responseOut)
+		thinkingEmitted = thinkingEmitted || emitted.thinking
+		textEmitted = textEmitted || emitted.text
+		return res, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("anthropic streaming error: %w", err)
 	}
 
-	// Check for streaming errors
-	if stream.Err() != nil {
-		return nil, fmt.Errorf("anthropic streaming error: %w", stream.Err())
+	// Signal end of thinking if we accumulated or displayed thinking content
+	if (result.thinking != "" || thinkingEmitted) && thinkingOut != nil {
+		message.EndThinking(thinkingOut)
 	}
 
-	// Signal end of thinking if we accumulated thinking content
-	if thinkingBuilder.Len() > 0 && enableThinking && thinkingChan != nil {
-		message.EndThinking(thinkingChan)
-	}
+	acc := result.acc
 
 	// Now process the accumulated message like the non-streaming version
 	if len(acc.Content) == 0 {
@@ -304,9 +374,11 @@ func (c *AnthropicClient) chatWithStreaming(ctx context.Context, messageParams a
 		}
 	}
 
+	c.captureUsage(acc.Usage)
+
 	// Get accumulated thinking content and signature from streaming
-	finalThinking := thinkingBuilder.String()
-	finalSignature := signatureBuilder.String()
+	finalThinking := result.thinking
+	finalSignature := result.signature
 
 	// If we have tool calls, return a batch when multiple; single otherwise
 	if len(toolCalls) > 0 {
@@ -356,19 +428,91 @@ func (c *AnthropicClient) chatWithStreaming(ctx context.Context, messageParams a
 		return message.NewToolCallBatch(calls), nil
 	}
 
-	// Capture token usage if available in accumulated message
-	// Anthropic Usage includes: InputTokens, OutputTokens, CacheCreationInputTokens, CacheReadInputTokens
-	// We record input/output/total only for quick visibility; cache details can be added later if needed.
-	c.lastUsage = message.TokenUsage{
-		InputTokens:  int(acc.Usage.InputTokens),
-		OutputTokens: int(acc.Usage.OutputTokens),
-		TotalTokens:  int(acc.Usage.InputTokens + acc.Usage.OutputTokens),
-	}
-
 	// Create response message with thinking content if available
-	if thinkingBuilder.Len() > 0 {
+	if finalThinking != "" {
 		return message.NewChatMessageWithThinking(message.MessageTypeAssistant, content, finalThinking), nil
 	}
 
 	return message.NewChatMessage(message.MessageTypeAssistant, content), nil
 }
+
+// streamEmitted records which content a streaming attempt has already shown
+type streamEmitted struct {
+	thinking bool // sent to the thinking channel
+	text     bool // sent to the response channel
+}
+
+// streamOnce performs a single streaming request. It reports which content was
+// sent to thinkingOut and responseOut so callers can avoid re-emitting it on retry.
+func (c *AnthropicClient) streamOnce(ctx context.Context, messageParams anthropic.MessageNewParams, showThinking bool, thinkingOut, responseOut chan<- string) (*streamResult, streamEmitted, error) {
+	// Create streaming request
+	stream := c.client.Messages.NewStreaming(ctx, messageParams)
+	defer stream.Close()
+
+	// Use Message.Accumulate pattern for proper streaming handling
+	var acc anthropic.Message
+	var thinkingBuilder strings.Builder
+	var signatureBuilder strings.Builder
+	var emitted streamEmitted
+
+	// Process streaming events
+	for stream.Next() {
+		event := stream.Current()
+
+		// Accumulate the event into the message
+		if err := acc.Accumulate(event); err != nil {
+			return nil, emitted, fmt.Errorf("failed to accumulate streaming event: %w", err)
+		}
+
+		// Handle thinking display for progressive feedback
+		switch eventData := event.AsAny().(type) {
+		case anthropic.ContentBlockDeltaEvent:
+			if delta, ok := eventData.Delta.AsAny().(anthropic.ThinkingDelta); ok {
+				// Thinking content - show progressively
+				if delta.Thinking != "" && showThinking {
+					if thinkingOut != nil {
+						message.SendThinkingContent(thinkingOut, delta.Thinking)
+						emitted.thinking = true
+					}
+
+					// Accumulate thinking content
+					thinkingBuilder.WriteString(delta.Thinking)
+				}
+			} else if delta, ok := eventData.Delta.AsAny().(anthropic.SignatureDelta); ok {
+				// Signature content - accumulate but don't display
+				if delta.Signature != "" {
+					signatureBuilder.WriteString(delta.Signature)
+				}
+			} else if delta, ok := eventData.Delta.AsAny().(anthropic.TextDelta); ok {
+				// Answer text - stream it when a response channel is set
+				if delta.Text != "" && responseOut != nil {
+					message.SendResponseContent(ctx, responseOut, delta.Text)
+					emitted.text = true
+				}
+			}
+
+		case anthropic.ContentBlockStartEvent:
+			if block, ok := eventData.ContentBlock.AsAny().(anthropic.ThinkingBlock); ok {
+				// Thinking block started - send initial thinking content if present
+				if block.Thinking != "" && showThinking {
+					if thinkingOut != nil {
+						message.SendThinkingContent(thinkingOut, block.Thinking)
+						emitted.thinking = true
+					}
+					thinkingBuilder.WriteString(block.Thinking)
+				}
+			}
+		}
+	}
+
+	// Check for streaming errors
+	if err := stream.Err(); err != nil {
+		return nil, emitted, err
+	}
+
+	return &streamResult{
+		acc:       acc,
+		thinking:  thinkingBuilder.String(),
+		signature: signatureBuilder.String(),
+	}, emitted, nil
+}
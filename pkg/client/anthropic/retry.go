@@ -0,0 +1,149 @@
+package anthropic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	pkgLogger "github.com/fpt/go-gennai-cli/pkg/logger"
+)
+
+const (
+	defaultRetryMaxAttempts = 3
+	defaultRetryBaseDelay   = 1 * time.Second
+	maxRetryDelay           = 30 * time.Second
+
+	// statusOverloaded is returned by the Anthropic API when the service is overloaded
+	statusOverloaded = 529
+)
+
+var anthropicLogger = pkgLogger.NewComponentLogger("anthropic-client")
+
+// RetryConfig controls how transient API errors are retried
+type RetryConfig struct {
+	MaxAttempts int           // total attempts including the first (0 = default)
+	BaseDelay   time.Duration // initial backoff delay, doubled per attempt (0 = default)
+}
+
+// DefaultRetryConfig returns the retry configuration used when none is specified
+func DefaultRetryConfig() RetryConfig {
+	return RetryConfig{
+		MaxAttempts: defaultRetryMaxAttempts,
+		BaseDelay:   defaultRetryBaseDelay,
+	}
+}
+
+// withDefaults fills zero values with defaults
+func (r RetryConfig) withDefaults() RetryConfig {
+	if r.MaxAttempts <= 0 {
+		r.MaxAttempts = defaultRetryMaxAttempts
+	}
+	if r.BaseDelay <= 0 {
+		r.BaseDelay = defaultRetryBaseDelay
+	}
+	return r
+}
+
+// RetryError is returned when all retry attempts for a request have failed
+type RetryError struct {
+	Attempts int
+	Err      error
+}
+
+func (e *RetryError) Error() string {
+	return fmt.Sprintf("anthropic request failed after %d attempts: %v", e.Attempts, e.Err)
+}
+
+func (e *RetryError) Unwrap() error { return e.Err }
+
+// isRetryableError reports whether err is a transient failure worth retrying:
+// rate limits (429), server errors (5xx), overload (529) and network timeouts.
+// Client-side validation errors (400, 401, 403, 404, ...) are never retried.
+func isRetryableError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var apiErr *anthropic.Error
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+			http.StatusServiceUnavailable, http.StatusGatewayTimeout, statusOverloaded:
+			return true
+		}
+		return false
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
+	// Errors delivered as SSE "error" events mid-stream carry no status code
+	msg := err.Error()
+	for _, marker := range []string{"overloaded_error", "api_error", "rate_limit_error"} {
+		if strings.Contains(msg, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// backoffDelay returns the delay before the given retry (1-based), using
+// exponential backoff with jitter in [delay/2, delay).
+func backoffDelay(base time.Duration, retry int) time.Duration {
+	delay := base << (retry - 1)
+	if delay <= 0 || delay > maxRetryDelay {
+		delay = maxRetryDelay
+	}
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// withRetry runs fn until it succeeds, returns a non-retryable error, or the
+// attempts are exhausted. fn receives the 1-based attempt number.
+func withRetry[T any](ctx context.Context, cfg RetryConfig, fn func(attempt int) (T, error)) (T, error) {
+	cfg = cfg.withDefaults()
+
+	var zero T
+	var lastErr error
+	for attempt := 1; attempt <= cfg.MaxAttempts; attempt++ {
+		result, err := fn(attempt)
+		if err == nil {
+			return result, nil
+		}
+		lastErr = err
+
+		if !isRetryableError(err) {
+			return zero, err
+		}
+		if attempt == cfg.MaxAttempts {
+			break
+		}
+
+		delay := backoffDelay(cfg.BaseDelay, attempt)
+		anthropicLogger.DebugWithIntention(pkgLogger.IntentionWarning, "Transient Anthropic API error, retrying",
+			"attempt", attempt, "max_attempts", cfg.MaxAttempts, "delay", delay, "error", err)
+
+		select {
+		case <-ctx.Done():
+			return zero, ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+
+	return zero, &RetryError{Attempts: cfg.MaxAttempts, Err: lastErr}
+}
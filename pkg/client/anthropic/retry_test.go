@@ -0,0 +1,115 @@
+package anthropic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/anthropics/anthropic-sdk-go"
+)
+
+// apiError builds an API error with the request/response populated so Error() is safe to call
+func apiError(status int) *anthropic.Error {
+	return &anthropic.Error{
+		StatusCode: status,
+		Request:    httptest.NewRequest(http.MethodPost, "https://api.anthropic.com/v1/messages", nil),
+		Response:   &http.Response{StatusCode: status},
+	}
+}
+
+func TestIsRetryableError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{"nil", nil, false},
+		{"rate limited", apiError(429), true},
+		{"internal server error", apiError(500), true},
+		{"overloaded", apiError(529), true},
+		{"bad request", apiError(400), false},
+		{"unauthorized", apiError(401), false},
+		{"wrapped overloaded", fmt.Errorf("wrap: %w", apiError(529)), true},
+		{"stream overloaded event", errors.New(`received error while streaming: {"type":"overloaded_error"}`), true},
+		{"context canceled", context.Canceled, false},
+		{"other", errors.New("invalid tool schema"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isRetryableError(tt.err); got != tt.expected {
+				t.Errorf("isRetryableError(%v) = %v, want %v", tt.err, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestWithRetry(t *testing.T) {
+	cfg := RetryConfig{MaxAttempts: 3, BaseDelay: time.Millisecond}
+
+	t.Run("succeeds after transient failures", func(t *testing.T) {
+		calls := 0
+		got, err := withRetry(context.Background(), cfg, func(attempt int) (string, error) {
+			calls++
+			if attempt < 3 {
+				return "", apiError(529)
+			}
+			return "ok", nil
+		})
+		if err != nil || got != "ok" {
+			t.Fatalf("expected ok, got %q, %v", got, err)
+		}
+		if calls != 3 {
+			t.Errorf("expected 3 calls, got %d", calls)
+		}
+	})
+
+	t.Run("does not retry validation errors", func(t *testing.T) {
+		calls := 0
+		_, err := withRetry(context.Background(), cfg, func(attempt int) (string, error) {
+			calls++
+			return "", apiError(400)
+		})
+		if err == nil {
+			t.Fatal("expected error")
+		}
+		if calls != 1 {
+			t.Errorf("expected 1 call, got %d", calls)
+		}
+		var retryErr *RetryError
+		if errors.As(err, &retryErr) {
+			t.Errorf("non-retryable error should not be wrapped in RetryError")
+		}
+	})
+
+	t.Run("reports attempts when exhausted", func(t *testing.T) {
+		_, err := withRetry(context.Background(), cfg, func(attempt int) (string, error) {
+			return "", apiError(500)
+		})
+		var retryErr *RetryError
+		if !errors.As(err, &retryErr) {
+			t.Fatalf("expected RetryError, got %v", err)
+		}
+		if retryErr.Attempts != 3 {
+			t.Errorf("expected 3 attempts, got %d", retryErr.Attempts)
+		}
+	})
+}
+
+func TestBackoffDelay(t *testing.T) {
+	base := 100 * time.Millisecond
+	for retry := 1; retry <= 3; retry++ {
+		full := base << (retry - 1)
+		d := backoffDelay(base, retry)
+		if d < full/2 || d > full {
+			t.Errorf("retry %d: delay %v outside [%v, %v]", retry, d, full/2, full)
+		}
+	}
+	if d := backoffDelay(time.Second, 20); d > maxRetryDelay {
+		t.Errorf("delay %v exceeds cap %v", d, maxRetryDelay)
+	}
+}
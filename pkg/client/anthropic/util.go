@@ -1,11 +1,12 @@
 package anthropic
 
 import (
-	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/anthropics/anthropic-sdk-go"
 	"github.com/fpt/go-gennai-cli/pkg/agent/domain"
+	"github.com/fpt/go-gennai-cli/pkg/client/models"
 	"github.com/fpt/go-gennai-cli/pkg/message"
 	llmmsg "github.com/fpt/go-gennai-cli/pkg/message"
 )
@@ -31,38 +32,27 @@ func getAnthropicModel(model string) anthropic.Model {
 	return anthropic.ModelClaude3_7SonnetLatest
 }
 
-// supportsThinking checks if the model supports thinking functionality
+// supportsThinking checks if the model supports thinking functionality.
+// Models missing from the registry are assumed to support it.
 func supportsThinking(model string) bool {
-	switch model {
-	case "claude-opus-4-20250514":
-		return true
-	case "claude-sonnet-4-20250514":
-		return true
-	case "claude-3-7-sonnet-latest":
-		return true
-	case "claude-3-5-haiku-latest":
-		return false // Haiku doesn't support thinking
+	if info, ok := models.Lookup("anthropic", model); ok {
+		return info.Thinking
 	}
-
-	// Default to true for Sonnet models (conservative approach)
 	return true
 }
 
-// getModelContextWindow returns a conservative approximation of the
-// model's context window (input token capacity). Anthropic models
-// generally provide large windows (~200k). This value is used for
-// utilization reporting only.
+// defaultContextWindow is a conservative approximation of the context window
+// of models missing from the registry. Anthropic models generally provide
+// large windows (~200k). It is used for utilization reporting only.
+const defaultContextWindow = 200000
+
+// getModelContextWindow returns the model's context window (input token
+// capacity) from the registry
 func getModelContextWindow(model string) int {
-	switch model {
-	case "claude-opus-4-20250514",
-		"claude-sonnet-4-20250514",
-		"claude-3-7-sonnet-latest",
-		"claude-3-5-haiku-latest":
-		return 200000
-	default:
-		// Conservative default for unknown/alias names
-		return 200000
+	if info, ok := models.Lookup("anthropic", model); ok {
+		return info.ContextWindow
 	}
+	return defaultContextWindow
 }
 
 // convertToolChoiceToAnthropic converts domain ToolChoice to Anthropic format
@@ -154,7 +144,16 @@ func unsanitizeToolNameFromAnthropic(sanitizedName string) string {
 func convertToolsToAnthropic(tools map[message.ToolName]message.Tool) []anthropic.ToolUnionParam {
 	var anthropicTools []anthropic.ToolUnionParam
 
-	for _, tool := range tools {
+	// Sort by name so the tool list (the start of the prompt) is identical across
+	// requests, which prompt caching requires
+	names := make([]message.ToolName, 0, len(tools))
+	for name := range tools {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		tool := tools[name]
 		// Create properties from tool arguments using enhanced schema conversion
 		properties := make(map[string]any)
 		var required []string
@@ -186,6 +185,28 @@ func convertToolsToAnthropic(tools map[message.ToolName]message.Tool) []anthropi
 	return anthropicTools
 }
 
+// systemMessagePrefix starts the user messages that carry system messages
+const systemMessagePrefix = "System: "
+
+// applyPromptCacheBreakpoints marks the stable prompt prefix for Anthropic
+// prompt caching: the last tool definition and the last system message (the
+// scenario prompt), which precede the changing conversation
+func applyPromptCacheBreakpoints(params *anthropic.MessageNewParams) {
+	if n := len(params.Tools); n > 0 && params.Tools[n-1].OfTool != nil {
+		params.Tools[n-1].OfTool.CacheControl = anthropic.NewCacheControlEphemeralParam()
+	}
+	for i := len(params.Messages) - 1; i >= 0; i-- {
+		msg := params.Messages[i]
+		if msg.Role != anthropic.MessageParamRoleUser || len(msg.Content) == 0 {
+			continue
+		}
+		if text := msg.Content[0].OfText; text != nil && strings.HasPrefix(text.Text, systemMessagePrefix) {
+			text.CacheControl = anthropic.NewCacheControlEphemeralParam()
+			return
+		}
+	}
+}
+
 // convertArgumentToAnthropicProperty converts a ToolArgument to Anthropic property schema
 // This provides enhanced schema conversion similar to Ollama's dynamic approach
 func convertArgumentToAnthropicProperty(arg message.ToolArgument) map[string]any {
@@ -263,6 +284,21 @@ func convertArgumentToAnthropicProperty(arg message.ToolArgument) map[string]any
 	return property
 }
 
+// imageMediaType detects the format of Base64 image data from its leading
+// bytes, defaulting to JPEG
+func imageMediaType(imageData string) string {
+	switch {
+	case strings.HasPrefix(imageData, "iVBORw0KGgo"):
+		return "image/png"
+	case strings.HasPrefix(imageData, "R0lGOD"):
+		return "image/gif"
+	case strings.HasPrefix(imageData, "UklGR"):
+		return "image/webp"
+	default:
+		return "image/jpeg"
+	}
+}
+
 // toAnthropicMessages converts neutral messages to Anthropic format
 func toAnthropicMessages(messages []message.Message) []anthropic.MessageParam {
 	var anthropicMessages []anthropic.MessageParam
@@ -279,12 +315,7 @@ func toAnthropicMessages(messages []message.Message) []anthropic.MessageParam {
 
 				// Add image blocks first (Anthropic recommendation)
 				for _, imageData := range images {
-					// Detect image format from Base64 data
-					mediaType := "image/jpeg" // Default to JPEG format
-					if strings.HasPrefix(imageData, "iVBORw0KGgo") {
-						mediaType = "image/png"
-					}
-					imageBlock := anthropic.NewImageBlockBase64(mediaType, imageData)
+					imageBlock := anthropic.NewImageBlockBase64(imageMediaType(imageData), imageData)
 					contentBlocks = append(contentBlocks, imageBlock)
 				}
 
@@ -328,7 +359,7 @@ func toAnthropicMessages(messages []message.Message) []anthropic.MessageParam {
 			anthropicMessages = append(anthropicMessages, anthropic.NewAssistantMessage(contentBlocks...))
 		case message.MessageTypeSystem:
 			// System messages in Anthropic are handled differently - convert to user message
-			anthropicMessages = append(anthropicMessages, anthropic.NewUserMessage(anthropic.NewTextBlock(fmt.Sprintf("System: %s", msg.Content()))))
+			anthropicMessages = append(anthropicMessages, anthropic.NewUserMessage(anthropic.NewTextBlock(systemMessagePrefix+msg.Content())))
 		case message.MessageTypeToolCall:
 			if toolCallMsg, ok := msg.(*llmmsg.ToolCallMessage); ok {
 				// When thinking is enabled globally and we have thinking content,
@@ -373,6 +404,13 @@ func toAnthropicMessages(messages []message.Message) []anthropic.MessageParam {
 						},
 					},
 				}
+				// Images a tool returned (e.g. Read on a PNG) go in the same block
+				for _, imageData := range toolResultMsg.Images() {
+					imageBlock := anthropic.NewImageBlockBase64(imageMediaType(imageData), imageData)
+					toolResultParam.Content = append(toolResultParam.Content, anthropic.ToolResultBlockParamContentUnion{
+						OfImage: imageBlock.OfImage,
+					})
+				}
 				toolResult := anthropic.ContentBlockParamUnion{
 					OfToolResult: &toolResultParam,
 				}
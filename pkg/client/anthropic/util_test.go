@@ -101,6 +101,36 @@ func TestSupportsThinking(t *testing.T) {
 	}
 }
 
+func TestThinkingBudgetTokens(t *testing.T) {
+	tests := []struct {
+		name      string
+		budget    int
+		maxTokens int
+		expected  int64
+		disabled  bool
+	}{
+		{name: "unset uses the default", budget: 0, maxTokens: 8192, expected: defaultThinkingBudget},
+		{name: "configured budget is used", budget: 4000, maxTokens: 8192, expected: 4000},
+		{name: "raised to the API minimum", budget: 100, maxTokens: 8192, expected: minThinkingBudget},
+		{name: "kept below max_tokens", budget: 16000, maxTokens: 8192, expected: 8192 - minThinkingBudget},
+		{name: "disabled when max_tokens leaves no room", budget: 0, maxTokens: minThinkingBudget, disabled: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core := &AnthropicCore{maxTokens: tt.maxTokens}
+			core.SetThinkingBudget(tt.budget)
+			got, ok := core.thinkingBudgetTokens()
+			if ok == tt.disabled {
+				t.Fatalf("thinkingBudgetTokens() ok = %v, want %v", ok, !tt.disabled)
+			}
+			if ok && got != tt.expected {
+				t.Errorf("thinkingBudgetTokens() = %d, want %d", got, tt.expected)
+			}
+		})
+	}
+}
+
 func TestConvertToolChoiceToAnthropic(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -572,3 +602,44 @@ func TestToAnthropicMessages(t *testing.T) {
 		})
 	}
 }
+
+func TestApplyPromptCacheBreakpoints(t *testing.T) {
+	tools := map[message.ToolName]message.Tool{
+		"write_file": &mockTool{name: "write_file", description: "Write"},
+		"read_file":  &mockTool{name: "read_file", description: "Read"},
+		"bash":       &mockTool{name: "bash", description: "Run"},
+	}
+	params := anthropic.MessageNewParams{
+		Tools: convertToolsToAnthropic(tools),
+		Messages: toAnthropicMessages([]message.Message{
+			message.NewChatMessage(message.MessageTypeSystem, "[[SYSTEM_PREAMBLE]]\nhouse style"),
+			message.NewChatMessage(message.MessageTypeSystem, "[[SCENARIO_PROMPT:CODE]]\nYou are a coding assistant"),
+			message.NewChatMessage(message.MessageTypeUser, "fix the build"),
+		}),
+	}
+
+	// Tools are sorted so the cached prefix is identical across requests
+	var names []string
+	for _, tool := range params.Tools {
+		names = append(names, tool.OfTool.Name)
+	}
+	if want := []string{"bash", "read_file", "write_file"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("tool order = %v, want %v", names, want)
+	}
+
+	applyPromptCacheBreakpoints(&params)
+
+	cached := func(block anthropic.CacheControlEphemeralParam) bool { return block.Type == "ephemeral" }
+	if !cached(params.Tools[2].OfTool.CacheControl) || cached(params.Tools[0].OfTool.CacheControl) {
+		t.Error("expected a cache breakpoint on the last tool only")
+	}
+	if cached(params.Messages[0].Content[0].OfText.CacheControl) {
+		t.Error("the preamble should not carry a breakpoint when a later system message exists")
+	}
+	if !cached(params.Messages[1].Content[0].OfText.CacheControl) {
+		t.Error("expected a cache breakpoint on the scenario prompt")
+	}
+	if cached(params.Messages[2].Content[0].OfText.CacheControl) {
+		t.Error("the user request should not be cached")
+	}
+}
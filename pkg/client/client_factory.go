@@ -22,6 +22,13 @@ func NewClientWithToolManager(client domain.LLM, toolManager domain.ToolManager)
 
 	// Determine the appropriate tool calling client based on the client type
 	switch c := client.(type) {
+	case *CachingLLM:
+		// Build the tool calling client from the wrapped LLM and keep caching its requests
+		toolClient, err := NewClientWithToolManager(c.llm, toolManager)
+		if err != nil {
+			return nil, err
+		}
+		return c.withToolClient(toolClient, toolManager), nil
 	case *ollama.OllamaClient:
 		// For Ollama clients, use the embedded OllamaCore to create a new tool calling client
 		// This will automatically choose between native tool calling or schema-based based on model capabilities
@@ -60,6 +67,13 @@ func NewStructuredClient[T any](client domain.LLM) (domain.StructuredLLM[T], err
 
 	// Determine the appropriate structured client based on the client type
 	switch c := client.(type) {
+	case *CachingLLM:
+		// Build the structured client from the wrapped LLM and keep caching its requests
+		structured, err := NewStructuredClient[T](c.llm)
+		if err != nil {
+			return nil, err
+		}
+		return withStructuredClient(c, structured), nil
 	case *ollama.OllamaClient:
 		// For Ollama clients, check if the model supports JSON Schema or native tool calling
 		if ollama.IsJSONSchemaCapableModel(c.Model()) {
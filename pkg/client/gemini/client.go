@@ -9,17 +9,48 @@ import (
 	"google.golang.org/genai"
 
 	"github.com/fpt/go-gennai-cli/pkg/agent/domain"
+	"github.com/fpt/go-gennai-cli/pkg/httpclient"
 	pkgLogger "github.com/fpt/go-gennai-cli/pkg/logger"
 	"github.com/fpt/go-gennai-cli/pkg/message"
 )
 
 var geminiLogger = pkgLogger.NewComponentLogger("gemini-client")
 
+// minThinkingBudget is the smallest thinking budget Gemini 2.5 models accept
+const minThinkingBudget = 128
+
 // GeminiCore holds shared resources for Gemini clients
 type GeminiCore struct {
 	client    *genai.Client
 	model     string
 	maxTokens int
+
+	thinkingBudget int // tokens for thinking (0 = let the model decide)
+}
+
+// SetThinkingBudget sets the tokens available for thinking
+// (domain.ThinkingBudgetConfigurator). 0 lets the model decide.
+func (c *GeminiCore) SetThinkingBudget(tokens int) {
+	c.thinkingBudget = tokens
+}
+
+// thinkingConfig requests thought summaries, capped at the configured budget
+// when set. With thinking off it sends a zero budget, since 2.5 Flash models
+// think by default; 2.5 Pro can't turn thinking off and gets the minimum.
+func (c *GeminiCore) thinkingConfig(enabled bool) *genai.ThinkingConfig {
+	if !enabled {
+		budget := int32(0)
+		if c.model == modelGemini25Pro {
+			budget = minThinkingBudget
+		}
+		return &genai.ThinkingConfig{ThinkingBudget: &budget}
+	}
+	config := &genai.ThinkingConfig{IncludeThoughts: true}
+	if c.thinkingBudget > 0 {
+		budget := int32(max(c.thinkingBudget, minThinkingBudget))
+		config.ThinkingBudget = &budget
+	}
+	return config
 }
 
 // GeminiClient implements ToolCallingLLM and VisionLLM interfaces
@@ -47,8 +78,9 @@ func NewGeminiClientWithTokens(model string, maxTokens int) (*GeminiClient, erro
 
 	ctx := context.Background()
 	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey:  apiKey,
-		Backend: genai.BackendGeminiAPI,
+		APIKey:     apiKey,
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: httpclient.New(0),
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Gemini client: %w", err)
@@ -119,13 +151,10 @@ func (c *GeminiClient) Chat(ctx context.Context, messages []message.Message, ena
 		case message.MessageTypeUser:
 			// Handle images if present
 			if images := msg.Images(); len(images) > 0 {
-				// Create parts with both text and images
-				parts := []*genai.Part{}
-				if content := msg.Content(); content != "" {
-					parts = append(parts, &genai.Part{Text: content})
+				parts, err := userPartsWithImages(msg.Content(), images)
+				if err != nil {
+					return nil, err
 				}
-				// TODO: Implement image handling with proper base64 decoding
-				// For now, just add text content
 				geminiContents = append(geminiContents, genai.NewContentFromParts(parts, genai.RoleUser))
 			} else {
 				geminiContents = append(geminiContents, genai.NewContentFromText(msg.Content(), genai.RoleUser))
@@ -151,14 +180,13 @@ func (c *GeminiClient) Chat(ctx context.Context, messages []message.Message, ena
 		config.SystemInstruction = systemInstruction
 	}
 
-	// Enable thinking if requested and model supports it
-	if enableThinking && c.isThinkingCapable() {
-		config.ThinkingConfig = &genai.ThinkingConfig{
-			IncludeThoughts: true,
+	// Thinking models get a budget either way, or they think when asked not to
+	if c.isThinkingCapable() {
+		config.ThinkingConfig = c.thinkingConfig(enableThinking)
+		if enableThinking {
+			// Use streaming for progressive thinking display (no tool handling in basic chat)
+			return c.chatWithStreaming(ctx, geminiContents, config, true, false, enableThinking, thinkingChan)
 		}
-
-		// Use streaming for progressive thinking display (no tool handling in basic chat)
-		return c.chatWithStreaming(ctx, geminiContents, config, true, false, enableThinking, thinkingChan)
 	}
 
 	// Generate content using the Models interface (non-streaming)
@@ -262,17 +290,7 @@ func (c *GeminiClient) chatWithStreaming(ctx context.Context, contents []*genai.
 	// Check if we have tool calls to return (only when tool handling is enabled)
 	if handleTools && len(toolCalls) > 0 {
 		// Build messages for all collected function calls
-		if len(toolCalls) == 1 {
-			fc := toolCalls[0]
-			args := convertGeminiArgsToToolArgs(convertToolArgsToJSON(fc.Args))
-			return message.NewToolCallMessage(message.ToolName(fc.Name), args), nil
-		}
-		calls := make([]*message.ToolCallMessage, 0, len(toolCalls))
-		for _, fc := range toolCalls {
-			args := convertGeminiArgsToToolArgs(convertToolArgsToJSON(fc.Args))
-			calls = append(calls, message.NewToolCallMessage(message.ToolName(fc.Name), args))
-		}
-		return message.NewToolCallBatch(calls), nil
+		return toToolCallResponse(toolCalls), nil
 	}
 
 	finalText := responseText.String()
@@ -321,13 +339,10 @@ func (c *GeminiClient) ChatWithToolChoice(ctx context.Context, messages []messag
 		case message.MessageTypeUser:
 			// Handle images if present
 			if images := msg.Images(); len(images) > 0 {
-				// Create parts with both text and images
-				parts := []*genai.Part{}
-				if content := msg.Content(); content != "" {
-					parts = append(parts, &genai.Part{Text: content})
+				parts, err := userPartsWithImages(msg.Content(), images)
+				if err != nil {
+					return nil, err
 				}
-				// TODO: Implement image handling with proper base64 decoding
-				// For now, just add text content
 				geminiContents = append(geminiContents, genai.NewContentFromParts(parts, genai.RoleUser))
 			} else {
 				geminiContents = append(geminiContents, genai.NewContentFromText(msg.Content(), genai.RoleUser))
@@ -356,7 +371,16 @@ func (c *GeminiClient) ChatWithToolChoice(ctx context.Context, messages []messag
 			// Represent tool results as user messages
 			if toolResultMsg, ok := msg.(interface{ Content() string }); ok {
 				resultText := "[Function result: " + toolResultMsg.Content() + "]"
-				geminiContents = append(geminiContents, genai.NewContentFromText(resultText, genai.RoleUser))
+				// Images a tool returned (e.g. Read on a PNG) travel with the result
+				if images := msg.Images(); len(images) > 0 {
+					parts, err := userPartsWithImages(resultText, images)
+					if err != nil {
+						return nil, err
+					}
+					geminiContents = append(geminiContents, genai.NewContentFromParts(parts, genai.RoleUser))
+				} else {
+					geminiContents = append(geminiContents, genai.NewContentFromText(resultText, genai.RoleUser))
+				}
 			}
 
 		case message.MessageTypeToolCallBatch:
@@ -388,14 +412,13 @@ func (c *GeminiClient) ChatWithToolChoice(ctx context.Context, messages []messag
 		}
 	}
 
-	// Enable thinking for tool calling as well
+	// Enable thinking for tool calling as well, unless the caller disabled it
 	if c.isThinkingCapable() {
-		config.ThinkingConfig = &genai.ThinkingConfig{
-			IncludeThoughts: true,
+		config.ThinkingConfig = c.thinkingConfig(enableThinking)
+		if enableThinking {
+			// Use streaming for progressive thinking display with tool handling enabled
+			return c.chatWithStreaming(ctx, geminiContents, config, true, true, enableThinking, thinkingChan)
 		}
-
-		// Use streaming for progressive thinking display with tool handling enabled
-		return c.chatWithStreaming(ctx, geminiContents, config, true, true, enableThinking, thinkingChan)
 	}
 
 	// Generate content using the Models interface (non-streaming)
@@ -412,16 +435,14 @@ func (c *GeminiClient) ChatWithToolChoice(ctx context.Context, messages []messag
 		totalTokens := resp.UsageMetadata.TotalTokenCount
 		utilizationPct := float64(outputTokens) / float64(maxTokens) * 100
 
-		fmt.Printf("DEBUG: Gemini API Usage - Input: %d tokens, Output: %d tokens, Total: %d tokens, Model: %s\n",
-			inputTokens, outputTokens, totalTokens, c.model)
-		fmt.Printf("DEBUG: Token Utilization - %.1f%% of max output tokens (%d/%d)\n",
-			utilizationPct, outputTokens, maxTokens)
+		geminiLogger.DebugWithIntention(pkgLogger.IntentionStatistics, "Gemini API Usage", "input_tokens", inputTokens, "output_tokens", outputTokens, "total_tokens", totalTokens, "model", c.model)
+		geminiLogger.DebugWithIntention(pkgLogger.IntentionStatistics, "Token utilization", "percent", fmt.Sprintf("%.1f", utilizationPct), "output", outputTokens, "max_output", maxTokens)
 
 		// Warn if we're approaching the limit
 		if utilizationPct > 90 {
-			fmt.Printf("⚠️  WARNING: Very high token usage (%.1f%%) - potential truncation risk!\n", utilizationPct)
+			geminiLogger.Warn("Very high token usage - potential truncation risk!", "percent", fmt.Sprintf("%.1f", utilizationPct))
 		} else if utilizationPct > 80 {
-			fmt.Printf("⚠️  WARNING: High token usage (%.1f%%) - approaching limit\n", utilizationPct)
+			geminiLogger.Warn("High token usage - approaching limit", "percent", fmt.Sprintf("%.1f", utilizationPct))
 		}
 	}
 
@@ -440,17 +461,14 @@ func (c *GeminiClient) ChatWithToolChoice(ctx context.Context, messages []messag
 
 	// Check if response contains function calls (collect all)
 	if len(resp.Candidates) > 0 && resp.Candidates[0].Content != nil && len(resp.Candidates[0].Content.Parts) > 0 {
-		var calls []*message.ToolCallMessage
+		var calls []*genai.FunctionCall
 		for _, part := range resp.Candidates[0].Content.Parts {
-			if functionCall := part.FunctionCall; functionCall != nil {
-				args := convertGeminiArgsToToolArgs(convertToolArgsToJSON(functionCall.Args))
-				calls = append(calls, message.NewToolCallMessage(message.ToolName(functionCall.Name), args))
+			if part.FunctionCall != nil {
+				calls = append(calls, part.FunctionCall)
 			}
 		}
-		if len(calls) == 1 {
-			return calls[0], nil
-		} else if len(calls) > 1 {
-			return message.NewToolCallBatch(calls), nil
+		if msg := toToolCallResponse(calls); msg != nil {
+			return msg, nil
 		}
 	}
 
@@ -0,0 +1,49 @@
+package gemini
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/fpt/go-gennai-cli/pkg/agent/domain"
+	"github.com/fpt/go-gennai-cli/pkg/message"
+	"google.golang.org/genai"
+)
+
+// ChatWithSchema implements domain.SchemaLLM using Gemini's native JSON Schema output
+func (c *GeminiClient) ChatWithSchema(ctx context.Context, messages []message.Message, schema json.RawMessage) (string, error) {
+	var jsonSchema any
+	if err := json.Unmarshal(schema, &jsonSchema); err != nil {
+		return "", fmt.Errorf("invalid JSON schema: %w", err)
+	}
+
+	geminiContents, systemInstruction := toStructuredGeminiContents(messages)
+	config := &genai.GenerateContentConfig{
+		MaxOutputTokens:    int32(c.maxTokens),
+		ResponseMIMEType:   "application/json",
+		ResponseJsonSchema: jsonSchema,
+	}
+	if systemInstruction != nil {
+		config.SystemInstruction = systemInstruction
+	}
+
+	resp, err := c.client.Models.GenerateContent(ctx, c.model, geminiContents, config)
+	if err != nil {
+		return "", fmt.Errorf("Gemini API call failed: %w", err)
+	}
+	if resp.UsageMetadata != nil {
+		c.lastUsage = message.TokenUsage{
+			InputTokens:  int(resp.UsageMetadata.PromptTokenCount),
+			OutputTokens: int(resp.UsageMetadata.CandidatesTokenCount),
+			TotalTokens:  int(resp.UsageMetadata.TotalTokenCount),
+		}
+	}
+
+	responseText := resp.Text()
+	if responseText == "" {
+		return "", fmt.Errorf("empty structured response from Gemini")
+	}
+	return responseText, nil
+}
+
+var _ domain.SchemaLLM = (*GeminiClient)(nil)
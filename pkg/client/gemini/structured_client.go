@@ -66,11 +66,9 @@ func (c *GeminiStructuredClient[T]) ChatWithStructure(ctx context.Context, messa
 		config.SystemInstruction = systemInstruction
 	}
 
-	// Add thinking parameter if supported and enabled
-	if enableThinking && c.isThinkingCapable() {
-		config.ThinkingConfig = &genai.ThinkingConfig{
-			IncludeThoughts: true,
-		}
+	// Thinking models get a budget either way, or they think when asked not to
+	if c.isThinkingCapable() {
+		config.ThinkingConfig = c.core.thinkingConfig(enableThinking)
 	}
 
 	// Generate content using Gemini's structured output
@@ -207,6 +205,12 @@ func (c *GeminiStructuredClient[T]) generateFieldSchema(fieldType reflect.Type)
 
 // convertMessagesToGemini converts internal messages to Gemini format
 func (c *GeminiStructuredClient[T]) convertMessagesToGemini(messages []message.Message) ([]*genai.Content, *genai.Content) {
+	return toStructuredGeminiContents(messages)
+}
+
+// toStructuredGeminiContents converts internal messages for a structured
+// output request, which carries plain text turns only
+func toStructuredGeminiContents(messages []message.Message) ([]*genai.Content, *genai.Content) {
 	geminiContents := make([]*genai.Content, 0)
 	var systemInstruction *genai.Content
 
@@ -215,13 +219,12 @@ func (c *GeminiStructuredClient[T]) convertMessagesToGemini(messages []message.M
 		case message.MessageTypeUser:
 			// Handle images if present
 			if images := msg.Images(); len(images) > 0 {
-				// Create parts with both text and images
-				parts := []*genai.Part{}
-				if content := msg.Content(); content != "" {
-					parts = append(parts, &genai.Part{Text: content})
+				parts, err := userPartsWithImages(msg.Content(), images)
+				if err != nil {
+					// Structured output can still proceed on the text alone
+					geminiLogger.Warn("Dropping images from structured request", "error", err)
+					parts = []*genai.Part{{Text: msg.Content()}}
 				}
-				// TODO: Implement image handling with proper base64 decoding
-				// For now, just add text content
 				geminiContents = append(geminiContents, genai.NewContentFromParts(parts, genai.RoleUser))
 			} else {
 				geminiContents = append(geminiContents, genai.NewContentFromText(msg.Content(), genai.RoleUser))
@@ -18,19 +18,25 @@ func convertGeminiArgsToToolArgs(argsJSON string) message.ToolArgumentValues {
 		return result
 	}
 
-	// Parse JSON arguments
-	var args map[string]interface{}
-	if err := json.Unmarshal([]byte(argsJSON), &args); err != nil {
+	// Parse JSON arguments, including an object sent JSON-encoded in a string
+	args, err := message.ParseToolArguments(argsJSON)
+	if err != nil {
 		// If parsing fails, return empty map
 		return result
 	}
+	return args
+}
 
-	// Convert interface{} values to proper types
-	for key, value := range args {
-		result[key] = value
+// toToolCallResponse converts the function calls of one response to a tool
+// call message, or a batch when Gemini made several calls at once. It returns
+// nil when there are none.
+func toToolCallResponse(functionCalls []*genai.FunctionCall) message.Message {
+	calls := make([]*message.ToolCallMessage, 0, len(functionCalls))
+	for _, fc := range functionCalls {
+		args := convertGeminiArgsToToolArgs(convertToolArgsToJSON(fc.Args))
+		calls = append(calls, message.NewToolCallMessage(message.ToolName(fc.Name), args))
 	}
-
-	return result
+	return message.NewToolCallResponse(calls)
 }
 
 // convertToolArgsToJSON converts tool argument values to JSON string
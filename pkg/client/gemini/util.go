@@ -1,5 +1,15 @@
 package gemini
 
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/fpt/go-gennai-cli/pkg/client/models"
+	"google.golang.org/genai"
+)
+
 // Google Gemini 2.5 Models
 // https://ai.google.dev/gemini-api/docs/models
 
@@ -11,33 +21,11 @@ const (
 
 // getGeminiModel maps user-friendly model names to actual Gemini 2.5 model identifiers
 func getGeminiModel(model string) string {
-	// Normalize the model name to 2.5 series only
-	switch model {
-	case "gemini-2.5-pro", "gemini-pro", "pro":
-		return modelGemini25Pro
-	case "gemini-2.5-flash", "gemini-flash", "flash":
-		return modelGemini25Flash
-	case "gemini-2.5-flash-lite", "gemini-2.5-lite", "gemini-lite", "lite":
-		return modelGemini25FlashLite
-	default:
-		// If it's already a valid Gemini 2.5 model name, return as-is
-		if isValidGeminiModel(model) {
-			return model
-		}
-		// Default to Gemini 2.5 Flash for unknown models (most balanced)
-		return modelGemini25Flash
-	}
-}
-
-// isValidGeminiModel checks if a model name is a valid Gemini 2.5 model
-func isValidGeminiModel(model string) bool {
-	validModels := map[string]bool{
-		// Gemini 2.5 models only
-		modelGemini25Pro:       true,
-		modelGemini25Flash:     true,
-		modelGemini25FlashLite: true,
+	if info, ok := models.Lookup("gemini", model); ok {
+		return info.Name
 	}
-	return validModels[model]
+	// Default to Gemini 2.5 Flash for unknown models (most balanced)
+	return modelGemini25Flash
 }
 
 // ModelCapabilities represents the capabilities of a Gemini model
@@ -55,52 +43,61 @@ type ModelCapabilities struct {
 }
 
 // getModelCapabilities returns the capabilities of a specific Gemini 2.5 model
+// from the registry. All Gemini 2.5 models take structured output, system
+// prompts and multimodal input.
 func getModelCapabilities(model string) ModelCapabilities {
-	switch model {
-	case modelGemini25Pro:
-		return ModelCapabilities{
-			SupportsVision:       true,
-			SupportsToolCalling:  true,  // Function calling supported
-			SupportsStructured:   true,  // Structured output supported
-			MaxTokens:            65536, // Output token limit: 65,536 (Input limit: 1,048,576)
-			MaxContextWindow:     1048576,
-			SupportsSystemPrompt: true,
-			SupportsMultimodal:   true, // Audio, images, video, text, PDF
-			IsReasoningModel:     true, // Thinking supported, caching, code execution, grounding
-		}
-	case modelGemini25Flash:
-		return ModelCapabilities{
-			SupportsVision:       true,
-			SupportsToolCalling:  true,  // Function calling supported
-			SupportsStructured:   true,  // Structured output supported
-			MaxTokens:            65536, // Output token limit: 65,536 (Input limit: 1,048,576)
-			MaxContextWindow:     1048576,
-			SupportsSystemPrompt: true,
-			SupportsMultimodal:   true, // Text, images, video, audio (no PDF)
-			IsReasoningModel:     true, // Thinking supported, caching, code execution, grounding
+	info, ok := models.Lookup("gemini", model)
+	if !ok {
+		// Default to Flash Lite capabilities for unknown models
+		info, _ = models.Lookup("gemini", modelGemini25FlashLite)
+	}
+	return ModelCapabilities{
+		SupportsVision:       info.Vision,
+		SupportsToolCalling:  info.Tools,
+		SupportsStructured:   true,
+		MaxTokens:            info.MaxOutputTokens,
+		MaxContextWindow:     info.ContextWindow,
+		SupportsSystemPrompt: true,
+		SupportsMultimodal:   true,
+		IsReasoningModel:     info.Thinking,
+	}
+}
+
+// userPartsWithImages builds the parts of a user message carrying Base64-encoded
+// images. Images are decoded and sent as inline blobs with a detected MIME type,
+// followed by the text content.
+func userPartsWithImages(content string, images []string) ([]*genai.Part, error) {
+	parts := make([]*genai.Part, 0, len(images)+1)
+	for i, imageData := range images {
+		data, err := decodeBase64Image(imageData)
+		if err != nil {
+			return nil, fmt.Errorf("invalid image %d: %w", i+1, err)
 		}
-	case modelGemini25FlashLite:
-		return ModelCapabilities{
-			SupportsVision:       true,
-			SupportsToolCalling:  true,  // Function calling supported
-			SupportsStructured:   true,  // Structured output supported
-			MaxTokens:            65536, // Output token limit: 65,536 (Input limit: 1,048,576)
-			MaxContextWindow:     1048576,
-			SupportsSystemPrompt: true,
-			SupportsMultimodal:   true, // Text, images, video, audio, PDF
-			IsReasoningModel:     true, // Thinking supported, caching, code execution, grounding, URL context
+		mimeType := http.DetectContentType(data)
+		if !strings.HasPrefix(mimeType, "image/") {
+			return nil, fmt.Errorf("invalid image %d: unsupported content type %s", i+1, mimeType)
 		}
-	default:
-		// Default to Flash Lite capabilities for unknown models
-		return ModelCapabilities{
-			SupportsVision:       true,
-			SupportsToolCalling:  true,  // Function calling supported
-			SupportsStructured:   true,  // Structured output supported
-			MaxTokens:            65536, // Output token limit: 65,536 (Input limit: 1,048,576)
-			MaxContextWindow:     1048576,
-			SupportsSystemPrompt: true,
-			SupportsMultimodal:   true, // Text, images, video, audio, PDF
-			IsReasoningModel:     true, // Thinking supported
+		parts = append(parts, genai.NewPartFromBytes(data, mimeType))
+	}
+	if content != "" {
+		parts = append(parts, genai.NewPartFromText(content))
+	}
+	return parts, nil
+}
+
+// decodeBase64Image decodes raw Base64 or a data URL (data:image/png;base64,...)
+func decodeBase64Image(imageData string) ([]byte, error) {
+	if strings.HasPrefix(imageData, "data:") {
+		if idx := strings.Index(imageData, ","); idx >= 0 {
+			imageData = imageData[idx+1:]
 		}
 	}
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(imageData))
+	if err != nil {
+		return nil, fmt.Errorf("malformed base64 data: %w", err)
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("empty image data")
+	}
+	return data, nil
 }
@@ -0,0 +1,75 @@
+package gemini
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"google.golang.org/genai"
+)
+
+// 1x1 transparent PNG
+const testPNGBase64 = "iVBORw0KGgoAAAANSUhEUgAAAAEAAAABCAQAAAC1HAwCAAAAC0lEQVR42mNkYAAAAAYAAjCB0C8AAAAASUVORK5CYII="
+
+func TestUserPartsWithImages(t *testing.T) {
+	parts, err := userPartsWithImages("Describe this screenshot", []string{testPNGBase64})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(parts) != 2 {
+		t.Fatalf("expected image and text parts, got %d", len(parts))
+	}
+	if parts[0].InlineData == nil || parts[0].InlineData.MIMEType != "image/png" {
+		t.Errorf("expected inline image/png blob, got %+v", parts[0].InlineData)
+	}
+	if parts[1].Text != "Describe this screenshot" {
+		t.Errorf("expected text part last, got %q", parts[1].Text)
+	}
+
+	// Data URLs are accepted as well
+	if _, err := userPartsWithImages("", []string{"data:image/png;base64," + testPNGBase64}); err != nil {
+		t.Errorf("unexpected error for data URL: %v", err)
+	}
+}
+
+func TestUserPartsWithImages_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"malformed base64": "not base64!!",
+		"not an image":     base64.StdEncoding.EncodeToString([]byte("plain text, not an image")),
+	}
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := userPartsWithImages("hi", []string{data})
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if !strings.Contains(err.Error(), "invalid image 1") {
+				t.Errorf("expected descriptive error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestThinkingConfig(t *testing.T) {
+	budget := func(config *genai.ThinkingConfig) int32 {
+		t.Helper()
+		if config.ThinkingBudget == nil {
+			t.Fatal("expected a thinking budget")
+		}
+		return *config.ThinkingBudget
+	}
+
+	flash := &GeminiCore{model: modelGemini25Flash}
+	if off := flash.thinkingConfig(false); budget(off) != 0 || off.IncludeThoughts {
+		t.Errorf("thinking off = %+v, want a zero budget without thoughts", off)
+	}
+	if on := flash.thinkingConfig(true); on.ThinkingBudget != nil || !on.IncludeThoughts {
+		t.Errorf("thinking on = %+v, want thoughts and the model's own budget", on)
+	}
+
+	// 2.5 Pro rejects a zero budget
+	pro := &GeminiCore{model: modelGemini25Pro}
+	if got := budget(pro.thinkingConfig(false)); got != minThinkingBudget {
+		t.Errorf("pro thinking off budget = %d, want %d", got, minThinkingBudget)
+	}
+}
@@ -0,0 +1,401 @@
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/fpt/go-gennai-cli/pkg/agent/domain"
+	"github.com/fpt/go-gennai-cli/pkg/message"
+)
+
+// LLMCacheMode selects how a CachingLLM uses its store
+type LLMCacheMode string
+
+const (
+	// LLMCacheRecord sends every request to the provider and stores the response
+	LLMCacheRecord LLMCacheMode = "record"
+	// LLMCacheReplay answers from the store only; a miss fails with domain.ErrLLMCacheMiss
+	LLMCacheReplay LLMCacheMode = "replay"
+)
+
+// ParseLLMCacheMode validates a mode name from the command line
+func ParseLLMCacheMode(mode string) (LLMCacheMode, error) {
+	switch LLMCacheMode(mode) {
+	case LLMCacheRecord, LLMCacheReplay:
+		return LLMCacheMode(mode), nil
+	}
+	return "", fmt.Errorf("unsupported LLM cache mode: %s (must be 'record' or 'replay')", mode)
+}
+
+// CachingLLM records LLM responses to a domain.CacheStore and replays them, so
+// a scenario can be re-run deterministically without calling the provider.
+// Requests are keyed by model, messages, tool choice and available tools;
+// message IDs and timestamps are left out so a replayed run produces the same
+// keys as the recorded one.
+type CachingLLM struct {
+	llm         domain.LLM
+	store       domain.CacheStore
+	mode        LLMCacheMode
+	toolManager domain.ToolManager
+	structure   string // Go type of structured responses, for structured clients
+
+	mu         sync.Mutex
+	lastUsage  message.TokenUsage
+	usageKnown bool
+}
+
+// NewCachingLLM wraps llm with a record/replay cache backed by store
+func NewCachingLLM(llm domain.LLM, store domain.CacheStore, mode LLMCacheMode) *CachingLLM {
+	return &CachingLLM{llm: llm, store: store, mode: mode}
+}
+
+// WithLLM wraps another client with the same store and mode, e.g. after the
+// model was switched
+func (c *CachingLLM) WithLLM(llm domain.LLM) *CachingLLM {
+	return NewCachingLLM(llm, c.store, c.mode)
+}
+
+// Mode returns whether the client records or replays
+func (c *CachingLLM) Mode() LLMCacheMode {
+	return c.mode
+}
+
+// Chat implements domain.LLM
+func (c *CachingLLM) Chat(ctx context.Context, messages []message.Message, enableThinking bool, thinkingChan chan<- string) (message.Message, error) {
+	return c.cached(ctx, messages, nil, func() (message.Message, error) {
+		return c.llm.Chat(ctx, messages, enableThinking, thinkingChan)
+	})
+}
+
+// ModelID implements domain.LLM
+func (c *CachingLLM) ModelID() string {
+	return c.llm.ModelID()
+}
+
+// SetResponseChannel implements domain.ResponseStreamer. Only recorded
+// requests stream; replayed responses are returned whole.
+func (c *CachingLLM) SetResponseChannel(ch chan<- string) {
+	if streamer, ok := c.llm.(domain.ResponseStreamer); ok {
+		streamer.SetResponseChannel(ch)
+	}
+}
+
+// LastTokenUsage implements domain.TokenUsageProvider, reporting the recorded
+// usage for replayed responses
+func (c *CachingLLM) LastTokenUsage() (message.TokenUsage, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lastUsage, c.usageKnown
+}
+
+// MaxContextTokens implements domain.ContextWindowProvider
+func (c *CachingLLM) MaxContextTokens() int {
+	if provider, ok := c.llm.(domain.ContextWindowProvider); ok {
+		return provider.MaxContextTokens()
+	}
+	return 0
+}
+
+// SupportsVision implements domain.VisionLLM
+func (c *CachingLLM) SupportsVision() bool {
+	vision, ok := c.llm.(domain.VisionLLM)
+	return ok && vision.SupportsVision()
+}
+
+// MakeCacheKey implements domain.CacheKeyProvider
+func (c *CachingLLM) MakeCacheKey(ctx context.Context, messages []message.Message, toolChoice *domain.ToolChoice) (string, error) {
+	request := cacheKeyRequest{
+		Model:      c.llm.ModelID(),
+		Structure:  c.structure,
+		ToolChoice: toolChoice,
+		Messages:   make([]cacheKeyMessage, 0, len(messages)),
+	}
+	if c.toolManager != nil {
+		for name, tool := range c.toolManager.GetTools() {
+			request.Tools = append(request.Tools, cacheKeyTool{Name: name, Description: tool.Description(), Arguments: tool.Arguments()})
+		}
+		sort.Slice(request.Tools, func(i, j int) bool { return request.Tools[i].Name < request.Tools[j].Name })
+	}
+	for _, msg := range messages {
+		request.Messages = append(request.Messages, toCacheKeyMessage(msg))
+	}
+
+	encoded, err := json.Marshal(request)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode request for the LLM cache: %w", err)
+	}
+	sum := sha256.Sum256(encoded)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// cached answers a request from the store in replay mode, or sends it and
+// stores the response in record mode
+func (c *CachingLLM) cached(ctx context.Context, messages []message.Message, toolChoice *domain.ToolChoice, send func() (message.Message, error)) (message.Message, error) {
+	key, err := c.MakeCacheKey(ctx, messages, toolChoice)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.mode == LLMCacheReplay {
+		resp, found, err := c.store.Get(ctx, key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read recorded LLM response %s: %w", key, err)
+		}
+		if !found {
+			return nil, fmt.Errorf("%w (key %s, model %s); the conversation differs from the recording, so record it again with --llm-cache record", domain.ErrLLMCacheMiss, key, c.llm.ModelID())
+		}
+		// The recorded usage travels on the message; hand it out the usual way
+		usage := message.TokenUsage{InputTokens: resp.InputTokens(), OutputTokens: resp.OutputTokens(), TotalTokens: resp.TotalTokens()}
+		resp.SetTokenUsage(0, 0, 0)
+		c.setUsage(usage, usage.TotalTokens > 0)
+		return resp, nil
+	}
+
+	resp, err := send()
+	if err != nil {
+		return nil, err
+	}
+	usage, known := c.innerUsage()
+	c.setUsage(usage, known)
+
+	// Store a copy so the usage recorded with it doesn't show up on the live message
+	recorded := fromCachedResponse(toCachedResponse(resp))
+	recorded.SetTokenUsage(usage.InputTokens, usage.OutputTokens, usage.TotalTokens)
+	if err := c.store.Set(ctx, key, recorded); err != nil {
+		return nil, fmt.Errorf("failed to record LLM response %s: %w", key, err)
+	}
+	return resp, nil
+}
+
+func (c *CachingLLM) innerUsage() (message.TokenUsage, bool) {
+	if provider, ok := c.llm.(domain.TokenUsageProvider); ok {
+		return provider.LastTokenUsage()
+	}
+	return message.TokenUsage{}, false
+}
+
+func (c *CachingLLM) setUsage(usage message.TokenUsage, known bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lastUsage = usage
+	c.usageKnown = known
+}
+
+// withToolClient returns a tool calling client that caches the requests of
+// toolClient, a tool calling client built from the wrapped LLM
+func (c *CachingLLM) withToolClient(toolClient domain.ToolCallingLLM, toolManager domain.ToolManager) *cachingToolCallingLLM {
+	cached := c.WithLLM(toolClient)
+	cached.toolManager = toolManager
+	return &cachingToolCallingLLM{CachingLLM: cached, tools: toolClient}
+}
+
+// cachingToolCallingLLM is a CachingLLM around a tool calling client
+type cachingToolCallingLLM struct {
+	*CachingLLM
+	tools domain.ToolCallingLLM
+}
+
+// SetToolManager implements domain.ToolCallingLLM
+func (c *cachingToolCallingLLM) SetToolManager(toolManager domain.ToolManager) {
+	c.toolManager = toolManager
+	c.tools.SetToolManager(toolManager)
+}
+
+// ChatWithToolChoice implements domain.ToolCallingLLM
+func (c *cachingToolCallingLLM) ChatWithToolChoice(ctx context.Context, messages []message.Message, toolChoice domain.ToolChoice, enableThinking bool, thinkingChan chan<- string) (message.Message, error) {
+	return c.cached(ctx, messages, &toolChoice, func() (message.Message, error) {
+		return c.tools.ChatWithToolChoice(ctx, messages, toolChoice, enableThinking, thinkingChan)
+	})
+}
+
+// withStructuredClient returns a structured client that caches the requests of
+// structured, a structured client built from the wrapped LLM
+func withStructuredClient[T any](c *CachingLLM, structured domain.StructuredLLM[T]) *cachingStructuredLLM[T] {
+	cached := c.WithLLM(structured)
+	var zero T
+	cached.structure = fmt.Sprintf("%T", zero)
+	return &cachingStructuredLLM[T]{CachingLLM: cached, structured: structured}
+}
+
+// cachingStructuredLLM is a CachingLLM around a structured client. Responses
+// are stored as the JSON encoding of the result.
+type cachingStructuredLLM[T any] struct {
+	*CachingLLM
+	structured domain.StructuredLLM[T]
+}
+
+// ChatWithStructure implements domain.StructuredLLM
+func (c *cachingStructuredLLM[T]) ChatWithStructure(ctx context.Context, messages []message.Message, enableThinking bool, thinkingChan chan<- string) (T, error) {
+	var result T
+	resp, err := c.cached(ctx, messages, nil, func() (message.Message, error) {
+		var err error
+		if result, err = c.structured.ChatWithStructure(ctx, messages, enableThinking, thinkingChan); err != nil {
+			return nil, err
+		}
+		encoded, err := json.Marshal(result)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode structured response for the LLM cache: %w", err)
+		}
+		return message.NewChatMessage(message.MessageTypeAssistant, string(encoded)), nil
+	})
+	if err != nil || c.mode != LLMCacheReplay {
+		return result, err
+	}
+	if err := json.Unmarshal([]byte(resp.Content()), &result); err != nil {
+		return result, fmt.Errorf("failed to decode recorded structured response: %w", err)
+	}
+	return result, nil
+}
+
+// cacheKeyRequest is what identifies a request in the cache
+type cacheKeyRequest struct {
+	Model      string             `json:"model"`
+	Structure  string             `json:"structure,omitempty"`
+	ToolChoice *domain.ToolChoice `json:"tool_choice,omitempty"`
+	Tools      []cacheKeyTool     `json:"tools,omitempty"`
+	Messages   []cacheKeyMessage  `json:"messages"`
+}
+
+type cacheKeyTool struct {
+	Name        message.ToolName        `json:"name"`
+	Description message.ToolDescription `json:"description"`
+	Arguments   []message.ToolArgument  `json:"arguments"`
+}
+
+// cacheKeyMessage holds the parts of a message sent to the provider. IDs,
+// timestamps and thinking are left out: they differ between runs, or are
+// provider output already covered by the request that produced them.
+type cacheKeyMessage struct {
+	Type    message.MessageType   `json:"type"`
+	Source  message.MessageSource `json:"source,omitempty"`
+	Content string                `json:"content,omitempty"`
+	Images  []string              `json:"images,omitempty"`
+	Calls   []cachedToolCall      `json:"calls,omitempty"`
+}
+
+func toCacheKeyMessage(msg message.Message) cacheKeyMessage {
+	key := cacheKeyMessage{Type: msg.Type(), Source: msg.Source(), Images: msg.Images()}
+	switch m := msg.(type) {
+	case *message.ToolCallMessage:
+		key.Calls = []cachedToolCall{{Name: m.ToolName(), Arguments: m.ToolArguments()}}
+	case *message.ToolCallBatchMessage:
+		for _, call := range m.Calls() {
+			key.Calls = append(key.Calls, cachedToolCall{Name: call.ToolName(), Arguments: call.ToolArguments()})
+		}
+	default:
+		key.Content = msg.Content()
+	}
+	return key
+}
+
+// cachedResponse is the stored form of an LLM response
+type cachedResponse struct {
+	Type     message.MessageType `json:"type"`
+	Content  string              `json:"content,omitempty"`
+	Thinking string              `json:"thinking,omitempty"`
+	Calls    []cachedToolCall    `json:"calls,omitempty"`
+	Usage    message.TokenUsage  `json:"usage"`
+}
+
+type cachedToolCall struct {
+	ID        string                     `json:"id,omitempty"`
+	Name      message.ToolName           `json:"name"`
+	Arguments message.ToolArgumentValues `json:"arguments"`
+}
+
+func toCachedResponse(msg message.Message) cachedResponse {
+	resp := cachedResponse{
+		Type:     msg.Type(),
+		Content:  msg.Content(),
+		Thinking: msg.Thinking(),
+		Usage:    message.TokenUsage{InputTokens: msg.InputTokens(), OutputTokens: msg.OutputTokens(), TotalTokens: msg.TotalTokens()},
+	}
+	switch m := msg.(type) {
+	case *message.ToolCallMessage:
+		resp.Calls = []cachedToolCall{{ID: m.ID(), Name: m.ToolName(), Arguments: m.ToolArguments()}}
+	case *message.ToolCallBatchMessage:
+		for _, call := range m.Calls() {
+			resp.Calls = append(resp.Calls, cachedToolCall{ID: call.ID(), Name: call.ToolName(), Arguments: call.ToolArguments()})
+		}
+	}
+	return resp
+}
+
+func fromCachedResponse(resp cachedResponse) message.Message {
+	var msg message.Message
+	switch resp.Type {
+	case message.MessageTypeToolCall, message.MessageTypeToolCallBatch:
+		// Tool results refer to the call IDs, so replayed calls keep the recorded ones
+		calls := make([]*message.ToolCallMessage, 0, len(resp.Calls))
+		for _, call := range resp.Calls {
+			calls = append(calls, message.NewToolCallMessageWithID(call.ID, call.Name, call.Arguments, time.Now()))
+		}
+		if resp.Type == message.MessageTypeToolCall && len(calls) == 1 {
+			msg = calls[0]
+		} else {
+			msg = message.NewToolCallBatch(calls)
+		}
+	default:
+		if resp.Thinking != "" {
+			msg = message.NewChatMessageWithThinking(resp.Type, resp.Content, resp.Thinking)
+		} else {
+			msg = message.NewChatMessage(resp.Type, resp.Content)
+		}
+	}
+	msg.SetTokenUsage(resp.Usage.InputTokens, resp.Usage.OutputTokens, resp.Usage.TotalTokens)
+	return msg
+}
+
+// DiskCacheStore is a domain.CacheStore keeping one JSON file per response in
+// a directory, so recordings can be inspected and checked in with tests
+type DiskCacheStore struct {
+	dir string
+}
+
+// NewDiskCacheStore creates a store in dir; the directory is created on the first write
+func NewDiskCacheStore(dir string) *DiskCacheStore {
+	return &DiskCacheStore{dir: dir}
+}
+
+func (s *DiskCacheStore) path(key string) string {
+	return filepath.Join(s.dir, key+".json")
+}
+
+// Get implements domain.CacheStore
+func (s *DiskCacheStore) Get(ctx context.Context, key string) (message.Message, bool, error) {
+	data, err := os.ReadFile(s.path(key))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	var resp cachedResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, false, fmt.Errorf("failed to parse %s: %w", s.path(key), err)
+	}
+	return fromCachedResponse(resp), true, nil
+}
+
+// Set implements domain.CacheStore
+func (s *DiskCacheStore) Set(ctx context.Context, key string, resp message.Message) error {
+	data, err := json.MarshalIndent(toCachedResponse(resp), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode response: %w", err)
+	}
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", s.dir, err)
+	}
+	if err := os.WriteFile(s.path(key), data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", s.path(key), err)
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/fpt/go-gennai-cli/pkg/agent/domain"
+	"github.com/fpt/go-gennai-cli/pkg/message"
+)
+
+// usageLLM is a mockLLM that reports token usage
+type usageLLM struct {
+	*mockLLM
+	usage message.TokenUsage
+}
+
+func (u *usageLLM) LastTokenUsage() (message.TokenUsage, bool) { return u.usage, true }
+
+func TestCachingLLM_RecordThenReplay(t *testing.T) {
+	ctx := context.Background()
+	store := NewDiskCacheStore(t.TempDir())
+	tools := &mockToolManager{}
+
+	// A fresh conversation each time: new message IDs and timestamps
+	conversation := func() []message.Message {
+		return []message.Message{
+			message.NewSystemMessage("You are a coding agent"),
+			message.NewChatMessage(message.MessageTypeUser, "What is in main.go?"),
+		}
+	}
+	followUp := func(call message.Message) []message.Message {
+		return append(conversation(), call, message.NewToolResultMessage(call.ID(), "package main", ""))
+	}
+
+	recorded := &usageLLM{mockLLM: &mockLLM{}, usage: message.TokenUsage{InputTokens: 120, OutputTokens: 30, TotalTokens: 150}}
+	recorded.chatWithToolChoiceFunc = func(ctx context.Context, messages []message.Message, toolChoice domain.ToolChoice) (message.Message, error) {
+		if len(messages) == 2 {
+			return message.NewToolCallMessageWithID("call_1", "Read", message.ToolArgumentValues{"file_path": "main.go"}, time.Now()), nil
+		}
+		return message.NewChatMessageWithThinking(message.MessageTypeAssistant, "It declares package main.", "The file is short"), nil
+	}
+	recorder, err := NewClientWithToolManager(NewCachingLLM(recorded, store, LLMCacheRecord), tools)
+	if err != nil {
+		t.Fatalf("NewClientWithToolManager: %v", err)
+	}
+	call, err := recorder.ChatWithToolChoice(ctx, conversation(), domain.NewToolChoiceAuto(), false, nil)
+	if err != nil {
+		t.Fatalf("record tool call: %v", err)
+	}
+	if call.InputTokens() != 0 {
+		t.Errorf("expected recording to leave the live message's usage alone, got %d input tokens", call.InputTokens())
+	}
+	if _, err := recorder.ChatWithToolChoice(ctx, followUp(call), domain.NewToolChoiceAuto(), false, nil); err != nil {
+		t.Fatalf("record answer: %v", err)
+	}
+
+	offline := &mockLLM{}
+	offline.chatWithToolChoiceFunc = func(ctx context.Context, messages []message.Message, toolChoice domain.ToolChoice) (message.Message, error) {
+		t.Fatal("replay must not call the provider")
+		return nil, nil
+	}
+	replayer, err := NewClientWithToolManager(NewCachingLLM(offline, store, LLMCacheReplay), tools)
+	if err != nil {
+		t.Fatalf("NewClientWithToolManager: %v", err)
+	}
+
+	replayedCall, err := replayer.ChatWithToolChoice(ctx, conversation(), domain.NewToolChoiceAuto(), false, nil)
+	if err != nil {
+		t.Fatalf("replay tool call: %v", err)
+	}
+	toolCall, ok := replayedCall.(*message.ToolCallMessage)
+	if !ok {
+		t.Fatalf("expected a tool call, got %T", replayedCall)
+	}
+	if toolCall.ID() != "call_1" || toolCall.ToolName() != "Read" || toolCall.ToolArguments()["file_path"] != "main.go" {
+		t.Errorf("unexpected replayed tool call: id=%s name=%s args=%v", toolCall.ID(), toolCall.ToolName(), toolCall.ToolArguments())
+	}
+	if usage, ok := replayer.(domain.TokenUsageProvider).LastTokenUsage(); !ok || usage.TotalTokens != 150 {
+		t.Errorf("expected recorded usage to be replayed, got %+v (known=%v)", usage, ok)
+	}
+	if replayedCall.TotalTokens() != 0 {
+		t.Errorf("expected usage to be reported through LastTokenUsage only, got %d on the message", replayedCall.TotalTokens())
+	}
+
+	answer, err := replayer.ChatWithToolChoice(ctx, followUp(replayedCall), domain.NewToolChoiceAuto(), false, nil)
+	if err != nil {
+		t.Fatalf("replay answer: %v", err)
+	}
+	if answer.Content() != "It declares package main." || answer.Thinking() != "The file is short" {
+		t.Errorf("unexpected replayed answer: %q (thinking %q)", answer.Content(), answer.Thinking())
+	}
+}
+
+// choiceLLM is a mockLLM with structured output
+type choiceLLM struct {
+	*mockLLM
+	choose func() (cachedChoice, error)
+}
+
+type cachedChoice struct {
+	Action string `json:"action"`
+}
+
+func (c *choiceLLM) ChatWithStructure(ctx context.Context, messages []message.Message, enableThinking bool, thinkingChan chan<- string) (cachedChoice, error) {
+	return c.choose()
+}
+
+func TestCachingLLM_StructuredRecordThenReplay(t *testing.T) {
+	ctx := context.Background()
+	store := NewDiskCacheStore(t.TempDir())
+	ask := func() []message.Message {
+		return []message.Message{message.NewChatMessage(message.MessageTypeUser, "Pick an action")}
+	}
+
+	live := &choiceLLM{mockLLM: &mockLLM{}, choose: func() (cachedChoice, error) { return cachedChoice{Action: "CODE"}, nil }}
+	recorder, err := NewStructuredClient[cachedChoice](NewCachingLLM(live, store, LLMCacheRecord))
+	if err != nil {
+		t.Fatalf("NewStructuredClient: %v", err)
+	}
+	if got, err := recorder.ChatWithStructure(ctx, ask(), false, nil); err != nil || got.Action != "CODE" {
+		t.Fatalf("record = %+v, %v", got, err)
+	}
+
+	offline := &choiceLLM{mockLLM: &mockLLM{}, choose: func() (cachedChoice, error) {
+		t.Fatal("replay must not call the provider")
+		return cachedChoice{}, nil
+	}}
+	replayer, err := NewStructuredClient[cachedChoice](NewCachingLLM(offline, store, LLMCacheReplay))
+	if err != nil {
+		t.Fatalf("NewStructuredClient: %v", err)
+	}
+	if got, err := replayer.ChatWithStructure(ctx, ask(), false, nil); err != nil || got.Action != "CODE" {
+		t.Errorf("replay = %+v, %v", got, err)
+	}
+
+	// A plain chat with the same messages is a different request
+	if _, err := NewCachingLLM(offline, store, LLMCacheReplay).Chat(ctx, ask(), false, nil); !errors.Is(err, domain.ErrLLMCacheMiss) {
+		t.Errorf("expected a plain chat to miss the structured recording, got %v", err)
+	}
+}
+
+func TestCachingLLM_ReplayMiss(t *testing.T) {
+	replayer := NewCachingLLM(&mockLLM{}, NewDiskCacheStore(t.TempDir()), LLMCacheReplay)
+	_, err := replayer.Chat(context.Background(), []message.Message{message.NewChatMessage(message.MessageTypeUser, "Hello")}, false, nil)
+	if !errors.Is(err, domain.ErrLLMCacheMiss) {
+		t.Fatalf("expected ErrLLMCacheMiss, got %v", err)
+	}
+}
+
+func TestCachingLLM_KeyDependsOnRequest(t *testing.T) {
+	ctx := context.Background()
+	cache := NewCachingLLM(&mockLLM{}, NewDiskCacheStore(t.TempDir()), LLMCacheRecord)
+	hello := []message.Message{message.NewChatMessage(message.MessageTypeUser, "Hello")}
+
+	base, err := cache.MakeCacheKey(ctx, hello, nil)
+	if err != nil {
+		t.Fatalf("MakeCacheKey: %v", err)
+	}
+	same, _ := cache.MakeCacheKey(ctx, []message.Message{message.NewChatMessage(message.MessageTypeUser, "Hello")}, nil)
+	if same != base {
+		t.Error("expected equal requests to share a key regardless of message IDs")
+	}
+	other, _ := cache.MakeCacheKey(ctx, []message.Message{message.NewChatMessage(message.MessageTypeUser, "Hi")}, nil)
+	choice := domain.NewToolChoiceAuto()
+	withChoice, _ := cache.MakeCacheKey(ctx, hello, &choice)
+	if other == base || withChoice == base {
+		t.Error("expected different content or tool choice to change the key")
+	}
+}
+
+func TestParseLLMCacheMode(t *testing.T) {
+	for _, mode := range []string{"record", "replay"} {
+		if _, err := ParseLLMCacheMode(mode); err != nil {
+			t.Errorf("ParseLLMCacheMode(%q): %v", mode, err)
+		}
+	}
+	if _, err := ParseLLMCacheMode("refresh"); err == nil {
+		t.Error("expected an unknown mode to be rejected")
+	}
+}
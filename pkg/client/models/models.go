@@ -0,0 +1,138 @@
+// Package models is the registry of the models the backend clients know
+// about. The clients read model capabilities from it, and the CLI uses it to
+// catch model name typos.
+package models
+
+import (
+	"slices"
+	"strings"
+)
+
+// Info describes a known model
+type Info struct {
+	Name            string
+	Aliases         []string // other names the client maps to this model
+	Vision          bool     // accepts image input
+	Thinking        bool     // supports thinking/reasoning output
+	Tools           bool     // supports native tool calling
+	ContextWindow   int      // input tokens
+	MaxOutputTokens int      // default output limit per generation, 0 for the client default
+}
+
+// registry lists the known models per backend
+var registry = map[string][]Info{
+	"anthropic": {
+		{Name: "claude-opus-4-20250514", Vision: true, Thinking: true, Tools: true, ContextWindow: 200000},
+		{Name: "claude-sonnet-4-20250514", Vision: true, Thinking: true, Tools: true, ContextWindow: 200000},
+		{Name: "claude-3-7-sonnet-latest", Vision: true, Thinking: true, Tools: true, ContextWindow: 200000},
+		{Name: "claude-3-5-haiku-latest", Vision: true, Thinking: false, Tools: true, ContextWindow: 200000},
+	},
+	"openai": {
+		{Name: "gpt-5", Vision: true, Thinking: true, Tools: true, ContextWindow: 128000, MaxOutputTokens: 16384},
+		{Name: "gpt-5-mini", Vision: true, Thinking: true, Tools: true, ContextWindow: 128000, MaxOutputTokens: 16384},
+		{Name: "gpt-5-nano", Vision: true, Thinking: true, Tools: true, ContextWindow: 128000, MaxOutputTokens: 8192},
+		// GPT-4o models do not accept reasoning_effort
+		{Name: "gpt-4o", Vision: true, Thinking: false, Tools: true, ContextWindow: 128000, MaxOutputTokens: 8192},
+		{Name: "gpt-4o-mini", Vision: true, Thinking: false, Tools: true, ContextWindow: 128000, MaxOutputTokens: 4096},
+	},
+	"gemini": {
+		{Name: "gemini-2.5-pro", Aliases: []string{"gemini-pro", "pro"}, Vision: true, Thinking: true, Tools: true, ContextWindow: 1048576, MaxOutputTokens: 65536},
+		{Name: "gemini-2.5-flash", Aliases: []string{"gemini-flash", "flash"}, Vision: true, Thinking: true, Tools: true, ContextWindow: 1048576, MaxOutputTokens: 65536},
+		{Name: "gemini-2.5-flash-lite", Aliases: []string{"gemini-2.5-lite", "gemini-lite", "lite"}, Vision: true, Thinking: true, Tools: true, ContextWindow: 1048576, MaxOutputTokens: 65536},
+	},
+	// From https://ollama.com/search, kept in sync by hand
+	"ollama": {
+		{Name: "gpt-oss:latest", Aliases: []string{"gpt-oss"}, Thinking: true, Tools: true, ContextWindow: 128000},
+		{Name: "gpt-oss:20b", Thinking: true, Tools: true, ContextWindow: 128000},
+		{Name: "gpt-oss:120b", Thinking: true, Tools: true, ContextWindow: 128000},
+		{Name: "gemma3:latest", Aliases: []string{"gemma3"}, Vision: true, ContextWindow: 8192},
+	},
+}
+
+// normalizeBackend maps backend aliases to registry keys
+func normalizeBackend(backend string) string {
+	if backend == "claude" {
+		return "anthropic"
+	}
+	return backend
+}
+
+// Known returns the known models for backend
+func Known(backend string) []Info {
+	return registry[normalizeBackend(backend)]
+}
+
+// Lookup returns the registry entry for model on backend. Ollama names also
+// match when they contain a known model (e.g. a registry-prefixed
+// "library/gpt-oss:20b").
+func Lookup(backend, model string) (Info, bool) {
+	backend = normalizeBackend(backend)
+	name := strings.ToLower(strings.TrimSpace(model))
+	for _, info := range registry[backend] {
+		if name == info.Name || slices.Contains(info.Aliases, name) {
+			return info, true
+		}
+	}
+	if backend == "ollama" {
+		for _, info := range registry[backend] {
+			if strings.Contains(name, info.Name) {
+				return info, true
+			}
+		}
+	}
+	return Info{}, false
+}
+
+// ContextWindow returns the context window of a known model on any backend,
+// or 0 when the model is unknown
+func ContextWindow(model string) int {
+	for _, backend := range []string{"anthropic", "openai", "gemini", "ollama"} {
+		if info, ok := Lookup(backend, model); ok {
+			return info.ContextWindow
+		}
+	}
+	return 0
+}
+
+// Suggest returns the known model closest to an unknown name on backend, or
+// "" when nothing is close enough to be a likely typo
+func Suggest(backend, model string) string {
+	name := strings.ToLower(strings.TrimSpace(model))
+	if name == "" {
+		return ""
+	}
+	best, bestDist := "", len(name)/4+2
+	for _, info := range Known(backend) {
+		for _, candidate := range append([]string{info.Name}, info.Aliases...) {
+			// A prefix of a dated or tagged name ("claude-sonnet-4") is a near miss
+			if len(name) >= 4 && strings.HasPrefix(candidate, name) {
+				return info.Name
+			}
+			if d := editDistance(name, candidate); d < bestDist {
+				best, bestDist = info.Name, d
+			}
+		}
+	}
+	return best
+}
+
+// editDistance is the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,70 @@
+package models
+
+import "testing"
+
+func TestLookup(t *testing.T) {
+	tests := []struct {
+		backend, model string
+		wantName       string
+		wantKnown      bool
+	}{
+		{"anthropic", "claude-sonnet-4-20250514", "claude-sonnet-4-20250514", true},
+		{"claude", "claude-3-5-haiku-latest", "claude-3-5-haiku-latest", true},
+		{"gemini", "flash", "gemini-2.5-flash", true},
+		{"ollama", "gpt-oss", "gpt-oss:latest", true},
+		{"ollama", "library/gpt-oss:20b", "gpt-oss:20b", true},
+		{"openai", "gpt-5-mimi", "", false},
+		{"openai", "claude-3-7-sonnet-latest", "", false},
+	}
+	for _, tt := range tests {
+		info, known := Lookup(tt.backend, tt.model)
+		if known != tt.wantKnown || info.Name != tt.wantName {
+			t.Errorf("Lookup(%q, %q) = %q, %v; want %q, %v", tt.backend, tt.model, info.Name, known, tt.wantName, tt.wantKnown)
+		}
+	}
+
+	if info, _ := Lookup("gemini", "gemini-2.5-pro"); info.ContextWindow != 1048576 || !info.Vision {
+		t.Errorf("unexpected gemini-2.5-pro capabilities: %+v", info)
+	}
+	if info, _ := Lookup("anthropic", "claude-3-5-haiku-latest"); info.Thinking {
+		t.Error("claude-3-5-haiku-latest should not support thinking")
+	}
+	if info, _ := Lookup("ollama", "gemma3:latest"); info.Tools {
+		t.Error("gemma3 should not support native tool calling")
+	}
+	if info, _ := Lookup("openai", "gpt-4o-mini"); info.MaxOutputTokens != 4096 {
+		t.Errorf("gpt-4o-mini max output tokens = %d, want 4096", info.MaxOutputTokens)
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	tests := []struct {
+		backend, model, want string
+	}{
+		{"openai", "gpt-5-mimi", "gpt-5-mini"},
+		{"openai", "gpt5", "gpt-5"},
+		{"anthropic", "claude-sonnet-4", "claude-sonnet-4-20250514"},
+		{"anthropic", "claude-3-7-sonet-latest", "claude-3-7-sonnet-latest"},
+		{"ollama", "gpt-os:20b", "gpt-oss:20b"},
+		{"gemini", "gemini-2.5-flsh", "gemini-2.5-flash"},
+		{"openai", "llama3", ""},
+		{"anthropic", "", ""},
+	}
+	for _, tt := range tests {
+		if got := Suggest(tt.backend, tt.model); got != tt.want {
+			t.Errorf("Suggest(%q, %q) = %q, want %q", tt.backend, tt.model, got, tt.want)
+		}
+	}
+}
+
+func TestContextWindow(t *testing.T) {
+	if got := ContextWindow("gemma3:latest"); got != 8192 {
+		t.Errorf("gemma3 context window = %d, want 8192", got)
+	}
+	if got := ContextWindow("gpt-4o"); got != 128000 {
+		t.Errorf("gpt-4o context window = %d, want 128000", got)
+	}
+	if got := ContextWindow("unknown-model"); got != 0 {
+		t.Errorf("unknown model context window = %d, want 0", got)
+	}
+}
@@ -6,8 +6,10 @@ import (
 	"strings"
 
 	"github.com/fpt/go-gennai-cli/pkg/agent/domain"
+	"github.com/fpt/go-gennai-cli/pkg/httpclient"
 	"github.com/fpt/go-gennai-cli/pkg/message"
 	"github.com/ollama/ollama/api"
+	"github.com/ollama/ollama/envconfig"
 	"github.com/pkg/errors"
 )
 
@@ -19,9 +21,13 @@ type OllamaCore struct {
 	client    *api.Client
 	model     string
 	maxTokens int
-	thinking  bool // Settings-based thinking control
+	thinking  bool          // Settings-based thinking control
+	keepAlive *api.Duration // How long the server keeps the model loaded (nil = server default)
+	numCtx    int           // Context window the server allocates (num_ctx; 0 = server default)
 	// Telemetry
 	lastUsage message.TokenUsage
+	// Receives content deltas while set (domain.ResponseStreamer)
+	responseChan chan<- string
 }
 
 // NewOllamaCore creates a new Ollama core with shared resources
@@ -36,10 +42,8 @@ func NewOllamaCoreWithTokens(model string, maxTokens int) (*OllamaCore, error) {
 
 // NewOllamaCoreWithOptions creates a new Ollama core with configurable maxTokens and thinking
 func NewOllamaCoreWithOptions(model string, maxTokens int, thinking bool) (*OllamaCore, error) {
-	client, err := api.ClientFromEnvironment()
-	if err != nil {
-		return nil, fmt.Errorf("failed to create Ollama client: %w", err)
-	}
+	// Same host resolution as api.ClientFromEnvironment, on the shared transport
+	client := api.NewClient(envconfig.Host(), httpclient.New(0))
 
 	// Use default maxTokens if not specified
 	if maxTokens <= 0 {
@@ -51,6 +55,7 @@ func NewOllamaCoreWithOptions(model string, maxTokens int, thinking bool) (*Olla
 		model:     model,
 		maxTokens: maxTokens,
 		thinking:  thinking,
+		numCtx:    GetModelContextWindow(model),
 	}, nil
 }
 
@@ -68,13 +73,22 @@ func (c *OllamaCore) chat(ctx context.Context, chatRequest *api.ChatRequest, thi
 	var result api.Message
 	var contentBuilder strings.Builder
 	var thinkingBuilder strings.Builder
+	var toolCalls []api.ToolCall
+
+	// Clear usage so a failed request never reports the previous call's counts
+	c.lastUsage = message.TokenUsage{}
 
 	err := c.client.Chat(ctx, chatRequest, func(resp api.ChatResponse) error {
 		// Accumulate content and thinking from streaming responses
 		if resp.Message.Content != "" {
+			message.SendResponseContent(ctx, c.responseChan, resp.Message.Content)
 			contentBuilder.WriteString(resp.Message.Content)
 		}
 
+		// Tool calls arrive in the chunk where the model finished them, usually
+		// before the final one, so collect every call of the response
+		toolCalls = append(toolCalls, resp.Message.ToolCalls...)
+
 		if resp.Message.Thinking != "" {
 			// Send thinking content to channel if enabled
 			if shouldShowThinking(c.thinking, chatRequest.Think) && thinkingChan != nil {
@@ -90,26 +104,16 @@ func (c *OllamaCore) chat(ctx context.Context, chatRequest *api.ChatRequest, thi
 				message.EndThinking(thinkingChan)
 			}
 
-			// Capture token usage counts on final chunk when available
-			// Ollama typically exposes prompt_eval_count (input) and eval_count (output)
-			// Convert to our TokenUsage format for telemetry and logging
-			// These fields may be zero if the backend doesn't supply them.
-			c.lastUsage = message.TokenUsage{}
-			// Use reflection-like safe access via documented fields
-			// Direct struct fields are used here; if API changes, counts will stay zero.
-			c.lastUsage.InputTokens = int(resp.PromptEvalCount)
-			c.lastUsage.OutputTokens = int(resp.EvalCount)
-			c.lastUsage.TotalTokens = c.lastUsage.InputTokens + c.lastUsage.OutputTokens
+			// Capture real token counts reported on the final chunk
+			c.lastUsage = usageFromResponse(resp)
+			c.noteLoad(resp.LoadDuration)
 
 			// Combine accumulated content and thinking
 			result = api.Message{
-				Role:     resp.Message.Role,
-				Content:  contentBuilder.String(),
-				Thinking: thinkingBuilder.String(),
-			}
-			// Copy other fields from the final response
-			if len(resp.Message.ToolCalls) > 0 {
-				result.ToolCalls = resp.Message.ToolCalls
+				Role:      resp.Message.Role,
+				Content:   contentBuilder.String(),
+				Thinking:  thinkingBuilder.String(),
+				ToolCalls: toolCalls,
 			}
 		}
 
@@ -119,6 +123,27 @@ func (c *OllamaCore) chat(ctx context.Context, chatRequest *api.ChatRequest, thi
 	return result, errors.Wrap(err, "ollama chat error")
 }
 
+// usageFromResponse converts the prompt_eval_count (input) and eval_count (output)
+// metrics of a final Ollama response into TokenUsage. Counts are zero when the
+// backend omits them (e.g. prompt_eval_count for a fully cached prompt), in which
+// case LastTokenUsage reports no input and callers fall back to estimates.
+func usageFromResponse(resp api.ChatResponse) message.TokenUsage {
+	usage := message.TokenUsage{
+		InputTokens:  int(resp.PromptEvalCount),
+		OutputTokens: int(resp.EvalCount),
+	}
+	usage.TotalTokens = usage.InputTokens + usage.OutputTokens
+	return usage
+}
+
+// LastTokenUsage returns the token counts reported by the most recent request
+func (c *OllamaCore) LastTokenUsage() (message.TokenUsage, bool) {
+	if c.lastUsage.InputTokens != 0 || c.lastUsage.OutputTokens != 0 || c.lastUsage.TotalTokens != 0 {
+		return c.lastUsage, true
+	}
+	return message.TokenUsage{}, false
+}
+
 // shouldShowThinking determines if thinking should be displayed based on settings and request parameters
 func shouldShowThinking(settingsThinking bool, requestThink *api.ThinkValue) bool {
 	if requestThink != nil {
@@ -179,6 +204,9 @@ func (c *OllamaClient) IsToolCapable() bool {
 	return capable
 }
 
+// ResponseStreamer implementation
+func (c *OllamaClient) SetResponseChannel(ch chan<- string) { c.responseChan = ch }
+
 // SetToolManager sets the tool manager for native tool calling
 func (c *OllamaClient) SetToolManager(toolManager domain.ToolManager) {
 	c.toolManager = toolManager
@@ -201,15 +229,15 @@ func (c *OllamaClient) ModelID() string { return c.model }
 
 // ContextWindowProvider implementation
 func (c *OllamaClient) MaxContextTokens() int {
+	if c.numCtx > 0 {
+		return c.numCtx
+	}
 	return GetModelContextWindow(c.model)
 }
 
 // TokenUsageProvider implementation
 func (c *OllamaClient) LastTokenUsage() (message.TokenUsage, bool) {
-	if c.lastUsage.InputTokens != 0 || c.lastUsage.OutputTokens != 0 || c.lastUsage.TotalTokens != 0 {
-		return c.lastUsage, true
-	}
-	return message.TokenUsage{}, false
+	return c.OllamaCore.LastTokenUsage()
 }
 
 // ChatWithToolChoice sends a message to Ollama with tool choice control
@@ -218,12 +246,13 @@ func (c *OllamaClient) ChatWithToolChoice(ctx context.Context, messages []messag
 	ollamaMessages := toOllamaMessages(messages)
 
 	chatRequest := &api.ChatRequest{
-		Model:    c.model,
-		Messages: ollamaMessages,
-		Options: map[string]any{
+		Model:     c.model,
+		Messages:  ollamaMessages,
+		KeepAlive: c.keepAlive,
+		Options: c.modelOptions(map[string]any{
 			"temperature": temperature,
 			"num_predict": c.maxTokens, // Max output tokens for Ollama
-		},
+		}),
 	}
 
 	// Handle tool choice for tool-capable models
@@ -264,12 +293,13 @@ func (c *OllamaClient) chatWithOptions(ctx context.Context, messages []message.M
 	ollamaMessages := toOllamaMessages(messages)
 
 	chatRequest := &api.ChatRequest{
-		Model:    c.model,
-		Messages: ollamaMessages,
-		Options: map[string]any{
+		Model:     c.model,
+		Messages:  ollamaMessages,
+		KeepAlive: c.keepAlive,
+		Options: c.modelOptions(map[string]any{
 			"temperature": temperature,
 			"num_predict": c.maxTokens, // Max output tokens for Ollama
-		},
+		}),
 	}
 
 	// Set thinking parameter if supported
@@ -0,0 +1,89 @@
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fpt/go-gennai-cli/pkg/message"
+)
+
+func TestChatReturnsAllToolCalls(t *testing.T) {
+	readCall := func(path string) map[string]any {
+		return map[string]any{"function": map[string]any{"name": "Read", "arguments": map[string]any{"file_path": path}}}
+	}
+	chunk := func(done bool, calls ...map[string]any) map[string]any {
+		msg := map[string]any{"role": "assistant", "content": ""}
+		if len(calls) > 0 {
+			msg["tool_calls"] = calls
+		}
+		return map[string]any{"model": "gpt-oss:latest", "message": msg, "done": done}
+	}
+
+	tests := []struct {
+		name   string
+		chunks []map[string]any
+		want   []string // file_path of each call, in order
+	}{
+		{
+			name:   "single call",
+			chunks: []map[string]any{chunk(false, readCall("a.go")), chunk(true)},
+			want:   []string{"a.go"},
+		},
+		{
+			name:   "parallel calls in one chunk",
+			chunks: []map[string]any{chunk(false, readCall("a.go"), readCall("b.go")), chunk(true)},
+			want:   []string{"a.go", "b.go"},
+		},
+		{
+			name:   "parallel calls across chunks",
+			chunks: []map[string]any{chunk(false, readCall("a.go")), chunk(false, readCall("b.go")), chunk(true, readCall("c.go"))},
+			want:   []string{"a.go", "b.go", "c.go"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				encoder := json.NewEncoder(w)
+				for _, c := range tt.chunks {
+					_ = encoder.Encode(c)
+				}
+			}))
+			defer server.Close()
+			t.Setenv("OLLAMA_HOST", server.URL)
+
+			client, err := NewOllamaClient("gpt-oss:latest", 0, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := client.Chat(context.Background(), []message.Message{message.NewChatMessage(message.MessageTypeUser, "read the files")}, false, nil)
+			if err != nil {
+				t.Fatalf("chat failed: %v", err)
+			}
+
+			var calls []*message.ToolCallMessage
+			switch m := resp.(type) {
+			case *message.ToolCallMessage:
+				calls = []*message.ToolCallMessage{m}
+			case *message.ToolCallBatchMessage:
+				calls = m.Calls()
+				if len(tt.want) == 1 {
+					t.Errorf("expected a single call to stay a ToolCallMessage")
+				}
+			default:
+				t.Fatalf("expected tool calls, got %T", resp)
+			}
+			if len(calls) != len(tt.want) {
+				t.Fatalf("expected %d calls, got %d", len(tt.want), len(calls))
+			}
+			for i, call := range calls {
+				if call.ToolName() != "Read" || call.ToolArguments()["file_path"] != tt.want[i] {
+					t.Errorf("call %d = %s %v, want Read %s", i, call.ToolName(), call.ToolArguments(), tt.want[i])
+				}
+			}
+		})
+	}
+}
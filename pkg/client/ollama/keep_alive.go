@@ -0,0 +1,59 @@
+package ollama
+
+import (
+	"context"
+	"time"
+
+	pkgLogger "github.com/fpt/go-gennai-cli/pkg/logger"
+	"github.com/ollama/ollama/api"
+	"github.com/pkg/errors"
+)
+
+// coldLoadThreshold is the load time above which a request is reported as
+// having waited for the model to be loaded into memory
+const coldLoadThreshold = time.Second
+
+// SetKeepAlive sets how long the server keeps the model loaded after each
+// request; a negative duration keeps it loaded until the server stops
+func (c *OllamaCore) SetKeepAlive(d time.Duration) {
+	c.keepAlive = &api.Duration{Duration: d}
+}
+
+// Preload loads the model with an empty prompt so that the first chat does
+// not wait for it, and reports how long loading took
+func (c *OllamaCore) Preload(ctx context.Context) error {
+	stream := false
+	req := &api.GenerateRequest{
+		Model:     c.model,
+		KeepAlive: c.keepAlive,
+		Stream:    &stream,
+		// Loading with a different num_ctx than the chats would load the model twice
+		Options: c.modelOptions(nil),
+	}
+	var loadDuration time.Duration
+	err := c.client.Generate(ctx, req, func(resp api.GenerateResponse) error {
+		loadDuration = resp.LoadDuration
+		return nil
+	})
+	if err != nil {
+		return errors.Wrap(err, "ollama preload error")
+	}
+	if loadDuration >= coldLoadThreshold {
+		logger.InfoWithIntention(pkgLogger.IntentionStatus, "Loaded Ollama model",
+			"model", c.model, "load_duration", loadDuration.Round(time.Millisecond))
+	} else {
+		logger.DebugWithIntention(pkgLogger.IntentionDebug, "Ollama model already loaded", "model", c.model)
+	}
+	return nil
+}
+
+// noteLoad logs a request that had to wait for the model to load, which
+// explains a slow first response
+func (c *OllamaCore) noteLoad(loadDuration time.Duration) {
+	if loadDuration < coldLoadThreshold {
+		return
+	}
+	logger.InfoWithIntention(pkgLogger.IntentionStatus, "Ollama model was not loaded; this response waited for it",
+		"model", c.model, "load_duration", loadDuration.Round(time.Millisecond),
+		"hint", "set llm.keep_alive (e.g. \"30m\" or \"-1\") to keep it loaded")
+}
@@ -0,0 +1,40 @@
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestPreloadSendsKeepAlive(t *testing.T) {
+	var got map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/generate" {
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("invalid request body: %v", err)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"model": "gpt-oss:latest", "done": true, "load_duration": int64(2 * time.Second)})
+	}))
+	defer server.Close()
+	t.Setenv("OLLAMA_HOST", server.URL)
+
+	core, err := NewOllamaCore("gpt-oss:latest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	core.SetKeepAlive(-1)
+	if err := core.Preload(context.Background()); err != nil {
+		t.Fatalf("preload failed: %v", err)
+	}
+	if got["model"] != "gpt-oss:latest" || got["keep_alive"] != float64(-1) {
+		t.Errorf("unexpected preload request %v", got)
+	}
+	if prompt, ok := got["prompt"]; ok && prompt != "" {
+		t.Errorf("expected an empty prompt, got %q", prompt)
+	}
+}
@@ -0,0 +1,25 @@
+package ollama
+
+// SetNumCtx sets the context window the server allocates for the model. Without
+// num_ctx Ollama uses its own default, often 2048 or 4096 tokens, and silently
+// drops the start of longer conversations. 0 leaves it to the server.
+func (c *OllamaCore) SetNumCtx(tokens int) {
+	c.numCtx = tokens
+}
+
+// NumCtx returns the context window sent with each request (0 = server default).
+// It defaults to the model's known context window.
+func (c *OllamaCore) NumCtx() int {
+	return c.numCtx
+}
+
+// modelOptions adds the options every request for this model shares to options
+func (c *OllamaCore) modelOptions(options map[string]any) map[string]any {
+	if options == nil {
+		options = make(map[string]any)
+	}
+	if c.numCtx > 0 {
+		options["num_ctx"] = c.numCtx
+	}
+	return options
+}
@@ -0,0 +1,56 @@
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fpt/go-gennai-cli/pkg/message"
+)
+
+func TestChatSendsNumCtx(t *testing.T) {
+	var got map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("invalid request body: %v", err)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"model":   "gpt-oss:latest",
+			"message": map[string]any{"role": "assistant", "content": "hi"},
+			"done":    true,
+		})
+	}))
+	defer server.Close()
+	t.Setenv("OLLAMA_HOST", server.URL)
+
+	client, err := NewOllamaClient("gpt-oss:latest", 0, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	messages := []message.Message{message.NewChatMessage(message.MessageTypeUser, "hello")}
+
+	// Defaults to the model's known context window
+	if _, err := client.Chat(ctx, messages, false, nil); err != nil {
+		t.Fatalf("chat failed: %v", err)
+	}
+	options, _ := got["options"].(map[string]any)
+	if options["num_ctx"] != float64(GetModelContextWindow("gpt-oss:latest")) {
+		t.Errorf("expected num_ctx to default to the model's context window, got options %v", options)
+	}
+
+	ollamaClient := client.(*OllamaClient)
+	ollamaClient.SetNumCtx(32768)
+	if _, err := client.Chat(ctx, messages, false, nil); err != nil {
+		t.Fatalf("chat failed: %v", err)
+	}
+	options, _ = got["options"].(map[string]any)
+	if options["num_ctx"] != float64(32768) {
+		t.Errorf("expected configured num_ctx 32768, got options %v", options)
+	}
+	if ollamaClient.MaxContextTokens() != 32768 {
+		t.Errorf("expected the context window to follow num_ctx, got %d", ollamaClient.MaxContextTokens())
+	}
+}
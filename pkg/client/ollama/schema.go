@@ -0,0 +1,53 @@
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/fpt/go-gennai-cli/pkg/agent/domain"
+	"github.com/fpt/go-gennai-cli/pkg/message"
+	"github.com/ollama/ollama/api"
+)
+
+// ChatWithSchema implements domain.SchemaLLM by passing the schema as the
+// request format. Models without JSON Schema support return domain.ErrSchemaUnsupported.
+func (c *OllamaClient) ChatWithSchema(ctx context.Context, messages []message.Message, schema json.RawMessage) (string, error) {
+	if !IsJSONSchemaCapableModel(c.model) {
+		return "", fmt.Errorf("model %s: %w", c.model, domain.ErrSchemaUnsupported)
+	}
+
+	req := &api.ChatRequest{
+		Model:     c.model,
+		Messages:  toOllamaMessages(messages),
+		Format:    schema,
+		KeepAlive: c.keepAlive,
+		Options: c.modelOptions(map[string]any{
+			"temperature": temperature,
+			"num_predict": c.maxTokens,
+		}),
+		Stream: &[]bool{false}[0], // Disable streaming for structured output
+	}
+	if IsThinkingCapableModel(c.model) {
+		// Keep reasoning out of the JSON answer
+		req.Think = &api.ThinkValue{Value: false}
+	}
+
+	c.lastUsage = message.TokenUsage{}
+	resp := &api.ChatResponse{}
+	err := c.client.Chat(ctx, req, func(response api.ChatResponse) error {
+		*resp = response
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("ollama chat failed: %w", err)
+	}
+	c.lastUsage = usageFromResponse(*resp)
+
+	if resp.Message.Content == "" {
+		return "", fmt.Errorf("empty structured response from Ollama")
+	}
+	return resp.Message.Content, nil
+}
+
+var _ domain.SchemaLLM = (*OllamaClient)(nil)
@@ -64,9 +64,9 @@ func (c *OllamaStructuredClient[T]) ChatWithStructure(ctx context.Context, messa
 		Model:    c.core.model,
 		Messages: ollamaMessages,
 		Format:   schema, // Use JSON Schema for structured output
-		Options: map[string]any{
+		Options: c.core.modelOptions(map[string]any{
 			"num_predict": c.core.maxTokens,
-		},
+		}),
 		Stream: &[]bool{false}[0], // Disable streaming for structured output
 	}
 
@@ -76,6 +76,7 @@ func (c *OllamaStructuredClient[T]) ChatWithStructure(ctx context.Context, messa
 	}
 
 	// Send request to Ollama
+	c.core.lastUsage = message.TokenUsage{}
 	resp := &api.ChatResponse{}
 	err = c.core.client.Chat(ctx, req, func(response api.ChatResponse) error {
 		*resp = response
@@ -85,6 +86,7 @@ func (c *OllamaStructuredClient[T]) ChatWithStructure(ctx context.Context, messa
 	if err != nil {
 		return zero, fmt.Errorf("ollama chat failed: %w", err)
 	}
+	c.core.lastUsage = usageFromResponse(*resp)
 
 	// Parse the JSON response into the target type
 	var result T
@@ -113,6 +115,12 @@ func (c *OllamaStructuredClient[T]) GetSchema() T {
 // ModelID returns the underlying model identifier
 func (c *OllamaStructuredClient[T]) ModelID() string { return c.core.model }
 
+// LastTokenUsage implements domain.TokenUsageProvider
+func (c *OllamaStructuredClient[T]) LastTokenUsage() (message.TokenUsage, bool) {
+	return c.core.LastTokenUsage()
+}
+
 // Ensure OllamaStructuredClient implements the required interfaces
 var _ domain.LLM = (*OllamaStructuredClient[any])(nil)
+var _ domain.TokenUsageProvider = (*OllamaStructuredClient[any])(nil)
 var _ domain.StructuredLLM[any] = (*OllamaStructuredClient[any])(nil)
@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"reflect"
 	"testing"
+
+	"github.com/ollama/ollama/api"
 )
 
 // Test structures for structured client testing
@@ -174,3 +176,30 @@ func TestOllamaStructuredClient_ComplexTypes(t *testing.T) {
 		t.Error("Schema should return zero value for complex nested types")
 	}
 }
+
+func TestOllamaCore_LastTokenUsage(t *testing.T) {
+	core := &OllamaCore{model: "test-model"}
+	client := NewOllamaStructuredClient[TestResponse](core)
+
+	if _, ok := client.LastTokenUsage(); ok {
+		t.Error("Expected no usage before any request")
+	}
+
+	core.lastUsage = usageFromResponse(api.ChatResponse{
+		Done:    true,
+		Metrics: api.Metrics{PromptEvalCount: 1200, EvalCount: 85},
+	})
+	usage, ok := client.LastTokenUsage()
+	if !ok {
+		t.Fatal("Expected usage to be reported")
+	}
+	if usage.InputTokens != 1200 || usage.OutputTokens != 85 || usage.TotalTokens != 1285 {
+		t.Errorf("Unexpected usage: %+v", usage)
+	}
+
+	// Omitted counts report no usage so callers fall back to estimates
+	core.lastUsage = usageFromResponse(api.ChatResponse{Done: true})
+	if _, ok := client.LastTokenUsage(); ok {
+		t.Error("Expected no usage when the API omits counts")
+	}
+}
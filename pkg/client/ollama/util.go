@@ -18,22 +18,16 @@ const roleSystem = "system"
 // When includeThinking is true and thinking text is present, it attaches thinking.
 // Tool calls are converted to ToolCall or ToolCallBatch messages regardless of includeThinking.
 func toDomainMessageFromOllama(msg api.Message, includeThinking bool) message.Message {
-	// Handle tool calls from the model first
-	if len(msg.ToolCalls) == 1 {
-		toolCall := msg.ToolCalls[0]
-		return message.NewToolCallMessage(
-			message.ToolName(toolCall.Function.Name),
-			message.ToolArgumentValues(toolCall.Function.Arguments),
-		)
-	} else if len(msg.ToolCalls) > 1 {
-		var calls []*message.ToolCallMessage
+	// Handle tool calls from the model first; several calls become a batch
+	if len(msg.ToolCalls) > 0 {
+		calls := make([]*message.ToolCallMessage, 0, len(msg.ToolCalls))
 		for _, tc := range msg.ToolCalls {
 			calls = append(calls, message.NewToolCallMessage(
 				message.ToolName(tc.Function.Name),
 				message.ToolArgumentValues(tc.Function.Arguments),
 			))
 		}
-		return message.NewToolCallBatch(calls)
+		return message.NewToolCallResponse(calls)
 	}
 
 	// Assistant text response (thinking optional)
@@ -61,18 +55,7 @@ func toOllamaMessages(messages []message.Message) []api.Message {
 
 			// Add images if present
 			if images := msg.Images(); len(images) > 0 {
-				ollamaMsg.Images = make([]api.ImageData, len(images))
-				for i, imageData := range images {
-					// Always assume Base64 data and decode to raw binary
-					if decodedData, err := base64.StdEncoding.DecodeString(imageData); err == nil {
-						ollamaMsg.Images[i] = api.ImageData(decodedData) // Use raw binary data
-						logger.DebugWithIntention(pkgLogger.IntentionDebug, "Using Base64 image data", "decoded_bytes", len(decodedData))
-					} else {
-						logger.Warn("Failed to decode Base64 image data", "error", err)
-						// Fallback to treating as raw data (though this probably won't work)
-						ollamaMsg.Images[i] = api.ImageData(imageData)
-					}
-				}
+				ollamaMsg.Images = toImageData(images)
 			}
 
 			// Add thinking if present
@@ -110,10 +93,15 @@ func toOllamaMessages(messages []message.Message) []api.Message {
 				}
 				// For now, just send as a regular user message
 				// TODO: Implement proper tool result handling when API supports it
-				ollamaMessages = append(ollamaMessages, api.Message{
+				resultMsg := api.Message{
 					Role:    "user", // Tool results come from user/tool perspective
 					Content: content,
-				})
+				}
+				// Images a tool returned (e.g. Read on a PNG) travel with the result
+				if images := toolResultMsg.Images(); len(images) > 0 {
+					resultMsg.Images = toImageData(images)
+				}
+				ollamaMessages = append(ollamaMessages, resultMsg)
 			}
 		case message.MessageTypeToolCallBatch:
 			// Skip batch container in request reconstruction; individual calls/results are present
@@ -124,6 +112,23 @@ func toOllamaMessages(messages []message.Message) []api.Message {
 	return ollamaMessages
 }
 
+// toImageData decodes Base64 images to the raw bytes Ollama expects
+func toImageData(images []string) []api.ImageData {
+	data := make([]api.ImageData, len(images))
+	for i, imageData := range images {
+		// Always assume Base64 data and decode to raw binary
+		if decodedData, err := base64.StdEncoding.DecodeString(imageData); err == nil {
+			data[i] = api.ImageData(decodedData) // Use raw binary data
+			logger.DebugWithIntention(pkgLogger.IntentionDebug, "Using Base64 image data", "decoded_bytes", len(decodedData))
+		} else {
+			logger.Warn("Failed to decode Base64 image data", "error", err)
+			// Fallback to treating as raw data (though this probably won't work)
+			data[i] = api.ImageData(imageData)
+		}
+	}
+	return data
+}
+
 // convertToOllamaTools converts domain tools to Ollama API tool format
 func convertToOllamaTools(tools map[message.ToolName]message.Tool) api.Tools {
 	var ollamaTools api.Tools
@@ -0,0 +1,93 @@
+package openai
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/openai/openai-go/v2"
+	"github.com/openai/openai-go/v2/option"
+
+	"github.com/fpt/go-gennai-cli/pkg/httpclient"
+)
+
+// DefaultAzureAPIVersion is the Azure OpenAI API version used when none is
+// configured; it is the first one that serves the Responses API
+const DefaultAzureAPIVersion = "2025-03-01-preview"
+
+// AzureConfig locates an Azure OpenAI resource
+type AzureConfig struct {
+	Endpoint   string // e.g. https://my-resource.openai.azure.com
+	APIKey     string
+	APIVersion string // defaults to DefaultAzureAPIVersion
+	Model      string // OpenAI model behind the deployment, for capabilities; defaults to the deployment name
+}
+
+// AzureConfigFromEnv reads AZURE_OPENAI_ENDPOINT, AZURE_OPENAI_API_KEY,
+// AZURE_OPENAI_API_VERSION and AZURE_OPENAI_MODEL
+func AzureConfigFromEnv() AzureConfig {
+	return AzureConfig{
+		Endpoint:   os.Getenv("AZURE_OPENAI_ENDPOINT"),
+		APIKey:     os.Getenv("AZURE_OPENAI_API_KEY"),
+		APIVersion: os.Getenv("AZURE_OPENAI_API_VERSION"),
+		Model:      os.Getenv("AZURE_OPENAI_MODEL"),
+	}
+}
+
+// Validate reports the first required setting that is missing
+func (c AzureConfig) Validate() error {
+	if c.Endpoint == "" {
+		return fmt.Errorf("AZURE_OPENAI_ENDPOINT environment variable not set")
+	}
+	if !strings.HasPrefix(c.Endpoint, "https://") && !strings.HasPrefix(c.Endpoint, "http://") {
+		return fmt.Errorf("AZURE_OPENAI_ENDPOINT must be a URL like https://<resource>.openai.azure.com, got %q", c.Endpoint)
+	}
+	if c.APIKey == "" {
+		return fmt.Errorf("AZURE_OPENAI_API_KEY environment variable not set")
+	}
+	return nil
+}
+
+// NewAzureOpenAIClient creates a client for an Azure OpenAI deployment. Azure
+// takes the deployment name where OpenAI takes the model name, authenticates
+// with an api-key header and requires an api-version on every request; the
+// message conversion and tool calling are the same as for OpenAI.
+func NewAzureOpenAIClient(deployment string, maxTokens int, cfg AzureConfig) (*OpenAIClient, error) {
+	if deployment == "" {
+		return nil, fmt.Errorf("Azure OpenAI deployment name is required")
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	if cfg.APIVersion == "" {
+		cfg.APIVersion = DefaultAzureAPIVersion
+	}
+
+	client := openai.NewClient(
+		option.WithBaseURL(strings.TrimRight(cfg.Endpoint, "/")+"/openai/"),
+		option.WithQuery("api-version", cfg.APIVersion),
+		option.WithHeader("api-key", cfg.APIKey),
+		// Don't send credentials the SDK picks up from OPENAI_* variables
+		option.WithHeaderDel("authorization"),
+		option.WithHeaderDel("OpenAI-Organization"),
+		option.WithHeaderDel("OpenAI-Project"),
+		option.WithHTTPClient(httpclient.New(0)),
+	)
+
+	baseModel := getOpenAIModel(deployment)
+	if cfg.Model != "" {
+		baseModel = getOpenAIModel(cfg.Model)
+	}
+	if maxTokens <= 0 {
+		maxTokens = getModelCapabilities(baseModel).MaxTokens
+	}
+
+	return &OpenAIClient{
+		OpenAICore: &OpenAICore{
+			client:    &client,
+			model:     deployment,
+			baseModel: baseModel,
+			maxTokens: maxTokens,
+		},
+	}, nil
+}
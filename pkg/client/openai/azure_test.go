@@ -0,0 +1,90 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/fpt/go-gennai-cli/pkg/message"
+)
+
+func TestAzureConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     AzureConfig
+		wantErr string
+	}{
+		{"missing endpoint", AzureConfig{APIKey: "k"}, "AZURE_OPENAI_ENDPOINT"},
+		{"endpoint not a URL", AzureConfig{Endpoint: "my-resource", APIKey: "k"}, "must be a URL"},
+		{"missing key", AzureConfig{Endpoint: "https://r.openai.azure.com"}, "AZURE_OPENAI_API_KEY"},
+		{"complete", AzureConfig{Endpoint: "https://r.openai.azure.com", APIKey: "k"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	if _, err := NewAzureOpenAIClient("", 0, AzureConfig{Endpoint: "https://r.openai.azure.com", APIKey: "k"}); err == nil {
+		t.Error("expected an error for a missing deployment")
+	}
+}
+
+func TestNewAzureOpenAIClient_Request(t *testing.T) {
+	// Credentials for public OpenAI must not leak to Azure
+	t.Setenv("OPENAI_API_KEY", "openai-key")
+
+	var gotPath, gotVersion, gotKey, gotAuth, gotModel string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotVersion = r.URL.Path, r.URL.Query().Get("api-version")
+		gotKey, gotAuth = r.Header.Get("api-key"), r.Header.Get("Authorization")
+		var body struct {
+			Model string `json:"model"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		gotModel = body.Model
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"resp_1","object":"response","status":"completed","output":[{"type":"message","id":"msg_1","role":"assistant","status":"completed","content":[{"type":"output_text","text":"hello","annotations":[]}]}]}`))
+	}))
+	defer server.Close()
+
+	client, err := NewAzureOpenAIClient("my-gpt4o", 0, AzureConfig{Endpoint: server.URL + "/", APIKey: "azure-key", Model: "gpt-4o"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.ModelID() != "my-gpt4o" {
+		t.Errorf("expected the deployment as model ID, got %s", client.ModelID())
+	}
+	if client.maxTokens != getModelCapabilities("gpt-4o").MaxTokens || client.capabilities().SupportsThinking {
+		t.Errorf("expected gpt-4o capabilities for the deployment")
+	}
+
+	resp, err := client.Chat(context.Background(), []message.Message{message.NewChatMessage(message.MessageTypeUser, "hi")}, false, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Content() != "hello" {
+		t.Errorf("unexpected response %q", resp.Content())
+	}
+	if gotPath != "/openai/responses" || gotVersion != DefaultAzureAPIVersion {
+		t.Errorf("unexpected request %s?api-version=%s", gotPath, gotVersion)
+	}
+	if gotKey != "azure-key" || gotAuth != "" {
+		t.Errorf("unexpected auth headers api-key=%q Authorization=%q", gotKey, gotAuth)
+	}
+	if gotModel != "my-gpt4o" {
+		t.Errorf("expected the deployment as request model, got %q", gotModel)
+	}
+}
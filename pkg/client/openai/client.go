@@ -13,6 +13,8 @@ import (
 	"github.com/openai/openai-go/v2/shared"
 
 	"github.com/fpt/go-gennai-cli/pkg/agent/domain"
+	"github.com/fpt/go-gennai-cli/pkg/httpclient"
+	pkgLogger "github.com/fpt/go-gennai-cli/pkg/logger"
 	"github.com/fpt/go-gennai-cli/pkg/message"
 )
 
@@ -21,13 +23,16 @@ const defaultReasoningEffort = shared.ReasoningEffortLow // Default reasoning ef
 // OpenAICore holds shared resources for OpenAI clients
 type OpenAICore struct {
 	client    *openai.Client
-	model     string
+	model     string // sent as the request model (the deployment name on Azure)
+	baseModel string // OpenAI model whose capabilities apply; defaults to model
 	maxTokens int
 	// streamingUnsupported is set to true when the API rejects streaming
 	// (e.g., org not verified). Subsequent calls will avoid streaming.
 	streamingUnsupported bool
 }
 
+var openaiLogger = pkgLogger.NewComponentLogger("openai-client")
+
 // OpenAIClient implements ToolCallingLLM and VisionLLM interfaces
 type OpenAIClient struct {
 	*OpenAICore
@@ -37,21 +42,33 @@ type OpenAIClient struct {
 	lastUsage message.TokenUsage
 	sessionID string
 	cacheOpts domain.ModelSideCacheOptions
+
+	// Receives text deltas while set (domain.ResponseStreamer)
+	responseChan chan<- string
 }
 
 // NewOpenAIClient creates a new OpenAI client with configurable maxTokens
 // maxTokens = 0 means default
 func NewOpenAIClient(model string, maxTokens int) (*OpenAIClient, error) {
+	return NewOpenAIClientWithBaseURL(model, maxTokens, os.Getenv("OPENAI_BASE_URL"))
+}
+
+// NewOpenAIClientWithBaseURL creates a new OpenAI client that talks to an OpenAI-compatible
+// endpoint (vLLM, LiteLLM, Azure OpenAI, etc.). An empty baseURL uses the default endpoint.
+func NewOpenAIClientWithBaseURL(model string, maxTokens int, baseURL string) (*OpenAIClient, error) {
 	apiKey := os.Getenv("OPENAI_API_KEY")
 	if apiKey == "" {
 		return nil, fmt.Errorf("OPENAI_API_KEY environment variable not set")
 	}
 
 	// Setup client options
-	opts := []option.RequestOption{option.WithAPIKey(apiKey)}
+	opts := []option.RequestOption{
+		option.WithAPIKey(apiKey),
+		option.WithHTTPClient(httpclient.New(0)),
+	}
 
-	// Support custom base URL (for Azure OpenAI, etc.)
-	if baseURL := os.Getenv("OPENAI_BASE_URL"); baseURL != "" {
+	// Support custom base URL (for self-hosted gateways, Azure OpenAI, etc.)
+	if baseURL != "" {
 		opts = append(opts, option.WithBaseURL(baseURL))
 	}
 
@@ -77,6 +94,14 @@ func NewOpenAIClient(model string, maxTokens int) (*OpenAIClient, error) {
 	}, nil
 }
 
+// capabilities returns the capabilities of the model behind this client
+func (c *OpenAICore) capabilities() ModelCapabilities {
+	if c.baseModel != "" {
+		return getModelCapabilities(c.baseModel)
+	}
+	return getModelCapabilities(c.model)
+}
+
 // NewOpenAIClientFromCore creates a new client instance from existing core (for factory pattern)
 func NewOpenAIClientFromCore(core *OpenAICore) domain.ToolCallingLLM {
 	return &OpenAIClient{
@@ -89,7 +114,7 @@ func (c *OpenAIClient) ModelID() string { return c.model }
 
 // ContextWindowProvider implementation
 func (c *OpenAIClient) MaxContextTokens() int {
-	caps := getModelCapabilities(c.model)
+	caps := c.capabilities()
 	if caps.MaxContextWindow > 0 {
 		return caps.MaxContextWindow
 	}
@@ -109,11 +134,50 @@ func (c *OpenAIClient) LastTokenUsage() (message.TokenUsage, bool) {
 func (c *OpenAIClient) SetSessionID(id string) { c.sessionID = id }
 func (c *OpenAIClient) SessionID() string      { return c.sessionID }
 
-// ModelSideCacheConfigurator implementation (store hints for later use)
+// ModelSideCacheConfigurator implementation. OpenAI caches long prompt
+// prefixes automatically; enabling caching adds a prompt_cache_key so requests
+// sharing the scenario prompt are routed to the same cache.
 func (c *OpenAIClient) ConfigureModelSideCache(opts domain.ModelSideCacheOptions) {
 	c.cacheOpts = opts
 }
 
+// ResponseStreamer implementation
+func (c *OpenAIClient) SetResponseChannel(ch chan<- string) { c.responseChan = ch }
+
+// defaultPromptCacheKey groups requests when no session ID is configured
+const defaultPromptCacheKey = "gennai"
+
+// applyPromptCacheKey sets prompt_cache_key when prompt caching is enabled
+func (c *OpenAIClient) applyPromptCacheKey(params *responses.ResponseNewParams) {
+	if !c.cacheOpts.PromptCachingEnabled {
+		return
+	}
+	key := c.cacheOpts.SessionID
+	if key == "" {
+		key = c.sessionID
+	}
+	if key == "" {
+		key = defaultPromptCacheKey
+	}
+	params.PromptCacheKey = openai.String(key)
+}
+
+// captureUsage records token usage from a response and logs prompt cache hits
+func (c *OpenAIClient) captureUsage(usage responses.ResponseUsage) {
+	if !usage.JSON.InputTokens.Valid() && !usage.JSON.OutputTokens.Valid() && !usage.JSON.TotalTokens.Valid() {
+		return
+	}
+	c.lastUsage = message.TokenUsage{
+		InputTokens:  int(usage.InputTokens),
+		OutputTokens: int(usage.OutputTokens),
+		TotalTokens:  int(usage.TotalTokens),
+	}
+	if cached := usage.InputTokensDetails.CachedTokens; cached > 0 {
+		openaiLogger.DebugWithIntention(pkgLogger.IntentionStatus, "Prompt cache hit",
+			"cached_tokens", cached, "input_tokens", usage.InputTokens)
+	}
+}
+
 // Chat implements the basic LLM interface with thinking control
 func (c *OpenAIClient) Chat(ctx context.Context, messages []message.Message, enableThinking bool, thinkingChan chan<- string) (message.Message, error) {
 	// Also respect cached fallback state from previous attempts
@@ -127,7 +191,10 @@ func (c *OpenAIClient) Chat(ctx context.Context, messages []message.Message, ena
 	}
 
 	// Convert messages to proper structured input
-	inputItems := c.convertMessagesToResponsesInputItems(messages)
+	inputItems, err := c.convertMessagesToResponsesInputItems(messages)
+	if err != nil {
+		return nil, err
+	}
 
 	// Create response parameters
 	params := responses.ResponseNewParams{
@@ -136,6 +203,7 @@ func (c *OpenAIClient) Chat(ctx context.Context, messages []message.Message, ena
 		},
 		Model: shared.ChatModel(c.model),
 	}
+	c.applyPromptCacheKey(&params)
 
 	// Add max tokens if specified
 	if c.maxTokens > 0 {
@@ -143,7 +211,7 @@ func (c *OpenAIClient) Chat(ctx context.Context, messages []message.Message, ena
 	}
 
 	// Add reasoning effort for thinking models
-	caps := getModelCapabilities(c.model)
+	caps := c.capabilities()
 	if caps.SupportsThinking {
 		// Enable reasoning for GPT-5 models to see thinking process
 		params.Reasoning = shared.ReasoningParam{
@@ -171,13 +239,7 @@ func (c *OpenAIClient) Chat(ctx context.Context, messages []message.Message, ena
 	}
 
 	// Capture token usage if provided
-	if resp.Usage.JSON.InputTokens.Valid() || resp.Usage.JSON.OutputTokens.Valid() || resp.Usage.JSON.TotalTokens.Valid() {
-		c.lastUsage = message.TokenUsage{
-			InputTokens:  int(resp.Usage.InputTokens),
-			OutputTokens: int(resp.Usage.OutputTokens),
-			TotalTokens:  int(resp.Usage.TotalTokens),
-		}
-	}
+	c.captureUsage(resp.Usage)
 
 	// Extract response text and reasoning content
 	outputText := resp.OutputText()
@@ -237,7 +299,10 @@ func (c *OpenAIClient) Chat(ctx context.Context, messages []message.Message, ena
 // chatWithStreaming handles streaming responses using the Responses API
 func (c *OpenAIClient) chatWithStreaming(ctx context.Context, messages []message.Message, showThinking bool, thinkingChan chan<- string) (message.Message, error) {
 	// Convert messages to proper structured input
-	inputItems := c.convertMessagesToResponsesInputItems(messages)
+	inputItems, err := c.convertMessagesToResponsesInputItems(messages)
+	if err != nil {
+		return nil, err
+	}
 
 	// Create response parameters
 	params := responses.ResponseNewParams{
@@ -246,6 +311,7 @@ func (c *OpenAIClient) chatWithStreaming(ctx context.Context, messages []message
 		},
 		Model: shared.ChatModel(c.model),
 	}
+	c.applyPromptCacheKey(&params)
 
 	// Add max tokens if specified
 	if c.maxTokens > 0 {
@@ -253,7 +319,7 @@ func (c *OpenAIClient) chatWithStreaming(ctx context.Context, messages []message
 	}
 
 	// Add reasoning effort for thinking models
-	caps := getModelCapabilities(c.model)
+	caps := c.capabilities()
 	if caps.SupportsThinking && showThinking {
 		// Enable reasoning for GPT-5 models to see thinking process
 		params.Reasoning = shared.ReasoningParam{
@@ -288,17 +354,11 @@ func (c *OpenAIClient) chatWithStreaming(ctx context.Context, messages []message
 		// Check the event type to handle different kinds of deltas appropriately
 		switch eventData := event.AsAny().(type) {
 		case responses.ResponseTextDeltaEvent:
-			// This is regular text content - display and accumulate it
+			// This is regular text content - stream and accumulate it
 			if eventData.Delta != "" {
-				fmt.Print(eventData.Delta)
+				message.SendResponseContent(ctx, c.responseChan, eventData.Delta)
 				responseBuilder.WriteString(eventData.Delta)
 			}
-		case responses.ResponseFunctionCallArgumentsDeltaEvent:
-			// This is tool call arguments - display but don't accumulate as response text
-			if eventData.Delta != "" {
-				fmt.Print(eventData.Delta)
-				// Note: We don't add this to responseBuilder since it's tool call args
-			}
 		case responses.ResponseReasoningTextDeltaEvent:
 			// This is reasoning content - accumulate it for thinking
 			if eventData.Delta != "" {
@@ -325,14 +385,13 @@ func (c *OpenAIClient) chatWithStreaming(ctx context.Context, messages []message
 		default:
 			// For other event types, try to extract text delta
 			if textEvent := event.AsResponseOutputTextDelta(); textEvent.Delta != "" {
-				fmt.Print(textEvent.Delta)
+				message.SendResponseContent(ctx, c.responseChan, textEvent.Delta)
 				responseBuilder.WriteString(textEvent.Delta)
 			}
 		}
 
 		// Check if we have a completed response
 		if completedEvent := event.AsResponseCompleted(); completedEvent.Type != "" {
-			fmt.Println()
 			break
 		}
 	}
@@ -355,13 +414,7 @@ func (c *OpenAIClient) chatWithStreaming(ctx context.Context, messages []message
 			}
 
 			// Capture token usage if provided
-			if resp.Usage.JSON.InputTokens.Valid() || resp.Usage.JSON.OutputTokens.Valid() || resp.Usage.JSON.TotalTokens.Valid() {
-				c.lastUsage = message.TokenUsage{
-					InputTokens:  int(resp.Usage.InputTokens),
-					OutputTokens: int(resp.Usage.OutputTokens),
-					TotalTokens:  int(resp.Usage.TotalTokens),
-				}
-			}
+			c.captureUsage(resp.Usage)
 
 			// Extract response text and reasoning content
 			outputText := resp.OutputText()
@@ -422,12 +475,21 @@ func (c *OpenAIClient) chatWithStreaming(ctx context.Context, messages []message
 }
 
 // convertMessagesToResponsesInputItems converts internal messages to structured input items for Responses API
-func (c *OpenAIClient) convertMessagesToResponsesInputItems(messages []message.Message) responses.ResponseInputParam {
+func (c *OpenAIClient) convertMessagesToResponsesInputItems(messages []message.Message) (responses.ResponseInputParam, error) {
 	var inputItems responses.ResponseInputParam
 
 	for _, msg := range messages {
 		switch msg.Type() {
 		case message.MessageTypeUser:
+			if images := msg.Images(); len(images) > 0 {
+				// Send images as input_image parts with Base64 data URIs alongside the text
+				if !c.SupportsVision() {
+					return nil, fmt.Errorf("model %s does not support image input; use a vision-capable model such as %s", c.model, modelGPT4o)
+				}
+				inputItems = append(inputItems, responses.ResponseInputItemParamOfMessage(
+					userContentWithImages(msg.Content(), images), responses.EasyInputMessageRoleUser))
+				continue
+			}
 			// TODO: Should use ResponseInputItemParamOfInputMessage
 			inputItem := responses.ResponseInputItemParamOfMessage(msg.Content(), responses.EasyInputMessageRoleUser)
 			inputItems = append(inputItems, inputItem)
@@ -473,6 +535,12 @@ func (c *OpenAIClient) convertMessagesToResponsesInputItems(messages []message.M
 					toolResultMsg.Result,
 				)
 				inputItems = append(inputItems, inputItem)
+				// Function call output is text only, so images a tool returned
+				// (e.g. Read on a PNG) follow as a user message
+				if images := toolResultMsg.Images(); len(images) > 0 && c.SupportsVision() {
+					inputItems = append(inputItems, responses.ResponseInputItemParamOfMessage(
+						userContentWithImages("[Images returned by the tool call above]", images), responses.EasyInputMessageRoleUser))
+				}
 			} else {
 				// Fallback to message representation if cast fails
 				inputItem := responses.ResponseInputItemParamOfMessage(
@@ -494,11 +562,44 @@ func (c *OpenAIClient) convertMessagesToResponsesInputItems(messages []message.M
 		}
 	}
 
-	// TODO: When Responses API exposes prompt caching controls in openai-go,
-	// we can apply c.cacheOpts (PromptCachingEnabled/PolicyHint/SessionID) to
-	// the appropriate input items or request params here.
+	return inputItems, nil
+}
+
+// userContentWithImages builds a multi-part user message: image parts followed by the text
+func userContentWithImages(content string, images []string) responses.ResponseInputMessageContentListParam {
+	parts := make(responses.ResponseInputMessageContentListParam, 0, len(images)+1)
+	for _, imageData := range images {
+		parts = append(parts, responses.ResponseInputContentUnionParam{
+			OfInputImage: &responses.ResponseInputImageParam{
+				Detail:   responses.ResponseInputImageDetailAuto,
+				ImageURL: openai.String(imageDataURL(imageData)),
+			},
+		})
+	}
+	if content != "" {
+		parts = append(parts, responses.ResponseInputContentUnionParam{
+			OfInputText: &responses.ResponseInputTextParam{Text: content},
+		})
+	}
+	return parts
+}
 
-	return inputItems
+// imageDataURL wraps Base64 image data in a data URI, detecting the format from
+// the Base64 magic prefix (defaults to JPEG). Existing data URIs pass through.
+func imageDataURL(imageData string) string {
+	if strings.HasPrefix(imageData, "data:") {
+		return imageData
+	}
+	mediaType := "image/jpeg"
+	switch {
+	case strings.HasPrefix(imageData, "iVBORw0KGgo"):
+		mediaType = "image/png"
+	case strings.HasPrefix(imageData, "R0lGOD"):
+		mediaType = "image/gif"
+	case strings.HasPrefix(imageData, "UklGR"):
+		mediaType = "image/webp"
+	}
+	return "data:" + mediaType + ";base64," + imageData
 }
 
 // SetToolManager implements ToolCallingLLM interface
@@ -509,14 +610,17 @@ func (c *OpenAIClient) SetToolManager(toolManager domain.ToolManager) {
 // IsToolCapable checks if the OpenAI client supports native tool calling
 func (c *OpenAIClient) IsToolCapable() bool {
 	// Check if the current model supports tool calling
-	caps := getModelCapabilities(c.model)
+	caps := c.capabilities()
 	return caps.SupportsToolCalling
 }
 
 // ChatWithToolChoice implements ToolCallingLLM interface with native OpenAI tool calling
 func (c *OpenAIClient) ChatWithToolChoice(ctx context.Context, messages []message.Message, toolChoice domain.ToolChoice, enableThinking bool, thinkingChan chan<- string) (message.Message, error) {
 	// Convert messages to proper structured input
-	inputItems := c.convertMessagesToResponsesInputItems(messages)
+	inputItems, err := c.convertMessagesToResponsesInputItems(messages)
+	if err != nil {
+		return nil, err
+	}
 
 	// Create response parameters
 	params := responses.ResponseNewParams{
@@ -525,6 +629,7 @@ func (c *OpenAIClient) ChatWithToolChoice(ctx context.Context, messages []messag
 		},
 		Model: shared.ChatModel(c.model),
 	}
+	c.applyPromptCacheKey(&params)
 
 	// Add max tokens if specified
 	if c.maxTokens > 0 {
@@ -532,7 +637,7 @@ func (c *OpenAIClient) ChatWithToolChoice(ctx context.Context, messages []messag
 	}
 
 	// Add reasoning effort for thinking models
-	caps := getModelCapabilities(c.model)
+	caps := c.capabilities()
 	if caps.SupportsThinking {
 		// Enable reasoning for GPT-5 models to see thinking process
 		params.Reasoning = shared.ReasoningParam{
@@ -582,17 +687,11 @@ func (c *OpenAIClient) chatWithToolChoiceStreaming(ctx context.Context, params r
 		// Check the event type to handle different kinds of deltas appropriately
 		switch eventData := event.AsAny().(type) {
 		case responses.ResponseTextDeltaEvent:
-			// This is regular text content - display and accumulate it
+			// This is regular text content - stream and accumulate it
 			if eventData.Delta != "" {
-				fmt.Print(eventData.Delta)
+				message.SendResponseContent(ctx, c.responseChan, eventData.Delta)
 				responseBuilder.WriteString(eventData.Delta)
 			}
-		case responses.ResponseFunctionCallArgumentsDeltaEvent:
-			// This is tool call arguments - display but don't accumulate as response text
-			if eventData.Delta != "" {
-				fmt.Print(eventData.Delta)
-				// Note: We don't add this to responseBuilder since it's tool call args
-			}
 		case responses.ResponseReasoningTextDeltaEvent:
 			// This is reasoning content - accumulate it for thinking
 			if eventData.Delta != "" {
@@ -619,14 +718,13 @@ func (c *OpenAIClient) chatWithToolChoiceStreaming(ctx context.Context, params r
 		default:
 			// For other event types, try to extract text delta
 			if textEvent := event.AsResponseOutputTextDelta(); textEvent.Delta != "" {
-				fmt.Print(textEvent.Delta)
+				message.SendResponseContent(ctx, c.responseChan, textEvent.Delta)
 				responseBuilder.WriteString(textEvent.Delta)
 			}
 		}
 
 		// Check if we have a completed response
 		if completedEvent := event.AsResponseCompleted(); completedEvent.Type != "" {
-			fmt.Println()
 			break
 		}
 	}
@@ -655,13 +753,7 @@ func (c *OpenAIClient) chatWithToolChoiceStreaming(ctx context.Context, params r
 	}
 
 	// Capture token usage if provided
-	if resp.Usage.JSON.InputTokens.Valid() || resp.Usage.JSON.OutputTokens.Valid() || resp.Usage.JSON.TotalTokens.Valid() {
-		c.lastUsage = message.TokenUsage{
-			InputTokens:  int(resp.Usage.InputTokens),
-			OutputTokens: int(resp.Usage.OutputTokens),
-			TotalTokens:  int(resp.Usage.TotalTokens),
-		}
-	}
+	c.captureUsage(resp.Usage)
 
 	// Check for different types of output items using the variant system
 	var reasoningContent string
@@ -817,10 +909,8 @@ func (c *OpenAIClient) chatWithToolChoiceStreaming(ctx context.Context, params r
 
 	// Decide what to return based on what we found
 	// If we found tool calls, return batch when multiple; single otherwise
-	if len(toolCalls) == 1 {
-		return toolCalls[0], nil
-	} else if len(toolCalls) > 1 {
-		return message.NewToolCallBatch(toolCalls), nil
+	if msg := message.NewToolCallResponse(toolCalls); msg != nil {
+		return msg, nil
 	}
 
 	// No tool calls found, return text response
@@ -889,8 +979,7 @@ type ResponseToolCall struct {
 
 // SupportsVision implements VisionLLM interface
 func (c *OpenAIClient) SupportsVision() bool {
-	// GPT-4V models support vision
-	return strings.Contains(c.model, "gpt-4") && (strings.Contains(c.model, "vision") || strings.Contains(c.model, "gpt-4o"))
+	return c.capabilities().SupportsVision
 }
 
 // isStreamingUnsupportedError checks whether the error indicates that streaming is not allowed
@@ -923,13 +1012,7 @@ func (c *OpenAIClient) chatWithToolChoiceNonStreaming(ctx context.Context, param
 	}
 
 	// Capture token usage if provided
-	if resp.Usage.JSON.InputTokens.Valid() || resp.Usage.JSON.OutputTokens.Valid() || resp.Usage.JSON.TotalTokens.Valid() {
-		c.lastUsage = message.TokenUsage{
-			InputTokens:  int(resp.Usage.InputTokens),
-			OutputTokens: int(resp.Usage.OutputTokens),
-			TotalTokens:  int(resp.Usage.TotalTokens),
-		}
-	}
+	c.captureUsage(resp.Usage)
 
 	// Check for different types of output items using the variant system
 	var reasoningContent string
@@ -960,11 +1043,9 @@ func (c *OpenAIClient) chatWithToolChoiceNonStreaming(ctx context.Context, param
 		}
 	}
 
-	// If we found tool calls, return them
-	if len(toolCalls) == 1 {
-		return toolCalls[0], nil
-	} else if len(toolCalls) > 1 {
-		return message.NewToolCallBatch(toolCalls), nil
+	// If we found tool calls, return them (a batch when there are several)
+	if msg := message.NewToolCallResponse(toolCalls); msg != nil {
+		return msg, nil
 	}
 
 	// Otherwise return text answer
@@ -2,6 +2,11 @@ package openai
 
 import (
 	"testing"
+
+	"github.com/openai/openai-go/v2/responses"
+
+	"github.com/fpt/go-gennai-cli/pkg/agent/domain"
+	"github.com/fpt/go-gennai-cli/pkg/message"
 )
 
 func TestGetOpenAIModel(t *testing.T) {
@@ -76,6 +81,33 @@ func TestNewOpenAIClient_NoAPIKey(t *testing.T) {
 	}
 }
 
+func TestNewOpenAIClientWithBaseURL(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "test-key")
+
+	tests := []struct {
+		name    string
+		baseURL string
+	}{
+		{"default endpoint", ""},
+		{"custom gateway", "http://localhost:8000/v1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewOpenAIClientWithBaseURL("gpt-4o", 0, tt.baseURL)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if client.model != "gpt-4o" {
+				t.Errorf("Expected model gpt-4o, got %s", client.model)
+			}
+			if client.maxTokens != getModelCapabilities("gpt-4o").MaxTokens {
+				t.Errorf("Expected default maxTokens, got %d", client.maxTokens)
+			}
+		})
+	}
+}
+
 // Test the new IsToolCapable method
 func TestIsToolCapable(t *testing.T) {
 	// Test with a mock client to avoid requiring API key
@@ -94,3 +126,71 @@ func TestIsToolCapable(t *testing.T) {
 		t.Error("Expected gpt-4o to support tool calling")
 	}
 }
+
+func TestImageDataURL(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected string
+	}{
+		{"iVBORw0KGgoAAA", "data:image/png;base64,iVBORw0KGgoAAA"},
+		{"/9j/4AAQSkZJRg", "data:image/jpeg;base64,/9j/4AAQSkZJRg"},
+		{"R0lGODlhAQAB", "data:image/gif;base64,R0lGODlhAQAB"},
+		{"data:image/webp;base64,UklGR", "data:image/webp;base64,UklGR"},
+	}
+
+	for _, tc := range testCases {
+		if result := imageDataURL(tc.input); result != tc.expected {
+			t.Errorf("imageDataURL(%q) = %q, expected %q", tc.input, result, tc.expected)
+		}
+	}
+}
+
+func TestConvertMessagesWithImages(t *testing.T) {
+	client := &OpenAIClient{OpenAICore: &OpenAICore{model: "gpt-4o"}}
+	if !client.SupportsVision() {
+		t.Fatal("Expected gpt-4o to support vision")
+	}
+
+	messages := []message.Message{
+		message.NewChatMessageWithImages(message.MessageTypeUser, "What is in this screenshot?", []string{"iVBORw0KGgoAAA"}),
+	}
+	items, err := client.convertMessagesToResponsesInputItems(messages)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(items) != 1 || items[0].OfMessage == nil {
+		t.Fatalf("Expected a single message input item, got %+v", items)
+	}
+
+	parts := items[0].OfMessage.Content.OfInputItemContentList
+	if len(parts) != 2 {
+		t.Fatalf("Expected image and text parts, got %d", len(parts))
+	}
+	if parts[0].OfInputImage == nil || parts[0].OfInputImage.ImageURL.Value != "data:image/png;base64,iVBORw0KGgoAAA" {
+		t.Errorf("Expected image part with data URI, got %+v", parts[0])
+	}
+	if parts[1].OfInputText == nil || parts[1].OfInputText.Text != "What is in this screenshot?" {
+		t.Errorf("Expected trailing text part, got %+v", parts[1])
+	}
+}
+
+func TestApplyPromptCacheKey(t *testing.T) {
+	client := &OpenAIClient{OpenAICore: &OpenAICore{model: "gpt-5"}}
+	params := responses.ResponseNewParams{}
+	client.applyPromptCacheKey(&params)
+	if params.PromptCacheKey.Valid() {
+		t.Error("expected no prompt_cache_key when caching is disabled")
+	}
+
+	client.ConfigureModelSideCache(domain.ModelSideCacheOptions{PromptCachingEnabled: true})
+	client.applyPromptCacheKey(&params)
+	if got := params.PromptCacheKey.Value; got != defaultPromptCacheKey {
+		t.Errorf("prompt_cache_key = %q, want %q", got, defaultPromptCacheKey)
+	}
+
+	client.SetSessionID("project-session")
+	client.applyPromptCacheKey(&params)
+	if got := params.PromptCacheKey.Value; got != "project-session" {
+		t.Errorf("prompt_cache_key = %q, want the session ID", got)
+	}
+}
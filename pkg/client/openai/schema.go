@@ -0,0 +1,61 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/fpt/go-gennai-cli/pkg/agent/domain"
+	"github.com/fpt/go-gennai-cli/pkg/message"
+	"github.com/openai/openai-go/v2"
+	"github.com/openai/openai-go/v2/responses"
+	"github.com/openai/openai-go/v2/shared"
+)
+
+// ChatWithSchema implements domain.SchemaLLM using the Responses API
+// json_schema text format. Strict mode is left off so that schemas which do
+// not meet its requirements (e.g. optional properties) are still accepted.
+func (c *OpenAIClient) ChatWithSchema(ctx context.Context, messages []message.Message, schema json.RawMessage) (string, error) {
+	var schemaMap map[string]any
+	if err := json.Unmarshal(schema, &schemaMap); err != nil {
+		return "", fmt.Errorf("invalid JSON schema: %w", err)
+	}
+
+	inputItems, err := c.convertMessagesToResponsesInputItems(messages)
+	if err != nil {
+		return "", err
+	}
+
+	params := responses.ResponseNewParams{
+		Input: responses.ResponseNewParamsInputUnion{
+			OfInputItemList: inputItems,
+		},
+		Model: shared.ChatModel(c.model),
+		Text: responses.ResponseTextConfigParam{
+			Format: responses.ResponseFormatTextConfigParamOfJSONSchema("response", schemaMap),
+		},
+	}
+	if c.maxTokens > 0 {
+		params.MaxOutputTokens = openai.Int(int64(c.maxTokens))
+	}
+
+	resp, err := c.client.Responses.New(ctx, params)
+	if err != nil {
+		return "", fmt.Errorf("Responses API call failed: %w", err)
+	}
+	if resp.Usage.JSON.InputTokens.Valid() || resp.Usage.JSON.OutputTokens.Valid() || resp.Usage.JSON.TotalTokens.Valid() {
+		c.lastUsage = message.TokenUsage{
+			InputTokens:  int(resp.Usage.InputTokens),
+			OutputTokens: int(resp.Usage.OutputTokens),
+			TotalTokens:  int(resp.Usage.TotalTokens),
+		}
+	}
+
+	outputText := resp.OutputText()
+	if outputText == "" {
+		return "", fmt.Errorf("empty structured response from Responses API")
+	}
+	return outputText, nil
+}
+
+var _ domain.SchemaLLM = (*OpenAIClient)(nil)
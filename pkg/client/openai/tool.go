@@ -218,19 +218,13 @@ func convertOpenAIArgsToToolArgs(argsJSON string) message.ToolArgumentValues {
 		return result
 	}
 
-	// Parse JSON arguments
-	var args map[string]interface{}
-	if err := json.Unmarshal([]byte(argsJSON), &args); err != nil {
+	// Parse JSON arguments, including an object sent JSON-encoded in a string
+	args, err := message.ParseToolArguments(argsJSON)
+	if err != nil {
 		// If parsing fails, return empty map
 		return result
 	}
-
-	// Convert interface{} values to proper types
-	for key, value := range args {
-		result[key] = value
-	}
-
-	return result
+	return args
 }
 
 // convertToolArgsToJSON converts tool argument values to JSON string
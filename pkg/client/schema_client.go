@@ -0,0 +1,157 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/fpt/go-gennai-cli/pkg/agent/domain"
+	"github.com/fpt/go-gennai-cli/pkg/client/anthropic"
+	"github.com/fpt/go-gennai-cli/pkg/client/ollama"
+	"github.com/fpt/go-gennai-cli/pkg/message"
+)
+
+// wrappedResponseArg carries the answer when the schema is not an object, since
+// tool arguments are always an object
+const wrappedResponseArg = "response"
+
+// NewSchemaClient creates a client that constrains answers to a JSON schema supplied
+// at runtime, using the backend's native feature where there is one (OpenAI
+// response_format, Gemini responseSchema, Ollama format) and tool forcing otherwise.
+// Returns an error wrapping domain.ErrSchemaUnsupported when the backend has neither.
+func NewSchemaClient(client domain.LLM) (domain.SchemaLLM, error) {
+	switch c := client.(type) {
+	case *ollama.OllamaClient:
+		if ollama.IsJSONSchemaCapableModel(c.Model()) {
+			return c, nil
+		} else if ollama.IsToolCapableModel(c.Model()) {
+			return NewToolCallingSchemaClient(ollama.NewOllamaClientFromCore(c.OllamaCore)), nil
+		}
+		return nil, fmt.Errorf("model %s: %w", c.Model(), domain.ErrSchemaUnsupported)
+	case *anthropic.AnthropicClient:
+		// Use a fresh client so the caller's tool manager is left untouched
+		return NewToolCallingSchemaClient(anthropic.NewAnthropicClientFromCore(c.AnthropicCore)), nil
+	case domain.SchemaLLM:
+		return c, nil
+	default:
+		return nil, fmt.Errorf("%T: %w", client, domain.ErrSchemaUnsupported)
+	}
+}
+
+// ToolCallingSchemaClient implements SchemaLLM by forcing a "respond" tool whose
+// arguments are the schema's properties
+type ToolCallingSchemaClient struct {
+	client domain.ToolCallingLLM
+}
+
+// NewToolCallingSchemaClient creates a schema client using tool calling
+func NewToolCallingSchemaClient(client domain.ToolCallingLLM) *ToolCallingSchemaClient {
+	return &ToolCallingSchemaClient{client: client}
+}
+
+// Chat implements the base LLM interface
+func (c *ToolCallingSchemaClient) Chat(ctx context.Context, messages []message.Message, enableThinking bool, thinkingChan chan<- string) (message.Message, error) {
+	return c.client.Chat(ctx, messages, enableThinking, thinkingChan)
+}
+
+// ChatWithSchema implements SchemaLLM using a forced "respond" tool call
+func (c *ToolCallingSchemaClient) ChatWithSchema(ctx context.Context, messages []message.Message, schema json.RawMessage) (string, error) {
+	var schemaMap map[string]any
+	if err := json.Unmarshal(schema, &schemaMap); err != nil {
+		return "", fmt.Errorf("invalid JSON schema: %w", err)
+	}
+	args, wrapped := schemaToToolArguments(schemaMap)
+
+	c.client.SetToolManager(&respondToolManager{
+		respondTool: &respondTool{
+			name:        "respond",
+			description: "Provide the final answer with the exact structure specified",
+			arguments:   args,
+			handler: func(ctx context.Context, args message.ToolArgumentValues) (message.ToolResult, error) {
+				// Never called; the tool only carries the schema
+				return message.NewToolResultError("respondTool does not execute"), nil
+			},
+		},
+	})
+
+	enhancedMessages := append([]message.Message{
+		message.NewSystemMessage(
+			"You must respond using the 'respond' tool with the exact structure specified. " +
+				"Do not provide any other response format. The tool parameters define the required response structure."),
+	}, messages...)
+	toolChoice := domain.ToolChoice{Type: domain.ToolChoiceTool, Name: "respond"}
+
+	response, err := c.client.ChatWithToolChoice(ctx, enhancedMessages, toolChoice, false, nil)
+	if err != nil {
+		return "", fmt.Errorf("tool calling failed: %w", err)
+	}
+	toolCallMsg, ok := response.(*message.ToolCallMessage)
+	if !ok {
+		return "", fmt.Errorf("expected tool call response, got %T", response)
+	}
+
+	var value any = map[string]any(toolCallMsg.ToolArguments())
+	if wrapped {
+		value = toolCallMsg.ToolArguments()[wrappedResponseArg]
+	}
+	jsonBytes, err := json.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal tool arguments: %w", err)
+	}
+	return string(jsonBytes), nil
+}
+
+// ModelID returns the underlying model identifier of the wrapped client
+func (c *ToolCallingSchemaClient) ModelID() string { return c.client.ModelID() }
+
+// schemaToToolArguments converts an object schema's properties to tool arguments,
+// keeping each property's full sub-schema. Other schemas become a single
+// "response" argument; wrapped reports whether that happened.
+func schemaToToolArguments(schema map[string]any) (args []message.ToolArgument, wrapped bool) {
+	properties, _ := schema["properties"].(map[string]any)
+	if schema["type"] != "object" || len(properties) == 0 {
+		return []message.ToolArgument{schemaToToolArgument(wrappedResponseArg, schema, true)}, true
+	}
+
+	required := make(map[string]bool)
+	if list, ok := schema["required"].([]any); ok {
+		for _, name := range list {
+			if s, ok := name.(string); ok {
+				required[s] = true
+			}
+		}
+	}
+	for _, name := range sortedKeys(properties) {
+		propSchema, _ := properties[name].(map[string]any)
+		args = append(args, schemaToToolArgument(name, propSchema, required[name]))
+	}
+	return args, false
+}
+
+// schemaToToolArgument builds one tool argument; keywords other than type and
+// description travel in Properties, which providers merge into the property schema
+func schemaToToolArgument(name string, schema map[string]any, required bool) message.ToolArgument {
+	arg := message.ToolArgument{
+		Name:     message.ToolName(name),
+		Type:     "string",
+		Required: required,
+	}
+	if t, ok := schema["type"].(string); ok {
+		arg.Type = t
+	}
+	if d, ok := schema["description"].(string); ok {
+		arg.Description = message.ToolDescription(d)
+	}
+	for k, v := range schema {
+		if k == "type" || k == "description" {
+			continue
+		}
+		if arg.Properties == nil {
+			arg.Properties = make(map[string]any)
+		}
+		arg.Properties[k] = v
+	}
+	return arg
+}
+
+var _ domain.SchemaLLM = (*ToolCallingSchemaClient)(nil)
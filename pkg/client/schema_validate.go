@@ -0,0 +1,167 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/fpt/go-gennai-cli/pkg/message"
+)
+
+// ValidateJSON checks data against a JSON schema and returns one message per
+// violation; an empty result means the data conforms. It covers the keywords
+// answers are usually constrained with: type, properties, required,
+// additionalProperties, items, enum, minItems/maxItems, minLength/maxLength and
+// minimum/maximum. Other keywords are not checked. An error is returned only
+// when the schema itself cannot be parsed.
+func ValidateJSON(data []byte, schema json.RawMessage) ([]string, error) {
+	var schemaValue any
+	if err := json.Unmarshal(schema, &schemaValue); err != nil {
+		return nil, fmt.Errorf("invalid JSON schema: %w", err)
+	}
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return []string{fmt.Sprintf("response is not valid JSON: %v", err)}, nil
+	}
+	var problems []string
+	validateValue(value, schemaValue, "$", &problems)
+	return problems, nil
+}
+
+func validateValue(value any, schemaValue any, path string, problems *[]string) {
+	schema, ok := schemaValue.(map[string]any)
+	if !ok {
+		// true, or a schema we do not understand, accepts anything
+		return
+	}
+
+	if types := schemaTypes(schema["type"]); len(types) > 0 {
+		matched := false
+		for _, t := range types {
+			if message.MatchesJSONType(value, t) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			*problems = append(*problems, fmt.Sprintf("%s: expected %s, got %s", path, strings.Join(types, " or "), message.JSONTypeName(value)))
+			return
+		}
+	}
+
+	if enum, ok := schema["enum"].([]any); ok {
+		found := false
+		for _, allowed := range enum {
+			if reflect.DeepEqual(value, allowed) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			*problems = append(*problems, fmt.Sprintf("%s: value %s is not one of the allowed values", path, compactJSON(value)))
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		validateObject(v, schema, path, problems)
+	case []any:
+		if n, ok := schemaNumber(schema, "minItems"); ok && float64(len(v)) < n {
+			*problems = append(*problems, fmt.Sprintf("%s: expected at least %v items, got %d", path, n, len(v)))
+		}
+		if n, ok := schemaNumber(schema, "maxItems"); ok && float64(len(v)) > n {
+			*problems = append(*problems, fmt.Sprintf("%s: expected at most %v items, got %d", path, n, len(v)))
+		}
+		if items, ok := schema["items"]; ok {
+			for i, item := range v {
+				validateValue(item, items, fmt.Sprintf("%s[%d]", path, i), problems)
+			}
+		}
+	case string:
+		length := len([]rune(v))
+		if n, ok := schemaNumber(schema, "minLength"); ok && float64(length) < n {
+			*problems = append(*problems, fmt.Sprintf("%s: expected at least %v characters, got %d", path, n, length))
+		}
+		if n, ok := schemaNumber(schema, "maxLength"); ok && float64(length) > n {
+			*problems = append(*problems, fmt.Sprintf("%s: expected at most %v characters, got %d", path, n, length))
+		}
+	case float64:
+		if n, ok := schemaNumber(schema, "minimum"); ok && v < n {
+			*problems = append(*problems, fmt.Sprintf("%s: %v is less than the minimum %v", path, v, n))
+		}
+		if n, ok := schemaNumber(schema, "maximum"); ok && v > n {
+			*problems = append(*problems, fmt.Sprintf("%s: %v is greater than the maximum %v", path, v, n))
+		}
+	}
+}
+
+func validateObject(object map[string]any, schema map[string]any, path string, problems *[]string) {
+	properties, _ := schema["properties"].(map[string]any)
+
+	if required, ok := schema["required"].([]any); ok {
+		for _, name := range required {
+			if s, ok := name.(string); ok {
+				if _, present := object[s]; !present {
+					*problems = append(*problems, fmt.Sprintf("%s: missing required property %q", path, s))
+				}
+			}
+		}
+	}
+
+	for _, name := range sortedKeys(object) {
+		childPath := path + "." + name
+		if propSchema, ok := properties[name]; ok {
+			validateValue(object[name], propSchema, childPath, problems)
+			continue
+		}
+		switch additional := schema["additionalProperties"].(type) {
+		case bool:
+			if !additional {
+				*problems = append(*problems, fmt.Sprintf("%s: property %q is not allowed", path, name))
+			}
+		case map[string]any:
+			validateValue(object[name], additional, childPath, problems)
+		}
+	}
+}
+
+// schemaTypes returns the type keyword as a list ("type" may be a string or an array)
+func schemaTypes(t any) []string {
+	switch v := t.(type) {
+	case string:
+		return []string{v}
+	case []any:
+		var types []string
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				types = append(types, s)
+			}
+		}
+		return types
+	}
+	return nil
+}
+
+func schemaNumber(schema map[string]any, key string) (float64, bool) {
+	n, ok := schema[key].(float64)
+	return n, ok
+}
+
+func compactJSON(value any) string {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(b)
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
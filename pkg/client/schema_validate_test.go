@@ -0,0 +1,89 @@
+package client
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestValidateJSON(t *testing.T) {
+	schema := json.RawMessage(`{
+		"type": "object",
+		"properties": {
+			"summary": {"type": "string", "minLength": 1},
+			"severity": {"type": "string", "enum": ["low", "medium", "high"]},
+			"files": {"type": "array", "items": {"type": "string"}, "maxItems": 2},
+			"count": {"type": "integer", "minimum": 0}
+		},
+		"required": ["summary", "severity"],
+		"additionalProperties": false
+	}`)
+
+	tests := []struct {
+		name string
+		data string
+		want []string // substrings, one per expected problem
+	}{
+		{"valid", `{"summary": "ok", "severity": "low", "files": ["a.go"], "count": 2}`, nil},
+		{"not json", `Here is the answer: {}`, []string{"not valid JSON"}},
+		{"wrong top-level type", `["ok"]`, []string{"$: expected object, got array"}},
+		{"missing required", `{"summary": "ok"}`, []string{`missing required property "severity"`}},
+		{"enum", `{"summary": "ok", "severity": "urgent"}`, []string{`$.severity: value "urgent"`}},
+		{"extra property", `{"summary": "ok", "severity": "low", "note": 1}`, []string{`property "note" is not allowed`}},
+		{"nested items", `{"summary": "ok", "severity": "low", "files": ["a", 2, "c"]}`, []string{"$.files: expected at most 2 items", "$.files[1]: expected string, got number"}},
+		{"integer and minimum", `{"summary": "", "severity": "low", "count": -1.5}`, []string{"$.count: expected integer", "$.summary: expected at least 1 characters"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problems, err := ValidateJSON([]byte(tt.data), schema)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(problems) != len(tt.want) {
+				t.Fatalf("got %d problem(s) %q, want %d", len(problems), problems, len(tt.want))
+			}
+			joined := strings.Join(problems, "\n")
+			for _, want := range tt.want {
+				if !strings.Contains(joined, want) {
+					t.Errorf("problems %q missing %q", problems, want)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateJSON_InvalidSchema(t *testing.T) {
+	if _, err := ValidateJSON([]byte(`{}`), json.RawMessage(`{not json`)); err == nil {
+		t.Error("expected an error for an unparsable schema")
+	}
+}
+
+func TestSchemaToToolArguments(t *testing.T) {
+	var schema map[string]any
+	_ = json.Unmarshal([]byte(`{
+		"type": "object",
+		"properties": {
+			"title": {"type": "string", "description": "Short title"},
+			"tags": {"type": "array", "items": {"type": "string"}}
+		},
+		"required": ["title"]
+	}`), &schema)
+
+	args, wrapped := schemaToToolArguments(schema)
+	if wrapped || len(args) != 2 {
+		t.Fatalf("expected two unwrapped arguments, got %+v (wrapped=%v)", args, wrapped)
+	}
+	tags, title := args[0], args[1]
+	if title.Name != "title" || !title.Required || title.Description != "Short title" {
+		t.Errorf("unexpected title argument: %+v", title)
+	}
+	if tags.Type != "array" || tags.Required || tags.Properties["items"] == nil {
+		t.Errorf("tags argument should keep its item schema: %+v", tags)
+	}
+
+	_ = json.Unmarshal([]byte(`{"type": "array", "items": {"type": "string"}}`), &schema)
+	args, wrapped = schemaToToolArguments(schema)
+	if !wrapped || len(args) != 1 || args[0].Name != wrappedResponseArg || args[0].Type != "array" {
+		t.Errorf("non-object schema should be wrapped in a single response argument: %+v", args)
+	}
+}
@@ -0,0 +1,96 @@
+// Package httpclient builds the HTTP clients used for outbound requests (LLM
+// APIs, WebFetch, web search) so that proxy settings and custom CA bundles are
+// honored consistently.
+package httpclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+)
+
+// Options configures the shared transport
+type Options struct {
+	// CABundle is a PEM file of additional trusted certificates, added to the
+	// system pool (e.g. a corporate TLS-inspecting proxy's root CA)
+	CABundle string
+}
+
+var (
+	mu        sync.RWMutex
+	transport = newTransport(nil)
+)
+
+// Configure replaces the shared transport. Call it once at startup, before
+// any clients are created.
+func Configure(opts Options) error {
+	var pool *x509.CertPool
+	if opts.CABundle != "" {
+		var err error
+		if pool, err = loadCABundle(opts.CABundle); err != nil {
+			return err
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	transport = newTransport(pool)
+	return nil
+}
+
+// Transport returns the shared transport, which uses HTTP_PROXY, HTTPS_PROXY
+// and NO_PROXY and trusts the configured CA bundle
+func Transport() http.RoundTripper {
+	mu.RLock()
+	defer mu.RUnlock()
+	return transport
+}
+
+// New returns a client on the shared transport. A zero timeout means none,
+// which suits streaming LLM responses.
+func New(timeout time.Duration) *http.Client {
+	return &http.Client{Transport: Transport(), Timeout: timeout}
+}
+
+// ProxyFor returns the proxy that requests to rawURL go through, with any
+// credentials removed, or "" when the request is made directly
+func ProxyFor(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	proxy, err := http.ProxyFromEnvironment(&http.Request{URL: u})
+	if err != nil || proxy == nil {
+		return ""
+	}
+	return proxy.Redacted()
+}
+
+func newTransport(roots *x509.CertPool) *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.Proxy = http.ProxyFromEnvironment
+	if roots != nil {
+		t.TLSClientConfig = &tls.Config{RootCAs: roots, MinVersion: tls.VersionTLS12}
+	}
+	return t
+}
+
+func loadCABundle(path string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("CA bundle %s contains no PEM certificates", path)
+	}
+	return pool, nil
+}
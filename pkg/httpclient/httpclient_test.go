@@ -0,0 +1,67 @@
+package httpclient
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConfigureCABundle(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+	t.Cleanup(func() { _ = Configure(Options{}) })
+
+	// The test server's self-signed certificate is not trusted by default
+	if _, err := New(5 * time.Second).Get(server.URL); err == nil {
+		t.Fatal("expected TLS verification failure without CA bundle")
+	}
+
+	bundle := filepath.Join(t.TempDir(), "ca.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(bundle, certPEM, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := Configure(Options{CABundle: bundle}); err != nil {
+		t.Fatalf("Configure failed: %v", err)
+	}
+
+	resp, err := New(5 * time.Second).Get(server.URL)
+	if err != nil {
+		t.Fatalf("request with CA bundle failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("status = %d, want 200", resp.StatusCode)
+	}
+}
+
+func TestConfigureInvalidCABundle(t *testing.T) {
+	t.Cleanup(func() { _ = Configure(Options{}) })
+
+	if err := Configure(Options{CABundle: filepath.Join(t.TempDir(), "missing.pem")}); err == nil {
+		t.Error("expected error for missing CA bundle")
+	}
+
+	bundle := filepath.Join(t.TempDir(), "bad.pem")
+	if err := os.WriteFile(bundle, []byte("not a certificate"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	err := Configure(Options{CABundle: bundle})
+	if err == nil || !strings.Contains(err.Error(), "no PEM certificates") {
+		t.Errorf("expected no PEM certificates error, got %v", err)
+	}
+}
+
+func TestTransportUsesEnvironmentProxy(t *testing.T) {
+	tr, ok := Transport().(*http.Transport)
+	if !ok || tr.Proxy == nil {
+		t.Fatal("expected shared transport to resolve proxies from the environment")
+	}
+}
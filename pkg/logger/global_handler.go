@@ -0,0 +1,60 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"sync/atomic"
+)
+
+// globalHandler is the handler of Default. Component loggers are created in
+// package variables, before settings are read, so they resolve it on every
+// record and follow SetGlobalLogLevel, SetGlobalLoggerWithConsoleWriter and
+// ConfigureFile.
+var globalHandler atomic.Pointer[slog.Handler]
+
+// deferredHandler applies its attributes and groups to the current global
+// handler, rebuilding them only when the global handler changes
+type deferredHandler struct {
+	ops      []func(slog.Handler) slog.Handler
+	resolved atomic.Pointer[resolvedHandler]
+}
+
+// resolvedHandler is a deferredHandler's ops applied to one global handler
+type resolvedHandler struct {
+	base    *slog.Handler
+	handler slog.Handler
+}
+
+func (h *deferredHandler) current() slog.Handler {
+	base := globalHandler.Load()
+	if r := h.resolved.Load(); r != nil && r.base == base {
+		return r.handler
+	}
+	handler := *base
+	for _, op := range h.ops {
+		handler = op(handler)
+	}
+	h.resolved.Store(&resolvedHandler{base: base, handler: handler})
+	return handler
+}
+
+func (h *deferredHandler) Enabled(ctx context.Context, lvl slog.Level) bool {
+	return h.current().Enabled(ctx, lvl)
+}
+
+func (h *deferredHandler) Handle(ctx context.Context, r slog.Record) error {
+	return h.current().Handle(ctx, r)
+}
+
+func (h *deferredHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return h.with(func(next slog.Handler) slog.Handler { return next.WithAttrs(attrs) })
+}
+
+func (h *deferredHandler) WithGroup(name string) slog.Handler {
+	return h.with(func(next slog.Handler) slog.Handler { return next.WithGroup(name) })
+}
+
+func (h *deferredHandler) with(op func(slog.Handler) slog.Handler) slog.Handler {
+	return &deferredHandler{ops: append(slices.Clip(h.ops), op)}
+}
@@ -2,10 +2,12 @@ package logger
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"log/slog"
 	"os"
 	"path/filepath"
+	"sync"
 )
 
 // LogLevel represents the available log levels
@@ -30,18 +32,8 @@ func NewLogger(level LogLevel) *Logger {
 
 // NewLoggerWithConsoleWriter builds a logger that writes console output to the given writer
 func NewLoggerWithConsoleWriter(level LogLevel, consoleWriter io.Writer) *Logger {
-	var slogLevel slog.Level
-
-	switch level {
-	case LogLevelDebug:
-		slogLevel = slog.LevelDebug
-	case LogLevelInfo:
-		slogLevel = slog.LevelInfo
-	case LogLevelWarn:
-		slogLevel = slog.LevelWarn
-	case LogLevelError:
-		slogLevel = slog.LevelError
-	default:
+	slogLevel, ok := level.slogLevel()
+	if !ok {
 		slogLevel = slog.LevelInfo // Default to info
 	}
 
@@ -54,13 +46,29 @@ func NewLoggerWithConsoleWriter(level LogLevel, consoleWriter io.Writer) *Logger
 	// File: structured text with time and level
 	fileHandler := newFileTextHandler(slogLevel)
 
-	// Fan out to both console and file
-	handler := newMultiHandler(consoleHandler, fileHandler)
+	// Fan out to both console and file, after removing anything the redactor filters
+	handler := newRedactHandler(newMultiHandler(consoleHandler, fileHandler))
 	logger := slog.New(handler)
 
 	return &Logger{Logger: logger}
 }
 
+// slogLevel maps the level to slog, reporting whether it is a known level
+func (level LogLevel) slogLevel() (slog.Level, bool) {
+	switch level {
+	case LogLevelDebug:
+		return slog.LevelDebug, true
+	case LogLevelInfo:
+		return slog.LevelInfo, true
+	case LogLevelWarn:
+		return slog.LevelWarn, true
+	case LogLevelError:
+		return slog.LevelError, true
+	default:
+		return slog.LevelInfo, false
+	}
+}
+
 // NewDefaultLogger creates a logger with INFO level for general use
 func NewDefaultLogger() *Logger {
 	return NewLogger(LogLevelInfo)
@@ -118,36 +126,135 @@ func (l *Logger) ErrorWithIcon(_ string, msg string, args ...any) { l.Error(msg,
 func (l *Logger) DebugWithIcon(_ string, msg string, args ...any) { l.Debug(msg, args...) }
 
 // Default logger instance - single instance for the entire application
-var Default = NewDefaultLogger()
+var Default *Logger
 
-// SetGlobalLogLevel updates the global default logger with a new log level
-// This affects all component loggers created after this call
+func init() {
+	setDefault(LogLevelInfo, os.Stderr)
+}
+
+// Console settings of Default, kept so ConfigureFile can rebuild it
+var (
+	defaultMu      sync.Mutex
+	defaultLevel   LogLevel
+	defaultConsole io.Writer
+)
+
+// setDefault replaces Default and the handler every component logger resolves
+func setDefault(level LogLevel, consoleWriter io.Writer) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultLevel, defaultConsole = level, consoleWriter
+	Default = NewLoggerWithConsoleWriter(level, consoleWriter)
+	handler := Default.Handler()
+	globalHandler.Store(&handler)
+}
+
+// SetGlobalLogLevel updates the global default logger with a new log level.
+// Component loggers follow it, including those created before this call.
 func SetGlobalLogLevel(level LogLevel) {
-	Default = NewLogger(level)
+	setDefault(level, os.Stderr)
 }
 
-// NewComponentLogger creates a new logger for a specific component
+// NewComponentLogger creates a new logger for a specific component. It writes
+// through the current Default, so later configuration reaches it.
 func NewComponentLogger(component string) *Logger {
-	return Default.WithComponent(component)
+	return &Logger{Logger: slog.New(&deferredHandler{}).With("component", component)}
 }
 
 // SetGlobalLoggerWithConsoleWriter replaces the global Default logger using the provided console writer
 func SetGlobalLoggerWithConsoleWriter(level LogLevel, consoleWriter io.Writer) {
-	Default = NewLoggerWithConsoleWriter(level, consoleWriter)
+	setDefault(level, consoleWriter)
+}
+
+// FileOptions configures the log file written alongside the console
+type FileOptions struct {
+	Path       string   // log file path; empty restores the default ~/.gennai/logs/gennai.log
+	Level      LogLevel // file level, independent of the console level (empty = console level)
+	MaxSizeMB  int      // rotate once the file reaches this size (0 = default 10)
+	MaxBackups *int     // rotated files to keep as path.1 ... path.N (nil = default 3, 0 = none)
 }
 
-// newFileTextHandler opens ~/.gennai/logs/gennai.log for append and returns a slog text handler
+const (
+	defaultLogFileMaxSizeMB  = 10
+	defaultLogFileMaxBackups = 3
+)
+
+// Log file shared by every logger
+var (
+	fileMu     sync.Mutex
+	fileOutput io.WriteCloser
+	fileLevel  *slog.Level
+)
+
+// ConfigureFile directs the file output of Default and the component loggers
+// to a rotating file at its own level, e.g. debug in the file while the
+// console stays at info. Loggers made with NewLogger afterwards use it too.
+func ConfigureFile(opts FileOptions) error {
+	var level *slog.Level
+	if opts.Level != "" {
+		l, ok := opts.Level.slogLevel()
+		if !ok {
+			return fmt.Errorf("unknown log level %q (must be debug, info, warn or error)", opts.Level)
+		}
+		level = &l
+	}
+
+	var output io.WriteCloser
+	if opts.Path != "" {
+		maxSizeMB, maxBackups := opts.MaxSizeMB, defaultLogFileMaxBackups
+		if maxSizeMB == 0 {
+			maxSizeMB = defaultLogFileMaxSizeMB
+		}
+		if opts.MaxBackups != nil {
+			maxBackups = *opts.MaxBackups
+		}
+		rf, err := NewRotatingFile(opts.Path, int64(maxSizeMB)*1024*1024, maxBackups)
+		if err != nil {
+			return err
+		}
+		output = rf
+	}
+
+	fileMu.Lock()
+	previous := fileOutput
+	fileOutput, fileLevel = output, level
+	fileMu.Unlock()
+
+	defaultMu.Lock()
+	consoleLevel, console := defaultLevel, defaultConsole
+	defaultMu.Unlock()
+	setDefault(consoleLevel, console)
+
+	if previous != nil {
+		_ = previous.Close()
+	}
+	return nil
+}
+
+// newFileTextHandler returns a slog text handler for the configured log file,
+// or ~/.gennai/logs/gennai.log at the console level when none is configured
 func newFileTextHandler(level slog.Level) slog.Handler {
-	// Determine log file path
-	home, _ := os.UserHomeDir()
-	base := filepath.Join(home, ".gennai", "logs")
-	_ = os.MkdirAll(base, 0o755)
-	path := filepath.Join(base, "gennai.log")
-
-	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
-	if err != nil {
-		// Fallback to stderr if file cannot be opened
-		return slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level})
+	fileMu.Lock()
+	output := fileOutput
+	if fileLevel != nil {
+		level = *fileLevel
+	}
+	fileMu.Unlock()
+
+	var f io.Writer = output
+	if output == nil {
+		// Determine log file path
+		home, _ := os.UserHomeDir()
+		base := filepath.Join(home, ".gennai", "logs")
+		_ = os.MkdirAll(base, 0o755)
+		path := filepath.Join(base, "gennai.log")
+
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			// Fallback to stderr if file cannot be opened
+			return slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level})
+		}
+		f = file
 	}
 
 	opts := &slog.HandlerOptions{
@@ -25,7 +25,10 @@ func (m *multiHandler) Enabled(ctx context.Context, lvl slog.Level) bool {
 
 func (m *multiHandler) Handle(ctx context.Context, r slog.Record) error {
 	for _, h := range m.handlers {
-		_ = h.Handle(ctx, r)
+		// Handlers may run at different levels (e.g. a debug log file behind an info console)
+		if h.Enabled(ctx, r.Level) {
+			_ = h.Handle(ctx, r)
+		}
 	}
 	return nil
 }
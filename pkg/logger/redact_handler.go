@@ -0,0 +1,85 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+)
+
+// redactor rewrites log text before it is written (nil = unchanged)
+var redactor atomic.Pointer[func(string) string]
+
+// SetRedactor installs a filter applied to the message and string attributes of
+// every log record, e.g. to remove secrets. It affects existing loggers too.
+// Pass nil to remove it.
+func SetRedactor(fn func(string) string) {
+	if fn == nil {
+		redactor.Store(nil)
+		return
+	}
+	redactor.Store(&fn)
+}
+
+// redactHandler applies the installed redactor before passing records on
+type redactHandler struct {
+	next slog.Handler
+}
+
+func newRedactHandler(next slog.Handler) slog.Handler {
+	return &redactHandler{next: next}
+}
+
+func (h *redactHandler) Enabled(ctx context.Context, lvl slog.Level) bool {
+	return h.next.Enabled(ctx, lvl)
+}
+
+func (h *redactHandler) Handle(ctx context.Context, r slog.Record) error {
+	fn := redactor.Load()
+	if fn == nil {
+		return h.next.Handle(ctx, r)
+	}
+	redacted := slog.NewRecord(r.Time, r.Level, (*fn)(r.Message), r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		redacted.AddAttrs(redactAttr(a, *fn))
+		return true
+	})
+	return h.next.Handle(ctx, redacted)
+}
+
+// redactAttr filters string values, including those inside groups
+func redactAttr(a slog.Attr, fn func(string) string) slog.Attr {
+	v := a.Value.Resolve()
+	switch v.Kind() {
+	case slog.KindString:
+		return slog.String(a.Key, fn(v.String()))
+	case slog.KindGroup:
+		attrs := v.Group()
+		out := make([]any, len(attrs))
+		for i, ga := range attrs {
+			out[i] = redactAttr(ga, fn)
+		}
+		return slog.Group(a.Key, out...)
+	case slog.KindAny:
+		if err, ok := v.Any().(error); ok {
+			return slog.String(a.Key, fn(err.Error()))
+		}
+	}
+	return a
+}
+
+// WithAttrs filters attributes bound to derived loggers when they are added;
+// a redactor installed later does not apply to them
+func (h *redactHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if fn := redactor.Load(); fn != nil {
+		filtered := make([]slog.Attr, len(attrs))
+		for i, a := range attrs {
+			filtered[i] = redactAttr(a, *fn)
+		}
+		attrs = filtered
+	}
+	return &redactHandler{next: h.next.WithAttrs(attrs)}
+}
+
+func (h *redactHandler) WithGroup(name string) slog.Handler {
+	return &redactHandler{next: h.next.WithGroup(name)}
+}
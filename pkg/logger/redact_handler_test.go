@@ -0,0 +1,38 @@
+package logger
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSetRedactor(t *testing.T) {
+	if err := ConfigureFile(FileOptions{Path: filepath.Join(t.TempDir(), "test.log")}); err != nil {
+		t.Fatal(err)
+	}
+	defer ConfigureFile(FileOptions{})
+
+	var console strings.Builder
+	l := NewLoggerWithConsoleWriter(LogLevelInfo, &console)
+	SetRedactor(func(s string) string { return strings.ReplaceAll(s, "hunter2", "***") })
+	defer SetRedactor(nil)
+
+	l.Info("password hunter2 seen", "value", "x-hunter2", "err", errors.New("bad hunter2"), "count", 3)
+	out := console.String()
+	if strings.Contains(out, "hunter2") {
+		t.Errorf("console output not redacted: %q", out)
+	}
+	for _, want := range []string{"password *** seen", "x-***", "bad ***", "3"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("console output missing %q: %q", want, out)
+		}
+	}
+
+	SetRedactor(nil)
+	console.Reset()
+	l.Info("hunter2")
+	if !strings.Contains(console.String(), "hunter2") {
+		t.Errorf("removing the redactor should restore output, got %q", console.String())
+	}
+}
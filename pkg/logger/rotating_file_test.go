@@ -0,0 +1,167 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRotatingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "gennai.log")
+	rf, err := NewRotatingFile(path, 10, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rf.Close()
+
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		if _, err := rf.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	read := func(p string) string {
+		t.Helper()
+		data, err := os.ReadFile(p)
+		if err != nil {
+			t.Fatalf("reading %s: %v", p, err)
+		}
+		return string(data)
+	}
+	// Each write would exceed 10 bytes with the previous one, so every line
+	// starts a new file and only the two newest backups survive
+	if got := read(path); got != "fourth\n" {
+		t.Errorf("current log = %q, want %q", got, "fourth\n")
+	}
+	if got := read(path + ".1"); got != "third\n" {
+		t.Errorf("backup 1 = %q, want %q", got, "third\n")
+	}
+	if got := read(path + ".2"); got != "second\n" {
+		t.Errorf("backup 2 = %q, want %q", got, "second\n")
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("expected no third backup, stat error = %v", err)
+	}
+}
+
+func TestRotatingFileKeepsLoggingWhenRotationFails(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gennai.log")
+	// A non-empty directory where the backup should go makes the rename fail
+	if err := os.MkdirAll(filepath.Join(path+".1", "blocker"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	rf, err := NewRotatingFile(path, 10, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rf.Close()
+
+	for _, line := range []string{"first\n", "second\n", "third\n"} {
+		if _, err := rf.Write([]byte(line)); err != nil {
+			t.Fatalf("write %q: %v", line, err)
+		}
+	}
+	data, _ := os.ReadFile(path)
+	if string(data) != "first\nsecond\nthird\n" {
+		t.Errorf("log = %q, want every line kept in the unrotated file", data)
+	}
+
+	// Rotation resumes once the obstacle is gone
+	if err := os.RemoveAll(path + ".1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rf.Write([]byte("fourth\n")); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "fourth\n" {
+		t.Errorf("log after rotation = %q, want %q", data, "fourth\n")
+	}
+}
+
+func TestRotatingFileAppendsToExisting(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gennai.log")
+	if err := os.WriteFile(path, []byte("old\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	rf, err := NewRotatingFile(path, 1024, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rf.Write([]byte("new\n")); err != nil {
+		t.Fatal(err)
+	}
+	rf.Close()
+
+	data, _ := os.ReadFile(path)
+	if string(data) != "old\nnew\n" {
+		t.Errorf("log = %q, want appended content", data)
+	}
+	if _, err := rf.Write([]byte("x")); err == nil {
+		t.Error("write after Close should fail")
+	}
+}
+
+func TestConfigureFileSeparateLevel(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "debug.log")
+	if err := ConfigureFile(FileOptions{Path: path, Level: LogLevelDebug}); err != nil {
+		t.Fatal(err)
+	}
+	defer ConfigureFile(FileOptions{})
+
+	var console strings.Builder
+	l := NewLoggerWithConsoleWriter(LogLevelInfo, &console)
+	l.Debug("debug detail", "key", "value")
+	l.Info("user-facing")
+
+	if strings.Contains(console.String(), "debug detail") {
+		t.Errorf("console should stay at info level, got %q", console.String())
+	}
+	if !strings.Contains(console.String(), "user-facing") {
+		t.Errorf("console missing info message, got %q", console.String())
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"level=DEBUG", "debug detail", "key=value", "user-facing"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("log file missing %q:\n%s", want, data)
+		}
+	}
+
+	if err := ConfigureFile(FileOptions{Path: path, Level: "verbose"}); err == nil {
+		t.Error("unknown level should be rejected")
+	}
+}
+
+func TestConfigureFileReachesComponentLoggers(t *testing.T) {
+	// Component loggers live in package variables created before settings are read
+	component := NewComponentLogger("early")
+
+	path := filepath.Join(t.TempDir(), "debug.log")
+	none := 0
+	if err := ConfigureFile(FileOptions{Path: path, Level: LogLevelDebug, MaxSizeMB: 1, MaxBackups: &none}); err != nil {
+		t.Fatal(err)
+	}
+	defer ConfigureFile(FileOptions{})
+
+	component.Debug("configured later", "key", "value")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"configured later", "component=early", "key=value"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("log file missing %q:\n%s", want, data)
+		}
+	}
+
+	// max_backups 0 keeps no rotated copies
+	component.Debug(strings.Repeat("x", 1024*1024))
+	component.Debug("after rotation")
+	if _, err := os.Stat(path + ".1"); !os.IsNotExist(err) {
+		t.Errorf("expected no backup with MaxBackups 0, stat error = %v", err)
+	}
+}
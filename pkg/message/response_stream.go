@@ -0,0 +1,16 @@
+package message
+
+import "context"
+
+// SendResponseContent sends a chunk of response text to the channel. Unlike
+// thinking content, response chunks are never dropped: the receiver must drain
+// the channel until it is closed. It gives up when ctx is cancelled.
+func SendResponseContent(ctx context.Context, channel chan<- string, content string) {
+	if channel == nil || content == "" {
+		return
+	}
+	select {
+	case channel <- content:
+	case <-ctx.Done():
+	}
+}
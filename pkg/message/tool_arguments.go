@@ -0,0 +1,98 @@
+package message
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+// ParseToolArguments decodes tool call arguments sent as a JSON object. Some
+// models, local ones in particular, send the object JSON-encoded inside a
+// string instead; such a string is decoded a second time.
+func ParseToolArguments(data string) (ToolArgumentValues, error) {
+	var decoded any
+	if err := json.Unmarshal([]byte(data), &decoded); err != nil {
+		return nil, err
+	}
+	if encoded, ok := decoded.(string); ok {
+		if err := json.Unmarshal([]byte(encoded), &decoded); err != nil {
+			return nil, fmt.Errorf("tool arguments are a string that is not JSON: %w", err)
+		}
+	}
+	args, ok := decoded.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("tool arguments must be a JSON object, got %T", decoded)
+	}
+	return ToolArgumentValues(args), nil
+}
+
+// MatchesJSONType reports whether a decoded JSON value has a JSON schema type.
+// Go numeric types count as numbers so values built in code match too; unknown
+// type names are not enforced.
+func MatchesJSONType(value any, schemaType string) bool {
+	switch schemaType {
+	case "object":
+		_, ok := value.(map[string]any)
+		return ok
+	case "array":
+		switch value.(type) {
+		case []any, []string, []map[string]any:
+			return true
+		}
+		return false
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "number":
+		_, ok := jsonNumber(value)
+		return ok
+	case "integer":
+		n, ok := jsonNumber(value)
+		return ok && n == math.Trunc(n)
+	case "null":
+		return value == nil
+	}
+	return true
+}
+
+// JSONTypeName names the JSON type of a decoded value
+func JSONTypeName(value any) string {
+	if _, ok := jsonNumber(value); ok {
+		return "number"
+	}
+	switch value.(type) {
+	case map[string]any:
+		return "object"
+	case []any, []string, []map[string]any:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case nil:
+		return "null"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func jsonNumber(value any) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case json.Number:
+		n, err := v.Float64()
+		return n, err == nil
+	}
+	return 0, false
+}
@@ -0,0 +1,61 @@
+package message
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestParseToolArguments(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    ToolArgumentValues
+		wantErr bool
+	}{
+		{"object", `{"file_path":"/a.go","limit":10}`, ToolArgumentValues{"file_path": "/a.go", "limit": float64(10)}, false},
+		{"object in a string", `"{\"file_path\":\"/a.go\",\"limit\":10}"`, ToolArgumentValues{"file_path": "/a.go", "limit": float64(10)}, false},
+		{"empty object", `{}`, ToolArgumentValues{}, false},
+		{"string that is not JSON", `"read /a.go"`, nil, true},
+		{"array", `[1,2]`, nil, true},
+		{"malformed", `{"file_path":`, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseToolArguments(tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseToolArguments(%s) error = %v, wantErr %v", tt.data, err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseToolArguments(%s) = %v, want %v", tt.data, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchesJSONType(t *testing.T) {
+	tests := []struct {
+		value      any
+		schemaType string
+		want       bool
+	}{
+		{"x", "string", true},
+		{float64(2), "integer", true},
+		{2.5, "integer", false},
+		{2.5, "number", true},
+		{3, "number", true},
+		{json.Number("7"), "integer", true},
+		{[]any{1}, "array", true},
+		{[]string{"a"}, "array", true},
+		{map[string]any{}, "object", true},
+		{"[1]", "array", false},
+		{nil, "null", true},
+		{true, "string", false},
+		{"anything", "custom", true},
+	}
+	for _, tt := range tests {
+		if got := MatchesJSONType(tt.value, tt.schemaType); got != tt.want {
+			t.Errorf("MatchesJSONType(%#v, %q) = %v, want %v (type %s)", tt.value, tt.schemaType, got, tt.want, JSONTypeName(tt.value))
+		}
+	}
+}
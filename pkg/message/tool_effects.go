@@ -0,0 +1,58 @@
+package message
+
+// ToolAccess says what a tool can change
+type ToolAccess int
+
+const (
+	// AccessUnknown tools may change anything, as bash, build commands and
+	// MCP tools can. It is the access of tools that declare no effects.
+	AccessUnknown ToolAccess = iota
+	// AccessRead tools change nothing, so calls may run concurrently
+	AccessRead
+	// AccessAgentState tools change only the agent's own notes, such as the
+	// todo list or the scratchpad, and never workspace files
+	AccessAgentState
+	// AccessWrite tools create, change or remove workspace files
+	AccessWrite
+)
+
+// ToolEffects classifies a tool for caching, approval, concurrency and dry
+// runs. Tools declare their effects when they are registered.
+type ToolEffects struct {
+	Access     ToolAccess
+	Cacheable  bool // AccessRead tool whose result only changes when the files it reads change
+	PathScoped bool // reads or writes only the files named in its path arguments
+}
+
+// ClassifiedTool is a Tool that declares its effects
+type ClassifiedTool interface {
+	Tool
+	Effects() ToolEffects
+}
+
+// EffectsOf returns the effects a tool declares. Tools that declare none get
+// AccessUnknown.
+func EffectsOf(tool Tool) ToolEffects {
+	if classified, ok := tool.(ClassifiedTool); ok {
+		return classified.Effects()
+	}
+	return ToolEffects{}
+}
+
+// Effects of the built-in tools, declared when they are registered
+var (
+	// CachedFileRead reads only the files named in its path arguments (Read, query_data, diff_files)
+	CachedFileRead = ToolEffects{Access: AccessRead, Cacheable: true, PathScoped: true}
+	// CachedSearch lists or searches directories, so any file change may alter its result
+	CachedSearch = ToolEffects{Access: AccessRead, Cacheable: true}
+	// UncachedRead changes nothing but may return something new each call (logs, git, web)
+	UncachedRead = ToolEffects{Access: AccessRead}
+	// AgentStateChange updates the agent's own notes only
+	AgentStateChange = ToolEffects{Access: AccessAgentState}
+	// FileWrite changes only the files named in its path arguments
+	FileWrite = ToolEffects{Access: AccessWrite, PathScoped: true}
+	// WorkspaceWrite changes files named elsewhere, such as inside a patch or an edit list
+	WorkspaceWrite = ToolEffects{Access: AccessWrite}
+	// AnyChange may change anything, like shell commands and builds
+	AnyChange = ToolEffects{Access: AccessUnknown}
+)
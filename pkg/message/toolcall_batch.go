@@ -40,3 +40,18 @@ func (b *ToolCallBatchMessage) Calls() []*ToolCallMessage { return b.calls }
 func (b *ToolCallBatchMessage) TruncatedString() string {
 	return fmt.Sprintf("🔧 Used %d tools (batch)", len(b.calls))
 }
+
+// NewToolCallResponse returns the message for the tool calls of one model
+// response: the call itself when there is one, and a batch when the model
+// asked for several at once so they run in the same turn. It returns nil when
+// there are no calls.
+func NewToolCallResponse(calls []*ToolCallMessage) Message {
+	switch len(calls) {
+	case 0:
+		return nil
+	case 1:
+		return calls[0]
+	default:
+		return NewToolCallBatch(calls)
+	}
+}
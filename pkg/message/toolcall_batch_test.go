@@ -0,0 +1,25 @@
+package message
+
+import "testing"
+
+func TestNewToolCallResponse(t *testing.T) {
+	read := NewToolCallMessage("Read", ToolArgumentValues{"file_path": "a.go"})
+	grep := NewToolCallMessage("Grep", ToolArgumentValues{"pattern": "TODO"})
+
+	if got := NewToolCallResponse(nil); got != nil {
+		t.Errorf("expected nil for no calls, got %T", got)
+	}
+	if got := NewToolCallResponse([]*ToolCallMessage{read}); got != read {
+		t.Errorf("expected a single call to be returned as is, got %T", got)
+	}
+	batch, ok := NewToolCallResponse([]*ToolCallMessage{read, grep}).(*ToolCallBatchMessage)
+	if !ok {
+		t.Fatal("expected several calls to become a batch")
+	}
+	if calls := batch.Calls(); len(calls) != 2 || calls[0] != read || calls[1] != grep {
+		t.Errorf("expected the batch to keep both calls in order, got %v", calls)
+	}
+	if batch.Type() != MessageTypeToolCallBatch {
+		t.Errorf("expected batch type, got %s", batch.Type())
+	}
+}
@@ -0,0 +1,107 @@
+package message
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Default limits for tool output truncation
+const (
+	DefaultTruncateHeadLines = 30
+	DefaultTruncateTailLines = 30
+	DefaultTruncateMaxTokens = 2000
+)
+
+// TruncationConfig controls how large tool outputs are shortened for display.
+// Zero values fall back to the defaults above.
+type TruncationConfig struct {
+	HeadLines int  // lines kept from the start of the output
+	TailLines int  // lines kept from the end of the output
+	MaxTokens int  // approximate token budget for the truncated output
+	Disabled  bool // show the output in full
+}
+
+// DefaultTruncationConfig returns the default tool output truncation settings
+func DefaultTruncationConfig() TruncationConfig {
+	return TruncationConfig{
+		HeadLines: DefaultTruncateHeadLines,
+		TailLines: DefaultTruncateTailLines,
+		MaxTokens: DefaultTruncateMaxTokens,
+	}
+}
+
+func (c TruncationConfig) withDefaults() TruncationConfig {
+	if c.HeadLines <= 0 {
+		c.HeadLines = DefaultTruncateHeadLines
+	}
+	if c.TailLines <= 0 {
+		c.TailLines = DefaultTruncateTailLines
+	}
+	if c.MaxTokens <= 0 {
+		c.MaxTokens = DefaultTruncateMaxTokens
+	}
+	return c
+}
+
+// EstimateTokens approximates the token count of text (~4 chars/token)
+func EstimateTokens(text string) int {
+	return (len(text) + 3) / 4
+}
+
+// TruncateHeadTail shortens content that exceeds the token budget by keeping the
+// first HeadLines and last TailLines with a "... N lines omitted ..." marker in
+// between. This preserves error summaries at the top and final status at the bottom.
+// Content within budget, or any content when truncation is disabled, is returned unchanged.
+func TruncateHeadTail(content string, cfg TruncationConfig) string {
+	cfg = cfg.withDefaults()
+	if cfg.Disabled || EstimateTokens(content) <= cfg.MaxTokens {
+		return content
+	}
+
+	lines := strings.Split(content, "\n")
+	omitted := 0
+	if len(lines) > cfg.HeadLines+cfg.TailLines {
+		omitted = len(lines) - cfg.HeadLines - cfg.TailLines
+	}
+
+	var kept []string
+	if omitted > 0 {
+		kept = make([]string, 0, cfg.HeadLines+cfg.TailLines)
+		kept = append(kept, lines[:cfg.HeadLines]...)
+		kept = append(kept, lines[len(lines)-cfg.TailLines:]...)
+	} else {
+		kept = lines
+	}
+
+	// Very long lines can still blow the budget; cap each line's width evenly
+	maxLineChars := cfg.MaxTokens * 4 / len(kept)
+	if maxLineChars < 20 {
+		maxLineChars = 20
+	}
+	for i, line := range kept {
+		if len(line) > maxLineChars {
+			kept[i] = truncateLine(line, maxLineChars)
+		}
+	}
+
+	if omitted == 0 {
+		return strings.Join(kept, "\n")
+	}
+
+	var b strings.Builder
+	b.WriteString(strings.Join(kept[:cfg.HeadLines], "\n"))
+	b.WriteString(fmt.Sprintf("\n... %d lines omitted ...\n", omitted))
+	b.WriteString(strings.Join(kept[cfg.HeadLines:], "\n"))
+	return b.String()
+}
+
+// truncateLine shortens a single line to at most max bytes, marking the cut
+// and avoiding splitting a multi-byte UTF-8 character
+func truncateLine(line string, max int) string {
+	cut := max - 3
+	for cut > 0 && cut < len(line) && !utf8.RuneStart(line[cut]) {
+		cut--
+	}
+	return line[:cut] + "..."
+}
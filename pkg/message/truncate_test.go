@@ -0,0 +1,52 @@
+package message
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestTruncateHeadTail(t *testing.T) {
+	t.Run("small output unchanged", func(t *testing.T) {
+		content := "line1\nline2\nline3"
+		if got := TruncateHeadTail(content, DefaultTruncationConfig()); got != content {
+			t.Errorf("expected unchanged content, got %q", got)
+		}
+	})
+
+	t.Run("keeps head and tail of large output", func(t *testing.T) {
+		var lines []string
+		for i := 1; i <= 200; i++ {
+			lines = append(lines, fmt.Sprintf("line %d with some padding to exceed the budget", i))
+		}
+		content := strings.Join(lines, "\n")
+
+		got := TruncateHeadTail(content, TruncationConfig{HeadLines: 3, TailLines: 2, MaxTokens: 100})
+		want := strings.Join([]string{
+			lines[0], lines[1], lines[2],
+			"... 195 lines omitted ...",
+			lines[198], lines[199],
+		}, "\n")
+		if got != want {
+			t.Errorf("unexpected truncation:\n%s\nwant:\n%s", got, want)
+		}
+	})
+
+	t.Run("disabled keeps everything", func(t *testing.T) {
+		content := strings.Repeat("a long line of tool output\n", 1000)
+		if got := TruncateHeadTail(content, TruncationConfig{MaxTokens: 10, Disabled: true}); got != content {
+			t.Errorf("expected full content, got %d of %d bytes", len(got), len(content))
+		}
+	})
+
+	t.Run("caps long lines to the budget", func(t *testing.T) {
+		content := strings.Repeat("x", 10000)
+		got := TruncateHeadTail(content, TruncationConfig{MaxTokens: 50})
+		if len(got) > 200 {
+			t.Errorf("expected line capped to ~200 chars, got %d", len(got))
+		}
+		if !strings.HasSuffix(got, "...") {
+			t.Errorf("expected truncation marker, got %q", got[len(got)-10:])
+		}
+	})
+}
@@ -0,0 +1,163 @@
+// Package secret redacts credentials from text before it is shown to the LLM,
+// saved in the session file or written to logs.
+package secret
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// Redacted replaces every secret found in text
+const Redacted = "[REDACTED]"
+
+// DefaultPatterns match well-known credential formats
+var DefaultPatterns = []string{
+	`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`,                                              // AWS access key ID
+	`(?i)aws_secret_access_key\s*[=:]\s*["']?[A-Za-z0-9/+=]{40}`,                 // AWS secret key assignment
+	`\bgh[pousr]_[A-Za-z0-9]{36,}\b`,                                             // GitHub token
+	`\bgithub_pat_[A-Za-z0-9_]{50,}\b`,                                           // GitHub fine-grained token
+	`\bsk-ant-[A-Za-z0-9_-]{20,}`,                                                // Anthropic API key
+	`\bsk-(?:proj-)?[A-Za-z0-9_-]{32,}`,                                          // OpenAI API key
+	`\bAIza[0-9A-Za-z_-]{35}\b`,                                                  // Google API key
+	`\bxox[abprs]-[A-Za-z0-9-]{10,}`,                                             // Slack token
+	`(?i)\bbearer\s+[A-Za-z0-9._~+/-]{20,}=*`,                                    // bearer token in a header
+	`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`, // PEM private key
+}
+
+// Environment values shorter than this are never treated as secrets
+const minEnvSecretLength = 8
+
+// secretNameHints mark environment variables that hold credentials
+var secretNameHints = []string{"KEY", "TOKEN", "SECRET", "PASSWORD", "PASSWD", "CREDENTIAL", "AUTH"}
+
+// Options configures a Scrubber
+type Options struct {
+	Patterns  []string // regular expressions redacted in addition to DefaultPatterns
+	IgnoreEnv bool     // don't redact values of secret-looking environment variables
+}
+
+// Scrubber replaces secrets in text with Redacted and counts the replacements
+type Scrubber struct {
+	patterns  []*regexp.Regexp
+	envValues []string // longest first, so a value containing another is replaced whole
+	total     atomic.Int64
+}
+
+// NewScrubber compiles the patterns and, unless IgnoreEnv is set, collects the
+// environment values that look like secrets
+func NewScrubber(opts Options) (*Scrubber, error) {
+	s := &Scrubber{}
+	for _, p := range append(append([]string{}, DefaultPatterns...), opts.Patterns...) {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid secret pattern %q: %w", p, err)
+		}
+		s.patterns = append(s.patterns, re)
+	}
+	if !opts.IgnoreEnv {
+		s.envValues = secretEnvValues(os.Environ())
+	}
+	return s, nil
+}
+
+// Scrub returns text with every secret replaced and the number of replacements
+func (s *Scrubber) Scrub(text string) (string, int) {
+	text, count := s.scrub(text)
+	if count > 0 {
+		s.total.Add(int64(count))
+	}
+	return text, count
+}
+
+// Count returns the number of secrets in text without recording them in Total
+func (s *Scrubber) Count(text string) int {
+	_, count := s.scrub(text)
+	return count
+}
+
+func (s *Scrubber) scrub(text string) (string, int) {
+	if s == nil || text == "" {
+		return text, 0
+	}
+	count := 0
+	for _, v := range s.envValues {
+		if n := strings.Count(text, v); n > 0 {
+			text = strings.ReplaceAll(text, v, Redacted)
+			count += n
+		}
+	}
+	for _, re := range s.patterns {
+		text = re.ReplaceAllStringFunc(text, func(match string) string {
+			if match == Redacted {
+				return match
+			}
+			count++
+			return Redacted
+		})
+	}
+	return text, count
+}
+
+// Redact is Scrub without the count, for use as a log filter
+func (s *Scrubber) Redact(text string) string {
+	text, _ = s.Scrub(text)
+	return text
+}
+
+// RedactUncounted is Redact without adding to Total, for copies of text that
+// is scrubbed again where it is kept, such as bash output streamed before the
+// tool result
+func (s *Scrubber) RedactUncounted(text string) string {
+	text, _ = s.scrub(text)
+	return text
+}
+
+// Total returns the number of replacements made since the scrubber was created
+func (s *Scrubber) Total() int {
+	if s == nil {
+		return 0
+	}
+	return int(s.total.Load())
+}
+
+// secretEnvValues returns the values of environment entries (KEY=value) that
+// look like credentials: any reasonably long value of a variable named like a
+// secret. Other variables are left alone however random their values look,
+// since commit hashes, build IDs and the like are not secrets.
+func secretEnvValues(environ []string) []string {
+	seen := make(map[string]bool)
+	var values []string
+	for _, kv := range environ {
+		name, value, ok := strings.Cut(kv, "=")
+		if !ok || len(value) < minEnvSecretLength || strings.ContainsAny(value, " \t\n") || isPathLike(value) || seen[value] {
+			continue
+		}
+		if !hasSecretName(name) {
+			continue
+		}
+		seen[value] = true
+		values = append(values, value)
+	}
+	sort.Slice(values, func(i, j int) bool { return len(values[i]) > len(values[j]) })
+	return values
+}
+
+func hasSecretName(name string) bool {
+	upper := strings.ToUpper(name)
+	for _, hint := range secretNameHints {
+		if strings.Contains(upper, hint) {
+			return true
+		}
+	}
+	return false
+}
+
+// isPathLike reports whether value is a path or path list, which are common
+// in the environment (even in variables like SSH_AUTH_SOCK) but not secret
+func isPathLike(value string) bool {
+	return strings.HasPrefix(value, "/") || strings.HasPrefix(value, "~") || strings.Contains(value, string(os.PathListSeparator))
+}